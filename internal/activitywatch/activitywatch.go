@@ -0,0 +1,120 @@
+// Package activitywatch converts captures into ActivityWatch buckets, in the format of the
+// aw-server export (GET /api/0/export) that POST /api/0/import accepts
+package activitywatch
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Bucket types understood by the ActivityWatch web UI
+const (
+	TypeCurrentWindow = "currentwindow"
+	TypeAFKStatus     = "afkstatus"
+)
+
+// Client is the client name of exported buckets; bucket IDs are prefixed with it, so imports
+// don't clash with the buckets of ActivityWatch's own watchers
+const Client = "stuff-time"
+
+// Event is an ActivityWatch event; Duration is in seconds
+type Event struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Duration  float64           `json:"duration"`
+	Data      map[string]string `json:"data"`
+}
+
+// Bucket is an ActivityWatch bucket with its events
+type Bucket struct {
+	ID       string   `json:"id"`
+	Created  string   `json:"created"`
+	Type     string   `json:"type"`
+	Client   string   `json:"client"`
+	Hostname string   `json:"hostname"`
+	Events   []*Event `json:"events"`
+}
+
+// Export is the document written by the export and read by the import endpoint
+type Export struct {
+	Buckets map[string]*Bucket `json:"buckets"`
+}
+
+// Sample is one capture
+type Sample struct {
+	Time  time.Time
+	App   string // "unknown" when empty
+	Title string
+}
+
+// NewExport creates an export with a window bucket and an AFK bucket for hostname
+func NewExport(hostname string, windows, afk []*Event) *Export {
+	created := time.Now().Format(time.RFC3339)
+	bucket := func(kind, bucketType string, events []*Event) *Bucket {
+		if events == nil {
+			events = []*Event{}
+		}
+		return &Bucket{
+			ID:       Client + "-" + kind + "_" + hostname,
+			Created:  created,
+			Type:     bucketType,
+			Client:   Client,
+			Hostname: hostname,
+			Events:   events,
+		}
+	}
+	export := &Export{Buckets: make(map[string]*Bucket)}
+	for _, b := range []*Bucket{bucket("window", TypeCurrentWindow, windows), bucket("afk", TypeAFKStatus, afk)} {
+		export.Buckets[b.ID] = b
+	}
+	return export
+}
+
+// WindowEvents merges chronologically ordered samples into window events
+// Each sample covers one capture interval; consecutive samples of the same app and title at most
+// maxGap apart form one event
+func WindowEvents(samples []Sample, interval, maxGap time.Duration) []*Event {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if maxGap < interval {
+		maxGap = interval
+	}
+
+	var events []*Event
+	var current *Event
+	var start, last time.Time
+	for _, s := range samples {
+		app := s.App
+		if app == "" {
+			app = "unknown"
+		}
+		if current != nil && (current.Data["app"] != app || current.Data["title"] != s.Title || s.Time.Sub(last) > maxGap) {
+			current = nil
+		}
+		if current == nil {
+			current = &Event{Timestamp: s.Time, Data: map[string]string{"app": app, "title": s.Title}}
+			events = append(events, current)
+			start = s.Time
+		}
+		last = s.Time
+		current.Duration = last.Add(interval).Sub(start).Seconds()
+	}
+	return events
+}
+
+// AFKEvent returns an afkstatus event for a span of time
+func AFKEvent(start, end time.Time, afk bool) *Event {
+	status := "not-afk"
+	if afk {
+		status = "afk"
+	}
+	return &Event{Timestamp: start, Duration: end.Sub(start).Seconds(), Data: map[string]string{"status": status}}
+}
+
+// Write writes the export as indented JSON
+func Write(w io.Writer, export *Export) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
@@ -0,0 +1,65 @@
+package activitywatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWindowEvents(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	samples := []Sample{
+		{Time: at(0), App: "Code", Title: "main.go"},
+		{Time: at(1), App: "Code", Title: "main.go"},
+		{Time: at(2), App: "Slack", Title: "#dev"},
+		// Gap of 10 minutes splits the event
+		{Time: at(12), App: "Slack", Title: "#dev"},
+		{Time: at(13)},
+	}
+
+	events := WindowEvents(samples, time.Minute, 2*time.Minute)
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4", len(events))
+	}
+	if events[0].Duration != 120 || events[0].Data["app"] != "Code" || events[0].Data["title"] != "main.go" {
+		t.Errorf("first event = %+v, want Code main.go for 120s", events[0])
+	}
+	if events[3].Data["app"] != "unknown" {
+		t.Errorf("app without window = %q, want unknown", events[3].Data["app"])
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+	export := NewExport("mac", nil, []*Event{AFKEvent(start, start.Add(5*time.Minute), true)})
+
+	var buf bytes.Buffer
+	if err := Write(&buf, export); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Buckets map[string]struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Timestamp string            `json:"timestamp"`
+				Duration  float64           `json:"duration"`
+				Data      map[string]string `json:"data"`
+			} `json:"events"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	afk, ok := decoded.Buckets["stuff-time-afk_mac"]
+	if !ok || afk.Type != TypeAFKStatus || len(afk.Events) != 1 {
+		t.Fatalf("afk bucket = %+v", afk)
+	}
+	if e := afk.Events[0]; e.Timestamp != "2025-12-09T14:00:00Z" || e.Duration != 300 || e.Data["status"] != "afk" {
+		t.Errorf("afk event = %+v", e)
+	}
+	if window, ok := decoded.Buckets["stuff-time-window_mac"]; !ok || window.Events == nil {
+		t.Errorf("window bucket should be present with an empty event list")
+	}
+}
@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+)
+
+// ErrRejected is returned when the daemon refuses an upload; resending the same screenshot won't help
+var ErrRejected = errors.New("upload rejected by server")
+
+// Client uploads screenshots to the central daemon
+type Client struct {
+	ServerURL string
+	Token     string
+	client    *http.Client
+}
+
+// NewClient creates a client for the daemon at serverURL
+// Only https URLs are accepted for other machines, so screenshots and the token never cross the network in cleartext
+func NewClient(serverURL, token string) (*Client, error) {
+	if serverURL == "" || token == "" {
+		return nil, fmt.Errorf("agent.server_url and agent.token are required")
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent.server_url: %w", err)
+	}
+	if u.Scheme != "https" && !IsLoopbackHost(u.Hostname()) {
+		return nil, fmt.Errorf("refusing to connect to %s over plain HTTP: use an https:// agent.server_url", serverURL)
+	}
+	return &Client{
+		ServerURL: strings.TrimRight(serverURL, "/"),
+		Token:     token,
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// TrustCA makes the client trust the server certificates signed by the PEM certificates in path,
+// e.g. a self-signed daemon certificate
+func (c *Client) TrustCA(path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", path)
+	}
+	c.client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+	}
+	return nil
+}
+
+// Ping checks that the daemon is reachable and accepts the token
+func (c *Client) Ping() error {
+	req, err := http.NewRequest("GET", c.ServerURL+PingPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req)
+}
+
+// Upload sends one screenshot with its metadata
+func (c *Client) Upload(meta *Metadata, imagePath string) error {
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	image, err := os.Open(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer image.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("metadata", string(metaJSON)); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	part, err := form.CreateFormFile("image", filepath.Base(imagePath))
+	if err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if _, err := io.Copy(part, image); err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to write upload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.ServerURL+ScreenshotsPath, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return c.do(req)
+}
+
+func (c *Client) do(req *http.Request) error {
+	return c.doJSON(req, nil)
+}
+
+// doJSON sends the request and decodes a JSON response into out (if not nil)
+func (c *Client) doJSON(req *http.Request, out interface{}) error {
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends an authorized request, returning the response only for a 2xx status
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: %s - %s", ErrRejected, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil, fmt.Errorf("server error: %s - %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// Spool keeps captured screenshots on disk until they are uploaded,
+// so captures made while the daemon is unreachable are sent later in order
+// Each screenshot is stored as <capture time>.<format> next to a <capture time>.json metadata file
+type Spool struct {
+	Dir string
+}
+
+// Add moves a captured image into the spool
+func (s *Spool) Add(meta *Metadata, imagePath string) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	name := fmt.Sprintf("%d", meta.Timestamp.UnixNano())
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := moveFile(imagePath, filepath.Join(s.Dir, name+"."+meta.Format)); err != nil {
+		return fmt.Errorf("failed to spool image: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Dir, name+".json"), metaJSON, 0644); err != nil {
+		return fmt.Errorf("failed to spool metadata: %w", err)
+	}
+	return nil
+}
+
+// Flush uploads spooled screenshots oldest first, removing each once the daemon has it
+// It stops at the first upload that can be retried later (daemon unreachable, server error)
+func (s *Spool) Flush(c *Client) (int, error) {
+	metaFiles, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list spool: %w", err)
+	}
+	sort.Strings(metaFiles)
+
+	sent := 0
+	for _, metaFile := range metaFiles {
+		data, err := os.ReadFile(metaFile)
+		if err != nil {
+			return sent, fmt.Errorf("failed to read spooled metadata: %w", err)
+		}
+		var meta Metadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			logger.GetLogger().Warnf("Dropping spooled screenshot with invalid metadata %s: %v", metaFile, err)
+			os.Remove(metaFile)
+			continue
+		}
+		imagePath := strings.TrimSuffix(metaFile, ".json") + "." + meta.Format
+
+		err = c.Upload(&meta, imagePath)
+		if errors.Is(err, ErrRejected) || errors.Is(err, os.ErrNotExist) {
+			logger.GetLogger().Warnf("Dropping spooled screenshot %s: %v", imagePath, err)
+		} else if err != nil {
+			return sent, err
+		} else {
+			sent++
+		}
+		os.Remove(imagePath)
+		os.Remove(metaFile)
+	}
+	return sent, nil
+}
+
+// moveFile renames src to dst, copying when they are on different file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
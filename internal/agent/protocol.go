@@ -0,0 +1,65 @@
+// Package agent implements the remote capture agent protocol: a lightweight agent on another machine
+// pushes screenshots and their metadata to the central stuff-time daemon over HTTP.
+// With remote analysis enabled, the same server hands its analysis queue to remote workers,
+// which pull screenshots, analyze and summarize them, and push the results back
+//
+// Protocol (version 1):
+//
+//	GET  /agent/v1/ping         checks connectivity and the token, responds 204
+//	POST /agent/v1/screenshots  uploads one screenshot as multipart/form-data:
+//	                            "metadata" - JSON encoded Metadata
+//	                            "image"    - the PNG image file
+//	GET  /agent/v1/queue        hands out screenshots waiting for analysis (?worker=<name>&limit=<n>),
+//	                            responds {"items": [QueueItem...]}; handed out screenshots are
+//	                            reserved for the worker until the lease expires
+//	GET  /agent/v1/queue/image  downloads the image of a queued screenshot (?id=<screenshot id>)
+//	POST /agent/v1/results      pushes JSON encoded Results, responds with a MergeResult
+//
+// Every request carries "Authorization: Bearer <token>". Responses are JSON;
+// errors are {"error": "..."} with a 4xx/5xx status. A 2xx response means the screenshot
+// was stored (or deliberately dropped, e.g. outside work hours) and must not be resent.
+// Merging results is idempotent: an analysis only fills a screenshot that is still unanalyzed,
+// and a summary only replaces an older version pushed by a worker
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Protocol paths
+const (
+	PingPath        = "/agent/v1/ping"
+	ScreenshotsPath = "/agent/v1/screenshots"
+)
+
+// MaxImageSize is the largest accepted screenshot upload
+const MaxImageSize = 32 << 20
+
+// Metadata describes an uploaded screenshot
+type Metadata struct {
+	Agent     string    `json:"agent"`     // Name of the machine running the agent
+	Timestamp time.Time `json:"timestamp"` // Capture time
+	ScreenID  int       `json:"screen_id"`
+	App       string    `json:"app,omitempty"`   // Frontmost application, if known
+	Title     string    `json:"title,omitempty"` // Frontmost window title, if known
+	Format    string    `json:"format"`          // Image format (only png is supported: screenshots are sent to the vision model as PNG)
+}
+
+// Validate checks the metadata of an upload
+func (m *Metadata) Validate() error {
+	if strings.TrimSpace(m.Agent) == "" {
+		return fmt.Errorf("agent name is required")
+	}
+	if strings.ContainsAny(m.Agent, `/\`) || m.Agent == "." || m.Agent == ".." {
+		return fmt.Errorf("invalid agent name %q", m.Agent)
+	}
+	if m.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if m.Format != "png" {
+		return fmt.Errorf("unsupported image format %q (supported: png)", m.Format)
+	}
+	return nil
+}
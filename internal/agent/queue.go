@@ -0,0 +1,247 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"stuff-time/internal/logger"
+)
+
+// Remote analysis paths
+const (
+	QueuePath      = "/agent/v1/queue"
+	QueueImagePath = "/agent/v1/queue/image"
+	ResultsPath    = "/agent/v1/results"
+)
+
+// MaxQueueBatch is the largest number of screenshots handed out per queue request
+const MaxQueueBatch = 500
+
+// maxResultsSize is the largest accepted results upload
+const maxResultsSize = 64 << 20
+
+// QueueItem is a screenshot waiting for analysis on the capture daemon
+type QueueItem struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	ScreenID  int       `json:"screen_id"`
+	Format    string    `json:"format"`               // Image format, from the image file extension
+	App       string    `json:"app,omitempty"`        // Frontmost application, if recorded
+	Title     string    `json:"title,omitempty"`      // Frontmost window title, if recorded
+	LocalOnly bool      `json:"local_only,omitempty"` // Captured in front of a local-only app: never sent to the cloud provider
+}
+
+// AnalysisResult is the outcome of analyzing a queued screenshot
+type AnalysisResult struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"` // Terminal analysis status (done, skipped, rejected, sampled_out)
+	Analysis string `json:"analysis,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SummaryResult is a period summary generated by a worker
+type SummaryResult struct {
+	PeriodKey   string    `json:"period_key"`
+	PeriodType  string    `json:"period_type"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Screenshots string    `json:"screenshots"`
+	Summary     string    `json:"summary"`
+	Analysis    string    `json:"analysis"`
+	GeneratedAt time.Time `json:"generated_at"` // When the worker pushed this version, the newest version wins
+	Hash        string    `json:"hash"`         // SummaryHash of the content
+}
+
+// Results are pushed by a worker after analyzing pulled screenshots
+type Results struct {
+	Worker    string            `json:"worker"`
+	Analyses  []*AnalysisResult `json:"analyses,omitempty"`
+	Summaries []*SummaryResult  `json:"summaries,omitempty"`
+}
+
+// MergeResult counts the results applied by the capture daemon; results it already has
+// (or that lost against a newer version) are ignored
+type MergeResult struct {
+	Analyses  int `json:"analyses"`
+	Summaries int `json:"summaries"`
+}
+
+// SummaryHash identifies the content of a period summary, so unchanged summaries aren't pushed
+// or merged again
+func SummaryHash(periodKey, screenshots, summary, analysis string) string {
+	h := sha256.New()
+	for _, s := range []string{periodKey, screenshots, summary, analysis} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Queue is the analysis queue of a capture daemon that leaves analysis to remote workers
+type Queue interface {
+	// Export hands up to limit unanalyzed screenshots to worker
+	Export(worker string, limit int) ([]*QueueItem, error)
+	// OpenImage opens the image of a queued screenshot, os.ErrNotExist if it is unknown
+	OpenImage(id string) (io.ReadCloser, error)
+	// Merge applies results pushed by a worker
+	Merge(results *Results) (*MergeResult, error)
+}
+
+// ServeQueue makes the server hand out its analysis queue to remote workers
+// Without a queue the queue endpoints respond 404
+func (s *Server) ServeQueue(q Queue) {
+	s.queue = q
+}
+
+// handleQueue serves GET /agent/v1/queue?worker=<name>&limit=<n>
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		writeError(w, http.StatusNotFound, "remote analysis is not enabled")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	worker := r.URL.Query().Get("worker")
+	if worker == "" {
+		writeError(w, http.StatusBadRequest, "worker is required")
+		return
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > MaxQueueBatch {
+		limit = MaxQueueBatch
+	}
+
+	items, err := s.queue.Export(worker, limit)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to export analysis queue to worker %s: %v", worker, err)
+		writeError(w, http.StatusInternalServerError, "failed to export queue")
+		return
+	}
+	if items == nil {
+		items = []*QueueItem{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
+
+// handleQueueImage serves GET /agent/v1/queue/image?id=<screenshot id>
+func (s *Server) handleQueueImage(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		writeError(w, http.StatusNotFound, "remote analysis is not enabled")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	image, err := s.queue.OpenImage(r.URL.Query().Get("id"))
+	if errors.Is(err, os.ErrNotExist) {
+		writeError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to open queued screenshot %s: %v", r.URL.Query().Get("id"), err)
+		writeError(w, http.StatusInternalServerError, "failed to open screenshot")
+		return
+	}
+	defer image.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, image)
+}
+
+// handleResults serves POST /agent/v1/results
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		writeError(w, http.StatusNotFound, "remote analysis is not enabled")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var results Results
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxResultsSize)).Decode(&results); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid results: %v", err))
+		return
+	}
+	if results.Worker == "" {
+		writeError(w, http.StatusBadRequest, "worker is required")
+		return
+	}
+
+	merged, err := s.queue.Merge(&results)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to merge results of worker %s: %v", results.Worker, err)
+		writeError(w, http.StatusInternalServerError, "failed to merge results")
+		return
+	}
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// PullQueue fetches up to limit screenshots waiting for analysis
+func (c *Client) PullQueue(worker string, limit int) ([]*QueueItem, error) {
+	query := url.Values{"worker": {worker}, "limit": {strconv.Itoa(limit)}}
+	req, err := http.NewRequest("GET", c.ServerURL+QueuePath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var resp struct {
+		Items []*QueueItem `json:"items"`
+	}
+	if err := c.doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// DownloadImage writes the image of a queued screenshot to w
+func (c *Client) DownloadImage(id string, w io.Writer) error {
+	req, err := http.NewRequest("GET", c.ServerURL+QueueImagePath+"?"+url.Values{"id": {id}}.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	return nil
+}
+
+// PushResults sends analysis results and summaries back to the capture daemon
+func (c *Client) PushResults(results *Results) (*MergeResult, error) {
+	body, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal results: %w", err)
+	}
+	req, err := http.NewRequest("POST", c.ServerURL+ResultsPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var merged MergeResult
+	if err := c.doJSON(req, &merged); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
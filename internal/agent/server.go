@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+)
+
+// IngestFunc stores an uploaded screenshot read from image
+type IngestFunc func(meta *Metadata, image io.Reader) error
+
+// Server receives screenshots from remote agents
+type Server struct {
+	token  string
+	ingest IngestFunc
+	queue  Queue
+	srv    *http.Server
+}
+
+// NewServer creates an agent server; uploads must carry token
+func NewServer(token string, ingest IngestFunc) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("agent.token is required to accept remote agents")
+	}
+	return &Server{token: token, ingest: ingest}, nil
+}
+
+// Handler returns the HTTP handler serving the agent protocol
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PingPath, s.authorized(s.handlePing))
+	mux.HandleFunc(ScreenshotsPath, s.authorized(s.handleScreenshot))
+	mux.HandleFunc(QueuePath, s.authorized(s.handleQueue))
+	mux.HandleFunc(QueueImagePath, s.authorized(s.handleQueueImage))
+	mux.HandleFunc(ResultsPath, s.authorized(s.handleResults))
+	return mux
+}
+
+// Start listens on addr and serves uploads in the background, over TLS when certFile and keyFile are set
+// Screenshots, analyses and the token cross the network, so addresses other than loopback require TLS
+func (s *Server) Start(addr, certFile, keyFile string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %s: %w", addr, err)
+	}
+	useTLS := certFile != "" || keyFile != ""
+	if !useTLS && !IsLoopbackHost(host) {
+		return fmt.Errorf("refusing to serve the agent protocol on %s without TLS: set agent.tls_cert and agent.tls_key, or listen on 127.0.0.1", addr)
+	}
+	var tlsConfig *tls.Config
+	if useTLS {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	s.srv = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.GetLogger().Errorf("Agent server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the server down, waiting for in-flight uploads
+func (s *Server) Stop() error {
+	if s.srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handlePing serves GET /agent/v1/ping
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleScreenshot serves POST /agent/v1/screenshots
+func (s *Server) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImageSize+1<<20)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid upload: %v", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var meta Metadata
+	if err := json.Unmarshal([]byte(r.FormValue("metadata")), &meta); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid metadata: %v", err))
+		return
+	}
+	if err := meta.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	image, _, err := r.FormFile("image")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "image is required")
+		return
+	}
+	defer image.Close()
+
+	if err := s.ingest(&meta, image); err != nil {
+		logger.GetLogger().Warnf("Failed to store screenshot from agent %s: %v", meta.Agent, err)
+		writeError(w, http.StatusInternalServerError, "failed to store screenshot")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"status": "stored"})
+}
+
+// IsLoopbackHost reports whether host is localhost or a loopback IP
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
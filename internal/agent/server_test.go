@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTransportSecurity(t *testing.T) {
+	s, err := NewServer("secret", func(meta *Metadata, image io.Reader) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start("0.0.0.0:0", "", ""); err == nil || !strings.Contains(err.Error(), "without TLS") {
+		t.Errorf("Start() on all interfaces without TLS = %v, want refusal", err)
+	}
+	if err := s.Start("127.0.0.1:0", "", ""); err != nil {
+		t.Errorf("Start() on loopback = %v", err)
+	}
+	s.Stop()
+
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"http://192.168.1.10:8788", false},
+		{"https://192.168.1.10:8788", true},
+		{"http://127.0.0.1:8788", true},
+		{"http://localhost:8788", true},
+	}
+	for _, tt := range tests {
+		if _, err := NewClient(tt.url, "secret"); (err == nil) != tt.ok {
+			t.Errorf("NewClient(%s) error = %v, want ok = %v", tt.url, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package analyzer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backend modes for the LLM client
+const (
+	BackendModeLive   = "live"   // 直接调用真实 API（默认）
+	BackendModeRecord = "record" // 调用真实 API，并将请求/响应对录制为 fixture
+	BackendModeReplay = "replay" // 只从 fixture 回放，不访问网络
+	BackendModeMock   = "mock"   // 纯 mock，返回固定内容
+)
+
+// DefaultMockResponse is returned by the mock backend when no response is configured
+const DefaultMockResponse = "[mock] 用户正在编辑器中编写代码，处理项目开发相关任务。"
+
+// ErrFixtureNotFound is returned in replay mode when no fixture matches the request (not retryable)
+var ErrFixtureNotFound = errors.New("no recorded fixture for request")
+
+// Fixture is a recorded request/response pair
+type Fixture struct {
+	Key        string    `json:"key"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Model      string    `json:"model,omitempty"`
+	StatusCode int       `json:"status_code"`
+	Body       string    `json:"body"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// NewBackendTransport creates an http.RoundTripper for the given backend mode.
+// Returns nil for live mode, meaning the default transport is used.
+func NewBackendTransport(mode, fixturesPath, mockResponse string) (http.RoundTripper, error) {
+	switch mode {
+	case "", BackendModeLive:
+		return nil, nil
+	case BackendModeRecord, BackendModeReplay:
+		if fixturesPath == "" {
+			return nil, fmt.Errorf("fixtures path is required for backend mode %q", mode)
+		}
+		if mode == BackendModeRecord {
+			if err := os.MkdirAll(fixturesPath, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+			}
+		}
+		return &RecordReplayTransport{
+			Mode:         mode,
+			FixturesPath: fixturesPath,
+		}, nil
+	case BackendModeMock:
+		if mockResponse == "" {
+			mockResponse = DefaultMockResponse
+		}
+		return &MockTransport{Response: mockResponse}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend mode: %s", mode)
+	}
+}
+
+// ConfigureBackend sets up the transport for the given backend mode
+// Live mode keeps the network transport (see ConfigureNetwork), record mode sends through it
+func (o *OpenAI) ConfigureBackend(mode, fixturesPath, mockResponse string) error {
+	transport, err := NewBackendTransport(mode, fixturesPath, mockResponse)
+	if err != nil {
+		return err
+	}
+	switch t := transport.(type) {
+	case nil:
+		return nil
+	case *RecordReplayTransport:
+		t.Next = o.Transport
+	}
+	o.Transport = transport
+	return nil
+}
+
+// RecordReplayTransport records API request/response pairs to fixture files,
+// or replays them deterministically without network access
+type RecordReplayTransport struct {
+	Mode         string
+	FixturesPath string
+	Next         http.RoundTripper // Underlying transport for record mode (defaults to http.DefaultTransport)
+}
+
+func (t *RecordReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := fixtureKey(req.Method, req.URL.Path, body)
+	fixturePath := filepath.Join(t.FixturesPath, key+".json")
+
+	if t.Mode == BackendModeReplay {
+		data, err := os.ReadFile(fixturePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w %s %s (key %s)", ErrFixtureNotFound, req.Method, req.URL.Path, key)
+			}
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", fixturePath, err)
+		}
+		return newStaticResponse(req, fixture.StatusCode, []byte(fixture.Body)), nil
+	}
+
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	fixture := Fixture{
+		Key:        key,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Model:      requestModel(body),
+		StatusCode: resp.StatusCode,
+		Body:       string(respBody),
+		RecordedAt: time.Now(),
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+	if err := os.WriteFile(fixturePath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// MockTransport answers every chat completion (or Responses API) request with a fixed response
+type MockTransport struct {
+	Response string
+}
+
+func (t *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(req.URL.Path, "/embeddings") {
+		return mockEmbeddings(req, reqBody)
+	}
+	if strings.HasSuffix(req.URL.Path, "/responses") {
+		text, _ := json.Marshal(t.Response)
+		body := fmt.Sprintf(`{"output":[{"type":"message","content":[{"type":"output_text","text":%s}]}]}`, text)
+		return newStaticResponse(req, http.StatusOK, []byte(body)), nil
+	}
+	var visionResp VisionResponse
+	visionResp.Choices = make([]Choice, 1)
+	visionResp.Choices[0].Message.Content = t.Response
+	body, err := json.Marshal(visionResp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mock response: %w", err)
+	}
+	return newStaticResponse(req, http.StatusOK, body), nil
+}
+
+// mockEmbeddings answers an embeddings request with word-hash vectors, so texts sharing words are similar
+func mockEmbeddings(req *http.Request, reqBody []byte) (*http.Response, error) {
+	var embReq embeddingRequest
+	if err := json.Unmarshal(reqBody, &embReq); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings request: %w", err)
+	}
+	var resp embeddingResponse
+	for i, text := range embReq.Input {
+		vector := make([]float64, 64)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			sum := sha256.Sum256([]byte(word))
+			vector[int(sum[0])%len(vector)]++
+		}
+		resp.Data = append(resp.Data, embeddingData{Index: i, Embedding: vector})
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mock embeddings: %w", err)
+	}
+	return newStaticResponse(req, http.StatusOK, body), nil
+}
+
+// readRequestBody reads the request body and restores it for further use
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// fixtureKey derives a deterministic key from method, path and body.
+// Host is excluded so fixtures stay valid when base_url changes.
+func fixtureKey(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.TrimSuffix(path, "/")))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func requestModel(body []byte) string {
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return req.Model
+}
+
+func newStaticResponse(req *http.Request, statusCode int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestOpenAI(baseURL string) *OpenAI {
+	return NewOpenAI("test-key", baseURL, "test-model", 100, "prompt", "", "", "summary-model", "summary prompt", "", "", "", "analysis-model", "analysis prompt")
+}
+
+func TestBackend_MockMode(t *testing.T) {
+	o := newTestOpenAI("http://127.0.0.1:0")
+	if err := o.ConfigureBackend(BackendModeMock, "", "固定回复"); err != nil {
+		t.Fatalf("ConfigureBackend() error = %v", err)
+	}
+
+	got, err := o.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("AnalyzeBehavior() error = %v", err)
+	}
+	if got != "固定回复" {
+		t.Errorf("AnalyzeBehavior() = %q, want %q", got, "固定回复")
+	}
+}
+
+func TestBackend_RecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"录制的回复"}}]}`))
+	}))
+	defer server.Close()
+
+	fixturesDir := filepath.Join(t.TempDir(), "fixtures")
+
+	recorder := newTestOpenAI(server.URL)
+	if err := recorder.ConfigureBackend(BackendModeRecord, fixturesDir, ""); err != nil {
+		t.Fatalf("ConfigureBackend(record) error = %v", err)
+	}
+	got, err := recorder.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("record AnalyzeBehavior() error = %v", err)
+	}
+	if got != "录制的回复" {
+		t.Errorf("record AnalyzeBehavior() = %q, want %q", got, "录制的回复")
+	}
+
+	entries, err := os.ReadDir(fixturesDir)
+	if err != nil {
+		t.Fatalf("failed to read fixtures dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 fixture, got %d", len(entries))
+	}
+
+	// Replay against a different base URL: fixtures must not depend on host
+	replayer := newTestOpenAI("http://127.0.0.1:1")
+	if err := replayer.ConfigureBackend(BackendModeReplay, fixturesDir, ""); err != nil {
+		t.Fatalf("ConfigureBackend(replay) error = %v", err)
+	}
+	got, err = replayer.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("replay AnalyzeBehavior() error = %v", err)
+	}
+	if got != "录制的回复" {
+		t.Errorf("replay AnalyzeBehavior() = %q, want %q", got, "录制的回复")
+	}
+	if calls != 1 {
+		t.Errorf("expected server to be called once, got %d", calls)
+	}
+
+	// Unknown request must fail instead of hitting the network
+	if _, err := replayer.AnalyzeBehavior("another summary"); err == nil {
+		t.Error("expected error for request without fixture")
+	}
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Fault types that can be injected into API calls
+const (
+	FaultRateLimit          = "429"
+	FaultServiceUnavailable = "503"
+	FaultTimeout            = "timeout"
+)
+
+// ChaosTransport randomly injects failures into API calls for resilience testing
+type ChaosTransport struct {
+	Next         http.RoundTripper
+	FailureRate  float64       // Probability (0-1) that a request fails
+	Faults       []string      // Fault types to pick from: "429", "503", "timeout"
+	TimeoutDelay time.Duration // How long an injected timeout blocks before failing
+}
+
+// EnableChaos wraps the current transport with fault injection
+func (o *OpenAI) EnableChaos(failureRate float64, faults []string, timeoutDelay time.Duration) {
+	if len(faults) == 0 {
+		faults = []string{FaultRateLimit, FaultServiceUnavailable, FaultTimeout}
+	}
+	o.Transport = &ChaosTransport{
+		Next:         o.Transport,
+		FailureRate:  failureRate,
+		Faults:       faults,
+		TimeoutDelay: timeoutDelay,
+	}
+}
+
+func (t *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if t.FailureRate <= 0 || rand.Float64() >= t.FailureRate {
+		return next.RoundTrip(req)
+	}
+
+	if _, err := readRequestBody(req); err != nil {
+		return nil, err
+	}
+
+	fault := t.Faults[rand.Intn(len(t.Faults))]
+	fmt.Fprintf(os.Stderr, "time=\"%s\" level=warning msg=\"Chaos: injecting %s fault into %s\"\n",
+		time.Now().Format("2006-01-02 15:04:05"), fault, req.URL.Path)
+
+	switch fault {
+	case FaultRateLimit:
+		return newStaticResponse(req, http.StatusTooManyRequests,
+			[]byte(`{"error":{"message":"chaos: rate limit exceeded","type":"rate_limit_error"}}`)), nil
+	case FaultServiceUnavailable:
+		return newStaticResponse(req, http.StatusServiceUnavailable,
+			[]byte(`{"error":{"message":"chaos: service unavailable"}}`)), nil
+	case FaultTimeout:
+		select {
+		case <-time.After(t.TimeoutDelay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		return nil, fmt.Errorf("chaos: injected i/o timeout")
+	default:
+		return nil, fmt.Errorf("chaos: unknown fault type %q", fault)
+	}
+}
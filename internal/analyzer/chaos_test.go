@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnableChaos(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"正常回复"}}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		rate       float64
+		fault      string
+		wantStatus int // 0 when the fault is not an HTTP response
+		wantErr    bool
+	}{
+		{"disabled", 0, FaultRateLimit, 0, false},
+		{"rate limit", 1, FaultRateLimit, http.StatusTooManyRequests, true},
+		{"service unavailable", 1, FaultServiceUnavailable, http.StatusServiceUnavailable, true},
+		{"timeout", 1, FaultTimeout, 0, true},
+	}
+	for _, tt := range tests {
+		hits.Store(0)
+		o := newTestOpenAI(server.URL)
+		o.Retry = RetryPolicy{}
+		o.EnableChaos(tt.rate, []string{tt.fault}, 10*time.Millisecond)
+
+		got, err := o.AnalyzeBehavior("summary")
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: AnalyzeBehavior() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr {
+			if got != "正常回复" || hits.Load() != 1 {
+				t.Errorf("%s: AnalyzeBehavior() = %q with %d request(s), want the server reply", tt.name, got, hits.Load())
+			}
+			continue
+		}
+		if hits.Load() != 0 {
+			t.Errorf("%s: injected fault still reached the server", tt.name)
+		}
+		var apiErr *APIError
+		if tt.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus) {
+			t.Errorf("%s: AnalyzeBehavior() error = %v, want status %d", tt.name, err, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// embedBatchSize is the number of texts sent per embeddings request
+const embedBatchSize = 100
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []embeddingData `json:"data"`
+}
+
+type embeddingData struct {
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// Embed returns the embedding of each text, in order, from the OpenAI-compatible embeddings endpoint
+func (o *OpenAI) Embed(model string, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		batch := texts[start:min(start+embedBatchSize, len(texts))]
+		batchVectors, err := o.embedBatch(model, batch)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+	return vectors, nil
+}
+
+func (o *OpenAI) embedBatch(model string, texts []string) (vectors [][]float64, err error) {
+	reqBody, err := json.Marshal(embeddingRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Described as a text request for the upload log and the cost report
+	req := VisionRequest{
+		Model:   model,
+		Purpose: PurposeEmbeddings,
+		Messages: []Message{
+			{Role: "user", Content: []ContentObject{{Type: "text", Text: strings.Join(texts, "\n")}}},
+		},
+	}
+	endpoint := o.EmbeddingsURL(model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	o.RecordUpload(endpoint, req)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	o.SetAuthHeader(httpReq)
+
+	var usage Usage
+	start := time.Now()
+	defer func() {
+		o.RecordCall(req, usage, time.Since(start), err)
+	}()
+
+	resp, err := o.NewHTTPClient(2 * time.Minute).Do(httpReq)
+	if err != nil {
+		return nil, NetworkError("failed to send request", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NetworkError("failed to read response", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, string(body), false)
+	}
+	usage = DecodeUsage(body)
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	vectors = make([][]float64, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// NameThreads asks the summary model to name clusters of related activity
+// Returns the raw model output (expected to be a JSON array of names)
+func (o *OpenAI) NameThreads(prompt string, threadsText string) (string, error) {
+	threadsText, err := o.fitInput(prompt, threadsText, "")
+	if err != nil {
+		return "", err
+	}
+	fullPrompt := fmt.Sprintf("%s\n\n任务线程：\n%s", prompt, threadsText)
+
+	req := VisionRequest{
+		Purpose:             PurposeThreadNaming,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: o.MaxCompletionTokens,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: fullPrompt,
+					},
+				},
+			},
+		},
+	}
+
+	return o.callAPI(req)
+}
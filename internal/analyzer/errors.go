@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Error kinds of LLM API calls, matched with errors.Is
+var (
+	// ErrRateLimited is returned when the API rejects the request with 429 (retryable with longer backoff)
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError is returned for 5xx responses (retryable)
+	ErrServerError = errors.New("server error")
+	// ErrNetwork is returned when the request could not be sent or the response could not be read (retryable)
+	ErrNetwork = errors.New("network error")
+	// ErrVisionRejected is returned when the API refuses the image of a request (invalid image, content policy);
+	// retrying the same screenshot will not help, so it should be skipped. Other 4xx responses (wrong model,
+	// bad parameter, proxy errors) are not rejections of the screenshot
+	ErrVisionRejected = errors.New("vision request rejected")
+)
+
+// APIError is a non-200 response of the LLM API
+type APIError struct {
+	StatusCode int
+	Body       string
+	kind       error
+}
+
+// newAPIError classifies a non-200 response; hasImage marks requests carrying a screenshot
+func newAPIError(statusCode int, body string, hasImage bool) *APIError {
+	e := &APIError{StatusCode: statusCode, Body: body}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		e.kind = ErrRateLimited
+	case statusCode >= 500:
+		e.kind = ErrServerError
+	case hasImage && (statusCode == http.StatusBadRequest || statusCode == http.StatusRequestEntityTooLarge || statusCode == http.StatusUnprocessableEntity) &&
+		isImageRejection(body):
+		e.kind = ErrVisionRejected
+	}
+	return e
+}
+
+// imageRejectionCodes are the error codes and types with which providers refuse the image itself
+var imageRejectionCodes = map[string]bool{
+	"invalid_image":            true,
+	"invalid_image_format":     true,
+	"invalid_image_url":        true,
+	"image_parse_error":        true,
+	"image_too_large":          true,
+	"unsupported_image":        true,
+	"content_policy_violation": true,
+}
+
+// isImageRejection reports whether the error body's code or type says the image was refused,
+// e.g. {"error": {"code": "invalid_image", "type": "invalid_request_error"}}
+func isImageRejection(body string) bool {
+	var resp struct {
+		Error struct {
+			Code any    `json:"code"`
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return false
+	}
+	code, _ := resp.Error.Code.(string)
+	return imageRejectionCodes[code] || imageRejectionCodes[resp.Error.Type]
+}
+
+// NewAPIError creates the error for a non-200 response of a text-only request
+func NewAPIError(statusCode int, body string) error {
+	return newAPIError(statusCode, body, false)
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// NetworkError wraps a transport error (send/read failure) as ErrNetwork
+func NetworkError(op string, err error) error {
+	return fmt.Errorf("%s: %w: %w", op, ErrNetwork, err)
+}
+
+// IsRetryable reports whether an API error is transient (rate limit, 5xx, network)
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrFixtureNotFound) {
+		return false
+	}
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServerError) || errors.Is(err, ErrNetwork)
+}
+
+// isTimeout reports whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// hasImageContent reports whether a request carries an image
+func hasImageContent(req VisionRequest) bool {
+	for _, m := range req.Messages {
+		for _, c := range m.Content {
+			if c.ImageURL != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
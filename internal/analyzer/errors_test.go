@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorTaxonomy(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      error
+		retryable bool
+	}{
+		{"rate limited", NewAPIError(429, "slow down"), ErrRateLimited, true},
+		{"bad gateway", NewAPIError(502, ""), ErrServerError, true},
+		{"image rejected", newAPIError(400, `{"error": {"message": "Invalid image.", "type": "invalid_request_error", "code": "invalid_image"}}`, true), ErrVisionRejected, false},
+		{"image content policy", newAPIError(400, `{"error": {"type": "content_policy_violation"}}`, true), ErrVisionRejected, false},
+		{"image request with wrong model", newAPIError(400, `{"error": {"type": "invalid_request_error", "code": "model_not_found"}}`, true), nil, false},
+		{"image request through proxy", newAPIError(413, "<html>Request Entity Too Large</html>", true), nil, false},
+		{"text bad request", NewAPIError(400, "bad prompt"), nil, false},
+		{"network", NetworkError("failed to send request", context.DeadlineExceeded), ErrNetwork, true},
+		{"fixture miss", fmt.Errorf("replay: %w", ErrFixtureNotFound), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to generate summary: %w", tt.err)
+			if tt.kind != nil && !errors.Is(wrapped, tt.kind) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.kind)
+			}
+			if tt.kind == nil && errors.Is(wrapped, ErrVisionRejected) {
+				t.Errorf("%v must not reject the screenshot", wrapped)
+			}
+			if got := IsRetryable(wrapped); got != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", wrapped, got, tt.retryable)
+			}
+		})
+	}
+
+	if got := NewAPIError(503, "down").Error(); got != "API error (status 503): down" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !isTimeout(NetworkError("failed to send request", context.DeadlineExceeded)) {
+		t.Error("context deadline must be detected as timeout")
+	}
+}
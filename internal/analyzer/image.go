@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// UploadFormatJPEG re-encodes screenshots as JPEG before upload
+const UploadFormatJPEG = "jpeg"
+
+// uploadJPEGQuality keeps small UI text legible while staying far below PNG size
+const uploadJPEGQuality = 85
+
+// imageDataURL returns the image as a data URL whose MIME type matches the content, re-encoded as
+// JPEG if UploadFormat asks for it; crop, if not nil, returns the region to send for the image size
+func (o *OpenAI) imageDataURL(imagePath string, crop func(size image.Point) image.Rectangle) (string, error) {
+	data, err := readImageFile(imagePath)
+	if err != nil {
+		return "", err
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("unsupported image content type %s", mimeType)
+	}
+
+	toJPEG := o.UploadFormat == UploadFormatJPEG && mimeType != "image/jpeg"
+	if crop != nil || toJPEG {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			if crop != nil {
+				return "", fmt.Errorf("failed to decode image: %w", err)
+			}
+			// No decoder for the format (e.g. WebP), send the file as captured
+		} else {
+			if crop != nil {
+				img = cropImage(img, crop(img.Bounds().Size()))
+			}
+			if data, mimeType, err = o.encodeImage(img, mimeType); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// encodeImage encodes a decoded (e.g. cropped) image in the upload format: JPEG if configured or
+// already JPEG, PNG otherwise
+func (o *OpenAI) encodeImage(img image.Image, mimeType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if o.UploadFormat == UploadFormatJPEG || mimeType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: uploadJPEGQuality}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// cropImage returns the region of the image, relative to its origin; an empty region keeps the full image
+func cropImage(img image.Image, region image.Rectangle) image.Image {
+	bounds := img.Bounds()
+	region = region.Add(bounds.Min).Intersect(bounds)
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok || region.Empty() || region == bounds {
+		return img
+	}
+	return sub.SubImage(region)
+}
+
+// readImageFile reads a screenshot, trying the nested path layout if the old flat path no longer exists
+func readImageFile(imagePath string) ([]byte, error) {
+	data, err := os.ReadFile(imagePath)
+	if err != nil && os.IsNotExist(err) {
+		if convertedPath := convertToNestedPath(imagePath); convertedPath != imagePath {
+			if converted, convErr := os.ReadFile(convertedPath); convErr == nil {
+				return converted, nil
+			}
+		}
+	}
+	return data, err
+}
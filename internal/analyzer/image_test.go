@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageDataURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shot.png")
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	img.Set(5, 5, color.White)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name       string
+		format     string
+		crop       func(image.Point) image.Rectangle
+		wantPrefix string
+	}{
+		{"original", "original", nil, "data:image/png;base64,"},
+		{"jpeg", UploadFormatJPEG, nil, "data:image/jpeg;base64,"},
+		{"cropped keeps png", "original", func(image.Point) image.Rectangle { return image.Rect(0, 0, 10, 10) }, "data:image/png;base64,"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &OpenAI{UploadFormat: tt.format}
+			got, err := o.imageDataURL(path, tt.crop)
+			if err != nil {
+				t.Fatalf("imageDataURL() error = %v", err)
+			}
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("imageDataURL() = %.40s..., want prefix %q", got, tt.wantPrefix)
+			}
+		})
+	}
+
+	notImage := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notImage, []byte("hello"), 0644)
+	if _, err := (&OpenAI{}).imageDataURL(notImage, nil); err == nil {
+		t.Errorf("imageDataURL() of a text file should fail")
+	}
+}
+
+func TestCropImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	if got := cropImage(img, image.Rect(10, 10, 200, 30)).Bounds(); got != image.Rect(10, 10, 100, 30) {
+		t.Errorf("cropImage() bounds = %v, want clamped to the image", got)
+	}
+	if got := cropImage(img, image.Rectangle{}).Bounds(); got != img.Bounds() {
+		t.Errorf("cropImage() with empty region = %v, want full image", got)
+	}
+}
@@ -0,0 +1,29 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// lengthPlaceholder is replaced with the target length of the period level in the length template
+const lengthPlaceholder = "{{max_words}}"
+
+// applyLengthLimit appends the length instruction of a period level to the prompt and returns
+// the completion token limit to use. Levels without a configured target keep the prompt and
+// the default MaxCompletionTokens
+func (o *OpenAI) applyLengthLimit(prompt string, periodType string) (string, int) {
+	maxWords := o.SummaryLengths[periodType]
+	if maxWords <= 0 {
+		return prompt, o.MaxCompletionTokens
+	}
+
+	if o.SummaryLengthTemplate != "" {
+		prompt += "\n\n" + strings.ReplaceAll(o.SummaryLengthTemplate, lengthPlaceholder, strconv.Itoa(maxWords))
+	}
+
+	maxTokens := o.MaxCompletionTokens
+	if o.SummaryTokensPerWord > 0 {
+		maxTokens = int(float64(maxWords) * o.SummaryTokensPerWord)
+	}
+	return prompt, maxTokens
+}
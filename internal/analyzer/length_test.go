@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyLengthLimit(t *testing.T) {
+	o := newTestOpenAI("http://127.0.0.1:0")
+	o.SummaryLengthTemplate = "输出控制在 {{max_words}} 字以内"
+	o.SummaryLengths = map[string]int{"fifteenmin": 80, "day": 500}
+	o.SummaryTokensPerWord = 2
+
+	prompt, maxTokens := o.applyLengthLimit("prompt", "fifteenmin")
+	if !strings.HasSuffix(prompt, "输出控制在 80 字以内") {
+		t.Errorf("applyLengthLimit() prompt = %q, want length instruction", prompt)
+	}
+	if maxTokens != 160 {
+		t.Errorf("applyLengthLimit() maxTokens = %d, want 160", maxTokens)
+	}
+
+	// Levels without a target keep the prompt and the default token limit
+	prompt, maxTokens = o.applyLengthLimit("prompt", "week")
+	if prompt != "prompt" || maxTokens != 100 {
+		t.Errorf("applyLengthLimit(week) = %q, %d, want unchanged prompt and 100", prompt, maxTokens)
+	}
+
+	// A zero ratio only templates the prompt
+	o.SummaryTokensPerWord = 0
+	if _, maxTokens = o.applyLengthLimit("prompt", "day"); maxTokens != 100 {
+		t.Errorf("applyLengthLimit(day) maxTokens = %d, want 100", maxTokens)
+	}
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// NewNetworkTransport creates the transport for calls to the provider: an explicit proxy (otherwise
+// HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment), extra trusted CA certificates and an
+// optional client certificate for gateways requiring mutual TLS.
+// Returns nil if nothing is configured, meaning the default transport is used.
+func NewNetworkTransport(proxyURL, caFile, certFile, keyFile string) (http.RoundTripper, error) {
+	if proxyURL == "" && caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		// Private CAs are trusted in addition to the system ones, so public endpoints keep working
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be configured together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// ConfigureNetwork sets up the proxy and TLS settings; call it before ConfigureBackend, which
+// records through this transport or replaces it for offline modes
+func (o *OpenAI) ConfigureNetwork(proxyURL, caFile, certFile, keyFile string) error {
+	transport, err := NewNetworkTransport(proxyURL, caFile, certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	o.Transport = transport
+	return nil
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewNetworkTransport_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"私有网关"}}]}`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test server's certificate is not trusted by default
+	if _, err := http.Get(server.URL); err == nil {
+		t.Fatalf("request without CA file should fail")
+	}
+
+	o := newTestOpenAI(server.URL)
+	if err := o.ConfigureNetwork("", caFile, "", ""); err != nil {
+		t.Fatalf("ConfigureNetwork() error = %v", err)
+	}
+	if err := o.ConfigureBackend(BackendModeLive, "", ""); err != nil {
+		t.Fatalf("ConfigureBackend() error = %v", err)
+	}
+	got, err := o.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("AnalyzeBehavior() error = %v", err)
+	}
+	if got != "私有网关" {
+		t.Errorf("AnalyzeBehavior() = %q, want %q", got, "私有网关")
+	}
+}
+
+func TestNewNetworkTransport_Invalid(t *testing.T) {
+	if transport, err := NewNetworkTransport("", "", "", ""); transport != nil || err != nil {
+		t.Errorf("NewNetworkTransport() without settings = %v, %v, want default transport", transport, err)
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+
+	tests := []struct {
+		name                             string
+		proxy, caFile, certFile, keyFile string
+	}{
+		{"proxy without scheme", "proxy.corp:3128", "", "", ""},
+		{"missing CA file", "", filepath.Join(t.TempDir(), "missing.pem"), "", ""},
+		{"CA file without certificates", "", notPEM, "", ""},
+		{"client cert without key", "", "", notPEM, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNetworkTransport(tt.proxy, tt.caFile, tt.certFile, tt.keyFile); err == nil {
+				t.Errorf("NewNetworkTransport() should fail")
+			}
+		})
+	}
+}
@@ -3,16 +3,21 @@ package analyzer
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"stuff-time/internal/events"
+	"stuff-time/internal/idlescreen"
+	"stuff-time/internal/logger"
 )
 
 type OpenAI struct {
@@ -32,6 +37,10 @@ type OpenAI struct {
 	SummaryEnhancedTemplate string // Enhanced summary prompt template
 	SummaryContextPrefixTemplate string // Context prefix template
 	SummaryRollingTemplate string // Rolling summary prompt template
+	SummaryCitationTemplate string // Citation instruction for fifteenmin/hour/work-segment/day summaries, empty disables citations
+	SummaryLengthTemplate string // Length instruction template, {{max_words}} is replaced with the level's target length
+	SummaryLengths map[string]int // Target length (characters/words) per period level, missing levels are unlimited
+	SummaryTokensPerWord float64 // Completion token limit per target word, 0 keeps MaxCompletionTokens
 	
 	// Level-specific summary prompts
 	FifteenminPrompt string
@@ -45,12 +54,46 @@ type OpenAI struct {
 	// Analysis configuration (less frequent, complex task, stronger model)
 	AnalysisModel  string
 	AnalysisPrompt string
+
+	// Transport overrides the HTTP transport (mock/record/replay backends), nil uses the default
+	Transport http.RoundTripper
+
+	// UploadRecorder records what is sent to the provider (privacy report), nil disables recording
+	UploadRecorder UploadRecorder
+
+	// CallRecorder records token usage, latency and errors of every request (cost report), nil disables recording
+	CallRecorder CallRecorder
+
+	// Provider is ProviderOpenAI or ProviderAzure, see ConfigureProvider
+	Provider         string
+	APIVersion       string            // Azure api-version query parameter
+	AzureDeployments map[string]string // Azure deployment name per model, other models are used as deployment names
+
+	// API is APIChatCompletions or APIResponses, see ConfigureAPI
+	API         string
+	ImageDetail map[string]string // Image detail ("low", "high", "auto") per request purpose, missing purposes use the provider default
+
+	// Prompt guard, see ConfigurePromptGuard
+	MaxPromptTokens int    // Estimated prompt token limit, 0 disables the guard
+	PromptStrategy  string // PromptStrategyDropOldest, PromptStrategySummarize or PromptStrategyReject
+
+	// UploadFormat is the image format sent to the model: UploadFormatJPEG re-encodes, anything else
+	// sends the file as captured
+	UploadFormat string
+
+	// Retry is the retry policy of failed API calls
+	Retry RetryPolicy
 }
 
 type VisionRequest struct {
 	Model     string    `json:"model"`
 	Messages  []Message `json:"messages"`
 	MaxCompletionTokens int `json:"max_completion_tokens"`
+
+	// Not sent: describe the request in the upload log
+	Purpose    string   `json:"-"`
+	ImagePaths []string `json:"-"`
+	Level      string   `json:"-"` // Summary period type
 }
 
 type Message struct {
@@ -65,7 +108,8 @@ type ContentObject struct {
 }
 
 type ImageURL struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"` // "low", "high" or "auto", set from OpenAI.ImageDetail
 }
 
 type VisionResponse struct {
@@ -107,6 +151,7 @@ func NewOpenAI(apiKey, baseURL, model string, maxTokens int, prompt string, desk
 		SummaryRollingTemplate: summaryRolling,
 		AnalysisModel:  analysisModel,
 		AnalysisPrompt: analysisPrompt,
+		Retry:          DefaultRetryPolicy(),
 	}
 	
 	// Set level-specific prompts if provided
@@ -138,11 +183,19 @@ func NewOpenAI(apiKey, baseURL, model string, maxTokens int, prompt string, desk
 	return openAI
 }
 
+// NewHTTPClient creates an HTTP client using the configured transport
+func (o *OpenAI) NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: o.Transport,
+	}
+}
+
 // IsLockScreen quickly checks if the screenshot is a lock screen
 // Returns true if it's a lock screen, false otherwise
 // Uses a simple prompt with cheaper model to minimize cost
 func (o *OpenAI) IsLockScreen(imagePath string) (bool, error) {
-	imageData, err := encodeImageToBase64(imagePath)
+	imageURL, err := o.imageDataURL(imagePath, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to encode image: %w", err)
 	}
@@ -160,6 +213,8 @@ func (o *OpenAI) IsLockScreen(imagePath string) (bool, error) {
 	}
 
 	req := VisionRequest{
+		Purpose:   PurposeLockScreenDetection,
+		ImagePaths: []string{imagePath},
 		Model:     model,
 		MaxCompletionTokens: 50, // Allow brief explanation if needed
 		Messages: []Message{
@@ -173,7 +228,7 @@ func (o *OpenAI) IsLockScreen(imagePath string) (bool, error) {
 					{
 						Type: "image_url",
 						ImageURL: &ImageURL{
-							URL: fmt.Sprintf("data:image/png;base64,%s", imageData),
+							URL: imageURL,
 						},
 					},
 				},
@@ -187,8 +242,7 @@ func (o *OpenAI) IsLockScreen(imagePath string) (bool, error) {
 	}
 
 	// Check if response indicates lock screen
-	content = strings.ToLower(strings.TrimSpace(content))
-	return strings.Contains(content, "是") || strings.Contains(content, "yes"), nil
+	return idlescreen.IsYes(content), nil
 }
 
 // IsDesktopOrLockScreen quickly checks if the screenshot is desktop or lock screen
@@ -200,7 +254,7 @@ func (o *OpenAI) IsDesktopOrLockScreen(imagePath string) (bool, error) {
 		return false, nil
 	}
 
-	imageData, err := encodeImageToBase64(imagePath)
+	imageURL, err := o.imageDataURL(imagePath, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to encode image: %w", err)
 	}
@@ -215,6 +269,8 @@ func (o *OpenAI) IsDesktopOrLockScreen(imagePath string) (bool, error) {
 	}
 
 	req := VisionRequest{
+		Purpose:   PurposeDesktopDetection,
+		ImagePaths: []string{imagePath},
 		Model:     model,
 		MaxCompletionTokens: 50, // Allow brief explanation if needed
 		Messages: []Message{
@@ -228,7 +284,7 @@ func (o *OpenAI) IsDesktopOrLockScreen(imagePath string) (bool, error) {
 					{
 						Type: "image_url",
 						ImageURL: &ImageURL{
-							URL: fmt.Sprintf("data:image/png;base64,%s", imageData),
+							URL: imageURL,
 						},
 					},
 				},
@@ -242,17 +298,37 @@ func (o *OpenAI) IsDesktopOrLockScreen(imagePath string) (bool, error) {
 	}
 
 	// Check if response indicates desktop or lock screen
-	content = strings.ToLower(strings.TrimSpace(content))
-	return strings.Contains(content, "是") || strings.Contains(content, "yes"), nil
+	return idlescreen.IsYes(content), nil
 }
 
-func (o *OpenAI) AnalyzeScreenshot(imagePath string) (string, error) {
-	imageData, err := encodeImageToBase64(imagePath)
+// AnalyzeScreenshot analyzes a screenshot; window is the frontmost window at capture, passed to the model
+// as context, nil when unknown
+func (o *OpenAI) AnalyzeScreenshot(imagePath string, window *CaptureWindow) (string, error) {
+	imageURL, err := o.imageDataURL(imagePath, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to encode image: %w", err)
 	}
 
+	return o.analyzeImageURL(imagePath, imageURL, window)
+}
+
+// AnalyzeScreenshotCropped analyzes only a region of the screenshot; crop returns the region for the
+// decoded image size. The file on disk is left untouched, the full image is sent if the region is empty
+func (o *OpenAI) AnalyzeScreenshotCropped(imagePath string, window *CaptureWindow, crop func(size image.Point) image.Rectangle) (string, error) {
+	imageURL, err := o.imageDataURL(imagePath, crop)
+	if err != nil {
+		// e.g. a format without a decoder here, the full image can still be sent as captured
+		logger.GetLogger().Debugf("Failed to crop %s, analyzing full screenshot: %v", imagePath, err)
+		return o.AnalyzeScreenshot(imagePath, window)
+	}
+
+	return o.analyzeImageURL(imagePath, imageURL, window)
+}
+
+func (o *OpenAI) analyzeImageURL(imagePath, imageURL string, window *CaptureWindow) (string, error) {
 	req := VisionRequest{
+		Purpose:   PurposeScreenshotAnalysis,
+		ImagePaths: []string{imagePath},
 		Model:     o.Model,
 		MaxCompletionTokens: o.MaxCompletionTokens,
 		Messages: []Message{
@@ -261,12 +337,12 @@ func (o *OpenAI) AnalyzeScreenshot(imagePath string) (string, error) {
 				Content: []ContentObject{
 					{
 						Type: "text",
-						Text: o.Prompt,
+						Text: screenshotPrompt(o.Prompt, window),
 					},
 					{
 						Type: "image_url",
 						ImageURL: &ImageURL{
-							URL: fmt.Sprintf("data:image/png;base64,%s", imageData),
+							URL: imageURL,
 						},
 					},
 				},
@@ -274,77 +350,37 @@ func (o *OpenAI) AnalyzeScreenshot(imagePath string) (string, error) {
 		},
 	}
 
-	reqBody, err := json.Marshal(req)
+	endpoint, reqBody, err := o.EncodeRequest(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	endpoint := fmt.Sprintf("%s/chat/completions", o.BaseURL)
+	o.RecordUpload(endpoint, req)
 	httpReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.APIKey))
+	o.SetAuthHeader(httpReq)
 
-	client := &http.Client{}
+	client := o.NewHTTPClient(0)
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", NetworkError("failed to send request", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var visionResp VisionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&visionResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(visionResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
-	}
-
-	content := visionResp.Choices[0].Message.Content
-	if content == "" {
-		return "", fmt.Errorf("empty content in response")
-	}
-
-	return content, nil
-}
-
-func encodeImageToBase64(imagePath string) (string, error) {
-	file, err := os.Open(imagePath)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		// Try to convert old flat path to new nested path if file not found
-		if os.IsNotExist(err) {
-			convertedPath := convertToNestedPath(imagePath)
-			if convertedPath != imagePath {
-				file, err = os.Open(convertedPath)
-				if err == nil {
-					defer file.Close()
-					data, err := io.ReadAll(file)
-					if err != nil {
-						return "", err
-					}
-					return base64.StdEncoding.EncodeToString(data), nil
-				}
-			}
-		}
-		return "", err
+		return "", NetworkError("failed to read response", err)
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp.StatusCode, string(body), true)
 	}
 
-	return base64.StdEncoding.EncodeToString(data), nil
+	return o.DecodeResponse(body)
 }
 
 // convertToNestedPath converts old flat path format to new nested format with Q and W directories
@@ -475,11 +511,23 @@ func (o *OpenAI) GenerateSummaryWithContext(analysisText string, progressContext
 		enhancedPrompt = strings.ReplaceAll(enhancedPrompt, "简洁", "详细且全面")
 		enhancedPrompt += "\n\n" + o.SummaryEnhancedTemplate
 	}
+	if o.SummaryCitationTemplate != "" && len(periodType) > 0 && CitedPeriodTypes[periodType[0]] {
+		enhancedPrompt += "\n\n" + o.SummaryCitationTemplate
+	}
+	maxTokens := o.MaxCompletionTokens
+	if len(periodType) > 0 {
+		enhancedPrompt, maxTokens = o.applyLengthLimit(enhancedPrompt, periodType[0])
+	}
+	analysisText, err := o.fitInput(enhancedPrompt, analysisText, progressContext)
+	if err != nil {
+		return "", err
+	}
 	fullPrompt := fmt.Sprintf("%s\n\n截图分析信息：\n%s", enhancedPrompt, analysisText)
 
 	req := VisionRequest{
+		Purpose:   PurposeSummary,
 		Model:     o.SummaryModel,
-		MaxCompletionTokens: o.MaxCompletionTokens,
+		MaxCompletionTokens: maxTokens,
 		Messages: []Message{
 			{
 				Role: "user",
@@ -492,10 +540,22 @@ func (o *OpenAI) GenerateSummaryWithContext(analysisText string, progressContext
 			},
 		},
 	}
+	if len(periodType) > 0 {
+		req.Level = periodType[0]
+	}
 	
 	return o.callAPIWithContext(req, progressContext)
 }
 
+// CitedPeriodTypes are the summary levels whose claims carry screenshot citation markers and whose
+// reports render them as footnotes
+var CitedPeriodTypes = map[string]bool{
+	"fifteenmin":   true,
+	"hour":         true,
+	"work-segment": true,
+	"day":          true,
+}
+
 // GenerateRollingSummary generates a rolling summary that combines previous summary with new content
 // This implements progressive summarization: previous summary + new content -> compressed summary
 // Similar items are merged and compressed to avoid redundancy
@@ -526,13 +586,18 @@ func (o *OpenAI) GenerateRollingSummaryWithContext(previousSummary string, newCo
 	}
 	
 	if newContent != "" {
+		fitted, err := o.fitInput(inputText.String(), newContent, progressContext)
+		if err != nil {
+			return "", err
+		}
 		inputText.WriteString("=== 新增内容 ===\n\n")
-		inputText.WriteString(newContent)
+		inputText.WriteString(fitted)
 	}
 
 	fullPrompt := inputText.String()
 
 	req := VisionRequest{
+		Purpose:   PurposeSummary,
 		Model:     o.SummaryModel,
 		MaxCompletionTokens: o.MaxCompletionTokens,
 		Messages: []Message{
@@ -554,10 +619,15 @@ func (o *OpenAI) GenerateRollingSummaryWithContext(previousSummary string, newCo
 // AnalyzeBehavior performs deep behavior analysis and provides efficiency improvement suggestions
 // Uses stronger model (analysis_model) for less frequent, complex tasks
 func (o *OpenAI) AnalyzeBehavior(summaryText string) (string, error) {
+	summaryText, err := o.fitInput(o.AnalysisPrompt, summaryText, "")
+	if err != nil {
+		return "", err
+	}
 	// Combine analysis prompt with the summary text
 	fullPrompt := fmt.Sprintf("%s\n\n工作活动摘要：\n%s", o.AnalysisPrompt, summaryText)
 
 	req := VisionRequest{
+		Purpose:   PurposeBehaviorAnalysis,
 		Model:     o.AnalysisModel,
 		MaxCompletionTokens: o.MaxCompletionTokens,
 		Messages: []Message{
@@ -582,148 +652,58 @@ func (o *OpenAI) callAPI(req VisionRequest) (string, error) {
 }
 
 // callAPIWithContext calls the API with optional progress context for logging
+// Failed calls are retried according to o.Retry
 func (o *OpenAI) callAPIWithContext(req VisionRequest, progressContext string) (string, error) {
-	const maxRetries = 5 // 增加重试次数
-	const initialBackoff = 2 * time.Second
-	
-	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// 自适应退避策略
-			backoff := calculateBackoff(attempt, initialBackoff, lastErr)
-			fmt.Fprintf(os.Stderr, "time=\"%s\" level=info msg=\"Retrying API request (attempt %d/%d, backoff: %v, reason: %s)\"\n",
-				time.Now().Format("2006-01-02 15:04:05"), attempt+1, maxRetries+1, backoff, getErrorType(lastErr))
-			time.Sleep(backoff)
-		}
-		
-		result, err := o.callAPISingleWithContext(req, attempt == 0, progressContext)
-		if err == nil {
-			// 成功时记录，帮助调试
-			if attempt > 0 {
-				fmt.Fprintf(os.Stderr, "time=\"%s\" level=info msg=\"API request succeeded after %d retries\"\n",
-					time.Now().Format("2006-01-02 15:04:05"), attempt)
-			}
-			return result, nil
-		}
-		
-		lastErr = err
-		
-		// Check if error is retryable
-		if !isRetryableError(err) {
-			return "", err
-		}
-		
-		// 对于最后一次重试前，增加额外的等待时间
-		if attempt == maxRetries-1 {
-			fmt.Fprintf(os.Stderr, "time=\"%s\" level=warning msg=\"Last retry attempt, adding extra backoff time\"\n",
-				time.Now().Format("2006-01-02 15:04:05"))
-		}
-	}
-	
-	return "", fmt.Errorf("API call failed after %d retries: %w", maxRetries+1, lastErr)
-}
-
-// calculateBackoff 计算自适应退避时间
-func calculateBackoff(attempt int, initialBackoff time.Duration, lastErr error) time.Duration {
-	// 基础指数退避
-	baseBackoff := initialBackoff * time.Duration(1<<uint(attempt-1))
-	
-	if lastErr == nil {
-		return baseBackoff
-	}
-	
-	errStr := lastErr.Error()
-	
-	// 对于限流错误 (429)，使用更长的退避时间
-	if strings.Contains(errStr, "status 429") || strings.Contains(errStr, "rate limit") {
-		return baseBackoff * 3 // 限流时等待 3 倍时间
-	}
-	
-	// 对于超时错误，使用较长的退避时间
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "i/o timeout") {
-		return baseBackoff * 2 // 超时时等待 2 倍时间
+	var result string
+	attempt := 0
+	err := o.Retry.Retry(func() error {
+		var err error
+		result, err = o.callAPISingleWithContext(req, attempt == 0, progressContext)
+		attempt++
+		return err
+	}, func(retry int, backoff time.Duration, err error) {
+		logger.Throttledf(logrus.InfoLevel, "api-retry", "Retrying API request (retry %d/%d, backoff: %v, reason: %s)",
+			retry, o.Retry.MaxRetries, backoff, getErrorType(err))
+		logger.Count("api_retries")
+		events.Emit(events.LevelWarn, events.ComponentAnalyzer, "Retrying API request (retry %d/%d, backoff: %v, reason: %s)",
+			retry, o.Retry.MaxRetries, backoff, getErrorType(err))
+	})
+	if err != nil {
+		return "", err
 	}
-	
-	// 对于网络连接错误，使用较长的退避时间
-	if strings.Contains(errStr, "dial tcp") || strings.Contains(errStr, "connection refused") {
-		return baseBackoff * 2
+	if attempt > 1 {
+		logger.GetLogger().Debugf("API request succeeded after %d retries", attempt-1)
 	}
-	
-	// 其他错误使用基础退避
-	return baseBackoff
+	return result, nil
 }
 
 // getErrorType 获取错误类型的简短描述
 func getErrorType(err error) string {
-	if err == nil {
+	var apiErr *APIError
+	switch {
+	case err == nil:
 		return "unknown"
-	}
-	
-	errStr := err.Error()
-	
-	if strings.Contains(errStr, "status 429") || strings.Contains(errStr, "rate limit") {
+	case errors.Is(err, ErrRateLimited):
 		return "rate_limit"
-	}
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "i/o timeout") {
+	case isTimeout(err):
 		return "timeout"
-	}
-	if strings.Contains(errStr, "dial tcp") {
+	case errors.Is(err, ErrNetwork):
 		return "connection_failed"
-	}
-	if strings.Contains(errStr, "status 502") {
-		return "bad_gateway"
-	}
-	if strings.Contains(errStr, "status 503") {
-		return "service_unavailable"
-	}
-	if strings.Contains(errStr, "status 504") {
-		return "gateway_timeout"
-	}
-	if strings.Contains(errStr, "status 500") {
-		return "internal_server_error"
-	}
-	
-	return "other_error"
-}
-
-// isRetryableError checks if an error is retryable (temporary network/server errors)
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	
-	errStr := err.Error()
-	// Check for retryable HTTP status codes
-	retryableStatusCodes := []string{
-		"status 502", // Bad Gateway
-		"status 503", // Service Unavailable
-		"status 504", // Gateway Timeout
-		"status 429", // Too Many Requests
-		"status 500", // Internal Server Error
-	}
-	
-	for _, code := range retryableStatusCodes {
-		if strings.Contains(errStr, code) {
-			return true
-		}
-	}
-	
-	// Check for network errors
-	networkErrors := []string{
-		"failed to send request",
-		"timeout",
-		"connection reset",
-		"connection refused",
-		"no such host",
-	}
-	
-	for _, netErr := range networkErrors {
-		if strings.Contains(strings.ToLower(errStr), netErr) {
-			return true
+	case errors.Is(err, ErrVisionRejected):
+		return "vision_rejected"
+	case errors.As(err, &apiErr):
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway:
+			return "bad_gateway"
+		case http.StatusServiceUnavailable:
+			return "service_unavailable"
+		case http.StatusGatewayTimeout:
+			return "gateway_timeout"
+		case http.StatusInternalServerError:
+			return "internal_server_error"
 		}
 	}
-	
-	return false
+	return "other_error"
 }
 
 // callAPISingle makes a single API call without retry
@@ -732,23 +712,23 @@ func (o *OpenAI) callAPISingle(req VisionRequest, logProgress bool) (string, err
 }
 
 // callAPISingleWithContext makes a single API call with optional progress context
-func (o *OpenAI) callAPISingleWithContext(req VisionRequest, logProgress bool, progressContext string) (string, error) {
-	reqBody, err := json.Marshal(req)
+func (o *OpenAI) callAPISingleWithContext(req VisionRequest, logProgress bool, progressContext string) (result string, err error) {
+	endpoint, reqBody, err := o.EncodeRequest(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	endpoint := fmt.Sprintf("%s/chat/completions", o.BaseURL)
+	o.RecordUpload(endpoint, req)
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.APIKey))
+	o.SetAuthHeader(httpReq)
 
 	// Start progress logging in a goroutine
 	progressDone := make(chan bool)
@@ -762,11 +742,11 @@ func (o *OpenAI) callAPISingleWithContext(req VisionRequest, logProgress bool, p
 				case <-ticker.C:
 					elapsed := time.Since(startTime)
 					if progressContext != "" {
-						fmt.Fprintf(os.Stderr, "time=\"%s\" level=info msg=\"API request in progress (elapsed: %v, %s)\"\n",
-							time.Now().Format("2006-01-02 15:04:05"), elapsed.Round(time.Second), progressContext)
+						logger.Throttledf(logrus.InfoLevel, "api-progress", "API request in progress (elapsed: %v, %s)",
+							elapsed.Round(time.Second), progressContext)
 					} else {
-						fmt.Fprintf(os.Stderr, "time=\"%s\" level=info msg=\"API request in progress (elapsed: %v)\"\n",
-							time.Now().Format("2006-01-02 15:04:05"), elapsed.Round(time.Second))
+						logger.Throttledf(logrus.InfoLevel, "api-progress", "API request in progress (elapsed: %v)",
+							elapsed.Round(time.Second))
 					}
 				case <-progressDone:
 					return
@@ -777,41 +757,146 @@ func (o *OpenAI) callAPISingleWithContext(req VisionRequest, logProgress bool, p
 		}()
 	}
 
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
+	var usage Usage
+	start := time.Now()
+	defer func() {
+		o.RecordCall(req, usage, time.Since(start), err)
+	}()
+
+	client := o.NewHTTPClient(5 * time.Minute)
 	resp, err := client.Do(httpReq)
 	if logProgress {
 		close(progressDone)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", NetworkError("failed to send request", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", NetworkError("failed to read response", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return "", newAPIError(resp.StatusCode, string(body), hasImageContent(req))
 	}
 
-	var visionResp VisionResponse
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&visionResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	usage = DecodeUsage(body)
+	return o.DecodeResponse(body)
+}
+
+
+// ExtractDeliverables asks the summary model to extract concrete deliverables from analysis records
+// Returns the raw model output (expected to be a JSON array)
+func (o *OpenAI) ExtractDeliverables(prompt string, analysisText string) (string, error) {
+	analysisText, err := o.fitInput(prompt, analysisText, "")
+	if err != nil {
+		return "", err
+	}
+	fullPrompt := fmt.Sprintf("%s\n\n截图分析记录：\n%s", prompt, analysisText)
+
+	req := VisionRequest{
+		Purpose:   PurposeDeliverables,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: o.MaxCompletionTokens,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: fullPrompt,
+					},
+				},
+			},
+		},
+	}
+
+	return o.callAPI(req)
+}
+
+// GenerateResearchLog asks the summary model to turn reading-related analysis records into a research log
+func (o *OpenAI) GenerateResearchLog(prompt string, analysisText string) (string, error) {
+	analysisText, err := o.fitInput(prompt, analysisText, "")
+	if err != nil {
+		return "", err
+	}
+	fullPrompt := fmt.Sprintf("%s\n\n阅读相关的截图分析记录：\n%s", prompt, analysisText)
+
+	req := VisionRequest{
+		Purpose:   PurposeResearchLog,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: o.MaxCompletionTokens,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: fullPrompt,
+					},
+				},
+			},
+		},
 	}
 
-	if len(visionResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+	return o.callAPI(req)
+}
+
+// SplitSummary asks the summary model to restructure an oversized summary into themed sections
+// below a short executive summary
+func (o *OpenAI) SplitSummary(prompt string, summary string) (string, error) {
+	summary, err := o.fitInput(prompt, summary, "")
+	if err != nil {
+		return "", err
 	}
+	fullPrompt := fmt.Sprintf("%s\n\n原总结：\n%s", prompt, summary)
 
-	content := visionResp.Choices[0].Message.Content
-	if content == "" {
-		return "", fmt.Errorf("empty content in response")
+	req := VisionRequest{
+		Purpose:   PurposeSummarySections,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: o.MaxCompletionTokens,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: fullPrompt,
+					},
+				},
+			},
+		},
 	}
 
-	return content, nil
+	return o.callAPI(req)
 }
 
+// GenerateMeetingNote asks the summary model to synthesize a meeting note from the analyses of a meeting block
+func (o *OpenAI) GenerateMeetingNote(prompt string, analysisText string) (string, error) {
+	analysisText, err := o.fitInput(prompt, analysisText, "")
+	if err != nil {
+		return "", err
+	}
+	fullPrompt := fmt.Sprintf("%s\n\n会议期间的截图分析记录：\n%s", prompt, analysisText)
+
+	req := VisionRequest{
+		Purpose:   PurposeMeetingNote,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: o.MaxCompletionTokens,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: fullPrompt,
+					},
+				},
+			},
+		},
+	}
+
+	return o.callAPI(req)
+}
@@ -0,0 +1,25 @@
+package analyzer
+
+// Ping sends a minimal text request to the summary model to check that the provider is reachable
+// and the credentials are accepted; the reply itself isn't checked
+func (o *OpenAI) Ping() error {
+	req := VisionRequest{
+		Purpose:             PurposeHealthCheck,
+		Model:               o.SummaryModel,
+		MaxCompletionTokens: 16,
+		Messages: []Message{
+			{
+				Role: "user",
+				Content: []ContentObject{
+					{
+						Type: "text",
+						Text: "Reply with OK.",
+					},
+				},
+			},
+		},
+	}
+
+	_, err := o.callAPI(req)
+	return err
+}
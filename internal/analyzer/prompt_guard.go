@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"stuff-time/internal/logger"
+)
+
+// Strategies applied when the input of a request does not fit the prompt token limit
+const (
+	PromptStrategyDropOldest = "drop_oldest" // Drop the oldest records (default)
+	PromptStrategySummarize  = "summarize"   // Compress the overflowing oldest records with extra summary calls
+	PromptStrategyReject     = "reject"      // Fail the request
+)
+
+// ErrPromptTooLarge is returned when a prompt exceeds the token limit and cannot be shortened (not retryable)
+var ErrPromptTooLarge = errors.New("prompt too large")
+
+// overflowSummaryPrompt compresses records dropped by the summarize strategy
+const overflowSummaryPrompt = "以下是较早的截图分析记录，内容超出了模型上下文。请压缩为简短要点，保留时间、项目、关键操作和产出，不要添加记录中没有的信息。"
+
+// EstimateTokens roughly estimates the token count of text: one token per CJK character, four
+// characters per token otherwise. It errs on the high side for mixed text, which is what a guard wants
+func EstimateTokens(text string) int {
+	var wide, narrow int
+	for _, r := range text {
+		if r >= 0x2E80 {
+			wide++
+		} else {
+			narrow++
+		}
+	}
+	return wide + (narrow+3)/4
+}
+
+// ConfigurePromptGuard sets the prompt token limit (0 disables the guard) and the truncation strategy
+func (o *OpenAI) ConfigurePromptGuard(maxPromptTokens int, strategy string) error {
+	switch strategy {
+	case "":
+		strategy = PromptStrategyDropOldest
+	case PromptStrategyDropOldest, PromptStrategySummarize, PromptStrategyReject:
+	default:
+		return fmt.Errorf("unknown prompt strategy: %s (must be '%s', '%s' or '%s')",
+			strategy, PromptStrategyDropOldest, PromptStrategySummarize, PromptStrategyReject)
+	}
+	if maxPromptTokens < 0 {
+		return fmt.Errorf("max prompt tokens must be non-negative, got %d", maxPromptTokens)
+	}
+	o.MaxPromptTokens = maxPromptTokens
+	o.PromptStrategy = strategy
+	return nil
+}
+
+// fitInput shortens input (records one per line, oldest first) so that it fits the prompt limit
+// together with the instructions, according to the configured strategy
+func (o *OpenAI) fitInput(instructions, input, progressContext string) (string, error) {
+	if o.MaxPromptTokens <= 0 {
+		return input, nil
+	}
+	budget := o.MaxPromptTokens - EstimateTokens(instructions)
+	tokens := EstimateTokens(input)
+	if tokens <= budget {
+		return input, nil
+	}
+	if budget <= 0 || o.PromptStrategy == PromptStrategyReject {
+		return "", fmt.Errorf("%w: about %d tokens, limit %d", ErrPromptTooLarge, tokens+EstimateTokens(instructions), o.MaxPromptTokens)
+	}
+
+	lines := strings.Split(input, "\n")
+	if o.PromptStrategy == PromptStrategySummarize {
+		// Keep the newest three quarters of the budget verbatim, the rest holds the compressed overflow
+		overflow, kept := splitOldest(lines, budget*3/4)
+		summary, err := o.summarizeOverflow(overflow, budget/4, progressContext)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to summarize overflowing records, dropping them instead: %v", err)
+		} else {
+			lines = append([]string{"较早记录摘要（超出模型上下文，已压缩）：", summary, ""}, kept...)
+		}
+	}
+
+	dropped, kept := splitOldest(lines, budget-EstimateTokens(droppedNotice(len(lines))))
+	if len(dropped) > 0 {
+		logger.GetLogger().Warnf("Prompt of about %d tokens exceeds limit %d, dropped %d oldest line(s)", tokens, o.MaxPromptTokens, len(dropped))
+		kept = append([]string{droppedNotice(len(dropped))}, kept...)
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// summarizeOverflow compresses lines into a summary of about budget tokens, chunk by chunk
+func (o *OpenAI) summarizeOverflow(lines []string, budget int, progressContext string) (string, error) {
+	chunkBudget := o.MaxPromptTokens/2 - EstimateTokens(overflowSummaryPrompt)
+	var chunks []string
+	for len(lines) > 0 {
+		chunk, rest := splitNewest(lines, chunkBudget)
+		if len(chunk) == 0 {
+			// A single line larger than the chunk, cut it
+			chunk, rest = []string{truncateToTokens(lines[0], chunkBudget)}, lines[1:]
+		}
+		chunks = append(chunks, strings.Join(chunk, "\n"))
+		lines = rest
+	}
+
+	maxTokens := o.MaxCompletionTokens
+	if perChunk := budget / len(chunks); perChunk > 0 && (maxTokens <= 0 || perChunk < maxTokens) {
+		maxTokens = perChunk
+	}
+	var summaries []string
+	for _, chunk := range chunks {
+		req := VisionRequest{
+			Purpose:             PurposeSummary,
+			Model:               o.SummaryModel,
+			MaxCompletionTokens: maxTokens,
+			Messages: []Message{
+				{
+					Role:    "user",
+					Content: []ContentObject{{Type: "text", Text: overflowSummaryPrompt + "\n\n" + chunk}},
+				},
+			},
+		}
+		summary, err := o.callAPIWithContext(req, progressContext)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, strings.TrimSpace(summary))
+	}
+	return strings.Join(summaries, "\n"), nil
+}
+
+// checkPromptSize rejects requests whose text still exceeds the prompt limit (images are not counted)
+func (o *OpenAI) checkPromptSize(req VisionRequest) error {
+	if o.MaxPromptTokens <= 0 {
+		return nil
+	}
+	tokens := 0
+	for _, msg := range req.Messages {
+		for _, c := range msg.Content {
+			tokens += EstimateTokens(c.Text)
+		}
+	}
+	if tokens > o.MaxPromptTokens {
+		return fmt.Errorf("%w: about %d tokens, limit %d", ErrPromptTooLarge, tokens, o.MaxPromptTokens)
+	}
+	return nil
+}
+
+// splitOldest splits lines into the oldest ones that do not fit and the newest ones fitting budget tokens
+func splitOldest(lines []string, budget int) ([]string, []string) {
+	i := len(lines)
+	used := 0
+	for i > 0 {
+		t := EstimateTokens(lines[i-1]) + 1
+		if used+t > budget {
+			break
+		}
+		used += t
+		i--
+	}
+	return lines[:i], lines[i:]
+}
+
+// splitNewest splits lines into the oldest ones fitting budget tokens and the rest
+func splitNewest(lines []string, budget int) ([]string, []string) {
+	i := 0
+	used := 0
+	for i < len(lines) {
+		t := EstimateTokens(lines[i]) + 1
+		if used+t > budget {
+			break
+		}
+		used += t
+		i++
+	}
+	return lines[:i], lines[i:]
+}
+
+func truncateToTokens(line string, budget int) string {
+	runes := []rune(line)
+	for len(runes) > 0 && EstimateTokens(string(runes)) > budget {
+		runes = runes[:len(runes)*9/10]
+	}
+	return string(runes)
+}
+
+func droppedNotice(n int) string {
+	return fmt.Sprintf("（较早的 %d 行记录超出模型上下文，已省略）", n)
+}
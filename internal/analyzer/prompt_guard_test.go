@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":               0,
+		"abcd":           1,
+		"abcde":          2,
+		"编写代码":           4,
+		"编写 code review": 2 + 3,
+	}
+	for text, want := range tests {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestFitInput(t *testing.T) {
+	var records []string
+	for i := 0; i < 100; i++ {
+		records = append(records, fmt.Sprintf("[%02d] 用户在编辑器中编写代码", i))
+	}
+	input := strings.Join(records, "\n")
+
+	o := newTestOpenAI("http://127.0.0.1:0")
+	if err := o.ConfigurePromptGuard(0, ""); err != nil {
+		t.Fatalf("ConfigurePromptGuard() error = %v", err)
+	}
+	if got, _ := o.fitInput("总结", input, ""); got != input {
+		t.Errorf("fitInput() with the guard disabled should keep the input")
+	}
+
+	if err := o.ConfigurePromptGuard(300, PromptStrategyDropOldest); err != nil {
+		t.Fatalf("ConfigurePromptGuard() error = %v", err)
+	}
+	got, err := o.fitInput("总结", input, "")
+	if err != nil {
+		t.Fatalf("fitInput() error = %v", err)
+	}
+	if EstimateTokens(got) > 300 || !strings.HasSuffix(got, records[99]) || strings.Contains(got, records[0]) || !strings.Contains(got, "已省略") {
+		t.Errorf("fitInput() should keep the newest records with a notice, got %q", got)
+	}
+
+	if err := o.ConfigurePromptGuard(300, PromptStrategyReject); err != nil {
+		t.Fatalf("ConfigurePromptGuard() error = %v", err)
+	}
+	if _, err := o.fitInput("总结", input, ""); !errors.Is(err, ErrPromptTooLarge) {
+		t.Errorf("fitInput() error = %v, want ErrPromptTooLarge", err)
+	}
+	if IsRetryable(fmt.Errorf("wrapped: %w", ErrPromptTooLarge)) {
+		t.Errorf("ErrPromptTooLarge should not be retryable")
+	}
+
+	if err := o.ConfigurePromptGuard(300, PromptStrategySummarize); err != nil {
+		t.Fatalf("ConfigurePromptGuard() error = %v", err)
+	}
+	if err := o.ConfigureBackend(BackendModeMock, "", "早上在写代码"); err != nil {
+		t.Fatalf("ConfigureBackend() error = %v", err)
+	}
+	// Few enough records that the compressed overflow fits next to the kept ones
+	got, err = o.fitInput("总结", strings.Join(records[70:], "\n"), "")
+	if err != nil {
+		t.Fatalf("fitInput() error = %v", err)
+	}
+	if EstimateTokens(got) > 300 || !strings.Contains(got, "早上在写代码") || strings.Contains(got, records[70]) || !strings.HasSuffix(got, records[99]) {
+		t.Errorf("fitInput() should prepend the overflow summary, got %q", got)
+	}
+
+	if err := o.ConfigurePromptGuard(300, "truncate_middle"); err == nil {
+		t.Errorf("ConfigurePromptGuard() with an unknown strategy should fail")
+	}
+}
+
+func TestEncodeRequestPromptSize(t *testing.T) {
+	req := VisionRequest{Model: "test-model", Messages: []Message{{Role: "user",
+		Content: []ContentObject{{Type: "text", Text: strings.Repeat("编写代码", 100)}}}}}
+	for _, api := range []string{APIChatCompletions, APIResponses} {
+		o := newTestOpenAI("http://127.0.0.1:0")
+		if err := o.ConfigureAPI(api, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.ConfigurePromptGuard(300, PromptStrategyDropOldest); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := o.EncodeRequest(req); !errors.Is(err, ErrPromptTooLarge) {
+			t.Errorf("%s: EncodeRequest() error = %v, want ErrPromptTooLarge", api, err)
+		}
+		if err := o.ConfigurePromptGuard(1000, PromptStrategyDropOldest); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := o.EncodeRequest(req); err != nil {
+			t.Errorf("%s: EncodeRequest() within the limit error = %v", api, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// API providers
+const (
+	ProviderOpenAI = "openai" // OpenAI or any OpenAI-compatible endpoint (default)
+	ProviderAzure  = "azure"  // Azure OpenAI: deployment URLs, api-key header, api-version parameter
+)
+
+// DefaultAzureAPIVersion is used when no api-version is configured for Azure OpenAI
+const DefaultAzureAPIVersion = "2024-06-01"
+
+// ConfigureProvider selects the API provider; for Azure, BaseURL is the resource endpoint
+// (https://<resource>.openai.azure.com) and models are mapped to deployment names
+func (o *OpenAI) ConfigureProvider(provider, apiVersion string, deployments map[string]string) error {
+	switch provider {
+	case "", ProviderOpenAI:
+		o.Provider = ProviderOpenAI
+	case ProviderAzure:
+		if o.BaseURL == "" || strings.Contains(o.BaseURL, "api.openai.com") {
+			return fmt.Errorf("base_url must be the Azure OpenAI resource endpoint (https://<resource>.openai.azure.com)")
+		}
+		if apiVersion == "" {
+			apiVersion = DefaultAzureAPIVersion
+		}
+		o.Provider = ProviderAzure
+		o.APIVersion = apiVersion
+		o.AzureDeployments = deployments
+	default:
+		return fmt.Errorf("unknown provider: %s", provider)
+	}
+	return nil
+}
+
+// ChatCompletionsURL returns the chat completions endpoint for a request using model
+func (o *OpenAI) ChatCompletionsURL(model string) string {
+	return o.modelURL(model, "chat/completions")
+}
+
+// EmbeddingsURL returns the embeddings endpoint for a request using model
+func (o *OpenAI) EmbeddingsURL(model string) string {
+	return o.modelURL(model, "embeddings")
+}
+
+// modelURL returns the endpoint of an operation; Azure routes requests to the model's deployment
+func (o *OpenAI) modelURL(model, operation string) string {
+	if o.Provider != ProviderAzure {
+		return fmt.Sprintf("%s/%s", o.BaseURL, operation)
+	}
+
+	deployment := model
+	if d, ok := o.AzureDeployments[model]; ok && d != "" {
+		deployment = d
+	}
+	base := strings.TrimSuffix(strings.TrimRight(o.BaseURL, "/"), "/openai")
+	return fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s",
+		base, url.PathEscape(deployment), operation, url.QueryEscape(o.APIVersion))
+}
+
+// SetAuthHeader authenticates the request with the provider's header
+func (o *OpenAI) SetAuthHeader(req *http.Request) {
+	if o.Provider == ProviderAzure {
+		req.Header.Set("api-key", o.APIKey)
+		return
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.APIKey))
+}
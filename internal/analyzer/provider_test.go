@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChatCompletionsURL(t *testing.T) {
+	o := newTestOpenAI("https://api.openai.com/v1")
+	if got := o.ChatCompletionsURL("gpt-4o"); got != "https://api.openai.com/v1/chat/completions" {
+		t.Errorf("ChatCompletionsURL() = %q", got)
+	}
+
+	o = newTestOpenAI("https://corp.openai.azure.com/")
+	if err := o.ConfigureProvider(ProviderAzure, "", map[string]string{"gpt-4o": "vision-prod"}); err != nil {
+		t.Fatalf("ConfigureProvider() error = %v", err)
+	}
+	tests := map[string]string{
+		"gpt-4o":      "https://corp.openai.azure.com/openai/deployments/vision-prod/chat/completions?api-version=" + DefaultAzureAPIVersion,
+		"gpt-4o-mini": "https://corp.openai.azure.com/openai/deployments/gpt-4o-mini/chat/completions?api-version=" + DefaultAzureAPIVersion,
+	}
+	for model, want := range tests {
+		if got := o.ChatCompletionsURL(model); got != want {
+			t.Errorf("ChatCompletionsURL(%q) = %q, want %q", model, got, want)
+		}
+	}
+
+	if err := newTestOpenAI("https://api.openai.com/v1").ConfigureProvider(ProviderAzure, "", nil); err == nil {
+		t.Errorf("ConfigureProvider(azure) with the OpenAI base URL should fail")
+	}
+	if err := o.ConfigureProvider("bedrock", "", nil); err == nil {
+		t.Errorf("ConfigureProvider() with an unknown provider should fail")
+	}
+}
+
+func TestAzureRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/analysis-model/chat/completions" || r.URL.Query().Get("api-version") != "2024-10-21" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("api-key") != "test-key" || r.Header.Get("Authorization") != "" {
+			t.Errorf("request should authenticate with the api-key header only")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"Azure 回复"}}]}`))
+	}))
+	defer server.Close()
+
+	o := newTestOpenAI(server.URL)
+	if err := o.ConfigureProvider(ProviderAzure, "2024-10-21", nil); err != nil {
+		t.Fatalf("ConfigureProvider() error = %v", err)
+	}
+	got, err := o.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("AnalyzeBehavior() error = %v", err)
+	}
+	if got != "Azure 回复" {
+		t.Errorf("AnalyzeBehavior() = %q, want %q", got, "Azure 回复")
+	}
+}
@@ -0,0 +1,32 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EncodeRequest returns the endpoint and JSON body of req for the configured API
+// Every request passes through here, so the prompt size is checked for both API styles
+func (o *OpenAI) EncodeRequest(req VisionRequest) (string, []byte, error) {
+	if err := o.checkPromptSize(req); err != nil {
+		return "", nil, err
+	}
+	detail := o.ImageDetail[req.Purpose]
+
+	if o.API == APIResponses {
+		body, err := encodeResponsesRequest(req, detail)
+		if err != nil {
+			return "", nil, err
+		}
+		return o.responsesURL(), body, nil
+	}
+
+	if detail != "" {
+		req.Messages = withImageDetail(req.Messages, detail)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return o.ChatCompletionsURL(req.Model), body, nil
+}
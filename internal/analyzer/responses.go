@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// API styles for sending requests
+const (
+	APIChatCompletions = "chat_completions" // /chat/completions (default)
+	APIResponses       = "responses"        // Responses API (/responses)
+)
+
+// Image detail levels; "low" sends a fixed small rendition of the image, far cheaper for classification
+var imageDetails = map[string]bool{"low": true, "high": true, "auto": true}
+
+type responsesRequest struct {
+	Model           string           `json:"model"`
+	Input           []responsesInput `json:"input"`
+	MaxOutputTokens int              `json:"max_output_tokens,omitempty"`
+}
+
+type responsesInput struct {
+	Role    string             `json:"role"`
+	Content []responsesContent `json:"content"`
+}
+
+type responsesContent struct {
+	Type     string `json:"type"` // "input_text" or "input_image"
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type responsesResponse struct {
+	Output []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"output"`
+}
+
+// ConfigureAPI selects the API style and the image detail per request purpose (e.g. "desktop_detection": "low")
+func (o *OpenAI) ConfigureAPI(api string, imageDetail map[string]string) error {
+	switch api {
+	case "", APIChatCompletions:
+		o.API = APIChatCompletions
+	case APIResponses:
+		o.API = APIResponses
+	default:
+		return fmt.Errorf("unknown api: %s (must be '%s' or '%s')", api, APIChatCompletions, APIResponses)
+	}
+	for purpose, detail := range imageDetail {
+		if !imageDetails[detail] {
+			return fmt.Errorf("image detail for %s must be 'low', 'high' or 'auto', got '%s'", purpose, detail)
+		}
+	}
+	o.ImageDetail = imageDetail
+	return nil
+}
+
+// encodeResponsesRequest converts req to the Responses API, with detail as the image detail
+func encodeResponsesRequest(req VisionRequest, detail string) ([]byte, error) {
+	rr := responsesRequest{Model: req.Model, MaxOutputTokens: req.MaxCompletionTokens}
+	for _, msg := range req.Messages {
+		input := responsesInput{Role: msg.Role}
+		for _, c := range msg.Content {
+			if c.ImageURL != nil {
+				input.Content = append(input.Content, responsesContent{Type: "input_image", ImageURL: c.ImageURL.URL, Detail: detail})
+			} else {
+				input.Content = append(input.Content, responsesContent{Type: "input_text", Text: c.Text})
+			}
+		}
+		rr.Input = append(rr.Input, input)
+	}
+	body, err := json.Marshal(rr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return body, nil
+}
+
+// DecodeResponse returns the text content of a successful response body
+func (o *OpenAI) DecodeResponse(body []byte) (string, error) {
+	if o.API == APIResponses {
+		var resp responsesResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+		var sb strings.Builder
+		for _, item := range resp.Output {
+			if item.Type != "message" {
+				continue
+			}
+			for _, c := range item.Content {
+				if c.Type == "output_text" {
+					sb.WriteString(c.Text)
+				}
+			}
+		}
+		if sb.Len() == 0 {
+			return "", fmt.Errorf("empty content in response")
+		}
+		return sb.String(), nil
+	}
+
+	var visionResp VisionResponse
+	if err := json.Unmarshal(body, &visionResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(visionResp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+	content := visionResp.Choices[0].Message.Content
+	if content == "" {
+		return "", fmt.Errorf("empty content in response")
+	}
+	return content, nil
+}
+
+func (o *OpenAI) responsesURL() string {
+	if o.Provider != ProviderAzure {
+		return fmt.Sprintf("%s/responses", o.BaseURL)
+	}
+	base := strings.TrimSuffix(strings.TrimRight(o.BaseURL, "/"), "/openai")
+	return fmt.Sprintf("%s/openai/responses?api-version=%s", base, url.QueryEscape(o.APIVersion))
+}
+
+// withImageDetail copies messages with the detail set on every image, leaving the caller's request untouched
+func withImageDetail(messages []Message, detail string) []Message {
+	copied := make([]Message, len(messages))
+	for i, msg := range messages {
+		copied[i] = Message{Role: msg.Role, Content: make([]ContentObject, len(msg.Content))}
+		for j, c := range msg.Content {
+			if c.ImageURL != nil {
+				c.ImageURL = &ImageURL{URL: c.ImageURL.URL, Detail: detail}
+			}
+			copied[i].Content[j] = c
+		}
+	}
+	return copied
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEncodeRequest_ImageDetail(t *testing.T) {
+	o := newTestOpenAI("https://api.openai.com/v1")
+	if err := o.ConfigureAPI(APIChatCompletions, map[string]string{PurposeDesktopDetection: "low"}); err != nil {
+		t.Fatalf("ConfigureAPI() error = %v", err)
+	}
+
+	image := &ImageURL{URL: "data:image/png;base64,AAAA"}
+	req := VisionRequest{
+		Purpose:  PurposeDesktopDetection,
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: "user", Content: []ContentObject{{Type: "image_url", ImageURL: image}}}},
+	}
+	_, body, err := o.EncodeRequest(req)
+	if err != nil {
+		t.Fatalf("EncodeRequest() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"detail":"low"`) {
+		t.Errorf("EncodeRequest() body = %s, want detail low", body)
+	}
+	if image.Detail != "" {
+		t.Errorf("EncodeRequest() should not modify the caller's request")
+	}
+
+	req.Purpose = PurposeScreenshotAnalysis
+	if _, body, _ := o.EncodeRequest(req); strings.Contains(string(body), "detail") {
+		t.Errorf("EncodeRequest() body = %s, want no detail for unconfigured purpose", body)
+	}
+
+	if err := o.ConfigureAPI(APIChatCompletions, map[string]string{PurposeDesktopDetection: "tiny"}); err == nil {
+		t.Errorf("ConfigureAPI() with an invalid detail should fail")
+	}
+}
+
+func TestRoute(t *testing.T) {
+	o := newTestOpenAI("https://api.openai.com/v1")
+	if err := o.ConfigureAPI(APIChatCompletions, map[string]string{PurposeDesktopDetection: "low"}); err != nil {
+		t.Fatalf("ConfigureAPI() error = %v", err)
+	}
+
+	routed, err := o.Route("ocr-model", "逐字识别代码", "high")
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if routed.Model != "ocr-model" || routed.Prompt != "prompt\n\n逐字识别代码" {
+		t.Errorf("Route() model = %q, prompt = %q", routed.Model, routed.Prompt)
+	}
+	if routed.ImageDetail[PurposeScreenshotAnalysis] != "high" || routed.ImageDetail[PurposeDesktopDetection] != "low" {
+		t.Errorf("Route() image detail = %v", routed.ImageDetail)
+	}
+	if o.Model != "test-model" || o.Prompt != "prompt" || o.ImageDetail[PurposeScreenshotAnalysis] != "" {
+		t.Errorf("Route() should not modify the base analyzer")
+	}
+
+	if _, err := o.Route("", "", "tiny"); err == nil {
+		t.Errorf("Route() with an invalid detail should fail")
+	}
+}
+
+func TestResponsesAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/responses" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req responsesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "analysis-model" || len(req.Input) != 1 || req.Input[0].Content[0].Type != "input_text" {
+			t.Errorf("unexpected request %+v", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":[{"type":"reasoning","content":[]},{"type":"message","content":[{"type":"output_text","text":"Responses 回复"}]}]}`))
+	}))
+	defer server.Close()
+
+	o := newTestOpenAI(server.URL)
+	if err := o.ConfigureAPI(APIResponses, nil); err != nil {
+		t.Fatalf("ConfigureAPI() error = %v", err)
+	}
+	got, err := o.AnalyzeBehavior("summary")
+	if err != nil {
+		t.Fatalf("AnalyzeBehavior() error = %v", err)
+	}
+	if got != "Responses 回复" {
+		t.Errorf("AnalyzeBehavior() = %q, want %q", got, "Responses 回复")
+	}
+
+	// The mock backend answers in the Responses format too
+	if err := o.ConfigureBackend(BackendModeMock, "", "固定回复"); err != nil {
+		t.Fatalf("ConfigureBackend() error = %v", err)
+	}
+	if got, err := o.AnalyzeBehavior("summary"); err != nil || got != "固定回复" {
+		t.Errorf("AnalyzeBehavior() with mock backend = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Error classes with their own retry settings
+const (
+	RetryClassRateLimit   = "rate_limit"   // 429 responses
+	RetryClassServerError = "server_error" // 5xx responses
+	RetryClassTimeout     = "timeout"      // Request timeouts
+	RetryClassNetwork     = "network"      // Other send/read failures
+)
+
+// RetryPolicy decides how often a failed API call is retried and how long to wait in between
+// The wait before retry n is InitialBackoff * Multiplier^(n-1), capped at MaxBackoff
+type RetryPolicy struct {
+	MaxRetries     int           // Retries after the first attempt, 0 disables retries
+	InitialBackoff time.Duration // Wait before the first retry
+	Multiplier     float64       // Growth of the wait per retry, values below 1 keep the wait constant
+	MaxBackoff     time.Duration // Upper bound of a single wait, 0 is unbounded
+
+	// Classes overrides the settings per error class (RetryClassRateLimit, ...)
+	Classes map[string]RetryOverride
+}
+
+// RetryOverride overrides settings of a RetryPolicy; zero values keep the policy's
+type RetryOverride struct {
+	MaxRetries     *int
+	InitialBackoff time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy retries up to 5 times starting at 2s, waiting three times longer on rate limits
+// and twice as long on timeouts and network failures
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     5,
+		InitialBackoff: 2 * time.Second,
+		Multiplier:     2,
+		MaxBackoff:     2 * time.Minute,
+		Classes: map[string]RetryOverride{
+			RetryClassRateLimit: {InitialBackoff: 6 * time.Second},
+			RetryClassTimeout:   {InitialBackoff: 4 * time.Second},
+			RetryClassNetwork:   {InitialBackoff: 4 * time.Second},
+		},
+	}
+}
+
+// Override returns the policy with the override applied
+func (p RetryPolicy) Override(o RetryOverride) RetryPolicy {
+	if o.MaxRetries != nil {
+		p.MaxRetries = *o.MaxRetries
+	}
+	if o.InitialBackoff > 0 {
+		p.InitialBackoff = o.InitialBackoff
+	}
+	if o.Multiplier > 0 {
+		p.Multiplier = o.Multiplier
+	}
+	return p
+}
+
+// RetryClass returns the error class of a retryable error, empty for errors that aren't retried
+func RetryClass(err error) string {
+	switch {
+	case !IsRetryable(err):
+		return ""
+	case errors.Is(err, ErrRateLimited):
+		return RetryClassRateLimit
+	case isTimeout(err):
+		return RetryClassTimeout
+	case errors.Is(err, ErrNetwork):
+		return RetryClassNetwork
+	default:
+		return RetryClassServerError
+	}
+}
+
+// Backoff returns whether an error after the given number of retries (0 for the first attempt) is
+// retried again, and the wait before doing so
+func (p RetryPolicy) Backoff(retries int, err error) (time.Duration, bool) {
+	class := RetryClass(err)
+	if class == "" {
+		return 0, false
+	}
+	if o, ok := p.Classes[class]; ok {
+		p = p.Override(o)
+	}
+	if retries >= p.MaxRetries {
+		return 0, false
+	}
+
+	multiplier := math.Max(p.Multiplier, 1)
+	wait := time.Duration(float64(p.InitialBackoff) * math.Pow(multiplier, float64(retries)))
+	if p.MaxBackoff > 0 && (wait > p.MaxBackoff || wait < 0) {
+		wait = p.MaxBackoff
+	}
+	return wait, true
+}
+
+// Retry calls fn until it succeeds, fails with an error that isn't retryable or the retries of the
+// error's class are used up; onRetry (optional) is called before each wait with the retry number
+// (starting at 1) and the error being retried
+func (p RetryPolicy) Retry(fn func() error, onRetry func(retry int, wait time.Duration, err error)) error {
+	for retries := 0; ; retries++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		wait, ok := p.Backoff(retries, err)
+		if !ok {
+			if retries > 0 && IsRetryable(err) {
+				return fmt.Errorf("failed after %d retries: %w", retries, err)
+			}
+			return err
+		}
+		if onRetry != nil {
+			onRetry(retries+1, wait, err)
+		}
+		time.Sleep(wait)
+	}
+}
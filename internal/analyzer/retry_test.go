@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	noRetries := 0
+	policy := DefaultRetryPolicy()
+	policy.MaxBackoff = time.Minute
+	policy.Classes[RetryClassServerError] = RetryOverride{MaxRetries: &noRetries}
+
+	rateLimited := newAPIError(http.StatusTooManyRequests, "", false)
+	tests := []struct {
+		name    string
+		retries int
+		err     error
+		want    time.Duration
+		wantOK  bool
+	}{
+		{name: "rate limit first retry", retries: 0, err: rateLimited, want: 6 * time.Second, wantOK: true},
+		{name: "rate limit grows", retries: 2, err: rateLimited, want: 24 * time.Second, wantOK: true},
+		{name: "capped at max backoff", retries: 4, err: rateLimited, want: time.Minute, wantOK: true},
+		{name: "retries used up", retries: 5, err: rateLimited, wantOK: false},
+		{name: "network", retries: 1, err: NetworkError("failed to send request", errors.New("connection reset")), want: 8 * time.Second, wantOK: true},
+		{name: "class override disables retries", retries: 0, err: newAPIError(http.StatusBadGateway, "", false), wantOK: false},
+		{name: "not retryable", retries: 0, err: newAPIError(http.StatusBadRequest, "", false), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := policy.Backoff(tt.retries, tt.err)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Backoff(%d) = %v, %v, want %v, %v", tt.retries, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Retry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2}
+	calls, retries := 0, 0
+	err := policy.Retry(func() error {
+		calls++
+		return newAPIError(http.StatusServiceUnavailable, "busy", false)
+	}, func(retry int, wait time.Duration, err error) { retries = retry })
+	if calls != 3 || retries != 2 {
+		t.Errorf("Retry() made %d calls and %d retries, want 3 and 2", calls, retries)
+	}
+	if !errors.Is(err, ErrServerError) || !strings.Contains(err.Error(), "after 2 retries") {
+		t.Errorf("Retry() error = %v", err)
+	}
+
+	calls = 0
+	if err := policy.Retry(func() error {
+		calls++
+		if calls == 1 {
+			return NetworkError("failed to read response", errors.New("EOF"))
+		}
+		return nil
+	}, nil); err != nil || calls != 2 {
+		t.Errorf("Retry() = %v after %d calls, want success on the second", err, calls)
+	}
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Route returns a copy of the analyzer for one class of screenshots (e.g. terminals and IDEs), with
+// its own analysis model, instructions appended to the analysis prompt and image detail
+// Empty values keep the analyzer's settings; the copy shares the transport and recorders
+func (o *OpenAI) Route(model, instructions, imageDetail string) (*OpenAI, error) {
+	if imageDetail != "" && !imageDetails[imageDetail] {
+		return nil, fmt.Errorf("image detail must be 'low', 'high' or 'auto', got '%s'", imageDetail)
+	}
+
+	routed := *o
+	if model != "" {
+		routed.Model = model
+	}
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		routed.Prompt = strings.TrimRight(o.Prompt, "\n") + "\n\n" + instructions
+	}
+	if imageDetail != "" {
+		routed.ImageDetail = make(map[string]string, len(o.ImageDetail)+1)
+		for purpose, detail := range o.ImageDetail {
+			routed.ImageDetail[purpose] = detail
+		}
+		routed.ImageDetail[PurposeScreenshotAnalysis] = imageDetail
+	}
+	return &routed, nil
+}
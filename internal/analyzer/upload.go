@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+)
+
+// Request purposes recorded in the upload log
+const (
+	PurposeScreenshotAnalysis  = "screenshot_analysis"
+	PurposeLockScreenDetection = "lock_screen_detection"
+	PurposeDesktopDetection    = "desktop_detection"
+	PurposeSummary             = "summary"
+	PurposeBehaviorAnalysis    = "behavior_analysis"
+	PurposeDeliverables        = "deliverables"
+	PurposeResearchLog         = "research_log"
+	PurposeMeetingNote         = "meeting_note"
+	PurposeSummarySections     = "summary_sections"
+	PurposeEvaluation          = "evaluation"
+	PurposePromptAdvice        = "prompt_advice"
+	PurposeEmbeddings          = "embeddings"
+	PurposeThreadNaming        = "thread_naming"
+	PurposeHealthCheck         = "health_check"
+)
+
+// Upload describes what was sent to the LLM provider in one API request
+type Upload struct {
+	Time       time.Time
+	Endpoint   string // Provider API endpoint
+	Model      string
+	Purpose    string
+	ImagePaths []string // Local files of the uploaded images
+	Text       string   // All text parts of the request
+}
+
+// UploadRecorder receives every request before it is sent to the provider
+type UploadRecorder func(upload *Upload)
+
+// RecordUpload reports a request about to be sent to endpoint to the upload recorder (if any)
+// Every attempt is recorded, since retries upload the same data again
+func (o *OpenAI) RecordUpload(endpoint string, req VisionRequest) {
+	if o.UploadRecorder == nil {
+		return
+	}
+
+	var texts []string
+	for _, msg := range req.Messages {
+		for _, c := range msg.Content {
+			if c.Type == "text" && c.Text != "" {
+				texts = append(texts, c.Text)
+			}
+		}
+	}
+	o.UploadRecorder(&Upload{
+		Time:       time.Now(),
+		Endpoint:   endpoint,
+		Model:      req.Model,
+		Purpose:    req.Purpose,
+		ImagePaths: req.ImagePaths,
+		Text:       strings.Join(texts, "\n\n"),
+	})
+}
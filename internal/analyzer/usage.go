@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Usage is the token usage reported by the provider for one request
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	CachedTokens     int // Prompt tokens served from the provider's prompt cache
+}
+
+// Call describes one API request sent to the provider and how it went
+type Call struct {
+	Time    time.Time
+	Model   string
+	Purpose string
+	Level   string // Summary period type, empty for other purposes
+	Usage   Usage
+	Latency time.Duration
+	Err     error // nil for a successful request
+	// StatusCode is the HTTP status of a request the provider refused, 0 when it succeeded or no response arrived
+	StatusCode int
+}
+
+// CallRecorder receives every request after the provider answered or the request failed
+type CallRecorder func(call *Call)
+
+type usageResponse struct {
+	Usage struct {
+		// Chat Completions API
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+
+		// Responses API
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+	} `json:"usage"`
+}
+
+// DecodeUsage returns the token usage of a response body of either API style; providers that
+// don't report usage give a zero Usage
+func DecodeUsage(body []byte) Usage {
+	var resp usageResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Usage{}
+	}
+	u := resp.Usage
+	return Usage{
+		PromptTokens:     u.PromptTokens + u.InputTokens,
+		CompletionTokens: u.CompletionTokens + u.OutputTokens,
+		CachedTokens:     u.PromptTokensDetails.CachedTokens + u.InputTokensDetails.CachedTokens,
+	}
+}
+
+// RecordCall reports a finished request to the call recorder (if any)
+// Every attempt is recorded, so retries show up as failed calls
+func (o *OpenAI) RecordCall(req VisionRequest, usage Usage, latency time.Duration, err error) {
+	if o.CallRecorder == nil {
+		return
+	}
+	call := &Call{
+		Time:    time.Now(),
+		Model:   req.Model,
+		Purpose: req.Purpose,
+		Level:   req.Level,
+		Usage:   usage,
+		Latency: latency,
+		Err:     err,
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		call.StatusCode = apiErr.StatusCode
+	}
+	o.CallRecorder(call)
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDecodeUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Usage
+	}{
+		{
+			name: "chat completions",
+			body: `{"choices":[],"usage":{"prompt_tokens":1200,"completion_tokens":300,"prompt_tokens_details":{"cached_tokens":1024}}}`,
+			want: Usage{PromptTokens: 1200, CompletionTokens: 300, CachedTokens: 1024},
+		},
+		{
+			name: "responses",
+			body: `{"output":[],"usage":{"input_tokens":800,"output_tokens":120,"input_tokens_details":{"cached_tokens":0}}}`,
+			want: Usage{PromptTokens: 800, CompletionTokens: 120},
+		},
+		{
+			name: "no usage",
+			body: `{"choices":[]}`,
+			want: Usage{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeUsage([]byte(tt.body)); got != tt.want {
+				t.Errorf("DecodeUsage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCallStatusCode(t *testing.T) {
+	var calls []*Call
+	o := &OpenAI{CallRecorder: func(call *Call) { calls = append(calls, call) }}
+	req := VisionRequest{Model: "gpt-4o", Purpose: PurposeScreenshotAnalysis}
+	o.RecordCall(req, Usage{}, time.Second, fmt.Errorf("failed to analyze: %w", newAPIError(429, `{"error":{"type":"rate_limit_error"}}`, true)))
+	o.RecordCall(req, Usage{}, time.Second, errors.New("connection reset"))
+	o.RecordCall(req, Usage{PromptTokens: 100}, time.Second, nil)
+
+	for i, want := range []int{429, 0, 0} {
+		if calls[i].StatusCode != want {
+			t.Errorf("call %d: StatusCode = %d, want %d", i, calls[i].StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxWindowTitleRunes bounds the window title passed to the model; browser and IDE titles can be very long
+const maxWindowTitleRunes = 200
+
+// CaptureWindow is the frontmost application and window title when a screenshot was captured
+type CaptureWindow struct {
+	App   string
+	Title string
+}
+
+// screenshotPrompt returns the screenshot analysis prompt with the capture window appended as context
+// The model is told the window is a hint, so it still describes what the screenshot shows
+func screenshotPrompt(prompt string, window *CaptureWindow) string {
+	if window == nil || strings.TrimSpace(window.App) == "" {
+		return prompt
+	}
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n\n截图时的前台窗口（由系统记录，可作为判断依据，但以截图内容为准）：\n")
+	sb.WriteString(fmt.Sprintf("- 应用：%s\n", strings.TrimSpace(window.App)))
+	if title := strings.Join(strings.Fields(window.Title), " "); title != "" {
+		if runes := []rune(title); len(runes) > maxWindowTitleRunes {
+			title = string(runes[:maxWindowTitleRunes]) + "..."
+		}
+		sb.WriteString(fmt.Sprintf("- 窗口标题：%s\n", title))
+	}
+	return sb.String()
+}
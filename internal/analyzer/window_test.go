@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScreenshotPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		window *CaptureWindow
+		want   []string // Substrings of the prompt after the base prompt, empty when unchanged
+	}{
+		{"no window", nil, nil},
+		{"no app", &CaptureWindow{Title: "main.go"}, nil},
+		{"app only", &CaptureWindow{App: "Finder"}, []string{"- 应用：Finder\n"}},
+		{"app and title", &CaptureWindow{App: "Code", Title: "main.go —\n stuff-time"}, []string{"- 应用：Code\n", "- 窗口标题：main.go — stuff-time\n"}},
+		{"long title", &CaptureWindow{App: "Chrome", Title: strings.Repeat("页", 300)}, []string{"- 窗口标题：" + strings.Repeat("页", maxWindowTitleRunes) + "...\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := screenshotPrompt("分析截图", tt.window)
+			if len(tt.want) == 0 {
+				if got != "分析截图" {
+					t.Errorf("screenshotPrompt() = %q, want the base prompt", got)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !strings.HasPrefix(got, "分析截图\n\n") || !strings.Contains(got, want) {
+					t.Errorf("screenshotPrompt() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
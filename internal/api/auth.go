@@ -0,0 +1,161 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+)
+
+// tokenCookie keeps the token of the dashboard, so the page and its thumbnails load after opening
+// /?token=... once
+const tokenCookie = "stuff_time_token"
+
+// require serves next only to requests allowed to use scope: addressed to this machine by a known name, not
+// sent by a page of another site, from the
+// local machine when api.localhost_only is set, and with a token granting the scope when api.tokens are configured;
+// without api.tokens only reading is allowed
+func (s *Server) require(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Web pages on other sites must not trigger actions or read data through the browser (CSRF)
+		if !sameOrigin(r) {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		// A site whose name was rebound to this machine's address must not reach the API (DNS rebinding)
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, "unexpected Host header, add the name to api.allowed_hosts")
+			return
+		}
+		if s.cfg.API.LocalhostOnly && !isLoopbackAddr(r.RemoteAddr) {
+			writeError(w, http.StatusForbidden, "only local requests are allowed")
+			return
+		}
+		if len(s.cfg.API.Tokens) == 0 {
+			// Without tokens anything on this machine could pause capture or spend money on generation
+			if scope != config.APIScopeReadReports {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("configure an api.tokens entry with the %s scope to use this endpoint", scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		value, fromQuery := requestToken(r)
+		token := s.matchToken(value)
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="stuff-time"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		if !token.HasScope(scope) {
+			logger.GetLogger().Warnf("API token %q without scope %s rejected: %s %s", token.Name, scope, r.Method, r.URL.Path)
+			writeError(w, http.StatusForbidden, fmt.Sprintf("token lacks the %s scope", scope))
+			return
+		}
+		if fromQuery && r.URL.Path == "/" {
+			http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: value, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether a request comes from the API's own pages or from a non-browser client:
+// the Origin header, when sent, must match the requested host, and browsers must not mark it cross-site
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// allowedHost reports whether the Host header names this server: localhost or a loopback IP, the listen
+// host, a name of api.allowed_hosts, or any IP address when listening on all interfaces
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+	}
+	if isLoopbackAddr(host) {
+		return true
+	}
+	listenHost := s.listenHost
+	if listenHost == "" {
+		listenHost, _, _ = net.SplitHostPort(s.cfg.API.Listen)
+	}
+	if strings.EqualFold(host, listenHost) {
+		return true
+	}
+	for _, allowed := range s.cfg.API.AllowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	if ip := net.ParseIP(listenHost); listenHost == "" || (ip != nil && ip.IsUnspecified()) {
+		return net.ParseIP(host) != nil
+	}
+	return false
+}
+
+// requestToken returns the token of a request: the bearer token, the token query parameter or the
+// dashboard cookie, and whether it came from the query
+func requestToken(r *http.Request) (string, bool) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if value, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(value), false
+		}
+		return "", false
+	}
+	if value := r.URL.Query().Get("token"); value != "" {
+		return value, true
+	}
+	if cookie, err := r.Cookie(tokenCookie); err == nil {
+		return cookie.Value, false
+	}
+	return "", false
+}
+
+// matchToken returns the configured token with the value, nil when none matches
+func (s *Server) matchToken(value string) *config.APIToken {
+	if value == "" {
+		return nil
+	}
+	for i := range s.cfg.API.Tokens {
+		token := &s.cfg.API.Tokens[i]
+		if token.Token != "" && subtle.ConstantTimeCompare([]byte(token.Token), []byte(value)) == 1 {
+			return token
+		}
+	}
+	return nil
+}
+
+// checkListen rejects listen addresses reachable from other machines when api.localhost_only is set
+func (s *Server) checkListen(addr string) error {
+	if !s.cfg.API.LocalhostOnly || isLoopbackAddr(addr) {
+		return nil
+	}
+	return fmt.Errorf("api.localhost_only is set, refusing to listen on %s (use 127.0.0.1 or localhost)", addr)
+}
+
+// isLoopbackAddr reports whether a host:port address is on the local machine
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
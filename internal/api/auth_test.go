@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stuff-time/internal/config"
+)
+
+func TestRequire(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.API.Tokens = []config.APIToken{
+		{Name: "tablet", Token: "read-token", Scopes: []string{config.APIScopeReadReports}},
+		{Name: "shortcuts", Token: "admin-token", Scopes: []string{config.APIScopeAdmin}},
+	}
+	s := &Server{cfg: cfg}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		scope  string
+		target string
+		header string
+		cookie string
+		remote string
+		local  bool
+		origin string
+		want   int
+	}{
+		{"no token", config.APIScopeReadReports, "/summaries", "", "", "192.168.1.20:5000", false, "", http.StatusUnauthorized},
+		{"wrong token", config.APIScopeReadReports, "/summaries", "Bearer nope", "", "192.168.1.20:5000", false, "", http.StatusUnauthorized},
+		{"bearer read", config.APIScopeReadReports, "/summaries", "Bearer read-token", "", "192.168.1.20:5000", false, "", http.StatusNoContent},
+		{"query read", config.APIScopeReadReports, "/?token=read-token", "", "", "192.168.1.20:5000", false, "", http.StatusNoContent},
+		{"cookie read", config.APIScopeReadReports, "/thumbnail?id=a", "", "read-token", "192.168.1.20:5000", false, "", http.StatusNoContent},
+		{"read cannot generate", config.APIScopeTriggerGeneration, "/generate", "Bearer read-token", "", "192.168.1.20:5000", false, "", http.StatusForbidden},
+		{"read cannot pause", config.APIScopeAdmin, "/pause", "Bearer read-token", "", "192.168.1.20:5000", false, "", http.StatusForbidden},
+		{"admin generates", config.APIScopeTriggerGeneration, "/generate", "Bearer admin-token", "", "192.168.1.20:5000", false, "", http.StatusNoContent},
+		{"localhost only remote", config.APIScopeReadReports, "/summaries", "Bearer admin-token", "", "192.168.1.20:5000", true, "", http.StatusForbidden},
+		{"localhost only local", config.APIScopeReadReports, "/summaries", "Bearer read-token", "", "127.0.0.1:5000", true, "", http.StatusNoContent},
+		{"same origin", config.APIScopeAdmin, "/pause", "Bearer admin-token", "", "127.0.0.1:5000", false, "http://127.0.0.1:8787", http.StatusNoContent},
+		{"cross-origin form post", config.APIScopeAdmin, "/pause", "", "admin-token", "127.0.0.1:5000", false, "https://evil.example", http.StatusForbidden},
+		{"opaque origin", config.APIScopeTriggerGeneration, "/generate", "", "admin-token", "127.0.0.1:5000", false, "null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.API.LocalhostOnly = tt.local
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r.Host = "127.0.0.1:8787"
+			r.RemoteAddr = tt.remote
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: tokenCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			s.require(tt.scope, ok).ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	// Without tokens only reading is allowed
+	cfg.API.Tokens, cfg.API.LocalhostOnly = nil, false
+	for scope, want := range map[string]int{
+		config.APIScopeReadReports:       http.StatusNoContent,
+		config.APIScopeTriggerGeneration: http.StatusForbidden,
+		config.APIScopeAdmin:             http.StatusForbidden,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/generate", nil)
+		r.Host = "127.0.0.1:8787"
+		w := httptest.NewRecorder()
+		s.require(scope, ok).ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("%s without tokens: status = %d, want %d", scope, w.Code, want)
+		}
+	}
+
+	cfg.API.LocalhostOnly = true
+	if err := s.checkListen("0.0.0.0:8787"); err == nil {
+		t.Errorf("checkListen() accepted a LAN address with localhost_only")
+	}
+	if err := s.checkListen("localhost:8787"); err != nil {
+		t.Errorf("checkListen() = %v for localhost", err)
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	tests := []struct {
+		listen string
+		host   string
+		want   bool
+	}{
+		{"127.0.0.1:8787", "127.0.0.1:8787", true},
+		{"127.0.0.1:8787", "localhost:8787", true},
+		{"127.0.0.1:8787", "[::1]:8787", true},
+		{"127.0.0.1:8787", "attacker.example:8787", false},
+		{"0.0.0.0:8787", "192.168.1.10:8787", true},
+		{"0.0.0.0:8787", "attacker.example:8787", false},
+		{"0.0.0.0:8787", "mymac.local:8787", true},
+		{"192.168.1.10:8787", "192.168.1.11:8787", false},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{}
+		cfg.API.Listen = tt.listen
+		cfg.API.AllowedHosts = []string{"MyMac.local"}
+		s := &Server{cfg: cfg}
+		if got := s.allowedHost(tt.host); got != tt.want {
+			t.Errorf("allowedHost(%s) listening on %s = %v, want %v", tt.host, tt.listen, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"stuff-time/internal/gallery"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+// maxThumbnailWidth bounds the width parameter of GET /thumbnail
+const maxThumbnailWidth = 1280
+
+// handleDashboard serves GET /: the web dashboard, a single page reading the JSON endpoints
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(dashboardPage))
+}
+
+// handleThumbnail serves GET /thumbnail?id=<screenshot id>[&width=320]: a JPEG thumbnail of a screenshot
+func (s *Server) handleThumbnail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "missing id")
+		return
+	}
+	width := gallery.DefaultThumbnailWidth
+	if value := r.URL.Query().Get("width"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxThumbnailWidth {
+			writeError(w, http.StatusBadRequest, "invalid width")
+			return
+		}
+		width = n
+	}
+
+	records, err := s.st.GetScreenshotsByIDs([]string{id})
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to get screenshot %s: %v", id, err)
+		writeError(w, http.StatusInternalServerError, "failed to get screenshot")
+		return
+	}
+	record := records[id]
+	if record == nil {
+		writeError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+	// Images moved to the object store are fetched back into the local cache first
+	if err := task.FetchImages(s.cfg, []*storage.ScreenshotRecord{record}); err != nil {
+		logger.GetLogger().Warnf("Failed to fetch screenshot %s: %v", id, err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch screenshot")
+		return
+	}
+	thumb, err := gallery.ThumbnailJPEG(record.ImagePath, width)
+	if err != nil {
+		// Deleted by cleanup, or not in the object store either
+		writeError(w, http.StatusNotFound, "image not available")
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(thumb)
+}
+
+const dashboardPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>stuff-time</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", sans-serif; margin: 0; background: #fafafa; color: #222; line-height: 1.6; }
+header { background: #fff; border-bottom: 1px solid #e5e5e5; padding: 10px 24px; display: flex; gap: 12px; align-items: center; }
+header strong { margin-right: auto; }
+main { max-width: 1100px; margin: 0 auto; padding: 16px 24px 48px; }
+h2 { font-size: 18px; margin-top: 28px; }
+.meta { color: #888; font-size: 13px; }
+.timeline { position: relative; height: 36px; background: #eee; border-radius: 4px; overflow: hidden; }
+.timeline div { position: absolute; top: 0; bottom: 0; cursor: pointer; }
+.axis { position: relative; height: 18px; font-size: 11px; color: #888; }
+.axis span { position: absolute; transform: translateX(-50%); }
+.legend span { display: inline-block; margin-right: 12px; font-size: 13px; }
+.legend i { display: inline-block; width: 10px; height: 10px; border-radius: 2px; margin-right: 4px; }
+.card { background: #fff; border-radius: 8px; box-shadow: 0 1px 3px rgba(0,0,0,.12); padding: 10px 14px; margin-bottom: 12px; }
+.card h3 { font-size: 15px; margin: 0 0 6px; }
+.card .text { white-space: pre-wrap; font-size: 14px; max-height: 320px; overflow-y: auto; }
+details summary { cursor: pointer; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(200px, 1fr)); gap: 12px; }
+.shot { background: #fff; border-radius: 8px; box-shadow: 0 1px 3px rgba(0,0,0,.12); overflow: hidden; font-size: 12px; }
+.shot img { width: 100%; display: block; min-height: 60px; background: #eee; }
+.shot div { padding: 6px 8px; }
+.shot.selected { outline: 2px solid #3867d6; }
+</style>
+</head>
+<body>
+<header>
+<strong>stuff-time</strong>
+<button id="prev">&lt;</button>
+<input id="date" type="date">
+<button id="next">&gt;</button>
+</header>
+<main>
+<h2>时间线</h2>
+<div id="timeline" class="timeline"></div>
+<div id="axis" class="axis"></div>
+<div id="legend" class="legend"></div>
+<p id="timeline-meta" class="meta"></p>
+
+<h2>日总结</h2>
+<div id="day"></div>
+
+<h2>工作段</h2>
+<div id="segments"></div>
+
+<h2>小时总结</h2>
+<div id="hours"></div>
+
+<h2>截图</h2>
+<p id="shots-meta" class="meta"></p>
+<div id="shots" class="grid"></div>
+</main>
+<script>
+(function () {
+  var colors = { coding: "#3867d6", docs: "#20bf6b", other: "#8854d0", meeting: "#fa8231", communication: "#f7b731",
+    entertainment: "#eb3b5a", idle: "#d1d8e0", unknown: "#a5b1c2" };
+  var input = document.getElementById("date");
+
+  function el(tag, attrs, text) {
+    var node = document.createElement(tag);
+    for (var k in attrs || {}) node.setAttribute(k, attrs[k]);
+    if (text !== undefined) node.textContent = text;
+    return node;
+  }
+  function hhmm(value) { var d = new Date(value); return ("0" + d.getHours()).slice(-2) + ":" + ("0" + d.getMinutes()).slice(-2); }
+  function get(path) { return fetch(path).then(function (r) { return r.ok ? r.json() : r.json().then(function (e) { throw new Error(e.error); }); }); }
+  function shift(days) {
+    var d = new Date(input.value + "T12:00:00");
+    d.setDate(d.getDate() + days);
+    input.value = d.getFullYear() + "-" + ("0" + (d.getMonth() + 1)).slice(-2) + "-" + ("0" + d.getDate()).slice(-2);
+    load();
+  }
+
+  function renderTimeline(data) {
+    var box = document.getElementById("timeline"), axis = document.getElementById("axis"), legend = document.getElementById("legend");
+    box.innerHTML = ""; axis.innerHTML = ""; legend.innerHTML = "";
+    var segments = data.segments;
+    if (!segments.length) { document.getElementById("timeline-meta").textContent = "当天没有截图"; return; }
+    var start = new Date(segments[0].start).getTime(), end = new Date(segments[segments.length - 1].end).getTime();
+    var span = Math.max(end - start, 60000), minutes = {}, total = 0;
+    segments.forEach(function (s) {
+      var left = (new Date(s.start).getTime() - start) / span * 100, width = (new Date(s.end).getTime() - new Date(s.start).getTime()) / span * 100;
+      var bar = el("div", { style: "left:" + left + "%;width:" + Math.max(width, 0.2) + "%;background:" + (colors[s.category] || "#778ca3"),
+        title: hhmm(s.start) + "-" + hhmm(s.end) + " " + s.category + (s.app ? " · " + s.app : "") + (s.space ? " · " + s.space : "") });
+      bar.onclick = function () { highlight(s.screenshot_ids); };
+      box.appendChild(bar);
+      minutes[s.category] = (minutes[s.category] || 0) + s.minutes;
+      total += s.minutes;
+    });
+    for (var t = Math.ceil(start / 3600000) * 3600000; t <= end; t += 3600000) {
+      axis.appendChild(el("span", { style: "left:" + (t - start) / span * 100 + "%" }, hhmm(t)));
+    }
+    Object.keys(minutes).sort(function (a, b) { return minutes[b] - minutes[a]; }).forEach(function (c) {
+      var item = el("span");
+      item.appendChild(el("i", { style: "background:" + (colors[c] || "#778ca3") }));
+      item.appendChild(document.createTextNode(c + " " + Math.floor(minutes[c] / 60) + "h" + (minutes[c] % 60) + "m"));
+      legend.appendChild(item);
+    });
+    document.getElementById("timeline-meta").textContent = hhmm(start) + " - " + hhmm(end) + "，共记录 " + Math.floor(total / 60) + "h" + (total % 60) + "m，点击时间段查看截图";
+  }
+
+  function renderSummaries(id, summaries, open) {
+    var box = document.getElementById(id);
+    box.innerHTML = "";
+    if (!summaries.length) { box.appendChild(el("p", { class: "meta" }, "暂无总结")); return; }
+    summaries.forEach(function (s) {
+      var card = el("div", { class: "card" });
+      card.appendChild(el("h3", {}, hhmm(s.start_time) + " - " + hhmm(s.end_time) + "  " + s.period_key));
+      var details = el("details");
+      if (open) details.setAttribute("open", "");
+      details.appendChild(el("summary", { class: "meta" }, "总结"));
+      details.appendChild(el("div", { class: "text" }, s.summary));
+      card.appendChild(details);
+      if (s.analysis) {
+        var analysis = el("details");
+        analysis.appendChild(el("summary", { class: "meta" }, "分析与建议"));
+        analysis.appendChild(el("div", { class: "text" }, s.analysis));
+        card.appendChild(analysis);
+      }
+      box.appendChild(card);
+    });
+  }
+
+  function renderShots(data) {
+    var box = document.getElementById("shots");
+    box.innerHTML = "";
+    document.getElementById("shots-meta").textContent = "共 " + data.screenshots.length + " 张截图";
+    data.screenshots.forEach(function (s) {
+      var card = el("div", { class: "shot", id: "shot-" + s.id });
+      card.appendChild(el("img", { src: "thumbnail?id=" + encodeURIComponent(s.id), loading: "lazy", alt: hhmm(s.time) }));
+      var caption = (s.analysis || s.error || s.status).split("\n").filter(function (l) { return l.trim(); })[0] || "";
+      card.appendChild(el("div", { title: s.analysis || s.error || "" }, hhmm(s.time) + " " + caption.slice(0, 80)));
+      box.appendChild(card);
+    });
+  }
+
+  function highlight(ids) {
+    document.querySelectorAll(".shot.selected").forEach(function (n) { n.classList.remove("selected"); });
+    ids.forEach(function (id, i) {
+      var card = document.getElementById("shot-" + id);
+      if (!card) return;
+      card.classList.add("selected");
+      if (i === 0) card.scrollIntoView({ behavior: "smooth", block: "center" });
+    });
+  }
+
+  function load() {
+    var date = input.value, range = "&from=" + date + "&to=" + date;
+    history.replaceState(null, "", "?date=" + date);
+    get("timeline?date=" + date).then(renderTimeline).catch(function (e) { document.getElementById("timeline-meta").textContent = e.message; });
+    get("summaries?type=day" + range).then(function (d) { renderSummaries("day", d.summaries, true); });
+    get("summaries?type=work-segment" + range).then(function (d) { renderSummaries("segments", d.summaries, false); });
+    get("summaries?type=hour" + range).then(function (d) { renderSummaries("hours", d.summaries, false); });
+    get("screenshots?date=" + date).then(renderShots);
+  }
+
+  var initial = new URLSearchParams(location.search).get("date");
+  if (initial) {
+    input.value = initial;
+    load();
+  } else {
+    // The server knows the logical today (storage.day_start_hour)
+    get("timeline").then(function (d) { input.value = d.date; load(); });
+  }
+  input.onchange = load;
+  document.getElementById("prev").onclick = function () { shift(-1); };
+  document.getElementById("next").onclick = function () { shift(1); };
+})();
+</script>
+</body>
+</html>
+`
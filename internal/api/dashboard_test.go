@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestDashboard(t *testing.T) {
+	dir := t.TempDir()
+	st, err := storage.NewStorage(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	imagePath := filepath.Join(dir, "screen.png")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 800, 500))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(imagePath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	for _, record := range []*storage.ScreenshotRecord{
+		{ID: "s1", Timestamp: timestamp, ImagePath: imagePath},
+		{ID: "s2", Timestamp: timestamp.Add(time.Minute), ImagePath: filepath.Join(dir, "cleaned-up.png")},
+		{ID: "s3", Timestamp: timestamp.Add(2 * time.Minute), ImagePath: filepath.Join(dir, "remote", "screen.png")},
+	} {
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// s3 was moved to the object store, its local copy evicted from the cache
+	bucket := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/remote/screen.png") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer bucket.Close()
+	cfg := &config.Config{}
+	cfg.Screenshot.StoragePath = dir
+	cfg.ObjectStore.Enabled = true
+	cfg.ObjectStore.Endpoint = bucket.URL
+	cfg.ObjectStore.Bucket = "shots"
+	cfg.ObjectStore.AccessKeyID = "AKID"
+	cfg.ObjectStore.SecretAccessKey = "secret"
+	handler := NewServer(cfg, st).Handler()
+
+	tests := []struct {
+		target      string
+		wantCode    int
+		contentType string
+	}{
+		{"/", http.StatusOK, "text/html; charset=utf-8"},
+		{"/missing", http.StatusNotFound, ""},
+		{"/thumbnail?id=s1&width=200", http.StatusOK, "image/jpeg"},
+		{"/thumbnail?id=s3&width=200", http.StatusOK, "image/jpeg"},
+		{"/thumbnail", http.StatusBadRequest, ""},
+		{"/thumbnail?id=s1&width=5000", http.StatusBadRequest, ""},
+		{"/thumbnail?id=unknown", http.StatusNotFound, ""},
+		{"/thumbnail?id=s2", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Host = "127.0.0.1:8787"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.wantCode {
+			t.Errorf("GET %s = %d %s, want %d", tt.target, w.Code, strings.TrimSpace(w.Body.String()), tt.wantCode)
+			continue
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("GET %s content type = %q, want %q", tt.target, w.Header().Get("Content-Type"), tt.contentType)
+		}
+		if tt.contentType == "image/jpeg" {
+			thumb, err := jpeg.Decode(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := thumb.Bounds().Size(); size.X != 200 || size.Y != 125 {
+				t.Errorf("GET %s thumbnail size = %v, want 200x125", tt.target, size)
+			}
+		}
+	}
+}
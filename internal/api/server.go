@@ -0,0 +1,535 @@
+// Package api serves stuff-time data over a local HTTP API for dashboards and export tools
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stuff-time/internal/apicache"
+	"stuff-time/internal/config"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+	"stuff-time/internal/timeline"
+)
+
+// Server is the local HTTP API server
+type Server struct {
+	cfg        *config.Config
+	st         *storage.Storage
+	cache      *apicache.Cache
+	srv        *http.Server
+	stopWatch  func()
+	listenHost string     // Host of the listen address, accepted in the Host header
+	generating sync.Mutex // Held while a POST /generate runs in the background
+}
+
+// NewServer creates an API server reading from st
+func NewServer(cfg *config.Config, st *storage.Storage) *Server {
+	cache := apicache.New(cfg.API.CacheSize)
+	// The daemon and generate command write the database from other processes
+	cache.TrackVersion(st.DataVersion)
+	return &Server{
+		cfg:   cfg,
+		st:    st,
+		cache: cache,
+	}
+}
+
+// Handler returns the HTTP handler serving all API routes
+func (s *Server) Handler() http.Handler {
+	read := func(h http.HandlerFunc) http.Handler { return s.require(config.APIScopeReadReports, h) }
+	admin := func(h http.HandlerFunc) http.Handler { return s.require(config.APIScopeAdmin, h) }
+	cached := func(h http.HandlerFunc) http.Handler {
+		return s.require(config.APIScopeReadReports, s.cache.Middleware(h))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", read(s.handleDashboard))
+	mux.Handle("/thumbnail", read(s.handleThumbnail))
+	mux.Handle("/timeline", s.require(config.APIScopeReadReports, s.cachedPastDays(http.HandlerFunc(s.handleTimeline))))
+	mux.Handle("/backlog", read(s.handleBacklog))
+	mux.Handle("/report", cached(s.handleReport))
+	mux.Handle("/screenshots", read(s.handleScreenshots))
+	mux.Handle("/summaries", cached(s.handleSummaries))
+	mux.Handle("/summary", cached(s.handleSummary))
+	mux.Handle("/generate", s.require(config.APIScopeTriggerGeneration, http.HandlerFunc(s.handleGenerate)))
+	// Single-purpose endpoints for automation tools (Apple Shortcuts, Alfred, Stream Deck)
+	mux.Handle("/today", read(s.handleToday))
+	mux.Handle("/pause", admin(s.handlePause))
+	mux.Handle("/resume", admin(s.handleResume))
+	mux.Handle("/snap", admin(s.handleSnap))
+	return mux
+}
+
+// Start listens on addr and serves requests in the background
+func (s *Server) Start(addr string) error {
+	if err := s.checkListen(addr); err != nil {
+		return err
+	}
+	s.listenHost, _, _ = net.SplitHostPort(addr)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.stopWatch = s.cache.Watch(events.Default())
+	s.srv = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.GetLogger().Errorf("API server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the server down, waiting for in-flight requests
+func (s *Server) Stop() error {
+	if s.srv == nil {
+		return nil
+	}
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
+
+// cachedPastDays serves requests for days before today from the response cache
+// Today's data changes with every capture, so it is always computed
+func (s *Server) cachedPastDays(next http.Handler) http.Handler {
+	cached := s.cache.Middleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		day, err := s.parseDate(r.URL.Query().Get("date"))
+		today, _ := s.parseDate("")
+		if err == nil && day.Before(today) {
+			cached.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleTimeline serves GET /timeline?date=YYYY-MM-DD[&space=...][&format=csv]
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	day, err := s.parseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	segments, err := task.DayTimeline(s.cfg, s.st, day)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to build timeline for %s: %v", day.Format("2006-01-02"), err)
+		writeError(w, http.StatusInternalServerError, "failed to build timeline")
+		return
+	}
+	if value := strings.TrimSpace(r.URL.Query().Get("space")); value != "" {
+		space := value
+		if n, err := strconv.Atoi(value); err == nil {
+			space = s.cfg.Spaces.Label(n)
+		}
+		var filtered []*timeline.Segment
+		for _, segment := range segments {
+			if strings.EqualFold(segment.Space, space) {
+				filtered = append(filtered, segment)
+			}
+		}
+		segments = filtered
+	}
+	if segments == nil {
+		segments = []*timeline.Segment{}
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, struct {
+			Date     string              `json:"date"`
+			Segments []*timeline.Segment `json:"segments"`
+		}{day.Format("2006-01-02"), segments})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if err := timeline.WriteCSV(w, segments); err != nil {
+			logger.GetLogger().Warnf("Failed to write timeline CSV: %v", err)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid format (supported: json, csv)")
+	}
+}
+
+// handleBacklog serves GET /backlog: the unanalyzed screenshot backlog, its recent samples and the
+// projected catch-up time
+func (s *Server) handleBacklog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	backlog, err := task.Backlog(s.cfg, s.st, time.Now())
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to get analysis backlog: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get analysis backlog")
+		return
+	}
+
+	response := struct {
+		Pending        int                      `json:"pending"`
+		Workers        int                      `json:"workers"`
+		LatencySeconds float64                  `json:"latency_seconds"` // 0 when unknown
+		InflowPerHour  float64                  `json:"inflow_per_hour"`
+		TrendPerHour   float64                  `json:"trend_per_hour"`
+		CatchesUp      bool                     `json:"catches_up"`
+		ETASeconds     float64                  `json:"eta_seconds"`
+		Samples        []*storage.BacklogSample `json:"samples"`
+	}{
+		Pending:        backlog.Pending,
+		Workers:        backlog.Workers,
+		LatencySeconds: backlog.Latency.Seconds(),
+		InflowPerHour:  backlog.Inflow,
+		TrendPerHour:   backlog.Trend,
+		CatchesUp:      backlog.CatchesUp,
+		ETASeconds:     backlog.ETA.Seconds(),
+		Samples:        backlog.Samples,
+	}
+	if response.Samples == nil {
+		response.Samples = []*storage.BacklogSample{}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleReport serves GET /report?period=<period key>: the report of a period as JSON (title,
+// metadata, sections and citations)
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	periodKey := r.URL.Query().Get("period")
+	if periodKey == "" {
+		writeError(w, http.StatusBadRequest, "missing period")
+		return
+	}
+	doc, err := task.ReadReportDocument(s.cfg, s.st, periodKey)
+	if errors.Is(err, task.ErrReportNotFound) {
+		writeError(w, http.StatusNotFound, "report not found")
+		return
+	}
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to read report of %s: %v", periodKey, err)
+		writeError(w, http.StatusInternalServerError, "failed to read report")
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// screenshotJSON is a screenshot as served by GET /screenshots
+type screenshotJSON struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	ScreenID  int       `json:"screen_id"`
+	ImagePath string    `json:"image_path"`
+	Status    string    `json:"status"`
+	Analysis  string    `json:"analysis,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	App       string    `json:"app,omitempty"`
+	Title     string    `json:"window_title,omitempty"`
+}
+
+// summaryJSON is a period summary as served by GET /summaries and GET /summary
+type summaryJSON struct {
+	PeriodKey  string    `json:"period_key"`
+	PeriodType string    `json:"period_type"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Summary    string    `json:"summary"`
+	Analysis   string    `json:"analysis,omitempty"`
+}
+
+func newSummaryJSON(summary *storage.PeriodSummary) *summaryJSON {
+	return &summaryJSON{
+		PeriodKey:  summary.PeriodKey,
+		PeriodType: summary.PeriodType,
+		StartTime:  summary.StartTime,
+		EndTime:    summary.EndTime,
+		Summary:    summary.Summary,
+		Analysis:   summary.Analysis,
+	}
+}
+
+// generatePeriodTypes are the period types POST /generate accepts
+var generatePeriodTypes = []string{"fifteenmin", "hour", "day", "week", "month", "quarter", "year"}
+
+// handleScreenshots serves GET /screenshots?date=YYYY-MM-DD[&status=...]: the screenshots of a day,
+// optionally only those with an analysis status (pending, done, failed, ...)
+func (s *Server) handleScreenshots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	day, err := s.parseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	status := r.URL.Query().Get("status")
+
+	start := s.cfg.Storage.DateStart(day)
+	records, err := s.st.QueryByDateRange(start, start.AddDate(0, 0, 1))
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query screenshots of %s: %v", day.Format("2006-01-02"), err)
+		writeError(w, http.StatusInternalServerError, "failed to query screenshots")
+		return
+	}
+	screenshots := []*screenshotJSON{}
+	for _, record := range records {
+		if status != "" && record.AnalysisStatus != status {
+			continue
+		}
+		screenshots = append(screenshots, &screenshotJSON{
+			ID:        record.ID,
+			Time:      record.Timestamp,
+			ScreenID:  record.ScreenID,
+			ImagePath: record.ImagePath,
+			Status:    record.AnalysisStatus,
+			Analysis:  record.Analysis,
+			Error:     record.AnalysisError,
+			App:       record.App,
+			Title:     record.WindowTitle,
+		})
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Date        string            `json:"date"`
+		Screenshots []*screenshotJSON `json:"screenshots"`
+	}{day.Format("2006-01-02"), screenshots})
+}
+
+// handleSummaries serves GET /summaries?type=day[&from=YYYY-MM-DD][&to=YYYY-MM-DD]: the summaries of a
+// period type starting within the days from-to (inclusive, both default to today)
+func (s *Server) handleSummaries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	query := r.URL.Query()
+	periodType := query.Get("type")
+	if periodType == "" {
+		writeError(w, http.StatusBadRequest, "missing type")
+		return
+	}
+	from, err := s.parseDate(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to := from
+	if value := query.Get("to"); value != "" {
+		if to, err = s.parseDate(value); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if to.Before(from) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	start, end := s.cfg.Storage.DateStart(from), s.cfg.Storage.DateStart(to.AddDate(0, 0, 1))
+	summaries, err := s.st.QueryPeriodSummaries(periodType, start, end)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query %s summaries: %v", periodType, err)
+		writeError(w, http.StatusInternalServerError, "failed to query summaries")
+		return
+	}
+	response := []*summaryJSON{}
+	for _, summary := range summaries {
+		response = append(response, newSummaryJSON(summary))
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Type      string         `json:"type"`
+		Summaries []*summaryJSON `json:"summaries"`
+	}{periodType, response})
+}
+
+// handleSummary serves GET /summary?period=<period key>: the summary of a period
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	periodKey := r.URL.Query().Get("period")
+	if periodKey == "" {
+		writeError(w, http.StatusBadRequest, "missing period")
+		return
+	}
+	summary, err := s.st.GetPeriodSummary(periodKey)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to get summary of %s: %v", periodKey, err)
+		writeError(w, http.StatusInternalServerError, "failed to get summary")
+		return
+	}
+	if summary == nil {
+		writeError(w, http.StatusNotFound, "summary not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, newSummaryJSON(summary))
+}
+
+// handleGenerate serves POST /generate?type=day[&date=YYYY-MM-DD]: generates the summary of the period
+// containing the date in the background, like "generate --period"; one generation runs at a time
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if !s.allowWrite(w, r) {
+		return
+	}
+	periodType := r.URL.Query().Get("type")
+	if !slices.Contains(generatePeriodTypes, periodType) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid type %q (supported: %s)", periodType, strings.Join(generatePeriodTypes, ", ")))
+		return
+	}
+	date := r.URL.Query().Get("date")
+	if date != "" {
+		if _, err := s.parseDate(date); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if !s.generating.TryLock() {
+		writeError(w, http.StatusConflict, "a generation is already running")
+		return
+	}
+	executor, err := task.NewExecutor(s.cfg, s.st)
+	if err != nil {
+		s.generating.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "failed to create executor: "+err.Error())
+		return
+	}
+	go func() {
+		defer s.generating.Unlock()
+		err := executor.GenerateSinglePeriodSummary(periodType, date, false)
+		switch {
+		case errors.Is(err, storage.ErrNoData):
+			logger.GetLogger().Infof("No data to generate %s summary (%s)", periodType, date)
+		case err != nil:
+			logger.GetLogger().Warnf("Failed to generate %s summary: %v", periodType, err)
+		default:
+			logger.GetLogger().Infof("Generated %s summary via API", periodType)
+		}
+	}()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started", "type": periodType, "date": date})
+}
+
+// handleToday serves GET /today: today's work summary as plain text
+func (s *Server) handleToday(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	text, err := task.TodaySummary(s.cfg, s.st, time.Now())
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to get today's summary: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get today's summary")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, text)
+}
+
+// handlePause serves POST /pause[?for=1h]: pauses screenshot capture, for one hour by default
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if !s.allowWrite(w, r) {
+		return
+	}
+	duration := time.Hour
+	if value := r.URL.Query().Get("for"); value != "" {
+		var err error
+		if duration, err = time.ParseDuration(value); err != nil || duration <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid duration %q (e.g. 30m, 1h)", value))
+			return
+		}
+	}
+	until := time.Now().Add(duration)
+	if err := task.PauseCapture(s.st, until); err != nil {
+		logger.GetLogger().Warnf("Failed to pause capture: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to pause capture")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"paused_until": until.Format(time.RFC3339)})
+}
+
+// handleResume serves POST /resume: ends a capture pause
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if !s.allowWrite(w, r) {
+		return
+	}
+	if err := task.ResumeCapture(s.st); err != nil {
+		logger.GetLogger().Warnf("Failed to resume capture: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to resume capture")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "resumed"})
+}
+
+// handleSnap serves POST /snap[?note=...]: captures a screenshot now and stars it with the note
+func (s *Server) handleSnap(w http.ResponseWriter, r *http.Request) {
+	if !s.allowWrite(w, r) {
+		return
+	}
+	record, err := task.Snap(s.cfg, s.st, strings.TrimSpace(r.URL.Query().Get("note")))
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to snap screenshot: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to capture screenshot: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": record.ID, "time": record.Timestamp.Format(time.RFC3339)})
+}
+
+// allowWrite rejects requests to endpoints that change data unless they are POSTs outside read-only mode
+func (s *Server) allowWrite(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	if storage.IsReadOnly() {
+		writeError(w, http.StatusForbidden, "not available in read-only mode")
+		return false
+	}
+	return true
+}
+
+// parseDate parses a YYYY-MM-DD query value in local time, defaulting to today
+func (s *Server) parseDate(value string) (time.Time, error) {
+	if value == "" {
+		date := s.cfg.Storage.LogicalDate(time.Now())
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return day, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
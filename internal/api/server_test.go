@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestCacheSeesWritesOfOtherProcesses(t *testing.T) {
+	// The server and the daemon open the same database
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	var stores []*storage.Storage
+	for i := 0; i < 2; i++ {
+		st, err := storage.NewStorage(dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer st.Close()
+		stores = append(stores, st)
+	}
+	handler := NewServer(&config.Config{}, stores[0]).Handler()
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	for _, text := range []string{"Reviewed the release", "Shipped the release"} {
+		if err := stores[1].SavePeriodSummary(&storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day",
+			StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: text}); err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/summary?period=day:2025-12-09", nil)
+		r.Host = "127.0.0.1:8787"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), text) {
+			t.Errorf("GET /summary = %d %s, want %q", w.Code, w.Body.String(), text)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package apicache is an in-memory LRU response cache with ETags for the HTTP API,
+// so the dashboard and CLI don't hit SQLite and re-render markdown on every request.
+// Entries are dropped whenever a summary is (re)generated on the event bus, or the database's
+// data version changes (writes by other processes, which never reach this process's bus)
+package apicache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"stuff-time/internal/events"
+)
+
+// Entry is a cached response
+type Entry struct {
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+}
+
+type item struct {
+	key   string
+	entry *Entry
+}
+
+// Cache is a fixed-capacity LRU cache of responses keyed by request URI
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Front is most recently used
+	items    map[string]*list.Element
+	hits     int
+	misses   int
+	version  func() (int64, error) // Optional, see TrackVersion
+	seen     int64                 // Last data version seen
+}
+
+// New creates a cache holding at most capacity responses
+func New(capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = 256
+	}
+	return &Cache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// ETag returns a strong ETag for a response body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// Get returns the cached response for key and marks it as recently used
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	return el.Value.(*item).entry, true
+}
+
+// Set stores a response, evicting the least recently used one when full
+func (c *Cache) Set(key string, entry *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&item{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
+
+// Purge drops all cached responses
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Stats returns the number of cached responses, hits and misses
+func (c *Cache) Stats() (size, hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), c.hits, c.misses
+}
+
+// TrackVersion makes the middleware check the data version before every request
+// Responses are cached per version, so a response computed while the data changed is never served
+// after the change; the cache is dropped when a new version is seen
+func (c *Cache) TrackVersion(version func() (int64, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+}
+
+// currentVersion returns the data version, purging the cache when it changed; 0 without version tracking
+func (c *Cache) currentVersion() (int64, error) {
+	c.mu.Lock()
+	version := c.version
+	c.mu.Unlock()
+	if version == nil {
+		return 0, nil
+	}
+	v, err := version()
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	changed := v != c.seen
+	c.seen = v
+	c.mu.Unlock()
+	if changed {
+		c.Purge()
+	}
+	return v, nil
+}
+
+// Watch purges the cache whenever a summary is generated on the bus
+// A generated period changes its own response as well as every list or parent-period response
+// that includes it, so the whole cache is dropped rather than tracking dependencies.
+// Returns a function that stops watching
+func (c *Cache) Watch(bus *events.Bus) func() {
+	id, ch := bus.Subscribe(64)
+	go func() {
+		for event := range ch {
+			if event.PeriodKey != "" {
+				c.Purge()
+			}
+		}
+	}()
+	return func() { bus.Unsubscribe(id) }
+}
+
+// Middleware serves GET requests from the cache and answers conditional requests with
+// 304 Not Modified. Only successful responses are cached
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, err := c.currentVersion()
+		if err != nil {
+			// Without the version a cached response may be stale
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := fmt.Sprintf("%d %s", version, r.URL.RequestURI())
+		entry, ok := c.Get(key)
+		if !ok {
+			rec := &recorder{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			entry = &Entry{
+				Status:      rec.status,
+				ContentType: rec.header.Get("Content-Type"),
+				ETag:        ETag(rec.body.Bytes()),
+				Body:        rec.body.Bytes(),
+			}
+			if entry.Status != http.StatusOK {
+				w.WriteHeader(entry.Status)
+				w.Write(entry.Body)
+				return
+			}
+			c.Set(key, entry)
+		}
+
+		w.Header().Set("ETag", entry.ETag)
+		if entry.ContentType != "" {
+			w.Header().Set("Content-Type", entry.ContentType)
+		}
+		if matchesETag(r.Header.Get("If-None-Match"), entry.ETag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(entry.Status)
+		w.Write(entry.Body)
+	})
+}
+
+// matchesETag checks an If-None-Match header value against an ETag
+func matchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder buffers a handler's response so it can be cached before being written
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,110 @@
+package apicache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stuff-time/internal/events"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(2)
+	c.Set("a", &Entry{Body: []byte("a")})
+	c.Set("b", &Entry{Body: []byte("b")})
+	c.Get("a")
+	c.Set("c", &Entry{Body: []byte("c")})
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b should have been evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("a should still be cached")
+	}
+}
+
+func TestMiddlewareCachesAndServesNotModified(t *testing.T) {
+	calls := 0
+	handler := New(8).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"period_key":"2025-12-09"}`))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/api/periods/2025-12-09", nil))
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first response = %d, ETag %q", first.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/periods/2025-12-09", nil)
+	req.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, req)
+	if second.Code != http.StatusNotModified {
+		t.Errorf("conditional response = %d, want 304", second.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestMiddlewareSkipsErrors(t *testing.T) {
+	calls := 0
+	handler := New(8).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/periods/missing", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("response = %d, want 404", rec.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2 (errors are not cached)", calls)
+	}
+}
+
+func TestWatchPurgesOnSummaryGenerated(t *testing.T) {
+	bus := events.NewBus(0)
+	c := New(8)
+	stop := c.Watch(bus)
+	defer stop()
+
+	c.Set("/api/periods/2025-12-09", &Entry{Body: []byte("old")})
+	bus.Publish(events.Event{Component: events.ComponentCapture, Message: "Screenshot captured"})
+	bus.Publish(events.Event{Component: events.ComponentSummary, PeriodKey: "2025-12-09"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if size, _, _ := c.Stats(); size == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("cache was not purged after summary generated event")
+}
+
+func TestMiddlewareTracksDataVersion(t *testing.T) {
+	c := New(8)
+	var version int64
+	c.TrackVersion(func() (int64, error) { return version, nil })
+	calls := 0
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("summary"))
+	}))
+
+	for _, v := range []int64{1, 1, 2} {
+		version = v
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/summary?period=day:2025-12-09", nil))
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2 (once per data version)", calls)
+	}
+}
@@ -0,0 +1,351 @@
+// Package backup writes and restores a single-file backup of an installation (.stz): a gzip-compressed
+// tar with a manifest, a snapshot of the database, the config file, the loaded prompts and optionally
+// the reports. Restoring lays them out in one directory and points the config at it, so an
+// installation moves to another machine in one step. Screenshot images are not included
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// FormatVersion is the version of the backup format written by Create
+const FormatVersion = 1
+
+// Paths inside a backup
+const (
+	manifestName = "manifest.json"
+	configName   = "config.yaml"
+	databaseName = "stuff-time.db"
+	promptsDir   = "prompts"
+	reportsDir   = "reports"
+)
+
+// Layout of a restored installation, relative to the target directory
+const (
+	RestoredConfig      = "config.yaml"
+	RestoredDB          = "data/db/stuff-time.db"
+	RestoredReports     = "data/reports"
+	RestoredScreenshots = "data/screenshots"
+	RestoredPrompts     = "prompts"
+)
+
+// promptSceneKeys are the config keys of the prompt scene directories
+var promptSceneKeys = map[string]string{
+	"screenshot":   "openai.screenshot_path",
+	"summary":      "openai.summary_path",
+	"analysis":     "openai.analysis_path",
+	"deliverables": "deliverables.prompt_path",
+	"reading":      "reading.prompt_path",
+	"meetings":     "meetings.prompt_path",
+	"threads":      "threads.prompt_path",
+	"evaluation":   "evaluator.evaluation_path",
+	"improvement":  "evaluator.improvement_path",
+}
+
+// Manifest describes a backup; it is the first file of the archive
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Hostname  string    `json:"hostname"`
+	Prompts   []string  `json:"prompts"` // "<scene>/<file name without .txt>"
+	Reports   int       `json:"reports"` // Number of report files, 0 when the reports were left out
+}
+
+// Source is what a backup is made of
+type Source struct {
+	ConfigPath  string            // Config file, copied as is
+	Database    string            // Consistent copy of the database, see storage.SnapshotDatabase
+	Prompts     map[string]string // Prompt contents by name, "<scene>/<file name without .txt>"
+	ReportsPath string            // Reports directory, empty to leave the reports out
+}
+
+// Create writes a backup of src to w
+func Create(w io.Writer, src *Source, now time.Time) (*Manifest, error) {
+	manifest := &Manifest{Version: FormatVersion, CreatedAt: now}
+	manifest.Hostname, _ = os.Hostname()
+	for name, content := range src.Prompts {
+		scene, _, ok := strings.Cut(name, "/")
+		if ok && promptSceneKeys[scene] != "" && content != "" {
+			manifest.Prompts = append(manifest.Prompts, name)
+		}
+	}
+	sort.Strings(manifest.Prompts)
+
+	var reports []string
+	if src.ReportsPath != "" {
+		err := filepath.WalkDir(src.ReportsPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				reports = append(reports, p)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to list reports: %w", err)
+		}
+		manifest.Reports = len(reports)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeBytes(tw, manifestName, data, now); err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, configName, src.ConfigPath); err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, databaseName, src.Database); err != nil {
+		return nil, err
+	}
+	for _, name := range manifest.Prompts {
+		if err := writeBytes(tw, path.Join(promptsDir, name+".txt"), []byte(src.Prompts[name]), now); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range reports {
+		rel, err := filepath.Rel(src.ReportsPath, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get report path: %w", err)
+		}
+		if err := writeFile(tw, path.Join(reportsDir, filepath.ToSlash(rel)), p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeBytes(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeFile streams a file from disk into the archive, the database and reports can be large
+func writeFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+	header := &tar.Header{Name: name, Mode: 0600, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Restore unpacks a backup into the target directory and writes its config with the database,
+// reports, screenshot and prompt paths pointed at the target. Existing files are only replaced
+// with force, without it the backup is read twice so nothing is written when any file exists
+func Restore(r io.ReadSeeker, target string, force bool) (*Manifest, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target directory: %w", err)
+	}
+	if !force {
+		if err := checkExisting(r, target); err != nil {
+			return nil, err
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+	}
+
+	var manifest *Manifest
+	var configData []byte
+	scenes := make(map[string]bool)
+	err = readEntries(r, func(m *Manifest) { manifest = m }, func(name, dest string, tr io.Reader) error {
+		if name == configName {
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("failed to read config: %w", err)
+			}
+			configData = data
+			return nil
+		}
+		if strings.HasPrefix(name, promptsDir+"/") {
+			scene, _, _ := strings.Cut(strings.TrimPrefix(name, promptsDir+"/"), "/")
+			scenes[scene] = true
+		}
+		return extractFile(tr, filepath.Join(target, filepath.FromSlash(dest)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if configData == nil {
+		return nil, fmt.Errorf("backup has no config file")
+	}
+
+	settings := map[string]string{
+		"storage.db_path":         filepath.Join(target, filepath.FromSlash(RestoredDB)),
+		"storage.reports_path":    filepath.Join(target, filepath.FromSlash(RestoredReports)),
+		"screenshot.storage_path": filepath.Join(target, filepath.FromSlash(RestoredScreenshots)),
+	}
+	for scene := range scenes {
+		settings[promptSceneKeys[scene]] = filepath.Join(target, RestoredPrompts, scene)
+	}
+	for _, dir := range []string{RestoredReports, RestoredScreenshots} {
+		if err := os.MkdirAll(filepath.Join(target, filepath.FromSlash(dir)), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	if err := writeConfig(configData, settings, filepath.Join(target, RestoredConfig)); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// checkExisting fails with the first file of the backup that already exists in the target directory
+func checkExisting(r io.Reader, target string) error {
+	return readEntries(r, func(*Manifest) {}, func(name, dest string, _ io.Reader) error {
+		p := filepath.Join(target, filepath.FromSlash(dest))
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("%s already exists (use --force to replace it)", p)
+		}
+		return nil
+	})
+}
+
+// readEntries reads a backup, passing its manifest to onManifest and every file to restore to onFile
+// with its path inside the backup and its destination relative to the target directory
+func readEntries(r io.Reader, onManifest func(*Manifest), onFile func(name, dest string, r io.Reader) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("not a backup file: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	hasManifest := false
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if !hasManifest {
+			if name != manifestName {
+				return fmt.Errorf("not a backup file: missing manifest")
+			}
+			manifest := &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return fmt.Errorf("failed to read manifest: %w", err)
+			}
+			if manifest.Version > FormatVersion {
+				return fmt.Errorf("backup format version %d is newer than supported (%d), upgrade stuff-time", manifest.Version, FormatVersion)
+			}
+			hasManifest = true
+			onManifest(manifest)
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in backup: %s", header.Name)
+		}
+
+		var dest string
+		switch {
+		case name == configName:
+			dest = RestoredConfig
+		case name == databaseName:
+			dest = RestoredDB
+		case strings.HasPrefix(name, promptsDir+"/"):
+			scene, _, _ := strings.Cut(strings.TrimPrefix(name, promptsDir+"/"), "/")
+			if promptSceneKeys[scene] == "" {
+				continue
+			}
+			dest = name
+		case strings.HasPrefix(name, reportsDir+"/"):
+			dest = path.Join(RestoredReports, strings.TrimPrefix(name, reportsDir+"/"))
+		default:
+			continue
+		}
+		if err := onFile(name, dest, tr); err != nil {
+			return err
+		}
+	}
+	if !hasManifest {
+		return fmt.Errorf("not a backup file: missing manifest")
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+// writeConfig writes the backed up config to dest with the settings replaced
+// The config is rewritten from its parsed values, comments are not kept
+func writeConfig(data []byte, settings map[string]string, dest string) error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to parse backed up config: %w", err)
+	}
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	v.SetConfigPermissions(0600)
+	if err := v.WriteConfigAs(dest); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
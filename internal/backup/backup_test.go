@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestCreateRestore(t *testing.T) {
+	src := t.TempDir()
+	configPath := filepath.Join(src, "config.yaml")
+	config := "storage:\n  db_path: /old/db/stuff-time.db\n  day_start_hour: 4\nopenai:\n  model: gpt-4o\n  summary_path: /old/prompts/summary\n"
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(src, "snapshot.db")
+	if err := os.WriteFile(dbPath, []byte("database"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	reports := filepath.Join(src, "reports")
+	if err := os.MkdirAll(filepath.Join(reports, "2025", "12"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(reports, "2025", "12", "day.md"), []byte("# day"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	manifest, err := Create(&buf, &Source{
+		ConfigPath:  configPath,
+		Database:    dbPath,
+		Prompts:     map[string]string{"summary/day": "day prompt", "summary/week": "", "unknown/x": "ignored"},
+		ReportsPath: reports,
+	}, time.Date(2025, 12, 9, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(manifest.Prompts) != 1 || manifest.Reports != 1 {
+		t.Fatalf("manifest = %+v, want 1 prompt and 1 report", manifest)
+	}
+
+	target := t.TempDir()
+	data := buf.Bytes()
+	if _, err := Restore(bytes.NewReader(data), target, false); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for name, want := range map[string]string{
+		RestoredDB:                    "database",
+		"prompts/summary/day.txt":     "day prompt",
+		"data/reports/2025/12/day.md": "# day",
+	} {
+		got, err := os.ReadFile(filepath.Join(target, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(target, RestoredConfig))
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("restored config: %v", err)
+	}
+	if got := v.GetString("storage.db_path"); got != filepath.Join(target, RestoredDB) {
+		t.Errorf("storage.db_path = %q", got)
+	}
+	if got := v.GetString("openai.summary_path"); got != filepath.Join(target, "prompts", "summary") {
+		t.Errorf("openai.summary_path = %q", got)
+	}
+	if v.GetString("openai.model") != "gpt-4o" || v.GetInt("storage.day_start_hour") != 4 {
+		t.Errorf("restored config lost settings: %v", v.AllSettings())
+	}
+
+	if _, err := Restore(bytes.NewReader(data), target, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Restore into an existing installation: err = %v, want already exists", err)
+	}
+	if _, err := Restore(bytes.NewReader(data), target, true); err != nil {
+		t.Errorf("Restore with force: %v", err)
+	}
+	for _, dir := range []string{"data", RestoredReports, RestoredScreenshots, "prompts"} {
+		info, err := os.Stat(filepath.Join(target, filepath.FromSlash(dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0700 {
+			t.Errorf("%s: mode = %v, want 0700", dir, info.Mode().Perm())
+		}
+	}
+
+	// An existing report alone is enough to refuse, before anything is written
+	partial := t.TempDir()
+	existing := filepath.Join(partial, "data", "reports", "2025", "12", "day.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("# edited"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(bytes.NewReader(data), partial, false); err == nil || !strings.Contains(err.Error(), existing) {
+		t.Errorf("Restore over an existing report: err = %v, want %s already exists", err, existing)
+	}
+	if _, err := os.Stat(filepath.Join(partial, RestoredDB)); !os.IsNotExist(err) {
+		t.Errorf("refused restore wrote the database: %v", err)
+	}
+	if got, _ := os.ReadFile(existing); string(got) != "# edited" {
+		t.Errorf("refused restore replaced the report with %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+// Package breaks detects breaks (idle gaps within work hours) between captures and the continuous
+// work stretches they separate
+package breaks
+
+import "time"
+
+// Sample is one capture; Idle is set for lock screen and desktop captures
+type Sample struct {
+	Time time.Time
+	Idle bool
+}
+
+// Span is a period of time, a break or a work stretch
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the span
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Stats is the break cadence of a day
+type Stats struct {
+	Breaks         []Span        // Chronological
+	BreakTime      time.Duration // Total time of the breaks
+	Stretches      []Span        // Continuous work between breaks, chronological
+	LongestStretch Span
+}
+
+// AverageStretch returns the mean length of the work stretches
+func (s *Stats) AverageStretch() time.Duration {
+	if len(s.Stretches) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, stretch := range s.Stretches {
+		total += stretch.Duration()
+	}
+	return total / time.Duration(len(s.Stretches))
+}
+
+// Detect finds the breaks in chronologically ordered samples
+// Each working sample covers one capture interval; a gap of at least minBreak between working
+// samples (no captures, or only idle ones) ends the work stretch, and counts as a break when it
+// starts within work hours (inWorkHours nil treats all time as work hours)
+func Detect(samples []Sample, interval, minBreak time.Duration, inWorkHours func(time.Time) bool) *Stats {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if minBreak < interval {
+		minBreak = interval
+	}
+
+	stats := &Stats{}
+	var stretch *Span
+	closeStretch := func() {
+		if stretch == nil {
+			return
+		}
+		stats.Stretches = append(stats.Stretches, *stretch)
+		if stretch.Duration() > stats.LongestStretch.Duration() {
+			stats.LongestStretch = *stretch
+		}
+		stretch = nil
+	}
+
+	for _, s := range samples {
+		if s.Idle {
+			continue
+		}
+		if stretch != nil {
+			if gap := s.Time.Sub(stretch.End); gap >= minBreak {
+				if inWorkHours == nil || inWorkHours(stretch.End) {
+					stats.Breaks = append(stats.Breaks, Span{Start: stretch.End, End: s.Time})
+					stats.BreakTime += gap
+				}
+				closeStretch()
+			}
+		}
+		if stretch == nil {
+			stretch = &Span{Start: s.Time}
+		}
+		stretch.End = s.Time.Add(interval)
+	}
+	closeStretch()
+	return stats
+}
@@ -0,0 +1,45 @@
+package breaks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	base := time.Date(2025, 12, 9, 9, 0, 0, 0, time.Local)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	var samples []Sample
+	// 09:00-10:00 work, lock screen 10:00-10:20 (no captures), 10:20-10:30 work,
+	// 10:30-10:40 idle desktop captures, 10:40-10:43 work with a 2 minute gap that is too short
+	for m := 0; m < 60; m++ {
+		samples = append(samples, Sample{Time: at(m)})
+	}
+	for m := 20; m < 30; m++ {
+		samples = append(samples, Sample{Time: at(60 + m)})
+	}
+	for m := 30; m < 40; m++ {
+		samples = append(samples, Sample{Time: at(60 + m), Idle: true})
+	}
+	samples = append(samples, Sample{Time: at(100)}, Sample{Time: at(103)})
+
+	stats := Detect(samples, time.Minute, 5*time.Minute, nil)
+	if len(stats.Breaks) != 2 {
+		t.Fatalf("got %d breaks, want 2", len(stats.Breaks))
+	}
+	if stats.BreakTime != 30*time.Minute {
+		t.Errorf("BreakTime = %v, want 30m", stats.BreakTime)
+	}
+	if !stats.LongestStretch.Start.Equal(at(0)) || stats.LongestStretch.Duration() != time.Hour {
+		t.Errorf("LongestStretch = %v +%v, want 09:00 +1h", stats.LongestStretch.Start, stats.LongestStretch.Duration())
+	}
+	if len(stats.Stretches) != 3 || stats.Stretches[2].Duration() != 4*time.Minute {
+		t.Errorf("Stretches = %+v, want 3 with a 4 minute last stretch", stats.Stretches)
+	}
+
+	// Gaps starting outside work hours end the stretch without counting as a break
+	afternoonOnly := func(t time.Time) bool { return t.Hour() >= 12 }
+	if stats := Detect(samples, time.Minute, 5*time.Minute, afternoonOnly); len(stats.Breaks) != 0 || len(stats.Stretches) != 3 {
+		t.Errorf("outside work hours: %d breaks, %d stretches, want 0 and 3", len(stats.Breaks), len(stats.Stretches))
+	}
+}
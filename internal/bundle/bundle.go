@@ -0,0 +1,203 @@
+// Package bundle packs period reports into an archive for sharing with a third party (a coach,
+// therapist or manager): a gzip-compressed tar, optionally redacted and optionally encrypted with
+// a password (AES-256-GCM), so the recipient can tell the bundle was not changed on the way
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Iterations is the PBKDF2 iteration count used to derive the encryption key from the password
+const Iterations = 600000
+
+// MinPasswordLength is the shortest accepted password
+const MinPasswordLength = 8
+
+// encryptedMagic starts encrypted bundles, followed by the salt, the nonce and the sealed archive
+const encryptedMagic = "STBUNDLE1"
+
+// saltSize is the length of the random PBKDF2 salt
+const saltSize = 16
+
+// File is a file in a bundle
+type File struct {
+	Path    string // Slash-separated path inside the bundle
+	Content []byte
+}
+
+// Write writes files as a gzip-compressed tar to w
+func Write(w io.Writer, files []*File, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.Path,
+			Mode:    0600,
+			Size:    int64(len(f.Content)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		if _, err := tw.Write(f.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return nil
+}
+
+// Read reads the files of a gzip-compressed tar written by Write
+func Read(r io.Reader) ([]*File, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	var files []*File
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		files = append(files, &File{Path: header.Name, Content: content})
+	}
+}
+
+// Encrypt encrypts data with AES-256-GCM, using a key derived from password with PBKDF2-SHA256
+// and a random salt. The header (magic, salt and nonce) is authenticated along with the data, so
+// Decrypt rejects a bundle that was changed in any way
+func Encrypt(data []byte, password string) ([]byte, error) {
+	if len(password) < MinPasswordLength {
+		return nil, fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := deriveAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, len(encryptedMagic)+len(salt)+len(nonce))
+	header = append(append(append(header, encryptedMagic...), salt...), nonce...)
+	return aead.Seal(bytes.Clone(header), nonce, data, header), nil
+}
+
+// Decrypt reverses Encrypt, failing if the password is wrong or the bundle was changed
+func Decrypt(data []byte, password string) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		return nil, fmt.Errorf("not an encrypted stuff-time bundle")
+	}
+	if len(data) < len(encryptedMagic)+saltSize {
+		return nil, fmt.Errorf("encrypted bundle is truncated")
+	}
+	aead, err := deriveAEAD(password, data[len(encryptedMagic):len(encryptedMagic)+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptedMagic) + saltSize + aead.NonceSize()
+	if len(data) < headerSize+aead.Overhead() {
+		return nil, fmt.Errorf("encrypted bundle is truncated")
+	}
+	plaintext, err := aead.Open(nil, data[headerSize-aead.NonceSize():headerSize], data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("wrong password or damaged bundle")
+	}
+	return plaintext, nil
+}
+
+// DecryptCommand returns the command that decrypts and unpacks an encrypted bundle
+func DecryptCommand(name string) string {
+	return fmt.Sprintf("stuff-time export --decrypt %s", name)
+}
+
+func deriveAEAD(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, password, salt, Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// Redaction replaces redacted text
+const Redaction = "[已脱敏]"
+
+var (
+	imagePattern = regexp.MustCompile(`(?m)^!\[[^\]]*\]\([^)]*\)\n*`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	urlPattern   = regexp.MustCompile(`https?://[^\s)\]>"']+`)
+)
+
+// Redactor removes screenshot images, e-mail addresses, URLs and configured terms from reports
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a redactor for the configured terms (case-insensitive) and regular expressions
+func NewRedactor(terms, patterns []string) (*Redactor, error) {
+	r := &Redactor{patterns: []*regexp.Regexp{emailPattern, urlPattern}}
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			r.patterns = append(r.patterns, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(term)))
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns content with screenshot images removed and sensitive text replaced
+func (r *Redactor) Redact(content string) string {
+	content = imagePattern.ReplaceAllString(content, "")
+	for _, re := range r.patterns {
+		content = re.ReplaceAllString(content, Redaction)
+	}
+	return content
+}
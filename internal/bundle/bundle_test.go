@@ -0,0 +1,90 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEncryptedBundle(t *testing.T) {
+	files := []*File{
+		{Path: "2025/Q4/12/W2/09/day.md", Content: []byte("# 日周期总结报告\n")},
+		{Path: "2025/Q4/12/week-W2.md", Content: []byte("# 周周期总结报告\n")},
+	}
+	var archive bytes.Buffer
+	if err := Write(&archive, files, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Encrypt(archive.Bytes(), "short"); err == nil {
+		t.Errorf("Encrypt() accepted a short password")
+	}
+	encrypted, err := Encrypt(archive.Bytes(), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte("周期总结")) {
+		t.Fatalf("encrypted bundle contains plain text")
+	}
+
+	if _, err := Decrypt(encrypted, "wrong horse"); err == nil {
+		t.Errorf("Decrypt() accepted a wrong password")
+	}
+	for _, i := range []int{len(encryptedMagic), len(encrypted) / 2, len(encrypted) - 1} {
+		tampered := bytes.Clone(encrypted)
+		tampered[i] ^= 1
+		if _, err := Decrypt(tampered, "correct horse"); err == nil {
+			t.Errorf("Decrypt() accepted a bundle changed at byte %d", i)
+		}
+	}
+	if _, err := Decrypt(encrypted[:len(encrypted)-1], "correct horse"); err == nil {
+		t.Errorf("Decrypt() accepted a truncated bundle")
+	}
+
+	decrypted, err := Decrypt(encrypted, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(bytes.NewReader(decrypted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(files) {
+		t.Fatalf("Read() returned %d files, want %d", len(got), len(files))
+	}
+	for i, want := range files {
+		if got[i].Path != want.Path || !bytes.Equal(got[i].Content, want.Content) {
+			t.Errorf("entry %s = %q, want %s = %q", got[i].Path, got[i].Content, want.Path, want.Content)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	r, err := NewRedactor([]string{"Acme Corp"}, []string{`ACME-\d+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"term case-insensitive", "为 acme corp 准备方案", "为 [已脱敏] 准备方案"},
+		{"pattern", "处理 ACME-123 工单", "处理 [已脱敏] 工单"},
+		{"email", "回复 alice@example.com 的邮件", "回复 [已脱敏] 的邮件"},
+		{"url", "阅读 https://wiki.example.com/page?id=1 文档", "阅读 [已脱敏] 文档"},
+		{"image removed", "## 高光时刻\n\n![14:03](/data/shots/a.png)\n\n评审 PR\n", "## 高光时刻\n\n评审 PR\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.content); got != tt.want {
+				t.Errorf("Redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewRedactor(nil, []string{"("}); err == nil {
+		t.Errorf("NewRedactor() accepted an invalid pattern")
+	}
+}
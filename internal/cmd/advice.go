@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/evaluator"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+// advicePeriodTypes are the period types whose evaluations within a week are aggregated
+var advicePeriodTypes = []string{"halfhour", "hour", "work-segment", "day", "week"}
+
+var (
+	adviceConfigPath string
+	adviceWeek       string
+	adviceOutput     string
+)
+
+func NewPromptAdviceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt-advice",
+		Short: "Suggest prompt changes from a week of report evaluations",
+		Long: `Aggregate the evaluation reports written by "evaluate" for the periods of a week and run a
+meta-analysis that looks for problems recurring across reports (e.g. summaries keep mentioning
+ticket numbers that are not visible in the screenshots) and suggests concrete changes to the
+summary prompts.
+
+The advice is saved next to the week report as week-W<N>-prompt-advice.md. Periods without an
+evaluation report are skipped, so run "evaluate" on the week's reports first (e.g. with --children).
+
+Examples:
+  stuff-time prompt-advice                     # Last week
+  stuff-time prompt-advice --week 2025-12-09   # The week containing this day`,
+		RunE: runPromptAdvice,
+	}
+
+	cmd.Flags().StringVarP(&adviceConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&adviceWeek, "week", "w", "", "Any day of the week (YYYY-MM-DD), defaults to last week")
+	cmd.Flags().StringVarP(&adviceOutput, "output", "o", "", "Output path (default: next to the week report)")
+
+	return cmd
+}
+
+func runPromptAdvice(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(adviceConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Evaluator.AdvicePromptContent == "" {
+		return fmt.Errorf("prompt advice prompt not configured (check evaluator.advice_path in config)")
+	}
+
+	day := cfg.Storage.LogicalDate(time.Now()).AddDate(0, 0, -7)
+	if adviceWeek != "" {
+		day, err = time.ParseInLocation("2006-01-02", adviceWeek, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid week date: %w", err)
+		}
+	}
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	weekStart := cfg.Storage.DateStart(day.AddDate(0, 0, -(weekday - 1)))
+	weekEnd := cfg.Storage.DateStart(weekStart.AddDate(0, 0, 7))
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	var entries []*evaluator.AdviceEntry
+	for _, periodType := range advicePeriodTypes {
+		summaries, err := st.QueryPeriodSummaries(periodType, weekStart, weekEnd)
+		if err != nil {
+			return fmt.Errorf("failed to query %s summaries: %w", periodType, err)
+		}
+		for _, summary := range summaries {
+			report, err := os.ReadFile(buildEvaluationReportPath(cfg.Storage.ReportsPath, summary))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read evaluation of %s: %w", summary.PeriodKey, err)
+			}
+			entries = append(entries, &evaluator.AdviceEntry{
+				PeriodKey:  summary.PeriodKey,
+				PeriodType: summary.PeriodType,
+				Scores:     evaluator.ParseScores(string(report)),
+				Findings:   evaluator.ExtractFindings(string(report)),
+			})
+		}
+	}
+	period := fmt.Sprintf("%s - %s", datefmt.Date(weekStart), datefmt.Date(weekEnd.AddDate(0, 0, -1)))
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stdout, "No evaluation reports found for %s, run \"evaluate\" first\n", period)
+		return nil
+	}
+
+	openAI := analyzer.NewOpenAI(
+		cfg.OpenAI.APIKey,
+		cfg.OpenAI.BaseURL,
+		cfg.OpenAI.Model,
+		cfg.OpenAI.MaxCompletionTokens,
+		cfg.OpenAI.PromptContent,
+		cfg.OpenAI.DesktopLockDetectionPromptContent,
+		cfg.OpenAI.LockScreenDetectionPromptContent,
+		cfg.OpenAI.SummaryModel,
+		cfg.OpenAI.SummaryPromptContent,
+		cfg.OpenAI.SummaryEnhancedContent,
+		cfg.OpenAI.SummaryContextPrefixContent,
+		cfg.OpenAI.SummaryRollingContent,
+		cfg.OpenAI.AnalysisModel,
+		cfg.OpenAI.AnalysisPromptContent,
+	)
+	if err := task.ConfigureClient(cfg, openAI); err != nil {
+		return err
+	}
+	task.RecordUploads(cfg, openAI, st)
+	task.RecordAPICalls(cfg, openAI, st)
+
+	fmt.Fprintf(os.Stdout, "Analyzing %d evaluation report(s) of %s...\n", len(entries), period)
+	eval := evaluator.NewEvaluator(openAI, "", "", "", "", "")
+	advice, err := eval.SuggestPromptChanges(cfg.Evaluator.AdvicePromptContent, cfg.OpenAI.SummaryPromptContent, entries)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# 提示词改进建议\n\n")
+	sb.WriteString(fmt.Sprintf("**周期**: %s\n\n", period))
+	sb.WriteString(fmt.Sprintf("**评测数量**: %d\n\n", len(entries)))
+	sb.WriteString(fmt.Sprintf("**生成时间**: %s\n\n", datefmt.DateTime(time.Now())))
+	sb.WriteString("---\n\n")
+	sb.WriteString(strings.TrimSpace(advice))
+	sb.WriteString("\n\n---\n\n# 评测发现汇总\n\n")
+	sb.WriteString(evaluator.FormatAdviceInput(entries))
+
+	outputPath := adviceOutput
+	if outputPath == "" {
+		weekPath := buildEvaluationReportPath(cfg.Storage.ReportsPath, &storage.PeriodSummary{PeriodType: "week", StartTime: weekStart})
+		outputPath = strings.TrimSuffix(weekPath, "-evaluation.md") + "-prompt-advice.md"
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write prompt advice: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Prompt advice saved: %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"stuff-time/internal/agent"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/scheduler"
+	"stuff-time/internal/screenshot"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentConfigPath string
+	agentOnce       bool
+)
+
+func NewAgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Capture screenshots and push them to a central stuff-time daemon",
+		Long: `Run as a lightweight capture agent: screenshots are taken on the screenshot schedule and
+uploaded to the daemon at agent.server_url, which analyzes and summarizes them together with its own.
+Captures are spooled locally and uploaded in order once the daemon is reachable again.`,
+		RunE: runAgent,
+	}
+
+	cmd.Flags().StringVarP(&agentConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().BoolVar(&agentOnce, "once", false, "Capture once, upload the spool and exit")
+
+	return cmd
+}
+
+func runAgent(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(agentConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	client, err := agent.NewClient(cfg.Agent.ServerURL, cfg.Agent.Token)
+	if err != nil {
+		return err
+	}
+	if cfg.Agent.CACert != "" {
+		if err := client.TrustCA(cfg.Agent.CACert); err != nil {
+			return err
+		}
+	}
+	name := cfg.Agent.Name
+	if name == "" {
+		if name, err = os.Hostname(); err != nil {
+			return fmt.Errorf("failed to get hostname (set agent.name): %w", err)
+		}
+	}
+	spool := &agent.Spool{Dir: cfg.Agent.SpoolPath}
+
+	if err := client.Ping(); err != nil {
+		logger.GetLogger().Warnf("Daemon at %s is not reachable, screenshots will be spooled: %v", cfg.Agent.ServerURL, err)
+	}
+
+	// Embargo windows of the agent's own config apply to its captures; the spool is still flushed
+	capture := scheduler.Embargoed(cfg.IsEmbargoed, "agent capture", func() error {
+		return agentCapture(name, spool)
+	})
+	captureTask := func() error {
+		if err := capture(); err != nil {
+			logger.GetLogger().Warnf("Agent capture failed: %v", err)
+		}
+		sent, err := spool.Flush(client)
+		if sent > 0 {
+			logger.GetLogger().Infof("Uploaded %d screenshot(s) to %s", sent, cfg.Agent.ServerURL)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to upload screenshots (kept in spool): %w", err)
+		}
+		return nil
+	}
+
+	if agentOnce {
+		return captureTask()
+	}
+
+	var sched scheduler.Scheduler
+	if cfg.Screenshot.Cron != "" {
+		sched, err = scheduler.NewCronScheduler(cfg.Screenshot.Cron)
+		if err != nil {
+			return fmt.Errorf("failed to create screenshot cron scheduler: %w", err)
+		}
+	} else {
+		interval, err := cfg.Screenshot.GetIntervalDuration()
+		if err != nil {
+			return fmt.Errorf("failed to parse screenshot interval: %w", err)
+		}
+		sched = scheduler.NewFixedRateScheduler(interval)
+	}
+	if err := sched.Start(captureTask); err != nil {
+		return fmt.Errorf("failed to start screenshot scheduler: %w", err)
+	}
+	logger.GetLogger().Infof("Agent %s started, uploading to %s", name, cfg.Agent.ServerURL)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	logger.GetLogger().Info("Stopping agent...")
+	return sched.Stop()
+}
+
+// agentCapture captures the screen under the mouse into the spool
+// Work hours are applied by the daemon, so the agent captures whenever the screen is unlocked
+func agentCapture(name string, spool *agent.Spool) error {
+	if locked, err := screenshot.IsScreenLocked(); err == nil && locked {
+		logger.GetLogger().Debug("Screen is locked, skipping agent capture")
+		return nil
+	}
+
+	screenID, err := screenshot.GetMouseScreenID()
+	if err != nil {
+		return fmt.Errorf("failed to get mouse screen ID: %w", err)
+	}
+	now := time.Now()
+	imagePath, err := screenshot.CaptureScreen(screenID, filepath.Join(spool.Dir, "capture"), "png")
+	if err != nil {
+		return fmt.Errorf("failed to capture screen: %w", err)
+	}
+
+	meta := &agent.Metadata{Agent: name, Timestamp: now, ScreenID: screenID, Format: "png"}
+	if app, title, err := screenshot.FrontmostWindow(); err == nil {
+		meta.App, meta.Title = app, title
+	}
+	return spool.Add(meta, imagePath)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/backup"
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+var (
+	backupCreateConfigPath string
+	backupCreateReports    bool
+	backupCreateForce      bool
+	backupRestoreTarget    string
+	backupRestoreForce     bool
+)
+
+func NewBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the installation to a single file and restore it on another machine",
+		Long: `Back up everything needed to move stuff-time to another machine into one .stz file: a consistent
+snapshot of the database, the config file, the prompts and optionally the reports. Screenshot images
+are not included; summaries, analyses and reports survive without them.
+
+backup restore unpacks a backup into one directory (default ~/.stuff-time, where the config is found
+without -c): config.yaml, prompts/, data/db and data/reports. The restored config points the database,
+reports, screenshot and prompt paths at that directory; its comments are not kept.
+
+Examples:
+  stuff-time backup create backup.stz
+  stuff-time backup create backup.stz --reports
+  stuff-time backup restore backup.stz
+  stuff-time backup restore backup.stz --target ~/stuff-time --force`,
+	}
+
+	cmd.AddCommand(NewBackupCreateCmd())
+	cmd.AddCommand(NewBackupRestoreCmd())
+
+	return cmd
+}
+
+func NewBackupCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <file>",
+		Short: "Write the database, config and prompts (and optionally reports) to a backup file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBackupCreate,
+	}
+	cmd.Flags().StringVarP(&backupCreateConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().BoolVar(&backupCreateReports, "reports", false, "Also include the report files")
+	cmd.Flags().BoolVar(&backupCreateForce, "force", false, "Overwrite an existing backup file")
+	return cmd
+}
+
+func NewBackupRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Restore a backup file into a working installation directory",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBackupRestore,
+	}
+	cmd.Flags().StringVar(&backupRestoreTarget, "target", "", "Installation directory (default: ~/.stuff-time)")
+	cmd.Flags().BoolVar(&backupRestoreForce, "force", false, "Replace existing files (config, database, prompts, reports) in the target directory")
+	return cmd
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(backupCreateConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configPath := config.FileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file found, pass it with -c")
+	}
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	tmpDir, err := os.MkdirTemp("", "stuff-time-backup-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	snapshot := filepath.Join(tmpDir, "stuff-time.db")
+	if err := st.SnapshotDatabase(snapshot); err != nil {
+		return err
+	}
+
+	src := &backup.Source{
+		ConfigPath: configPath,
+		Database:   snapshot,
+		Prompts:    backupPrompts(cfg),
+	}
+	if backupCreateReports {
+		src.ReportsPath = cfg.Storage.ReportsPath
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	if backupCreateForce {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	f, err := os.OpenFile(args[0], flags, 0600)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	manifest, err := backup.Create(f, src, time.Now())
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write backup file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(args[0])
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Wrote %s: database, config, %d prompts", args[0], len(manifest.Prompts))
+	if backupCreateReports {
+		fmt.Fprintf(os.Stdout, ", %d report files", manifest.Reports)
+	}
+	fmt.Fprintln(os.Stdout)
+	return nil
+}
+
+// backupPrompts returns the loaded content of every prompt by name, including the evaluator prompts
+func backupPrompts(cfg *config.Config) map[string]string {
+	prompts := make(map[string]string)
+	for _, p := range cfg.PromptFiles() {
+		prompts[p.Name] = *p.Content
+	}
+	prompts["evaluation/main"] = cfg.Evaluator.EvaluationPromptContent
+	prompts["evaluation/report-content"] = cfg.Evaluator.ReportContentContent
+	prompts["evaluation/screenshot-source"] = cfg.Evaluator.ScreenshotSourceContent
+	prompts["evaluation/report-format"] = cfg.Evaluator.ReportFormatContent
+	prompts["evaluation/screenshot-source-section"] = cfg.Evaluator.ScreenshotSourceSectionContent
+	prompts["improvement/main"] = cfg.Evaluator.ImprovementPromptContent
+	prompts["improvement/screenshot-source"] = cfg.Evaluator.ImprovementScreenshotSourceContent
+	return prompts
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	target := backupRestoreTarget
+	if target == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory, pass --target: %w", err)
+		}
+		target = filepath.Join(home, ".stuff-time")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer f.Close()
+	manifest, err := backup.Restore(f, target, backupRestoreForce)
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(target, backup.RestoredConfig)
+	fmt.Fprintf(os.Stdout, "Restored backup of %s from %s into %s\n", manifest.Hostname,
+		datefmt.DateTimeMinute(manifest.CreatedAt.Local()), target)
+	fmt.Fprintf(os.Stdout, "  config:   %s\n", configPath)
+	fmt.Fprintf(os.Stdout, "  database: %s\n", filepath.Join(target, backup.RestoredDB))
+	fmt.Fprintf(os.Stdout, "  prompts:  %d\n", len(manifest.Prompts))
+	fmt.Fprintf(os.Stdout, "  reports:  %d files\n", manifest.Reports)
+	fmt.Fprintf(os.Stdout, "Check it with: stuff-time status -c %s\n", configPath)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"stuff-time/internal/config"
 	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
 
 	"github.com/spf13/cobra"
 )
@@ -39,6 +40,15 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Fprintf(os.Stdout, "Cleanup completed. Records older than %d days have been removed.\n", cfg.Storage.RetentionDays)
+
+	if cfg.Personal.Enabled {
+		result, err := task.CleanupPersonalData(cfg, st)
+		if err != nil {
+			return fmt.Errorf("failed to cleanup personal data: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "Personal data older than %d days removed: %d screenshot(s), %d summary(ies), %d report(s).\n",
+			cfg.Personal.RetentionDays, result.Screenshots, result.Summaries, result.Reports)
+	}
 	return nil
 }
 
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	compactConfigPath string
+	compactMonths     int
+	compactDryRun     bool
+)
+
+func NewCompactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Compact old fifteenmin/hour reports into per-month archives",
+		Long: `Compact the fifteenmin and hour report files of old months into one archive per month.
+
+Each compacted month directory (YYYY/QN/MM) gets a tar file holding the reports and an index
+(reports-archive.json) with the position of every report, so backups and file browsers handle two
+files per month instead of thousands of small ones. Day and longer reports are left in place.
+
+Archived reports are still read by summary, stuff-time-view, site build and the report watchdog;
+a report written again later is stored as a file and replaces the archived one at the next run.
+Months compacted before are merged with their new report files.
+
+Examples:
+  stuff-time compact --months 3 --dry-run
+  stuff-time compact --months 6`,
+		RunE: runCompact,
+	}
+
+	cmd.Flags().StringVarP(&compactConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().IntVar(&compactMonths, "months", 0, "Compact months that ended more than N months ago (defaults to storage.compact_after_months, or 3)")
+	cmd.Flags().BoolVar(&compactDryRun, "dry-run", false, "Show what would be compacted without changing anything")
+
+	return cmd
+}
+
+func runCompact(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(compactConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	months := compactMonths
+	if months == 0 {
+		months = cfg.Storage.CompactAfterMonths
+	}
+	if months == 0 {
+		months = 3
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	before := task.CompactBefore(time.Now(), months)
+	result, err := task.CompactReports(cfg, st, months, compactDryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Compacted"
+	if compactDryRun {
+		verb = "Would compact"
+	}
+	fmt.Fprintf(os.Stdout, "%s %d reports (%.1f MB) of %d months before %s\n",
+		verb, result.Reports, float64(result.Bytes)/(1024*1024), result.Months, before.Format("2006-01"))
+	return nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -10,6 +11,7 @@ import (
 )
 
 var configConfigPath string
+var configSchemaOutput string
 
 func NewConfigCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -18,9 +20,46 @@ func NewConfigCmd() *cobra.Command {
 		RunE:  runConfig,
 	}
 	cmd.Flags().StringVarP(&configConfigPath, "config", "c", "", "Path to config file")
+	cmd.AddCommand(NewConfigSchemaCmd())
 	return cmd
 }
 
+func NewConfigSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of config.yaml",
+		Long: `Print a JSON Schema of config.yaml generated from the config structs, for validation and
+autocompletion in editors (e.g. with the YAML language server). Unknown keys are rejected.
+
+Examples:
+  stuff-time config schema -o config/config.schema.json
+  # Then add to the top of config.yaml:
+  # yaml-language-server: $schema=./config.schema.json`,
+		Args: cobra.NoArgs,
+		RunE: runConfigSchema,
+	}
+	cmd.Flags().StringVarP(&configSchemaOutput, "output", "o", "", "Write the schema to a file instead of stdout")
+	return cmd
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	data = append(data, '\n')
+
+	if configSchemaOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(configSchemaOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Schema written to %s\n", configSchemaOutput)
+	return nil
+}
+
 func runConfig(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load(configConfigPath)
 	if err != nil {
@@ -41,6 +80,10 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	fmt.Fprintf(os.Stdout, "  Analysis Interval: %s\n", cfg.Screenshot.AnalysisInterval)
 	fmt.Fprintf(os.Stdout, "  Analysis Cron: %s\n", cfg.Screenshot.AnalysisCron)
 	fmt.Fprintf(os.Stdout, "  Analysis Workers: %d\n", cfg.Screenshot.AnalysisWorkers)
+	if scaling := cfg.Screenshot.WorkerScaling; scaling.Enabled {
+		lo, hi := scaling.Bounds()
+		fmt.Fprintf(os.Stdout, "  Worker Scaling: %d-%d workers, target latency %s\n", lo, hi, scaling.GetTargetLatency())
+	}
 	if len(cfg.Screenshot.SummaryPeriods) > 0 {
 		fmt.Fprintf(os.Stdout, "  Summary Periods: %v\n", cfg.Screenshot.SummaryPeriods)
 	} else {
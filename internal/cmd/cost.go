@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/costs"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	costConfigPath string
+	costMonth      string
+	costFormat     string
+)
+
+func NewCostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Show what the tool itself cost in a month (tokens, dollars, cache hits, latency, failures)",
+		Long: `Show the API usage of the tool itself in a month: tokens and dollars per summary level and per
+model, prompt cache hit rates, tokens saved by de-duplicating sibling summaries, average latency
+and failure rates (retries included), to tune intervals, models and thresholds with data.
+
+Dollars are computed from openai.pricing; calls made before this report existed are not counted.
+
+Examples:
+  stuff-time cost                   # Current month
+  stuff-time cost --month 2025-11
+  stuff-time cost --format json`,
+		RunE: runCost,
+	}
+
+	cmd.Flags().StringVarP(&costConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&costMonth, "month", "m", "", "Month to show (YYYY-MM), defaults to the current month")
+	cmd.Flags().StringVarP(&costFormat, "format", "f", "table", "Output format: table, json")
+
+	return cmd
+}
+
+func runCost(cmd *cobra.Command, args []string) error {
+	if costFormat != "table" && costFormat != "json" {
+		return fmt.Errorf("invalid format: %s (supported: table, json)", costFormat)
+	}
+
+	month := time.Now()
+	if costMonth != "" {
+		var err error
+		month, err = time.ParseInLocation("2006-01", costMonth, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid month format: %w", err)
+		}
+	}
+
+	cfg, err := config.Load(costConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	report, err := task.MonthCosts(cfg, st, month)
+	if err != nil {
+		return fmt.Errorf("failed to compute costs: %w", err)
+	}
+
+	if costFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	costs.Render(os.Stdout, report)
+	return nil
+}
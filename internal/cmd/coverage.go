@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/coverage"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	coverageConfigPath string
+	coverageMonth      string
+	coverageMetric     string
+	coverageFormat     string
+)
+
+func NewCoverageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show a heatmap of captured/analyzed/summarized minutes per hour",
+		Long: `Show a calendar heatmap of how many minutes per hour were captured, analyzed and summarized,
+so silent gaps (screen recording permission lost, daemon not running) stand out.
+
+Examples:
+  stuff-time coverage                             # Current month, captured minutes
+  stuff-time coverage --month 2025-11 --metric analyzed
+  stuff-time coverage --month 2025-11 --format json`,
+		RunE: runCoverage,
+	}
+
+	cmd.Flags().StringVarP(&coverageConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&coverageMonth, "month", "m", "", "Month to show (YYYY-MM), defaults to the current month")
+	cmd.Flags().StringVar(&coverageMetric, "metric", coverage.MetricCaptured, "Heatmap metric: captured, analyzed, summarized")
+	cmd.Flags().StringVarP(&coverageFormat, "format", "f", "table", "Output format: table, json")
+
+	return cmd
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	switch coverageMetric {
+	case coverage.MetricCaptured, coverage.MetricAnalyzed, coverage.MetricSummarized:
+	default:
+		return fmt.Errorf("invalid metric: %s (supported: captured, analyzed, summarized)", coverageMetric)
+	}
+	if coverageFormat != "table" && coverageFormat != "json" {
+		return fmt.Errorf("invalid format: %s (supported: table, json)", coverageFormat)
+	}
+
+	month := time.Now()
+	if coverageMonth != "" {
+		var err error
+		month, err = time.ParseInLocation("2006-01", coverageMonth, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid month format: %w", err)
+		}
+	}
+
+	cfg, err := config.Load(coverageConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	result, err := task.MonthCoverage(cfg, st, month)
+	if err != nil {
+		return fmt.Errorf("failed to compute coverage: %w", err)
+	}
+
+	if coverageFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	coverage.Render(os.Stdout, result, coverageMetric)
+	return nil
+}
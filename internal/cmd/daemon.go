@@ -76,6 +76,15 @@ func getPidFile() string {
 	return filepath.Join(homeDir, ".stuff-time.pid")
 }
 
+// getSocketFile returns the path of the daemon status socket
+func getSocketFile() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "./stuff-time.sock"
+	}
+	return filepath.Join(homeDir, ".stuff-time.sock")
+}
+
 func getLogFile() string {
 	cfg, err := config.Load(daemonConfigPath)
 	if err != nil {
@@ -133,7 +142,8 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 	}
 
 	logFile := getLogFile()
-	logFileHandle, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	// The daemon output repeats the log, so it is private like the rest of the data
+	logFileHandle, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
@@ -219,6 +229,7 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Status: Running (PID: %d)\n", pid)
 		fmt.Printf("PID file: %s\n", getPidFile())
 		fmt.Printf("Log file: %s\n", getLogFile())
+		fmt.Printf("Status socket: %s\n", getSocketFile())
 	} else {
 		fmt.Println("Status: Not running (stale PID file)")
 		removePidFile()
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/config"
+)
+
+// parseDayRange parses the --from/--to dates (YYYY-MM-DD, --to inclusive) of a command into [start, end)
+// Without --from the range starts today, without --to it ends after the --from day;
+// days begin at storage.day_start_hour
+func parseDayRange(cfg *config.Config, from, to string) (time.Time, time.Time, error) {
+	start := cfg.Storage.DayStart(time.Now())
+	if from != "" {
+		var err error
+		if start, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	end := start.AddDate(0, 0, 1)
+	if to != "" {
+		toDate, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to date: %w", err)
+		}
+		end = toDate.AddDate(0, 0, 1)
+	}
+
+	start, end = cfg.Storage.DateStart(start), cfg.Storage.DateStart(end)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--from must not be after --to")
+	}
+	return start, end, nil
+}
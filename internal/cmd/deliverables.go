@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	deliverablesConfigPath string
+	deliverablesFrom       string
+	deliverablesTo         string
+	deliverablesExtract    bool
+)
+
+func NewDeliverablesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deliverables",
+		Short: "List concrete deliverables (PRs, docs, tickets) in a date range",
+		Long: `List deliverables extracted from screenshot analyses and configured git repositories.
+Deliverables are extracted automatically when a day summary is generated; use --extract to re-run
+the extraction for each day in the range.
+
+Examples:
+  stuff-time deliverables                                  # Today
+  stuff-time deliverables --from 2025-12-01 --to 2025-12-07
+  stuff-time deliverables --from 2025-12-09 --extract`,
+		RunE: runDeliverables,
+	}
+
+	cmd.Flags().StringVarP(&deliverablesConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&deliverablesFrom, "from", "", "Start date (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&deliverablesTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to --from")
+	cmd.Flags().BoolVar(&deliverablesExtract, "extract", false, "Re-run deliverable extraction for each day before listing")
+
+	return cmd
+}
+
+func runDeliverables(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(deliverablesConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	start, end, err := parseDayRange(cfg, deliverablesFrom, deliverablesTo)
+	if err != nil {
+		return err
+	}
+
+	if deliverablesExtract {
+		if storage.IsReadOnly() {
+			return fmt.Errorf("--extract is not available in read-only mode")
+		}
+		executor, err := task.NewExecutor(cfg, st)
+		if err != nil {
+			return fmt.Errorf("failed to create executor: %w", err)
+		}
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			extracted, err := executor.ExtractDeliverables(day)
+			if err != nil {
+				fmt.Fprintf(os.Stdout, "WARNING: %s: %v\n", datefmt.Date(day), err)
+				continue
+			}
+			fmt.Fprintf(os.Stdout, "Extracted %d deliverable(s) for %s\n", len(extracted), datefmt.Date(day))
+		}
+		fmt.Fprintf(os.Stdout, "\n")
+	}
+
+	deliverables, err := st.QueryDeliverables(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query deliverables: %w", err)
+	}
+	if len(deliverables) == 0 {
+		fmt.Fprintf(os.Stdout, "No deliverables found for %s - %s\n", datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "Deliverables (%d):\n", len(deliverables))
+	for _, d := range deliverables {
+		line := fmt.Sprintf("  %s  [%-7s] %s", datefmt.DateTimeMinute(d.Timestamp), d.Kind, d.Title)
+		if d.Reference != "" {
+			line += fmt.Sprintf(" (%s)", d.Reference)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", line)
+	}
+
+	return nil
+}
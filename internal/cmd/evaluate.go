@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,27 +12,38 @@ import (
 	"stuff-time/internal/config"
 	"stuff-time/internal/evaluator"
 	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
 )
 
 var evaluateConfigPath string
-var evaluatePeriodKey string
+var evaluatePeriodKeys []string
 var evaluatePeriodType string
 var evaluateDate string
 var evaluateOutput string
+var evaluateChildren bool
+var evaluateWorkers int
+var evaluateImprove bool
 
 func NewEvaluateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "evaluate",
 		Short: "Evaluate period report quality using LLM",
-		Long:  "Evaluate the quality of a period report (accuracy, relevance, depth) using LLM and generate a Markdown evaluation report.",
-		RunE:  runEvaluate,
+		Long: `Evaluate the quality of a period report (accuracy, relevance, depth) using LLM and generate a Markdown evaluation report.
+
+Several periods can be evaluated at once (repeated --period-key, or --children for the periods one level below,
+e.g. the hours of a day). Their screenshots are fetched once and shared, and the evaluations run in parallel.
+With --improve every evaluated report is also improved right away, reusing the same screenshots.`,
+		RunE: runEvaluate,
 	}
 
 	cmd.Flags().StringVarP(&evaluateConfigPath, "config", "c", "", "Path to config file")
-	cmd.Flags().StringVar(&evaluatePeriodKey, "period-key", "", "Directly specify period key (e.g., \"2025-11-19\")")
+	cmd.Flags().StringSliceVar(&evaluatePeriodKeys, "period-key", nil, "Directly specify period key (e.g., \"2025-11-19\"), repeatable")
 	cmd.Flags().StringVarP(&evaluatePeriodType, "period-type", "p", "", "Period type (hour, day, week, month, year)")
 	cmd.Flags().StringVarP(&evaluateDate, "date", "d", "", "Date for period (YYYY-MM-DD), used with --period-type")
-	cmd.Flags().StringVarP(&evaluateOutput, "output", "o", "", "Output path for evaluation report (default: save to reports/evaluations/)")
+	cmd.Flags().StringVarP(&evaluateOutput, "output", "o", "", "Output path for evaluation report (default: save to reports/evaluations/), single period only")
+	cmd.Flags().BoolVar(&evaluateChildren, "children", false, "Evaluate the periods one level below the selected periods (e.g. the hours of a day)")
+	cmd.Flags().IntVar(&evaluateWorkers, "workers", 3, "Number of periods evaluated in parallel")
+	cmd.Flags().BoolVar(&evaluateImprove, "improve", false, "Improve each report after evaluating it")
 
 	return cmd
 }
@@ -58,26 +68,48 @@ func runEvaluate(cmd *cobra.Command, args []string) error {
 	}
 	defer st.Close()
 
-	// Determine period key
-	var periodKey string
-	if evaluatePeriodKey != "" {
-		periodKey = evaluatePeriodKey
-	} else if evaluatePeriodType != "" {
-		periodKey, err = buildPeriodKey(evaluatePeriodType, evaluateDate)
+	// Determine period keys
+	periodKeys := evaluatePeriodKeys
+	if len(periodKeys) == 0 {
+		if evaluatePeriodType == "" {
+			return fmt.Errorf("must specify either --period-key or --period-type")
+		}
+		periodKey, err := buildPeriodKey(cfg, evaluatePeriodType, evaluateDate)
 		if err != nil {
 			return fmt.Errorf("failed to build period key: %w", err)
 		}
-	} else {
-		return fmt.Errorf("must specify either --period-key or --period-type")
+		periodKeys = []string{periodKey}
 	}
 
-	// Get period summary from database
-	summary, err := st.GetPeriodSummary(periodKey)
-	if err != nil {
-		return fmt.Errorf("failed to get period summary: %w", err)
+	// Get period summaries from database
+	var summaries []*storage.PeriodSummary
+	for _, periodKey := range periodKeys {
+		summary, err := st.GetPeriodSummary(periodKey)
+		if err != nil {
+			return fmt.Errorf("failed to get period summary: %w", err)
+		}
+		if summary == nil {
+			return fmt.Errorf("period summary not found for key: %s", periodKey)
+		}
+		if !evaluateChildren {
+			summaries = append(summaries, summary)
+			continue
+		}
+		childType := task.LowerLevelPeriodType(summary.PeriodType)
+		if childType == "" {
+			return fmt.Errorf("%s has no lower-level periods", periodKey)
+		}
+		children, err := st.QueryPeriodSummaries(childType, summary.StartTime, summary.EndTime)
+		if err != nil {
+			return fmt.Errorf("failed to query %s summaries: %w", childType, err)
+		}
+		summaries = append(summaries, children...)
 	}
-	if summary == nil {
-		return fmt.Errorf("period summary not found for key: %s", periodKey)
+	if len(summaries) == 0 {
+		return fmt.Errorf("no period summaries to evaluate")
+	}
+	if evaluateOutput != "" && len(summaries) > 1 {
+		return fmt.Errorf("--output can only be used when evaluating a single period")
 	}
 
 	// Create analyzer
@@ -97,67 +129,103 @@ func runEvaluate(cmd *cobra.Command, args []string) error {
 		cfg.OpenAI.AnalysisModel,
 		cfg.OpenAI.AnalysisPromptContent,
 	)
-
-	// Get screenshot records for traceability
-	var screenshotRecords map[string]*storage.ScreenshotRecord
-	screenshotIDs := strings.Split(summary.Screenshots, ",")
-	if len(screenshotIDs) > 0 && summary.Screenshots != "" {
-		// Filter out empty IDs
-		validIDs := make([]string, 0, len(screenshotIDs))
-		for _, id := range screenshotIDs {
-			id = strings.TrimSpace(id)
-			if id != "" {
-				validIDs = append(validIDs, id)
-			}
-		}
-		if len(validIDs) > 0 {
-			screenshotRecords, err = st.GetScreenshotsByIDs(validIDs)
-			if err != nil {
-				return fmt.Errorf("failed to get screenshot records: %w", err)
-			}
-			fmt.Fprintf(os.Stdout, "Found %d/%d screenshot records for traceability\n", len(screenshotRecords), len(validIDs))
-		}
+	if err := task.ConfigureClient(cfg, openAI); err != nil {
+		return err
 	}
+	task.RecordUploads(cfg, openAI, st)
+	task.RecordAPICalls(cfg, openAI, st)
 
-	// Create evaluator
-	eval := evaluator.NewEvaluator(
-		openAI,
-		cfg.Evaluator.EvaluationPromptContent,
-		cfg.Evaluator.ReportContentContent,
-		cfg.Evaluator.ScreenshotSourceContent,
-		cfg.Evaluator.ReportFormatContent,
-		cfg.Evaluator.ScreenshotSourceSectionContent,
-	)
-
-	// Evaluate report
-	fmt.Fprintf(os.Stdout, "Evaluating period report (key: %s)...\n", periodKey)
-	evaluationReport, err := eval.EvaluateReport(summary, screenshotRecords)
+	// Get screenshot records for traceability, once for all periods and passes
+	screenshotRecords, err := prefetchScreenshots(st, summaries, "traceability")
 	if err != nil {
-		return fmt.Errorf("failed to evaluate report: %w", err)
+		return err
 	}
 
-	// Determine output path
-	outputPath := evaluateOutput
-	if outputPath == "" {
-		outputPath = buildEvaluationReportPath(cfg.Storage.ReportsPath, summary)
+	// Create evaluator (with the improvement prompt when improving)
+	var eval *evaluator.Evaluator
+	if evaluateImprove {
+		if eval, err = newImprovementEvaluator(cfg, openAI); err != nil {
+			return err
+		}
+	} else {
+		eval = evaluator.NewEvaluator(
+			openAI,
+			cfg.Evaluator.EvaluationPromptContent,
+			cfg.Evaluator.ReportContentContent,
+			cfg.Evaluator.ScreenshotSourceContent,
+			cfg.Evaluator.ReportFormatContent,
+			cfg.Evaluator.ScreenshotSourceSectionContent,
+		)
 	}
 
-	// Ensure output directory exists
-	outputDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	// Evaluate reports
+	for _, summary := range summaries {
+		fmt.Fprintf(os.Stdout, "Evaluating period report (key: %s)...\n", summary.PeriodKey)
 	}
+	results := eval.EvaluateReports(summaries, screenshotRecords, evaluateWorkers)
 
-	// Write evaluation report
-	if err := os.WriteFile(outputPath, []byte(evaluationReport), 0644); err != nil {
-		return fmt.Errorf("failed to write evaluation report: %w", err)
+	var executor *task.Executor
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to evaluate %s: %v\n", result.Summary.PeriodKey, result.Err)
+			failed++
+			continue
+		}
+
+		// Determine output path
+		outputPath := evaluateOutput
+		if outputPath == "" {
+			outputPath = buildEvaluationReportPath(cfg.Storage.ReportsPath, result.Summary)
+		}
+
+		// Ensure output directory exists
+		outputDir := filepath.Dir(outputPath)
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		// Write evaluation report
+		if err := os.WriteFile(outputPath, []byte(result.Report), 0644); err != nil {
+			return fmt.Errorf("failed to write evaluation report: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "Evaluation report saved: %s\n", outputPath)
+
+		if evaluateImprove {
+			if executor == nil {
+				if executor, err = task.NewExecutor(cfg, st); err != nil {
+					return fmt.Errorf("failed to create executor: %w", err)
+				}
+			}
+			fmt.Fprintf(os.Stdout, "Improving period report (key: %s)...\n", result.Summary.PeriodKey)
+			if _, err := improvePeriodReport(cfg, st, executor, eval, result.Summary, outputPath, screenshotRecords); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to improve %s: %v\n", result.Summary.PeriodKey, err)
+				failed++
+			}
+		}
 	}
 
-	fmt.Fprintf(os.Stdout, "Evaluation report saved: %s\n", outputPath)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d period(s) failed", failed, len(summaries))
+	}
 	return nil
 }
 
-func buildPeriodKey(periodType string, date string) (string, error) {
+// prefetchScreenshots fetches the screenshot records referenced by the summaries in one query
+func prefetchScreenshots(st *storage.Storage, summaries []*storage.PeriodSummary, purpose string) (map[string]*storage.ScreenshotRecord, error) {
+	ids := evaluator.ReferencedScreenshotIDs(summaries...)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	records, err := st.GetScreenshotsByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshot records: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Found %d/%d screenshot records for %s\n", len(records), len(ids), purpose)
+	return records, nil
+}
+
+func buildPeriodKey(cfg *config.Config, periodType string, date string) (string, error) {
 	var now time.Time
 	var err error
 
@@ -166,20 +234,19 @@ func buildPeriodKey(periodType string, date string) (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("invalid date format: %w", err)
 		}
-	} else {
+	} else if periodType == "hour" {
 		now = time.Now()
+	} else {
+		now = cfg.Storage.LogicalDate(time.Now())
 	}
 
 	var startTime time.Time
-	var periodKey string
 
 	switch periodType {
 	case "hour":
 		startTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
-		periodKey = startTime.Format("2006-01-02-15")
 	case "day":
 		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		periodKey = startTime.Format("2006-01-02")
 	case "week":
 		weekday := int(now.Weekday())
 		if weekday == 0 {
@@ -187,18 +254,14 @@ func buildPeriodKey(periodType string, date string) (string, error) {
 		}
 		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 		startTime = startTime.AddDate(0, 0, -(weekday - 1))
-		periodKey = startTime.Format("2006-01-02") + "-week"
 	case "month":
 		startTime = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		periodKey = startTime.Format("2006-01")
 	case "year":
 		startTime = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		periodKey = startTime.Format("2006")
 	default:
 		return "", fmt.Errorf("unsupported period type: %s", periodType)
 	}
-
-	return periodKey, nil
+	return storage.FormatPeriodKey(periodType, startTime), nil
 }
 
 func buildEvaluationReportPath(reportsPath string, summary *storage.PeriodSummary) string {
@@ -239,11 +302,9 @@ func buildEvaluationReportPath(reportsPath string, summary *storage.PeriodSummar
 		weekDir := fmt.Sprintf("W%d", weekNum)
 		dayDir := summary.StartTime.Format("02")
 		evalDir = filepath.Join(reportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir)
-		// Extract segment index from period key (format: YYYY-MM-DD-segment-N)
-		parts := strings.Split(summary.PeriodKey, "-")
-		if len(parts) >= 5 && parts[3] == "segment" {
-			segmentNum := parts[4]
-			filename = fmt.Sprintf("work-segment-%s-evaluation.md", segmentNum)
+		// Extract segment index from period key (format: work-segment:YYYY-MM-DD#N)
+		if key, err := storage.ParsePeriodKey(summary.PeriodKey); err == nil && key.Type == "work-segment" {
+			filename = fmt.Sprintf("work-segment-%d-evaluation.md", key.Segment)
 		} else {
 			filename = fmt.Sprintf("%s-evaluation.md", summary.PeriodKey)
 		}
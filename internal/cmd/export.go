@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/bundle"
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+// exportPasswordEnv holds the bundle password for non-interactive use
+const exportPasswordEnv = "STUFF_TIME_EXPORT_PASSWORD"
+
+var (
+	exportConfigPath   string
+	exportFrom         string
+	exportTo           string
+	exportPeriods      string
+	exportOutput       string
+	exportBundle       bool
+	exportEncrypt      bool
+	exportRedact       bool
+	exportPasswordFile string
+	exportDecrypt      string
+)
+
+func NewExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export period reports for sharing, optionally redacted and encrypted",
+		Long: `Export the reports of the selected periods to share them with a third party (a coach,
+therapist or manager). Only periods fully inside the date range are exported, with their
+reports directory layout.
+
+--bundle writes a single .tar.gz archive instead of a directory. --encrypt protects the archive
+with a password (AES-256-GCM, key derived with PBKDF2-SHA256), so changes to the bundle are
+detected; the recipient opens it with --decrypt, which needs no config or data. The password is
+read from --password-file, the ` + exportPasswordEnv + ` environment variable, or asked for.
+
+--redact removes screenshot images and replaces e-mail addresses, URLs and the terms and
+patterns of export.redact_terms / export.redact_patterns with "` + bundle.Redaction + `".
+
+Examples:
+  stuff-time export --from 2025-12-01 --to 2025-12-07 --period day,week
+  stuff-time export --from 2025-12-01 --to 2025-12-31 --period week,month --bundle --encrypt --redact
+  stuff-time export --bundle --encrypt --password-file ~/.coach-password -o coach.tar.gz.enc
+  stuff-time export --decrypt coach.tar.gz.enc -o coach`,
+		RunE: runExport,
+	}
+
+	cmd.Flags().StringVarP(&exportConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&exportFrom, "from", "", "Start date (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&exportTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to --from")
+	cmd.Flags().StringVarP(&exportPeriods, "period", "p", "day,week", "Comma-separated period types to export ("+strings.Join(task.ExportPeriodTypes, ", ")+")")
+	cmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output directory, or file with --bundle (default: in the working directory)")
+	cmd.Flags().BoolVar(&exportBundle, "bundle", false, "Write a single .tar.gz archive")
+	cmd.Flags().BoolVar(&exportEncrypt, "encrypt", false, "Encrypt the bundle with a password (requires --bundle)")
+	cmd.Flags().BoolVar(&exportRedact, "redact", false, "Remove screenshot images and redact e-mail addresses, URLs and configured terms")
+	cmd.Flags().StringVar(&exportPasswordFile, "password-file", "", "Read the bundle password from the first line of this file")
+	cmd.Flags().StringVar(&exportDecrypt, "decrypt", "", "Decrypt an encrypted bundle and unpack it into --output (default: the bundle name without extensions)")
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportDecrypt != "" {
+		return decryptBundle(exportDecrypt, exportOutput)
+	}
+	if exportEncrypt && !exportBundle {
+		return fmt.Errorf("--encrypt requires --bundle")
+	}
+	var periodTypes []string
+	for _, periodType := range strings.Split(exportPeriods, ",") {
+		periodType = strings.TrimSpace(periodType)
+		if !slices.Contains(task.ExportPeriodTypes, periodType) {
+			return fmt.Errorf("unsupported period type %q (supported: %s)", periodType, strings.Join(task.ExportPeriodTypes, ", "))
+		}
+		periodTypes = append(periodTypes, periodType)
+	}
+
+	cfg, err := config.Load(exportConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Storage.ReportsPath == "" {
+		return fmt.Errorf("storage.reports_path is not configured")
+	}
+
+	var redactor *bundle.Redactor
+	if exportRedact {
+		if redactor, err = bundle.NewRedactor(cfg.Export.RedactTerms, cfg.Export.RedactPatterns); err != nil {
+			return err
+		}
+	}
+
+	start, end, err := parseDayRange(cfg, exportFrom, exportTo)
+	if err != nil {
+		return err
+	}
+
+	output := exportOutput
+	if output == "" {
+		output = fmt.Sprintf("stuff-time-export-%s_%s", start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02"))
+		if exportBundle {
+			output += ".tar.gz"
+		}
+		if exportEncrypt {
+			output += ".enc"
+		}
+	}
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("%s already exists", output)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	files, err := task.ExportReports(cfg, st, start, end, periodTypes)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stdout, "No %s reports found for %s - %s\n", strings.Join(periodTypes, "/"), datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+		return nil
+	}
+	if redactor != nil {
+		for _, f := range files {
+			f.Content = []byte(redactor.Redact(string(f.Content)))
+		}
+	}
+
+	if !exportBundle {
+		for _, f := range files {
+			path := filepath.Join(output, filepath.FromSlash(f.Path))
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+			if err := os.WriteFile(path, f.Content, 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+		}
+		fmt.Fprintf(os.Stdout, "Exported %d report(s) to %s\n", len(files), output)
+		return nil
+	}
+
+	var archive bytes.Buffer
+	if err := bundle.Write(&archive, files, time.Now()); err != nil {
+		return err
+	}
+	data := archive.Bytes()
+	if exportEncrypt {
+		password, err := readExportPassword(true)
+		if err != nil {
+			return err
+		}
+		if data, err = bundle.Encrypt(data, password); err != nil {
+			return err
+		}
+	}
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Exported %d report(s) to %s\n", len(files), output)
+	if exportEncrypt {
+		fmt.Fprintf(os.Stdout, "Share the password separately; the recipient unpacks the bundle with:\n  %s\n", bundle.DecryptCommand(filepath.Base(output)))
+	}
+	return nil
+}
+
+// decryptBundle decrypts the bundle at path and writes its files under output
+func decryptBundle(path, output string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	password, err := readExportPassword(false)
+	if err != nil {
+		return err
+	}
+	if data, err = bundle.Decrypt(data, password); err != nil {
+		return err
+	}
+	files, err := bundle.Read(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output, _, _ = strings.Cut(filepath.Base(path), ".")
+	}
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("%s already exists", output)
+	}
+	for _, f := range files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return fmt.Errorf("bundle contains an invalid path: %s", f.Path)
+		}
+		path := filepath.Join(output, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(path, f.Content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	fmt.Fprintf(os.Stdout, "Unpacked %d report(s) to %s\n", len(files), output)
+	return nil
+}
+
+// readExportPassword reads the bundle password from --password-file, the environment, or the terminal,
+// asking twice for a new password
+func readExportPassword(confirmNew bool) (string, error) {
+	if exportPasswordFile != "" {
+		content, err := os.ReadFile(exportPasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		password, _, _ := strings.Cut(string(content), "\n")
+		return strings.TrimRight(password, "\r"), nil
+	}
+	if password := os.Getenv(exportPasswordEnv); password != "" {
+		return password, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	password, err := promptPassword(reader, "Password: ")
+	if err != nil || !confirmNew {
+		return password, err
+	}
+	confirm, err := promptPassword(reader, "Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
+
+// promptPassword reads a line from the terminal without echoing it where stty is available
+func promptPassword(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	stty := exec.Command("stty", "-echo")
+	stty.Stdin = os.Stdin
+	if stty.Run() == nil {
+		defer func() {
+			restore := exec.Command("stty", "echo")
+			restore.Stdin = os.Stdin
+			restore.Run()
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	forgetConfigPath   string
+	forgetFrom         string
+	forgetTo           string
+	forgetApp          string
+	forgetYes          bool
+	forgetDryRun       bool
+	forgetNoRegenerate bool
+)
+
+func NewForgetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forget",
+		Short: "Permanently delete all data captured in a time range",
+		Long: `Permanently delete screenshots, analyses and report files captured in a time range,
+regenerate the affected summaries, and record an audit entry.
+
+Time format: "YYYY-MM-DD HH:MM" or "YYYY-MM-DD" (a date-only --to includes the whole day).
+
+Examples:
+  stuff-time forget --from "2025-12-09 14:00" --to "2025-12-09 15:30" --yes
+  stuff-time forget --from 2025-12-09 --to 2025-12-09 --app WeChat --dry-run`,
+		RunE: runForget,
+	}
+
+	cmd.Flags().StringVarP(&forgetConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&forgetFrom, "from", "", "Start of range (inclusive)")
+	cmd.Flags().StringVar(&forgetTo, "to", "", "End of range (exclusive; date-only includes the whole day)")
+	cmd.Flags().StringVar(&forgetApp, "app", "", "Only forget screenshots of this application")
+	cmd.Flags().BoolVarP(&forgetYes, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&forgetDryRun, "dry-run", false, "Show what would be deleted without deleting anything")
+	cmd.Flags().BoolVar(&forgetNoRegenerate, "no-regenerate", false, "Do not regenerate affected summaries after deletion")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// parseForgetTime parses "YYYY-MM-DD HH:MM" or "YYYY-MM-DD" in local time
+// If endOfDay is true, a date-only value is moved to the start of the next day
+func parseForgetTime(value string, endOfDay bool) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (expected YYYY-MM-DD or YYYY-MM-DD HH:MM)", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	from, err := parseForgetTime(forgetFrom, false)
+	if err != nil {
+		return err
+	}
+	to, err := parseForgetTime(forgetTo, true)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(forgetConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	opts := task.ForgetOptions{From: from, To: to, App: forgetApp, DryRun: true}
+	preview, err := task.ForgetRange(cfg, st, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Range: %s -> %s\n", datefmt.DateTimeMinute(from), datefmt.DateTimeMinute(to))
+	if forgetApp != "" {
+		fmt.Fprintf(os.Stdout, "App filter: %s\n", forgetApp)
+	}
+	fmt.Fprintf(os.Stdout, "Screenshots to delete: %d\n", len(preview.Screenshots))
+	fmt.Fprintf(os.Stdout, "Summaries to delete and regenerate: %d\n", len(preview.DeletedSummaries))
+	if len(preview.UnlockedPeriods) > 0 {
+		fmt.Fprintf(os.Stdout, "Locked periods to unlock: %s\n", strings.Join(preview.UnlockedPeriods, ", "))
+	}
+
+	if len(preview.Screenshots) == 0 {
+		fmt.Fprintf(os.Stdout, "Nothing to forget.\n")
+		return nil
+	}
+	if forgetDryRun {
+		for _, periodKey := range preview.DeletedSummaries {
+			fmt.Fprintf(os.Stdout, "  - %s\n", periodKey)
+		}
+		return nil
+	}
+	if !forgetYes {
+		fmt.Fprintf(os.Stdout, "WARNING: This permanently deletes screenshot images, analyses and reports.\n")
+		fmt.Fprintf(os.Stdout, "Use --yes flag to confirm.\n")
+		return fmt.Errorf("forget cancelled (use --yes to confirm)")
+	}
+
+	opts.DryRun = false
+	result, err := task.ForgetRange(cfg, st, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Deleted %d screenshot(s), %d image file(s), %d report file(s), %d summary(ies).\n",
+		len(result.Screenshots), result.DeletedImages, result.DeletedReports, len(result.DeletedSummaries))
+
+	if forgetNoRegenerate {
+		fmt.Fprintf(os.Stdout, "Skipping summary regeneration. Run 'stuff-time generate' to rebuild affected summaries.\n")
+		return nil
+	}
+
+	executor, err := task.NewExecutor(cfg, st)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "WARNING: Cannot regenerate summaries: %v\n", err)
+		return nil
+	}
+	fmt.Fprintf(os.Stdout, "Regenerating affected summaries...\n")
+	if err := executor.RegenerateRange(from, to); err != nil {
+		return fmt.Errorf("data deleted, but regeneration failed: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Affected summaries regenerated.\n")
+
+	return nil
+}
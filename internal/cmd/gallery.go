@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/gallery"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	galleryConfigPath string
+	galleryDate       string
+	galleryOutput     string
+	galleryWidth      int
+)
+
+func NewGalleryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gallery",
+		Short: "Export a day's screenshots as a self-contained HTML gallery",
+		Long: `Generate a single self-contained HTML page with thumbnails of a day's screenshots
+in chronological order, each captioned by its analysis summary line.
+
+Examples:
+  stuff-time gallery --date 2025-12-09
+  stuff-time gallery --date 2025-12-09 -o ~/Desktop/gallery.html`,
+		RunE: runGallery,
+	}
+
+	cmd.Flags().StringVarP(&galleryConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&galleryDate, "date", "d", "", "Date to export (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVarP(&galleryOutput, "output", "o", "", "Output HTML file (default: gallery-YYYY-MM-DD.html in the reports directory)")
+	cmd.Flags().IntVar(&galleryWidth, "width", gallery.DefaultThumbnailWidth, "Thumbnail width in pixels")
+
+	return cmd
+}
+
+func runGallery(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(galleryConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	date := cfg.Storage.LogicalDate(time.Now())
+	if galleryDate != "" {
+		date, err = time.ParseInLocation("2006-01-02", galleryDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date format: %w", err)
+		}
+	}
+	start := cfg.Storage.DateStart(date)
+	end := start.AddDate(0, 0, 1)
+
+	records, err := st.QueryByDateRange(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stdout, "No screenshots found for %s\n", datefmt.Date(start))
+		return nil
+	}
+
+	if err := task.FetchImages(cfg, records); err != nil {
+		return fmt.Errorf("failed to fetch screenshots: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Building gallery for %d screenshot(s)...\n", len(records))
+	html, err := gallery.Build(fmt.Sprintf("%s 截图画廊", datefmt.Date(start)), records, galleryWidth)
+	if err != nil {
+		return err
+	}
+
+	output := galleryOutput
+	if output == "" {
+		dir := cfg.Storage.ReportsPath
+		// Read-only mode never writes into the archive, export to the working directory instead
+		if dir == "" || storage.IsReadOnly() {
+			dir = "."
+		}
+		output = filepath.Join(dir, fmt.Sprintf("gallery-%s.html", start.Format("2006-01-02")))
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(output, []byte(html), 0644); err != nil {
+		return fmt.Errorf("failed to write gallery: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Gallery written to: %s\n", output)
+	return nil
+}
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
 	"stuff-time/internal/storage"
 	"stuff-time/internal/task"
 )
@@ -17,12 +19,18 @@ var generateDate string
 var generateForceRebuild bool
 var generateUpward bool
 var generateRebuildFrom string
+var generateSandbox string
+var generateOverwriteEdits bool
 
 func NewGenerateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "generate",
 		Short: "Generate period summary reports based on actual data",
-		Long:  "Generate period summary reports based on actual screenshot data. The time range is determined by the actual data available - if data exists up to a certain time, the report will cover that range.",
+		Long: `Generate period summary reports based on actual screenshot data. The time range is determined by the actual data available - if data exists up to a certain time, the report will cover that range.
+
+With --sandbox DIR, reports are written to DIR/reports and summaries to a snapshot of the database in DIR
+instead of the production data, to try out prompts and aggregation settings safely. Later runs with the
+same DIR keep working on its snapshot; delete DIR to start over from the current data.`,
 		RunE:  runGenerate,
 	}
 
@@ -31,6 +39,8 @@ func NewGenerateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&generateDate, "date", "d", "", "Date for period generation (YYYY-MM-DD), defaults to today")
 	cmd.Flags().BoolVarP(&generateForceRebuild, "force-rebuild", "f", false, "Force rebuild from screenshots: ignore existing lower-level summaries and regenerate from raw screenshots layer by layer")
 	cmd.Flags().StringVarP(&generateRebuildFrom, "rebuild-from", "r", "", "Rebuild from specified level (fifteenmin, hour, work-segment, day, week, month, quarter). Keeps the specified level unchanged, but regenerates all higher levels. Mutually exclusive with --force-rebuild.")
+	cmd.Flags().StringVar(&generateSandbox, "sandbox", "", "Write reports to this directory and summaries to a database snapshot in it, leaving production data untouched")
+	cmd.Flags().BoolVar(&generateOverwriteEdits, "overwrite-edits", false, "Overwrite reports edited by hand since they were generated (default: keep them and skip their periods)")
 	cmd.Flags().BoolVarP(&generateUpward, "upward", "u", false, "Generate all higher-level summaries from the specified period. All intermediate level reports will be updated.")
 
 	return cmd
@@ -60,11 +70,29 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 	defer st.Close()
 
+	if generateSandbox != "" {
+		sandboxCfg, sandbox, err := openSandbox(cfg, st, generateSandbox)
+		if err != nil {
+			return err
+		}
+		defer sandbox.Close()
+		cfg, st = sandboxCfg, sandbox
+		fmt.Fprintf(os.Stdout, "Sandbox mode: reports are written to %s\n", cfg.Storage.ReportsPath)
+	}
+
+	if generateOverwriteEdits {
+		cfg.Storage.ManualEdits = "overwrite"
+	}
+
 	executor, err := task.NewExecutor(cfg, st)
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
 
+	// One summary record per run, per-period details are logged at Debug
+	run := logger.StartRun("generate")
+	defer run.Finish()
+
 	// Validate mutually exclusive flags
 	if generateForceRebuild && generateRebuildFrom != "" {
 		return fmt.Errorf("--force-rebuild and --rebuild-from are mutually exclusive")
@@ -89,10 +117,15 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Fprintf(os.Stdout, "Generating %s summary report...\n", generatePeriod)
 		}
-		if err := executor.GenerateSinglePeriodSummary(generatePeriod, generateDate, generateForceRebuild); err != nil {
+		err := executor.GenerateSinglePeriodSummary(generatePeriod, generateDate, generateForceRebuild)
+		switch {
+		case errors.Is(err, storage.ErrNoData):
+			fmt.Fprintf(os.Stdout, "No data for this %s, nothing to generate.\n", generatePeriod)
+		case err != nil:
 			return fmt.Errorf("failed to generate %s summary: %w", generatePeriod, err)
+		default:
+			fmt.Fprintf(os.Stdout, "%s summary report generated successfully.\n", generatePeriod)
 		}
-		fmt.Fprintf(os.Stdout, "%s summary report generated successfully.\n", generatePeriod)
 		
 		// If --upward flag is set, generate all higher-level summaries
 		if generateUpward {
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -62,7 +61,7 @@ func runImprove(cmd *cobra.Command, args []string) error {
 	if improvePeriodKey != "" {
 		periodKey = improvePeriodKey
 	} else if improvePeriodType != "" {
-		periodKey, err = buildPeriodKey(improvePeriodType, improveDate)
+		periodKey, err = buildPeriodKey(cfg, improvePeriodType, improveDate)
 		if err != nil {
 			return fmt.Errorf("failed to build period key: %w", err)
 		}
@@ -107,30 +106,47 @@ func runImprove(cmd *cobra.Command, args []string) error {
 		cfg.OpenAI.AnalysisModel,
 		cfg.OpenAI.AnalysisPromptContent,
 	)
+	if err := task.ConfigureClient(cfg, openAI); err != nil {
+		return err
+	}
+	task.RecordUploads(cfg, openAI, st)
+	task.RecordAPICalls(cfg, openAI, st)
 
 	// Get screenshot records for context
-	var screenshotRecords map[string]*storage.ScreenshotRecord
-	screenshotIDs := strings.Split(summary.Screenshots, ",")
-	if len(screenshotIDs) > 0 && summary.Screenshots != "" {
-		validIDs := make([]string, 0, len(screenshotIDs))
-		for _, id := range screenshotIDs {
-			id = strings.TrimSpace(id)
-			if id != "" {
-				validIDs = append(validIDs, id)
-			}
-		}
-		if len(validIDs) > 0 {
-			screenshotRecords, err = st.GetScreenshotsByIDs(validIDs)
-			if err != nil {
-				return fmt.Errorf("failed to get screenshot records: %w", err)
-			}
-			fmt.Fprintf(os.Stdout, "Found %d/%d screenshot records for context\n", len(screenshotRecords), len(validIDs))
-		}
+	screenshotRecords, err := prefetchScreenshots(st, []*storage.PeriodSummary{summary}, "context")
+	if err != nil {
+		return err
+	}
+
+	eval, err := newImprovementEvaluator(cfg, openAI)
+	if err != nil {
+		return err
+	}
+
+	executor, err := task.NewExecutor(cfg, st)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	// Improve report
+	fmt.Fprintf(os.Stdout, "Improving period report (key: %s) based on evaluation: %s\n", periodKey, evaluationPath)
+	improved, err := improvePeriodReport(cfg, st, executor, eval, summary, evaluationPath, screenshotRecords)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Improved report saved successfully.\n")
+	if improved.ImprovementNotes != "" {
+		fmt.Fprintf(os.Stdout, "\nImprovement notes:\n%s\n", improved.ImprovementNotes)
 	}
 
-	// Create evaluator with improvement prompt
+	return nil
+}
+
+// newImprovementEvaluator creates an evaluator with the improvement prompt
+func newImprovementEvaluator(cfg *config.Config, openAI *analyzer.OpenAI) (*evaluator.Evaluator, error) {
 	if cfg.Evaluator.ImprovementPromptContent == "" {
-		return fmt.Errorf("improvement prompt not configured (check evaluator.improvement_path in config)")
+		return nil, fmt.Errorf("improvement prompt not configured (check evaluator.improvement_path in config)")
 	}
 
 	eval, err := evaluator.NewEvaluatorWithImprovement(
@@ -144,39 +160,44 @@ func runImprove(cmd *cobra.Command, args []string) error {
 		cfg.Evaluator.ImprovementScreenshotSourceContent,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create evaluator: %w", err)
+		return nil, fmt.Errorf("failed to create evaluator: %w", err)
 	}
+	markers := evaluator.Markers{
+		Summary:  cfg.Evaluator.Markers.Summary,
+		Analysis: cfg.Evaluator.Markers.Analysis,
+		Notes:    cfg.Evaluator.Markers.Notes,
+	}
+	if err := eval.SetLanguage(cfg.Evaluator.Language, markers); err != nil {
+		return nil, fmt.Errorf("failed to configure evaluator: %w", err)
+	}
+	return eval, nil
+}
 
-	// Improve report
-	fmt.Fprintf(os.Stdout, "Improving period report (key: %s) based on evaluation: %s\n", periodKey, evaluationPath)
+// improvePeriodReport improves a period report based on its evaluation, saves the summary and regenerates the report file
+func improvePeriodReport(cfg *config.Config, st *storage.Storage, executor *task.Executor, eval *evaluator.Evaluator, summary *storage.PeriodSummary, evaluationPath string, screenshotRecords map[string]*storage.ScreenshotRecord) (*evaluator.ImprovedReport, error) {
+	if err := executor.CheckManualEdits(summary); err != nil {
+		return nil, err
+	}
 	improved, err := eval.ImproveReport(summary, evaluationPath, screenshotRecords)
 	if err != nil {
-		return fmt.Errorf("failed to improve report: %w", err)
+		return nil, fmt.Errorf("failed to improve report: %w", err)
 	}
 
 	// Update period summary in database
 	summary.Summary = improved.Summary
 	summary.Analysis = improved.Analysis
-
-	if err := st.SavePeriodSummary(summary); err != nil {
-		return fmt.Errorf("failed to save improved summary: %w", err)
+	if !cfg.Analysis.ShouldAnalyze(summary.PeriodType) {
+		// Behavior analysis is disabled for this level, keep the report factual only
+		summary.Analysis = ""
 	}
 
-	// Regenerate report file
-	executor, err := task.NewExecutor(cfg, st)
-	if err != nil {
-		return fmt.Errorf("failed to create executor: %w", err)
+	if err := st.SavePeriodSummary(summary); err != nil {
+		return nil, fmt.Errorf("failed to save improved summary: %w", err)
 	}
 
 	// Save period summary report
 	if err := executor.SavePeriodSummaryReport(summary); err != nil {
-		return fmt.Errorf("failed to regenerate report file: %w", err)
+		return nil, fmt.Errorf("failed to regenerate report file: %w", err)
 	}
-
-	fmt.Fprintf(os.Stdout, "Improved report saved successfully.\n")
-	if improved.ImprovementNotes != "" {
-		fmt.Fprintf(os.Stdout, "\nImprovement notes:\n%s\n", improved.ImprovementNotes)
-	}
-
-	return nil
+	return improved, nil
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	issuesConfigPath string
+	issuesFrom       string
+	issuesTo         string
+	issuesFormat     string
+	issuesOutput     string
+	issuesCorrelate  bool
+)
+
+func NewIssuesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issues",
+		Short: "Show time spent per Jira/Linear issue in a date range",
+		Long: `Show time spent per issue key (e.g. PROJ-123) found in screenshot analyses,
+with issue titles and status fetched from Jira or Linear.
+
+Examples:
+  stuff-time issues --from 2025-12-01 --to 2025-12-07
+  stuff-time issues --from 2025-12-01 --to 2025-12-31 --format csv -o issues.csv
+  stuff-time issues --from 2025-12-09 --correlate`,
+		RunE: runIssues,
+	}
+
+	cmd.Flags().StringVarP(&issuesConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&issuesFrom, "from", "", "Start date (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&issuesTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to --from")
+	cmd.Flags().StringVarP(&issuesFormat, "format", "f", "table", "Output format: table, csv")
+	cmd.Flags().StringVarP(&issuesOutput, "output", "o", "", "Write output to file instead of stdout")
+	cmd.Flags().BoolVar(&issuesCorrelate, "correlate", false, "Re-run issue correlation for each day before listing")
+
+	return cmd
+}
+
+func runIssues(cmd *cobra.Command, args []string) error {
+	if issuesFormat != "table" && issuesFormat != "csv" {
+		return fmt.Errorf("invalid format: %s (supported: table, csv)", issuesFormat)
+	}
+
+	cfg, err := config.Load(issuesConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	start, end, err := parseDayRange(cfg, issuesFrom, issuesTo)
+	if err != nil {
+		return err
+	}
+
+	if issuesCorrelate {
+		if storage.IsReadOnly() {
+			return fmt.Errorf("--correlate is not available in read-only mode")
+		}
+		executor, err := task.NewExecutor(cfg, st)
+		if err != nil {
+			return fmt.Errorf("failed to create executor: %w", err)
+		}
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			if _, err := executor.CorrelateIssues(day); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %s: %v\n", datefmt.Date(day), err)
+			}
+		}
+	}
+
+	issueTimes, err := task.AggregateIssueTime(st, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate issue time: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if issuesOutput != "" {
+		file, err := os.Create(issuesOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if issuesFormat == "csv" {
+		w := csv.NewWriter(out)
+		w.Write([]string{"key", "title", "status", "url", "minutes", "screenshots", "days"})
+		for _, it := range issueTimes {
+			w.Write([]string{it.Key, it.Title, it.Status, it.URL,
+				strconv.FormatFloat(it.Minutes, 'f', 1, 64), strconv.Itoa(it.Screenshots), strconv.Itoa(it.Days)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if len(issueTimes) == 0 {
+		fmt.Fprintf(out, "No issues found for %s - %s\n", datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+		return nil
+	}
+	fmt.Fprintf(out, "%-14s %-10s %-14s %s\n", "ISSUE", "TIME", "STATUS", "TITLE")
+	for _, it := range issueTimes {
+		fmt.Fprintf(out, "%-14s %-10s %-14s %s\n", it.Key, fmt.Sprintf("%.0fm", it.Minutes), it.Status, it.Title)
+	}
+	return nil
+}
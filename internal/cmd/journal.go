@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	journalConfigPath string
+	journalDate       string
+)
+
+func NewJournalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Reflect on the day with a few questions",
+		Long: `Show the day summary and ask a few reflective questions (journal.questions in the config).
+Answers are saved with the day, rendered in the "每日反思" section of the day report and
+fed into the week summary prompt, so the week summary blends what was tracked with how it felt.
+Press Enter to keep an existing answer (or skip a question).
+
+Examples:
+  stuff-time journal
+  stuff-time journal --date 2025-12-09`,
+		RunE: runJournal,
+	}
+
+	cmd.Flags().StringVarP(&journalConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&journalDate, "date", "d", "", "Date to reflect on (YYYY-MM-DD), defaults to today")
+
+	return cmd
+}
+
+func runJournal(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(journalConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(cfg.Journal.Questions) == 0 {
+		return fmt.Errorf("no journal questions configured (journal.questions)")
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	now := time.Now()
+	day := cfg.Storage.LogicalDate(now)
+	if journalDate != "" {
+		day, err = time.ParseInLocation("2006-01-02", journalDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date format: %w", err)
+		}
+	}
+	dayKey := day.Format("2006-01-02")
+
+	executor, err := task.NewExecutor(cfg, st)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	summary, err := st.GetPeriodSummary(storage.FormatPeriodKey("day", day))
+	if err != nil {
+		return fmt.Errorf("failed to get day summary: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Daily Summary for %s\n\n", dayKey)
+	if summary != nil && strings.TrimSpace(summary.Summary) != "" {
+		fmt.Fprintf(os.Stdout, "%s\n\n", strings.TrimSpace(summary.Summary))
+	} else {
+		fmt.Fprintf(os.Stdout, "No day summary yet (run 'stuff-time generate --period day' first to see it here)\n\n")
+	}
+
+	existing, err := executor.GetJournal(day)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]string, len(existing))
+	for _, a := range existing {
+		previous[a.Question] = a.Answer
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var answers []*task.JournalAnswer
+	for i, question := range cfg.Journal.Questions {
+		fmt.Fprintf(os.Stdout, "%d. %s\n", i+1, question)
+		if prev := previous[question]; prev != "" {
+			fmt.Fprintf(os.Stdout, "   [%s]\n", prev)
+		}
+		fmt.Fprintf(os.Stdout, "> ")
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			// Input closed: keep what was answered so far
+			fmt.Fprintln(os.Stdout)
+			break
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = previous[question]
+		}
+		answers = append(answers, &task.JournalAnswer{Question: question, Answer: answer})
+	}
+	// Keep earlier answers to questions that were not reached
+	for _, question := range cfg.Journal.Questions[len(answers):] {
+		answers = append(answers, &task.JournalAnswer{Question: question, Answer: previous[question]})
+	}
+
+	if err := executor.SaveJournal(day, answers); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "\nJournal saved for %s. It will be included in the week summary.\n", dayKey)
+	return nil
+}
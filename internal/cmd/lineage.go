@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	lineageConfigPath string
+	lineageDepth      int
+	lineageFormat     string
+)
+
+func NewLineageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lineage <period-key>",
+		Short: "Show which summaries and screenshots a period summary was generated from",
+		Long: `Show the lineage tree of a period summary: the child summaries and screenshots that were
+actually passed to the model (after placeholders and desktop/lock screens were filtered out),
+so every claim in a report can be traced back to its evidence.
+
+Examples:
+  stuff-time lineage day:2025-11-21               # Day summary down to the screenshots
+  stuff-time lineage week:2025-11-17 --depth 2     # Week, days and their direct children only
+  stuff-time lineage hour:2025-11-21T06:00Z --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runLineage,
+	}
+
+	cmd.Flags().StringVarP(&lineageConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().IntVar(&lineageDepth, "depth", 0, "Levels below the period to show (0 shows everything down to the screenshots)")
+	cmd.Flags().StringVarP(&lineageFormat, "format", "f", "tree", "Output format: tree, json")
+
+	return cmd
+}
+
+func runLineage(cmd *cobra.Command, args []string) error {
+	if lineageFormat != "tree" && lineageFormat != "json" {
+		return fmt.Errorf("invalid format: %s (supported: tree, json)", lineageFormat)
+	}
+	if lineageDepth < 0 {
+		return fmt.Errorf("invalid depth: %d", lineageDepth)
+	}
+
+	cfg, err := config.Load(lineageConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	root, err := task.BuildLineage(st, args[0], lineageDepth)
+	if err != nil {
+		return fmt.Errorf("failed to build lineage: %w", err)
+	}
+
+	if lineageFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(root)
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", lineageLabel(root))
+	printLineage(os.Stdout, root, "")
+	return nil
+}
+
+// printLineage prints the evidence of node as an indented tree
+func printLineage(w io.Writer, node *task.LineageNode, indent string) {
+	var lines []string
+	var children []*task.LineageNode
+	for _, child := range node.Children {
+		lines = append(lines, lineageLabel(child))
+		children = append(children, child)
+	}
+	for _, shot := range node.Screenshots {
+		lines = append(lines, screenshotLabel(shot))
+		children = append(children, nil)
+	}
+	if node.Collapsed > 0 {
+		lines = append(lines, fmt.Sprintf("... %d more (increase --depth)", node.Collapsed))
+		children = append(children, nil)
+	}
+
+	for i, line := range lines {
+		branch, next := "├── ", "│   "
+		if i == len(lines)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, line)
+		if children[i] != nil {
+			printLineage(w, children[i], indent+next)
+		}
+	}
+}
+
+func lineageLabel(node *task.LineageNode) string {
+	if node.PeriodType == "" {
+		return fmt.Sprintf("%s (summary deleted)", node.PeriodKey)
+	}
+	label := fmt.Sprintf("%s [%s] %s - %s", node.PeriodKey, node.PeriodType,
+		datefmt.DateTimeMinute(node.StartTime), datefmt.DateTimeMinute(node.EndTime))
+	if !node.Recorded {
+		label += " (no lineage recorded)"
+	}
+	if len(node.Prompts) > 0 {
+		label += " prompts: " + strings.Join(node.Prompts, ", ")
+	}
+	return label
+}
+
+func screenshotLabel(shot *task.LineageScreenshot) string {
+	if shot.Deleted {
+		return fmt.Sprintf("screenshot %s (deleted)", shot.ID)
+	}
+	analysis := strings.Join(strings.Fields(shot.Analysis), " ")
+	if runes := []rune(analysis); len(runes) > 60 {
+		analysis = string(runes[:60]) + "..."
+	}
+	return fmt.Sprintf("%s screenshot %s: %s", datefmt.Time(shot.Timestamp), shot.ID, analysis)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+var (
+	lockConfigPath string
+	lockReason     string
+	lockRemove     bool
+	lockList       bool
+)
+
+func NewLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock [period-key]...",
+		Short: "Lock periods so their hand-edited reports are never regenerated",
+		Long: `Lock a period after editing its report by hand. The summary of a locked period is never regenerated,
+also not when a parent period is force rebuilt, and invalid-report cleanup keeps its report.
+When a parent period is summarized, the content of locked child periods is marked as authoritative
+in the prompt, so the parent summary keeps the corrected facts.
+
+Edit the report file before (or after) locking: the report content is used as the period's summary.
+
+Examples:
+  stuff-time lock day:2025-11-21 --reason "修正了项目名称"
+  stuff-time lock hour:2025-11-21T06:00Z work-segment:2025-11-21#1
+  stuff-time lock day:2025-11-21 --remove
+  stuff-time lock --list`,
+		RunE: runLock,
+	}
+
+	cmd.Flags().StringVarP(&lockConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&lockReason, "reason", "", "Why the period is locked, shown by --list")
+	cmd.Flags().BoolVar(&lockRemove, "remove", false, "Unlock the periods instead of locking them")
+	cmd.Flags().BoolVarP(&lockList, "list", "l", false, "List locked periods")
+
+	return cmd
+}
+
+func runLock(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(lockConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	if lockList {
+		return listLocks(st)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("specify the period keys to lock, or --list")
+	}
+
+	for _, periodKey := range args {
+		if lockRemove {
+			if err := st.UnlockPeriod(periodKey); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Unlocked %s\n", periodKey)
+			continue
+		}
+
+		// Only existing periods can be locked, so a mistyped key does not silently lock nothing
+		summary, err := st.GetPeriodSummary(periodKey)
+		if err != nil {
+			return fmt.Errorf("failed to get period summary: %w", err)
+		}
+		if summary == nil {
+			return fmt.Errorf("period summary not found for key: %s", periodKey)
+		}
+		if err := st.LockPeriod(periodKey, lockReason); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Locked %s (%s)\n", periodKey, summary.PeriodType)
+	}
+	return nil
+}
+
+func listLocks(st *storage.Storage) error {
+	locks, err := st.ListPeriodLocks()
+	if err != nil {
+		return err
+	}
+	if len(locks) == 0 {
+		fmt.Fprintf(os.Stdout, "No locked periods\n")
+		return nil
+	}
+
+	for _, lock := range locks {
+		line := fmt.Sprintf("%-24s locked %s", lock.PeriodKey, datefmt.DateTimeMinute(lock.LockedAt))
+		if lock.Reason != "" {
+			line += "  " + strings.TrimSpace(lock.Reason)
+		}
+		fmt.Fprintln(os.Stdout, line)
+	}
+	return nil
+}
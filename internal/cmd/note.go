@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	noteConfigPath string
+	notePeriodKey  string
+)
+
+func NewNoteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "note [text]",
+		Short: "Add a manual note to a period",
+		Long: `Append a manual note to a period. Notes are stored next to the period's report
+(notes.md in the directory of a year/quarter/month/day/hour, <report>-notes.md otherwise),
+fed into the period's summary prompt and rendered in the "手动备注" section of its report.
+Without text, prints the current notes of the period.
+
+Examples:
+  stuff-time note --period day:2025-12-09 "下午的线下评审会没有截图，讨论了发布计划"
+  stuff-time note --period hour:2025-12-09T06:00Z "在白板上画了新架构草图"
+  stuff-time note --period week:2025-12-08`,
+		RunE: runNote,
+	}
+
+	cmd.Flags().StringVarP(&noteConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&notePeriodKey, "period", "p", "", "Period key (e.g. day:2025-12-09, hour:2025-12-09T06:00Z, week:2025-12-08, month:2025-12, quarter:2025-Q4, year:2025)")
+	cmd.MarkFlagRequired("period")
+
+	return cmd
+}
+
+// parseNotePeriodKey derives the period type and time range from a period key
+// Days and longer periods begin at storage.day_start_hour
+func parseNotePeriodKey(periodKey string, dayStartHour int) (*storage.PeriodSummary, error) {
+	key, err := storage.ParsePeriodKey(periodKey)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported period key: %s", periodKey)
+	}
+	start, end, err := key.Range(dayStartHour)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported period key: %s (work-segment notes require an existing summary)", periodKey)
+	}
+	return &storage.PeriodSummary{PeriodKey: key.String(), PeriodType: key.Type, StartTime: start, EndTime: end}, nil
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(noteConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	// Prefer the stored summary (covers work-segment keys and lets us re-render the report)
+	summary, err := st.GetPeriodSummary(notePeriodKey)
+	if err != nil {
+		return fmt.Errorf("failed to get period summary: %w", err)
+	}
+	existing := summary != nil
+	if !existing {
+		if summary, err = parseNotePeriodKey(notePeriodKey, cfg.Storage.DayStartHour); err != nil {
+			return err
+		}
+	}
+
+	// Notes only render stored summaries, so they work without an API key
+	executor := task.NewReportExecutor(cfg, st)
+	notesPath, err := executor.PeriodNotesPath(summary)
+	if err != nil {
+		return fmt.Errorf("failed to resolve notes path: %w", err)
+	}
+
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if text == "" {
+		content, err := os.ReadFile(notesPath)
+		if os.IsNotExist(err) {
+			fmt.Fprintf(os.Stdout, "No notes for %s (%s)\n", notePeriodKey, notesPath)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read notes: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n\n%s\n", notesPath, strings.TrimSpace(string(content)))
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(notesPath), 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	f, err := os.OpenFile(notesPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open notes file: %w", err)
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		text = "\n" + text
+	}
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Note added to %s: %s\n", notePeriodKey, notesPath)
+
+	// Re-render the existing report so the note shows up right away;
+	// the summary itself picks the note up the next time it is generated
+	if existing {
+		if err := executor.SavePeriodSummaryReport(summary); err != nil {
+			return fmt.Errorf("failed to update report: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "Report updated. Regenerate the period to include the note in its summary.\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	ocrConfigPath string
+	ocrFrom       string
+	ocrTo         string
+	ocrLimit      int
+)
+
+func NewOCRCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ocr",
+		Short: "Recognize and index the text of screenshots for search",
+		Long: `Recognize the text of screenshots that have not been indexed yet, e.g. those captured before
+ocr.enabled was set, so "stuff-time search" also finds them. With ocr.enabled, new screenshots are
+indexed when they are analyzed. Text recognition uses the macOS Vision framework, no model is called.
+
+Examples:
+  stuff-time ocr
+  stuff-time ocr --from 2025-12-01 --to 2025-12-07 --limit 2000`,
+		RunE: runOCR,
+	}
+
+	cmd.Flags().StringVarP(&ocrConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&ocrFrom, "from", "", "Start date (YYYY-MM-DD), defaults to all time")
+	cmd.Flags().StringVar(&ocrTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to today")
+	cmd.Flags().IntVarP(&ocrLimit, "limit", "n", 500, "Maximum number of screenshots to index")
+
+	return cmd
+}
+
+func runOCR(cmd *cobra.Command, args []string) error {
+	if ocrLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	cfg, err := config.Load(ocrConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	start := time.Time{}
+	end := time.Now().AddDate(0, 0, 1)
+	if ocrFrom != "" {
+		if start, err = time.ParseInLocation("2006-01-02", ocrFrom, time.Local); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	if ocrTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", ocrTo, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+		end = to.AddDate(0, 0, 1)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	indexed, failed, err := task.IndexScreenshotText(cfg, st, start, end, ocrLimit)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Indexed the text of %d screenshot(s)", indexed)
+	if failed > 0 {
+		fmt.Fprintf(os.Stdout, ", %d failed (see log)", failed)
+	}
+	fmt.Fprintln(os.Stdout)
+	return nil
+}
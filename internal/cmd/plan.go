@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	planAddConfigPath    string
+	planAddDate          string
+	planListConfigPath   string
+	planListDate         string
+	planRemoveConfigPath string
+)
+
+func NewPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Plan focus blocks and compare them with the tracked time",
+		Long: `Plan time-boxed focus blocks ahead (tomorrow by default). The day report of the planned day gets
+a "计划与实际" section comparing each block with the focused time captured in it (coding, docs and
+other work; meetings, communication and entertainment inside a block count against it), with the
+start delay and a 0-100 adherence score over the blocks that have ended.
+
+Examples:
+  stuff-time plan add 09:30-11:30 "spec writing"
+  stuff-time plan add 14:00-15:30 "code review" --date 2025-12-10
+  stuff-time plan list
+  stuff-time plan list --date 2025-12-09
+  stuff-time plan remove 3`,
+	}
+
+	cmd.AddCommand(NewPlanAddCmd())
+	cmd.AddCommand(NewPlanListCmd())
+	cmd.AddCommand(NewPlanRemoveCmd())
+
+	return cmd
+}
+
+func NewPlanAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <HH:MM-HH:MM> <title>",
+		Short: "Plan a focus block",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  runPlanAdd,
+	}
+	cmd.Flags().StringVarP(&planAddConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&planAddDate, "date", "", "Day of the block (YYYY-MM-DD), defaults to tomorrow")
+	return cmd
+}
+
+func NewPlanListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the planned blocks of a day with their adherence",
+		Args:  cobra.NoArgs,
+		RunE:  runPlanList,
+	}
+	cmd.Flags().StringVarP(&planListConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&planListDate, "date", "", "Day to list (YYYY-MM-DD), defaults to today")
+	return cmd
+}
+
+func NewPlanRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a planned block",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPlanRemove,
+	}
+	cmd.Flags().StringVarP(&planRemoveConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func openPlanStorage(configPath string) (*config.Config, *storage.Storage, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return cfg, st, nil
+}
+
+// parsePlanDate parses a --date value, defaulting to today plus offset days
+func parsePlanDate(value string, offset int) (time.Time, error) {
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day()+offset, 0, 0, 0, 0, time.Local), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --date: %w", err)
+	}
+	return day, nil
+}
+
+func runPlanAdd(cmd *cobra.Command, args []string) error {
+	day, err := parsePlanDate(planAddDate, 1)
+	if err != nil {
+		return err
+	}
+	start, end, err := task.ParsePlanRange(day, args[0])
+	if err != nil {
+		return err
+	}
+	title := strings.TrimSpace(strings.Join(args[1:], " "))
+	if title == "" {
+		return fmt.Errorf("title must not be empty")
+	}
+
+	_, st, err := openPlanStorage(planAddConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	block := &storage.PlanBlock{StartTime: start, EndTime: end, Title: title}
+	if err := st.AddPlanBlock(block); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Planned #%d %s %s-%s %s\n", block.ID, datefmt.Date(start), datefmt.TimeMinute(start), datefmt.TimeMinute(end), title)
+	return nil
+}
+
+func runPlanList(cmd *cobra.Command, args []string) error {
+	day, err := parsePlanDate(planListDate, 0)
+	if err != nil {
+		return err
+	}
+	cfg, st, err := openPlanStorage(planListConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	results, err := task.ComparePlan(cfg, st, day, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stdout, "No focus blocks planned for %s\n", datefmt.Date(day))
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "Focus blocks of %s:\n", datefmt.Date(day))
+	for _, r := range results {
+		status := "upcoming"
+		if !r.Upcoming || r.FocusedMinutes > 0 {
+			status = fmt.Sprintf("%dm focused of %dm (%.0f%%)", r.FocusedMinutes, r.PlannedMinutes(), r.Adherence*100)
+			if r.StartDelay >= time.Minute {
+				status += fmt.Sprintf(", started %dm late", int(r.StartDelay.Minutes()))
+			}
+		}
+		fmt.Fprintf(os.Stdout, "  #%-4d %s-%s  %-30s %s\n", r.Block.ID, datefmt.TimeMinute(r.Block.StartTime), datefmt.TimeMinute(r.Block.EndTime), r.Block.Title, status)
+	}
+	if score, ok := task.PlanScore(results); ok {
+		fmt.Fprintf(os.Stdout, "Adherence: %d/100\n", score)
+	}
+	return nil
+}
+
+func runPlanRemove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid block id %q", args[0])
+	}
+	_, st, err := openPlanStorage(planRemoveConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	removed, err := st.DeletePlanBlock(id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("no planned block #%d", id)
+	}
+	fmt.Fprintf(os.Stdout, "Removed #%d\n", id)
+	return nil
+}
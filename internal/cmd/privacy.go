@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+var (
+	privacyConfigPath string
+	privacyFrom       string
+	privacyTo         string
+	privacyFormat     string
+	privacyShowText   bool
+)
+
+func NewPrivacyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "privacy",
+		Short: "Report which images and text were sent to the LLM provider",
+		Long: `List every request sent to the LLM provider in a date range: when it was sent,
+to which endpoint and model, for what purpose, which screenshots were uploaded and how much text.
+Requests are recorded as they are sent (retries included); mock/replay backends send nothing.
+
+Examples:
+  stuff-time privacy                                  # Today
+  stuff-time privacy --from 2025-12-01 --to 2025-12-07
+  stuff-time privacy --from 2025-12-09 --text         # Include the full text of each request
+  stuff-time privacy --from 2025-12-09 --format json`,
+		RunE: runPrivacy,
+	}
+
+	cmd.Flags().StringVarP(&privacyConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&privacyFrom, "from", "", "Start date (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&privacyTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to --from")
+	cmd.Flags().StringVarP(&privacyFormat, "format", "f", "table", "Output format: table, json")
+	cmd.Flags().BoolVar(&privacyShowText, "text", false, "Print the full text sent with each request")
+
+	return cmd
+}
+
+func runPrivacy(cmd *cobra.Command, args []string) error {
+	if privacyFormat != "table" && privacyFormat != "json" {
+		return fmt.Errorf("invalid format: %s (supported: table, json)", privacyFormat)
+	}
+
+	cfg, err := config.Load(privacyConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	start, end, err := parseDayRange(cfg, privacyFrom, privacyTo)
+	if err != nil {
+		return err
+	}
+
+	uploads, err := st.QueryLLMUploads(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query LLM uploads: %w", err)
+	}
+
+	if privacyFormat == "json" {
+		type jsonUpload struct {
+			Time     time.Time `json:"time"`
+			Endpoint string    `json:"endpoint"`
+			Model    string    `json:"model"`
+			Purpose  string    `json:"purpose"`
+			Images   []string  `json:"images"`
+			Text     string    `json:"text"`
+		}
+		out := make([]jsonUpload, 0, len(uploads))
+		for _, u := range uploads {
+			images := u.ImagePaths
+			if images == nil {
+				images = []string{}
+			}
+			out = append(out, jsonUpload{u.Timestamp, u.Endpoint, u.Model, u.Purpose, images, u.Text})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	}
+
+	fmt.Fprintf(os.Stdout, "LLM uploads from %s to %s\n\n", datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+	if len(uploads) == 0 {
+		fmt.Fprintf(os.Stdout, "Nothing was sent to an LLM provider in this period.\n")
+		return nil
+	}
+
+	// Totals per provider host and model
+	type totals struct{ requests, images, textChars int }
+	byDestination := make(map[string]*totals)
+	for _, u := range uploads {
+		key := fmt.Sprintf("%s %s", endpointHost(u.Endpoint), u.Model)
+		t, ok := byDestination[key]
+		if !ok {
+			t = &totals{}
+			byDestination[key] = t
+		}
+		t.requests++
+		t.images += len(u.ImagePaths)
+		t.textChars += len([]rune(u.Text))
+	}
+	keys := make([]string, 0, len(byDestination))
+	for key := range byDestination {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(os.Stdout, "%-50s %8s %8s %12s\n", "Provider / model", "Requests", "Images", "Text chars")
+	for _, key := range keys {
+		t := byDestination[key]
+		fmt.Fprintf(os.Stdout, "%-50s %8d %8d %12d\n", key, t.requests, t.images, t.textChars)
+	}
+
+	fmt.Fprintf(os.Stdout, "\n%-19s  %-25s  %-22s  %s\n", "Time", "Model", "Purpose", "Uploaded")
+	for _, u := range uploads {
+		fmt.Fprintf(os.Stdout, "%-19s  %-25s  %-22s  %d chars of text\n",
+			datefmt.DateTime(u.Timestamp.Local()), u.Model, u.Purpose, len([]rune(u.Text)))
+		for _, path := range u.ImagePaths {
+			fmt.Fprintf(os.Stdout, "%-19s  %-25s  %-22s  image: %s\n", "", "", "", path)
+		}
+		if privacyShowText && u.Text != "" {
+			for _, line := range strings.Split(u.Text, "\n") {
+				fmt.Fprintf(os.Stdout, "    | %s\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+// endpointHost returns the host of a provider endpoint, or the endpoint itself if it isn't a URL
+func endpointHost(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return endpoint
+}
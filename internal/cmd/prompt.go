@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+var (
+	promptListConfigPath     string
+	promptEditConfigPath     string
+	promptEditNote           string
+	promptLogConfigPath      string
+	promptActivateConfigPath string
+	promptImportConfigPath   string
+	promptImportNote         string
+	promptImportAll          bool
+	promptExportConfigPath   string
+	promptExportOutput       string
+)
+
+func NewPromptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Edit prompts stored in the database, with version history",
+		Long: `Prompts can be stored in the database instead of the prompt files: every edit is saved as a new
+version with its author and a note, one version of each prompt is active, and any earlier version
+can be activated again. Set prompts.store: db in the config to use the active versions; prompts
+without a stored version keep using their files. The lineage of a summary records the prompt
+versions it was generated with.
+
+Prompts are named "<scene>/<file name without .txt>", e.g. summary/day or screenshot/screenshot;
+prompt list shows the names. Files remain the import/export format.
+
+Examples:
+  stuff-time prompt list
+  stuff-time prompt edit summary/day --note "shorter highlights"
+  stuff-time prompt log summary/day
+  stuff-time prompt activate summary/day 2
+  stuff-time prompt import --all
+  stuff-time prompt export summary/day -o day.txt`,
+	}
+
+	cmd.AddCommand(NewPromptListCmd())
+	cmd.AddCommand(NewPromptEditCmd())
+	cmd.AddCommand(NewPromptLogCmd())
+	cmd.AddCommand(NewPromptActivateCmd())
+	cmd.AddCommand(NewPromptImportCmd())
+	cmd.AddCommand(NewPromptExportCmd())
+
+	return cmd
+}
+
+func NewPromptListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the prompts and their active database version",
+		Args:  cobra.NoArgs,
+		RunE:  runPromptList,
+	}
+	cmd.Flags().StringVarP(&promptListConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewPromptEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <name>",
+		Short: "Edit a prompt in $EDITOR and save it as a new active version",
+		Long: `Open the active version of a prompt (or its file content when it has no stored version) in
+$EDITOR (vi by default). If the content changed, it is saved as a new version and activated.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPromptEdit,
+	}
+	cmd.Flags().StringVarP(&promptEditConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&promptEditNote, "note", "", "Note describing the change")
+	return cmd
+}
+
+func NewPromptLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log <name>",
+		Short: "Show the version history of a prompt",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPromptLog,
+	}
+	cmd.Flags().StringVarP(&promptLogConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewPromptActivateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "activate <name> <version>",
+		Short: "Activate a stored version of a prompt, e.g. to revert an edit",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPromptActivate,
+	}
+	cmd.Flags().StringVarP(&promptActivateConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewPromptImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [<name> [file]]",
+		Short: "Store a prompt file as a new active version",
+		Long: `Store the content of a file as a new active version of a prompt. Without a file, the configured
+prompt file is imported; --all imports every configured prompt file that differs from its active version.`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: runPromptImport,
+	}
+	cmd.Flags().StringVarP(&promptImportConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&promptImportNote, "note", "", "Note describing the change")
+	cmd.Flags().BoolVar(&promptImportAll, "all", false, "Import all configured prompt files")
+	return cmd
+}
+
+func NewPromptExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <name>",
+		Short: "Write the active version of a prompt to stdout or a file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPromptExport,
+	}
+	cmd.Flags().StringVarP(&promptExportConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&promptExportOutput, "output", "o", "", "Output file (default: stdout)")
+	return cmd
+}
+
+// openPromptStorage loads the config and opens the storage of a prompt command
+func openPromptStorage(configPath string) (*config.Config, *storage.Storage, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return cfg, st, nil
+}
+
+// currentPrompt returns the content of a prompt: its active version, or the configured file content
+// Returns a nil version when the prompt has no stored version
+func currentPrompt(cfg *config.Config, st *storage.Storage, name string) (string, *storage.PromptVersion, error) {
+	prompt := cfg.PromptFile(name)
+	if prompt == nil {
+		return "", nil, fmt.Errorf("unknown prompt %q (see prompt list)", name)
+	}
+	active, err := st.GetActivePrompt(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if active != nil {
+		return active.Content, active, nil
+	}
+	return *prompt.Content, nil, nil
+}
+
+// promptAuthor returns the name of the user running the command
+func promptAuthor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// saveHint reminds that stored versions are not used until prompts.store is db
+func saveHint(cfg *config.Config) {
+	if !cfg.Prompts.UseDB() {
+		fmt.Fprintln(os.Stdout, "Note: prompts.store is not db, set it in the config to use stored prompts")
+	}
+}
+
+func runPromptList(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openPromptStorage(promptListConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	active, err := st.ListActivePrompts()
+	if err != nil {
+		return err
+	}
+	versions := make(map[string]*storage.PromptVersion, len(active))
+	for _, v := range active {
+		versions[v.Name] = v
+	}
+
+	fmt.Fprintf(os.Stdout, "Prompt store: %s\n\n", cfg.Prompts.Store)
+	for _, prompt := range cfg.PromptFiles() {
+		source := "file"
+		if *prompt.Content == "" {
+			source = "file (missing)"
+		}
+		if v := versions[prompt.Name]; v != nil {
+			source = fmt.Sprintf("db v%d (%s, %s)", v.Version, datefmt.DateTimeMinute(v.CreatedAt), v.Author)
+		}
+		fmt.Fprintf(os.Stdout, "%-36s %s\n", prompt.Name, source)
+	}
+	return nil
+}
+
+func runPromptEdit(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openPromptStorage(promptEditConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	name := args[0]
+	content, _, err := currentPrompt(cfg, st, name)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "stuff-time-prompt-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	file.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may carry arguments ("code --wait")
+	fields := strings.Fields(editor)
+	editCmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return fmt.Errorf("failed to read edited prompt: %w", err)
+	}
+	if string(edited) == content {
+		fmt.Fprintf(os.Stdout, "%s unchanged\n", name)
+		return nil
+	}
+	if strings.TrimSpace(string(edited)) == "" {
+		return fmt.Errorf("prompt is empty, not saved")
+	}
+
+	version := &storage.PromptVersion{Name: name, Content: string(edited), Author: promptAuthor(), Note: promptEditNote}
+	if err := st.AddPromptVersion(version); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Saved %s v%d (active)\n", name, version.Version)
+	saveHint(cfg)
+	return nil
+}
+
+func runPromptLog(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openPromptStorage(promptLogConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	name := args[0]
+	if cfg.PromptFile(name) == nil {
+		return fmt.Errorf("unknown prompt %q (see prompt list)", name)
+	}
+	versions, err := st.ListPromptVersions(name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(os.Stdout, "%s has no stored versions, its file is used\n", name)
+		return nil
+	}
+	for _, v := range versions {
+		marker := " "
+		if v.Active {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s v%-3d %s  %s  %d chars", marker, v.Version, datefmt.DateTimeMinute(v.CreatedAt), v.Author, len([]rune(v.Content)))
+		if v.Note != "" {
+			line += "  " + v.Note
+		}
+		fmt.Fprintln(os.Stdout, line)
+	}
+	return nil
+}
+
+func runPromptActivate(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openPromptStorage(promptActivateConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	name := args[0]
+	version, err := strconv.Atoi(strings.TrimPrefix(args[1], "v"))
+	if err != nil {
+		return fmt.Errorf("invalid version %q", args[1])
+	}
+	ok, err := st.ActivatePromptVersion(name, version)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s has no version %d", name, version)
+	}
+	fmt.Fprintf(os.Stdout, "Activated %s v%d\n", name, version)
+	saveHint(cfg)
+	return nil
+}
+
+func runPromptImport(cmd *cobra.Command, args []string) error {
+	if promptImportAll != (len(args) == 0) {
+		return fmt.Errorf("specify a prompt name or --all")
+	}
+	cfg, st, err := openPromptStorage(promptImportConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if promptImportAll {
+		imported := 0
+		for _, prompt := range cfg.PromptFiles() {
+			if *prompt.Content == "" {
+				continue
+			}
+			active, err := st.GetActivePrompt(prompt.Name)
+			if err != nil {
+				return err
+			}
+			if active != nil && active.Content == *prompt.Content {
+				continue
+			}
+			if err := importPrompt(st, prompt.Name, *prompt.Content); err != nil {
+				return err
+			}
+			imported++
+		}
+		fmt.Fprintf(os.Stdout, "Imported %d prompt(s)\n", imported)
+		saveHint(cfg)
+		return nil
+	}
+
+	name := args[0]
+	prompt := cfg.PromptFile(name)
+	if prompt == nil {
+		return fmt.Errorf("unknown prompt %q (see prompt list)", name)
+	}
+	content := *prompt.Content
+	if len(args) == 2 {
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		content = string(data)
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("prompt %s is empty", name)
+	}
+	if err := importPrompt(st, name, content); err != nil {
+		return err
+	}
+	saveHint(cfg)
+	return nil
+}
+
+func importPrompt(st *storage.Storage, name, content string) error {
+	note := promptImportNote
+	if note == "" {
+		note = "imported from file"
+	}
+	version := &storage.PromptVersion{Name: name, Content: content, Author: promptAuthor(), Note: note}
+	if err := st.AddPromptVersion(version); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Saved %s v%d (active)\n", name, version.Version)
+	return nil
+}
+
+func runPromptExport(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openPromptStorage(promptExportConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	content, _, err := currentPrompt(cfg, st, args[0])
+	if err != nil {
+		return err
+	}
+	if promptExportOutput == "" {
+		_, err := os.Stdout.WriteString(content)
+		return err
+	}
+	if err := os.WriteFile(promptExportOutput, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Wrote %s to %s\n", args[0], promptExportOutput)
+	return nil
+}
@@ -3,12 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
 	"stuff-time/internal/storage"
 )
 
@@ -20,14 +20,21 @@ func NewQueryCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "query",
 		Short: "Query completed historical reports",
-		Long:  "Query and view completed historical reports that have already been generated.",
-		RunE:  runQuery,
+		Long: `Query and view completed historical reports that have already been generated.
+
+The subcommands give structured access to the database tables, e.g.
+  stuff-time query screenshots --where "hour_key=2025-12-09-14" --columns id,timestamp,analysis --format json`,
+		RunE: runQuery,
 	}
 
 	cmd.Flags().StringVarP(&queryDate, "date", "d", "", "Query date (YYYY-MM-DD)")
 	cmd.Flags().StringVar(&queryHour, "hour", "", "Query hour (0-23)")
 	cmd.Flags().StringVarP(&queryConfigPath, "config", "c", "", "Path to config file")
 
+	for _, table := range storage.RowTables {
+		cmd.AddCommand(newRowQueryCmd(table))
+	}
+
 	return cmd
 }
 
@@ -59,12 +66,11 @@ func runQuery(cmd *cobra.Command, args []string) error {
 			start = time.Date(date.Year(), date.Month(), date.Day(), hour, 0, 0, 0, date.Location())
 			end = start.Add(time.Hour)
 		} else {
-			start = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+			start = cfg.Storage.DateStart(date)
 			end = start.AddDate(0, 0, 1)
 		}
 	} else {
-		now := time.Now()
-		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		start = cfg.Storage.DayStart(time.Now())
 		end = start.AddDate(0, 0, 1)
 	}
 
@@ -91,11 +97,17 @@ func runQuery(cmd *cobra.Command, args []string) error {
 
 			fmt.Fprintf(os.Stdout, "Screenshots (%d):\n", len(screenshots))
 			for i, s := range screenshots {
-				fmt.Fprintf(os.Stdout, "\n[%d] %s - %s\n", i+1, s.Timestamp.Format("15:04:05"), s.ImagePath)
-				if s.Analysis != "" && !strings.HasPrefix(s.Analysis, "Analysis failed") {
+				fmt.Fprintf(os.Stdout, "\n[%d] %s - %s\n", i+1, datefmt.Time(s.Timestamp), s.ImagePath)
+				if s.HasAnalysis() {
 					fmt.Fprintf(os.Stdout, "    Analysis: %s\n", s.Analysis)
-				} else if s.Analysis != "" {
-					fmt.Fprintf(os.Stdout, "    Analysis: (Failed)\n")
+				} else if s.AnalysisUnavailable() {
+					fmt.Fprintf(os.Stdout, "    Analysis: (Failed: %s)\n", s.AnalysisError)
+				} else if s.AnalysisStatus == storage.AnalysisSkipped && s.AnalysisError != "" {
+					fmt.Fprintf(os.Stdout, "    Analysis: (Skipped: %s)\n", s.AnalysisError)
+				} else if s.AnalysisStatus == storage.AnalysisSkipped {
+					fmt.Fprintf(os.Stdout, "    Analysis: (Skipped: desktop/lock screen)\n")
+				} else if s.AnalysisStatus == storage.AnalysisSampledOut {
+					fmt.Fprintf(os.Stdout, "    Analysis: (Sampled out during rebuild)\n")
 				} else {
 					fmt.Fprintf(os.Stdout, "    Analysis: (Not analyzed yet)\n")
 				}
@@ -110,11 +122,11 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		}
 
 		if len(summaries) == 0 {
-			fmt.Fprintf(os.Stdout, "No data found for %s\n", start.Format("2006-01-02"))
+			fmt.Fprintf(os.Stdout, "No data found for %s\n", datefmt.Date(start))
 			return nil
 		}
 
-		fmt.Fprintf(os.Stdout, "Hour Summaries for %s\n", start.Format("2006-01-02"))
+		fmt.Fprintf(os.Stdout, "Hour Summaries for %s\n", datefmt.Date(start))
 		fmt.Fprintf(os.Stdout, "================\n\n")
 
 		for _, s := range summaries {
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+// maxCellRunes caps the width of a cell in table output
+const maxCellRunes = 80
+
+// newRowQueryCmd creates the query subcommand of a documented table
+func newRowQueryCmd(table *storage.RowTable) *cobra.Command {
+	var where []string
+	var columns []string
+	var format string
+	var limit int
+	var configPath string
+
+	var long strings.Builder
+	long.WriteString(table.Description + ".\n\nColumns:\n")
+	for _, c := range table.Columns {
+		long.WriteString(fmt.Sprintf("  %-18s %s\n", c.Name, c.Description))
+	}
+	long.WriteString(fmt.Sprintf("\nConditions are <column><op><value> with op one of %s; \"~\" matches values containing the text.\n", strings.Join(storage.RowFilterOps, " ")))
+	long.WriteString("Several --where flags are combined with AND. Times are RFC 3339 in local time, so prefixes such as 2025-12-09 compare as expected.")
+
+	cmd := &cobra.Command{
+		Use:   table.Name,
+		Short: fmt.Sprintf("Query %s", strings.ToLower(table.Description)),
+		Long:  long.String(),
+		Example: fmt.Sprintf("  stuff-time query %s --where \"%s\" --columns %s --format table",
+			table.Name, exampleRowFilter(table), strings.Join(table.DefaultColumns, ",")),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("invalid format %q (expected table or json)", format)
+			}
+			q := &storage.RowQuery{Table: table.Name, Columns: columns, Limit: limit}
+			for _, expr := range where {
+				filter, err := storage.ParseRowFilter(expr)
+				if err != nil {
+					return err
+				}
+				q.Filters = append(q.Filters, filter)
+			}
+			if len(q.Columns) == 0 {
+				q.Columns = table.DefaultColumns
+			}
+
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer st.Close()
+
+			rows, err := st.QueryRows(q)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				return writeRowsJSON(q.Columns, rows)
+			}
+			return writeRowsTable(q.Columns, rows)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&where, "where", "w", nil, "Condition <column><op><value> (repeatable, combined with AND)")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, fmt.Sprintf("Comma-separated columns to show (default: %s)", strings.Join(table.DefaultColumns, ",")))
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format: table or json")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 100, fmt.Sprintf("Maximum number of rows (at most %d)", storage.MaxRowQueryLimit))
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file")
+
+	return cmd
+}
+
+// exampleRowFilter returns a sample condition for the help of a table
+func exampleRowFilter(table *storage.RowTable) string {
+	switch {
+	case table.HasColumn("hour_key"):
+		return "hour_key=2025-12-09-14"
+	case table.HasColumn("period_type"):
+		return "period_type=day"
+	default:
+		return "timestamp>=2025-12-09"
+	}
+}
+
+// formatRowValue renders a column value as text
+func formatRowValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeRowsTable prints rows as aligned columns, flattening and shortening long values
+func writeRowsTable(columns []string, rows [][]any) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cell := strings.Join(strings.Fields(formatRowValue(v)), " ")
+			if r := []rune(cell); len(r) > maxCellRunes {
+				cell = string(r[:maxCellRunes-3]) + "..."
+			}
+			cells[i] = cell
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "(%d rows)\n", len(rows))
+	return nil
+}
+
+// writeRowsJSON prints rows as a JSON array of objects keyed by column
+func writeRowsJSON(columns []string, rows [][]any) error {
+	objects := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		object := make(map[string]any, len(columns))
+		for i, c := range columns {
+			if t, ok := row[i].(time.Time); ok {
+				object[c] = t.Format(time.RFC3339)
+			} else {
+				object[c] = row[i]
+			}
+		}
+		objects = append(objects, object)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(objects)
+}
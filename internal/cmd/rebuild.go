@@ -9,11 +9,15 @@ import (
 	"stuff-time/internal/analyzer"
 	"stuff-time/internal/config"
 	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
 )
 
 var (
-	rebuildConfigPath string
-	rebuildYes        bool
+	rebuildConfigPath  string
+	rebuildYes         bool
+	rebuildSample      string
+	rebuildLockWorkers int
+	rebuildLockRate    int
 )
 
 func NewRebuildCmd() *cobra.Command {
@@ -25,15 +29,39 @@ This will clear all screenshot data in the database and rebuild it from the file
 All screenshots will need to be re-analyzed after rebuilding.
 
 Note: This command rebuilds screenshot metadata (screenshots table), not period summaries.
-To rebuild period summaries from report files, use 'validate --rebuild-db' instead.`,
+To rebuild period summaries from report files, use 'validate --rebuild-db' instead.
+
+Use --sample to bound the analysis cost of long ranges: only the sampled screenshots are analyzed,
+the others are marked as sampled out (not failed) and summaries note the sampling rate.
+
+Lock screen detection results are cached by image content, so rebuilding the same archive again
+only checks new screenshots. Blank and clearly detailed screenshots are decided locally; the rest
+are checked by the model with --lock-workers concurrent calls and at most --lock-rate calls per minute.
+
+Examples:
+  stuff-time rebuild --yes
+  stuff-time rebuild --yes --sample 3               # Analyze every 3rd screenshot
+  stuff-time rebuild --yes --sample 4/fifteenmin    # Analyze at most 4 screenshots per fifteen minutes
+  stuff-time rebuild --yes --lock-workers 8 --lock-rate 300`,
 		RunE: runRebuild,
 	}
 	cmd.Flags().StringVarP(&rebuildConfigPath, "config", "c", "", "Path to config file")
 	cmd.Flags().BoolVarP(&rebuildYes, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().StringVar(&rebuildSample, "sample", "", "Only analyze a sample of the screenshots: N (every Nth) or N/fifteenmin (at most N per fifteen minutes)")
+	cmd.Flags().IntVar(&rebuildLockWorkers, "lock-workers", 0, "Concurrent lock screen detection calls (default: screenshot.analysis_workers)")
+	cmd.Flags().IntVar(&rebuildLockRate, "lock-rate", 120, "Lock screen detection calls per minute (0 for no limit)")
 	return cmd
 }
 
 func runRebuild(cmd *cobra.Command, args []string) error {
+	var sample task.SampleSpec
+	if rebuildSample != "" {
+		var err error
+		if sample, err = task.ParseSampleSpec(rebuildSample); err != nil {
+			return err
+		}
+	}
+
 	cfg, err := config.Load(rebuildConfigPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -56,7 +84,8 @@ func runRebuild(cmd *cobra.Command, args []string) error {
 	
 	// Create analyzer for lock screen detection if API key is configured
 	var lockScreenDetector storage.LockScreenDetector
-	if cfg.OpenAI.APIKey != "" {
+	var lockDetection *task.LockScreenDetection
+	if cfg.OpenAI.APIKey != "" || cfg.OpenAI.Backend.IsOffline() {
 		openAI := analyzer.NewOpenAI(
 			cfg.OpenAI.APIKey,
 			cfg.OpenAI.BaseURL,
@@ -73,8 +102,19 @@ func runRebuild(cmd *cobra.Command, args []string) error {
 			cfg.OpenAI.AnalysisModel,
 			cfg.OpenAI.AnalysisPromptContent,
 		)
-		lockScreenDetector = openAI.IsLockScreen
-		fmt.Fprintf(os.Stdout, "Lock screen detection enabled (using LLM analysis)\n")
+		if err := task.ConfigureClient(cfg, openAI); err != nil {
+			return err
+		}
+		task.RecordUploads(cfg, openAI, st)
+		task.RecordAPICalls(cfg, openAI, st)
+		openAI.UploadFormat = cfg.OpenAI.UploadFormat
+		workers := rebuildLockWorkers
+		if workers <= 0 {
+			workers = cfg.Screenshot.AnalysisWorkers
+		}
+		lockDetection = task.NewLockScreenDetection(st, openAI.IsLockScreen, workers, rebuildLockRate)
+		lockScreenDetector = lockDetection.Detect
+		fmt.Fprintf(os.Stdout, "Lock screen detection enabled (using LLM analysis, %d worker(s))\n", workers)
 	} else {
 		fmt.Fprintf(os.Stdout, "WARNING: OpenAI API key not configured, lock screen detection disabled\n")
 	}
@@ -85,6 +125,27 @@ func runRebuild(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Fprintf(os.Stdout, "Successfully imported %d screenshot(s).\n", count)
+	if lockDetection != nil {
+		fmt.Fprintf(os.Stdout, "Lock screen detection: %d cached, %d decided locally, %d checked by the model, %d failed\n",
+			lockDetection.Cached, lockDetection.Heuristic, lockDetection.Model, lockDetection.Failed)
+	}
+
+	if rebuildSample != "" {
+		screenshots, err := st.GetAllScreenshots()
+		if err != nil {
+			return fmt.Errorf("failed to get screenshots: %w", err)
+		}
+		sampledOut := task.SampleScreenshots(screenshots, sample)
+		updates := make([]*storage.ScreenshotAnalysisUpdate, 0, len(sampledOut))
+		for _, s := range sampledOut {
+			updates = append(updates, &storage.ScreenshotAnalysisUpdate{ID: s.ID, Status: storage.AnalysisSampledOut})
+		}
+		if err := st.UpdateScreenshotAnalyses(updates); err != nil {
+			return fmt.Errorf("failed to mark sampled-out screenshots: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "Sampling (%s): %d screenshot(s) will be analyzed, %d sampled out.\n",
+			sample, len(screenshots)-len(sampledOut), len(sampledOut))
+	}
 	fmt.Fprintf(os.Stdout, "Database rebuild completed. Screenshots will be analyzed on the next analysis cycle.\n")
 
 	return nil
@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/evaluator"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	regressConfigPath       string
+	regressPeriodKeys       []string
+	regressFromScreenshots  bool
+	regressWorkers          int
+	regressKeepSandbox      bool
+	regressMaxDrop          float64
+	regressMarkConfigPath   string
+	regressUnmarkConfigPath string
+	regressListConfigPath   string
+)
+
+// regressPeriodTypes are the period types that can be regenerated on their own
+var regressPeriodTypes = map[string]bool{
+	"fifteenmin": true, "hour": true, "day": true, "week": true, "month": true, "quarter": true, "year": true,
+}
+
+func NewRegressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "regress",
+		Short: "Regenerate golden periods and compare their quality with the stored outputs",
+		Long: `Summary quality regression tests. Mark periods whose summaries are good references as golden
+(regress mark); after changing prompts or models, run regress to regenerate every golden period and compare.
+
+Golden periods are regenerated in a sandbox: a snapshot of the database and an empty reports directory
+in a temporary directory, so the real summaries and reports are never overwritten. Deliverables, issues
+and reading logs are not extracted in the sandbox, and notes next to the real reports are not read.
+The stored golden output and the regenerated one are then both rated by the evaluator (accuracy,
+relevance, depth), and the per-period and average quality deltas are reported.
+
+Examples:
+  stuff-time regress mark day:2025-12-09 week:2025-12-08
+  stuff-time regress list
+  stuff-time regress
+  stuff-time regress --period-key day:2025-12-09 --keep-sandbox
+  stuff-time regress --max-drop 0.5`,
+		Args: cobra.NoArgs,
+		RunE: runRegress,
+	}
+
+	cmd.Flags().StringVarP(&regressConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringSliceVar(&regressPeriodKeys, "period-key", nil, "Only regenerate these golden periods, repeatable")
+	cmd.Flags().BoolVar(&regressFromScreenshots, "from-screenshots", false, "Also rebuild lower-level summaries from screenshots in the sandbox")
+	cmd.Flags().IntVar(&regressWorkers, "workers", 3, "Number of reports evaluated in parallel")
+	cmd.Flags().BoolVar(&regressKeepSandbox, "keep-sandbox", false, "Keep the sandbox directory with the regenerated reports")
+	cmd.Flags().Float64Var(&regressMaxDrop, "max-drop", 0, "Fail if the average overall score drops by more than this (0: never fail)")
+
+	cmd.AddCommand(NewRegressMarkCmd())
+	cmd.AddCommand(NewRegressUnmarkCmd())
+	cmd.AddCommand(NewRegressListCmd())
+
+	return cmd
+}
+
+func NewRegressMarkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mark <period-key>...",
+		Short: "Mark periods as golden, storing their current summary as the reference output",
+		Long: `Mark periods as golden. The current summary and analysis of each period are stored as its reference
+output; marking a golden period again replaces the stored output.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runRegressMark,
+	}
+	cmd.Flags().StringVarP(&regressMarkConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewRegressUnmarkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unmark <period-key>...",
+		Short: "Remove periods from the golden set",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runRegressUnmark,
+	}
+	cmd.Flags().StringVarP(&regressUnmarkConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewRegressListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List golden periods",
+		Args:  cobra.NoArgs,
+		RunE:  runRegressList,
+	}
+	cmd.Flags().StringVarP(&regressListConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func openRegressStorage(configPath string) (*config.Config, *storage.Storage, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return cfg, st, nil
+}
+
+func runRegressMark(cmd *cobra.Command, args []string) error {
+	_, st, err := openRegressStorage(regressMarkConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	for _, periodKey := range args {
+		summary, err := st.GetPeriodSummary(periodKey)
+		if err != nil {
+			return fmt.Errorf("failed to get period summary: %w", err)
+		}
+		if summary == nil || summary.Summary == "" || summary.Summary == "__NO_WORK_ACTIVITY_PLACEHOLDER__" {
+			return fmt.Errorf("no summary found for %s", periodKey)
+		}
+		if !regressPeriodTypes[summary.PeriodType] {
+			return fmt.Errorf("%s periods cannot be regenerated on their own", summary.PeriodType)
+		}
+		golden := &storage.GoldenPeriod{
+			PeriodKey:   summary.PeriodKey,
+			PeriodType:  summary.PeriodType,
+			StartTime:   summary.StartTime,
+			EndTime:     summary.EndTime,
+			Screenshots: summary.Screenshots,
+			Summary:     summary.Summary,
+			Analysis:    summary.Analysis,
+		}
+		if err := st.SaveGoldenPeriod(golden); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Marked %s (%s) as golden\n", periodKey, summary.PeriodType)
+	}
+	return nil
+}
+
+func runRegressUnmark(cmd *cobra.Command, args []string) error {
+	_, st, err := openRegressStorage(regressUnmarkConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	for _, periodKey := range args {
+		if err := st.DeleteGoldenPeriod(periodKey); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Unmarked %s\n", periodKey)
+	}
+	return nil
+}
+
+func runRegressList(cmd *cobra.Command, args []string) error {
+	_, st, err := openRegressStorage(regressListConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	goldens, err := st.ListGoldenPeriods()
+	if err != nil {
+		return err
+	}
+	if len(goldens) == 0 {
+		fmt.Fprintln(os.Stdout, "No golden periods, mark one with: stuff-time regress mark <period-key>")
+		return nil
+	}
+	for _, g := range goldens {
+		fmt.Fprintf(os.Stdout, "%-20s %-10s marked %s  (%d chars)\n",
+			g.PeriodKey, g.PeriodType, datefmt.DateTimeMinute(g.MarkedAt), len([]rune(g.Summary)))
+	}
+	return nil
+}
+
+func runRegress(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openRegressStorage(regressConfigPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	goldens, err := selectGoldenPeriods(st, regressPeriodKeys)
+	if err != nil {
+		return err
+	}
+
+	// Sandbox: database snapshot and empty reports directory, the real ones are never written
+	sandboxDir, err := os.MkdirTemp("", "stuff-time-regress-")
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	if regressKeepSandbox {
+		fmt.Fprintf(os.Stdout, "Sandbox: %s\n", sandboxDir)
+	} else {
+		defer os.RemoveAll(sandboxDir)
+	}
+
+	sandboxCfg, sandbox, err := openSandbox(cfg, st, sandboxDir)
+	if err != nil {
+		return err
+	}
+	defer sandbox.Close()
+	// Skip the per-day extractions, they cost LLM calls and do not affect the compared summaries
+	sandboxCfg.Deliverables.Enabled = false
+	sandboxCfg.Issues.Enabled = false
+	sandboxCfg.Reading.Enabled = false
+	sandboxCfg.Personal.Enabled = false
+
+	executor, err := task.NewExecutor(sandboxCfg, sandbox)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	// Regenerate every golden period, pairing the stored output with the new one
+	var summaries []*storage.PeriodSummary
+	var regenerated []*storage.GoldenPeriod
+	for _, g := range goldens {
+		fmt.Fprintf(os.Stdout, "Regenerating %s (%s)...\n", g.PeriodKey, g.PeriodType)
+		if err := executor.RegeneratePeriod(g.PeriodType, g.StartTime, regressFromScreenshots); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to regenerate %s: %v\n", g.PeriodKey, err)
+			continue
+		}
+		current, err := sandbox.GetPeriodSummary(g.PeriodKey)
+		if err != nil || current == nil || current.Summary == "" {
+			fmt.Fprintf(os.Stderr, "Failed to regenerate %s: no summary was generated\n", g.PeriodKey)
+			continue
+		}
+		golden := &storage.PeriodSummary{
+			PeriodKey:   g.PeriodKey,
+			PeriodType:  g.PeriodType,
+			StartTime:   g.StartTime,
+			EndTime:     g.EndTime,
+			Screenshots: g.Screenshots,
+			Summary:     g.Summary,
+			Analysis:    g.Analysis,
+		}
+		summaries = append(summaries, golden, current)
+		regenerated = append(regenerated, g)
+	}
+	if len(regenerated) == 0 {
+		return fmt.Errorf("no golden period could be regenerated")
+	}
+
+	// Rate both outputs with the same evaluator, so prompt/model changes of the evaluation itself
+	// affect both sides alike
+	openAI := analyzer.NewOpenAI(
+		cfg.OpenAI.APIKey,
+		cfg.OpenAI.BaseURL,
+		cfg.OpenAI.Model,
+		cfg.OpenAI.MaxCompletionTokens,
+		cfg.OpenAI.PromptContent,
+		cfg.OpenAI.DesktopLockDetectionPromptContent,
+		cfg.OpenAI.LockScreenDetectionPromptContent,
+		cfg.OpenAI.SummaryModel,
+		cfg.OpenAI.SummaryPromptContent,
+		cfg.OpenAI.SummaryEnhancedContent,
+		cfg.OpenAI.SummaryContextPrefixContent,
+		cfg.OpenAI.SummaryRollingContent,
+		cfg.OpenAI.AnalysisModel,
+		cfg.OpenAI.AnalysisPromptContent,
+	)
+	if err := task.ConfigureClient(cfg, openAI); err != nil {
+		return err
+	}
+	task.RecordUploads(cfg, openAI, st)
+	task.RecordAPICalls(cfg, openAI, st)
+
+	screenshotRecords, err := prefetchScreenshots(st, summaries, "evaluation")
+	if err != nil {
+		return err
+	}
+	eval := evaluator.NewEvaluator(
+		openAI,
+		cfg.Evaluator.EvaluationPromptContent,
+		cfg.Evaluator.ReportContentContent,
+		cfg.Evaluator.ScreenshotSourceContent,
+		cfg.Evaluator.ReportFormatContent,
+		cfg.Evaluator.ScreenshotSourceSectionContent,
+	)
+	fmt.Fprintf(os.Stdout, "Evaluating %d golden and %d regenerated report(s)...\n", len(regenerated), len(regenerated))
+	results := eval.EvaluateReports(summaries, screenshotRecords, regressWorkers)
+
+	// Report per-period and average deltas
+	fmt.Fprintln(os.Stdout)
+	var totalDelta float64
+	compared := 0
+	for i, g := range regenerated {
+		before, after := results[2*i], results[2*i+1]
+		if before.Err != nil || after.Err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to evaluate %s: %v\n", g.PeriodKey, firstError(before.Err, after.Err))
+			continue
+		}
+		beforeScores := evaluator.ParseScores(before.Report)
+		afterScores := evaluator.ParseScores(after.Report)
+		if !beforeScores.Rated() || !afterScores.Rated() {
+			fmt.Fprintf(os.Stderr, "Failed to evaluate %s: no score found in the evaluation\n", g.PeriodKey)
+			continue
+		}
+		delta := afterScores.Sub(beforeScores)
+		fmt.Fprintf(os.Stdout, "%s (%s)\n", g.PeriodKey, g.PeriodType)
+		fmt.Fprintf(os.Stdout, "  golden:      %s\n", beforeScores)
+		fmt.Fprintf(os.Stdout, "  regenerated: %s\n", afterScores)
+		fmt.Fprintf(os.Stdout, "  delta:       %+.1f\n", delta.Overall)
+		totalDelta += delta.Overall
+		compared++
+
+		if regressKeepSandbox {
+			dir := filepath.Join(sandboxDir, "evaluations")
+			if err := os.MkdirAll(dir, 0755); err == nil {
+				name := storage.LegacyPeriodKey(g.PeriodKey)
+				os.WriteFile(filepath.Join(dir, name+"-golden.md"), []byte(before.Report), 0644)
+				os.WriteFile(filepath.Join(dir, name+"-regenerated.md"), []byte(after.Report), 0644)
+			}
+		}
+	}
+	if compared == 0 {
+		return fmt.Errorf("no golden period could be evaluated")
+	}
+
+	average := totalDelta / float64(compared)
+	fmt.Fprintf(os.Stdout, "\nCompared %d/%d golden period(s), average overall delta: %+.2f\n", compared, len(goldens), average)
+	if regressMaxDrop > 0 && -average > regressMaxDrop {
+		return fmt.Errorf("average overall score dropped by %.2f (max %.2f)", -average, regressMaxDrop)
+	}
+	return nil
+}
+
+// selectGoldenPeriods returns the golden periods, only those with the given keys if any
+func selectGoldenPeriods(st *storage.Storage, periodKeys []string) ([]*storage.GoldenPeriod, error) {
+	goldens, err := st.ListGoldenPeriods()
+	if err != nil {
+		return nil, err
+	}
+	if len(goldens) == 0 {
+		return nil, fmt.Errorf("no golden periods, mark one with: stuff-time regress mark <period-key>")
+	}
+	if len(periodKeys) == 0 {
+		return goldens, nil
+	}
+
+	byKey := make(map[string]*storage.GoldenPeriod, len(goldens))
+	for _, g := range goldens {
+		byKey[g.PeriodKey] = g
+	}
+	var selected []*storage.GoldenPeriod
+	for _, key := range periodKeys {
+		g, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a golden period", key)
+		}
+		selected = append(selected, g)
+	}
+	return selected, nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	retagConfigPath string
+	retagFrom       string
+	retagTo         string
+)
+
+func NewRetagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retag",
+		Short: "Re-apply tagging rules to historical screenshots and refresh report statistics",
+		Long: `Re-apply the current tagging rules to screenshots captured since a date, after the rules changed
+(e.g. a repository added under projects.roots or a key added to issues.projects).
+
+Everything runs locally without the LLM: window records are attributed to repositories and
+branches again, issue attributions and focus scores (category mix) are recomputed, and the
+reports of day and longer periods are rewritten from their stored summaries, so only their
+statistics sections change.
+
+Examples:
+  stuff-time retag --from 2025-12-01
+  stuff-time retag --from 2025-12-01 --to 2025-12-07`,
+		RunE: runRetag,
+	}
+
+	cmd.Flags().StringVarP(&retagConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&retagFrom, "from", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&retagTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to today")
+	cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func runRetag(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(retagConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	toDate := retagTo
+	if toDate == "" {
+		toDate = cfg.Storage.LogicalDate(time.Now()).Format("2006-01-02")
+	}
+	from, to, err := parseDayRange(cfg, retagFrom, toDate)
+	if err != nil {
+		return err
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	executor, err := task.NewExecutor(cfg, st)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Retagging %s to %s...\n", datefmt.Date(from), datefmt.Date(to.AddDate(0, 0, -1)))
+	result, err := executor.Retag(from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Windows retagged: %d of %d\n", result.Retagged, result.Windows)
+	fmt.Fprintf(os.Stdout, "Days recomputed:  %d\n", result.Days)
+	fmt.Fprintf(os.Stdout, "Reports updated:  %d\n", result.Reports)
+	return nil
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+
+	"stuff-time/internal/storage"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -23,8 +25,80 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(NewRebuildCmd())            // Rebuild database from screenshot directory
 	rootCmd.AddCommand(NewEvaluateCmd())           // Evaluate period report quality
 	rootCmd.AddCommand(NewImproveCmd())            // Improve period report based on evaluation feedback
+	rootCmd.AddCommand(NewPromptAdviceCmd())       // Suggest prompt changes from a week of evaluations
+	rootCmd.AddCommand(NewPromptCmd())             // Versioned prompts stored in the database
 	rootCmd.AddCommand(NewValidateCmd())           // Validate consistency between database and files
 	rootCmd.AddCommand(NewScanInvalidReportsCmd()) // Scan and detect invalid report files
+	rootCmd.AddCommand(NewTailCmd())               // Stream live events from the daemon
+	rootCmd.AddCommand(NewGalleryCmd())            // Export a day's screenshots as HTML gallery
+	rootCmd.AddCommand(NewForgetCmd())             // Permanently delete data in a time range
+	rootCmd.AddCommand(NewDeliverablesCmd())       // List deliverables extracted from analyses and git
+	rootCmd.AddCommand(NewIssuesCmd())             // Time per Jira/Linear issue
+	rootCmd.AddCommand(NewScoreCmd())              // Daily focus score, streak and trend
+	rootCmd.AddCommand(NewWorkdayCmd())            // Detected workday start/end and overtime
+	rootCmd.AddCommand(NewNoteCmd())               // Attach manual notes to a period
+	rootCmd.AddCommand(NewJournalCmd())            // End-of-day reflection questions
+	rootCmd.AddCommand(NewPlanCmd())               // Plan focus blocks and compare them with the tracked time
+	rootCmd.AddCommand(NewStarCmd())               // Star screenshots for report highlights
+	rootCmd.AddCommand(NewLockCmd())               // Lock hand-edited periods against regeneration
+	rootCmd.AddCommand(NewSearchCmd())             // Search analyses and summaries
+	rootCmd.AddCommand(NewOCRCmd())                // Index the text of screenshots for search
+	rootCmd.AddCommand(NewCoverageCmd())           // Heatmap of captured/analyzed/summarized minutes
+	rootCmd.AddCommand(NewPrivacyCmd())            // Report what was sent to the LLM provider
+	rootCmd.AddCommand(NewCostCmd())               // Monthly tokens/dollars spent by the tool itself
+	rootCmd.AddCommand(NewServeCmd())              // Serve the local HTTP API
+	rootCmd.AddCommand(NewTimeSyncCmd())           // Export sessions to Toggl/Clockify
+	rootCmd.AddCommand(NewAgentCmd())              // Capture and push screenshots to a central daemon
+	rootCmd.AddCommand(NewSiteCmd())               // Build a static HTML site from the reports
+	rootCmd.AddCommand(NewRegressCmd())            // Summary quality regression tests on golden periods
+	rootCmd.AddCommand(NewLineageCmd())            // Trace a summary back to its child summaries and screenshots
+	rootCmd.AddCommand(NewActivityWatchCmd())      // Export screen time per app as ActivityWatch buckets
+	rootCmd.AddCommand(NewRetagCmd())              // Re-apply tagging rules to history and refresh report statistics
+	rootCmd.AddCommand(NewCompactCmd())            // Compact old fifteenmin/hour reports into per-month archives
+	rootCmd.AddCommand(NewWorkerCmd())             // Analyze the screenshot queue of a capture-only daemon
+	rootCmd.AddCommand(NewExportCmd())             // Export period reports as a redacted/encrypted bundle
+	rootCmd.AddCommand(NewBackupCmd())             // Single-file backup and restore for moving to another machine
+	rootCmd.AddCommand(NewTodayCmd())              // Shortcut: print today's summary as plain text
+	rootCmd.AddCommand(NewPauseCmd())              // Shortcut: pause capture for a while
+	rootCmd.AddCommand(NewResumeCmd())             // Shortcut: resume paused capture
+	rootCmd.AddCommand(NewSnapCmd())               // Shortcut: capture and star a screenshot with a note
+
+	return rootCmd
+}
+
+// NewViewerRootCmd creates the root command of the read-only viewer (stuff-time-view)
+// It only exposes browsing, searching and exporting commands and opens the data directory read-only,
+// so an archive can be reviewed on another machine without capturing, analyzing or deleting anything
+func NewViewerRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "stuff-time-view",
+		Short: "Stuff time viewer - browse a stuff-time archive read-only",
+		Long:  "A read-only viewer for stuff-time data: browse, search and export reports without modifying the archive",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			storage.SetReadOnly(true)
+		},
+	}
+
+	rootCmd.AddCommand(NewStatusCmd())
+	rootCmd.AddCommand(NewQueryCmd())
+	rootCmd.AddCommand(NewConfigCmd())
+	rootCmd.AddCommand(NewSummaryCmd())
+	rootCmd.AddCommand(NewSearchCmd())
+	rootCmd.AddCommand(NewCoverageCmd())
+	rootCmd.AddCommand(NewPrivacyCmd())
+	rootCmd.AddCommand(NewCostCmd())
+	rootCmd.AddCommand(NewLineageCmd())
+	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewGalleryCmd())       // Export writes outside the archive
+	rootCmd.AddCommand(NewActivityWatchCmd()) // Export writes outside the archive
+	rootCmd.AddCommand(NewSiteCmd())          // Site is built outside the archive
+	rootCmd.AddCommand(NewExportCmd())        // Export writes outside the archive
+	rootCmd.AddCommand(NewDeliverablesCmd())  // --extract is rejected
+	rootCmd.AddCommand(NewIssuesCmd())        // --correlate is rejected
+	rootCmd.AddCommand(NewScoreCmd())         // Shows stored scores only
+	rootCmd.AddCommand(NewWorkdayCmd())       // Shows stored workdays only
+	rootCmd.AddCommand(NewStarCmd())          // --list only
+	rootCmd.AddCommand(NewTodayCmd())
 
 	return rootCmd
 }
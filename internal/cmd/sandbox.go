@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+// sandboxDBName is the database snapshot inside a sandbox directory
+const sandboxDBName = "stuff-time.db"
+
+// openSandbox returns a copy of cfg pointed at the sandbox directory dir and its storage: a snapshot of the
+// production database and a separate reports directory, so generation can be tried out without
+// overwriting production data. The snapshot is taken on first use; later runs with the same directory
+// keep working on it, delete the directory to start over from the current data
+func openSandbox(cfg *config.Config, st *storage.Storage, dir string) (*config.Config, *storage.Storage, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sandbox directory: %w", err)
+	}
+	// Sandbox reports inside the production reports would be picked up as real reports
+	if reports, err := filepath.Abs(cfg.Storage.ReportsPath); err == nil && cfg.Storage.ReportsPath != "" {
+		if rel, err := filepath.Rel(reports, absDir); err == nil && !strings.HasPrefix(rel, "..") {
+			return nil, nil, fmt.Errorf("sandbox directory must not be inside the reports directory")
+		}
+	}
+	if err := os.MkdirAll(absDir, 0700); err != nil {
+		return nil, nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+
+	sandboxCfg := *cfg
+	sandboxCfg.Storage.DBPath = filepath.Join(absDir, sandboxDBName)
+	sandboxCfg.Storage.ReportsPath = filepath.Join(absDir, "reports")
+
+	if _, err := os.Stat(sandboxCfg.Storage.DBPath); os.IsNotExist(err) {
+		if err := st.SnapshotDatabase(sandboxCfg.Storage.DBPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := sandboxCfg.Storage.EnsureReportsPath(); err != nil {
+		return nil, nil, fmt.Errorf("failed to create sandbox reports path: %w", err)
+	}
+
+	sandbox, err := storage.NewStorage(sandboxCfg.Storage.DBPath, sandboxCfg.Storage.ReportsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize sandbox storage: %w", err)
+	}
+	return &sandboxCfg, sandbox, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestOpenSandbox(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DBPath = filepath.Join(dir, "data", "stuff-time.db")
+	cfg.Storage.ReportsPath = filepath.Join(dir, "data", "reports")
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	production := &storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day", StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: "生产总结"}
+	if err := st.SavePeriodSummary(production); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := openSandbox(cfg, st, filepath.Join(cfg.Storage.ReportsPath, "sandbox")); err == nil {
+		t.Error("openSandbox() accepted a directory inside the reports directory")
+	}
+
+	sandboxDir := filepath.Join(dir, "sandbox")
+	sandboxCfg, sandbox, err := openSandbox(cfg, st, sandboxDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sandboxCfg.Storage.ReportsPath != filepath.Join(sandboxDir, "reports") || cfg.Storage.ReportsPath != filepath.Join(dir, "data", "reports") {
+		t.Errorf("sandbox reports path = %s, production = %s", sandboxCfg.Storage.ReportsPath, cfg.Storage.ReportsPath)
+	}
+	// The sandbox starts from the production data and writes only to its snapshot
+	edited := *production
+	edited.Summary = "沙盒总结"
+	if err := sandbox.SavePeriodSummary(&edited); err != nil {
+		t.Fatal(err)
+	}
+	sandbox.Close()
+
+	if got, err := st.GetPeriodSummary(production.PeriodKey); err != nil || got.Summary != "生产总结" {
+		t.Errorf("production summary after a sandbox write = %v, %v", got, err)
+	}
+
+	// Later runs keep working on the existing snapshot
+	_, sandbox, err = openSandbox(cfg, st, sandboxDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sandbox.Close()
+	if got, err := sandbox.GetPeriodSummary(production.PeriodKey); err != nil || got.Summary != "沙盒总结" {
+		t.Errorf("sandbox summary on reopen = %v, %v, want the sandbox edit", got, err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	scoreConfigPath string
+	scoreDate       string
+	scoreDays       int
+	scoreRecompute  bool
+)
+
+func NewScoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "score",
+		Short: "Show the daily focus score, streak and trend",
+		Long: `Show the daily focus score (0-100) computed from session lengths, context switches
+and activity category mix, together with the current streak and recent trend.
+
+Examples:
+  stuff-time score                      # Today, with 14-day history
+  stuff-time score --date 2025-12-09 --days 30
+  stuff-time score --days 30 --recompute`,
+		RunE: runScore,
+	}
+
+	cmd.Flags().StringVarP(&scoreConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&scoreDate, "date", "d", "", "Day to show (YYYY-MM-DD), defaults to today")
+	cmd.Flags().IntVar(&scoreDays, "days", 14, "Number of days of history to show")
+	cmd.Flags().BoolVar(&scoreRecompute, "recompute", false, "Recompute scores of all days in the history from screenshots")
+
+	return cmd
+}
+
+func runScore(cmd *cobra.Command, args []string) error {
+	if scoreDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	cfg, err := config.Load(scoreConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	day := cfg.Storage.LogicalDate(time.Now())
+	if scoreDate != "" {
+		day, err = time.ParseInLocation("2006-01-02", scoreDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date format: %w", err)
+		}
+	}
+	day = cfg.Storage.DateStart(day)
+
+	// The selected day is always recomputed since it may still be in progress
+	// Read-only mode only shows the stored scores
+	if storage.IsReadOnly() {
+		if scoreRecompute {
+			return fmt.Errorf("--recompute is not available in read-only mode")
+		}
+	} else {
+		firstDay := day
+		if scoreRecompute {
+			firstDay = day.AddDate(0, 0, -(scoreDays - 1))
+		}
+		for d := firstDay; !d.After(day); d = d.AddDate(0, 0, 1) {
+			if _, err := task.ComputeFocusScore(cfg, st, d); err != nil {
+				return fmt.Errorf("failed to compute focus score for %s: %w", d.Format("2006-01-02"), err)
+			}
+		}
+	}
+
+	history, err := task.FocusHistory(st, day, scoreDays)
+	if err != nil {
+		return fmt.Errorf("failed to query focus history: %w", err)
+	}
+
+	values := make([]int, len(history))
+	for i, s := range history {
+		if s != nil {
+			values[i] = s.Score
+		}
+	}
+
+	today := history[len(history)-1]
+	if today == nil {
+		fmt.Fprintf(os.Stdout, "No screenshots for %s\n", datefmt.Date(day))
+	} else {
+		fmt.Fprintf(os.Stdout, "Focus score for %s: %d / 100\n\n", datefmt.Date(day), today.Score)
+		fmt.Fprintf(os.Stdout, "  Active time:      %.0f min\n", today.ActiveMinutes)
+		fmt.Fprintf(os.Stdout, "  Deep work:        %.0f min (sessions >= %d min)\n", today.DeepWorkMinutes, cfg.Focus.DeepWorkMinutes)
+		fmt.Fprintf(os.Stdout, "  Longest session:  %.0f min\n", today.LongestSessionMinutes)
+		fmt.Fprintf(os.Stdout, "  Context switches: %d\n", today.ContextSwitches)
+		categories := make([]string, 0, len(today.CategoryMinutes))
+		for category := range today.CategoryMinutes {
+			categories = append(categories, category)
+		}
+		sort.Slice(categories, func(i, j int) bool {
+			return today.CategoryMinutes[categories[i]] > today.CategoryMinutes[categories[j]]
+		})
+		for _, category := range categories {
+			fmt.Fprintf(os.Stdout, "  %-17s %.0f min\n", category+":", today.CategoryMinutes[category])
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "\nStreak (score >= %d): %d day(s)\n", cfg.Focus.StreakThreshold, focus.Streak(values, cfg.Focus.StreakThreshold))
+	fmt.Fprintf(os.Stdout, "Last %d days: %s\n\n", scoreDays, focus.Sparkline(values))
+	for _, s := range history {
+		if s == nil {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "  %s  %3d\n", s.Date.Format("2006-01-02 Mon"), s.Score)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+var (
+	searchConfigPath string
+	searchFrom       string
+	searchTo         string
+	searchLimit      int
+	searchSummaries  bool
+)
+
+func NewSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search screenshot analyses, screenshot text and period summaries",
+		Long: `Search screenshot analyses (and optionally period summaries) for a piece of text.
+
+When ocr.enabled is set, the text recognized in screenshots is searched too; such matches are
+marked [OCR]. Each result shows the screenshot's time and image path. Screenshots captured before
+OCR was enabled can be indexed with "stuff-time ocr".
+
+Examples:
+  stuff-time search "design review"
+  stuff-time search kubernetes --from 2025-12-01 --to 2025-12-07
+  stuff-time search "周报" --summaries
+  stuff-time search 0x80070005`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runSearch,
+	}
+
+	cmd.Flags().StringVarP(&searchConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&searchFrom, "from", "", "Start date (YYYY-MM-DD), defaults to all time")
+	cmd.Flags().StringVar(&searchTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to today")
+	cmd.Flags().IntVarP(&searchLimit, "limit", "n", 50, "Maximum number of results")
+	cmd.Flags().BoolVarP(&searchSummaries, "summaries", "s", false, "Search summaries of periods overlapping the date range instead of screenshot analyses")
+
+	return cmd
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if text == "" {
+		return fmt.Errorf("search text must not be empty")
+	}
+	if searchLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	cfg, err := config.Load(searchConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	start := time.Time{}
+	end := time.Now().AddDate(0, 0, 1)
+	if searchFrom != "" {
+		if start, err = time.ParseInLocation("2006-01-02", searchFrom, time.Local); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	if searchTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", searchTo, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+		end = to.AddDate(0, 0, 1)
+	}
+
+	if searchSummaries {
+		summaries, err := st.SearchPeriodSummaries(text, start, end, searchLimit)
+		if err != nil {
+			return err
+		}
+		if len(summaries) == 0 {
+			fmt.Fprintf(os.Stdout, "No summaries matching %q\n", text)
+			return nil
+		}
+		for _, s := range summaries {
+			fmt.Fprintf(os.Stdout, "%-12s %s\n", s.PeriodType, s.PeriodKey)
+			fmt.Fprintf(os.Stdout, "  %s\n\n", matchSnippet(s.Summary, text))
+		}
+		return nil
+	}
+
+	records, err := st.SearchScreenshots(text, start, end, searchLimit)
+	if err != nil {
+		return err
+	}
+	texts, err := st.SearchScreenshotText(text, start, end, searchLimit)
+	if err != nil {
+		return err
+	}
+
+	hits := make([]searchHit, 0, len(records)+len(texts))
+	seen := make(map[string]bool, len(records))
+	for _, r := range records {
+		seen[r.ID] = true
+		hits = append(hits, searchHit{id: r.ID, timestamp: r.Timestamp, imagePath: r.ImagePath, content: r.Analysis})
+	}
+	for _, t := range texts {
+		if !seen[t.ScreenshotID] {
+			hits = append(hits, searchHit{id: t.ScreenshotID, timestamp: t.Timestamp, imagePath: t.ImagePath, content: t.Text, ocr: true})
+		}
+	}
+	if len(hits) == 0 {
+		fmt.Fprintf(os.Stdout, "No screenshots matching %q\n", text)
+		return nil
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].timestamp.After(hits[j].timestamp)
+	})
+	if len(hits) > searchLimit {
+		hits = hits[:searchLimit]
+	}
+
+	for _, h := range hits {
+		source := ""
+		if h.ocr {
+			source = "  [OCR]"
+		}
+		fmt.Fprintf(os.Stdout, "%s  %s%s\n", datefmt.DateTime(h.timestamp), h.id, source)
+		fmt.Fprintf(os.Stdout, "  %s\n", h.imagePath)
+		fmt.Fprintf(os.Stdout, "  %s\n\n", matchSnippet(h.content, text))
+	}
+	fmt.Fprintf(os.Stdout, "%d result(s)\n", len(hits))
+	return nil
+}
+
+// searchHit is a screenshot whose analysis or recognized (OCR) text matches the search
+type searchHit struct {
+	id        string
+	timestamp time.Time
+	imagePath string
+	content   string
+	ocr       bool
+}
+
+// matchSnippet returns a single-line excerpt of text around the first (case-insensitive) match of query
+func matchSnippet(text, query string) string {
+	const radius = 60
+
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+	queryRunes := []rune(strings.ToLower(query))
+
+	pos := 0
+	for i := 0; i+len(queryRunes) <= len(lower); i++ {
+		if string(lower[i:i+len(queryRunes)]) == string(queryRunes) {
+			pos = i
+			break
+		}
+	}
+
+	from := max(pos-radius, 0)
+	to := min(pos+len(queryRunes)+radius, len(runes))
+	snippet := string(runes[from:to])
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/api"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+var (
+	serveConfigPath string
+	serveListen     string
+)
+
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the local HTTP API and web dashboard",
+		Long: `Serve the local HTTP API in the foreground (the daemon serves it too when api.enabled is set).
+
+Open http://<listen address>/ in a browser for the dashboard: the activity timeline, the day, work-segment
+and hour summaries and the screenshots of a day.
+
+Endpoints:
+  GET /timeline?date=YYYY-MM-DD[&format=csv]   Minute-resolution activity segments of a day (&space= filters by Space)
+  GET /backlog                                 Unanalyzed screenshot backlog with its catch-up ETA
+  GET /report?period=<period key>              Report of a period as JSON (metadata, sections, citations)
+  GET /screenshots?date=YYYY-MM-DD[&status=]   Screenshots of a day with their analysis
+  GET /thumbnail?id=<screenshot id>[&width=]   JPEG thumbnail of a screenshot
+  GET /summaries?type=<type>[&from=&to=]       Summaries of a period type within a date range
+  GET /summary?period=<period key>             Summary of a period
+  GET /today                                   Today's work summary as plain text
+  POST /pause[?for=1h]                         Pause screenshot capture
+  POST /resume                                 Resume screenshot capture
+  POST /snap[?note=...]                        Capture a screenshot now and star it with the note
+  POST /generate?type=<type>[&date=YYYY-MM-DD] Generate the summary of a period in the background
+
+With api.tokens configured, every request needs a token ("Authorization: Bearer <token>" or ?token=)
+with the scope of the endpoint: read-reports for GET endpoints and the dashboard, trigger-generation
+for POST /generate, admin for everything. Open the dashboard as /?token=<token> once, the browser
+keeps the token in a cookie. Without api.tokens the POST endpoints are disabled.
+api.localhost_only rejects listen addresses and requests from other machines.`,
+		RunE: runServe,
+	}
+
+	cmd.Flags().StringVarP(&serveConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&serveListen, "listen", "", "Listen address, defaults to api.listen")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(serveConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	listen := cfg.API.Listen
+	if serveListen != "" {
+		listen = serveListen
+	}
+
+	server := api.NewServer(cfg, st)
+	if err := server.Start(listen); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "API server listening on http://%s, dashboard at http://%s/ (Ctrl+C to stop)\n", listen, listen)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	return server.Stop()
+}
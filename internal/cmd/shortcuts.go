@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+// Single-purpose commands for Apple Shortcuts, Alfred or Stream Deck: each does one thing and prints
+// one short result, suitable for a notification
+
+var shortcutConfigPath string
+
+// openShortcutStorage loads the config and opens the storage for a shortcut command
+func openShortcutStorage() (*config.Config, *storage.Storage, error) {
+	cfg, err := config.Load(shortcutConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return cfg, st, nil
+}
+
+func NewTodayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "today",
+		Short: "Print today's work summary as plain text",
+		Long: `Print today's day summary as plain text, or the hour summaries so far while the day
+summary has not been generated yet. Meant for automation tools (Apple Shortcuts, Alfred, Stream Deck).
+
+Examples:
+  stuff-time today
+  stuff-time today | pbcopy`,
+		Args: cobra.NoArgs,
+		RunE: runToday,
+	}
+	cmd.Flags().StringVarP(&shortcutConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewPauseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause [duration]",
+		Short: "Pause screenshot capture for a while (default 1h)",
+		Long: `Pause screenshot capture of the running daemon for a duration (default 1h), e.g. during a
+private call. The daemon picks the pause up before its next capture; "stuff-time resume" ends it early.
+
+Examples:
+  stuff-time pause
+  stuff-time pause 30m`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runPause,
+	}
+	cmd.Flags().StringVarP(&shortcutConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume screenshot capture paused with pause",
+		Args:  cobra.NoArgs,
+		RunE:  runResume,
+	}
+	cmd.Flags().StringVarP(&shortcutConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func NewSnapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snap [note]",
+		Short: "Capture a screenshot now and star it with a note",
+		Long: `Capture a screenshot right away, also outside work hours or while capture is paused, and star
+it with the note, so it shows up in the "高光时刻" section of the day and week reports.
+The running daemon analyzes it with its next batch.
+
+Examples:
+  stuff-time snap
+  stuff-time snap "第一次跑通端到端流程"`,
+		RunE: runSnap,
+	}
+	cmd.Flags().StringVarP(&shortcutConfigPath, "config", "c", "", "Path to config file")
+	return cmd
+}
+
+func runToday(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openShortcutStorage()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	text, err := task.TodaySummary(cfg, st, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, text)
+	return nil
+}
+
+func runPause(cmd *cobra.Command, args []string) error {
+	duration := time.Hour
+	if len(args) == 1 {
+		var err error
+		if duration, err = time.ParseDuration(args[0]); err != nil || duration <= 0 {
+			return fmt.Errorf("invalid duration %q (e.g. 30m, 1h)", args[0])
+		}
+	}
+
+	_, st, err := openShortcutStorage()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	until := time.Now().Add(duration)
+	if err := task.PauseCapture(st, until); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "已暂停截图，%s 恢复\n", datefmt.TimeMinute(until))
+	return nil
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	_, st, err := openShortcutStorage()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	if err := task.ResumeCapture(st); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "已恢复截图")
+	return nil
+}
+
+func runSnap(cmd *cobra.Command, args []string) error {
+	cfg, st, err := openShortcutStorage()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if err := cfg.Screenshot.EnsureStoragePath(); err != nil {
+		return fmt.Errorf("failed to create storage path: %w", err)
+	}
+
+	record, err := task.Snap(cfg, st, strings.TrimSpace(strings.Join(args, " ")))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "已截图并加星：%s\n", datefmt.DateTime(record.Timestamp))
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/site"
+	"stuff-time/internal/storage"
+)
+
+var (
+	siteConfigPath string
+	siteOutput     string
+	siteTitle      string
+)
+
+func NewSiteCmd() *cobra.Command {
+	siteCmd := &cobra.Command{
+		Use:   "site",
+		Short: "Build a static HTML site from the reports directory",
+	}
+
+	siteCmd.AddCommand(NewSiteBuildCmd())
+
+	return siteCmd
+}
+
+func NewSiteBuildCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Convert the reports directory into a browsable static site",
+		Long: `Convert every markdown report under storage.reports_path into a static HTML site with an index page
+per year/quarter/month/week/day, breadcrumbs and a search page, as an alternative to the live API.
+The site only uses relative links, so it can be opened from disk or hosted under any path.
+The output directory is replaced on every build; it must not be inside the reports directory.
+
+Examples:
+  stuff-time site build
+  stuff-time site build -o /srv/private/reports --title "Work log"`,
+		RunE: runSiteBuild,
+	}
+
+	cmd.Flags().StringVarP(&siteConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&siteOutput, "output", "o", "", "Output directory (default: site/ next to the reports directory)")
+	cmd.Flags().StringVar(&siteTitle, "title", "stuff-time 报告", "Site title")
+
+	return cmd
+}
+
+func runSiteBuild(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(siteConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Storage.ReportsPath == "" {
+		return fmt.Errorf("storage.reports_path is not configured")
+	}
+
+	output := siteOutput
+	if output == "" {
+		dir := filepath.Dir(cfg.Storage.ReportsPath)
+		// Read-only mode never writes into the archive, export to the working directory instead
+		if storage.IsReadOnly() {
+			dir = "."
+		}
+		output = filepath.Join(dir, "site")
+	}
+
+	result, err := site.Build(cfg.Storage.ReportsPath, output, siteTitle)
+	if err != nil {
+		return fmt.Errorf("failed to build site: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "Site built: %d report page(s), %d index page(s)\n", result.Pages, result.Indexes)
+	fmt.Fprintf(os.Stdout, "Open %s\n", filepath.Join(output, "index.html"))
+	return nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/gallery"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	starConfigPath string
+	starAt         string
+	starNote       string
+	starRemove     bool
+	starList       bool
+	starFrom       string
+	starTo         string
+)
+
+func NewStarCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "star [screenshot-id]",
+		Short: "Star screenshots to include them in report highlights",
+		Long: `Star (bookmark) a screenshot by ID, or the screenshot closest to a time with --at.
+Starred screenshots are shown with their analyses in the "高光时刻" section of day and week reports.
+
+Time format: "YYYY-MM-DD HH:MM" or "YYYY-MM-DD HH:MM:SS".
+
+Examples:
+  stuff-time star 3f2a9c1e-... --note "第一次跑通端到端流程"
+  stuff-time star --at "2025-12-09 14:03"
+  stuff-time star --at "2025-12-09 14:03" --remove
+  stuff-time star --list --from 2025-12-08 --to 2025-12-14`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runStar,
+	}
+
+	cmd.Flags().StringVarP(&starConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&starAt, "at", "", "Star the screenshot closest to this time")
+	cmd.Flags().StringVarP(&starNote, "note", "n", "", "Note shown with the starred screenshot")
+	cmd.Flags().BoolVar(&starRemove, "remove", false, "Remove the star instead of adding it")
+	cmd.Flags().BoolVarP(&starList, "list", "l", false, "List starred screenshots")
+	cmd.Flags().StringVar(&starFrom, "from", "", "Start date for --list (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&starTo, "to", "", "End date for --list (YYYY-MM-DD, inclusive), defaults to --from")
+
+	return cmd
+}
+
+// maxStarDistance is how far from --at the closest screenshot may be
+const maxStarDistance = 10 * time.Minute
+
+// closestScreenshot returns the screenshot captured closest to t
+func closestScreenshot(st *storage.Storage, t time.Time) (*storage.ScreenshotRecord, error) {
+	records, err := st.QueryByDateRange(t.Add(-maxStarDistance), t.Add(maxStarDistance))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+
+	var closest *storage.ScreenshotRecord
+	for _, r := range records {
+		if closest == nil || absDuration(r.Timestamp.Sub(t)) < absDuration(closest.Timestamp.Sub(t)) {
+			closest = r
+		}
+	}
+	if closest == nil {
+		return nil, fmt.Errorf("no screenshot within %s of %s", maxStarDistance, datefmt.DateTime(t))
+	}
+	return closest, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func runStar(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(starConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	if starList {
+		return listStarred(st)
+	}
+	if storage.IsReadOnly() {
+		return fmt.Errorf("starring is not available in read-only mode, use --list")
+	}
+
+	var record *storage.ScreenshotRecord
+	switch {
+	case len(args) == 1:
+		records, err := st.GetScreenshotsByIDs([]string{args[0]})
+		if err != nil {
+			return fmt.Errorf("failed to get screenshot: %w", err)
+		}
+		if record = records[args[0]]; record == nil {
+			return fmt.Errorf("screenshot not found: %s", args[0])
+		}
+	case starAt != "":
+		at, err := parseForgetTime(starAt, false)
+		if err != nil {
+			return err
+		}
+		if record, err = closestScreenshot(st, at); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("must specify a screenshot ID, --at or --list")
+	}
+
+	if starRemove {
+		if err := st.UnstarScreenshot(record.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Unstarred %s (%s)\n", record.ID, datefmt.DateTime(record.Timestamp))
+	} else {
+		if err := st.StarScreenshot(record.ID, starNote); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Starred %s (%s): %s\n", record.ID, datefmt.DateTime(record.Timestamp), gallery.CaptionLine(record.Analysis))
+	}
+
+	return refreshHighlightReports(cfg, st, record.Timestamp)
+}
+
+// refreshHighlightReports re-renders the existing day and week reports containing t
+// so their highlights reflect the change without regenerating the summaries
+func refreshHighlightReports(cfg *config.Config, st *storage.Storage, t time.Time) error {
+	day := cfg.Storage.DayStart(t)
+	keys := []string{
+		storage.BuildPeriodKeyFromStartTime(day, "day"),
+		storage.BuildPeriodKeyFromStartTime(day, "week"),
+	}
+
+	executor := task.NewReportExecutor(cfg, st)
+	for _, key := range keys {
+		summary, err := st.GetPeriodSummary(key)
+		if err != nil || summary == nil {
+			continue
+		}
+		if err := executor.SavePeriodSummaryReport(summary); err != nil {
+			return fmt.Errorf("failed to update report %s: %w", key, err)
+		}
+		fmt.Fprintf(os.Stdout, "Report updated: %s\n", key)
+	}
+	return nil
+}
+
+func listStarred(st *storage.Storage) error {
+	from := time.Now()
+	if starFrom != "" {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", starFrom, time.Local); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	to := from
+	if starTo != "" {
+		var err error
+		if to, err = time.ParseInLocation("2006-01-02", starTo, time.Local); err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+	}
+	to = to.AddDate(0, 0, 1)
+
+	starred, err := st.QueryStarred(from, to)
+	if err != nil {
+		return err
+	}
+	if len(starred) == 0 {
+		fmt.Fprintf(os.Stdout, "No starred screenshots\n")
+		return nil
+	}
+
+	for _, star := range starred {
+		s := star.Screenshot
+		fmt.Fprintf(os.Stdout, "%s  %s  %s\n", datefmt.DateTime(s.Timestamp), s.ID, gallery.CaptionLine(s.Analysis))
+		if star.Note != "" {
+			fmt.Fprintf(os.Stdout, "    ★ %s\n", star.Note)
+		}
+	}
+	return nil
+}
@@ -8,8 +8,12 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"stuff-time/internal/agent"
+	"stuff-time/internal/api"
 	"stuff-time/internal/config"
+	"stuff-time/internal/events"
 	"stuff-time/internal/logger"
+	"stuff-time/internal/permissions"
 	"stuff-time/internal/scheduler"
 	"stuff-time/internal/storage"
 	"stuff-time/internal/task"
@@ -35,6 +39,15 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Files created by the agent from here on are private to the current user
+	if cfg.Storage.StrictPermissions {
+		permissions.RestrictUmask()
+		// The log file was opened while loading the config, before the umask applied
+		if _, err := permissions.Fix([]string{cfg.Storage.LogPath}); err != nil {
+			logger.GetLogger().Warnf("Failed to restrict log file permissions: %v", err)
+		}
+	}
+
 	if err := cfg.Screenshot.EnsureStoragePath(); err != nil {
 		return fmt.Errorf("failed to create storage path: %w", err)
 	}
@@ -47,6 +60,10 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create reports path: %w", err)
 	}
 
+	if err := checkDataPermissions(cfg); err != nil {
+		return err
+	}
+
 	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
@@ -58,6 +75,46 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
 
+	// Start status socket so `stuff-time tail` can stream live events
+	logger.GetLogger().AddHook(events.NewLogHook())
+	statusServer := events.NewServer(getSocketFile())
+	if err := statusServer.Start(); err != nil {
+		logger.GetLogger().Warnf("Failed to start status socket: %v", err)
+	} else {
+		defer statusServer.Stop()
+		logger.GetLogger().Infof("Status socket listening on %s", getSocketFile())
+	}
+
+	if cfg.API.Enabled {
+		apiServer := api.NewServer(cfg, st)
+		if err := apiServer.Start(cfg.API.Listen); err != nil {
+			logger.GetLogger().Warnf("Failed to start API server: %v", err)
+		} else {
+			defer apiServer.Stop()
+			logger.GetLogger().Infof("API server listening on http://%s", cfg.API.Listen)
+		}
+	}
+
+	// With remote analysis the agent server also hands the analysis queue to remote workers
+	if cfg.RemoteAnalysis.Enabled && !cfg.Agent.Enabled {
+		return fmt.Errorf("remote_analysis.enabled requires agent.enabled to serve the analysis queue")
+	}
+	if cfg.Agent.Enabled {
+		agentServer, err := agent.NewServer(cfg.Agent.Token, executor.IngestAgentScreenshot)
+		if err != nil {
+			return fmt.Errorf("failed to create agent server: %w", err)
+		}
+		if cfg.RemoteAnalysis.Enabled {
+			agentServer.ServeQueue(task.NewRemoteQueue(executor))
+		}
+		if err := agentServer.Start(cfg.Agent.Listen, cfg.Agent.TLSCert, cfg.Agent.TLSKey); err != nil {
+			logger.GetLogger().Warnf("Failed to start agent server: %v", err)
+		} else {
+			defer agentServer.Stop()
+			logger.GetLogger().Infof("Accepting remote agent screenshots on %s", cfg.Agent.Listen)
+		}
+	}
+
 	var screenshotSched scheduler.Scheduler
 	if cfg.Screenshot.Cron != "" {
 		screenshotSched, err = scheduler.NewCronScheduler(cfg.Screenshot.Cron)
@@ -65,14 +122,16 @@ func runStart(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to create screenshot cron scheduler: %w", err)
 		}
 	} else {
-		interval, err := cfg.Screenshot.GetIntervalDuration()
+		// Ticks at the shortest app interval; captures of slower apps are skipped by the executor
+		interval, err := cfg.Screenshot.TickInterval()
 		if err != nil {
 			return fmt.Errorf("failed to parse screenshot interval: %w", err)
 		}
 		screenshotSched = scheduler.NewFixedRateScheduler(interval)
 	}
 
-	if err := screenshotSched.Start(executor.CaptureScreenshot); err != nil {
+	// Nothing is captured or analyzed during embargo windows
+	if err := screenshotSched.Start(scheduler.Embargoed(cfg.IsEmbargoed, "screenshot capture", executor.CaptureScreenshot)); err != nil {
 		return fmt.Errorf("failed to start screenshot scheduler: %w", err)
 	}
 
@@ -90,10 +149,23 @@ func runStart(cmd *cobra.Command, args []string) error {
 		analysisSched = scheduler.NewFixedRateScheduler(interval)
 	}
 
-	analysisTask := func() error {
+	analysisTask := scheduler.Embargoed(cfg.IsEmbargoed, "analysis", func() error {
+		run := logger.StartRun("analysis")
+		defer run.Finish()
+
+		// Remote workers analyze and summarize, results arrive through the agent server
+		if cfg.RemoteAnalysis.Enabled {
+			return executor.SyncImages()
+		}
+
 		if err := executor.BatchAnalyze(); err != nil {
 			return err
 		}
+
+		// Retry failed uploads and keep the local screenshot cache within its size
+		if err := executor.SyncImages(); err != nil {
+			logger.GetLogger().Warnf("Failed to sync screenshots with the object store: %v", err)
+		}
 		
 		// Check and fill missing summaries to reduce token consumption
 		// This ensures all intermediate summaries (fifteenmin, halfhour, hour, etc.) are saved
@@ -103,7 +175,7 @@ func runStart(cmd *cobra.Command, args []string) error {
 		}
 		
 		return executor.GeneratePeriodSummary(false, false) // false: not manual, auto-generated
-	}
+	})
 
 	if err := analysisSched.Start(analysisTask); err != nil {
 		return fmt.Errorf("failed to start analysis scheduler: %w", err)
@@ -126,6 +198,14 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	if cleanupSched != nil {
 		cleanupTask := func() error {
+			if _, err := task.CleanupPersonalData(cfg, st); err != nil {
+				logger.GetLogger().Warnf("Failed to cleanup personal data: %v", err)
+			}
+			if cfg.Storage.CompactAfterMonths > 0 {
+				if _, err := task.CompactReports(cfg, st, cfg.Storage.CompactAfterMonths, false); err != nil {
+					logger.GetLogger().Warnf("Failed to compact reports: %v", err)
+				}
+			}
 			return executor.CleanupInvalidReports()
 		}
 
@@ -135,6 +215,45 @@ func runStart(cmd *cobra.Command, args []string) error {
 		logger.GetLogger().Infof("Cleanup scheduler started (interval: %s, cron: %s)", cfg.Screenshot.CleanupInterval, cfg.Screenshot.CleanupCron)
 	}
 
+	// Watch the reports tree for reports deleted or moved by hand
+	var watchdogSched scheduler.Scheduler
+	if cfg.Storage.ReportWatchdog.Enabled && cfg.Storage.ReportsPath != "" {
+		interval, err := cfg.Storage.ReportWatchdog.GetIntervalDuration()
+		if err != nil {
+			return fmt.Errorf("failed to parse report watchdog interval: %w", err)
+		}
+		watchdog := task.NewReportWatchdog(executor, cfg.Storage.ReportWatchdog.Regenerate)
+		watchdogSched = scheduler.NewFixedRateScheduler(interval)
+		if err := watchdogSched.Start(watchdog.Check); err != nil {
+			return fmt.Errorf("failed to start report watchdog: %w", err)
+		}
+		logger.GetLogger().Infof("Report watchdog started (interval: %s, regenerate: %v)", cfg.Storage.ReportWatchdog.Interval, cfg.Storage.ReportWatchdog.Regenerate)
+	}
+
+	// Daily self-test, so a silently broken capture pipeline is noticed within days
+	var healthSched scheduler.Scheduler
+	if cfg.Health.Enabled && cfg.Health.Cron != "" {
+		healthSched, err = scheduler.NewCronScheduler(cfg.Health.Cron)
+		if err != nil {
+			return fmt.Errorf("failed to create health check cron scheduler: %w", err)
+		}
+		healthTask := func() error {
+			_, err := executor.RunHealthCheck()
+			return err
+		}
+		if err := healthSched.Start(healthTask); err != nil {
+			return fmt.Errorf("failed to start health check scheduler: %w", err)
+		}
+		logger.GetLogger().Infof("Health check scheduler started (cron: %s)", cfg.Health.Cron)
+	}
+
+	// Catch up on screenshots captured before a restart, newest first, before the regular analysis
+	if !cfg.RemoteAnalysis.Enabled {
+		if err := executor.CatchUp(cfg.Screenshot.CatchupBudget); err != nil {
+			logger.GetLogger().Warnf("Startup catch-up failed: %v", err)
+		}
+	}
+
 	// Execute analysis immediately on startup
 	logger.GetLogger().Info("Executing initial analysis on startup...")
 	if err := analysisTask(); err != nil {
@@ -162,8 +281,38 @@ func runStart(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to stop cleanup scheduler: %w", err)
 		}
 	}
+	if watchdogSched != nil {
+		if err := watchdogSched.Stop(); err != nil {
+			return fmt.Errorf("failed to stop report watchdog: %w", err)
+		}
+	}
+	if healthSched != nil {
+		if err := healthSched.Stop(); err != nil {
+			return fmt.Errorf("failed to stop health check scheduler: %w", err)
+		}
+	}
 	logger.GetLogger().Info("Stopped.")
 
 	return nil
 }
 
+// checkDataPermissions checks the data paths before the agent starts writing to them
+// Data owned by another user is refused (it would mix screenshots of different accounts),
+// group/world accessible paths are only warned about
+func checkDataPermissions(cfg *config.Config) error {
+	if !cfg.Storage.StrictPermissions {
+		return nil
+	}
+
+	issues, err := permissions.Check(cfg.DataPaths(), false)
+	if err != nil {
+		return fmt.Errorf("failed to check data permissions: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.Kind == permissions.IssueForeignOwner {
+			return fmt.Errorf("refusing to use data path %s: it is owned by another user, configure a per-user data directory", issue.Path)
+		}
+		logger.GetLogger().Warnf("Data path is %s: %s (run `stuff-time validate --fix-permissions`)", issue.Kind, issue)
+	}
+	return nil
+}
@@ -3,10 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/events"
 	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
 
 	"github.com/spf13/cobra"
 )
@@ -36,7 +40,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	defer st.Close()
 
 	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today := cfg.Storage.DayStart(now)
 	tomorrow := today.AddDate(0, 0, 1)
 
 	screenshots, err := st.QueryByDateRange(today, tomorrow)
@@ -54,6 +58,69 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Fprintf(os.Stdout, "Today's Screenshots: %d\n", len(screenshots))
 	fmt.Fprintf(os.Stdout, "Today's Hour Summaries: %d\n\n", len(summaries))
 
+	// Daemon status flags are only available while the daemon is running
+	if flags, err := events.QueryStatus(getSocketFile()); err != nil {
+		fmt.Fprintf(os.Stdout, "Daemon: not running\n\n")
+	} else {
+		fmt.Fprintf(os.Stdout, "Daemon: running\n")
+		switch flags[events.StatusScreenRecording] {
+		case "lost":
+			fmt.Fprintf(os.Stdout, "Screen recording: LOST (captures are blank or failing, re-grant permission in System Settings > Privacy & Security > Screen Recording)\n\n")
+		case "ok":
+			fmt.Fprintf(os.Stdout, "Screen recording: ok\n\n")
+		default:
+			fmt.Fprintf(os.Stdout, "Screen recording: unknown (no capture yet)\n")
+		}
+		if flags[events.StatusAnalysis] == "running" {
+			fmt.Fprintf(os.Stdout, "Analysis: running\n\n")
+		} else {
+			fmt.Fprintf(os.Stdout, "Analysis: idle\n\n")
+		}
+	}
+
+	// Pipeline state persisted by the daemon, to check that capture, analysis and summaries keep running
+	states, err := st.GetRuntimeStates()
+	if err != nil {
+		return fmt.Errorf("failed to get runtime state: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Pipeline:\n")
+	printRuntimeState(now, "Last capture", states[storage.RuntimeLastCapture])
+	if until, err := task.CapturePausedUntil(st, now); err == nil && !until.IsZero() {
+		fmt.Fprintf(os.Stdout, "  %-26s until %s (stuff-time resume to end it)\n", "Capture paused:", datefmt.DateTime(until))
+	}
+	if cfg.Performance.Budget.Enabled() {
+		if budget, err := task.CheckBudget(cfg, st, now); err == nil && budget.Exceeded != "" {
+			fmt.Fprintf(os.Stdout, "  %-26s %s, analysis and summaries deferred until %s\n", "Budget exceeded:", budget.Exceeded, datefmt.DateTimeMinute(budget.ResumeAt))
+		}
+	}
+	printRuntimeState(now, "Last analysis", states[storage.RuntimeLastAnalysis])
+	for _, periodType := range []string{"fifteenmin", "hour", "work-segment", "day", "week", "month", "quarter", "year"} {
+		if state := states[storage.RuntimeLastSummaryPrefix+periodType]; state != nil {
+			printRuntimeState(now, fmt.Sprintf("Last %s summary", periodType), state)
+		}
+	}
+	if checks, err := st.QueryHealthChecks(1); err == nil && len(checks) > 0 {
+		printHealthCheck(now, checks[0])
+	}
+	fmt.Fprintf(os.Stdout, "\n")
+
+	if backlog, err := task.Backlog(cfg, st, now); err == nil {
+		printBacklog(backlog)
+	}
+
+	// Reports the daemon found deleted or moved by hand
+	if missing, err := st.ListMissingReports(); err == nil && len(missing) > 0 {
+		fmt.Fprintf(os.Stdout, "Missing Reports: %d (deleted or moved by hand)\n", len(missing))
+		for i, m := range missing {
+			if i >= 5 {
+				fmt.Fprintf(os.Stdout, "  ... and %d more\n", len(missing)-5)
+				break
+			}
+			fmt.Fprintf(os.Stdout, "  %s: %s\n", m.PeriodKey, m.ReportPath)
+		}
+		fmt.Fprintf(os.Stdout, "\n")
+	}
+
 	if len(summaries) > 0 {
 		fmt.Fprintf(os.Stdout, "Recent Hour Summaries:\n")
 		for i, s := range summaries {
@@ -67,6 +134,76 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printRuntimeState prints when a pipeline step last ran and its value
+func printRuntimeState(now time.Time, label string, state *storage.RuntimeState) {
+	if state == nil {
+		fmt.Fprintf(os.Stdout, "  %-26s never\n", label+":")
+		return
+	}
+	fmt.Fprintf(os.Stdout, "  %-26s %s (%s ago) %s\n", label+":", datefmt.DateTime(state.UpdatedAt),
+		now.Sub(state.UpdatedAt).Round(time.Second), state.Value)
+}
+
+// printBacklog prints the unanalyzed screenshot backlog with its trend and projected catch-up time,
+// suggesting more analysis workers when that would shorten a long catch-up noticeably
+func printBacklog(b *task.BacklogStatus) {
+	fmt.Fprintf(os.Stdout, "Analysis Backlog:\n")
+	trend := ""
+	if len(b.Samples) >= 2 {
+		trend = fmt.Sprintf(" (%+.0f/h over the last hours)", b.Trend)
+	}
+	fmt.Fprintf(os.Stdout, "  %-26s %d%s\n", "Unanalyzed screenshots:", b.Pending, trend)
+	if b.Pending == 0 {
+		fmt.Fprintf(os.Stdout, "\n")
+		return
+	}
+
+	switch {
+	case b.Latency == 0:
+		fmt.Fprintf(os.Stdout, "  %-26s unknown (no analysis request in the last 24h)\n", "Catch-up ETA:")
+	case b.CatchesUp:
+		fmt.Fprintf(os.Stdout, "  %-26s ~%s (%d workers, %.1fs per screenshot)\n", "Catch-up ETA:",
+			formatETA(b.ETA), b.Workers, b.Latency.Seconds())
+	default:
+		fmt.Fprintf(os.Stdout, "  %-26s never, captures (%.0f/h) outpace analysis with %d workers at %.1fs per screenshot\n", "Catch-up ETA:",
+			b.Inflow, b.Workers, b.Latency.Seconds())
+	}
+	if b.Latency > 0 && (!b.CatchesUp || b.ETA > 2*time.Hour) {
+		if eta, ok := b.ETAWith(b.Workers * 2); ok && (!b.CatchesUp || eta < b.ETA*3/4) {
+			setting := "screenshot.analysis_workers"
+			if b.Scaled {
+				setting = "screenshot.worker_scaling.max_workers"
+			}
+			fmt.Fprintf(os.Stdout, "  Raising %s to %d would catch up in ~%s\n", setting, b.Workers*2, formatETA(eta))
+		}
+	}
+	fmt.Fprintf(os.Stdout, "\n")
+}
+
+// formatETA rounds a projected duration to minutes
+func formatETA(d time.Duration) string {
+	if d < time.Minute {
+		return "1m"
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
+// printHealthCheck prints the result of the last self-test, with the failed steps if it failed
+func printHealthCheck(now time.Time, check *storage.HealthCheck) {
+	result := "passed"
+	if !check.Passed {
+		var failed []string
+		for _, line := range strings.Split(check.Details, "\n") {
+			if strings.Contains(line, ": failed") {
+				failed = append(failed, line)
+			}
+		}
+		result = "FAILED " + strings.Join(failed, "; ")
+	}
+	fmt.Fprintf(os.Stdout, "  %-26s %s (%s ago) %s\n", "Last health check:", datefmt.DateTime(check.Timestamp),
+		now.Sub(check.Timestamp).Round(time.Second), result)
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
 	"stuff-time/internal/storage"
 )
 
@@ -42,7 +43,7 @@ func runSummary(cmd *cobra.Command, args []string) error {
 	var start, end time.Time
 	var periodType string
 
-	now := time.Now()
+	now := cfg.Storage.LogicalDate(time.Now())
 	if summaryDate != "" {
 		date, err := time.Parse("2006-01-02", summaryDate)
 		if err != nil {
@@ -53,27 +54,27 @@ func runSummary(cmd *cobra.Command, args []string) error {
 
 	switch summaryPeriod {
 	case "day":
-		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		start = time.Date(now.Year(), now.Month(), now.Day(), cfg.Storage.DayStartHour, 0, 0, 0, now.Location())
 		end = start.AddDate(0, 0, 1)
 		periodType = "day"
-		fmt.Fprintf(os.Stdout, "Daily Summary for %s\n", start.Format("2006-01-02"))
+		fmt.Fprintf(os.Stdout, "Daily Summary for %s\n", datefmt.Date(start))
 	case "week":
 		weekday := int(now.Weekday())
 		if weekday == 0 {
 			weekday = 7
 		}
-		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		start = time.Date(now.Year(), now.Month(), now.Day(), cfg.Storage.DayStartHour, 0, 0, 0, now.Location())
 		start = start.AddDate(0, 0, -(weekday - 1))
 		end = start.AddDate(0, 0, 7)
 		periodType = "week"
-		fmt.Fprintf(os.Stdout, "Weekly Summary for week starting %s\n", start.Format("2006-01-02"))
+		fmt.Fprintf(os.Stdout, "Weekly Summary for week starting %s\n", datefmt.Date(start))
 	case "month":
-		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		start = time.Date(now.Year(), now.Month(), 1, cfg.Storage.DayStartHour, 0, 0, 0, now.Location())
 		end = start.AddDate(0, 1, 0)
 		periodType = "month"
 		fmt.Fprintf(os.Stdout, "Monthly Summary for %s\n", start.Format("2006-01"))
 	case "year":
-		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		start = time.Date(now.Year(), 1, 1, cfg.Storage.DayStartHour, 0, 0, 0, now.Location())
 		end = start.AddDate(1, 0, 0)
 		periodType = "year"
 		fmt.Fprintf(os.Stdout, "Yearly Summary for %d\n", now.Year())
@@ -95,8 +96,8 @@ func runSummary(cmd *cobra.Command, args []string) error {
 		for _, s := range summaries {
 			fmt.Fprintf(os.Stdout, "%s (%s - %s):\n%s\n\n",
 				s.PeriodKey,
-				s.StartTime.Format("2006-01-02 15:04"),
-				s.EndTime.Format("2006-01-02 15:04"),
+				datefmt.DateTimeMinute(s.StartTime),
+				datefmt.DateTimeMinute(s.EndTime),
 				s.Summary)
 		}
 	} else {
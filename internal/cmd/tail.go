@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/events"
+)
+
+var (
+	tailLevel     string
+	tailComponent string
+	tailLines     int
+	tailNoColor   bool
+)
+
+func NewTailCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Stream live activity events from the running daemon",
+		Long: `Stream structured events (captures, analyses, summary generations, API retries)
+from the running daemon over the status socket.
+
+Examples:
+  stuff-time tail
+  stuff-time tail --level warn
+  stuff-time tail --component analyzer`,
+		RunE: runTail,
+	}
+
+	cmd.Flags().StringVarP(&tailLevel, "level", "l", "info", "Minimum level to show (debug, info, warn, error)")
+	cmd.Flags().StringVar(&tailComponent, "component", "", "Only show events from these components, comma-separated (capture, analyzer, summary, daemon)")
+	cmd.Flags().IntVarP(&tailLines, "lines", "n", 20, "Number of recent events to show before streaming")
+	cmd.Flags().BoolVar(&tailNoColor, "no-color", false, "Disable colorized output")
+
+	return cmd
+}
+
+func runTail(cmd *cobra.Command, args []string) error {
+	minRank := events.LevelRank(strings.ToLower(tailLevel))
+
+	components := make(map[string]bool)
+	for _, c := range strings.Split(tailComponent, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			components[c] = true
+		}
+	}
+
+	useColor := !tailNoColor && isTerminal(os.Stdout)
+
+	return events.Tail(getSocketFile(), tailLines, func(event events.Event) {
+		if events.LevelRank(event.Level) < minRank {
+			return
+		}
+		if len(components) > 0 && !components[event.Component] {
+			return
+		}
+		fmt.Fprintln(os.Stdout, formatTailEvent(event, useColor))
+	})
+}
+
+// formatTailEvent formats an event as a single line, optionally colorized by level
+func formatTailEvent(event events.Event, useColor bool) string {
+	level := strings.ToUpper(event.Level)
+	if len(level) > 5 {
+		level = level[:5]
+	}
+	line := fmt.Sprintf("%s %-5s [%-8s] %s",
+		datefmt.DateTime(event.Time), level, event.Component, event.Message)
+	if !useColor {
+		return line
+	}
+
+	var color string
+	switch events.LevelRank(event.Level) {
+	case 0:
+		color = "\033[90m" // gray
+	case 2:
+		color = "\033[33m" // yellow
+	case 3:
+		color = "\033[31m" // red
+	default:
+		color = "\033[36m" // cyan
+	}
+	return color + line + "\033[0m"
+}
+
+// isTerminal reports whether f is a character device (terminal)
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
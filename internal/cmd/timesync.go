@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	timesyncConfigPath string
+	timesyncFrom       string
+	timesyncTo         string
+	timesyncDryRun     bool
+)
+
+func NewTimeSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timesync",
+		Short: "Export work sessions as Toggl/Clockify time entries",
+		Long: `Export the work sessions detected per repository (from the IDE/terminal windows recorded at capture time)
+as time entries to Toggl Track or Clockify. Exported sessions are remembered, so running it again never
+creates duplicate entries. Sessions that may still be going on are left for a later run.
+
+Examples:
+  stuff-time timesync --dry-run                   # Preview today's sessions
+  stuff-time timesync --from 2025-12-01 --to 2025-12-07`,
+		RunE: runTimeSync,
+	}
+
+	cmd.Flags().StringVarP(&timesyncConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVar(&timesyncFrom, "from", "", "Start date (YYYY-MM-DD), defaults to today")
+	cmd.Flags().StringVar(&timesyncTo, "to", "", "End date (YYYY-MM-DD, inclusive), defaults to --from")
+	cmd.Flags().BoolVar(&timesyncDryRun, "dry-run", false, "Only show the sessions that would be exported")
+
+	return cmd
+}
+
+func runTimeSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(timesyncConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	start, end, err := parseDayRange(cfg, timesyncFrom, timesyncTo)
+	if err != nil {
+		return err
+	}
+
+	entries, err := task.ProjectSessions(cfg, st, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to detect sessions: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stdout, "No finished sessions between %s and %s\n", datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+		return nil
+	}
+
+	results, syncErr := task.SyncTimeEntries(cfg, st, entries, timesyncDryRun)
+
+	mode := ""
+	if timesyncDryRun {
+		mode = ", dry run"
+	}
+	fmt.Fprintf(os.Stdout, "Sessions (%s%s):\n", cfg.TimeSync.Provider, mode)
+	created, failed := 0, 0
+	for _, r := range results {
+		project := r.Entry.Project
+		if r.Entry.ProjectID != "" {
+			project += " -> " + r.Entry.ProjectID
+		}
+		var status string
+		switch {
+		case r.AlreadySynced:
+			status = "already synced (" + r.ExternalID + ")"
+		case r.Err != nil:
+			status = "FAILED: " + r.Err.Error()
+			failed++
+		case timesyncDryRun:
+			status = "would create"
+		default:
+			status = "created (" + r.ExternalID + ")"
+			created++
+		}
+		fmt.Fprintf(os.Stdout, "  %s-%s  %4d min  %-30s  %s\n",
+			datefmt.DateTimeMinute(r.Entry.Start), datefmt.TimeMinute(r.Entry.End),
+			int(r.Entry.End.Sub(r.Entry.Start).Minutes()), project, status)
+	}
+	if syncErr != nil {
+		return syncErr
+	}
+
+	if !timesyncDryRun {
+		fmt.Fprintf(os.Stdout, "\nCreated %d time entr(ies), %d failed\n", created, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d session(s) failed to sync", failed)
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/permissions"
 	"stuff-time/internal/storage"
 )
 
@@ -19,6 +21,8 @@ var validateEndDate string
 var validateFix bool
 var validateVerbose bool
 var validateRebuildDB bool
+var validateFixPermissions bool
+var validateCheckConfig bool
 
 func NewValidateCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -31,7 +35,9 @@ This command checks:
 3. Whether files have corresponding database records
 
 Use --fix to automatically correct inconsistencies.
-Use --rebuild-db to rebuild entire database from report files (useful when database file is missing or corrupted).`,
+Use --rebuild-db to rebuild entire database from report files (useful when database file is missing or corrupted).
+Use --fix-permissions to restrict screenshots, reports, database and log to the current user (0700/0600).
+Use --check-config to check the config file for unknown keys and values of the wrong type.`,
 		RunE: runValidate,
 	}
 
@@ -42,11 +48,18 @@ Use --rebuild-db to rebuild entire database from report files (useful when datab
 	cmd.Flags().BoolVarP(&validateFix, "fix", "f", false, "Automatically fix inconsistencies (rebuild period_key from file content)")
 	cmd.Flags().BoolVarP(&validateVerbose, "verbose", "v", false, "Show detailed validation results")
 	cmd.Flags().BoolVarP(&validateRebuildDB, "rebuild-db", "r", false, "Rebuild database from report files (use when database file is missing or corrupted)")
+	cmd.Flags().BoolVar(&validateFixPermissions, "fix-permissions", false, "Remove group/world access from all data files and directories")
+	cmd.Flags().BoolVar(&validateCheckConfig, "check-config", false, "Check the config file against the config schema")
 
 	return cmd
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
+	// Checked before loading, which fails on the first undecodable value without naming the key
+	if validateCheckConfig {
+		return checkConfigFile(validateConfigPath)
+	}
+
 	cfg, err := config.Load(validateConfigPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -54,6 +67,10 @@ func runValidate(cmd *cobra.Command, args []string) error {
 
 	// Logger initialization is handled by config
 
+	if validateFixPermissions {
+		return fixDataPermissions(cfg)
+	}
+
 	// Check if database file exists
 	dbExists := false
 	if cfg.Storage.DBPath != "" {
@@ -122,7 +139,7 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		periodTypes = []string{validatePeriodType}
 	}
 
-	fmt.Printf("Validating period summaries from %s to %s\n", startTime.Format("2006-01-02"), endTime.Format("2006-01-02"))
+	fmt.Printf("Validating period summaries from %s to %s\n", datefmt.Date(startTime), datefmt.Date(endTime))
 	fmt.Println()
 
 	var totalChecked int
@@ -306,3 +323,67 @@ func validateFileConsistency(reportsPath string, filePath string, periodType str
 	return issues, nil
 }
 
+
+// fixDataPermissions removes group/world access from every data file and directory
+func fixDataPermissions(cfg *config.Config) error {
+	if !permissions.Supported() {
+		fmt.Println("Permission bits are not supported on this platform, nothing to fix")
+		return nil
+	}
+
+	paths := cfg.DataPaths()
+	issues, err := permissions.Check(paths, true)
+	if err != nil {
+		return fmt.Errorf("failed to check data permissions: %w", err)
+	}
+	if len(issues) == 0 {
+		fmt.Println("✓ All data paths are private to the current user")
+		return nil
+	}
+
+	foreign := 0
+	for _, issue := range issues {
+		if issue.Kind == permissions.IssueForeignOwner {
+			foreign++
+			if validateVerbose {
+				fmt.Printf("  ! %s\n", issue)
+			}
+		} else if validateVerbose {
+			fmt.Printf("  - %s\n", issue)
+		}
+	}
+
+	fixed, err := permissions.Fix(paths)
+	if err != nil {
+		return fmt.Errorf("failed to fix data permissions: %w", err)
+	}
+	fmt.Printf("Fixed permissions of %d path(s)\n", fixed)
+	if foreign > 0 {
+		fmt.Printf("WARNING: %d path(s) are owned by another user and were left unchanged, move them to that user's data directory\n", foreign)
+	}
+	return nil
+}
+
+// checkConfigFile reports unknown keys and mistyped values of the config file with their key paths
+func checkConfigFile(configPath string) error {
+	if configPath == "" {
+		// Resolve the file the same way every command does; load errors are what the check explains
+		_, _ = config.Load("")
+		if configPath = config.FileUsed(); configPath == "" {
+			return fmt.Errorf("no config file found")
+		}
+	}
+
+	errs, err := config.CheckFile(configPath)
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		fmt.Printf("%s: OK\n", configPath)
+		return nil
+	}
+	for _, e := range errs {
+		fmt.Printf("%s: %s\n", configPath, e)
+	}
+	return fmt.Errorf("%d problem(s) found in %s", len(errs), configPath)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+)
+
+var (
+	workdayConfigPath string
+	workdayDate       string
+	workdayDays       int
+)
+
+func NewWorkdayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workday",
+		Short: "Show the detected start and end of each workday and the overtime",
+		Long: `Show when each workday actually started and ended: the first and last capture that is not a lock
+screen, desktop or idle screenshot. Overtime is the time before and after screenshot.work_hours, or with
+workday.use_detected the time beyond workday.standard_hours.
+
+Examples:
+  stuff-time workday                      # The last 7 days
+  stuff-time workday --date 2025-12-09 --days 30`,
+		RunE: runWorkday,
+	}
+
+	cmd.Flags().StringVarP(&workdayConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().StringVarP(&workdayDate, "date", "d", "", "Last day to show (YYYY-MM-DD), defaults to today")
+	cmd.Flags().IntVar(&workdayDays, "days", 7, "Number of days to show")
+
+	return cmd
+}
+
+func runWorkday(cmd *cobra.Command, args []string) error {
+	if workdayDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	cfg, err := config.Load(workdayConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	day := cfg.Storage.LogicalDate(time.Now())
+	if workdayDate != "" {
+		day, err = time.ParseInLocation("2006-01-02", workdayDate, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date format: %w", err)
+		}
+	}
+	end := cfg.Storage.DateStart(day).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -workdayDays)
+
+	// Read-only mode only shows the stored workdays
+	var workdays []*storage.Workday
+	if storage.IsReadOnly() {
+		workdays, err = st.QueryWorkdays(start, end)
+	} else {
+		workdays, err = task.DetectWorkdays(cfg, st, start, end)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get workdays: %w", err)
+	}
+	if len(workdays) == 0 {
+		fmt.Fprintf(os.Stdout, "No work captured from %s to %s\n", datefmt.Date(start), datefmt.Date(end.AddDate(0, 0, -1)))
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "%-12s %-6s %-6s %8s %8s\n", "Date", "Start", "End", "Length", "Overtime")
+	var total, overtimeTotal time.Duration
+	for _, w := range workdays {
+		overtime := "-"
+		if d, ok := task.WorkdayOvertime(cfg, w); ok {
+			overtimeTotal += d
+			overtime = formatWorkdayDuration(d)
+		}
+		total += w.Duration()
+		fmt.Fprintf(os.Stdout, "%-12s %-6s %-6s %8s %8s\n", datefmt.Date(w.Date), datefmt.TimeMinute(w.Start),
+			datefmt.TimeMinute(w.End), formatWorkdayDuration(w.Duration()), overtime)
+	}
+	fmt.Fprintf(os.Stdout, "\n%d workdays, %s in total (%s on average), %s overtime\n", len(workdays),
+		formatWorkdayDuration(total), formatWorkdayDuration(total/time.Duration(len(workdays))), formatWorkdayDuration(overtimeTotal))
+	return nil
+}
+
+// formatWorkdayDuration formats a duration as "9h35m"
+func formatWorkdayDuration(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
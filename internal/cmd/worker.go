@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"stuff-time/internal/agent"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/scheduler"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/task"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workerConfigPath string
+	workerOnce       bool
+)
+
+func NewWorkerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Analyze the screenshot queue of a capture-only daemon",
+		Long: `Run as a remote analysis worker: screenshots waiting for analysis are pulled from the daemon at
+agent.server_url (started with remote_analysis.enabled), analyzed and summarized with this machine's
+model settings, and the analyses and summaries are pushed back to the daemon.
+The worker keeps its own database and reports; results that couldn't be pushed are pushed by the next run.
+A daemon on another machine must be reached over https (agent.ca_cert trusts a self-signed certificate).`,
+		RunE: runWorker,
+	}
+
+	cmd.Flags().StringVarP(&workerConfigPath, "config", "c", "", "Path to config file")
+	cmd.Flags().BoolVar(&workerOnce, "once", false, "Pull, analyze and push once, then exit")
+
+	return cmd
+}
+
+func runWorker(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(workerConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	client, err := agent.NewClient(cfg.Agent.ServerURL, cfg.Agent.Token)
+	if err != nil {
+		return err
+	}
+	if cfg.Agent.CACert != "" {
+		if err := client.TrustCA(cfg.Agent.CACert); err != nil {
+			return err
+		}
+	}
+	name := cfg.Agent.Name
+	if name == "" {
+		if name, err = os.Hostname(); err != nil {
+			return fmt.Errorf("failed to get hostname (set agent.name): %w", err)
+		}
+	}
+
+	st, err := storage.NewStorage(cfg.Storage.DBPath, cfg.Storage.ReportsPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	executor, err := task.NewExecutor(cfg, st)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+	worker := task.NewRemoteWorker(executor, client, name)
+
+	if err := client.Ping(); err != nil {
+		logger.GetLogger().Warnf("Daemon at %s is not reachable, results are pushed once it is: %v", cfg.Agent.ServerURL, err)
+	}
+
+	workerTask := func() error {
+		run := logger.StartRun("worker")
+		defer run.Finish()
+
+		result, err := worker.Run()
+		if err != nil {
+			return err
+		}
+		if result.Pulled > 0 || result.Analyses > 0 || result.Summaries > 0 {
+			logger.GetLogger().Infof("Worker run: pulled %d screenshot(s), %d analyses and %d summaries merged",
+				result.Pulled, result.Analyses, result.Summaries)
+		}
+		return nil
+	}
+
+	if workerOnce {
+		return workerTask()
+	}
+
+	sched := scheduler.NewFixedRateScheduler(cfg.RemoteAnalysis.GetPullIntervalDuration())
+	if err := sched.Start(workerTask); err != nil {
+		return fmt.Errorf("failed to start worker scheduler: %w", err)
+	}
+	logger.GetLogger().Infof("Worker %s started, pulling from %s every %s", name, cfg.Agent.ServerURL, cfg.RemoteAnalysis.GetPullIntervalDuration())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	logger.GetLogger().Info("Stopping worker...")
+	return sched.Stop()
+}
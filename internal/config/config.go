@@ -4,19 +4,58 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/idlescreen"
 	"stuff-time/internal/logger"
 )
 
 type Config struct {
-	OpenAI      OpenAIConfig      `mapstructure:"openai"`
-	Screenshot  ScreenshotConfig  `mapstructure:"screenshot"`
-	Storage     StorageConfig     `mapstructure:"storage"`
-	Evaluator   EvaluatorConfig   `mapstructure:"evaluator"`
-	Performance PerformanceConfig `mapstructure:"performance"`
+	OpenAI       OpenAIConfig       `mapstructure:"openai"`
+	Screenshot   ScreenshotConfig   `mapstructure:"screenshot"`
+	Storage      StorageConfig      `mapstructure:"storage"`
+	Evaluator    EvaluatorConfig    `mapstructure:"evaluator"`
+	Performance  PerformanceConfig  `mapstructure:"performance"`
+	Chaos        ChaosConfig        `mapstructure:"chaos"`
+	Summary      SummaryConfig      `mapstructure:"summary"`
+	Deliverables DeliverablesConfig `mapstructure:"deliverables"`
+	Issues       IssuesConfig       `mapstructure:"issues"`
+	Focus        FocusConfig        `mapstructure:"focus"`
+	Breaks       BreaksConfig       `mapstructure:"breaks"`
+	Reading      ReadingConfig      `mapstructure:"reading"`
+	Meetings     MeetingsConfig     `mapstructure:"meetings"`
+	Threads      ThreadsConfig      `mapstructure:"threads"`
+	Analysis     AnalysisConfig     `mapstructure:"analysis"`
+	Projects     ProjectsConfig     `mapstructure:"projects"`
+	API          APIConfig          `mapstructure:"api"`
+	TimeSync     TimeSyncConfig     `mapstructure:"timesync"`
+	Journal      JournalConfig      `mapstructure:"journal"`
+	LocalOnly    LocalOnlyConfig    `mapstructure:"local_only"`
+	Routing      RoutingConfig      `mapstructure:"routing"`
+	Personal     PersonalConfig     `mapstructure:"personal"`
+	Agent        AgentConfig        `mapstructure:"agent"`
+	ObjectStore  ObjectStoreConfig  `mapstructure:"object_store"`
+	Locale       LocaleConfig       `mapstructure:"locale"`
+	Health       HealthConfig       `mapstructure:"health"`
+
+	RemoteAnalysis RemoteAnalysisConfig `mapstructure:"remote_analysis"`
+	Export         ExportConfig         `mapstructure:"export"`
+	DesktopLock    DesktopLockConfig    `mapstructure:"desktop_lock"`
+	OCR            OCRConfig            `mapstructure:"ocr"`
+	Spaces         SpacesConfig         `mapstructure:"spaces"`
+	Idle           IdleConfig           `mapstructure:"idle"`
+	Prompts        PromptsConfig        `mapstructure:"prompts"`
+	Workday        WorkdayConfig        `mapstructure:"workday"`
+
+	// 屏蔽时段（如每周二 17:00-18:00 的心理咨询）：不截图、不分析、不报告空档，报告中只显示"已屏蔽"
+	Embargo []EmbargoWindow `mapstructure:"embargo"`
 }
 
 type OpenAIConfig struct {
@@ -25,6 +64,17 @@ type OpenAIConfig struct {
 	Model               string `mapstructure:"model"`    // Default model for screenshot analysis
 	MaxCompletionTokens int    `mapstructure:"max_completion_tokens"`
 
+	// Provider: "openai" (default, also OpenAI-compatible endpoints) or "azure" (Azure OpenAI, base_url is
+	// the resource endpoint and models are deployment names)
+	Provider         string            `mapstructure:"provider"`
+	APIVersion       string            `mapstructure:"api_version"`       // Azure api-version query parameter
+	AzureDeployments map[string]string `mapstructure:"azure_deployments"` // Azure deployment name per model, models without an entry are used as deployment names
+
+	// API style: "chat_completions" (default) or "responses" (Responses API)
+	API string `mapstructure:"api"`
+	// Image detail ("low", "high", "auto") per task: screenshot_analysis, desktop_detection, lock_screen_detection
+	ImageDetail map[string]string `mapstructure:"image_detail"`
+
 	// Prompt scene paths (directories, not individual files)
 	ScreenshotPath string `mapstructure:"screenshot_path"` // Path to screenshot analysis prompt scene directory
 	SummaryPath    string `mapstructure:"summary_path"`    // Path to period summary prompt scene directory
@@ -43,6 +93,9 @@ type OpenAIConfig struct {
 	SummaryEnhancedContent      string // Enhanced summary prompt content
 	SummaryContextPrefixContent string // Context prefix prompt content
 	SummaryRollingContent       string // Rolling summary prompt content
+	SummaryCitationContent      string // Citation instruction appended for fifteenmin/hour/work-segment/day summaries
+	SummaryLengthContent        string // Length instruction template appended for levels with a target length
+	SummarySectionsContent      string // Restructures oversized day summaries into themed sections
 
 	// Level-specific summary prompts (loaded from summary_path directory)
 	FifteenminPromptContent string // 15-minute summary prompt content
@@ -58,11 +111,83 @@ type OpenAIConfig struct {
 
 	// Analysis configuration (less frequent, complex task, stronger model)
 	AnalysisModel string `mapstructure:"analysis_model"` // Model for deep behavior analysis
+
+	// Backend configuration (live API, record/replay fixtures, or pure mock)
+	Backend BackendConfig `mapstructure:"backend"`
+
+	// Prompt size guard applied before each call
+	PromptGuard PromptGuardConfig `mapstructure:"prompt_guard"`
+
+	// Proxy and TLS settings for reaching the API (e.g. a corporate AI gateway)
+	Network NetworkConfig `mapstructure:"network"`
+
+	// Retries of failed API calls (analysis, summaries, evaluation) and of whole fifteen-minute summaries
+	Retry RetryConfig `mapstructure:"retry"`
+
+	// Image format sent to the vision model: "original" (default, the file as captured) or "jpeg"
+	// (re-encoded, much smaller than PNG screenshots); formats that cannot be decoded are sent as-is
+	UploadFormat string `mapstructure:"upload_format"`
+
+	// Price per model for the cost report (`stuff-time cost`), models without a price show tokens only
+	Pricing map[string]ModelPricing `mapstructure:"pricing"`
+}
+
+// ModelPricing is the price of a model in USD per million tokens
+type ModelPricing struct {
+	Input       float64 `mapstructure:"input"`        // Prompt tokens
+	CachedInput float64 `mapstructure:"cached_input"` // Prompt tokens served from the provider's prompt cache, 0 uses input
+	Output      float64 `mapstructure:"output"`       // Completion tokens
+}
+
+type BackendConfig struct {
+	Mode         string `mapstructure:"mode"`          // "live" (default), "record", "replay", "mock"
+	FixturesPath string `mapstructure:"fixtures_path"` // Directory for recorded request/response fixtures
+	MockResponse string `mapstructure:"mock_response"` // Fixed response content in mock mode
+}
+
+type PromptGuardConfig struct {
+	MaxPromptTokens int    `mapstructure:"max_prompt_tokens"` // Estimated prompt token limit (keep below the model context), 0 disables the guard
+	Strategy        string `mapstructure:"strategy"`          // "drop_oldest" (default), "summarize" or "reject"
+}
+
+type RetryConfig struct {
+	MaxRetries     int     `mapstructure:"max_retries"`     // Retries after the first attempt, 0 disables retries
+	InitialBackoff string  `mapstructure:"initial_backoff"` // Wait before the first retry (e.g. "2s")
+	Multiplier     float64 `mapstructure:"multiplier"`      // Growth of the wait per retry
+	MaxBackoff     string  `mapstructure:"max_backoff"`     // Upper bound of a single wait, empty or "0" is unbounded
+
+	// Overrides per error class, unset values keep the settings above
+	RateLimit   RetryOverride `mapstructure:"rate_limit"`   // 429 responses
+	ServerError RetryOverride `mapstructure:"server_error"` // 5xx responses
+	Timeout     RetryOverride `mapstructure:"timeout"`      // Request timeouts
+	Network     RetryOverride `mapstructure:"network"`      // Other connection failures
+
+	// Retries of a whole fifteen-minute summary once the retries of its API calls are used up
+	Summary RetryOverride `mapstructure:"summary"`
+}
+
+type RetryOverride struct {
+	MaxRetries     *int    `mapstructure:"max_retries"`     // Unset keeps max_retries
+	InitialBackoff string  `mapstructure:"initial_backoff"` // Empty keeps initial_backoff
+	Multiplier     float64 `mapstructure:"multiplier"`      // 0 keeps multiplier
+}
+
+type NetworkConfig struct {
+	Proxy          string `mapstructure:"proxy"`            // Proxy URL (http://, https:// or socks5://), empty uses HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	CAFile         string `mapstructure:"ca_file"`          // PEM bundle of extra trusted CA certificates (private gateways)
+	ClientCertFile string `mapstructure:"client_cert_file"` // PEM client certificate for mutual TLS
+	ClientKeyFile  string `mapstructure:"client_key_file"`  // PEM private key of the client certificate
+}
+
+// IsOffline returns true if the backend never calls the real API (no API key required)
+func (b *BackendConfig) IsOffline() bool {
+	return b.Mode == "replay" || b.Mode == "mock"
 }
 
 type EvaluatorConfig struct {
 	EvaluationPath  string `mapstructure:"evaluation_path"`  // Path to evaluation prompt scene directory
 	ImprovementPath string `mapstructure:"improvement_path"` // Path to improvement prompt scene directory
+	AdvicePath      string `mapstructure:"advice_path"`      // Path to prompt advice scene directory
 
 	// Evaluation prompt content (loaded from evaluation_path directory)
 	EvaluationPromptContent        string // Evaluation main prompt content
@@ -74,6 +199,20 @@ type EvaluatorConfig struct {
 	// Improvement prompt content (loaded from improvement_path directory)
 	ImprovementPromptContent           string // Improvement main prompt content
 	ImprovementScreenshotSourceContent string // Improvement screenshot source template content
+
+	// Prompt advice meta-analysis prompt content (loaded from advice_path directory)
+	AdvicePromptContent string
+
+	// Language of the improvement section markers and period names: "zh" (default) or "en"
+	Language string `mapstructure:"language"`
+	// Markers overrides the section markers of the language (empty fields keep the built-in ones)
+	Markers EvaluatorMarkersConfig `mapstructure:"markers"`
+}
+
+type EvaluatorMarkersConfig struct {
+	Summary  string `mapstructure:"summary"`  // Improved fact summary, default 【改进后的事实总结】 / [Improved Summary]
+	Analysis string `mapstructure:"analysis"` // Improved suggestions, default 【改进后的改进建议】 / [Improved Analysis]
+	Notes    string `mapstructure:"notes"`    // Improvement notes, default 【改进说明】 / [Improvement Notes]
 }
 
 type PerformanceConfig struct {
@@ -84,6 +223,721 @@ type PerformanceConfig struct {
 	MaxParallelMonths          int `mapstructure:"max_parallel_months"`
 	MaxParallelQuarters        int `mapstructure:"max_parallel_quarters"`
 	MaxParallelTreeAggregation int `mapstructure:"max_parallel_tree_aggregation"`
+
+	// LLM spending limits, see BudgetConfig
+	Budget BudgetConfig `mapstructure:"budget"`
+}
+
+// BudgetConfig LLM 调用预算：当天或当月的 token 用量或费用超出上限后，守护进程暂停批量分析和总结生成，
+// 到下一天（storage.day_start_hour）或下一个月再继续；截图照常进行，积压的截图和总结之后补上
+type BudgetConfig struct {
+	DailyTokens   int     `mapstructure:"daily_tokens"`   // 每天的 token 上限（输入+输出，0表示不限制）
+	MonthlyTokens int     `mapstructure:"monthly_tokens"` // 每月的 token 上限（0表示不限制）
+	DailyUSD      float64 `mapstructure:"daily_usd"`      // 每天的费用上限（美元，按 openai.pricing 计算，0表示不限制）
+	MonthlyUSD    float64 `mapstructure:"monthly_usd"`    // 每月的费用上限（美元，0表示不限制）
+}
+
+// Enabled reports whether any limit is set
+func (c *BudgetConfig) Enabled() bool {
+	return c.DailyTokens > 0 || c.MonthlyTokens > 0 || c.DailyUSD > 0 || c.MonthlyUSD > 0
+}
+
+// ChaosConfig 故障注入配置，仅用于验证重试、队列和断点续传等机制
+type ChaosConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`          // 是否启用故障注入（默认false）
+	APIFailureRate float64  `mapstructure:"api_failure_rate"` // LLM 请求失败概率（0-1）
+	APIFaults      []string `mapstructure:"api_faults"`       // 注入的故障类型："429", "503", "timeout"
+	APITimeout     string   `mapstructure:"api_timeout"`      // 注入超时故障时的阻塞时长
+	DiskSlowRate   float64  `mapstructure:"disk_slow_rate"`   // 慢速写入的概率（0-1）
+	DiskMaxDelay   string   `mapstructure:"disk_max_delay"`   // 慢速写入的最大延迟
+}
+
+// GetAPITimeoutDuration returns the injected timeout duration (default 30s)
+func (c *ChaosConfig) GetAPITimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(c.APITimeout); err == nil && d > 0 {
+		return d
+	}
+	return 30 * time.Second
+}
+
+// GetDiskMaxDelayDuration returns the maximum injected disk delay (default 2s)
+func (c *ChaosConfig) GetDiskMaxDelayDuration() time.Duration {
+	if d, err := time.ParseDuration(c.DiskMaxDelay); err == nil && d > 0 {
+		return d
+	}
+	return 2 * time.Second
+}
+
+// SummaryConfig 总结生成的阈值配置
+type SummaryConfig struct {
+	// ShortSummaryChars: 包含"无工作活动"提示且规范化后短于该字符数的总结视为无效（默认200）
+	ShortSummaryChars int `mapstructure:"short_summary_chars"`
+	// MinRemainingChars: 去除"无工作活动"提示和标点后，剩余内容少于该字符数即视为无效（默认50）
+	MinRemainingChars int `mapstructure:"min_remaining_chars"`
+	// DirectMergeMaxSummaries: 从已聚合层级（工作段、日、周等）生成时，下级总结不超过该数量则直接拼接，不调用 LLM（默认10）
+	DirectMergeMaxSummaries int `mapstructure:"direct_merge_max_summaries"`
+	// MinScreenshotsPerSummary: 周期内有效截图少于该数量时直接标记为空闲，不调用 LLM（默认0，表示不限制）
+	MinScreenshotsPerSummary int `mapstructure:"min_screenshots_per_summary"`
+	// Citations: 小时/日等总结中的每条事实附带引用标记，报告末尾列出对应截图（默认true）
+	Citations bool `mapstructure:"citations"`
+	// Lengths: 各级别总结的目标篇幅（字数），写入提示词，设为0的级别不限制
+	Lengths map[string]int `mapstructure:"lengths"`
+	// TokensPerWord: 输出 token 上限 = 目标字数 × 该系数（默认0：只写入提示词，仍使用 openai.max_completion_tokens）
+	// 目标篇幅只是提示，模型常会超出，上限过紧会截断总结，设置时需留足余量
+	TokensPerWord float64 `mapstructure:"tokens_per_word"`
+	// DedupThreshold: 聚合下级总结前，相似度达到该值的重复行只保留第一次出现（0-1，默认0.85，设为0关闭去重）
+	DedupThreshold float64 `mapstructure:"dedup_threshold"`
+	// SplitDayChars: 日总结超过该字数时，再调用一次 LLM 将其整理为开头带执行摘要的分主题段落，周总结只使用执行摘要（默认0，不拆分）
+	SplitDayChars int `mapstructure:"split_day_chars"`
+	// Stats: 十五分钟/小时/工作时间段/日总结的输入附带由截图精确计算的统计数据（截图数、活跃/空闲时长、活动分布），要求模型只使用这些数字（默认true）
+	Stats bool `mapstructure:"stats"`
+}
+
+// GetShortSummaryChars returns the short summary threshold (default 200)
+func (c *SummaryConfig) GetShortSummaryChars() int {
+	if c.ShortSummaryChars > 0 {
+		return c.ShortSummaryChars
+	}
+	return 200
+}
+
+// GetMinRemainingChars returns the minimum remaining content length (default 50)
+func (c *SummaryConfig) GetMinRemainingChars() int {
+	if c.MinRemainingChars > 0 {
+		return c.MinRemainingChars
+	}
+	return 50
+}
+
+// GetDirectMergeMaxSummaries returns the direct-merge threshold (default 10)
+func (c *SummaryConfig) GetDirectMergeMaxSummaries() int {
+	if c.DirectMergeMaxSummaries > 0 {
+		return c.DirectMergeMaxSummaries
+	}
+	return 10
+}
+
+// AnalysisConfig 行为分析（改进建议）配置
+type AnalysisConfig struct {
+	Enabled bool     `mapstructure:"enabled"` // 是否生成行为分析，关闭后只保留事实记录（默认true）
+	Levels  []string `mapstructure:"levels"`  // 生成行为分析的周期级别（默认 week/month/quarter/year）
+}
+
+// ShouldAnalyze reports whether behavior analysis should be generated for the period type
+func (c *AnalysisConfig) ShouldAnalyze(periodType string) bool {
+	if !c.Enabled {
+		return false
+	}
+	for _, level := range c.Levels {
+		if strings.EqualFold(strings.TrimSpace(level), periodType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectsConfig 仓库/分支识别配置（解析 IDE、终端窗口标题）
+type ProjectsConfig struct {
+	Enabled bool     `mapstructure:"enabled"` // 截图时是否记录前台 IDE/终端窗口并归属到仓库和分支（默认true）
+	Roots   []string `mapstructure:"roots"`   // 存放仓库的目录（如 ~/code），用于根据 IDE 工作区名称找到仓库
+}
+
+// PersonalConfig 24/7 模式配置：工作时间之外也截图，归入单独的"个人"轨道（独立的总结、报告目录和保留期）
+type PersonalConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`        // 是否启用 24/7 模式（默认false，工作时间之外不截图）
+	ReportsPath   string `mapstructure:"reports_path"`   // 个人报告目录（默认 ./data/personal-reports）
+	RetentionDays int    `mapstructure:"retention_days"` // 个人时间截图、总结和报告的保留天数（默认14，0表示不单独清理）
+}
+
+// IsPersonalTime reports whether a capture time belongs to the personal track
+// (24/7 mode enabled and outside work hours)
+func (c *Config) IsPersonalTime(t time.Time) bool {
+	return c.Personal.Enabled && !c.Screenshot.WorkHours.IsWorkTime(t)
+}
+
+// LocalOnlyConfig 仅本地分析配置：指定应用在前台时的截图不发送到云端，只在本地分析
+type LocalOnlyConfig struct {
+	Apps    []string `mapstructure:"apps"`     // 仅本地分析的应用名称（不区分大小写，如 "1Password"、"WeChat"）
+	BaseURL string   `mapstructure:"base_url"` // 本地 OpenAI 兼容模型服务地址（如 Ollama 的 http://localhost:11434/v1），留空则只根据应用和窗口标题生成描述
+	Model   string   `mapstructure:"model"`    // 本地视觉模型（如 llava）
+	APIKey  string   `mapstructure:"api_key"`  // 本地模型服务的 API Key（大多数本地服务不需要）
+}
+
+// IsLocalOnlyApp reports whether screenshots of the app must stay on this machine
+func (c *LocalOnlyConfig) IsLocalOnlyApp(app string) bool {
+	for _, a := range c.Apps {
+		if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(app)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PromptsConfig 提示词存储配置：提示词可以保存在数据库中，带版本历史和激活标记，用 prompt 命令编辑和回滚
+type PromptsConfig struct {
+	Store string `mapstructure:"store"` // "file"（默认，使用提示词目录中的文件）或 "db"（使用数据库中激活的版本，没有版本的提示词仍使用文件）
+}
+
+// UseDB reports whether the active database versions of prompts replace the prompt files
+func (c *PromptsConfig) UseDB() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Store), "db")
+}
+
+// PromptFile is a prompt of a scene directory that can be stored in the database
+type PromptFile struct {
+	Name    string  // "<scene>/<file name without .txt>", e.g. summary/day
+	Content *string // Loaded content, empty when the optional file is missing
+}
+
+// PromptFiles returns the prompts used by capture, analysis and summaries, in a stable order
+// Evaluator prompts (evaluation, improvement, advice) are always read from their files
+func (c *Config) PromptFiles() []PromptFile {
+	prompts := []PromptFile{
+		{"screenshot/screenshot", &c.OpenAI.PromptContent},
+		{"screenshot/desktop-lock-detection", &c.OpenAI.DesktopLockDetectionPromptContent},
+		{"screenshot/lock-screen-detection", &c.OpenAI.LockScreenDetectionPromptContent},
+	}
+	for i := range c.Routing.Rules {
+		if rule := &c.Routing.Rules[i]; rule.Prompt != "" {
+			prompts = append(prompts, PromptFile{"screenshot/" + strings.TrimSuffix(rule.Prompt, ".txt"), &rule.PromptContent})
+		}
+	}
+	return append(prompts,
+		PromptFile{"summary/main", &c.OpenAI.SummaryPromptContent},
+		PromptFile{"summary/enhanced", &c.OpenAI.SummaryEnhancedContent},
+		PromptFile{"summary/citation", &c.OpenAI.SummaryCitationContent},
+		PromptFile{"summary/length", &c.OpenAI.SummaryLengthContent},
+		PromptFile{"summary/sections", &c.OpenAI.SummarySectionsContent},
+		PromptFile{"summary/context-prefix", &c.OpenAI.SummaryContextPrefixContent},
+		PromptFile{"summary/rolling", &c.OpenAI.SummaryRollingContent},
+		PromptFile{"summary/fifteenmin", &c.OpenAI.FifteenminPromptContent},
+		PromptFile{"summary/hour", &c.OpenAI.HourPromptContent},
+		PromptFile{"summary/day", &c.OpenAI.DayPromptContent},
+		PromptFile{"summary/week", &c.OpenAI.WeekPromptContent},
+		PromptFile{"summary/month", &c.OpenAI.MonthPromptContent},
+		PromptFile{"summary/quarter", &c.OpenAI.QuarterPromptContent},
+		PromptFile{"summary/year", &c.OpenAI.YearPromptContent},
+		PromptFile{"analysis/analysis", &c.OpenAI.AnalysisPromptContent},
+		PromptFile{"deliverables/deliverables", &c.Deliverables.PromptContent},
+		PromptFile{"reading/reading", &c.Reading.PromptContent},
+		PromptFile{"meetings/meeting", &c.Meetings.PromptContent},
+		PromptFile{"threads/threads", &c.Threads.PromptContent},
+	)
+}
+
+// PromptFile returns the prompt with the name, nil when there is none
+func (c *Config) PromptFile(name string) *PromptFile {
+	for _, p := range c.PromptFiles() {
+		if p.Name == name {
+			return &p
+		}
+	}
+	return nil
+}
+
+// IdleConfig 输入空闲检测：键盘和鼠标长时间没有操作时（离开但没有锁屏）不截图，或把截图标记为空闲
+type IdleConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`     // 是否启用（默认false，仅 macOS）
+	After      string   `mapstructure:"after"`       // 没有键盘/鼠标输入达到该时长视为空闲（默认5m）
+	Action     string   `mapstructure:"action"`      // "skip"（默认，不截图）或 "tag"（照常截图，标记为空闲且不分析）
+	ExceptApps []string `mapstructure:"except_apps"` // 前台为这些应用时不判定为空闲（如视频会议、播放器），不区分大小写
+}
+
+// GetAfterDuration returns how long without input counts as idle (default 5m)
+func (c *IdleConfig) GetAfterDuration() time.Duration {
+	if d, err := time.ParseDuration(c.After); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Minute
+}
+
+// TagOnly reports whether idle captures are kept and tagged instead of skipped
+func (c *IdleConfig) TagOnly() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Action), "tag")
+}
+
+// IsExceptApp reports whether the app is used without input (calls, videos), so it never counts as idle
+func (c *IdleConfig) IsExceptApp(app string) bool {
+	for _, a := range c.ExceptApps {
+		if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(app)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SpacesConfig macOS 桌面空间（Spaces）配置：记录每次截图时所在的桌面，按桌面统计时间，或不截图某些桌面
+type SpacesConfig struct {
+	Enabled bool              `mapstructure:"enabled"` // 是否记录截图时所在的桌面（默认false）
+	Names   map[string]string `mapstructure:"names"`   // 桌面编号 → 名称（如 "2": personal），时间线和报告中显示名称
+	Exclude []string          `mapstructure:"exclude"` // 不截图的桌面（编号或名称），位于这些桌面时跳过截图
+}
+
+// Label returns the name of a Space, "Space N" when it has none
+func (c *SpacesConfig) Label(space int) string {
+	if name := strings.TrimSpace(c.Names[strconv.Itoa(space)]); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Space %d", space)
+}
+
+// IsExcluded reports whether screenshots of a Space must not be taken
+func (c *SpacesConfig) IsExcluded(space int) bool {
+	for _, e := range c.Exclude {
+		e = strings.TrimSpace(e)
+		if e == strconv.Itoa(space) || strings.EqualFold(e, c.Label(space)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutingConfig 截图分析模型路由：截图时根据前台应用和窗口标题预先分类，不同类型的截图交给不同的模型分析
+type RoutingConfig struct {
+	Rules []RoutingRule `mapstructure:"rules"` // 路由规则，按顺序匹配，第一条匹配的规则生效；都不匹配的截图使用 openai.model
+}
+
+// RoutingRule 一类截图的分析设置
+type RoutingRule struct {
+	Name          string   `mapstructure:"name"`           // 截图类型名称（如 text、design），截图时记录在数据库中
+	Apps          []string `mapstructure:"apps"`           // 前台应用名称（不区分大小写，如 "Terminal"、"Figma"）
+	TitleContains []string `mapstructure:"title_contains"` // 窗口标题包含的关键字（不区分大小写），应用或标题任一匹配即可
+	Model         string   `mapstructure:"model"`          // 分析模型，留空使用 openai.model
+	Prompt        string   `mapstructure:"prompt"`         // 追加到截图分析提示词之后的说明文件（位于 openai.screenshot_path 目录，如 text.txt），留空不追加
+	ImageDetail   string   `mapstructure:"image_detail"`   // 图片精度（low/high/auto），留空使用 openai.image_detail.screenshot_analysis
+
+	PromptContent string // 说明内容（运行时从 prompt 文件加载）
+}
+
+// Match reports whether a screenshot of the app and window title belongs to the rule
+func (r *RoutingRule) Match(app, title string) bool {
+	for _, a := range r.Apps {
+		if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(app)) {
+			return true
+		}
+	}
+	title = strings.ToLower(title)
+	for _, keyword := range r.TitleContains {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" && strings.Contains(title, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Route returns the first rule matching the app and window title, nil if none matches
+func (c *RoutingConfig) Route(app, title string) *RoutingRule {
+	for i := range c.Rules {
+		if c.Rules[i].Match(app, title) {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Rule returns the rule with the given name, nil if there is none (e.g. removed since the capture)
+func (c *RoutingConfig) Rule(name string) *RoutingRule {
+	for i := range c.Rules {
+		if c.Rules[i].Name == name {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// EmbargoWindow 屏蔽时段，每周在指定星期的固定时间段重复
+type EmbargoWindow struct {
+	Days  []string `mapstructure:"days"`  // 星期（mon、tue、wed、thu、fri、sat、sun），为空表示每天
+	Start string   `mapstructure:"start"` // 开始时间 HH:MM
+	End   string   `mapstructure:"end"`   // 结束时间 HH:MM，早于开始时间表示跨越午夜（星期按开始时间计）
+}
+
+var embargoWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Validate checks the days and times of the window
+func (w *EmbargoWindow) Validate() error {
+	for _, day := range w.Days {
+		if _, ok := embargoWeekday(day); !ok {
+			return fmt.Errorf("invalid day '%s' (use mon, tue, wed, thu, fri, sat, sun)", day)
+		}
+	}
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("start and end are both %s", w.Start)
+	}
+	return nil
+}
+
+// Occurrences returns the occurrences of the window that overlap [start, end), clipped to it
+func (w *EmbargoWindow) Occurrences(start, end time.Time) [][2]time.Time {
+	from, err := parseClock(w.Start)
+	if err != nil {
+		return nil
+	}
+	to, err := parseClock(w.End)
+	if err != nil {
+		return nil
+	}
+	length := to - from
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+
+	var occurrences [][2]time.Time
+	// Start a day early so an occurrence crossing midnight into start is found
+	day := time.Date(start.Year(), start.Month(), start.Day()-1, 0, 0, 0, 0, start.Location())
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !w.onDay(day.Weekday()) {
+			continue
+		}
+		occStart := time.Date(day.Year(), day.Month(), day.Day(), int(from/time.Hour), int(from%time.Hour/time.Minute), 0, 0, day.Location())
+		occEnd := occStart.Add(length)
+		if !occEnd.After(start) || !occStart.Before(end) {
+			continue
+		}
+		if occStart.Before(start) {
+			occStart = start
+		}
+		if occEnd.After(end) {
+			occEnd = end
+		}
+		occurrences = append(occurrences, [2]time.Time{occStart, occEnd})
+	}
+	return occurrences
+}
+
+func (w *EmbargoWindow) onDay(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, day := range w.Days {
+		if d, ok := embargoWeekday(day); ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// embargoWeekday parses a day name, full ("Tuesday") or abbreviated ("tue")
+func embargoWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if len(day) < 3 {
+		return 0, false
+	}
+	weekday, ok := embargoWeekdays[day[:3]]
+	return weekday, ok
+}
+
+// parseClock parses HH:MM into the duration since midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s' (use HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsEmbargoed reports whether t falls into an embargo window
+func (c *Config) IsEmbargoed(t time.Time) bool {
+	return len(c.EmbargoOccurrences(t, t.Add(time.Nanosecond))) > 0
+}
+
+// EmbargoOccurrences returns the embargoed time ranges overlapping [start, end), clipped to it, in order
+func (c *Config) EmbargoOccurrences(start, end time.Time) [][2]time.Time {
+	var occurrences [][2]time.Time
+	for i := range c.Embargo {
+		occurrences = append(occurrences, c.Embargo[i].Occurrences(start, end)...)
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i][0].Before(occurrences[j][0]) })
+	return occurrences
+}
+
+// ObjectStoreConfig S3 兼容对象存储配置：截图写入存储桶，本地截图目录作为写穿缓存
+type ObjectStoreConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`           // 是否把截图保存到对象存储（默认false）
+	Endpoint        string `mapstructure:"endpoint"`          // S3 兼容服务地址（如 https://s3.us-east-1.amazonaws.com、http://localhost:9000）
+	Region          string `mapstructure:"region"`            // 区域（默认 us-east-1）
+	Bucket          string `mapstructure:"bucket"`            // 存储桶名称
+	Prefix          string `mapstructure:"prefix"`            // 对象键前缀（默认 screenshots）
+	AccessKeyID     string `mapstructure:"access_key_id"`     // 访问密钥ID，留空时读取 AWS_ACCESS_KEY_ID
+	SecretAccessKey string `mapstructure:"secret_access_key"` // 访问密钥，留空时读取 AWS_SECRET_ACCESS_KEY
+	CacheSizeMB     int    `mapstructure:"cache_size_mb"`     // 本地缓存上限（默认2048MB），超过后删除最早的已上传截图，0 表示不清理
+}
+
+// AgentConfig 远程截图 agent 配置：其他机器上的 agent 把截图和元数据推送到中心 daemon 统一分析
+// 中心 daemon 使用 enabled/listen/token，agent 端使用 server_url/token/name/spool_path
+type AgentConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`    // start 时是否接收远程 agent 的截图（默认false）
+	Listen    string `mapstructure:"listen"`     // 接收地址（默认 127.0.0.1:8788，其他机器访问时需要配置 TLS）
+	TLSCert   string `mapstructure:"tls_cert"`   // TLS 证书文件（PEM），监听非回环地址时必填
+	TLSKey    string `mapstructure:"tls_key"`    // TLS 私钥文件（PEM），监听非回环地址时必填
+	Token     string `mapstructure:"token"`      // 共享令牌，两端必须一致（必填）
+	ServerURL string `mapstructure:"server_url"` // agent 端：中心 daemon 地址（如 https://192.168.1.10:8788，非本机地址必须使用 https）
+	CACert    string `mapstructure:"ca_cert"`    // agent 端：信任的 CA 证书文件（PEM），中心使用自签名证书时填写
+	Name      string `mapstructure:"name"`       // agent 端：机器名称，显示在报告中（默认主机名）
+	SpoolPath string `mapstructure:"spool_path"` // agent 端：上传前的本地缓存目录，上传失败时保留待重试（默认 ./data/agent-spool）
+}
+
+// APIConfig 本地 HTTP API 配置（供仪表盘和时间追踪工具读取数据）
+type APIConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`    // start 时是否同时启动 HTTP API（默认false）
+	Listen    string `mapstructure:"listen"`     // 监听地址（默认 127.0.0.1:8787，仅本机可访问）
+	CacheSize int    `mapstructure:"cache_size"` // 响应缓存条数（默认256）
+	// 只允许本机访问（默认false）：拒绝监听非回环地址，并拒绝来自其他机器的请求
+	LocalhostOnly bool `mapstructure:"localhost_only"`
+	// 额外允许的访问主机名（如 mymac.local）：请求的 Host 只能是监听地址、本机地址或这里列出的名称，防止 DNS 重绑定
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// 访问令牌：配置后每个请求都需要携带令牌（Authorization: Bearer <令牌> 或 ?token=<令牌>），未配置时不校验
+	Tokens []APIToken `mapstructure:"tokens"`
+}
+
+// API token scopes
+const (
+	APIScopeReadReports       = "read-reports"       // Reports, summaries, screenshots, timeline and the dashboard
+	APIScopeTriggerGeneration = "trigger-generation" // POST /generate
+	APIScopeAdmin             = "admin"              // Everything, including pausing and triggering captures
+)
+
+// APIToken HTTP API 访问令牌
+type APIToken struct {
+	Name   string   `mapstructure:"name"`   // 名称，用于日志
+	Token  string   `mapstructure:"token"`  // 令牌值（建议使用足够长的随机字符串，如 openssl rand -hex 24）
+	Scopes []string `mapstructure:"scopes"` // 权限：read-reports、trigger-generation、admin（包含所有权限）
+}
+
+// HasScope reports whether the token grants the scope; admin grants every scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == APIScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// JournalConfig 每日反思配置：journal 命令在一天结束时提出的问题，回答会纳入周总结
+type JournalConfig struct {
+	Questions []string `mapstructure:"questions"` // 反思问题（默认3个）
+}
+
+// TimeSyncConfig Toggl/Clockify 时间条目同步配置：把按仓库识别的工作会话导出为时间条目
+type TimeSyncConfig struct {
+	Provider    string            `mapstructure:"provider"`     // "toggl" 或 "clockify"
+	BaseURL     string            `mapstructure:"base_url"`     // API 地址，留空使用官方地址
+	WorkspaceID string            `mapstructure:"workspace_id"` // 工作区ID
+	APIToken    string            `mapstructure:"api_token"`    // Toggl API Token 或 Clockify API Key
+	Projects    map[string]string `mapstructure:"projects"`     // 本地项目（仓库名，或 仓库名/子项目）→ 追踪工具项目ID
+	MinMinutes  int               `mapstructure:"min_minutes"`  // 短于该时长的会话不同步（默认5）
+	MaxGap      string            `mapstructure:"max_gap"`      // 同一项目的截图间隔超过该时长则拆分会话（默认10m）
+}
+
+// GetMaxGapDuration returns the maximum gap inside a work session (default 10m)
+func (c *TimeSyncConfig) GetMaxGapDuration() time.Duration {
+	if d, err := time.ParseDuration(c.MaxGap); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// DeliverablesConfig 交付成果提取配置
+type DeliverablesConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`     // 生成日总结后是否提取交付成果（默认true）
+	PromptPath string   `mapstructure:"prompt_path"` // 交付成果提取提示词场景目录
+	GitRepos   []string `mapstructure:"git_repos"`   // 可选：从这些本地 git 仓库读取提交记录
+	GitAuthor  string   `mapstructure:"git_author"`  // 可选：只统计该作者的提交（git log --author）
+
+	PromptContent string // Deliverable extraction prompt content
+}
+
+// IssuesConfig 工单关联配置（Jira/Linear）
+type IssuesConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`   // 是否启用工单关联（默认false）
+	Provider string   `mapstructure:"provider"`  // "jira"、"linear"，为空表示只识别工单号、不查询详情
+	BaseURL  string   `mapstructure:"base_url"`  // Jira 站点地址（如 https://company.atlassian.net），Linear 可留空
+	Email    string   `mapstructure:"email"`     // Jira 账号邮箱（Basic Auth），为空时使用 Bearer Token
+	APIToken string   `mapstructure:"api_token"` // Jira API Token 或 Linear API Key
+	Projects []string `mapstructure:"projects"`  // 只识别这些项目前缀的工单号（如 ["PROJ", "ENG"]），为空表示全部
+	CacheTTL string   `mapstructure:"cache_ttl"` // 工单详情缓存时长（默认6h）
+}
+
+// GetCacheTTLDuration returns the issue details cache TTL (default 6h)
+func (c *IssuesConfig) GetCacheTTLDuration() time.Duration {
+	if d, err := time.ParseDuration(c.CacheTTL); err == nil && d > 0 {
+		return d
+	}
+	return 6 * time.Hour
+}
+
+// FocusConfig 专注度评分配置
+type FocusConfig struct {
+	Enabled         bool `mapstructure:"enabled"`           // 生成日总结时计算专注度评分（默认true）
+	DeepWorkMinutes int  `mapstructure:"deep_work_minutes"` // 连续同类活动达到该时长才计为深度工作（默认25分钟）
+	StreakThreshold int  `mapstructure:"streak_threshold"`  // 评分达到该值的连续天数计入连胜（默认60）
+}
+
+// WorkdayConfig 上下班时间识别：以每天第一张和最后一张非空闲截图作为实际的上下班时间，记录并显示在日报、周报中
+type WorkdayConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`        // 是否启用（默认true）
+	UseDetected   bool    `mapstructure:"use_detected"`   // 工作时段切分和加班统计使用识别的上下班时间代替 screenshot.work_hours（默认false）
+	StandardHours float64 `mapstructure:"standard_hours"` // use_detected 时每天的标准工作时长（小时），超出部分计为加班（默认8）
+}
+
+// StandardDuration returns the standard length of a workday with use_detected (default 8h)
+func (c *WorkdayConfig) StandardDuration() time.Duration {
+	if c.StandardHours <= 0 {
+		return 8 * time.Hour
+	}
+	return time.Duration(c.StandardHours * float64(time.Hour))
+}
+
+// BreaksConfig 休息统计配置：识别工作时间内的休息（锁屏、离开等空档），统计休息节奏
+type BreaksConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // 日报告中是否显示休息统计（默认true）
+	MinBreak    string `mapstructure:"min_break"`    // 工作时间内没有工作截图达到该时长才算一次休息（默认5m）
+	NotifyAfter string `mapstructure:"notify_after"` // 连续工作达到该时长时发送系统通知提醒休息（如 90m，默认为空不提醒）
+}
+
+// GetMinBreakDuration returns the minimum gap counted as a break (default 5m)
+func (c *BreaksConfig) GetMinBreakDuration() time.Duration {
+	if d, err := time.ParseDuration(c.MinBreak); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Minute
+}
+
+// GetNotifyAfterDuration returns the continuous work time after which a break reminder is sent,
+// 0 when reminders are disabled
+func (c *BreaksConfig) GetNotifyAfterDuration() time.Duration {
+	if d, err := time.ParseDuration(c.NotifyAfter); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// HealthConfig 自检配置：守护进程每天试拍一帧并检查黑帧检测、磁盘写入、数据库写入和 LLM 连通性，
+// 记录通过/失败结果，连续失败时发送系统通知，避免截图静默中断多日无人察觉
+type HealthConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`     // 是否启用每日自检（默认true）
+	Cron       string `mapstructure:"cron"`        // 自检时间，带秒的 cron 表达式（默认每天 12:00）
+	Ping       bool   `mapstructure:"ping"`        // 是否向 LLM 发送一个极小的请求检查连通性（默认true）
+	AlertAfter int    `mapstructure:"alert_after"` // 连续失败达到该次数时发送系统通知（默认2，0 不通知）
+}
+
+// RemoteAnalysisConfig 远程分析配置：笔记本只截图并排队，性能更好的家用服务器（或容器）运行 worker，
+// 通过 agent 协议拉取待分析队列，完成分析和总结后把结果推送回来
+// 截图端使用 enabled/lease（需同时开启 agent.enabled 并设置 agent.token），worker 端使用 agent.server_url/token/name
+type RemoteAnalysisConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // 截图端：start 时不在本机分析和生成总结，而是把待分析队列提供给 worker（默认false）
+	Lease        string `mapstructure:"lease"`         // 截图端：截图交给 worker 后在该时长内不再交给其他 worker，超时未收到结果时重新排队（默认30m）
+	PullInterval string `mapstructure:"pull_interval"` // worker 端：拉取队列、推送结果的间隔（默认1m）
+	BatchSize    int    `mapstructure:"batch_size"`    // worker 端：每次最多拉取的截图数（默认50）
+}
+
+// GetLeaseDuration returns how long exported screenshots are reserved for a worker (default 30m)
+func (c *RemoteAnalysisConfig) GetLeaseDuration() time.Duration {
+	if d, err := time.ParseDuration(c.Lease); err == nil && d > 0 {
+		return d
+	}
+	return 30 * time.Minute
+}
+
+// GetPullIntervalDuration returns the interval between worker pulls (default 1m)
+func (c *RemoteAnalysisConfig) GetPullIntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(c.PullInterval); err == nil && d > 0 {
+		return d
+	}
+	return time.Minute
+}
+
+// ExportConfig 导出配置：export --redact 在导出的报告中替换的内容
+// 邮箱地址、网址和截图图片始终会被替换或移除
+type ExportConfig struct {
+	RedactTerms    []string `mapstructure:"redact_terms"`    // 需要替换的词（如客户名、同事姓名），不区分大小写
+	RedactPatterns []string `mapstructure:"redact_patterns"` // 需要替换的正则表达式（如工单号 ACME-\d+）
+}
+
+// DesktopLockConfig 桌面/锁屏识别规则配置：按截图分析内容和检测提示词的回答识别桌面、锁屏截图
+type DesktopLockConfig struct {
+	Languages    []string `mapstructure:"languages"`     // 启用的规则语言（默认 ["zh", "en"]），多种语言的规则合并使用
+	PatternsPath string   `mapstructure:"patterns_path"` // 规则文件目录（默认 patterns/desktop-lock），其中的 <语言>.txt 覆盖内置规则；内置规则只有 zh 和 en
+}
+
+// OCRConfig 截图文字识别配置：分析截图时在本机（macOS Vision 框架）识别截图中的文字并建立全文索引，
+// search 可以搜索截图中出现过的文字（如错误码）
+type OCRConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`   // 是否识别截图文字（默认false）
+	Languages []string `mapstructure:"languages"` // 识别语言，按优先级排列（默认 ["zh-Hans", "en-US"]）
+}
+
+// ReadingConfig 阅读模式配置：为浏览器/PDF 阅读较多的日期生成研究日志
+type ReadingConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // 是否启用阅读模式（默认true）
+	PromptPath     string `mapstructure:"prompt_path"`     // 研究日志提示词场景目录
+	MinScreenshots int    `mapstructure:"min_screenshots"` // 当天阅读类截图达到该数量才生成研究日志（默认5）
+
+	PromptContent string // Research log prompt content
+}
+
+// MeetingsConfig 会议纪要配置：识别连续的会议截图并生成会议纪要
+type MeetingsConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`     // 是否启用会议纪要（默认true）
+	PromptPath string `mapstructure:"prompt_path"` // 会议纪要提示词场景目录
+	MinMinutes int    `mapstructure:"min_minutes"` // 连续会议达到该时长才生成纪要（默认10分钟）
+	MaxGap     string `mapstructure:"max_gap"`     // 会议截图之间允许的最大间隔，超过则视为两场会议（默认5m）
+
+	PromptContent string // Meeting note prompt content
+}
+
+// ThreadsConfig 任务线程配置：按分析内容的向量相似度把一天的活动聚成若干任务线程
+type ThreadsConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`         // 生成日总结时是否识别任务线程（默认false，需要 embeddings 接口）
+	EmbeddingModel string  `mapstructure:"embedding_model"` // 向量模型（默认text-embedding-3-small）
+	Similarity     float64 `mapstructure:"similarity"`      // 与线程中心的余弦相似度达到该值才归入该线程（默认0.8）
+	MinMinutes     int     `mapstructure:"min_minutes"`     // 时长不足该值的线程并入"其他"（默认10分钟）
+	PromptPath     string  `mapstructure:"prompt_path"`     // 线程命名提示词场景目录，缺失时用代表性分析内容命名
+	OpenWindow     string  `mapstructure:"open_window"`     // 当天最后这段时间内仍在进行的线程视为未完成（默认1h）
+	Carryover      string  `mapstructure:"carryover"`       // 前一天未完成的线程加入当天开始这段时间内总结的上下文（默认2h，0表示关闭）
+
+	PromptContent string // Thread naming prompt content
+}
+
+// GetOpenWindowDuration returns how close to the end of the day's work a thread must have been
+// active to count as unfinished (default 1h)
+func (c *ThreadsConfig) GetOpenWindowDuration() time.Duration {
+	if d, err := time.ParseDuration(c.OpenWindow); err == nil && d > 0 {
+		return d
+	}
+	return time.Hour
+}
+
+// GetCarryoverDuration returns how long after the first screenshot of a day summaries get the
+// previous day's unfinished threads as context (default 2h, 0 disables the carryover)
+func (c *ThreadsConfig) GetCarryoverDuration() time.Duration {
+	if c.Carryover == "" {
+		return 2 * time.Hour
+	}
+	if d, err := time.ParseDuration(c.Carryover); err == nil && d >= 0 {
+		return d
+	}
+	return 2 * time.Hour
+}
+
+// GetMaxGapDuration returns the maximum gap inside a meeting block (default 5m)
+func (c *MeetingsConfig) GetMaxGapDuration() time.Duration {
+	if d, err := time.ParseDuration(c.MaxGap); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Minute
 }
 
 type ScreenshotConfig struct {
@@ -98,6 +952,100 @@ type ScreenshotConfig struct {
 	WorkHours        WorkHoursConfig `mapstructure:"work_hours"`       // Work hours configuration
 	CleanupInterval  string          `mapstructure:"cleanup_interval"` // Interval for invalid reports cleanup
 	CleanupCron      string          `mapstructure:"cleanup_cron"`     // Cron expression for invalid reports cleanup
+	// Consecutive blank/failed captures before alerting that screen recording permission was lost (0 disables the alert)
+	PermissionAlertAfter int `mapstructure:"permission_alert_after"`
+	// Unanalyzed screenshots analyzed (newest first) right after the daemon starts, 0 disables the catch-up
+	CatchupBudget int `mapstructure:"catchup_budget"`
+	// Upload only the frontmost window region for analysis; the full screenshot stays on disk
+	CropToWindow bool `mapstructure:"crop_to_window"`
+	// Points kept around the window when cropping, so adjacent context (e.g. a side panel) remains visible
+	CropPadding int `mapstructure:"crop_padding"`
+	// Record the frontmost app and window title of every capture, for screen time per app and exports
+	RecordApps bool `mapstructure:"record_apps"`
+	// Store the frontmost app and window title with each screenshot and give them to the analysis model as context
+	WindowContext bool `mapstructure:"window_context"`
+	// Capture intervals by foreground app category, e.g. every 10m while a video player or game is in
+	// front and every 1m in an IDE; the first matching rule wins, other apps are captured every interval
+	AppIntervals []AppIntervalRule `mapstructure:"app_intervals"`
+	// Adapt the number of analysis workers to API latency, rate limits and spending instead of a fixed analysis_workers
+	WorkerScaling WorkerScalingConfig `mapstructure:"worker_scaling"`
+}
+
+// WorkerScalingConfig adapts the analysis worker count before each batch analysis, starting from analysis_workers
+type WorkerScalingConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	MinWorkers    int     `mapstructure:"min_workers"`    // Lower bound of the worker count
+	MaxWorkers    int     `mapstructure:"max_workers"`    // Upper bound of the worker count
+	TargetLatency string  `mapstructure:"target_latency"` // Average analysis latency above which workers are removed (e.g. "20s")
+	MaxErrorRate  float64 `mapstructure:"max_error_rate"` // Share of failed or rate-limited calls above which workers are removed
+}
+
+// GetTargetLatency returns the latency target of worker scaling (default 20s)
+func (c *WorkerScalingConfig) GetTargetLatency() time.Duration {
+	if d, err := time.ParseDuration(c.TargetLatency); err == nil && d > 0 {
+		return d
+	}
+	return 20 * time.Second
+}
+
+// Bounds returns the worker count range, with max raised to min when misconfigured
+func (c *WorkerScalingConfig) Bounds() (lo, hi int) {
+	lo, hi = c.MinWorkers, c.MaxWorkers
+	if lo < 1 {
+		lo = 1
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// AppIntervalRule is the capture interval of a category of foreground apps
+type AppIntervalRule struct {
+	Name     string   `mapstructure:"name"`     // Category name shown in logs (e.g. video, ide)
+	Apps     []string `mapstructure:"apps"`     // Foreground app names (case-insensitive, e.g. "IINA", "GoLand")
+	Interval string   `mapstructure:"interval"` // Capture interval while one of the apps is in front (e.g. "10m")
+}
+
+// MatchAppInterval returns the first rule matching the foreground app, nil when none matches
+func (c *ScreenshotConfig) MatchAppInterval(app string) *AppIntervalRule {
+	for i := range c.AppIntervals {
+		for _, a := range c.AppIntervals[i].Apps {
+			if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(app)) {
+				return &c.AppIntervals[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CaptureInterval returns the minimum time between captures while app is in front
+// 0 means every scheduled capture is taken (cron schedules without a matching rule)
+func (c *ScreenshotConfig) CaptureInterval(app string) time.Duration {
+	if rule := c.MatchAppInterval(app); rule != nil {
+		d, _ := time.ParseDuration(rule.Interval)
+		return d
+	}
+	if c.Cron != "" {
+		return 0
+	}
+	d, _ := c.GetIntervalDuration()
+	return d
+}
+
+// TickInterval returns the period of the fixed-rate capture scheduler: the shortest of interval and
+// the app intervals, so the foreground app can be checked often enough for its own interval
+func (c *ScreenshotConfig) TickInterval() (time.Duration, error) {
+	tick, err := c.GetIntervalDuration()
+	if err != nil {
+		return 0, err
+	}
+	for _, rule := range c.AppIntervals {
+		if d, err := time.ParseDuration(rule.Interval); err == nil && d > 0 && d < tick {
+			tick = d
+		}
+	}
+	return tick, nil
 }
 
 type WorkHoursConfig struct {
@@ -105,6 +1053,16 @@ type WorkHoursConfig struct {
 	StartMinute int `mapstructure:"start_minute"` // Work start minute (0-59)
 	EndHour     int `mapstructure:"end_hour"`     // Work end hour (0-23)
 	EndMinute   int `mapstructure:"end_minute"`   // Work end minute (0-59)
+	// Length of a work-segment summary in minutes (default 120)
+	SegmentMinutes int `mapstructure:"segment_minutes"`
+}
+
+// SegmentDuration returns the length of a work-segment, 2 hours when not configured
+func (w *WorkHoursConfig) SegmentDuration() time.Duration {
+	if w.SegmentMinutes <= 0 {
+		return 2 * time.Hour
+	}
+	return time.Duration(w.SegmentMinutes) * time.Minute
 }
 
 // IsWorkTime checks if the given time is within work hours
@@ -139,15 +1097,72 @@ type StorageConfig struct {
 	ReportsPath   string    `mapstructure:"reports_path"`
 	Log           LogConfig `mapstructure:"log"`
 
+	// 权限配置
+	StrictPermissions bool `mapstructure:"strict_permissions"` // 数据目录仅当前用户可访问（umask 077，启动时检查权限和属主，默认true）
+
 	// 主观周期配置
 	HourSegments    int    `mapstructure:"hour_segments"`     // 小时内分段数（默认4，即15分钟一段）
 	DayWorkSegments int    `mapstructure:"day_work_segments"` // 日内工作段数（默认0，表示不使用工作段）
 	MonthWeeks      string `mapstructure:"month_weeks"`       // 月内周数计算方式（默认"calendar"，可选"fixed"）
 	YearQuarters    int    `mapstructure:"year_quarters"`     // 年内季度数（默认4）
+	DayStartHour    int    `mapstructure:"day_start_hour"`    // 一天开始的小时（默认0；设为3时凌晨0-3点归入前一天，日/周/月边界、周期键和报告路径随之偏移）
 
 	// 结构配置
 	EnableNestedStructure bool `mapstructure:"enable_nested_structure"` // 启用层级嵌套结构（默认true）
 	BackwardCompatible    bool `mapstructure:"backward_compatible"`     // 向后兼容模式（默认true，迁移完成后可设为false）
+
+	// 报告格式配置
+	Markdown MarkdownConfig `mapstructure:"markdown"`
+
+	// 手动修改过的报告重新生成时的处理方式（默认"preserve"：保留 <!-- user --> 标记的段落，其他修改时拒绝覆盖；"overwrite"：直接覆盖）
+	ManualEdits string `mapstructure:"manual_edits"`
+
+	// 报告目录监控：发现手动删除或移动的报告时在数据库中标记为报告缺失
+	ReportWatchdog ReportWatchdogConfig `mapstructure:"report_watchdog"`
+
+	// 报告压缩：fifteenmin/hour 报告所在月份结束超过该月数后，守护进程每天将其压缩为每月一个归档文件（默认0，不自动压缩；也可手动运行 compact 命令）
+	CompactAfterMonths int `mapstructure:"compact_after_months"`
+}
+
+// ReportWatchdogConfig 报告目录监控配置，避免手动整理文件夹后数据库和报告文件不一致
+type ReportWatchdogConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`    // 启用监控（默认true，仅在 start 守护进程中运行）
+	Interval   string `mapstructure:"interval"`   // 扫描间隔（默认"1m"）
+	Regenerate bool   `mapstructure:"regenerate"` // 用数据库中的内容重新写出缺失的报告（默认false，只标记；不调用模型）
+}
+
+// MarkdownConfig 报告 Markdown 格式配置，便于把报告嵌入自带 H1 的文档系统（mkdocs、Hugo）
+// 零值即默认格式
+type MarkdownConfig struct {
+	HeadingLevel        int  `mapstructure:"heading_level"`         // 报告标题使用的级别（默认1，设为2时所有标题下沉一级，最多到 H6）
+	OmitHorizontalRules bool `mapstructure:"omit_horizontal_rules"` // 不输出分隔线 ---（默认false）
+	OmitMetadata        bool `mapstructure:"omit_metadata"`         // 不输出标题下的元数据块（时间、截图ID等）和报告生成时间（默认false）
+	// 脱敏：遮盖家目录下的绝对路径、内网主机名和 IP 地址（默认false），便于把报告同步到云笔记
+	Redact         bool     `mapstructure:"redact"`
+	RedactPatterns []string `mapstructure:"redact_patterns"` // 额外需要遮盖的正则表达式（如内部域名 \.acme\.io），启用 redact 时生效
+}
+
+// IsDefault reports whether reports are written in the default format
+func (c *MarkdownConfig) IsDefault() bool {
+	return c.HeadingLevel <= 1 && !c.OmitHorizontalRules && !c.OmitMetadata && !c.Redact
+}
+
+// validateRedactPatterns checks that the extra redaction patterns compile
+func (c *MarkdownConfig) validateRedactPatterns() error {
+	for _, pattern := range c.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid storage.markdown.redact_patterns entry %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// LocaleConfig 日期时间显示格式，用于报告、命令行输出和导出（周期键、文件路径和 JSON 保持固定格式）
+type LocaleConfig struct {
+	Language   string `mapstructure:"language"`    // 星期和上午/下午的语言：zh（默认）或 en
+	DateFormat string `mapstructure:"date_format"` // 日期格式，Go 时间布局（默认 2006-01-02，如 01/02/2006、2006年1月2日）
+	Clock      string `mapstructure:"clock"`       // 24h（默认）或 12h
+	Weekday    bool   `mapstructure:"weekday"`     // 日期后显示星期（默认false）
 }
 
 type LogConfig struct {
@@ -157,6 +1172,8 @@ type LogConfig struct {
 	MaxBackups   int    `mapstructure:"max_backups"`   // Maximum number of old log files to retain
 	MaxAge       int    `mapstructure:"max_age"`       // Maximum number of days to retain old log files
 	Compress     bool   `mapstructure:"compress"`      // Whether to compress rotated log files
+	SampleBurst  int    `mapstructure:"sample_burst"`  // Maximum repetitive lines (progress, retries) per key within sample_window, 0 disables sampling
+	SampleWindow string `mapstructure:"sample_window"` // Sampling window (e.g., "1m")
 }
 
 // Validate 验证存储配置的有效性
@@ -185,6 +1202,11 @@ func (c *StorageConfig) Validate() error {
 		return fmt.Errorf("year_quarters must divide 12 evenly, got %d", c.YearQuarters)
 	}
 
+	// 验证 DayStartHour：0-23
+	if c.DayStartHour < 0 || c.DayStartHour > 23 {
+		return fmt.Errorf("day_start_hour must be between 0 and 23, got %d", c.DayStartHour)
+	}
+
 	// 验证 MonthWeeks：必须为 "calendar" 或 "fixed"
 	if c.MonthWeeks != "calendar" && c.MonthWeeks != "fixed" {
 		return fmt.Errorf("month_weeks must be 'calendar' or 'fixed', got '%s'", c.MonthWeeks)
@@ -193,6 +1215,25 @@ func (c *StorageConfig) Validate() error {
 	return nil
 }
 
+// LogicalDate 返回 t 所属的日期：早于 day_start_hour 的时间归入前一天
+// 只有返回值的年月日有意义，用于周期键、周/月/季度归属和报告路径
+func (c *StorageConfig) LogicalDate(t time.Time) time.Time {
+	if t.Hour() < c.DayStartHour {
+		return time.Date(t.Year(), t.Month(), t.Day()-1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	return t
+}
+
+// DateStart 返回以 date 的年月日命名的那一天的开始时间（当天 day_start_hour 点）
+func (c *StorageConfig) DateStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), c.DayStartHour, 0, 0, 0, date.Location())
+}
+
+// DayStart 返回 t 所在的那一天的开始时间
+func (c *StorageConfig) DayStart(t time.Time) time.Time {
+	return c.DateStart(c.LogicalDate(t))
+}
+
 // ApplyDefaults 应用默认配置值
 func (c *StorageConfig) ApplyDefaults() {
 	if c.HourSegments == 0 {
@@ -251,9 +1292,30 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("openai.analysis_model", "gpt-4o")
 	viper.SetDefault("openai.analysis_path", "prompts/analysis")
 
+	// LLM backend configuration
+	viper.SetDefault("openai.provider", "openai")
+	viper.SetDefault("openai.api_version", "2024-06-01")
+	viper.SetDefault("openai.api", "chat_completions")
+	viper.SetDefault("openai.prompt_guard.max_prompt_tokens", 100000)
+	viper.SetDefault("openai.prompt_guard.strategy", "drop_oldest")
+	viper.SetDefault("openai.backend.mode", "live")
+	viper.SetDefault("openai.upload_format", "original")
+	viper.SetDefault("openai.backend.fixtures_path", "./testdata/fixtures")
+	viper.SetDefault("openai.retry.max_retries", 5)
+	viper.SetDefault("openai.retry.initial_backoff", "2s")
+	viper.SetDefault("openai.retry.multiplier", 2)
+	viper.SetDefault("openai.retry.max_backoff", "2m")
+	viper.SetDefault("openai.retry.rate_limit.initial_backoff", "6s") // Rate limits wait three times longer
+	viper.SetDefault("openai.retry.timeout.initial_backoff", "4s")
+	viper.SetDefault("openai.retry.network.initial_backoff", "4s")
+	viper.SetDefault("openai.retry.summary.max_retries", 2)
+	viper.SetDefault("openai.retry.summary.initial_backoff", "30s")
+
 	// Evaluator configuration
 	viper.SetDefault("evaluator.evaluation_path", "prompts/evaluation")
 	viper.SetDefault("evaluator.improvement_path", "prompts/improvement")
+	viper.SetDefault("evaluator.advice_path", "prompts/advice")
+	viper.SetDefault("evaluator.language", "zh")
 	viper.SetDefault("screenshot.interval", "1m")
 	viper.SetDefault("screenshot.storage_path", "./data/screenshots")
 	viper.SetDefault("screenshot.image_format", "png")
@@ -264,8 +1326,24 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("screenshot.work_hours.start_minute", 30)
 	viper.SetDefault("screenshot.work_hours.end_hour", 20)
 	viper.SetDefault("screenshot.work_hours.end_minute", 0)
+	viper.SetDefault("screenshot.work_hours.segment_minutes", 120)
 	viper.SetDefault("screenshot.cleanup_interval", "24h") // Default: cleanup once per day
 	viper.SetDefault("screenshot.cleanup_cron", "")        // Default: use interval instead of cron
+	viper.SetDefault("screenshot.permission_alert_after", 3)
+	viper.SetDefault("screenshot.catchup_budget", 200)
+	viper.SetDefault("screenshot.crop_to_window", false)
+	viper.SetDefault("screenshot.crop_padding", 40)
+	viper.SetDefault("screenshot.record_apps", false)
+	viper.SetDefault("screenshot.window_context", true)
+	viper.SetDefault("screenshot.worker_scaling.enabled", false)
+	viper.SetDefault("screenshot.worker_scaling.min_workers", 1)
+	viper.SetDefault("screenshot.worker_scaling.max_workers", 8)
+	viper.SetDefault("screenshot.worker_scaling.target_latency", "20s")
+	viper.SetDefault("screenshot.worker_scaling.max_error_rate", 0.1)
+	viper.SetDefault("performance.budget.daily_tokens", 0)
+	viper.SetDefault("performance.budget.monthly_tokens", 0)
+	viper.SetDefault("performance.budget.daily_usd", 0)
+	viper.SetDefault("performance.budget.monthly_usd", 0)
 	viper.SetDefault("storage.db_path", "./data/db/stuff-time.db")
 	viper.SetDefault("storage.reports_path", "./data/reports")
 	viper.SetDefault("storage.retention_days", 30)
@@ -276,15 +1354,171 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("storage.log.max_backups", 3)      // Keep 3 old log files
 	viper.SetDefault("storage.log.max_age", 28)         // Keep logs for 28 days
 	viper.SetDefault("storage.log.compress", true)      // Compress rotated logs
+	viper.SetDefault("storage.log.sample_burst", 5)     // At most 5 similar progress/retry lines...
+	viper.SetDefault("storage.log.sample_window", "1m") // ...per minute
+	viper.SetDefault("storage.strict_permissions", true)
+
+	// 故障注入默认关闭
+	viper.SetDefault("chaos.enabled", false)
+	viper.SetDefault("chaos.api_failure_rate", 0.2)
+	viper.SetDefault("chaos.api_faults", []string{"429", "503", "timeout"})
+	viper.SetDefault("chaos.api_timeout", "30s")
+	viper.SetDefault("chaos.disk_slow_rate", 0.1)
+	viper.SetDefault("chaos.disk_max_delay", "2s")
+
+	// 总结阈值默认值
+	viper.SetDefault("summary.short_summary_chars", 200)
+	viper.SetDefault("summary.min_remaining_chars", 50)
+	viper.SetDefault("summary.direct_merge_max_summaries", 10)
+	viper.SetDefault("summary.min_screenshots_per_summary", 0)
+	viper.SetDefault("summary.citations", true)
+	viper.SetDefault("summary.lengths", map[string]int{
+		"fifteenmin":   80,
+		"hour":         150,
+		"work-segment": 300,
+		"day":          500,
+		"week":         800,
+		"month":        1500,
+		"quarter":      2000,
+		"year":         3000,
+	})
+	viper.SetDefault("summary.tokens_per_word", 0)
+	viper.SetDefault("summary.dedup_threshold", 0.85)
+	viper.SetDefault("summary.split_day_chars", 0)
+	viper.SetDefault("summary.stats", true)
+
+	// 交付成果提取默认值
+	viper.SetDefault("deliverables.enabled", true)
+	viper.SetDefault("deliverables.prompt_path", "prompts/deliverables")
+
+	// 工单关联默认关闭
+	viper.SetDefault("issues.enabled", false)
+	viper.SetDefault("issues.cache_ttl", "6h")
+
+	// 专注度评分默认值
+	viper.SetDefault("focus.enabled", true)
+	viper.SetDefault("focus.deep_work_minutes", 25)
+	viper.SetDefault("focus.streak_threshold", 60)
+
+	// 休息统计默认值：默认不发送休息提醒
+	viper.SetDefault("breaks.enabled", true)
+	viper.SetDefault("breaks.min_break", "5m")
+	viper.SetDefault("breaks.notify_after", "")
+
+	// 阅读模式默认值
+	viper.SetDefault("reading.enabled", true)
+	viper.SetDefault("reading.prompt_path", "prompts/reading")
+	viper.SetDefault("reading.min_screenshots", 5)
+
+	// 会议纪要默认值
+	viper.SetDefault("meetings.enabled", true)
+	viper.SetDefault("meetings.prompt_path", "prompts/meeting")
+	viper.SetDefault("meetings.min_minutes", 10)
+	viper.SetDefault("meetings.max_gap", "5m")
+
+	// 任务线程默认关闭
+	viper.SetDefault("threads.enabled", false)
+	viper.SetDefault("threads.embedding_model", "text-embedding-3-small")
+	viper.SetDefault("threads.similarity", 0.8)
+	viper.SetDefault("threads.min_minutes", 10)
+	viper.SetDefault("threads.prompt_path", "prompts/threads")
+	viper.SetDefault("threads.open_window", "1h")
+	viper.SetDefault("threads.carryover", "2h")
+
+	// 行为分析默认值：仅周及以上级别生成改进建议
+	viper.SetDefault("analysis.enabled", true)
+	viper.SetDefault("analysis.levels", []string{"week", "month", "quarter", "year"})
+
+	// 仓库/分支识别默认值
+	viper.SetDefault("projects.enabled", true)
+
+	// 本地 HTTP API 默认值
+	viper.SetDefault("api.enabled", false)
+	viper.SetDefault("api.listen", "127.0.0.1:8787")
+	viper.SetDefault("api.cache_size", 256)
+	viper.SetDefault("api.localhost_only", false)
+
+	// 时间条目同步默认值
+	viper.SetDefault("timesync.min_minutes", 5)
+	viper.SetDefault("timesync.max_gap", "10m")
+
+	// 每日反思默认值
+	viper.SetDefault("journal.questions", []string{
+		"今天最有成就感的一件事是什么？",
+		"今天遇到的最大阻碍是什么？它是怎么产生的？",
+		"明天最想改变或继续保持的一点是什么？",
+	})
+
+	// 24/7 模式默认值
+	viper.SetDefault("personal.enabled", false)
+	viper.SetDefault("personal.reports_path", "./data/personal-reports")
+	viper.SetDefault("personal.retention_days", 14)
+	viper.SetDefault("spaces.enabled", false)
+	viper.SetDefault("idle.enabled", false)
+	viper.SetDefault("idle.after", "5m")
+	viper.SetDefault("idle.action", "skip")
+	viper.SetDefault("prompts.store", "file")
+	viper.SetDefault("workday.enabled", true)
+	viper.SetDefault("workday.use_detected", false)
+	viper.SetDefault("workday.standard_hours", 8)
+
+	// 对象存储默认值
+	viper.SetDefault("object_store.enabled", false)
+	viper.SetDefault("object_store.region", "us-east-1")
+	viper.SetDefault("object_store.prefix", "screenshots")
+	viper.SetDefault("object_store.cache_size_mb", 2048)
+
+	// 远程截图 agent 默认值
+	viper.SetDefault("agent.enabled", false)
+	viper.SetDefault("agent.listen", "127.0.0.1:8788")
+	viper.SetDefault("agent.spool_path", "./data/agent-spool")
 
 	// 主观周期配置默认值
 	viper.SetDefault("storage.hour_segments", 4)              // 默认4段，即15分钟一段
 	viper.SetDefault("storage.day_work_segments", 0)          // 默认不使用工作段
 	viper.SetDefault("storage.month_weeks", "calendar")       // 默认使用日历周
 	viper.SetDefault("storage.year_quarters", 4)              // 默认4个季度
+	viper.SetDefault("storage.day_start_hour", 0)             // 默认午夜开始新的一天
 	viper.SetDefault("storage.enable_nested_structure", true) // 默认启用层级嵌套结构
 	viper.SetDefault("storage.backward_compatible", true)     // 默认启用向后兼容模式
 
+	// 报告格式默认值
+	viper.SetDefault("storage.markdown.heading_level", 1)
+	viper.SetDefault("storage.markdown.omit_horizontal_rules", false)
+	viper.SetDefault("storage.markdown.omit_metadata", false)
+	viper.SetDefault("storage.markdown.redact", false)
+	viper.SetDefault("storage.manual_edits", "preserve")
+	viper.SetDefault("storage.report_watchdog.enabled", true)
+	viper.SetDefault("storage.report_watchdog.interval", "1m")
+	viper.SetDefault("storage.report_watchdog.regenerate", false)
+	viper.SetDefault("storage.compact_after_months", 0)
+
+	// 日期时间格式默认值
+	viper.SetDefault("locale.language", "zh")
+	viper.SetDefault("locale.date_format", "2006-01-02")
+	viper.SetDefault("locale.clock", "24h")
+	viper.SetDefault("locale.weekday", false)
+
+	// 自检默认值：每天中午自检一次，连续两次失败时通知
+	viper.SetDefault("health.enabled", true)
+	viper.SetDefault("health.cron", "0 0 12 * * *")
+	viper.SetDefault("health.ping", true)
+	viper.SetDefault("health.alert_after", 2)
+
+	// 桌面/锁屏识别规则默认值
+	viper.SetDefault("desktop_lock.languages", []string{"zh", "en"})
+	viper.SetDefault("desktop_lock.patterns_path", "patterns/desktop-lock")
+
+	// 截图文字识别默认值
+	viper.SetDefault("ocr.enabled", false)
+	viper.SetDefault("ocr.languages", []string{"zh-Hans", "en-US"})
+
+	// 远程分析默认值
+	viper.SetDefault("remote_analysis.enabled", false)
+	viper.SetDefault("remote_analysis.lease", "30m")
+	viper.SetDefault("remote_analysis.pull_interval", "1m")
+	viper.SetDefault("remote_analysis.batch_size", 50)
+
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -296,9 +1530,18 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.OpenAI.APIKey == "" && cfg.OpenAI.Provider == "azure" {
+		cfg.OpenAI.APIKey = os.Getenv("AZURE_OPENAI_API_KEY")
+	}
 	if cfg.OpenAI.APIKey == "" {
 		cfg.OpenAI.APIKey = os.Getenv("OPENAI_API_KEY")
 	}
+	if cfg.ObjectStore.AccessKeyID == "" {
+		cfg.ObjectStore.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.ObjectStore.SecretAccessKey == "" {
+		cfg.ObjectStore.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
 
 	// 应用存储配置默认值
 	cfg.Storage.ApplyDefaults()
@@ -312,13 +1555,93 @@ func Load(configPath string) (*Config, error) {
 		cfg.Storage.DayWorkSegments = 0
 		cfg.Storage.MonthWeeks = "calendar"
 		cfg.Storage.YearQuarters = 4
+		cfg.Storage.DayStartHour = 0
 		cfg.Storage.EnableNestedStructure = true
 		cfg.Storage.BackwardCompatible = true
 	}
 
+	if cfg.OpenAI.UploadFormat != "original" && cfg.OpenAI.UploadFormat != "jpeg" {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid openai.upload_format '%s', must be 'original' or 'jpeg'. Using 'original'.\n", cfg.OpenAI.UploadFormat)
+		cfg.OpenAI.UploadFormat = "original"
+	}
+
+	if cfg.Storage.ManualEdits != "preserve" && cfg.Storage.ManualEdits != "overwrite" {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid storage.manual_edits '%s', must be 'preserve' or 'overwrite'. Using 'preserve'.\n", cfg.Storage.ManualEdits)
+		cfg.Storage.ManualEdits = "preserve"
+	}
+
+	// 没有名称或名称重复的路由规则不生效
+	routing := RoutingConfig{}
+	for _, rule := range cfg.Routing.Rules {
+		if rule.Name == "" || routing.Rule(rule.Name) != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Ignoring routing rule without a unique name: %q\n", rule.Name)
+			continue
+		}
+		routing.Rules = append(routing.Rules, rule)
+	}
+	cfg.Routing = routing
+
+	// 没有应用或间隔无效的截图间隔规则不生效
+	appIntervals := cfg.Screenshot.AppIntervals[:0]
+	for _, rule := range cfg.Screenshot.AppIntervals {
+		if d, err := time.ParseDuration(rule.Interval); err != nil || d <= 0 || len(rule.Apps) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: Ignoring screenshot.app_intervals rule %q: needs apps and a positive interval\n", rule.Name)
+			continue
+		}
+		appIntervals = append(appIntervals, rule)
+	}
+	cfg.Screenshot.AppIntervals = appIntervals
+
+	// 未知的令牌权限不生效；令牌为空的条目无法匹配任何请求，但仍然要求所有请求携带令牌
+	for i := range cfg.API.Tokens {
+		token := &cfg.API.Tokens[i]
+		if token.Token == "" {
+			fmt.Fprintf(os.Stderr, "Warning: api.tokens entry %q has no token and never matches\n", token.Name)
+		}
+		scopes := token.Scopes[:0]
+		for _, scope := range token.Scopes {
+			if scope != APIScopeReadReports && scope != APIScopeTriggerGeneration && scope != APIScopeAdmin {
+				fmt.Fprintf(os.Stderr, "Warning: Ignoring unknown scope %q of api.tokens entry %q\n", scope, token.Name)
+				continue
+			}
+			scopes = append(scopes, scope)
+		}
+		token.Scopes = scopes
+	}
+
+	// 无效的屏蔽时段不生效
+	embargo := cfg.Embargo[:0]
+	for _, w := range cfg.Embargo {
+		if err := w.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Ignoring embargo window %s-%s: %v\n", w.Start, w.End, err)
+			continue
+		}
+		embargo = append(embargo, w)
+	}
+	cfg.Embargo = embargo
+
+	if d, err := cfg.Storage.ReportWatchdog.GetIntervalDuration(); err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid storage.report_watchdog.interval '%s'. Using '1m'.\n", cfg.Storage.ReportWatchdog.Interval)
+		cfg.Storage.ReportWatchdog.Interval = "1m"
+	}
+
+	// 应用日期时间格式（无效时保留默认格式）
+	if err := datefmt.Set(datefmt.Options{
+		Language:   cfg.Locale.Language,
+		DateFormat: cfg.Locale.DateFormat,
+		Clock:      cfg.Locale.Clock,
+		Weekday:    cfg.Locale.Weekday,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Invalid locale configuration: %v. Using default values.\n", err)
+		datefmt.Set(datefmt.Options{})
+	}
+
 	if err := normalizePaths(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to normalize paths: %w", err)
 	}
+	if err := cfg.Storage.Markdown.validateRedactPatterns(); err != nil {
+		return nil, err
+	}
 
 	// Load prompt files
 	configFileDir := ""
@@ -342,6 +1665,9 @@ func Load(configPath string) (*Config, error) {
 	if err := loadPromptFiles(&cfg, configFileDir); err != nil {
 		return nil, fmt.Errorf("failed to load prompt files: %w", err)
 	}
+	if err := loadDesktopLockPatterns(&cfg.DesktopLock, configFileDir); err != nil {
+		return nil, fmt.Errorf("failed to load desktop/lock patterns: %w", err)
+	}
 
 	globalConfig = &cfg
 	return &cfg, nil
@@ -375,6 +1701,13 @@ func (c *ScreenshotConfig) GetCleanupIntervalDuration() (time.Duration, error) {
 	return time.ParseDuration(c.CleanupInterval)
 }
 
+func (c *ReportWatchdogConfig) GetIntervalDuration() (time.Duration, error) {
+	if c.Interval == "" {
+		return 0, fmt.Errorf("report watchdog interval not configured")
+	}
+	return time.ParseDuration(c.Interval)
+}
+
 func (c *ScreenshotConfig) EnsureStoragePath() error {
 	return os.MkdirAll(c.StoragePath, 0755)
 }
@@ -394,6 +1727,22 @@ func (c *StorageConfig) EnsureReportsPath() error {
 	return nil
 }
 
+// DataPaths returns the paths holding captured data: screenshot directory, reports directory,
+// database file (with its journal files) and log file
+func (c *Config) DataPaths() []string {
+	paths := []string{c.Screenshot.StoragePath, c.Storage.ReportsPath}
+	if c.Personal.Enabled && c.Personal.ReportsPath != "" {
+		paths = append(paths, c.Personal.ReportsPath)
+	}
+	if c.Agent.ServerURL != "" && c.Agent.SpoolPath != "" {
+		paths = append(paths, c.Agent.SpoolPath)
+	}
+	if c.Storage.DBPath != "" {
+		paths = append(paths, c.Storage.DBPath, c.Storage.DBPath+"-wal", c.Storage.DBPath+"-shm", c.Storage.DBPath+"-journal")
+	}
+	return append(paths, c.Storage.LogPath)
+}
+
 func normalizePaths(cfg *Config) error {
 	// Use executable directory as base for relative paths, fallback to working directory
 	baseDir, err := getBaseDirectory()
@@ -441,6 +1790,18 @@ func normalizePaths(cfg *Config) error {
 		cfg.Storage.ReportsPath = filepath.Join(baseDir, cfg.Storage.ReportsPath)
 	}
 
+	if cfg.Personal.ReportsPath != "" && !filepath.IsAbs(cfg.Personal.ReportsPath) {
+		cfg.Personal.ReportsPath = filepath.Join(baseDir, cfg.Personal.ReportsPath)
+	}
+
+	if cfg.Agent.SpoolPath != "" && !filepath.IsAbs(cfg.Agent.SpoolPath) {
+		cfg.Agent.SpoolPath = filepath.Join(baseDir, cfg.Agent.SpoolPath)
+	}
+
+	if cfg.OpenAI.Backend.FixturesPath != "" && !filepath.IsAbs(cfg.OpenAI.Backend.FixturesPath) {
+		cfg.OpenAI.Backend.FixturesPath = filepath.Join(baseDir, cfg.OpenAI.Backend.FixturesPath)
+	}
+
 	// If log level is not set, use default
 	if cfg.Storage.Log.Level == "" {
 		cfg.Storage.Log.Level = "info"
@@ -511,6 +1872,8 @@ func initLogger(storage *StorageConfig) error {
 		MaxBackups:   storage.Log.MaxBackups,
 		MaxAge:       storage.Log.MaxAge,
 		Compress:     storage.Log.Compress,
+		SampleBurst:  storage.Log.SampleBurst,
+		SampleWindow: storage.Log.SampleWindow,
 	})
 }
 
@@ -551,6 +1914,21 @@ func loadPromptFiles(cfg *Config, configFileDir string) error {
 			cfg.OpenAI.SummaryEnhancedContent = enhanced
 		}
 
+		// Citation instruction (optional)
+		if citation, err := loadPromptFromScene(cfg.OpenAI.SummaryPath, "citation.txt", configFileDir); err == nil {
+			cfg.OpenAI.SummaryCitationContent = citation
+		}
+
+		// Length instruction template (optional)
+		if length, err := loadPromptFromScene(cfg.OpenAI.SummaryPath, "length.txt", configFileDir); err == nil {
+			cfg.OpenAI.SummaryLengthContent = length
+		}
+
+		// Themed sections prompt for oversized day summaries (optional, they are kept as is without it)
+		if sections, err := loadPromptFromScene(cfg.OpenAI.SummaryPath, "sections.txt", configFileDir); err == nil {
+			cfg.OpenAI.SummarySectionsContent = sections
+		}
+
 		// Context prefix prompt (optional)
 		if prefix, err := loadPromptFromScene(cfg.OpenAI.SummaryPath, "context-prefix.txt", configFileDir); err == nil {
 			cfg.OpenAI.SummaryContextPrefixContent = prefix
@@ -594,6 +1972,47 @@ func loadPromptFiles(cfg *Config, configFileDir string) error {
 		cfg.OpenAI.AnalysisPromptContent = content
 	}
 
+	// Load deliverable extraction prompt (optional, extraction is skipped without it)
+	if cfg.Deliverables.PromptPath != "" {
+		if content, err := loadPromptFromScene(cfg.Deliverables.PromptPath, "deliverables.txt", configFileDir); err == nil {
+			cfg.Deliverables.PromptContent = content
+		}
+	}
+
+	// Load research log prompt (optional, reading mode is skipped without it)
+	if cfg.Reading.PromptPath != "" {
+		if content, err := loadPromptFromScene(cfg.Reading.PromptPath, "reading.txt", configFileDir); err == nil {
+			cfg.Reading.PromptContent = content
+		}
+	}
+
+	// Load meeting note prompt (optional, meeting notes are skipped without it)
+	if cfg.Meetings.PromptPath != "" {
+		if content, err := loadPromptFromScene(cfg.Meetings.PromptPath, "meeting.txt", configFileDir); err == nil {
+			cfg.Meetings.PromptContent = content
+		}
+	}
+
+	// Load thread naming prompt (optional, threads are named after their analyses without it)
+	if cfg.Threads.PromptPath != "" {
+		if content, err := loadPromptFromScene(cfg.Threads.PromptPath, "threads.txt", configFileDir); err == nil {
+			cfg.Threads.PromptContent = content
+		}
+	}
+
+	// Load the extra instructions of screenshot routing rules
+	for i := range cfg.Routing.Rules {
+		rule := &cfg.Routing.Rules[i]
+		if rule.Prompt == "" {
+			continue
+		}
+		content, err := loadPromptFromScene(cfg.OpenAI.ScreenshotPath, rule.Prompt, configFileDir)
+		if err != nil {
+			return fmt.Errorf("failed to load prompt of routing rule %s: %w", rule.Name, err)
+		}
+		rule.PromptContent = content
+	}
+
 	// Load evaluation prompts from evaluation scene directory
 	if cfg.Evaluator.EvaluationPath != "" {
 		// Main evaluation prompt
@@ -639,6 +2058,41 @@ func loadPromptFiles(cfg *Config, configFileDir string) error {
 		}
 	}
 
+	// Load prompt advice prompt (optional, required by prompt-advice only)
+	if cfg.Evaluator.AdvicePath != "" {
+		if content, err := loadPromptFromScene(cfg.Evaluator.AdvicePath, "advice.txt", configFileDir); err == nil {
+			cfg.Evaluator.AdvicePromptContent = content
+		}
+	}
+
+	return nil
+}
+
+// loadDesktopLockPatterns loads the desktop/lock screen patterns of the configured languages: the
+// language's file in patterns_path, or the built-in patterns without one
+func loadDesktopLockPatterns(c *DesktopLockConfig, configFileDir string) error {
+	var sets []*idlescreen.Patterns
+	for _, language := range c.Languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		name := language + ".txt"
+		content, err := "", os.ErrNotExist
+		if c.PatternsPath != "" {
+			content, err = loadPromptFromScene(c.PatternsPath, name, configFileDir)
+		}
+		if err != nil {
+			builtin, ok := idlescreen.Builtin[language]
+			if !ok {
+				return fmt.Errorf("no patterns for language %q (add %s to %s)", language, name, c.PatternsPath)
+			}
+			content, name = builtin, "built-in "+language
+		}
+		patterns, err := idlescreen.Parse(name, content)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, patterns)
+	}
+	idlescreen.Set(idlescreen.Merge(sets...))
 	return nil
 }
 
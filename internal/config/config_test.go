@@ -1,7 +1,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStorageConfig_Validate(t *testing.T) {
@@ -100,6 +104,17 @@ func TestStorageConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "无效配置 - 一天开始的小时超出范围",
+			config: StorageConfig{
+				HourSegments:    4,
+				DayWorkSegments: 0,
+				MonthWeeks:      "calendar",
+				YearQuarters:    4,
+				DayStartHour:    24,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -373,3 +388,311 @@ func TestProperty8_InvalidConfigsRejected(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalysisConfig_ShouldAnalyze(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     AnalysisConfig
+		periodType string
+		want       bool
+	}{
+		{
+			name:       "默认级别 - 周",
+			config:     AnalysisConfig{Enabled: true, Levels: []string{"week", "month", "quarter", "year"}},
+			periodType: "week",
+			want:       true,
+		},
+		{
+			name:       "默认级别 - 日不分析",
+			config:     AnalysisConfig{Enabled: true, Levels: []string{"week", "month", "quarter", "year"}},
+			periodType: "day",
+			want:       false,
+		},
+		{
+			name:       "全局关闭",
+			config:     AnalysisConfig{Enabled: false, Levels: []string{"week", "month"}},
+			periodType: "week",
+			want:       false,
+		},
+		{
+			name:       "自定义级别 - 只分析月",
+			config:     AnalysisConfig{Enabled: true, Levels: []string{" Month "}},
+			periodType: "month",
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.ShouldAnalyze(tt.periodType); got != tt.want {
+				t.Errorf("ShouldAnalyze(%q) = %v, want %v", tt.periodType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalOnlyConfig_IsLocalOnlyApp(t *testing.T) {
+	config := LocalOnlyConfig{Apps: []string{"1Password", " WeChat "}}
+	tests := []struct {
+		name string
+		app  string
+		want bool
+	}{
+		{name: "完全匹配", app: "1Password", want: true},
+		{name: "不区分大小写", app: "wechat", want: true},
+		{name: "未配置的应用", app: "Safari", want: false},
+		{name: "不做前缀匹配", app: "1Password 7", want: false},
+		{name: "未知应用", app: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.IsLocalOnlyApp(tt.app); got != tt.want {
+				t.Errorf("IsLocalOnlyApp(%q) = %v, want %v", tt.app, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpacesConfig_IsExcluded(t *testing.T) {
+	config := SpacesConfig{Names: map[string]string{"2": "personal"}, Exclude: []string{"Personal", " 4 "}}
+	tests := []struct {
+		name  string
+		space int
+		want  bool
+	}{
+		{name: "按名称排除（不区分大小写）", space: 2, want: true},
+		{name: "按编号排除", space: 4, want: true},
+		{name: "未排除的桌面", space: 1, want: false},
+		{name: "默认名称不匹配其他桌面", space: 3, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.IsExcluded(tt.space); got != tt.want {
+				t.Errorf("IsExcluded(%d) = %v, want %v", tt.space, got, tt.want)
+			}
+		})
+	}
+	if got := config.Label(3); got != "Space 3" {
+		t.Errorf("Label(3) = %q, want %q", got, "Space 3")
+	}
+}
+
+func TestRoutingConfig_Route(t *testing.T) {
+	config := RoutingConfig{Rules: []RoutingRule{
+		{Name: "text", Apps: []string{"Terminal", " Code "}},
+		{Name: "design", Apps: []string{"Figma"}, TitleContains: []string{"figma"}},
+	}}
+	tests := []struct {
+		name  string
+		app   string
+		title string
+		want  string
+	}{
+		{name: "应用匹配", app: "Terminal", want: "text"},
+		{name: "不区分大小写", app: "code", title: "main.go", want: "text"},
+		{name: "标题关键字匹配", app: "Google Chrome", title: "Onboarding – Figma", want: "design"},
+		{name: "第一条匹配的规则生效", app: "Code", title: "figma-plugin", want: "text"},
+		{name: "未匹配", app: "Safari", title: "News", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if rule := config.Route(tt.app, tt.title); rule != nil {
+				got = rule.Name
+			}
+			if got != tt.want {
+				t.Errorf("Route(%q, %q) = %q, want %q", tt.app, tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenshotConfig_CaptureInterval(t *testing.T) {
+	config := ScreenshotConfig{Interval: "2m", AppIntervals: []AppIntervalRule{
+		{Name: "video", Apps: []string{"IINA", " Steam "}, Interval: "10m"},
+		{Name: "ide", Apps: []string{"GoLand"}, Interval: "30s"},
+	}}
+	tests := []struct {
+		name string
+		app  string
+		cron string
+		want time.Duration
+	}{
+		{name: "视频应用", app: "iina", want: 10 * time.Minute},
+		{name: "去除空白", app: "Steam", want: 10 * time.Minute},
+		{name: "IDE", app: "GoLand", want: 30 * time.Second},
+		{name: "未匹配使用 interval", app: "Safari", want: 2 * time.Minute},
+		{name: "cron 下未匹配每次都截图", app: "Safari", cron: "*/2 * * * *", want: 0},
+		{name: "cron 下匹配规则", app: "IINA", cron: "*/2 * * * *", want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Cron = tt.cron
+			if got := config.CaptureInterval(tt.app); got != tt.want {
+				t.Errorf("CaptureInterval(%q) = %v, want %v", tt.app, got, tt.want)
+			}
+		})
+	}
+
+	config.Cron = ""
+	if tick, err := config.TickInterval(); err != nil || tick != 30*time.Second {
+		t.Errorf("TickInterval() = %v, %v, want 30s", tick, err)
+	}
+}
+
+func TestConfig_IsPersonalTime(t *testing.T) {
+	workHours := WorkHoursConfig{StartHour: 9, EndHour: 18}
+	at := func(hour int) time.Time { return time.Date(2024, 1, 15, hour, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name      string
+		enabled   bool
+		workHours WorkHoursConfig
+		t         time.Time
+		want      bool
+	}{
+		{name: "工作时间", enabled: true, workHours: workHours, t: at(10), want: false},
+		{name: "下班时间", enabled: true, workHours: workHours, t: at(21), want: true},
+		{name: "未启用 24/7 模式", enabled: false, workHours: workHours, t: at(21), want: false},
+		{name: "未配置工作时间", enabled: true, t: at(21), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Personal: PersonalConfig{Enabled: tt.enabled}}
+			cfg.Screenshot.WorkHours = tt.workHours
+			if got := cfg.IsPersonalTime(tt.t); got != tt.want {
+				t.Errorf("IsPersonalTime(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageConfig_DayStart(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 30, 0, 0, time.Local) }
+	tests := []struct {
+		name         string
+		dayStartHour int
+		t            time.Time
+		wantDate     string
+		wantStart    time.Time
+	}{
+		{name: "午夜开始", dayStartHour: 0, t: at(15, 1), wantDate: "2024-01-15", wantStart: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)},
+		{name: "凌晨归入前一天", dayStartHour: 3, t: at(15, 1), wantDate: "2024-01-14", wantStart: time.Date(2024, 1, 14, 3, 0, 0, 0, time.Local)},
+		{name: "开始时间之后", dayStartHour: 3, t: at(15, 3), wantDate: "2024-01-15", wantStart: time.Date(2024, 1, 15, 3, 0, 0, 0, time.Local)},
+		{name: "跨月", dayStartHour: 4, t: time.Date(2024, 3, 1, 2, 0, 0, 0, time.Local), wantDate: "2024-02-29", wantStart: time.Date(2024, 2, 29, 4, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &StorageConfig{DayStartHour: tt.dayStartHour}
+			if got := c.LogicalDate(tt.t).Format("2006-01-02"); got != tt.wantDate {
+				t.Errorf("LogicalDate(%v) = %s, want %s", tt.t, got, tt.wantDate)
+			}
+			if got := c.DayStart(tt.t); !got.Equal(tt.wantStart) {
+				t.Errorf("DayStart(%v) = %v, want %v", tt.t, got, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestConfig_IsEmbargoed(t *testing.T) {
+	cfg := &Config{Embargo: []EmbargoWindow{
+		{Days: []string{"tue"}, Start: "17:00", End: "18:00"},
+		{Days: []string{"Friday"}, Start: "23:30", End: "00:30"},
+	}}
+	// 2025-11-25 是周二，2025-11-28 是周五
+	tests := []struct {
+		name string
+		time time.Time
+		want bool
+	}{
+		{"周二屏蔽时段内", time.Date(2025, 11, 25, 17, 30, 0, 0, time.Local), true},
+		{"周二屏蔽时段开始", time.Date(2025, 11, 25, 17, 0, 0, 0, time.Local), true},
+		{"周二屏蔽时段结束", time.Date(2025, 11, 25, 18, 0, 0, 0, time.Local), false},
+		{"周三同一时间", time.Date(2025, 11, 26, 17, 30, 0, 0, time.Local), false},
+		{"周五跨午夜之前", time.Date(2025, 11, 28, 23, 45, 0, 0, time.Local), true},
+		{"周六跨午夜之后", time.Date(2025, 11, 29, 0, 15, 0, 0, time.Local), true},
+		{"周六晚上", time.Date(2025, 11, 29, 23, 45, 0, 0, time.Local), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.IsEmbargoed(tt.time); got != tt.want {
+				t.Errorf("IsEmbargoed(%v) = %v, want %v", tt.time, got, tt.want)
+			}
+		})
+	}
+
+	day := time.Date(2025, 11, 25, 0, 0, 0, 0, time.Local)
+	occurrences := cfg.EmbargoOccurrences(day.Add(17*time.Hour+30*time.Minute), day.AddDate(0, 0, 1))
+	if len(occurrences) != 1 || !occurrences[0][0].Equal(day.Add(17*time.Hour+30*time.Minute)) || !occurrences[0][1].Equal(day.Add(18*time.Hour)) {
+		t.Errorf("EmbargoOccurrences() = %v, want one range clipped to 17:30-18:00", occurrences)
+	}
+
+	for _, w := range []EmbargoWindow{
+		{Days: []string{"xx"}, Start: "17:00", End: "18:00"},
+		{Start: "25:00", End: "18:00"},
+		{Start: "17:00", End: "17:00"},
+	} {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", w)
+		}
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+openai:
+  modle: gpt-4o
+  pricing:
+    gpt-4o: {input: 2.5, output: "ten"}
+screenshot:
+  analysis_workers: "4"
+  work_hours:
+    start_hour: nine
+storage:
+  report_watchdog: true
+embargo:
+  - days: [tue]
+    start: "17:00"
+    end: "18:00"
+    label: therapy
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := CheckFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		`embargo[0].label: unknown key`,
+		`openai.modle: unknown key (did you mean "model"?)`,
+		`openai.pricing.gpt-4o.output: expected a number, got "ten"`,
+		`screenshot.work_hours.start_hour: expected an integer, got "nine"`,
+		`storage.report_watchdog: expected a mapping, got true`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFile() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSchema(t *testing.T) {
+	properties := Schema()["properties"].(map[string]any)
+	watchdog := properties["storage"].(map[string]any)["properties"].(map[string]any)["report_watchdog"].(map[string]any)
+	if watchdog["properties"].(map[string]any)["interval"].(map[string]any)["type"] != "string" {
+		t.Errorf("storage.report_watchdog.interval not a string in schema: %v", watchdog)
+	}
+	if _, ok := properties["openai"].(map[string]any)["properties"].(map[string]any)["promptcontent"]; ok {
+		t.Errorf("runtime-only field included in schema")
+	}
+}
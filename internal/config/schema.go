@@ -0,0 +1,264 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Schema returns a JSON Schema of config.yaml generated from the mapstructure tags of Config,
+// so it stays in sync with the structs; fields without a tag are runtime-only and not included
+func Schema() map[string]any {
+	schema := structSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "stuff-time configuration"
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for _, field := range schemaFields(t) {
+		properties[field.key] = typeSchema(field.typ)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+type schemaField struct {
+	key string
+	typ reflect.Type
+}
+
+// schemaFields returns the configurable fields of a struct, keyed by their mapstructure tag
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if !f.IsExported() || key == "" || key == "-" {
+			continue
+		}
+		fields = append(fields, schemaField{key: key, typ: f.Type})
+	}
+	return fields
+}
+
+// FileUsed returns the config file found by the last Load, empty if none was found
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// SchemaError is a config value that does not match the schema
+type SchemaError struct {
+	Path    string // Dotted key path, e.g. "screenshot.work_hours.start_hour" or "embargo[0].days"
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// CheckFile checks a config file against the Config structs and returns every unknown key
+// (usually a typo, silently ignored when loading) and every value that cannot be decoded
+// Values are checked as leniently as loading decodes them, e.g. "5" is accepted for an integer
+func CheckFile(configPath string) ([]SchemaError, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var errs []SchemaError
+	checkValue(reflect.TypeOf(Config{}), v.AllSettings(), "", &errs)
+	return errs, nil
+}
+
+func checkValue(t reflect.Type, value any, path string, errs *[]SchemaError) {
+	if value == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		checkValue(t.Elem(), value, path, errs)
+	case reflect.String:
+		if !isScalar(value) {
+			fail("expected a string, got %s", describeValue(value))
+		}
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool, int, int64, float64:
+		case string:
+			if _, err := strconv.ParseBool(v); err != nil && v != "" {
+				fail("expected true or false, got %q", v)
+			}
+		default:
+			fail("expected true or false, got %s", describeValue(value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := value.(type) {
+		case int, int64, uint64, bool:
+		case float64:
+			if v != float64(int64(v)) {
+				fail("expected an integer, got %v", v)
+			}
+		case string:
+			if _, err := strconv.ParseInt(strings.TrimSpace(v), 0, 64); err != nil && v != "" {
+				fail("expected an integer, got %q", v)
+			}
+		default:
+			fail("expected an integer, got %s", describeValue(value))
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case int, int64, uint64, float64, bool:
+		case string:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil && v != "" {
+				fail("expected a number, got %q", v)
+			}
+		default:
+			fail("expected a number, got %s", describeValue(value))
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			// A single value is decoded as a one-element list
+			checkValue(t.Elem(), value, path, errs)
+			return
+		}
+		for i, item := range items {
+			checkValue(t.Elem(), item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		entries, ok := value.(map[string]any)
+		if !ok {
+			fail("expected a mapping, got %s", describeValue(value))
+			return
+		}
+		for _, key := range sortedKeys(entries) {
+			checkValue(t.Elem(), entries[key], joinPath(path, key), errs)
+		}
+	case reflect.Struct:
+		entries, ok := value.(map[string]any)
+		if !ok {
+			fail("expected a mapping, got %s", describeValue(value))
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		for _, field := range schemaFields(t) {
+			fields[field.key] = field.typ
+		}
+		for _, key := range sortedKeys(entries) {
+			fieldType, known := fields[strings.ToLower(key)]
+			if !known {
+				*errs = append(*errs, SchemaError{Path: joinPath(path, key), Message: "unknown key" + suggestKey(key, fields)})
+				continue
+			}
+			checkValue(fieldType, entries[key], joinPath(path, key), errs)
+		}
+	}
+}
+
+// suggestKey names the closest known key when an unknown key looks like a typo of it
+func suggestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for candidate := range fields {
+		if d := editDistance(strings.ToLower(key), candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case string, int, int64, uint64, float64, bool:
+		return true
+	}
+	return false
+}
+
+func describeValue(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "a mapping"
+	case []any:
+		return "a list"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,257 @@
+// Package costs summarizes what the tool itself cost over a month: tokens and dollars per summary
+// level, prompt cache hit rates, de-duplication savings, latency and failure rates, so interval,
+// model and threshold settings can be tuned with data
+package costs
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+// Price is the price of a model in USD per million tokens
+type Price struct {
+	Input       float64
+	CachedInput float64 // 0 uses Input
+	Output      float64
+}
+
+// Row aggregates the calls of one summary level, request purpose or model
+type Row struct {
+	Name             string  `json:"name"`
+	Calls            int     `json:"calls"`
+	Failed           int     `json:"failed"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CachedTokens     int     `json:"cached_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost_usd"`
+	Unpriced         int     `json:"unpriced_calls"` // Successful calls whose model has no price
+	AvgLatencyMs     int64   `json:"avg_latency_ms"` // Successful calls only
+
+	latencyTotal int64
+	latencyCount int
+}
+
+// CacheHitRate is the share of prompt tokens served from the provider's prompt cache
+func (r *Row) CacheHitRate() float64 {
+	if r.PromptTokens == 0 {
+		return 0
+	}
+	return float64(r.CachedTokens) / float64(r.PromptTokens)
+}
+
+// FailureRate is the share of failed calls, retries included
+func (r *Row) FailureRate() float64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Calls)
+}
+
+// Report is the cost report of a month
+type Report struct {
+	Month       string `json:"month"`
+	Levels      []*Row `json:"levels"` // Summary levels and other request purposes, most expensive first
+	Models      []*Row `json:"models"`
+	Total       *Row   `json:"total"`
+	DedupLines  int    `json:"dedup_lines"`  // Repeated sibling summary lines removed
+	DedupTokens int    `json:"dedup_tokens"` // Estimated prompt tokens saved by removing them
+	// Models called without a configured price
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
+// Compute builds the report of the month containing month; prices are keyed by lower-case model name
+func Compute(month time.Time, calls []*storage.APICall, savings []*storage.DedupSaving, prices map[string]Price) *Report {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	report := &Report{Month: monthStart.Format("2006-01"), Total: &Row{Name: "total"}}
+	levels := make(map[string]*Row)
+	models := make(map[string]*Row)
+	unpriced := make(map[string]bool)
+	row := func(rows map[string]*Row, name string) *Row {
+		if rows[name] == nil {
+			rows[name] = &Row{Name: name}
+		}
+		return rows[name]
+	}
+
+	for _, call := range calls {
+		if call.Timestamp.Before(monthStart) || !call.Timestamp.Before(monthEnd) {
+			continue
+		}
+		level := call.Purpose
+		if call.Level != "" {
+			level = call.Purpose + "/" + call.Level
+		}
+		if level == "" {
+			level = "other"
+		}
+		model := call.Model
+		if model == "" {
+			model = "unknown"
+		}
+
+		price, priced := prices[strings.ToLower(call.Model)]
+		if !priced && call.Error == "" {
+			unpriced[model] = true
+		}
+		for _, r := range []*Row{row(levels, level), row(models, model), report.Total} {
+			r.add(call, price, priced)
+		}
+	}
+	for _, s := range savings {
+		if s.Timestamp.Before(monthStart) || !s.Timestamp.Before(monthEnd) {
+			continue
+		}
+		report.DedupLines += s.Lines
+		report.DedupTokens += s.Tokens
+	}
+
+	report.Levels = sortedRows(levels)
+	report.Models = sortedRows(models)
+	report.Total.finish()
+	for model := range unpriced {
+		report.UnpricedModels = append(report.UnpricedModels, model)
+	}
+	sort.Strings(report.UnpricedModels)
+	return report
+}
+
+// Usage aggregates usage summed per model into one row; prices are keyed by lower-case model name
+func Usage(usage []*storage.APICallUsage, prices map[string]Price) *Row {
+	total := &Row{Name: "total"}
+	for _, u := range usage {
+		total.Calls += u.Calls
+		total.Failed += u.Failed
+		total.PromptTokens += u.PromptTokens
+		total.CachedTokens += u.CachedTokens
+		total.CompletionTokens += u.CompletionTokens
+		if price, ok := prices[strings.ToLower(u.Model)]; ok {
+			total.Cost += price.cost(u.PromptTokens, u.CachedTokens, u.CompletionTokens)
+		} else if u.Calls > u.Failed {
+			total.Unpriced += u.Calls - u.Failed
+		}
+	}
+	return total
+}
+
+// cost is the price of the tokens in USD
+func (p Price) cost(promptTokens, cachedTokens, completionTokens int) float64 {
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	uncached := promptTokens - cachedTokens
+	return (float64(uncached)*p.Input + float64(cachedTokens)*cachedPrice + float64(completionTokens)*p.Output) / 1e6
+}
+
+// Tokens is the number of prompt and completion tokens
+func (r *Row) Tokens() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+func (r *Row) add(call *storage.APICall, price Price, priced bool) {
+	r.Calls++
+	if call.Error != "" {
+		r.Failed++
+		return
+	}
+	r.PromptTokens += call.PromptTokens
+	r.CachedTokens += call.CachedTokens
+	r.CompletionTokens += call.CompletionTokens
+	r.latencyTotal += call.LatencyMs
+	r.latencyCount++
+	if !priced {
+		r.Unpriced++
+		return
+	}
+	r.Cost += price.cost(call.PromptTokens, call.CachedTokens, call.CompletionTokens)
+}
+
+func (r *Row) finish() {
+	if r.latencyCount > 0 {
+		r.AvgLatencyMs = r.latencyTotal / int64(r.latencyCount)
+	}
+}
+
+func sortedRows(rows map[string]*Row) []*Row {
+	sorted := make([]*Row, 0, len(rows))
+	for _, r := range rows {
+		r.finish()
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Cost != sorted[j].Cost {
+			return sorted[i].Cost > sorted[j].Cost
+		}
+		if sorted[i].PromptTokens != sorted[j].PromptTokens {
+			return sorted[i].PromptTokens > sorted[j].PromptTokens
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// Render writes the report as tables
+func Render(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "API usage %s\n\n", report.Month)
+	if report.Total.Calls == 0 {
+		fmt.Fprintf(w, "No API calls recorded.\n")
+		return
+	}
+
+	renderRows(w, "Level / purpose", report.Levels, report.Total)
+	fmt.Fprintf(w, "\n")
+	renderRows(w, "Model", report.Models, nil)
+
+	if report.DedupLines > 0 {
+		fmt.Fprintf(w, "\nDe-duplication removed %d repeated summary line(s), saving ~%s prompt tokens\n",
+			report.DedupLines, formatTokens(report.DedupTokens))
+	}
+	if len(report.UnpricedModels) > 0 {
+		fmt.Fprintf(w, "\nNo price configured for %s (set openai.pricing); costs marked * exclude their calls\n",
+			strings.Join(report.UnpricedModels, ", "))
+	}
+}
+
+func renderRows(w io.Writer, title string, rows []*Row, total *Row) {
+	fmt.Fprintf(w, "%-28s %7s %7s %10s %7s %10s %9s %10s\n",
+		title, "Calls", "Failed", "Prompt", "Cached", "Output", "Latency", "Cost")
+	for _, r := range rows {
+		renderRow(w, r)
+	}
+	if total != nil {
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 95))
+		renderRow(w, total)
+	}
+}
+
+func renderRow(w io.Writer, r *Row) {
+	latency := "-"
+	if r.AvgLatencyMs > 0 {
+		latency = fmt.Sprintf("%.1fs", float64(r.AvgLatencyMs)/1000)
+	}
+	cost := fmt.Sprintf("$%.2f", r.Cost)
+	if r.Unpriced > 0 {
+		cost += "*"
+	}
+	fmt.Fprintf(w, "%-28s %7d %6.1f%% %10s %6.0f%% %10s %9s %10s\n",
+		r.Name, r.Calls, r.FailureRate()*100, formatTokens(r.PromptTokens), r.CacheHitRate()*100,
+		formatTokens(r.CompletionTokens), latency, cost)
+}
+
+// formatTokens abbreviates token counts, e.g. 12.3M, 45.6K
+func formatTokens(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fK", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d", n)
+}
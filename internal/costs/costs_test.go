@@ -0,0 +1,50 @@
+package costs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestCompute(t *testing.T) {
+	month := time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local)
+	at := month.Add(36 * time.Hour)
+	calls := []*storage.APICall{
+		{Timestamp: at, Model: "gpt-4o", Purpose: "screenshot_analysis", PromptTokens: 1000000, CachedTokens: 500000, CompletionTokens: 100000, LatencyMs: 2000},
+		{Timestamp: at, Model: "gpt-4o", Purpose: "screenshot_analysis", Error: "rate limited", LatencyMs: 100},
+		{Timestamp: at, Model: "GPT-4o-mini", Purpose: "summary", Level: "hour", PromptTokens: 2000, CompletionTokens: 500, LatencyMs: 1000},
+		{Timestamp: at, Model: "local", Purpose: "summary", Level: "day", PromptTokens: 3000, LatencyMs: 4000},
+		{Timestamp: month.AddDate(0, 1, 0), Model: "gpt-4o", Purpose: "summary", PromptTokens: 9999999},
+	}
+	savings := []*storage.DedupSaving{{Timestamp: at, PeriodKey: "2025-11-02", Lines: 3, Tokens: 120}}
+	prices := map[string]Price{
+		"gpt-4o":      {Input: 2.5, CachedInput: 1.25, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+	}
+
+	report := Compute(month, calls, savings, prices)
+
+	if report.Total.Calls != 4 || report.Total.Failed != 1 {
+		t.Errorf("total calls = %d, failed = %d, want 4, 1", report.Total.Calls, report.Total.Failed)
+	}
+	// 0.5M uncached * 2.5 + 0.5M cached * 1.25 + 0.1M output * 10, plus 2000 * 0.15 + 500 * 0.6 per million
+	if want := 1.25 + 0.625 + 1.0 + 0.0006; math.Abs(report.Total.Cost-want) > 1e-9 {
+		t.Errorf("total cost = %v, want %v", report.Total.Cost, want)
+	}
+	if len(report.Levels) != 3 || report.Levels[0].Name != "screenshot_analysis" {
+		t.Fatalf("levels = %+v", report.Levels)
+	}
+	analysis := report.Levels[0]
+	if analysis.FailureRate() != 0.5 || analysis.CacheHitRate() != 0.5 || analysis.AvgLatencyMs != 2000 {
+		t.Errorf("analysis failure rate = %v, cache hit rate = %v, latency = %d",
+			analysis.FailureRate(), analysis.CacheHitRate(), analysis.AvgLatencyMs)
+	}
+	if len(report.UnpricedModels) != 1 || report.UnpricedModels[0] != "local" {
+		t.Errorf("unpriced models = %v, want [local]", report.UnpricedModels)
+	}
+	if report.DedupLines != 3 || report.DedupTokens != 120 {
+		t.Errorf("dedup = %d lines, %d tokens", report.DedupLines, report.DedupTokens)
+	}
+}
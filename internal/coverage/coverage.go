@@ -0,0 +1,161 @@
+// Package coverage computes how many minutes per hour were captured, analyzed and summarized,
+// so silent gaps (screen recording permission lost, daemon not running) stand out
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Metrics shown by the heatmap
+const (
+	MetricCaptured   = "captured"
+	MetricAnalyzed   = "analyzed"
+	MetricSummarized = "summarized"
+)
+
+// Sample is one capture time
+type Sample struct {
+	Time       time.Time
+	Analyzed   bool // Analyzed or skipped as desktop/lock screen (processed by the analysis pipeline)
+	Summarized bool // Covered by a period summary
+}
+
+// Day holds the covered minutes of each hour of a day
+type Day struct {
+	Date       string  `json:"date"`
+	Captured   [24]int `json:"captured"`
+	Analyzed   [24]int `json:"analyzed"`
+	Summarized [24]int `json:"summarized"`
+	// Hours overlapping an embargo window, where nothing is captured on purpose
+	Embargoed [24]bool `json:"embargoed"`
+}
+
+// Month is the coverage of every day of a month
+type Month struct {
+	Month string `json:"month"`
+	Days  []*Day `json:"days"`
+}
+
+// Compute builds the coverage of the month containing month
+// Each capture covers one capture interval; screenshots of several screens taken together count once
+func Compute(month time.Time, samples []Sample, interval time.Duration) *Month {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	result := &Month{Month: monthStart.Format("2006-01")}
+	for d := monthStart; d.Before(monthEnd); d = d.AddDate(0, 0, 1) {
+		result.Days = append(result.Days, &Day{Date: d.Format("2006-01-02")})
+	}
+
+	type slotState struct{ analyzed, summarized bool }
+	slots := make(map[time.Time]*slotState)
+	for _, s := range samples {
+		if s.Time.Before(monthStart) || !s.Time.Before(monthEnd) {
+			continue
+		}
+		slot := s.Time.Truncate(interval)
+		state, ok := slots[slot]
+		if !ok {
+			state = &slotState{}
+			slots[slot] = state
+		}
+		state.analyzed = state.analyzed || s.Analyzed
+		state.summarized = state.summarized || s.Summarized
+	}
+
+	// Accumulate in seconds so sub-minute intervals aren't rounded away
+	seconds := make(map[[2]int]*[3]float64)
+	for slot, state := range slots {
+		key := [2]int{slot.Day() - 1, slot.Hour()}
+		acc, ok := seconds[key]
+		if !ok {
+			acc = &[3]float64{}
+			seconds[key] = acc
+		}
+		acc[0] += interval.Seconds()
+		if state.analyzed {
+			acc[1] += interval.Seconds()
+		}
+		if state.summarized {
+			acc[2] += interval.Seconds()
+		}
+	}
+	for key, acc := range seconds {
+		day := result.Days[key[0]]
+		day.Captured[key[1]] = toMinutes(acc[0])
+		day.Analyzed[key[1]] = toMinutes(acc[1])
+		day.Summarized[key[1]] = toMinutes(acc[2])
+	}
+	return result
+}
+
+func toMinutes(seconds float64) int {
+	minutes := int(seconds/60 + 0.5)
+	if minutes > 60 {
+		minutes = 60
+	}
+	return minutes
+}
+
+// Hours returns the per-hour minutes of a metric
+func (d *Day) Hours(metric string) [24]int {
+	switch metric {
+	case MetricAnalyzed:
+		return d.Analyzed
+	case MetricSummarized:
+		return d.Summarized
+	default:
+		return d.Captured
+	}
+}
+
+// Shade returns the heatmap cell for the minutes covered in an hour
+func Shade(minutes int) rune {
+	switch {
+	case minutes <= 0:
+		return '·'
+	case minutes <= 15:
+		return '░'
+	case minutes <= 30:
+		return '▒'
+	case minutes <= 45:
+		return '▓'
+	default:
+		return '█'
+	}
+}
+
+func total(hours [24]int) int {
+	sum := 0
+	for _, m := range hours {
+		sum += m
+	}
+	return sum
+}
+
+// Render prints the heatmap of a metric (one row per day, one cell per hour) with daily totals of all metrics
+func Render(w io.Writer, m *Month, metric string) {
+	fmt.Fprintf(w, "Coverage for %s (%s minutes per hour)\n\n", m.Month, metric)
+	fmt.Fprintf(w, "%-15s%-24s  %8s %8s %10s\n", "", "0     6     12    18", MetricCaptured, MetricAnalyzed, MetricSummarized)
+	for _, d := range m.Days {
+		date, _ := time.Parse("2006-01-02", d.Date)
+		var cells strings.Builder
+		for hour, minutes := range d.Hours(metric) {
+			if minutes == 0 && d.Embargoed[hour] {
+				// Not a gap: nothing is captured during embargo windows
+				cells.WriteRune('×')
+				continue
+			}
+			cells.WriteRune(Shade(minutes))
+		}
+		fmt.Fprintf(w, "%-15s%s  %8d %8d %10d\n", date.Format("2006-01-02 Mon"), cells.String(),
+			total(d.Captured), total(d.Analyzed), total(d.Summarized))
+	}
+	fmt.Fprintf(w, "\n· none  ░ 1-15  ▒ 16-30  ▓ 31-45  █ 46-60 min  × embargoed\n")
+}
@@ -0,0 +1,46 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeCountsCaptureIntervals(t *testing.T) {
+	base := time.Date(2025, 11, 3, 9, 0, 0, 0, time.Local)
+	var samples []Sample
+	for i := 0; i < 30; i++ {
+		at := base.Add(time.Duration(i) * time.Minute)
+		// Two screens captured together count once
+		samples = append(samples,
+			Sample{Time: at, Analyzed: i < 20, Summarized: i < 15},
+			Sample{Time: at, Analyzed: i < 20, Summarized: i < 15})
+	}
+	// Outside the month
+	samples = append(samples, Sample{Time: base.AddDate(0, 1, 0)})
+
+	m := Compute(base, samples, time.Minute)
+	if len(m.Days) != 30 {
+		t.Fatalf("days = %d, want 30", len(m.Days))
+	}
+	day := m.Days[2]
+	if day.Captured[9] != 30 || day.Analyzed[9] != 20 || day.Summarized[9] != 15 {
+		t.Errorf("hour 9 = %d/%d/%d, want 30/20/15", day.Captured[9], day.Analyzed[9], day.Summarized[9])
+	}
+	if day.Captured[10] != 0 || m.Days[0].Captured[9] != 0 {
+		t.Errorf("unexpected coverage outside the captured hour")
+	}
+}
+
+func TestRenderShowsGaps(t *testing.T) {
+	day := &Day{Date: "2025-11-03"}
+	day.Captured[9] = 60
+	day.Captured[10] = 10
+	var buf bytes.Buffer
+	Render(&buf, &Month{Month: "2025-11", Days: []*Day{day}}, MetricCaptured)
+
+	if !strings.Contains(buf.String(), "·········█░·············") {
+		t.Errorf("unexpected heatmap:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,170 @@
+// Package datefmt formats dates and times shown to the user (reports, CLI output, exports) according to
+// the locale configuration. Machine-readable keys (period keys, file paths, JSON) keep fixed layouts.
+package datefmt
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLayout is the date-time layout of reports written before formats were configurable;
+// Parse always accepts it
+const DefaultLayout = "2006-01-02 15:04:05"
+
+// Options configures the display format
+type Options struct {
+	Language   string // "zh" (default) or "en": weekday and AM/PM names
+	DateFormat string // Go layout of dates, e.g. "2006-01-02" (default), "01/02/2006", "Jan 2, 2006"
+	Clock      string // "24h" (default) or "12h"
+	Weekday    bool   // Show the weekday after dates
+}
+
+type format struct {
+	language    string
+	date        string
+	clock       string
+	clockMinute string
+}
+
+var current atomic.Pointer[format]
+
+func init() {
+	f, _ := newFormat(Options{})
+	current.Store(f)
+}
+
+// zhNames translates the English names produced by time.Format; long names come first so that
+// "Tuesday" is not translated as "Tue"+"sday"
+var zhNames = []struct{ en, zh string }{
+	{"Sunday", "星期日"}, {"Monday", "星期一"}, {"Tuesday", "星期二"}, {"Wednesday", "星期三"},
+	{"Thursday", "星期四"}, {"Friday", "星期五"}, {"Saturday", "星期六"},
+	{"Sun", "周日"}, {"Mon", "周一"}, {"Tue", "周二"}, {"Wed", "周三"}, {"Thu", "周四"}, {"Fri", "周五"}, {"Sat", "周六"},
+	{"AM", "上午"}, {"PM", "下午"},
+}
+
+func newFormat(opts Options) (*format, error) {
+	f := &format{language: opts.Language, date: opts.DateFormat}
+	if f.language == "" {
+		f.language = "zh"
+	}
+	if f.language != "zh" && f.language != "en" {
+		return nil, fmt.Errorf("language must be 'zh' or 'en', got '%s'", opts.Language)
+	}
+	if f.date == "" {
+		f.date = "2006-01-02"
+	}
+	if opts.Weekday {
+		f.date += " Mon"
+	}
+
+	switch opts.Clock {
+	case "", "24h":
+		f.clock, f.clockMinute = "15:04:05", "15:04"
+	case "12h":
+		if f.language == "zh" {
+			f.clock, f.clockMinute = "PM3:04:05", "PM3:04"
+		} else {
+			f.clock, f.clockMinute = "3:04:05 PM", "3:04 PM"
+		}
+	default:
+		return nil, fmt.Errorf("clock must be '24h' or '12h', got '%s'", opts.Clock)
+	}
+
+	// Reports are parsed back (validate --rebuild-db), so the date-time format must round-trip
+	ref := time.Date(2025, 12, 9, 14, 3, 5, 0, time.UTC)
+	if parsed, err := f.parse(f.format(ref, f.date+" "+f.clock)); err != nil || !parsed.Equal(ref) {
+		return nil, fmt.Errorf("date_format %q cannot be parsed back (it needs the year, month and day)", opts.DateFormat)
+	}
+	return f, nil
+}
+
+// Set changes the display format; invalid options leave the current format unchanged
+func Set(opts Options) error {
+	f, err := newFormat(opts)
+	if err != nil {
+		return err
+	}
+	current.Store(f)
+	return nil
+}
+
+// Date formats a date, e.g. 2025-12-09
+func Date(t time.Time) string {
+	f := current.Load()
+	return f.format(t, f.date)
+}
+
+// DateTime formats a date and time with seconds, e.g. 2025-12-09 14:03:05
+func DateTime(t time.Time) string {
+	f := current.Load()
+	return f.format(t, f.date+" "+f.clock)
+}
+
+// DateTimeMinute formats a date and time without seconds, e.g. 2025-12-09 14:03
+func DateTimeMinute(t time.Time) string {
+	f := current.Load()
+	return f.format(t, f.date+" "+f.clockMinute)
+}
+
+// Time formats a time of day with seconds, e.g. 14:03:05
+func Time(t time.Time) string {
+	f := current.Load()
+	return f.format(t, f.clock)
+}
+
+// TimeMinute formats a time of day without seconds, e.g. 14:03
+func TimeMinute(t time.Time) string {
+	f := current.Load()
+	return f.format(t, f.clockMinute)
+}
+
+// Language returns the configured language, "zh" or "en"
+func Language() string {
+	return current.Load().language
+}
+
+// Minutes formats a duration in whole minutes in the configured language, e.g. 1 小时 47 分钟 or 1 h 47 min
+func Minutes(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	hourUnit, minuteUnit := "小时", "分钟"
+	if current.Load().language == "en" {
+		hourUnit, minuteUnit = "h", "min"
+	}
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d %s", minutes, minuteUnit)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d %s", minutes/60, hourUnit)
+	default:
+		return fmt.Sprintf("%d %s %d %s", minutes/60, hourUnit, minutes%60, minuteUnit)
+	}
+}
+
+// Parse parses a date-time written by DateTime (or DefaultLayout), like time.Parse
+func Parse(value string) (time.Time, error) {
+	if t, err := time.Parse(DefaultLayout, value); err == nil {
+		return t, nil
+	}
+	return current.Load().parse(value)
+}
+
+func (f *format) format(t time.Time, layout string) string {
+	s := t.Format(layout)
+	if f.language == "zh" {
+		for _, name := range zhNames {
+			s = strings.ReplaceAll(s, name.en, name.zh)
+		}
+	}
+	return s
+}
+
+func (f *format) parse(value string) (time.Time, error) {
+	if f.language == "zh" {
+		for _, name := range zhNames {
+			value = strings.ReplaceAll(value, name.zh, name.en)
+		}
+	}
+	return time.Parse(f.date+" "+f.clock, value)
+}
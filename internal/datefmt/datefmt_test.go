@@ -0,0 +1,69 @@
+package datefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	defer Set(Options{})
+	ts := time.Date(2025, 12, 9, 14, 3, 5, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		opts         Options
+		wantDateTime string
+		wantDate     string
+		wantMinute   string
+	}{
+		{"default", Options{}, "2025-12-09 14:03:05", "2025-12-09", "14:03"},
+		{"zh 12h with weekday", Options{Clock: "12h", Weekday: true}, "2025-12-09 周二 下午2:03:05", "2025-12-09 周二", "下午2:03"},
+		{"en 12h", Options{Language: "en", DateFormat: "01/02/2006", Clock: "12h", Weekday: true}, "12/09/2025 Tue 2:03:05 PM", "12/09/2025 Tue", "2:03 PM"},
+		{"zh date layout", Options{DateFormat: "2006年1月2日"}, "2025年12月9日 14:03:05", "2025年12月9日", "14:03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Set(tt.opts); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if got := DateTime(ts); got != tt.wantDateTime {
+				t.Errorf("DateTime() = %q, want %q", got, tt.wantDateTime)
+			}
+			if got := Date(ts); got != tt.wantDate {
+				t.Errorf("Date() = %q, want %q", got, tt.wantDate)
+			}
+			if got := TimeMinute(ts); got != tt.wantMinute {
+				t.Errorf("TimeMinute() = %q, want %q", got, tt.wantMinute)
+			}
+			if got, want := Minutes(107*time.Minute), map[string]string{"zh": "1 小时 47 分钟", "en": "1 h 47 min"}[Language()]; got != want {
+				t.Errorf("Minutes() = %q, want %q", got, want)
+			}
+
+			parsed, err := Parse(DateTime(ts))
+			if err != nil || !parsed.Equal(ts) {
+				t.Errorf("Parse(DateTime()) = %v, %v, want %v", parsed, err, ts)
+			}
+			// Reports written with the default layout stay readable
+			if parsed, err := Parse("2025-12-09 14:03:05"); err != nil || !parsed.Equal(ts) {
+				t.Errorf("Parse(default layout) = %v, %v", parsed, err)
+			}
+		})
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	defer Set(Options{})
+	for _, opts := range []Options{
+		{Language: "fr"},
+		{Clock: "36h"},
+		{DateFormat: "01/02"},
+	} {
+		if err := Set(opts); err == nil {
+			t.Errorf("Set(%+v) should fail", opts)
+		}
+	}
+	if got := Date(time.Date(2025, 12, 9, 0, 0, 0, 0, time.UTC)); got != "2025-12-09" {
+		t.Errorf("invalid options should keep the previous format, got %q", got)
+	}
+}
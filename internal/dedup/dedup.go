@@ -0,0 +1,137 @@
+// Package dedup collapses near-identical content repeated across sibling summaries
+// (e.g. four fifteen-minute summaries of the same long task) before they are aggregated
+package dedup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Options controls line de-duplication
+type Options struct {
+	// Threshold is the similarity (0-1) at or above which a line counts as a duplicate
+	Threshold float64
+	// MinRunes skips lines shorter than this (headings, separators), they are always kept
+	MinRunes int
+	// Normalize optionally rewrites a line before comparison (e.g. to drop citation markers)
+	Normalize func(string) string
+}
+
+// Similarity returns the Dice coefficient of the character bigrams of two normalized strings
+func Similarity(a, b string) float64 {
+	return dice(bigrams(normalize(a)), bigrams(normalize(b)))
+}
+
+// Lines removes lines that are near-identical to a line kept earlier in the same or a previous text.
+// Texts left without content are dropped. Returns the de-duplicated texts and the number of removed lines
+func Lines(texts []string, opts Options) ([]string, int) {
+	if opts.Threshold <= 0 {
+		return texts, 0
+	}
+
+	var seen []map[string]int
+	removed := 0
+	result := make([]string, 0, len(texts))
+	for _, text := range texts {
+		var kept []string
+		hasContent := false
+		for _, line := range strings.Split(text, "\n") {
+			key := line
+			if opts.Normalize != nil {
+				key = opts.Normalize(key)
+			}
+			key = normalize(key)
+			if len([]rune(key)) < opts.MinRunes {
+				kept = append(kept, line)
+				continue
+			}
+
+			grams := bigrams(key)
+			duplicate := false
+			for _, other := range seen {
+				if dice(grams, other) >= opts.Threshold {
+					duplicate = true
+					break
+				}
+			}
+			if duplicate {
+				removed++
+				continue
+			}
+			seen = append(seen, grams)
+			kept = append(kept, line)
+			hasContent = true
+		}
+
+		if hasContent {
+			result = append(result, collapseBlankLines(strings.Join(kept, "\n")))
+		}
+	}
+	return result, removed
+}
+
+// normalize lowercases s and drops whitespace, punctuation and list markers
+func normalize(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// bigrams returns the multiset of character bigrams of s
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	grams := make(map[string]int)
+	if len(runes) == 1 {
+		grams[s]++
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	return grams
+}
+
+// dice computes the Dice coefficient of two bigram multisets
+func dice(a, b map[string]int) float64 {
+	total := 0
+	for _, n := range a {
+		total += n
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	shared := 0
+	for gram, n := range a {
+		if m, ok := b[gram]; ok {
+			shared += min(n, m)
+		}
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// collapseBlankLines trims the text and collapses runs of blank lines left by removed lines
+func collapseBlankLines(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,58 @@
+package dedup
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("用户在 VS Code 中修改 executor.go 的重试逻辑", "用户在VS Code中修改executor.go的重试逻辑。"); got != 1 {
+		t.Errorf("Similarity() of whitespace/punctuation variants = %v, want 1", got)
+	}
+	if got := Similarity("用户在 VS Code 中修改 executor.go 的重试逻辑", "用户在浏览器中阅读 Kubernetes 官方文档"); got > 0.5 {
+		t.Errorf("Similarity() of unrelated lines = %v, want <= 0.5", got)
+	}
+}
+
+func TestLinesCollapsesRepeatsAcrossSiblings(t *testing.T) {
+	texts := []string{
+		"【工作记录】\n- 在 VS Code 中修改 executor.go 的重试逻辑\n- 运行单元测试",
+		"【工作记录】\n- 在 VS Code 中修改 executor.go 的重试逻辑。\n- 在浏览器中查看 CI 构建结果",
+		"【工作记录】\n-  在VS Code中修改 executor.go 的重试逻辑",
+	}
+
+	got, removed := Lines(texts, Options{Threshold: 0.85, MinRunes: 8})
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(texts) = %d, want 2 (third sibling has no new content)", len(got))
+	}
+	if strings.Contains(got[1], "重试逻辑") || !strings.Contains(got[1], "CI 构建结果") {
+		t.Errorf("second text = %q, want only the new line", got[1])
+	}
+}
+
+func TestLinesNormalize(t *testing.T) {
+	citation := regexp.MustCompile(`\[#[^\]]*\]`)
+	texts := []string{
+		"- 修改 executor.go 的重试逻辑 [#14:03:20]",
+		"- 修改 executor.go 的重试逻辑 [#14:18:20][#14:19:20]",
+	}
+
+	got, removed := Lines(texts, Options{Threshold: 1, MinRunes: 8, Normalize: func(s string) string {
+		return citation.ReplaceAllString(s, "")
+	}})
+	if removed != 1 || len(got) != 1 {
+		t.Errorf("Lines() = %q (removed %d), want the cited duplicate removed", got, removed)
+	}
+}
+
+func TestLinesDisabled(t *testing.T) {
+	texts := []string{"同一句话重复出现", "同一句话重复出现"}
+	got, removed := Lines(texts, Options{})
+	if removed != 0 || len(got) != 2 {
+		t.Errorf("Lines() with zero threshold = %q (removed %d), want unchanged", got, removed)
+	}
+}
@@ -0,0 +1,174 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"stuff-time/internal/analyzer"
+)
+
+// AdviceEntry is the evaluation of one period report, as input for prompt advice
+type AdviceEntry struct {
+	PeriodKey  string
+	PeriodType string
+	Scores     Scores
+	Findings   []string
+}
+
+// findingLabels start the lines of an evaluation that name problems of the report
+var findingLabels = []string{"问题", "具体示例"}
+
+// ExtractFindings returns the problems, examples and improvement suggestions an evaluation report
+// lists, one per entry; "无" and empty items are skipped
+func ExtractFindings(report string) []string {
+	var findings []string
+	inProblems, inAdvice := false, false
+	for _, line := range strings.Split(report, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "【"):
+			inProblems, inAdvice = false, strings.Contains(trimmed, "改进建议")
+			continue
+		case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "---"):
+			inProblems, inAdvice = false, false
+			continue
+		}
+
+		bullet := strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "•")
+		text := strings.TrimSpace(strings.ReplaceAll(strings.TrimLeft(trimmed, "-*• "), "**", ""))
+		if label, rest, ok := cutLabel(text); ok {
+			inProblems = slices.Contains(findingLabels, label)
+			if inProblems {
+				findings = appendFinding(findings, rest)
+			}
+			continue
+		}
+		if bullet && (inProblems || inAdvice) {
+			findings = appendFinding(findings, text)
+		}
+	}
+	return findings
+}
+
+// cutLabel splits "标签：内容" lines, the label being a short prefix without spaces
+func cutLabel(text string) (string, string, bool) {
+	for _, sep := range []string{"：", ":"} {
+		if label, rest, ok := strings.Cut(text, sep); ok && label != "" && len([]rune(label)) <= 6 && !strings.ContainsAny(label, " []") {
+			return label, strings.TrimSpace(rest), true
+		}
+	}
+	return "", "", false
+}
+
+func appendFinding(findings []string, text string) []string {
+	text = strings.Trim(strings.TrimSpace(text), "[]")
+	if text == "" || text == "无" || strings.HasPrefix(text, "无明显") {
+		return findings
+	}
+	return append(findings, text)
+}
+
+// AverageScores returns the per-dimension average of the rated scores, and how many were rated
+func AverageScores(scores []Scores) (Scores, int) {
+	var sum Scores
+	n := 0
+	for _, s := range scores {
+		if !s.Rated() {
+			continue
+		}
+		sum.Accuracy += s.Accuracy
+		sum.Relevance += s.Relevance
+		sum.Depth += s.Depth
+		sum.Overall += s.Overall
+		n++
+	}
+	if n == 0 {
+		return Scores{}, 0
+	}
+	return Scores{
+		Accuracy:  sum.Accuracy / float64(n),
+		Relevance: sum.Relevance / float64(n),
+		Depth:     sum.Depth / float64(n),
+		Overall:   sum.Overall / float64(n),
+	}, n
+}
+
+// FormatAdviceInput lists the evaluations grouped by period type, with the average scores of each
+// type, as the input of the prompt advice meta-analysis
+func FormatAdviceInput(entries []*AdviceEntry) string {
+	byType := make(map[string][]*AdviceEntry)
+	var types []string
+	for _, entry := range entries {
+		if _, ok := byType[entry.PeriodType]; !ok {
+			types = append(types, entry.PeriodType)
+		}
+		byType[entry.PeriodType] = append(byType[entry.PeriodType], entry)
+	}
+	sort.Strings(types)
+
+	var sb strings.Builder
+	for _, periodType := range types {
+		group := byType[periodType]
+		scores := make([]Scores, 0, len(group))
+		for _, entry := range group {
+			scores = append(scores, entry.Scores)
+		}
+		avg, rated := AverageScores(scores)
+		sb.WriteString(fmt.Sprintf("## %s报告（%d 份评测", getPeriodTypeName(periodType), len(group)))
+		if rated > 0 {
+			sb.WriteString("，平均 " + avg.String())
+		}
+		sb.WriteString("）\n\n")
+		for _, entry := range group {
+			if len(entry.Findings) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("### %s（%s）\n", entry.PeriodKey, entry.Scores))
+			for _, finding := range entry.Findings {
+				sb.WriteString("- " + finding + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// SuggestPromptChanges runs the meta-analysis over a period's evaluations and returns concrete
+// changes to the summary prompts that address the recurring problems
+func (e *Evaluator) SuggestPromptChanges(prompt, currentPrompt string, entries []*AdviceEntry) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	if currentPrompt != "" {
+		sb.WriteString("\n\n当前的总结提示词：\n\n")
+		sb.WriteString(currentPrompt)
+	}
+	sb.WriteString("\n\n评测结果汇总：\n\n")
+	sb.WriteString(FormatAdviceInput(entries))
+
+	req := analyzer.VisionRequest{
+		Model:               e.analyzer.AnalysisModel,
+		MaxCompletionTokens: e.analyzer.MaxCompletionTokens,
+		Purpose:             analyzer.PurposePromptAdvice,
+		Messages: []analyzer.Message{
+			{
+				Role: "user",
+				Content: []analyzer.ContentObject{
+					{
+						Type: "text",
+						Text: sb.String(),
+					},
+				},
+			},
+		},
+	}
+
+	advice, err := e.callAPI(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to suggest prompt changes: %w", err)
+	}
+	return advice, nil
+}
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractFindings(t *testing.T) {
+	report := `# Period 报告质量评测
+
+## 评测结果
+
+【准确性评估】
+评分：5/10
+详细说明：
+- 优点：时间线基本正确
+- 问题：提到了截图中看不到的工单号 PROJ-123
+- 具体示例：
+  * "修复 PROJ-123" 在截图中没有依据
+【相关性评估】
+评分：7/10
+- 优点：覆盖了主要工作
+- 问题：无
+【改进建议】
+针对报告质量的改进建议（必须具体、可执行）：
+- 只提及截图中可见的编号
+- [补充具体的文件名]
+
+---
+
+## 截图来源信息
+
+### 截图 abcd1234
+
+- **完整ID**: abcd1234-0000
+- **分析内容**: 编辑 main.go
+`
+	want := []string{
+		"提到了截图中看不到的工单号 PROJ-123",
+		`"修复 PROJ-123" 在截图中没有依据`,
+		"只提及截图中可见的编号",
+		"补充具体的文件名",
+	}
+	if got := ExtractFindings(report); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ExtractFindings() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	avg, rated := AverageScores([]Scores{{Accuracy: 6, Relevance: 8, Depth: 4, Overall: 6}, {}, {Accuracy: 8, Relevance: 6, Depth: 6, Overall: 7}})
+	if rated != 2 || avg != (Scores{Accuracy: 7, Relevance: 7, Depth: 5, Overall: 6.5}) {
+		t.Errorf("AverageScores() = %+v, %d", avg, rated)
+	}
+}
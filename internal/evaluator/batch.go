@@ -0,0 +1,59 @@
+package evaluator
+
+import (
+	"strings"
+	"sync"
+
+	"stuff-time/internal/storage"
+)
+
+// EvaluationResult is the evaluation of one period report
+type EvaluationResult struct {
+	Summary *storage.PeriodSummary
+	Report  string
+	Err     error
+}
+
+// ReferencedScreenshotIDs returns the screenshot IDs referenced by the summaries, without duplicates,
+// so their records can be fetched in one GetScreenshotsByIDs call and shared across passes
+func ReferencedScreenshotIDs(summaries ...*storage.PeriodSummary) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, summary := range summaries {
+		for _, id := range strings.Split(summary.Screenshots, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// EvaluateReports evaluates the summaries with at most workers evaluations in flight,
+// all reading the same prefetched screenshot records; results keep the order of summaries
+func (e *Evaluator) EvaluateReports(summaries []*storage.PeriodSummary, screenshotRecords map[string]*storage.ScreenshotRecord, workers int) []*EvaluationResult {
+	if workers <= 0 {
+		workers = 1
+	}
+	results := make([]*EvaluationResult, len(summaries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(summaries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				report, err := e.EvaluateReport(summaries[i], screenshotRecords)
+				results[i] = &EvaluationResult{Summary: summaries[i], Report: report, Err: err}
+			}
+		}()
+	}
+	for i := range summaries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
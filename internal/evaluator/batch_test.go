@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/storage"
+)
+
+func TestEvaluateReportsKeepsOrder(t *testing.T) {
+	const workers = 3
+	marker := regexp.MustCompile(`summary-(\d+)`)
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		body, _ := io.ReadAll(r.Body)
+		match := marker.FindSubmatch(body)
+		if match == nil {
+			http.Error(w, "no summary in the prompt", http.StatusBadRequest)
+			return
+		}
+		// Earlier summaries take longer, so evaluations finish out of order
+		i, _ := strconv.Atoi(string(match[1]))
+		time.Sleep(time.Duration(10-i) * 5 * time.Millisecond)
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":"evaluated %s"}}]}`, match[0])
+	}))
+	defer server.Close()
+
+	client := analyzer.NewOpenAI("test-key", server.URL, "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	client.Retry = analyzer.RetryPolicy{}
+	eval := NewEvaluator(client, "评测%s %s-%s，%d 张截图", "%s\n%s", "%s", "%s", "%s")
+
+	start := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	var summaries []*storage.PeriodSummary
+	for i := 0; i < 10; i++ {
+		day := start.AddDate(0, 0, i)
+		summaries = append(summaries, &storage.PeriodSummary{PeriodKey: "day:" + day.Format("2006-01-02"), PeriodType: "day",
+			StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: fmt.Sprintf("summary-%d", i)})
+	}
+
+	results := eval.EvaluateReports(summaries, nil, workers)
+	if len(results) != len(summaries) {
+		t.Fatalf("EvaluateReports() returned %d results, want %d", len(results), len(summaries))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("result %d: %v", i, result.Err)
+		}
+		if result.Summary != summaries[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.Summary.PeriodKey, summaries[i].PeriodKey)
+		}
+		if want := fmt.Sprintf("evaluated summary-%d", i); !strings.Contains(result.Report, want) {
+			t.Errorf("result %d report does not contain %q", i, want)
+		}
+	}
+	if maxInFlight < 2 || maxInFlight > workers {
+		t.Errorf("%d evaluations in flight, want between 2 and %d", maxInFlight, workers)
+	}
+}
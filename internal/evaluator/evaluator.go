@@ -2,7 +2,6 @@ package evaluator
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +11,8 @@ import (
 	"time"
 
 	"stuff-time/internal/analyzer"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/idlescreen"
 	"stuff-time/internal/logger"
 	"stuff-time/internal/storage"
 )
@@ -25,6 +26,8 @@ type Evaluator struct {
 	screenshotSourceSectionTemplate     string
 	improvementPromptTemplate           *template.Template
 	improvementScreenshotSourceTemplate *template.Template
+	markers                             Markers
+	language                            string
 }
 
 func NewEvaluator(analyzer *analyzer.OpenAI, evaluationPromptTemplate, reportContentTemplate, screenshotSourceTemplate, reportFormatTemplate, screenshotSourceSectionTemplate string) *Evaluator {
@@ -36,6 +39,7 @@ func NewEvaluator(analyzer *analyzer.OpenAI, evaluationPromptTemplate, reportCon
 		reportFormatTemplate:            reportFormatTemplate,
 		screenshotSourceSectionTemplate: screenshotSourceSectionTemplate,
 		improvementPromptTemplate:       nil,
+		markers:                         ChineseMarkers,
 	}
 }
 
@@ -47,6 +51,7 @@ func NewEvaluatorWithImprovement(analyzer *analyzer.OpenAI, evaluationPromptTemp
 		screenshotSourceTemplate:        screenshotSourceTemplate,
 		reportFormatTemplate:            reportFormatTemplate,
 		screenshotSourceSectionTemplate: screenshotSourceSectionTemplate,
+		markers:                         ChineseMarkers,
 	}
 
 	// Parse improvement prompt template
@@ -70,6 +75,18 @@ func NewEvaluatorWithImprovement(analyzer *analyzer.OpenAI, evaluationPromptTemp
 	return eval, nil
 }
 
+// SetLanguage selects the improvement markers and period names of a language ("zh" or "en"); markers
+// fields that are set override the built-in ones
+func (e *Evaluator) SetLanguage(language string, markers Markers) error {
+	defaults, err := MarkersForLanguage(language)
+	if err != nil {
+		return err
+	}
+	e.language = language
+	e.markers = markers.withDefaults(defaults)
+	return nil
+}
+
 func (e *Evaluator) EvaluateReport(summary *storage.PeriodSummary, screenshotRecords map[string]*storage.ScreenshotRecord) (string, error) {
 	if summary == nil {
 		return "", fmt.Errorf("summary is nil")
@@ -82,6 +99,7 @@ func (e *Evaluator) EvaluateReport(summary *storage.PeriodSummary, screenshotRec
 	req := analyzer.VisionRequest{
 		Model:               e.analyzer.AnalysisModel,
 		MaxCompletionTokens: e.analyzer.MaxCompletionTokens,
+		Purpose:             analyzer.PurposeEvaluation,
 		Messages: []analyzer.Message{
 			{
 				Role: "user",
@@ -105,124 +123,52 @@ func (e *Evaluator) EvaluateReport(summary *storage.PeriodSummary, screenshotRec
 	return report, nil
 }
 
+// callAPI calls the API, retrying failed calls according to the analyzer's retry policy
 func (e *Evaluator) callAPI(req analyzer.VisionRequest) (string, error) {
-	const maxRetries = 3
-	const initialBackoff = 2 * time.Second
-
-	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 2s, 4s, 8s
-			backoff := initialBackoff * time.Duration(1<<uint(attempt-1))
-			logger.GetLogger().Infof("Retrying API request (attempt %d/%d, backoff: %v)",
-				attempt+1, maxRetries+1, backoff)
-			time.Sleep(backoff)
-		}
-
-		result, err := e.callAPISingle(req)
-		if err == nil {
-			return result, nil
-		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !isRetryableError(err) {
-			return "", err
-		}
-	}
-
-	return "", fmt.Errorf("API call failed after %d retries: %w", maxRetries+1, lastErr)
-}
-
-// isRetryableError checks if an error is retryable (temporary network/server errors)
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := err.Error()
-	// Check for retryable HTTP status codes
-	retryableStatusCodes := []string{
-		"status 502", // Bad Gateway
-		"status 503", // Service Unavailable
-		"status 504", // Gateway Timeout
-		"status 429", // Too Many Requests
-		"status 500", // Internal Server Error
-	}
-
-	for _, code := range retryableStatusCodes {
-		if strings.Contains(errStr, code) {
-			return true
-		}
-	}
-
-	// Check for network errors
-	networkErrors := []string{
-		"failed to send request",
-		"timeout",
-		"connection reset",
-		"connection refused",
-		"no such host",
-	}
-
-	for _, netErr := range networkErrors {
-		if strings.Contains(strings.ToLower(errStr), netErr) {
-			return true
-		}
-	}
-
-	return false
+	var result string
+	err := e.analyzer.Retry.Retry(func() error {
+		var err error
+		result, err = e.callAPISingle(req)
+		return err
+	}, func(retry int, backoff time.Duration, err error) {
+		logger.GetLogger().Infof("Retrying API request (retry %d/%d, backoff: %v)", retry, e.analyzer.Retry.MaxRetries, backoff)
+	})
+	return result, err
 }
 
 // callAPISingle makes a single API call without retry
 func (e *Evaluator) callAPISingle(req analyzer.VisionRequest) (string, error) {
-	reqBody, err := json.Marshal(req)
+	endpoint, reqBody, err := e.analyzer.EncodeRequest(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	httpReq, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(reqBody))
+	e.analyzer.RecordUpload(endpoint, req)
+	httpReq, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.analyzer.APIKey))
+	e.analyzer.SetAuthHeader(httpReq)
 
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
+	client := e.analyzer.NewHTTPClient(5 * time.Minute)
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", analyzer.NetworkError("failed to send request", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", analyzer.NetworkError("failed to read response", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return "", analyzer.NewAPIError(resp.StatusCode, string(body))
 	}
 
-	var visionResp analyzer.VisionResponse
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&visionResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(visionResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
-	}
-
-	content := visionResp.Choices[0].Message.Content
-	if content == "" {
-		return "", fmt.Errorf("empty content in response")
-	}
-
-	return content, nil
+	return e.analyzer.DecodeResponse(body)
 }
 
 func (e *Evaluator) buildEvaluationPrompt(summary *storage.PeriodSummary, screenshotRecords map[string]*storage.ScreenshotRecord) string {
@@ -298,10 +244,10 @@ func (e *Evaluator) formatEvaluationReport(summary *storage.PeriodSummary, evalu
 	// Header using template
 	header := fmt.Sprintf(e.reportFormatTemplate,
 		summary.PeriodType,
-		summary.StartTime.Format("2006-01-02 15:04:05"),
-		summary.EndTime.Format("2006-01-02 15:04:05"),
+		datefmt.DateTime(summary.StartTime),
+		datefmt.DateTime(summary.EndTime),
 		screenshotCount,
-		time.Now().Format("2006-01-02 15:04:05"),
+		datefmt.DateTime(time.Now()),
 		evaluationResult)
 	sb.WriteString(header)
 	sb.WriteString("\n\n")
@@ -400,6 +346,7 @@ func (e *Evaluator) ImproveReport(summary *storage.PeriodSummary, evaluationRepo
 	req := analyzer.VisionRequest{
 		Model:               e.analyzer.AnalysisModel,
 		MaxCompletionTokens: e.analyzer.MaxCompletionTokens,
+		Purpose:             analyzer.PurposeEvaluation,
 		Messages: []analyzer.Message{
 			{
 				Role: "user",
@@ -434,6 +381,9 @@ func (e *Evaluator) buildImprovementPrompt(summary *storage.PeriodSummary, evalu
 	}
 
 	periodTypeName := getPeriodTypeName(summary.PeriodType)
+	if e.language == "en" {
+		periodTypeName = summary.PeriodType
+	}
 
 	// Build screenshot source information using template
 	// Filter out desktop/lock screen screenshots as they are not work activities
@@ -464,6 +414,10 @@ func (e *Evaluator) buildImprovementPrompt(summary *storage.PeriodSummary, evalu
 		"Summary":          summary.Summary,
 		"Analysis":         summary.Analysis,
 		"ScreenshotSource": screenshotSource,
+		// Section markers, so the output format in the template always matches the parser
+		"SummaryMarker":  e.markers.Summary,
+		"AnalysisMarker": e.markers.Analysis,
+		"NotesMarker":    e.markers.Notes,
 	}
 
 	// Execute template
@@ -527,39 +481,19 @@ func (e *Evaluator) buildScreenshotSourceContent(screenshotRecords map[string]*s
 func (e *Evaluator) parseImprovedReport(result string) *ImprovedReport {
 	improved := &ImprovedReport{}
 
-	// Parse the result to extract:
-	// 【改进后的事实总结】...【改进后的改进建议】...【改进说明】...
+	// Parse the result to extract the sections headed by the markers:
+	// 【改进后的事实总结】...【改进后的改进建议】...【改进说明】... (or the configured markers)
 
 	lines := strings.Split(result, "\n")
 	var currentSection string
 	var currentContent strings.Builder
 
 	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		if strings.Contains(trimmed, "【改进后的事实总结】") {
-			if currentSection != "" {
-				e.setSectionContent(improved, currentSection, currentContent.String())
-			}
-			currentSection = "summary"
-			currentContent.Reset()
-			continue
-		}
-
-		if strings.Contains(trimmed, "【改进后的改进建议】") {
+		if section := e.markers.matchSection(line); section != "" {
 			if currentSection != "" {
 				e.setSectionContent(improved, currentSection, currentContent.String())
 			}
-			currentSection = "analysis"
-			currentContent.Reset()
-			continue
-		}
-
-		if strings.Contains(trimmed, "【改进说明】") {
-			if currentSection != "" {
-				e.setSectionContent(improved, currentSection, currentContent.String())
-			}
-			currentSection = "notes"
+			currentSection = section
 			currentContent.Reset()
 			continue
 		}
@@ -594,97 +528,7 @@ func (e *Evaluator) setSectionContent(improved *ImprovedReport, section, content
 
 // isDesktopOrLockScreenAnalysis checks if the analysis content indicates desktop or lock screen state
 // Returns true if the analysis suggests desktop/lock screen, false otherwise
-// This function uses strict matching to avoid false positives with work-related screenshots
+// The patterns are configured per language (desktop_lock), see package idlescreen
 func isDesktopOrLockScreenAnalysis(analysis string) bool {
-	if analysis == "" {
-		return true // Empty analysis usually means desktop/lock screen was detected
-	}
-
-	// Convert to lowercase for case-insensitive matching
-	lowerAnalysis := strings.ToLower(analysis)
-
-	// Extract summary section for more precise matching
-	// Only check the summary section to avoid false positives from detailed descriptions
-	var summaryPart string
-	if strings.Contains(lowerAnalysis, "【摘要】") {
-		summaryStart := strings.Index(lowerAnalysis, "【摘要】")
-		summaryEnd := strings.Index(lowerAnalysis, "【详细论述】")
-		if summaryEnd > summaryStart {
-			summaryPart = lowerAnalysis[summaryStart:summaryEnd]
-		} else {
-			// If no detailed section, use everything after summary marker
-			summaryPart = lowerAnalysis[summaryStart:]
-		}
-	} else {
-		// If no summary marker, use first 200 chars as summary
-		if len(lowerAnalysis) > 200 {
-			summaryPart = lowerAnalysis[:200]
-		} else {
-			summaryPart = lowerAnalysis
-		}
-	}
-
-	// Strict patterns that indicate desktop or lock screen state
-	// These patterns must appear in the summary section to avoid false positives
-	// We use more specific patterns to avoid matching work-related activities
-	strictPatterns := []string{
-		"锁屏界面",
-		"锁屏状态",
-		"处于锁屏",
-		"电脑桌面",
-		"桌面界面",
-		"桌面状态",
-		"系统登录",
-		"系统锁屏",
-		"等待解锁",
-		"等待输入密码",
-		"没有打开任何应用程序",
-		"没有打开任何应用",
-		"lock screen",
-		"lockscreen",
-		"desktop",
-	}
-
-	// Check for strict patterns in summary
-	for _, pattern := range strictPatterns {
-		if strings.Contains(summaryPart, pattern) {
-			return true
-		}
-	}
-
-	// Special handling for "登录界面" - only match if it's system login, not application login
-	// Pattern: "登录界面" but NOT "游戏.*登录界面" or "应用.*登录界面"
-	if strings.Contains(summaryPart, "登录界面") {
-		// Exclude game or application login screens
-		if !strings.Contains(summaryPart, "游戏") &&
-			!strings.Contains(summaryPart, "应用") &&
-			!strings.Contains(summaryPart, "软件") {
-			return true
-		}
-	}
-
-	// Special handling for "输入密码" - only match if it's system unlock, not application login
-	if strings.Contains(summaryPart, "输入密码") {
-		// Check if it's about unlocking system (lock screen context)
-		if strings.Contains(summaryPart, "解锁") ||
-			strings.Contains(summaryPart, "锁屏") ||
-			strings.Contains(summaryPart, "系统") {
-			return true
-		}
-		// If it's about game/application login, don't filter
-		if strings.Contains(summaryPart, "游戏") ||
-			strings.Contains(summaryPart, "应用") {
-			return false
-		}
-	}
-
-	// Additional check: if summary is very short (< 100 chars) and contains lock/desktop keywords,
-	// it's likely a non-work state
-	if len(summaryPart) < 100 {
-		if strings.Contains(summaryPart, "锁屏") || strings.Contains(summaryPart, "桌面") {
-			return true
-		}
-	}
-
-	return false
+	return idlescreen.IsIdleAnalysis(analysis)
 }
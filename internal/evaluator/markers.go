@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers are the section headings the improvement prompt asks the model to emit and the parser looks for
+type Markers struct {
+	Summary  string // Improved fact summary
+	Analysis string // Improved suggestions
+	Notes    string // What was changed and why
+}
+
+// Built-in marker sets by language
+var (
+	ChineseMarkers = Markers{Summary: "【改进后的事实总结】", Analysis: "【改进后的改进建议】", Notes: "【改进说明】"}
+	EnglishMarkers = Markers{Summary: "[Improved Summary]", Analysis: "[Improved Analysis]", Notes: "[Improvement Notes]"}
+)
+
+// MarkersForLanguage returns the built-in markers of a language ("zh" or "en")
+func MarkersForLanguage(language string) (Markers, error) {
+	switch language {
+	case "", "zh":
+		return ChineseMarkers, nil
+	case "en":
+		return EnglishMarkers, nil
+	default:
+		return Markers{}, fmt.Errorf("unsupported evaluator language: %s (must be 'zh' or 'en')", language)
+	}
+}
+
+// withDefaults fills markers left empty from defaults
+func (m Markers) withDefaults(defaults Markers) Markers {
+	if m.Summary == "" {
+		m.Summary = defaults.Summary
+	}
+	if m.Analysis == "" {
+		m.Analysis = defaults.Analysis
+	}
+	if m.Notes == "" {
+		m.Notes = defaults.Notes
+	}
+	return m
+}
+
+// matchSection returns the section ("summary", "analysis" or "notes") whose marker heads the line, or ""
+// Matching ignores markdown decoration (#, **), bracket style, case and a trailing colon, and accepts the
+// built-in markers of every language, so models that restyle the heading are still understood
+func (m Markers) matchSection(line string) string {
+	label := markerLabel(line)
+	if label == "" {
+		return ""
+	}
+	for _, set := range []Markers{m, ChineseMarkers, EnglishMarkers} {
+		// A line containing a full-width bracket marker anywhere (e.g. "### 【改进说明】") is a heading
+		for section, marker := range map[string]string{"summary": set.Summary, "analysis": set.Analysis, "notes": set.Notes} {
+			if strings.HasPrefix(marker, "【") && strings.Contains(line, marker) {
+				return section
+			}
+		}
+		switch label {
+		case markerLabel(set.Summary):
+			return "summary"
+		case markerLabel(set.Analysis):
+			return "analysis"
+		case markerLabel(set.Notes):
+			return "notes"
+		}
+	}
+	return ""
+}
+
+// markerLabel normalizes a heading line to its bare lower-case label
+func markerLabel(line string) string {
+	label := strings.TrimSpace(line)
+	label = strings.TrimLeft(label, "# ")
+	label = strings.Trim(label, "*_ ")
+	label = strings.TrimRight(label, ":： ")
+	label = strings.Trim(label, "*_ ")
+	for _, pair := range [][2]string{{"【", "】"}, {"[", "]"}} {
+		if strings.HasPrefix(label, pair[0]) && strings.HasSuffix(label, pair[1]) {
+			label = strings.TrimSuffix(strings.TrimPrefix(label, pair[0]), pair[1])
+			break
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(label))
+}
@@ -0,0 +1,48 @@
+package evaluator
+
+import "testing"
+
+func TestParseImprovedReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		markers  Markers
+		result   string
+	}{
+		{
+			name:   "chinese markers",
+			result: "【改进后的事实总结】\n编写代码\n\n【改进后的改进建议】\n减少切换\n【改进说明】\n删除了推测",
+		},
+		{
+			name:   "chinese markers as markdown headings",
+			result: "### 【改进后的事实总结】\n编写代码\n**【改进后的改进建议】**\n减少切换\n## 【改进说明】：\n删除了推测",
+		},
+		{
+			name:     "english markers restyled by the model",
+			language: "en",
+			result:   "## Improved Summary\n编写代码\n**[improved analysis]**:\n减少切换\n[Improvement Notes]\n删除了推测",
+		},
+		{
+			name:    "custom markers",
+			markers: Markers{Summary: "<<总结>>", Analysis: "<<建议>>", Notes: "<<说明>>"},
+			result:  "<<总结>>\n编写代码\n<<建议>>\n减少切换\n<<说明>>\n删除了推测",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEvaluator(nil, "", "", "", "", "")
+			if err := e.SetLanguage(tt.language, tt.markers); err != nil {
+				t.Fatalf("SetLanguage() error = %v", err)
+			}
+			got := e.parseImprovedReport(tt.result)
+			if got.Summary != "编写代码" || got.Analysis != "减少切换" || got.ImprovementNotes != "删除了推测" {
+				t.Errorf("parseImprovedReport() = %+v", got)
+			}
+		})
+	}
+
+	if err := NewEvaluator(nil, "", "", "", "", "").SetLanguage("fr", Markers{}); err == nil {
+		t.Errorf("SetLanguage() with an unsupported language should fail")
+	}
+}
@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Scores are the ratings (out of 10) parsed from an evaluation report; a dimension the model did not
+// rate is 0
+type Scores struct {
+	Accuracy  float64
+	Relevance float64
+	Depth     float64
+	Overall   float64
+}
+
+var (
+	scoreSectionPattern = regexp.MustCompile(`【(准确性|相关性|深度|总体)评[估分]】`)
+	scoreValuePattern   = regexp.MustCompile(`评分\**\s*[:：]\s*\**\s*(\d+(?:\.\d+)?)\s*/\s*10`)
+)
+
+// ParseScores extracts the per-dimension and overall ratings from an evaluation report
+func ParseScores(report string) Scores {
+	var scores Scores
+	sections := scoreSectionPattern.FindAllStringSubmatchIndex(report, -1)
+	for i, loc := range sections {
+		end := len(report)
+		if i+1 < len(sections) {
+			end = sections[i+1][0]
+		}
+		match := scoreValuePattern.FindStringSubmatch(report[loc[1]:end])
+		if match == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[1], 64)
+		if err != nil || value > 10 {
+			continue
+		}
+		switch report[loc[2]:loc[3]] {
+		case "准确性":
+			scores.Accuracy = value
+		case "相关性":
+			scores.Relevance = value
+		case "深度":
+			scores.Depth = value
+		case "总体":
+			scores.Overall = value
+		}
+	}
+
+	// Without an overall rating, use the weighting the evaluation prompt asks for
+	if scores.Overall == 0 && scores.Accuracy > 0 && scores.Relevance > 0 && scores.Depth > 0 {
+		scores.Overall = scores.Accuracy*0.4 + scores.Relevance*0.3 + scores.Depth*0.3
+	}
+	return scores
+}
+
+// Rated reports whether the overall rating could be determined
+func (s Scores) Rated() bool {
+	return s.Overall > 0
+}
+
+// Sub returns the per-dimension difference s - base
+func (s Scores) Sub(base Scores) Scores {
+	return Scores{
+		Accuracy:  s.Accuracy - base.Accuracy,
+		Relevance: s.Relevance - base.Relevance,
+		Depth:     s.Depth - base.Depth,
+		Overall:   s.Overall - base.Overall,
+	}
+}
+
+// String formats the ratings, e.g. "准确性 7 / 相关性 8 / 深度 6 / 综合 7.3"
+func (s Scores) String() string {
+	var parts []string
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{{"准确性", s.Accuracy}, {"相关性", s.Relevance}, {"深度", s.Depth}, {"综合", s.Overall}} {
+		parts = append(parts, p.name+" "+strconv.FormatFloat(math.Round(p.value*10)/10, 'f', -1, 64))
+	}
+	return strings.Join(parts, " / ")
+}
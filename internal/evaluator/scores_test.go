@@ -0,0 +1,39 @@
+package evaluator
+
+import "testing"
+
+func TestParseScores(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   Scores
+	}{
+		{
+			name: "all sections",
+			report: "【准确性评估】\n评分：7/10\n详细说明：评分：2/10 不会被误读\n" +
+				"【相关性评估】\n评分: 8 / 10\n【深度评估】\n**评分**：6/10\n【总体评分】\n综合评分：7.5/10\n",
+			want: Scores{Accuracy: 7, Relevance: 8, Depth: 6, Overall: 7.5},
+		},
+		{
+			name:   "overall computed from dimensions",
+			report: "【准确性评估】\n评分：5/10\n【相关性评估】\n评分：10/10\n【深度评估】\n评分：5/10\n",
+			want:   Scores{Accuracy: 5, Relevance: 10, Depth: 5, Overall: 6.5},
+		},
+		{
+			name:   "no scores",
+			report: "模型没有按格式输出",
+			want:   Scores{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseScores(tt.report); got != tt.want {
+				t.Errorf("ParseScores() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if (Scores{}).Rated() {
+		t.Errorf("empty scores should not be rated")
+	}
+}
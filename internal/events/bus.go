@@ -0,0 +1,156 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event levels
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Event components
+const (
+	ComponentCapture  = "capture"
+	ComponentAnalyzer = "analyzer"
+	ComponentSummary  = "summary"
+	ComponentDaemon   = "daemon"
+)
+
+// Event is a structured activity event published by the daemon
+type Event struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Component string    `json:"component"`
+	Message   string    `json:"message"`
+	PeriodKey string    `json:"period_key,omitempty"` // Set for summary-generated events
+}
+
+// Bus is an in-process publish/subscribe event bus
+// Slow subscribers never block publishers: events are dropped when a subscriber's buffer is full
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan Event
+	nextID      int
+	recent      []Event
+	maxRecent   int
+}
+
+// NewBus creates an event bus keeping the last maxRecent events for late subscribers
+func NewBus(maxRecent int) *Bus {
+	return &Bus{
+		subscribers: make(map[int]chan Event),
+		maxRecent:   maxRecent,
+	}
+}
+
+// Publish sends an event to all subscribers
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxRecent > 0 {
+		b.recent = append(b.recent, event)
+		if len(b.recent) > b.maxRecent {
+			b.recent = b.recent[len(b.recent)-b.maxRecent:]
+		}
+	}
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is too slow, drop the event
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns its ID and event channel
+func (b *Bus) Subscribe(buffer int) (int, <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subscribers[id] = ch
+	return id, ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Bus) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ch, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(ch)
+	}
+}
+
+// Recent returns up to n most recent events (oldest first)
+func (b *Bus) Recent(n int) []Event {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if n <= 0 || n > len(b.recent) {
+		n = len(b.recent)
+	}
+	result := make([]Event, n)
+	copy(result, b.recent[len(b.recent)-n:])
+	return result
+}
+
+var defaultBus = NewBus(200)
+
+// Default returns the process-wide event bus
+func Default() *Bus {
+	return defaultBus
+}
+
+// Emit publishes a formatted event on the default bus
+func Emit(level, component, format string, args ...interface{}) {
+	defaultBus.Publish(Event{
+		Time:      time.Now(),
+		Level:     level,
+		Component: component,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// EmitSummaryGenerated publishes a summary-generated event carrying the period key,
+// so subscribers (e.g. response caches) can react to the changed period
+func EmitSummaryGenerated(periodType, periodKey string) {
+	defaultBus.Publish(Event{
+		Time:      time.Now(),
+		Level:     LevelInfo,
+		Component: ComponentSummary,
+		Message:   fmt.Sprintf("%s summary generated: %s", periodType, periodKey),
+		PeriodKey: periodKey,
+	})
+}
+
+// LevelRank returns the severity rank of a level for filtering (unknown levels rank as info)
+func LevelRank(level string) int {
+	switch level {
+	case LevelDebug:
+		return 0
+	case LevelInfo:
+		return 1
+	case LevelWarn, "warning":
+		return 2
+	case LevelError, "fatal", "panic":
+		return 3
+	default:
+		return 1
+	}
+}
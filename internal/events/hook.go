@@ -0,0 +1,40 @@
+package events
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// LogHook forwards warning and error log entries to the event bus,
+// so problems logged outside of explicit events still show up in `tail`
+type LogHook struct {
+	bus *Bus
+}
+
+// NewLogHook creates a logrus hook publishing to the default bus
+func NewLogHook() *LogHook {
+	return &LogHook{bus: defaultBus}
+}
+
+func (h *LogHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (h *LogHook) Fire(entry *logrus.Entry) error {
+	component := ComponentDaemon
+	if c, ok := entry.Data["component"].(string); ok && c != "" {
+		component = c
+	}
+
+	level := entry.Level.String()
+	if entry.Level == logrus.WarnLevel {
+		level = LevelWarn
+	}
+
+	h.bus.Publish(Event{
+		Time:      entry.Time,
+		Level:     level,
+		Component: component,
+		Message:   entry.Message,
+	})
+	return nil
+}
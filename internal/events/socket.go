@@ -0,0 +1,196 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Handler handles a single status socket command
+// args are the whitespace-separated words following the command name
+type Handler func(conn net.Conn, args []string) error
+
+// Server serves the daemon status socket (Unix domain socket, line-based commands)
+type Server struct {
+	path     string
+	bus      *Bus
+	listener net.Listener
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a status socket server bound to the default bus
+func NewServer(socketPath string) *Server {
+	s := &Server{
+		path:     socketPath,
+		bus:      defaultBus,
+		handlers: make(map[string]Handler),
+	}
+	s.Handle("tail", s.handleTail)
+	s.Handle("status", s.handleStatus)
+	return s
+}
+
+// Handle registers a handler for a command
+func (s *Server) Handle(command string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = handler
+}
+
+// Start begins listening on the socket path
+func (s *Server) Start() error {
+	// Remove stale socket left over from a crashed daemon
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, err := net.Dial("unix", s.path); err == nil {
+			conn.Close()
+			return fmt.Errorf("status socket %s is already in use", s.path)
+		}
+		os.Remove(s.path)
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on status socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to set status socket permissions: %w", err)
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn)
+		}
+	}()
+
+	return nil
+}
+
+// Stop closes the listener and removes the socket file
+func (s *Server) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.wg.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	s.mu.RLock()
+	handler, ok := s.handlers[fields[0]]
+	s.mu.RUnlock()
+	if !ok {
+		fmt.Fprintf(conn, "ERROR unknown command: %s\n", fields[0])
+		return
+	}
+
+	if err := handler(conn, fields[1:]); err != nil {
+		fmt.Fprintf(conn, "ERROR %v\n", err)
+	}
+}
+
+// handleTail streams recent and live events as JSON lines until the client disconnects
+func (s *Server) handleTail(conn net.Conn, args []string) error {
+	backlog := 20
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n >= 0 {
+			backlog = n
+		}
+	}
+
+	id, ch := s.bus.Subscribe(256)
+	defer s.bus.Unsubscribe(id)
+
+	encoder := json.NewEncoder(conn)
+	if backlog > 0 {
+		for _, event := range s.bus.Recent(backlog) {
+			if err := encoder.Encode(event); err != nil {
+				return nil
+			}
+		}
+	}
+
+	// Detect client disconnect
+	closed := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := encoder.Encode(event); err != nil {
+				return nil
+			}
+		case <-closed:
+			return nil
+		}
+	}
+}
+
+// Tail connects to the status socket and calls fn for each received event
+func Tail(socketPath string, backlog int, fn func(Event)) error {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to status socket %s (is the daemon running?): %w", socketPath, err)
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "tail %d\n", backlog); err != nil {
+		return fmt.Errorf("failed to send tail command: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "ERROR ") {
+			return fmt.Errorf("status socket error: %s", strings.TrimPrefix(line, "ERROR "))
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		fn(event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read from status socket: %w", err)
+	}
+	return nil
+}
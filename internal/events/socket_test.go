@@ -0,0 +1,59 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServerTail(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "status.sock")
+	server := NewServer(socketPath)
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	for i := 1; i <= 3; i++ {
+		Emit(LevelInfo, ComponentCapture, "backlog %d", i)
+	}
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "tail 2\n")
+
+	scanner := bufio.NewScanner(conn)
+	next := func() Event {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatalf("tail stream ended: %v", scanner.Err())
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", scanner.Text(), err)
+		}
+		return event
+	}
+
+	// The backlog holds the most recent events, oldest first
+	for _, want := range []string{"backlog 2", "backlog 3"} {
+		if event := next(); event.Message != want {
+			t.Errorf("backlog event = %q, want %q", event.Message, want)
+		}
+	}
+
+	// The subscriber is registered before the backlog is sent, so this event is streamed live
+	EmitSummaryGenerated("day", "day:2025-12-09")
+	event := next()
+	if event.Component != ComponentSummary || event.PeriodKey != "day:2025-12-09" {
+		t.Errorf("live event = %+v, want the summary-generated event", event)
+	}
+}
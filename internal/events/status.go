@@ -0,0 +1,71 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Daemon status flags, served by the status socket "status" command
+const (
+	StatusScreenRecording = "screen_recording" // "ok" or "lost" (captures blank or failing)
+	StatusAnalysis        = "analysis"         // "running" while a batch analysis is in progress, otherwise "idle"
+)
+
+var (
+	statusMu    sync.RWMutex
+	statusFlags = make(map[string]string)
+)
+
+// SetStatus sets a daemon status flag
+func SetStatus(key, value string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statusFlags[key] = value
+}
+
+// StatusFlags returns a copy of the daemon status flags
+func StatusFlags() map[string]string {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	flags := make(map[string]string, len(statusFlags))
+	for k, v := range statusFlags {
+		flags[k] = v
+	}
+	return flags
+}
+
+// handleStatus writes the daemon status flags as a single JSON line
+func (s *Server) handleStatus(conn net.Conn, args []string) error {
+	return json.NewEncoder(conn).Encode(StatusFlags())
+}
+
+// QueryStatus connects to the status socket and returns the daemon status flags
+func QueryStatus(socketPath string) (map[string]string, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to status socket %s (is the daemon running?): %w", socketPath, err)
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "status\n"); err != nil {
+		return nil, fmt.Errorf("failed to send status command: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from status socket: %w", err)
+	}
+	if strings.HasPrefix(line, "ERROR ") {
+		return nil, fmt.Errorf("status socket error: %s", strings.TrimSpace(strings.TrimPrefix(line, "ERROR ")))
+	}
+
+	var flags map[string]string
+	if err := json.Unmarshal([]byte(line), &flags); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon status: %w", err)
+	}
+	return flags, nil
+}
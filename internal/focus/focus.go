@@ -0,0 +1,181 @@
+package focus
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Activity categories used for the category mix
+const (
+	CategoryCoding        = "coding"
+	CategoryDocs          = "docs"
+	CategoryCommunication = "communication"
+	CategoryMeeting       = "meeting"
+	CategoryEntertainment = "entertainment"
+	CategoryIdle          = "idle"
+	CategoryOther         = "other"
+)
+
+// categoryKeywords maps categories to keywords matched (case-insensitive) against screenshot analyses
+// Order matters: the first matching category wins
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{CategoryIdle, []string{"锁屏", "桌面壁纸", "屏幕保护", "lock screen", "screensaver"}},
+	{CategoryMeeting, []string{"会议", "腾讯会议", "zoom", "google meet", "teams 会议", "共享屏幕", "视频通话"}},
+	{CategoryEntertainment, []string{"视频网站", "bilibili", "b站", "youtube", "抖音", "微博", "游戏", "netflix", "小红书", "刷新闻"}},
+	{CategoryCoding, []string{"代码", "编程", "调试", "终端", "terminal", "vs code", "vscode", "cursor", "goland", "intellij", "xcode", "github", "gitlab", "pull request", "编译", "单元测试"}},
+	{CategoryCommunication, []string{"微信", "飞书", "钉钉", "slack", "邮件", "邮箱", "outlook", "mail", "聊天", "消息"}},
+	{CategoryDocs, []string{"文档", "阅读", "pdf", "notion", "confluence", "wiki", "表格", "设计稿", "figma", "论文", "资料"}},
+}
+
+// focusWeights is how much each category contributes to the category mix score
+var focusWeights = map[string]float64{
+	CategoryCoding:        1.0,
+	CategoryDocs:          1.0,
+	CategoryMeeting:       0.6,
+	CategoryCommunication: 0.4,
+	CategoryOther:         0.5,
+	CategoryEntertainment: 0.0,
+}
+
+// Sample is a single capture with its analysis
+type Sample struct {
+	Time     time.Time
+	Analysis string
+}
+
+// Stats is the focus statistics of a day
+type Stats struct {
+	Score                 int                // 0-100
+	ActiveMinutes         float64            // Non-idle captured time
+	DeepWorkMinutes       float64            // Time in sessions at least DeepWorkMinutes long
+	LongestSessionMinutes float64            // Longest uninterrupted single-category session
+	ContextSwitches       int                // Category changes between consecutive active samples
+	CategoryMinutes       map[string]float64 // Minutes per category
+}
+
+// Classify returns the activity category of a screenshot analysis
+func Classify(analysis string) string {
+	text := strings.ToLower(analysis)
+	if strings.TrimSpace(text) == "" {
+		return CategoryIdle
+	}
+	for _, ck := range categoryKeywords {
+		for _, kw := range ck.keywords {
+			if strings.Contains(text, kw) {
+				return ck.category
+			}
+		}
+	}
+	return CategoryOther
+}
+
+// Compute calculates the focus statistics from chronologically ordered samples
+// interval is the capture interval (time represented by one sample);
+// deepWorkMinutes is the minimum session length counted as deep work
+func Compute(samples []Sample, interval time.Duration, deepWorkMinutes float64) *Stats {
+	stats := &Stats{CategoryMinutes: make(map[string]float64)}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	step := interval.Minutes()
+	// A gap longer than this breaks a session (missed captures, lunch, ...)
+	maxGap := 2 * interval
+
+	var prevCategory string
+	var prevTime time.Time
+	var sessionMinutes float64
+	endSession := func() {
+		if sessionMinutes >= deepWorkMinutes {
+			stats.DeepWorkMinutes += sessionMinutes
+		}
+		if sessionMinutes > stats.LongestSessionMinutes {
+			stats.LongestSessionMinutes = sessionMinutes
+		}
+		sessionMinutes = 0
+	}
+
+	for _, sample := range samples {
+		category := Classify(sample.Analysis)
+		if category == CategoryIdle {
+			endSession()
+			prevCategory = ""
+			continue
+		}
+
+		stats.ActiveMinutes += step
+		stats.CategoryMinutes[category] += step
+
+		contiguous := prevCategory != "" && sample.Time.Sub(prevTime) <= maxGap
+		if contiguous && category != prevCategory {
+			stats.ContextSwitches++
+		}
+		if !contiguous || category != prevCategory || category == CategoryEntertainment {
+			endSession()
+		}
+		if category != CategoryEntertainment {
+			sessionMinutes += step
+		}
+
+		prevCategory = category
+		prevTime = sample.Time
+	}
+	endSession()
+
+	stats.Score = score(stats)
+	return stats
+}
+
+// score combines deep work ratio (40), context switch rate (30) and category mix (30) into 0-100
+func score(stats *Stats) int {
+	if stats.ActiveMinutes <= 0 {
+		return 0
+	}
+
+	deepRatio := stats.DeepWorkMinutes / stats.ActiveMinutes
+
+	// 12+ switches per active hour counts as fully fragmented
+	switchesPerHour := float64(stats.ContextSwitches) / (stats.ActiveMinutes / 60)
+	switchScore := math.Max(0, 1-switchesPerHour/12)
+
+	var weighted float64
+	for category, minutes := range stats.CategoryMinutes {
+		weighted += focusWeights[category] * minutes
+	}
+	mixScore := weighted / stats.ActiveMinutes
+
+	return int(math.Round(40*deepRatio + 30*switchScore + 30*mixScore))
+}
+
+// Streak returns the number of consecutive days (ending with the last element) whose score is at least threshold
+// scores must be ordered by date ascending without gaps; a missing day should be passed as 0
+func Streak(scores []int, threshold int) int {
+	streak := 0
+	for i := len(scores) - 1; i >= 0; i-- {
+		if scores[i] < threshold {
+			break
+		}
+		streak++
+	}
+	return streak
+}
+
+// Sparkline renders scores (0-100) as a unicode sparkline
+func Sparkline(scores []int) string {
+	bars := []rune("▁▂▃▄▅▆▇█")
+	var sb strings.Builder
+	for _, s := range scores {
+		idx := s * (len(bars) - 1) / 100
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(bars) {
+			idx = len(bars) - 1
+		}
+		sb.WriteRune(bars[idx])
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package focus
+
+import (
+	"testing"
+	"time"
+)
+
+func samplesOf(start time.Time, analyses ...string) []Sample {
+	samples := make([]Sample, len(analyses))
+	for i, a := range analyses {
+		samples[i] = Sample{Time: start.Add(time.Duration(i) * time.Minute), Analysis: a}
+	}
+	return samples
+}
+
+func repeat(s string, n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = s
+	}
+	return result
+}
+
+func TestComputeFocusedDay(t *testing.T) {
+	start := time.Date(2025, 12, 9, 9, 0, 0, 0, time.Local)
+	stats := Compute(samplesOf(start, repeat("用户在 VS Code 中编写代码", 60)...), time.Minute, 25)
+
+	if stats.ActiveMinutes != 60 {
+		t.Errorf("ActiveMinutes = %v, want 60", stats.ActiveMinutes)
+	}
+	if stats.DeepWorkMinutes != 60 || stats.LongestSessionMinutes != 60 {
+		t.Errorf("DeepWork/Longest = %v/%v, want 60/60", stats.DeepWorkMinutes, stats.LongestSessionMinutes)
+	}
+	if stats.ContextSwitches != 0 {
+		t.Errorf("ContextSwitches = %d, want 0", stats.ContextSwitches)
+	}
+	if stats.Score != 100 {
+		t.Errorf("Score = %d, want 100", stats.Score)
+	}
+}
+
+func TestComputeFragmentedDay(t *testing.T) {
+	start := time.Date(2025, 12, 9, 9, 0, 0, 0, time.Local)
+	var analyses []string
+	for i := 0; i < 30; i++ {
+		analyses = append(analyses, "用户在编写代码", "用户在微信聊天")
+	}
+	stats := Compute(samplesOf(start, analyses...), time.Minute, 25)
+
+	if stats.ContextSwitches != 59 {
+		t.Errorf("ContextSwitches = %d, want 59", stats.ContextSwitches)
+	}
+	if stats.DeepWorkMinutes != 0 {
+		t.Errorf("DeepWorkMinutes = %v, want 0", stats.DeepWorkMinutes)
+	}
+	if stats.Score >= 30 {
+		t.Errorf("Score = %d, want < 30 for a fragmented day", stats.Score)
+	}
+}
+
+func TestStreak(t *testing.T) {
+	if got := Streak([]int{80, 40, 70, 65, 90}, 60); got != 3 {
+		t.Errorf("Streak = %d, want 3", got)
+	}
+	if got := Streak([]int{80, 50}, 60); got != 0 {
+		t.Errorf("Streak = %d, want 0", got)
+	}
+}
@@ -0,0 +1,205 @@
+package gallery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+// DefaultThumbnailWidth is the default width of gallery thumbnails in pixels
+const DefaultThumbnailWidth = 320
+
+// Item is a single gallery entry
+type Item struct {
+	Time      string
+	ID        string
+	ImagePath string
+	Caption   string
+	Thumbnail template.URL // data URL, empty if the image could not be loaded
+	Status    string       // "ok", "failed", "skipped", "sampled", "pending", "missing"
+}
+
+// Build renders a self-contained HTML page with thumbnails of the given screenshots
+// in chronological order, each captioned by its analysis summary line
+func Build(title string, records []*storage.ScreenshotRecord, thumbnailWidth int) (string, error) {
+	if thumbnailWidth <= 0 {
+		thumbnailWidth = DefaultThumbnailWidth
+	}
+
+	items := make([]Item, 0, len(records))
+	for _, record := range records {
+		item := Item{
+			Time:      datefmt.Time(record.Timestamp),
+			ID:        record.ID,
+			ImagePath: record.ImagePath,
+			Caption:   CaptionLine(record.Analysis),
+			Status:    "ok",
+		}
+		switch {
+		case record.AnalysisUnavailable():
+			item.Status = "failed"
+			item.Caption = CaptionLine(record.AnalysisError)
+		case record.AnalysisStatus == storage.AnalysisSkipped:
+			item.Status = "skipped"
+			item.Caption = CaptionLine(record.AnalysisError)
+		case record.AnalysisStatus == storage.AnalysisSampledOut:
+			item.Status = "sampled"
+		case record.Analysis == "":
+			item.Status = "pending"
+		}
+
+		thumb, err := Thumbnail(record.ImagePath, thumbnailWidth)
+		if err != nil {
+			item.Status = "missing"
+		} else {
+			item.Thumbnail = template.URL("data:image/jpeg;base64," + thumb)
+		}
+		items = append(items, item)
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		Title       string
+		GeneratedAt string
+		Items       []Item
+	}{
+		Title:       title,
+		GeneratedAt: datefmt.DateTime(time.Now()),
+		Items:       items,
+	}
+	if err := pageTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render gallery: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// CaptionLine extracts the one-line summary from an analysis text
+// Prefers the line after 【摘要】, falls back to the first non-empty line
+func CaptionLine(analysis string) string {
+	analysis = strings.TrimSpace(analysis)
+	if analysis == "" {
+		return "（未分析或已跳过）"
+	}
+
+	if idx := strings.Index(analysis, "【摘要】"); idx >= 0 {
+		rest := analysis[idx+len("【摘要】"):]
+		if end := strings.Index(rest, "【"); end >= 0 {
+			rest = rest[:end]
+		}
+		for _, line := range strings.Split(rest, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+	}
+
+	for _, line := range strings.Split(analysis, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#*- "))
+		if line != "" {
+			return line
+		}
+	}
+	return analysis
+}
+
+// Thumbnail loads an image and returns a base64-encoded JPEG thumbnail of the given width
+func Thumbnail(imagePath string, width int) (string, error) {
+	thumb, err := ThumbnailJPEG(imagePath, width)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(thumb), nil
+}
+
+// ThumbnailJPEG loads an image and returns a JPEG thumbnail of the given width
+func ThumbnailJPEG(imagePath string, width int) ([]byte, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	dst := resize(src, width)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 70}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resize scales an image to the given width keeping aspect ratio (nearest-neighbor sampling)
+func resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= width || srcW == 0 {
+		return src
+	}
+	height := srcH * width / srcW
+	if height <= 0 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy := bounds.Min.Y + y*srcH/height
+		for x := 0; x < width; x++ {
+			sx := bounds.Min.X + x*srcW/width
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+var pageTemplate = template.Must(template.New("gallery").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", sans-serif; margin: 24px; background: #fafafa; color: #222; }
+h1 { font-size: 22px; }
+.meta { color: #888; font-size: 13px; margin-bottom: 16px; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(260px, 1fr)); gap: 16px; }
+.card { background: #fff; border-radius: 8px; box-shadow: 0 1px 3px rgba(0,0,0,.12); overflow: hidden; }
+.card img { width: 100%; display: block; }
+.card .noimg { height: 140px; display: flex; align-items: center; justify-content: center; color: #aaa; background: #eee; }
+.card .caption { padding: 8px 10px; font-size: 13px; line-height: 1.5; }
+.card .time { font-weight: 600; margin-right: 6px; }
+.status-failed .caption { color: #c0392b; }
+.status-pending .caption, .status-skipped .caption, .status-sampled .caption { color: #999; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">共 {{len .Items}} 张截图 · 生成时间 {{.GeneratedAt}}</div>
+<div class="grid">
+{{- range .Items}}
+<div class="card status-{{.Status}}" id="{{.ID}}">
+{{- if .Thumbnail}}
+<img src="{{.Thumbnail}}" alt="{{.Time}}" title="{{.ImagePath}}">
+{{- else}}
+<div class="noimg">图片缺失</div>
+{{- end}}
+<div class="caption"><span class="time">{{.Time}}</span>{{.Caption}}</div>
+</div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
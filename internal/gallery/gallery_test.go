@@ -0,0 +1,92 @@
+package gallery
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestCaptionLine(t *testing.T) {
+	tests := []struct {
+		analysis string
+		want     string
+	}{
+		{"", "（未分析或已跳过）"},
+		{"【摘要】\n  在 VS Code 中编辑 gallery.go\n【细节】\n打开了三个文件", "在 VS Code 中编辑 gallery.go"},
+		{"## 正在浏览 GitHub\n查看合并请求", "正在浏览 GitHub"},
+		{"\n\n- 阅读文档\n", "阅读文档"},
+	}
+	for _, tt := range tests {
+		if got := CaptionLine(tt.analysis); got != tt.want {
+			t.Errorf("CaptionLine(%q) = %q, want %q", tt.analysis, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "screen.png")
+	src := image.NewRGBA(image.Rect(0, 0, 640, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 640; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(imagePath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, err := ThumbnailJPEG(imagePath, 160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := decoded.Bounds().Size(); size.X != 160 || size.Y != 100 {
+		t.Errorf("thumbnail size = %v, want 160x100", size)
+	}
+
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	records := []*storage.ScreenshotRecord{
+		{ID: "s1", Timestamp: start, ImagePath: imagePath, AnalysisStatus: storage.AnalysisDone, Analysis: "编辑 <script>alert(1)</script>"},
+		{ID: "s2", Timestamp: start.Add(time.Minute), ImagePath: filepath.Join(dir, "missing.png"), AnalysisStatus: storage.AnalysisDone, Analysis: "阅读文档"},
+		{ID: "s3", Timestamp: start.Add(2 * time.Minute), ImagePath: imagePath, AnalysisStatus: storage.AnalysisFailed, AnalysisError: "status 500"},
+	}
+	page, err := Build("2025-12-09 截图", records, 160)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<div class="card status-ok" id="s1">`,
+		`<img src="data:image/jpeg;base64,`,
+		"编辑 &lt;script&gt;alert(1)&lt;/script&gt;",
+		`<div class="card status-missing" id="s2">`,
+		"图片缺失",
+		`<div class="card status-failed" id="s3">`,
+		"status 500",
+		"共 3 张截图",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("gallery page does not contain %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("gallery page contains an unescaped caption")
+	}
+}
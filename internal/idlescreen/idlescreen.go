@@ -0,0 +1,189 @@
+// Package idlescreen recognizes desktop and lock screen screenshots from their analysis text and
+// from the answers of the detection prompts, using pattern files per language so that OS UIs in
+// other languages (German, Japanese, ...) can be recognized without recompiling
+//
+// A pattern file has one case-insensitive regular expression per line; empty lines and lines
+// starting with # are ignored:
+//
+//	锁屏界面                       the summary of the analysis matches: desktop or lock screen
+//	登录界面 !! 游戏|应用|软件        ... unless the summary also matches the part after " !! "
+//	short: 锁屏|桌面                only applies to short summaries (less than 100 bytes)
+//	yes: 是                        a detection prompt answer meaning "yes"
+package idlescreen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// shortSummaryLength is the length in bytes below which "short:" patterns apply
+const shortSummaryLength = 100
+
+// Builtin are the patterns used for languages without a pattern file
+var Builtin = map[string]string{
+	"zh": `# 中文系统界面
+锁屏界面
+锁屏状态
+处于锁屏
+电脑桌面
+桌面界面
+桌面状态
+系统登录
+系统锁屏
+等待解锁
+等待输入密码
+没有打开任何应用
+# 游戏、应用自己的登录界面不算
+登录界面 !! 游戏|应用|软件
+输入密码.*(解锁|锁屏|系统)|(解锁|锁屏|系统).*输入密码
+short: 锁屏|桌面
+yes: 是
+`,
+	"en": `# English system UI
+lock ?screen
+desktop
+yes: yes
+`,
+}
+
+type rule struct {
+	match  *regexp.Regexp
+	except *regexp.Regexp // nil without exception
+	short  bool
+}
+
+// Patterns recognizes desktop and lock screen states
+type Patterns struct {
+	rules []rule
+	yes   []*regexp.Regexp
+}
+
+// Parse parses a pattern file; name identifies it in errors
+func Parse(name, content string) (*Patterns, error) {
+	p := &Patterns{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		compile := func(expr string) (*regexp.Regexp, error) {
+			re, err := regexp.Compile(`(?is)` + strings.TrimSpace(expr))
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid pattern: %w", name, i+1, err)
+			}
+			return re, nil
+		}
+
+		if expr, ok := strings.CutPrefix(line, "yes:"); ok {
+			re, err := compile(expr)
+			if err != nil {
+				return nil, err
+			}
+			p.yes = append(p.yes, re)
+			continue
+		}
+		var r rule
+		if expr, ok := strings.CutPrefix(line, "short:"); ok {
+			r.short, line = true, expr
+		}
+		expr, except, hasExcept := strings.Cut(line, " !! ")
+		var err error
+		if r.match, err = compile(expr); err != nil {
+			return nil, err
+		}
+		if hasExcept {
+			if r.except, err = compile(except); err != nil {
+				return nil, err
+			}
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// Merge returns the patterns of all given sets combined
+func Merge(sets ...*Patterns) *Patterns {
+	merged := &Patterns{}
+	for _, p := range sets {
+		merged.rules = append(merged.rules, p.rules...)
+		merged.yes = append(merged.yes, p.yes...)
+	}
+	return merged
+}
+
+// IsIdleAnalysis reports whether a screenshot analysis describes a desktop or lock screen
+// Only the summary part of the analysis is checked, to avoid false positives from detailed descriptions
+func (p *Patterns) IsIdleAnalysis(analysis string) bool {
+	if analysis == "" {
+		return true // Empty analysis usually means desktop/lock screen was detected
+	}
+	summary := summaryPart(analysis)
+	for _, r := range p.rules {
+		if r.short && len(summary) >= shortSummaryLength {
+			continue
+		}
+		if r.match.MatchString(summary) && (r.except == nil || !r.except.MatchString(summary)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsYes reports whether the answer of a detection prompt means yes
+func (p *Patterns) IsYes(answer string) bool {
+	answer = strings.TrimSpace(answer)
+	for _, re := range p.yes {
+		if re.MatchString(answer) {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryPart returns the 【摘要】 section of an analysis, or its first 200 bytes without one
+func summaryPart(analysis string) string {
+	if start := strings.Index(analysis, "【摘要】"); start >= 0 {
+		if end := strings.Index(analysis, "【详细论述】"); end > start {
+			return analysis[start:end]
+		}
+		return analysis[start:]
+	}
+	if len(analysis) > 200 {
+		return analysis[:200]
+	}
+	return analysis
+}
+
+var (
+	mu      sync.RWMutex
+	current = defaultPatterns()
+)
+
+func defaultPatterns() *Patterns {
+	zh, _ := Parse("zh", Builtin["zh"])
+	en, _ := Parse("en", Builtin["en"])
+	return Merge(zh, en)
+}
+
+// Set replaces the patterns used by IsIdleAnalysis and IsYes (the built-in zh and en ones by default)
+func Set(p *Patterns) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = p
+}
+
+// IsIdleAnalysis reports whether a screenshot analysis describes a desktop or lock screen
+func IsIdleAnalysis(analysis string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.IsIdleAnalysis(analysis)
+}
+
+// IsYes reports whether the answer of a detection prompt means yes
+func IsYes(answer string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.IsYes(answer)
+}
@@ -0,0 +1,50 @@
+package idlescreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsIdleAnalysis(t *testing.T) {
+	de, err := Parse("de.txt", "# Deutsch\nsperrbildschirm\nanmeldebildschirm !! spiel|app\nshort: schreibtisch\nyes: \\bja\\b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := Merge(defaultPatterns(), de)
+
+	tests := []struct {
+		name     string
+		analysis string
+		want     bool
+	}{
+		{"empty", "", true},
+		{"builtin zh", "【摘要】电脑处于锁屏状态【详细论述】无", true},
+		{"builtin en", "The Lock Screen is shown", true},
+		{"only summary section", "【摘要】用户在编辑代码【详细论述】右下角可以看到锁屏界面的设置", false},
+		{"exception", "【摘要】游戏的登录界面", false},
+		{"system login", "【摘要】显示系统的登录界面", true},
+		{"password with unlock", "【摘要】输入密码以解锁", true},
+		{"german", "Der Sperrbildschirm wird angezeigt", true},
+		{"german exception", "Anmeldebildschirm eines Spiels", false},
+		{"german short", "Schreibtisch", true},
+		{"german long", "Schreibtisch" + strings.Repeat(" mit geöffnetem Editor", 10), false},
+		{"work", "【摘要】用户在 VS Code 中编写 Go 代码", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := patterns.IsIdleAnalysis(tt.analysis); got != tt.want {
+				t.Errorf("IsIdleAnalysis(%q) = %v, want %v", tt.analysis, got, tt.want)
+			}
+		})
+	}
+
+	for answer, want := range map[string]bool{"是": true, "Yes.": true, "Ja": true, "否": false, "Jahr": false} {
+		if got := patterns.IsYes(answer); got != want {
+			t.Errorf("IsYes(%q) = %v, want %v", answer, got, want)
+		}
+	}
+
+	if _, err := Parse("bad.txt", "lock\n(screen\n"); err == nil || !strings.Contains(err.Error(), "bad.txt line 2") {
+		t.Errorf("Parse() error = %v, want line 2 reported", err)
+	}
+}
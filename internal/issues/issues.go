@@ -0,0 +1,216 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderJira   = "jira"
+	ProviderLinear = "linear"
+)
+
+// keyPattern matches issue keys such as PROJ-123 or ENG-42
+var keyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]{1,9}-[1-9][0-9]{0,6}\b`)
+
+// nonIssuePrefixes are prefixes of standards, encodings and versions that look like issue keys, e.g. UTF-8 or ISO-8601
+var nonIssuePrefixes = map[string]bool{
+	"UTF": true, "UCS": true, "ISO": true, "IEC": true, "RFC": true, "IEEE": true, "ECMA": true, "ANSI": true,
+	"SHA": true, "MD": true, "AES": true, "RSA": true, "CRC": true, "BASE": true, "HTTP": true, "TLS": true,
+	"SSL": true, "IPV": true, "MPEG": true, "GPT": true, "WIN": true, "COVID": true, "CVE": true,
+}
+
+// Issue holds the tracker information of a single issue
+type Issue struct {
+	Key    string
+	Title  string
+	Status string
+	URL    string
+}
+
+// Tracker fetches issue details from an issue tracker
+type Tracker interface {
+	Fetch(key string) (*Issue, error)
+}
+
+// ExtractKeys returns the distinct issue keys found in text, in order of first appearance
+// If projects is not empty, only keys of these projects are returned, otherwise well-known non-issue
+// prefixes such as UTF-8 are skipped
+func ExtractKeys(text string, projects []string) []string {
+	allowed := make(map[string]bool)
+	for _, p := range projects {
+		allowed[strings.ToUpper(strings.TrimSpace(p))] = true
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range keyPattern.FindAllString(text, -1) {
+		if seen[key] {
+			continue
+		}
+		prefix := key[:strings.Index(key, "-")]
+		if len(allowed) > 0 && !allowed[prefix] {
+			continue
+		}
+		if len(allowed) == 0 && nonIssuePrefixes[prefix] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// NewTracker creates a tracker for the given provider
+func NewTracker(provider, baseURL, email, apiToken string) (Tracker, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch provider {
+	case ProviderJira:
+		if baseURL == "" {
+			return nil, fmt.Errorf("issues.base_url is required for jira")
+		}
+		return &JiraTracker{BaseURL: strings.TrimRight(baseURL, "/"), Email: email, APIToken: apiToken, client: client}, nil
+	case ProviderLinear:
+		if baseURL == "" {
+			baseURL = "https://api.linear.app/graphql"
+		}
+		return &LinearTracker{Endpoint: baseURL, APIKey: apiToken, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported issue provider: %s (supported: jira, linear)", provider)
+	}
+}
+
+// JiraTracker fetches issues from the Jira REST API
+type JiraTracker struct {
+	BaseURL  string
+	Email    string
+	APIToken string
+	client   *http.Client
+}
+
+func (j *JiraTracker) Fetch(key string) (*Issue, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,status", j.BaseURL, key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if j.Email != "" {
+		req.SetBasicAuth(j.Email, j.APIToken)
+	} else if j.APIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+j.APIToken)
+	}
+
+	body, err := doRequest(j.client, req)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, nil
+	}
+
+	var resp struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse jira response: %w", err)
+	}
+
+	return &Issue{
+		Key:    key,
+		Title:  resp.Fields.Summary,
+		Status: resp.Fields.Status.Name,
+		URL:    fmt.Sprintf("%s/browse/%s", j.BaseURL, key),
+	}, nil
+}
+
+// LinearTracker fetches issues from the Linear GraphQL API
+type LinearTracker struct {
+	Endpoint string
+	APIKey   string
+	client   *http.Client
+}
+
+func (l *LinearTracker) Fetch(key string) (*Issue, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     `query($id: String!) { issue(id: $id) { identifier title url state { name } } }`,
+		"variables": map[string]string{"id": key},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", l.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.APIKey)
+
+	body, err := doRequest(l.client, req)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, nil
+	}
+
+	var resp struct {
+		Data struct {
+			Issue *struct {
+				Identifier string `json:"identifier"`
+				Title      string `json:"title"`
+				URL        string `json:"url"`
+				State      struct {
+					Name string `json:"name"`
+				} `json:"state"`
+			} `json:"issue"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse linear response: %w", err)
+	}
+	if resp.Data.Issue == nil {
+		return nil, nil
+	}
+
+	return &Issue{
+		Key:    key,
+		Title:  resp.Data.Issue.Title,
+		Status: resp.Data.Issue.State.Name,
+		URL:    resp.Data.Issue.URL,
+	}, nil
+}
+
+// doRequest executes a request and returns the body; returns nil body for 404 (issue not found)
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,28 @@
+package issues
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		projects []string
+		want     []string
+	}{
+		{"distinct keys in order", "Working on PROJ-123 in VS Code, reviewing ENG-42 and PROJ-123 again", nil, []string{"PROJ-123", "ENG-42"}},
+		{"standards and encodings", "Saving as UTF-8, dates in ISO-8601, hashing with SHA-256 per RFC-6234", nil, nil},
+		{"versions", "Asked GPT-4 about the COVID-19 dashboard on WIN-11", nil, nil},
+		{"keys next to standards", "ENG-42: parse ISO-8601 timestamps as UTF-8", nil, []string{"ENG-42"}},
+		{"configured projects", "PROJ-123 and ENG-42 and OPS-7", []string{"proj", "ENG"}, []string{"PROJ-123", "ENG-42"}},
+		{"configured project with a denied prefix", "ISO-9 and UTF-8", []string{"ISO"}, []string{"ISO-9"}},
+	}
+	for _, tt := range tests {
+		got := ExtractKeys(tt.text, tt.projects)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ExtractKeys() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -27,6 +27,8 @@ type LogConfig struct {
 	MaxBackups     int    // Maximum number of old log files to retain
 	MaxAge         int    // Maximum number of days to retain old log files
 	Compress       bool   // Whether to compress rotated log files
+	SampleBurst    int    // Maximum repetitive lines (progress, retries) per key within SampleWindow, 0 disables sampling
+	SampleWindow   string // Sampling window (e.g., "1m")
 }
 
 // Init initializes the global logger with the given configuration
@@ -51,6 +53,16 @@ func Init(config LogConfig) error {
 	}
 	Logger.SetLevel(level)
 
+	// Configure sampling of repetitive lines
+	sampleWindow := time.Minute
+	if config.SampleWindow != "" {
+		sampleWindow, err = time.ParseDuration(config.SampleWindow)
+		if err != nil {
+			return fmt.Errorf("invalid sample_window: %w", err)
+		}
+	}
+	SetThrottle(config.SampleBurst, sampleWindow)
+
 	// Set formatter
 	Logger.SetFormatter(&logrus.TextFormatter{
 		FullTimestamp:   true,
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// throttle limits repetitive log lines (per-period progress, retries) to a burst per window and key
+type throttle struct {
+	mu     sync.Mutex
+	burst  int
+	window time.Duration
+	keys   map[string]*throttleState
+}
+
+type throttleState struct {
+	windowStart time.Time
+	emitted     int
+	suppressed  int
+}
+
+var logThrottle = &throttle{burst: 5, window: time.Minute, keys: make(map[string]*throttleState)}
+
+// SetThrottle configures log sampling: at most burst lines per key within window (burst <= 0 disables sampling)
+func SetThrottle(burst int, window time.Duration) {
+	logThrottle.mu.Lock()
+	defer logThrottle.mu.Unlock()
+	logThrottle.burst = burst
+	logThrottle.window = window
+	logThrottle.keys = make(map[string]*throttleState)
+}
+
+// allow reports whether a line for key may be emitted now, and how many lines were suppressed before it
+func (t *throttle) allow(key string, now time.Time) (bool, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.burst <= 0 || t.window <= 0 {
+		return true, 0
+	}
+
+	state, ok := t.keys[key]
+	if !ok || now.Sub(state.windowStart) >= t.window {
+		suppressed := 0
+		if ok {
+			suppressed = state.suppressed
+		}
+		t.keys[key] = &throttleState{windowStart: now, emitted: 1}
+		return true, suppressed
+	}
+	if state.emitted < t.burst {
+		state.emitted++
+		return true, 0
+	}
+	state.suppressed++
+	return false, 0
+}
+
+// Throttledf logs a repetitive message at the given level, sampled by key (see SetThrottle)
+// The number of suppressed lines is reported on the next line emitted for the key and counted in the active run
+func Throttledf(level logrus.Level, key, format string, args ...interface{}) {
+	ok, suppressed := logThrottle.allow(key, time.Now())
+	if !ok {
+		Count("suppressed_logs")
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if suppressed > 0 {
+		msg = fmt.Sprintf("%s (%d similar message(s) suppressed)", msg, suppressed)
+	}
+	GetLogger().Log(level, msg)
+}
+
+// Run collects counters of a long operation (rebuild, generate) and logs them as a single summary record
+type Run struct {
+	name   string
+	start  time.Time
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+var (
+	activeRunMu sync.Mutex
+	activeRun   *Run
+)
+
+// StartRun starts collecting counters for a run; counters from Count go to the most recently started run
+func StartRun(name string) *Run {
+	r := &Run{name: name, start: time.Now(), counts: make(map[string]int)}
+	activeRunMu.Lock()
+	activeRun = r
+	activeRunMu.Unlock()
+	return r
+}
+
+// Count increments a counter of the active run (no-op when no run is active)
+func Count(counter string) {
+	activeRunMu.Lock()
+	r := activeRun
+	activeRunMu.Unlock()
+	if r != nil {
+		r.Add(counter, 1)
+	}
+}
+
+// Add adds n to a counter of the run
+func (r *Run) Add(counter string, n int) {
+	r.mu.Lock()
+	r.counts[counter] += n
+	r.mu.Unlock()
+}
+
+// Summary returns the one-line summary of the run's counters
+func (r *Run) Summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.counts))
+	for name := range r.counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, r.counts[name]))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no activity")
+	}
+	return fmt.Sprintf("Run summary [%s]: %s, took %v", r.name, strings.Join(parts, " "), time.Since(r.start).Round(time.Second))
+}
+
+// Finish logs the run summary and detaches the run (runs without any counted activity are only logged at Debug)
+func (r *Run) Finish() {
+	activeRunMu.Lock()
+	if activeRun == r {
+		activeRun = nil
+	}
+	activeRunMu.Unlock()
+
+	r.mu.Lock()
+	idle := len(r.counts) == 0
+	r.mu.Unlock()
+	if idle {
+		GetLogger().Debug(r.Summary())
+		return
+	}
+	GetLogger().Info(r.Summary())
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThrottleAllow(t *testing.T) {
+	th := &throttle{burst: 2, window: time.Minute, keys: make(map[string]*throttleState)}
+	now := time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+
+	for i, want := range []bool{true, true, false, false} {
+		if ok, _ := th.allow("retry", now.Add(time.Duration(i)*time.Second)); ok != want {
+			t.Errorf("allow #%d = %v, want %v", i, ok, want)
+		}
+	}
+	if ok, _ := th.allow("other", now); !ok {
+		t.Error("other keys must not be throttled")
+	}
+
+	ok, suppressed := th.allow("retry", now.Add(time.Minute))
+	if !ok || suppressed != 2 {
+		t.Errorf("allow in next window = %v, %d suppressed, want true, 2", ok, suppressed)
+	}
+
+	th.burst = 0
+	for i := 0; i < 10; i++ {
+		if ok, _ := th.allow("retry", now); !ok {
+			t.Fatal("burst 0 must disable throttling")
+		}
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	r := StartRun("generate")
+	Count("generated")
+	Count("generated")
+	r.Add("failed", 1)
+
+	summary := r.Summary()
+	if !strings.Contains(summary, "[generate]: failed=1 generated=2") {
+		t.Errorf("Summary() = %q", summary)
+	}
+
+	r.Finish()
+	Count("generated")
+	if strings.Contains(r.Summary(), "generated=3") {
+		t.Error("Count after Finish must not reach the finished run")
+	}
+}
@@ -0,0 +1,22 @@
+// Package notify shows macOS desktop notifications
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Send shows a desktop notification via Notification Center
+func Send(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
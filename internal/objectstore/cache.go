@@ -0,0 +1,139 @@
+package objectstore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store mirrors the screenshot directory to a bucket: every image is uploaded when written
+// (write-through), read from the local copy, and downloaded again after it was evicted from the cache
+type Store struct {
+	Root          string // Local screenshot directory (the cache)
+	Prefix        string // Key prefix in the bucket
+	MaxCacheBytes int64  // Uploaded images are evicted oldest first above this size (0 keeps everything)
+	client        *Client
+}
+
+// NewStore creates a store caching the bucket in root
+func NewStore(client *Client, root, prefix string, maxCacheBytes int64) *Store {
+	return &Store{
+		Root:          root,
+		Prefix:        strings.Trim(prefix, "/"),
+		MaxCacheBytes: maxCacheBytes,
+		client:        client,
+	}
+}
+
+// Key returns the object key of a local image (its path relative to the cache root)
+func (s *Store) Key(localPath string) (string, error) {
+	rel, err := filepath.Rel(s.Root, localPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("image %s is outside the screenshot directory %s", localPath, s.Root)
+	}
+	return path.Join(s.Prefix, filepath.ToSlash(rel)), nil
+}
+
+// Upload copies a local image to the bucket and returns its key
+func (s *Store) Upload(localPath string) (string, error) {
+	key, err := s.Key(localPath)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if err := s.client.Put(key, data); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// Fetch makes sure an image is in the local cache, downloading it from the bucket if it was evicted
+func (s *Store) Fetch(localPath string) error {
+	if _, err := os.Stat(localPath); err == nil {
+		return nil
+	}
+	key, err := s.Key(localPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Download next to the target and rename, so readers never see a partial image
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), ".fetch-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := s.client.Get(key, tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	return os.Rename(tmp.Name(), localPath)
+}
+
+// Remove deletes an image from the bucket (the local copy is left to the caller)
+func (s *Store) Remove(localPath string) error {
+	key, err := s.Key(localPath)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Delete(key); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Evict deletes the oldest cached images until the cache fits MaxCacheBytes
+// Only images for which uploaded reports true are deleted; it returns the number of evicted images
+func (s *Store) Evict(uploaded func(localPath string) bool) (int, error) {
+	if s.MaxCacheBytes <= 0 {
+		return 0, nil
+	}
+
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime int64
+	}
+	var files []cachedFile
+	var total int64
+	err := filepath.Walk(s.Root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		files = append(files, cachedFile{path: p, size: info.Size(), modTime: info.ModTime().UnixNano()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan cache: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime < files[j].modTime })
+	evicted := 0
+	for _, f := range files {
+		if total <= s.MaxCacheBytes {
+			break
+		}
+		if !uploaded(f.path) {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			continue
+		}
+		total -= f.size
+		evicted++
+	}
+	return evicted, nil
+}
@@ -0,0 +1,119 @@
+package objectstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory bucket checking the signature headers of every request
+func fakeS3(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("unexpected Authorization %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			t.Errorf("payload hash mismatch for %s %s", r.Method, r.URL.Path)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "PUT":
+			objects[r.URL.Path] = body
+		case "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case "DELETE":
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestStoreWriteThroughAndFetch(t *testing.T) {
+	server := fakeS3(t)
+	defer server.Close()
+	client, err := NewClient(server.URL, "", "shots", "AKID", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	store := NewStore(client, root, "screenshots", 0)
+	imagePath := filepath.Join(root, "2025", "Q4", "12", "W2", "09", "14", "03.png")
+	os.MkdirAll(filepath.Dir(imagePath), 0755)
+	os.WriteFile(imagePath, []byte("png data"), 0644)
+
+	key, err := store.Upload(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "screenshots/2025/Q4/12/W2/09/14/03.png" {
+		t.Errorf("key = %s", key)
+	}
+
+	// Evicted images are downloaded again on demand
+	os.Remove(imagePath)
+	if err := store.Fetch(imagePath); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(imagePath); string(data) != "png data" {
+		t.Errorf("fetched %q", data)
+	}
+
+	if err := store.Remove(imagePath); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(imagePath)
+	if err := store.Fetch(imagePath); err == nil {
+		t.Error("expected fetching a deleted image to fail")
+	}
+
+	if _, err := store.Upload(filepath.Join(filepath.Dir(root), "elsewhere.png")); err == nil {
+		t.Error("expected images outside the screenshot directory to be rejected")
+	}
+}
+
+func TestStoreEvictsOldestUploaded(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(nil, root, "", 10)
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	var paths []string
+	for i, name := range []string{"a.png", "b.png", "c.png"} {
+		p := filepath.Join(root, name)
+		os.WriteFile(p, []byte("12345"), 0644)
+		os.Chtimes(p, base.Add(time.Duration(i)*time.Minute), base.Add(time.Duration(i)*time.Minute))
+		paths = append(paths, p)
+	}
+
+	// a.png is not uploaded yet, so b.png is evicted instead
+	evicted, err := store.Evict(func(p string) bool { return p != paths[0] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evicted != 1 {
+		t.Errorf("evicted = %d, want 1", evicted)
+	}
+	for i, want := range []bool{true, false, true} {
+		if _, err := os.Stat(paths[i]); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", paths[i], err == nil, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package permissions
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// ownerUID returns the owner uid of a file
+func ownerUID(info fs.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
+
+// RestrictUmask makes files and directories created by this process private to the current user
+func RestrictUmask() {
+	syscall.Umask(0077)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package permissions
+
+import "io/fs"
+
+// ownerUID is not available on Windows (access is governed by ACLs)
+func ownerUID(info fs.FileInfo) (int, bool) {
+	return 0, false
+}
+
+// RestrictUmask is a no-op on Windows
+func RestrictUmask() {}
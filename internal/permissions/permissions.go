@@ -0,0 +1,113 @@
+// Package permissions checks and fixes access permissions of the data directories,
+// so screenshot data does not leak between accounts on a shared machine
+package permissions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Issue kinds
+const (
+	IssueTooOpen      = "group/world accessible"
+	IssueForeignOwner = "owned by another user"
+)
+
+// Issue is a permission problem of a data path
+type Issue struct {
+	Path string
+	Mode os.FileMode
+	Kind string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s (%04o): %s", i.Path, i.Mode.Perm(), i.Kind)
+}
+
+// Supported reports whether permission checks are meaningful on this platform
+func Supported() bool {
+	return runtime.GOOS != "windows"
+}
+
+// Check checks the given paths for group/world access bits and foreign ownership
+// Directories are walked when recursive is set, otherwise only the paths themselves are checked
+// Missing paths are skipped
+func Check(paths []string, recursive bool) ([]Issue, error) {
+	if !Supported() {
+		return nil, nil
+	}
+
+	var issues []Issue
+	err := walk(paths, recursive, func(path string, info fs.FileInfo) error {
+		if uid, ok := ownerUID(info); ok && uid != os.Getuid() {
+			issues = append(issues, Issue{Path: path, Mode: info.Mode(), Kind: IssueForeignOwner})
+		}
+		if info.Mode().Perm()&0077 != 0 {
+			issues = append(issues, Issue{Path: path, Mode: info.Mode(), Kind: IssueTooOpen})
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// Fix recursively removes group/world permission bits from the given paths and returns the number of changed entries
+// Entries owned by another user are left alone (they can't be chmod'ed and must be moved by their owner)
+func Fix(paths []string) (int, error) {
+	if !Supported() {
+		return 0, nil
+	}
+
+	fixed := 0
+	err := walk(paths, true, func(path string, info fs.FileInfo) error {
+		if uid, ok := ownerUID(info); ok && uid != os.Getuid() {
+			return nil
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if info.Mode().Perm()&0077 == 0 {
+			return nil
+		}
+		if err := os.Chmod(path, info.Mode().Perm()&^0077); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", path, err)
+		}
+		fixed++
+		return nil
+	})
+	return fixed, err
+}
+
+// walk calls fn for every existing path (and its descendants when recursive)
+func walk(paths []string, recursive bool, fn func(path string, info fs.FileInfo) error) error {
+	for _, root := range paths {
+		if root == "" {
+			continue
+		}
+		info, err := os.Lstat(root)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", root, err)
+		}
+		if !recursive || !info.IsDir() {
+			if err := fn(root, info); err != nil {
+				return err
+			}
+			continue
+		}
+		err = filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return fmt.Errorf("failed to walk %s: %w", path, err)
+			}
+			return fn(path, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
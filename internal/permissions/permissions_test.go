@@ -0,0 +1,70 @@
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckAndFix(t *testing.T) {
+	if !Supported() {
+		t.Skip("permission bits are not supported on this platform")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "2025")
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(sub, 0750); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(sub, "report.md")
+	if err := os.WriteFile(file, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(file, 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.db")
+
+	issues, err := Check([]string{dir, missing}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Path != dir || issues[0].Kind != IssueTooOpen {
+		t.Fatalf("non-recursive Check() = %v, want only %s too open", issues, dir)
+	}
+
+	issues, err = Check([]string{dir}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("recursive Check() = %v, want 3 issues", issues)
+	}
+
+	fixed, err := Fix([]string{dir, missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fixed != 3 {
+		t.Errorf("Fix() fixed %d, want 3", fixed)
+	}
+	for path, want := range map[string]os.FileMode{dir: 0700, sub: 0700, file: 0600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s mode = %04o, want %04o", path, info.Mode().Perm(), want)
+		}
+	}
+
+	if issues, _ := Check([]string{dir}, true); len(issues) != 0 {
+		t.Errorf("Check() after Fix() = %v, want none", issues)
+	}
+}
@@ -0,0 +1,280 @@
+// Package projects attributes IDE and terminal window titles to repositories,
+// monorepo sub-projects and branches without any LLM calls
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Attribution is the repository, sub-project and branch a window belongs to
+type Attribution struct {
+	Repo       string
+	Subproject string // Directory of the nearest project manifest below the repo root (monorepo package), empty for the root
+	Branch     string
+}
+
+// Hint is what a window title reveals about the project
+type Hint struct {
+	Repo   string // Workspace/project name shown by the IDE
+	Path   string // File system path (terminal cwd or IDE project path)
+	Branch string
+}
+
+// ideApps are IDEs whose titles look like "<file> — <workspace> — <app>"
+var ideApps = []string{"code", "visual studio code", "cursor", "windsurf", "zed", "sublime text"}
+
+// jetbrainsApps are JetBrains IDEs whose titles look like "<project> [<path>] – <file>"
+var jetbrainsApps = []string{"goland", "intellij idea", "pycharm", "webstorm", "clion", "rustrover", "phpstorm", "rider", "android studio"}
+
+// terminalApps are terminals whose titles usually contain the working directory
+var terminalApps = []string{"terminal", "iterm2", "iterm", "warp", "alacritty", "kitty", "wezterm", "ghostty", "hyper", "tabby"}
+
+// manifestFiles mark the root of a (sub-)project inside a repository
+var manifestFiles = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "pom.xml", "build.gradle", "build.gradle.kts", "BUILD.bazel", "Gemfile", "composer.json"}
+
+var (
+	titleSeparator = regexp.MustCompile(`\s+[—–-]\s+`)
+	// branchPattern matches "(main)", "[feature/x]", "git:(main)" or "on main"
+	branchPattern = regexp.MustCompile(`(?:git:)?[(\[]([\w][\w./-]*)[)\]]|\bon\s+([\w][\w./-]*)`)
+	// pathPattern matches an absolute or home-relative path
+	pathPattern = regexp.MustCompile(`(~|/)[^\s:;,'"()\[\]]*`)
+	// bracketPathPattern matches the project path JetBrains shows in brackets
+	bracketPathPattern = regexp.MustCompile(`\[([~/][^\]]*)\]`)
+)
+
+func matchesApp(app string, apps []string) bool {
+	app = strings.ToLower(strings.TrimSpace(app))
+	for _, a := range apps {
+		if app == a || strings.HasPrefix(app, a+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTitle extracts a project hint from the title of an IDE or terminal window
+func ParseTitle(app, title string) (Hint, bool) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Hint{}, false
+	}
+
+	switch {
+	case matchesApp(app, ideApps):
+		return parseIDETitle(app, title)
+	case matchesApp(app, jetbrainsApps):
+		return parseJetBrainsTitle(title)
+	case matchesApp(app, terminalApps):
+		return parseTerminalTitle(title)
+	}
+	return Hint{}, false
+}
+
+// parseIDETitle handles "● executor.go — stuff-time — Visual Studio Code" style titles
+func parseIDETitle(app, title string) (Hint, bool) {
+	parts := titleSeparator.Split(title, -1)
+	// Drop the trailing application name
+	if len(parts) > 1 && matchesApp(parts[len(parts)-1], ideApps) {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 {
+		return Hint{}, false
+	}
+
+	workspace := parts[len(parts)-1]
+	hint := Hint{Branch: findBranch(workspace)}
+	workspace = strings.TrimSpace(branchPattern.ReplaceAllString(workspace, ""))
+	if strings.HasPrefix(workspace, "/") || strings.HasPrefix(workspace, "~") {
+		hint.Path = workspace
+	} else {
+		hint.Repo = workspace
+	}
+	if hint.Repo == "" && hint.Path == "" {
+		return Hint{}, false
+	}
+	return hint, true
+}
+
+// parseJetBrainsTitle handles "stuff-time [~/code/stuff-time] – internal/task/executor.go" style titles
+func parseJetBrainsTitle(title string) (Hint, bool) {
+	parts := titleSeparator.Split(title, -1)
+	project := parts[0]
+
+	var hint Hint
+	if m := bracketPathPattern.FindStringSubmatch(project); m != nil {
+		hint.Path = m[1]
+		project = strings.TrimSpace(strings.Replace(project, m[0], "", 1))
+		// Relative file path after the separator narrows the path down to a monorepo package
+		if len(parts) > 1 && strings.Contains(parts[1], "/") && !strings.HasPrefix(parts[1], "/") {
+			hint.Path = filepath.Join(hint.Path, filepath.Dir(parts[1]))
+		}
+	}
+	hint.Branch = findBranch(project)
+	hint.Repo = strings.TrimSpace(branchPattern.ReplaceAllString(project, ""))
+	if hint.Repo == "" && hint.Path == "" {
+		return Hint{}, false
+	}
+	return hint, true
+}
+
+// parseTerminalTitle handles "user@host: ~/code/monorepo/services/api (main)" style titles
+func parseTerminalTitle(title string) (Hint, bool) {
+	path := ""
+	for _, candidate := range pathPattern.FindAllString(title, -1) {
+		if candidate == "/" || candidate == "~" {
+			continue
+		}
+		if len(candidate) > len(path) {
+			path = candidate
+		}
+	}
+	if path == "" {
+		return Hint{}, false
+	}
+	return Hint{Path: path, Branch: findBranch(title)}, true
+}
+
+// findBranch returns the branch name shown in text, if any
+func findBranch(text string) string {
+	for _, m := range branchPattern.FindAllStringSubmatch(text, -1) {
+		branch := m[1]
+		if branch == "" {
+			branch = m[2]
+		}
+		// Skip JetBrains-style bracketed paths and VS Code status markers
+		if branch != "" && !strings.HasPrefix(branch, "/") && branch != "Workspace" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// Resolver resolves window titles to repositories on the local disk
+type Resolver struct {
+	// Roots are directories containing repositories, used to find a repo by its workspace name
+	Roots []string
+}
+
+// Resolve attributes a window to a repository, monorepo sub-project and branch
+func (r *Resolver) Resolve(app, title string) (Attribution, bool) {
+	hint, ok := ParseTitle(app, title)
+	if !ok {
+		return Attribution{}, false
+	}
+
+	dir := ""
+	if hint.Path != "" {
+		dir = expandHome(hint.Path)
+	} else {
+		for _, root := range r.Roots {
+			candidate := filepath.Join(expandHome(root), hint.Repo)
+			if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+				dir = candidate
+				break
+			}
+		}
+	}
+
+	gitRoot := ""
+	if dir != "" {
+		gitRoot = findGitRoot(dir)
+	}
+	if gitRoot == "" {
+		// Not a repository on this disk, fall back to what the title says
+		repo := hint.Repo
+		if repo == "" {
+			return Attribution{}, false
+		}
+		return Attribution{Repo: repo, Branch: hint.Branch}, true
+	}
+
+	attribution := Attribution{
+		Repo:       filepath.Base(gitRoot),
+		Subproject: findSubproject(gitRoot, dir),
+		Branch:     hint.Branch,
+	}
+	if attribution.Branch == "" {
+		attribution.Branch = readBranch(gitRoot)
+	}
+	return attribution, true
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// findGitRoot walks up from dir to the directory containing .git
+func findGitRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	// A file path: start from its directory
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findSubproject returns the directory (relative to gitRoot) of the nearest project manifest
+// between dir and the repository root, empty if that is the root itself
+func findSubproject(gitRoot, dir string) string {
+	dir = filepath.Clean(dir)
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for dir != gitRoot && strings.HasPrefix(dir, gitRoot+string(filepath.Separator)) {
+		for _, manifest := range manifestFiles {
+			if _, err := os.Stat(filepath.Join(dir, manifest)); err == nil {
+				rel, _ := filepath.Rel(gitRoot, dir)
+				return filepath.ToSlash(rel)
+			}
+		}
+		dir = filepath.Dir(dir)
+	}
+	return ""
+}
+
+// readBranch reads the checked-out branch from .git/HEAD (supports worktrees where .git is a file)
+func readBranch(gitRoot string) string {
+	gitDir := filepath.Join(gitRoot, ".git")
+	if info, err := os.Stat(gitDir); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(gitDir)
+		if err != nil {
+			return ""
+		}
+		target := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(content)), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(gitRoot, target)
+		}
+		gitDir = target
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return branch
+	}
+	// Detached HEAD
+	if len(ref) >= 7 {
+		return ref[:7]
+	}
+	return ""
+}
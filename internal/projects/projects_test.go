@@ -0,0 +1,69 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		app   string
+		title string
+		want  Hint
+		ok    bool
+	}{
+		{"Code", "● executor.go — stuff-time — Visual Studio Code", Hint{Repo: "stuff-time"}, true},
+		{"Cursor", "main.go — backend (feature/login) — Cursor", Hint{Repo: "backend", Branch: "feature/login"}, true},
+		{"GoLand", "stuff-time [~/code/stuff-time] – internal/task/executor.go", Hint{Repo: "stuff-time", Path: "~/code/stuff-time/internal/task"}, true},
+		{"iTerm2", "dev@mbp: ~/code/monorepo/services/api (main)", Hint{Path: "~/code/monorepo/services/api", Branch: "main"}, true},
+		{"Terminal", "vim", Hint{}, false},
+		{"Safari", "GitHub - stuff-time", Hint{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseTitle(tt.app, tt.title)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseTitle(%q, %q) = %+v, %v, want %+v, %v", tt.app, tt.title, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveMonorepo(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "monorepo")
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/release/1.2\n")
+	writeFile(t, filepath.Join(repo, "services", "api", "go.mod"), "module api\n")
+	writeFile(t, filepath.Join(repo, "services", "api", "handler", "user.go"), "package handler\n")
+
+	r := &Resolver{Roots: []string{root}}
+
+	got, ok := r.Resolve("Terminal", "dev@mbp: "+filepath.Join(repo, "services", "api", "handler"))
+	want := Attribution{Repo: "monorepo", Subproject: "services/api", Branch: "release/1.2"}
+	if !ok || got != want {
+		t.Errorf("Resolve(terminal) = %+v, %v, want %+v", got, ok, want)
+	}
+
+	// Workspace name only: found through the configured roots
+	got, ok = r.Resolve("Code", "README.md — monorepo — Visual Studio Code")
+	want = Attribution{Repo: "monorepo", Branch: "release/1.2"}
+	if !ok || got != want {
+		t.Errorf("Resolve(vscode) = %+v, %v, want %+v", got, ok, want)
+	}
+
+	// Unknown workspace keeps the title's name
+	got, ok = r.Resolve("Code", "main.go — elsewhere — Visual Studio Code")
+	if !ok || got != (Attribution{Repo: "elsewhere"}) {
+		t.Errorf("Resolve(unknown) = %+v, %v, want repo elsewhere", got, ok)
+	}
+}
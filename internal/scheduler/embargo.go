@@ -0,0 +1,18 @@
+package scheduler
+
+import (
+	"time"
+
+	"stuff-time/internal/logger"
+)
+
+// Embargoed wraps a task so runs during an embargo window do nothing, and nothing is stored for them
+func Embargoed(isEmbargoed func(t time.Time) bool, name string, task func() error) func() error {
+	return func() error {
+		if isEmbargoed(time.Now()) {
+			logger.GetLogger().Debugf("Embargo window, skipping %s", name)
+			return nil
+		}
+		return task()
+	}
+}
@@ -0,0 +1,41 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrBlankCapture is returned when a capture is entirely black,
+// which on macOS usually means the screen recording permission was revoked
+var ErrBlankCapture = errors.New("captured image is blank")
+
+// blankThreshold is the highest 8-bit channel value still considered black
+const blankThreshold = 8
+
+// IsBlankImage reports whether an image is entirely (near) black
+// Pixels are sampled on a grid so large displays stay cheap to check
+func IsBlankImage(img image.Image) bool {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return true
+	}
+
+	stepX := bounds.Dx() / 64
+	if stepX < 1 {
+		stepX = 1
+	}
+	stepY := bounds.Dy() / 64
+	if stepY < 1 {
+		stepY = 1
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if r>>8 > blankThreshold || g>>8 > blankThreshold || b>>8 > blankThreshold {
+				return false
+			}
+		}
+	}
+	return true
+}
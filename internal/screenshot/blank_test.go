@@ -0,0 +1,27 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestIsBlankImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1280, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 1280; x++ {
+			img.Set(x, y, color.RGBA{2, 2, 2, 255})
+		}
+	}
+	if !IsBlankImage(img) {
+		t.Errorf("near-black image should be blank")
+	}
+
+	// A menu bar row is enough content to not be blank
+	for x := 0; x < 1280; x++ {
+		img.Set(x, 0, color.RGBA{230, 230, 230, 255})
+	}
+	if IsBlankImage(img) {
+		t.Errorf("image with content should not be blank")
+	}
+}
@@ -36,6 +36,10 @@ func CaptureScreen(screenID int, storagePath string, imageFormat string) (string
 			return "", fmt.Errorf("failed to capture screen %d (took %v, bounds: %v): %w", screenID, elapsed, bounds, err)
 		}
 		// Success - capture completed
+		// Without screen recording permission macOS returns black frames instead of failing
+		if IsBlankImage(img) {
+			return "", fmt.Errorf("screen %d: %w (check System Settings > Privacy & Security > Screen Recording)", screenID, ErrBlankCapture)
+		}
 	case <-ctx.Done():
 		elapsed := time.Since(startTime)
 		// More generic error message since this could be various issues
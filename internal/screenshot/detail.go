@@ -0,0 +1,42 @@
+package screenshot
+
+import (
+	"image"
+)
+
+// edgeContrast is the lowest luminance difference between neighbouring pixels counted as an edge
+const edgeContrast = 40
+
+// EdgeDensity returns the share of sampled pixels that differ sharply from their right neighbour
+// Text-heavy screens (editors, browsers, terminals) score high; lock screens, which show a blurred
+// wallpaper with a clock, score close to zero
+func EdgeDensity(img image.Image) float64 {
+	bounds := img.Bounds()
+	if bounds.Dx() < 2 || bounds.Dy() < 1 {
+		return 0
+	}
+
+	stepX := max(bounds.Dx()/256, 1)
+	stepY := max(bounds.Dy()/256, 1)
+
+	var samples, edges int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X-1; x += stepX {
+			diff := luminance(img, x, y) - luminance(img, x+1, y)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff >= edgeContrast {
+				edges++
+			}
+			samples++
+		}
+	}
+	return float64(edges) / float64(samples)
+}
+
+// luminance returns the 8-bit luma of a pixel
+func luminance(img image.Image, x, y int) int {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return int((299*(r>>8) + 587*(g>>8) + 114*(b>>8)) / 1000)
+}
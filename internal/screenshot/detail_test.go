@@ -0,0 +1,35 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestEdgeDensity(t *testing.T) {
+	// Smooth gradient, like a blurred lock screen wallpaper
+	smooth := image.NewRGBA(image.Rect(0, 0, 1280, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 1280; x++ {
+			smooth.Set(x, y, color.RGBA{uint8(x / 8), uint8(y / 8), 120, 255})
+		}
+	}
+	if d := EdgeDensity(smooth); d > 0.01 {
+		t.Errorf("EdgeDensity(smooth) = %.3f, want close to 0", d)
+	}
+
+	// Alternating dark/light columns, like lines of text
+	text := image.NewRGBA(image.Rect(0, 0, 1280, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 1280; x++ {
+			c := color.RGBA{250, 250, 250, 255}
+			if x%2 == 0 && y%4 != 0 {
+				c = color.RGBA{20, 20, 20, 255}
+			}
+			text.Set(x, y, c)
+		}
+	}
+	if d := EdgeDensity(text); d < 0.3 {
+		t.Errorf("EdgeDensity(text) = %.3f, want high", d)
+	}
+}
@@ -0,0 +1,34 @@
+package screenshot
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// hidIdlePattern matches the time since the last keyboard/mouse event in the IOHIDSystem registry entry
+var hidIdlePattern = regexp.MustCompile(`"HIDIdleTime"\s*=\s*(\d+)`)
+
+// InputIdleTime returns the time since the last keyboard or mouse input
+func InputIdleTime() (time.Duration, error) {
+	output, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-r", "-d", "1").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read input idle time: %w", err)
+	}
+	return parseHIDIdleTime(string(output))
+}
+
+// parseHIDIdleTime parses the HIDIdleTime (nanoseconds) of ioreg output
+func parseHIDIdleTime(output string) (time.Duration, error) {
+	m := hidIdlePattern.FindStringSubmatch(output)
+	if m == nil {
+		return 0, fmt.Errorf("HIDIdleTime not found in ioreg output")
+	}
+	ns, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid HIDIdleTime %q: %w", m[1], err)
+	}
+	return time.Duration(ns), nil
+}
@@ -0,0 +1,30 @@
+package screenshot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHIDIdleTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "idle entry",
+			output: "+-o IOHIDSystem  <class IOHIDSystem, id 0x100000451>\n    {\n      \"HIDIdleTime\" = 372000000000\n      \"HIDScrollCountMinDeltaToStart\" = 30\n    }\n",
+			want:   372 * time.Second,
+		},
+		{name: "missing", output: "+-o IOHIDSystem\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHIDIdleTime(tt.output)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseHIDIdleTime() = %v, %v, want %v (error: %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
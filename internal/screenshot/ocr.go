@@ -0,0 +1,47 @@
+package screenshot
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// recognizeTextScript recognizes the text of an image with the macOS Vision framework and prints it
+// one line per recognized text block, top to bottom; arguments are the image path and the
+// comma-separated recognition languages
+const recognizeTextScript = `ObjC.import('Vision');
+function run(argv) {
+	const handler = $.VNImageRequestHandler.alloc.initWithURLOptions($.NSURL.fileURLWithPath(argv[0]), $({}));
+	const request = $.VNRecognizeTextRequest.alloc.init;
+	request.recognitionLevel = 0; // VNRequestTextRecognitionLevelAccurate
+	request.usesLanguageCorrection = true;
+	if (argv[1]) {
+		request.recognitionLanguages = $(argv[1].split(','));
+	}
+	const error = $();
+	if (!handler.performRequestsError($([request]), error)) {
+		throw new Error('text recognition failed: ' + ObjC.unwrap(error.localizedDescription));
+	}
+	const lines = [];
+	const results = request.results;
+	for (let i = 0; i < results.count; i++) {
+		const candidates = results.objectAtIndex(i).topCandidates(1);
+		if (candidates.count > 0) {
+			lines.push(ObjC.unwrap(candidates.objectAtIndex(0).string));
+		}
+	}
+	return lines.join('\n');
+}`
+
+// RecognizeText returns the text in a screenshot, recognized on this machine with the macOS Vision
+// framework; languages are recognition languages such as "zh-Hans" and "en-US", in order of priority
+func RecognizeText(imagePath string, languages []string) (string, error) {
+	output, err := exec.Command("osascript", "-l", "JavaScript", "-e", recognizeTextScript, imagePath, strings.Join(languages, ",")).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("failed to recognize text: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to recognize text: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -108,3 +108,9 @@ func checkScreenSaverActive() (bool, error) {
 	result := strings.ToLower(strings.TrimSpace(string(output)))
 	return result == "true", nil
 }
+
+// HasScreenRecordingPermission reports whether the process is allowed to capture screen content
+// It only checks the permission (macOS 10.15+) and never shows the system prompt
+func HasScreenRecordingPermission() bool {
+	return bool(C.CGPreflightScreenCaptureAccess())
+}
@@ -0,0 +1,66 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// spacesMonitorsKey is the key path of the per-display Space lists in the com.apple.spaces preferences
+const spacesMonitorsKey = "SpacesDisplayConfiguration.Management Data.Monitors"
+
+// spaceTypeDesktop is the type of desktop Spaces; full-screen apps have their own Space types
+const spaceTypeDesktop = 0
+
+type spaceInfo struct {
+	ID   int `json:"ManagedSpaceID"`
+	Type int `json:"type"`
+}
+
+type spacesMonitor struct {
+	Current *spaceInfo  `json:"Current Space"`
+	Spaces  []spaceInfo `json:"Spaces"`
+}
+
+// CurrentSpace returns the 1-based number of the active desktop Space of the main display, as shown in
+// Mission Control, or 0 when a full-screen app is in front
+func CurrentSpace() (int, error) {
+	prefs, err := exec.Command("defaults", "export", "com.apple.spaces", "-").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Spaces preferences: %w", err)
+	}
+	extract := exec.Command("plutil", "-extract", spacesMonitorsKey, "json", "-o", "-", "-")
+	extract.Stdin = bytes.NewReader(prefs)
+	monitors, err := extract.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract Spaces: %w", err)
+	}
+	return parseCurrentSpace(monitors)
+}
+
+// parseCurrentSpace finds the current Space of the first display that has one and numbers it among
+// the desktop Spaces of that display
+func parseCurrentSpace(data []byte) (int, error) {
+	var monitors []spacesMonitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		return 0, fmt.Errorf("failed to parse Spaces: %w", err)
+	}
+	for _, m := range monitors {
+		if m.Current == nil {
+			continue
+		}
+		number := 0
+		for _, s := range m.Spaces {
+			if s.Type != spaceTypeDesktop {
+				continue
+			}
+			number++
+			if s.ID == m.Current.ID {
+				return number, nil
+			}
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("no current Space found")
+}
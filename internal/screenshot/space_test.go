@@ -0,0 +1,46 @@
+package screenshot
+
+import "testing"
+
+func TestParseCurrentSpace(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "second desktop",
+			data: `[{"Current Space":{"ManagedSpaceID":4,"type":0},"Spaces":[{"ManagedSpaceID":1,"type":0},{"ManagedSpaceID":4,"type":0}]}]`,
+			want: 2,
+		},
+		{
+			name: "full-screen apps are not numbered",
+			data: `[{"Current Space":{"ManagedSpaceID":7,"type":0},"Spaces":[{"ManagedSpaceID":1,"type":0},{"ManagedSpaceID":5,"type":4},{"ManagedSpaceID":7,"type":0}]}]`,
+			want: 2,
+		},
+		{
+			name: "full-screen app in front",
+			data: `[{"Current Space":{"ManagedSpaceID":5,"type":4},"Spaces":[{"ManagedSpaceID":1,"type":0},{"ManagedSpaceID":5,"type":4}]}]`,
+			want: 0,
+		},
+		{
+			name: "first display with a current Space",
+			data: `[{"Spaces":[]},{"Current Space":{"ManagedSpaceID":3,"type":0},"Spaces":[{"ManagedSpaceID":3,"type":0}]}]`,
+			want: 1,
+		},
+		{name: "no current Space", data: `[{"Spaces":[]}]`, wantErr: true},
+		{name: "malformed", data: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCurrentSpace([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCurrentSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCurrentSpace() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
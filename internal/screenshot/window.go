@@ -0,0 +1,87 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/kbinani/screenshot"
+)
+
+// frontmostWindowScript prints the frontmost application name, its front window title and the window
+// bounds ("x,y,width,height" in global points) on three lines
+const frontmostWindowScript = `tell application "System Events"
+	set frontApp to first application process whose frontmost is true
+	set appName to name of frontApp
+	set winTitle to ""
+	set winBounds to ""
+	try
+		set winTitle to name of front window of frontApp
+	end try
+	try
+		set {x, y} to position of front window of frontApp
+		set {w, h} to size of front window of frontApp
+		set winBounds to (x as text) & "," & (y as text) & "," & (w as text) & "," & (h as text)
+	end try
+end tell
+return appName & linefeed & winTitle & linefeed & winBounds`
+
+// Window describes the frontmost window
+type Window struct {
+	App   string
+	Title string
+	// Bounds in global points; empty if the application has no window or accessibility permission is missing
+	Bounds image.Rectangle
+}
+
+// FrontmostWindow returns the name of the frontmost application and the title of its front window
+// The title is empty if the application has no window or accessibility permission is missing
+func FrontmostWindow() (string, string, error) {
+	win, err := FrontmostWindowInfo()
+	if err != nil {
+		return "", "", err
+	}
+	return win.App, win.Title, nil
+}
+
+// FrontmostWindowInfo returns the frontmost application, its front window title and the window bounds
+func FrontmostWindowInfo() (*Window, error) {
+	output, err := exec.Command("osascript", "-e", frontmostWindowScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frontmost window: %w", err)
+	}
+
+	lines := strings.SplitN(strings.TrimRight(string(output), "\n"), "\n", 3)
+	win := &Window{App: strings.TrimSpace(lines[0])}
+	if len(lines) > 1 {
+		win.Title = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		win.Bounds = parseWindowBounds(lines[2])
+	}
+	return win, nil
+}
+
+// parseWindowBounds parses "x,y,width,height", returning an empty rectangle on malformed input
+func parseWindowBounds(s string) image.Rectangle {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}
+	}
+	var v [4]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return image.Rectangle{}
+		}
+		v[i] = n
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3])
+}
+
+// DisplayBounds returns the bounds of a display in global points
+func DisplayBounds(screenID int) image.Rectangle {
+	return screenshot.GetDisplayBounds(screenID)
+}
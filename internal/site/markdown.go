@@ -0,0 +1,248 @@
+package site
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	listItemPattern  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	tableSepPattern  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern      = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicPattern    = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	plainTextPattern = regexp.MustCompile(`[#>*|` + "`" + `]+`)
+)
+
+// listLevel is an open <ul>/<ol> while rendering nested lists
+type listLevel struct {
+	indent int
+	tag    string
+}
+
+// renderMarkdown converts the markdown used by reports to HTML: headings, paragraphs, nested lists,
+// block quotes, tables, fenced code, horizontal rules and inline bold/italic/code/links
+// Raw HTML in reports is escaped, never passed through
+func renderMarkdown(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	var sb strings.Builder
+	var para []string
+	var lists []listLevel
+
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		rendered := make([]string, len(para))
+		for i, line := range para {
+			rendered[i] = renderInline(line)
+		}
+		sb.WriteString("<p>" + strings.Join(rendered, "<br>\n") + "</p>\n")
+		para = nil
+	}
+	closeLists := func() {
+		for len(lists) > 0 {
+			sb.WriteString("</li></" + lists[len(lists)-1].tag + ">\n")
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flushPara()
+			closeLists()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if trimmed == "" {
+			flushPara()
+			closeLists()
+			continue
+		}
+
+		if level, text := heading(trimmed); level > 0 {
+			flushPara()
+			closeLists()
+			tag := "h" + string(rune('0'+level))
+			sb.WriteString("<" + tag + ">" + renderInline(text) + "</" + tag + ">\n")
+			continue
+		}
+
+		if trimmed == "---" || trimmed == "***" || trimmed == "___" {
+			flushPara()
+			closeLists()
+			sb.WriteString("<hr>\n")
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flushPara()
+			closeLists()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			sb.WriteString("<blockquote>\n" + renderMarkdown(strings.Join(quoted, "\n")) + "</blockquote>\n")
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableSepPattern.MatchString(strings.TrimSpace(lines[i+1])) {
+			flushPara()
+			closeLists()
+			sb.WriteString("<table>\n<thead><tr>")
+			for _, cell := range tableCells(trimmed) {
+				sb.WriteString("<th>" + renderInline(cell) + "</th>")
+			}
+			sb.WriteString("</tr></thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				sb.WriteString("<tr>")
+				for _, cell := range tableCells(strings.TrimSpace(lines[i])) {
+					sb.WriteString("<td>" + renderInline(cell) + "</td>")
+				}
+				sb.WriteString("</tr>\n")
+			}
+			i--
+			sb.WriteString("</tbody>\n</table>\n")
+			continue
+		}
+
+		if m := listItemPattern.FindStringSubmatch(line); m != nil {
+			flushPara()
+			indent := len(m[1])
+			tag := "ul"
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				tag = "ol"
+			}
+			for len(lists) > 0 && lists[len(lists)-1].indent > indent {
+				sb.WriteString("</li></" + lists[len(lists)-1].tag + ">\n")
+				lists = lists[:len(lists)-1]
+			}
+			if len(lists) > 0 && lists[len(lists)-1].indent == indent && lists[len(lists)-1].tag != tag {
+				sb.WriteString("</li></" + lists[len(lists)-1].tag + ">\n")
+				lists = lists[:len(lists)-1]
+			}
+			if len(lists) > 0 && lists[len(lists)-1].indent == indent {
+				sb.WriteString("</li>\n")
+			} else {
+				// A deeper list opens inside the still-open parent item
+				sb.WriteString("<" + tag + ">\n")
+				lists = append(lists, listLevel{indent: indent, tag: tag})
+			}
+			sb.WriteString("<li>" + renderInline(m[3]))
+			continue
+		}
+
+		// Indented lines continue the current list item
+		if len(lists) > 0 && line != trimmed {
+			sb.WriteString("<br>\n" + renderInline(trimmed))
+			continue
+		}
+		closeLists()
+		para = append(para, trimmed)
+	}
+	flushPara()
+	closeLists()
+	return sb.String()
+}
+
+// heading returns the level and text of an ATX heading, level 0 if the line is not a heading
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+// tableCells splits a table row into its trimmed cells
+func tableCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// renderInline escapes a line and renders inline code, links, bold and italic text
+func renderInline(text string) string {
+	parts := strings.Split(text, "`")
+	var sb strings.Builder
+	for i, part := range parts {
+		// Odd parts are inside a code span; an unmatched trailing backtick is kept literally
+		if i%2 == 1 && i < len(parts)-1 {
+			sb.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			sb.WriteString("`")
+		}
+		part = html.EscapeString(part)
+		part = linkPattern.ReplaceAllStringFunc(part, func(m string) string {
+			sub := linkPattern.FindStringSubmatch(m)
+			href := sub[2]
+			if !safeLink(href) {
+				return sub[1]
+			}
+			return `<a href="` + reportLink(href) + `">` + sub[1] + "</a>"
+		})
+		part = boldPattern.ReplaceAllString(part, "<strong>$1</strong>")
+		part = italicPattern.ReplaceAllString(part, "<em>$1</em>")
+		sb.WriteString(part)
+	}
+	return sb.String()
+}
+
+// safeLink allows web, mail and relative links only
+func safeLink(href string) bool {
+	lower := strings.ToLower(href)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:") {
+		return true
+	}
+	colon := strings.Index(lower, ":")
+	return colon < 0 || (strings.Contains(lower, "/") && strings.Index(lower, "/") < colon)
+}
+
+// reportLink points relative links to other reports at their generated pages
+func reportLink(href string) string {
+	if strings.Contains(href, "://") {
+		return href
+	}
+	path, fragment, _ := strings.Cut(href, "#")
+	if strings.HasSuffix(path, ".md") {
+		path = strings.TrimSuffix(path, ".md") + ".html"
+	}
+	if fragment != "" {
+		return path + "#" + fragment
+	}
+	return path
+}
+
+// plainText strips markdown markup for the search index
+func plainText(content string) string {
+	var fields []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || line == "---" {
+			continue
+		}
+		if m := listItemPattern.FindStringSubmatch(line); m != nil {
+			line = m[3]
+		}
+		fields = append(fields, strings.Fields(plainTextPattern.ReplaceAllString(line, " "))...)
+	}
+	return strings.Join(fields, " ")
+}
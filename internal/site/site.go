@@ -0,0 +1,557 @@
+// Package site renders the reports directory as a static HTML site that can be hosted privately:
+// one page per report, an index page per directory (year/quarter/month/week/...), breadcrumbs
+// and a client-side search index
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+// markerFile marks a directory created by Build, so rebuilding may replace it
+const markerFile = ".stuff-time-site"
+
+// searchIndexFile is loaded with a <script> tag (not fetched), so search also works from file://
+const searchIndexFile = "search-index.js"
+
+// dirKind is the period a report directory stands for
+type dirKind int
+
+const (
+	kindOther dirKind = iota
+	kindRoot
+	kindYear
+	kindQuarter
+	kindMonth
+	kindWeek
+	kindDay
+	kindWorkSegment
+	kindHour
+	kindSegment
+)
+
+var (
+	yearDirPattern        = regexp.MustCompile(`^\d{4}$`)
+	twoDigitDirPattern    = regexp.MustCompile(`^\d{2}$`)
+	quarterDirPattern     = regexp.MustCompile(`^Q(\d+)$`)
+	weekDirPattern        = regexp.MustCompile(`^W(\d+)$`)
+	workSegmentDirPattern = regexp.MustCompile(`^WS(\d+)$`)
+	segmentDirPattern     = regexp.MustCompile(`^S(\d+)$`)
+)
+
+// summaryFileOrder lists period summaries first in an index, from the longest period down
+var summaryFileOrder = []string{"quarter", "month", "week", "day", "work-segment", "hour", "summary", "fifteenmin", "notes"}
+
+// Result describes a built site
+type Result struct {
+	Pages   int // Report pages
+	Indexes int // Directory index pages
+}
+
+// Crumb is one breadcrumb link
+type Crumb struct {
+	Label string
+	URL   string
+}
+
+type node struct {
+	rel   string // Slash-separated path relative to the reports directory, "" for the root
+	label string
+	kind  dirKind
+	dirs  []*node
+	pages []*page
+	total int // Pages in this directory and below
+}
+
+type page struct {
+	rel    string // Slash-separated path of the generated page
+	title  string
+	source string
+	read   func() ([]byte, error) // Reads a report compacted into a month archive, nil for report files
+}
+
+type searchEntry struct {
+	Title string `json:"t"`
+	URL   string `json:"u"`
+	Path  string `json:"p"`
+	Text  string `json:"x"`
+}
+
+// Build renders every markdown report under reportsDir into outputDir
+// outputDir must be empty, missing, or a site built before (it is then replaced), and must not
+// overlap reportsDir
+func Build(reportsDir, outputDir, title string) (*Result, error) {
+	reportsDir, err := filepath.Abs(reportsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reports directory: %w", err)
+	}
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	if within(outputDir, reportsDir) || within(reportsDir, outputDir) {
+		return nil, fmt.Errorf("output directory %s must not overlap the reports directory %s", outputDir, reportsDir)
+	}
+	if info, err := os.Stat(reportsDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("reports directory %s does not exist", reportsDir)
+	}
+
+	root, err := scan(reportsDir, "", kindRoot, "全部报告", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareOutput(outputDir); err != nil {
+		return nil, err
+	}
+
+	b := &builder{reportsDir: reportsDir, outputDir: outputDir, title: title, generatedAt: datefmt.DateTime(time.Now())}
+	if err := b.writeNode(root, nil); err != nil {
+		return nil, err
+	}
+	if err := b.writeSearch(); err != nil {
+		return nil, err
+	}
+	return &Result{Pages: b.pages, Indexes: b.indexes}, nil
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// prepareOutput empties a previously built site (so deleted reports disappear) or creates the directory
+func prepareOutput(outputDir string) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(entries) > 0 {
+		if _, err := os.Stat(filepath.Join(outputDir, markerFile)); err != nil {
+			return fmt.Errorf("output directory %s is not empty and was not built by stuff-time", outputDir)
+		}
+		if err := os.RemoveAll(outputDir); err != nil {
+			return fmt.Errorf("failed to remove previous site: %w", err)
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(outputDir, markerFile), []byte("generated by stuff-time site build\n"), 0644)
+}
+
+// scan collects the markdown reports under dir, skipping hidden entries and directories without reports
+// Reports compacted into a month archive are included as if their files were still in place;
+// archived holds those below dir, keyed by path relative to it
+func scan(reportsDir, rel string, kind dirKind, label string, archived map[string]func() ([]byte, error)) (*node, error) {
+	dir := filepath.Join(reportsDir, filepath.FromSlash(rel))
+	entries, err := os.ReadDir(dir)
+	if err != nil && !(os.IsNotExist(err) && len(archived) > 0) {
+		return nil, fmt.Errorf("failed to read directory %s: %w", rel, err)
+	}
+	archive, err := storage.OpenReportArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+	if archive != nil {
+		archived = make(map[string]func() ([]byte, error), len(archive.Index.Entries))
+		for _, entry := range archive.Index.Entries {
+			archived[entry.Path] = func() ([]byte, error) { return archive.Read(entry) }
+		}
+	}
+
+	n := &node{rel: rel, label: label, kind: kind}
+	addDir := func(name string) error {
+		childKind, childLabel := dirLabel(kind, name)
+		child, err := scan(reportsDir, path.Join(rel, name), childKind, childLabel, archivedBelow(archived, name))
+		if err != nil {
+			return err
+		}
+		if child.total > 0 {
+			n.dirs = append(n.dirs, child)
+			n.total += child.total
+		}
+		return nil
+	}
+
+	present := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		present[name] = true
+		childRel := path.Join(rel, name)
+		if entry.IsDir() {
+			if err := addDir(name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, ".md") {
+			continue
+		}
+		n.pages = append(n.pages, &page{
+			rel:    strings.TrimSuffix(childRel, ".md") + ".html",
+			source: filepath.Join(dir, name),
+		})
+		n.total++
+	}
+
+	// Archived reports whose file or directory is gone (a file written again takes precedence)
+	missingDirs := make(map[string]bool)
+	for p, read := range archived {
+		name, _, isDir := strings.Cut(p, "/")
+		switch {
+		case present[name]:
+		case isDir:
+			missingDirs[name] = true
+		case strings.HasSuffix(name, ".md"):
+			n.pages = append(n.pages, &page{
+				rel:    strings.TrimSuffix(path.Join(rel, name), ".md") + ".html",
+				source: filepath.Join(dir, name),
+				read:   read,
+			})
+			n.total++
+		}
+	}
+	for name := range missingDirs {
+		if err := addDir(name); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(n.dirs, func(i, j int) bool { return n.dirs[i].rel < n.dirs[j].rel })
+	sort.SliceStable(n.pages, func(i, j int) bool {
+		ri, rj := fileRank(path.Base(n.pages[i].rel)), fileRank(path.Base(n.pages[j].rel))
+		if ri != rj {
+			return ri < rj
+		}
+		return n.pages[i].rel < n.pages[j].rel
+	})
+	return n, nil
+}
+
+// archivedBelow returns the archived reports under the subdirectory name, keyed by path relative to it
+func archivedBelow(archived map[string]func() ([]byte, error), name string) map[string]func() ([]byte, error) {
+	var below map[string]func() ([]byte, error)
+	for p, read := range archived {
+		if rest, ok := strings.CutPrefix(p, name+"/"); ok {
+			if below == nil {
+				below = make(map[string]func() ([]byte, error))
+			}
+			below[rest] = read
+		}
+	}
+	return below
+}
+
+// dirLabel names a directory of the YYYY/QN/MM/WN/DD/WSN/HH/SN layout; unknown directories keep their name
+func dirLabel(parent dirKind, name string) (dirKind, string) {
+	switch {
+	case parent == kindRoot && yearDirPattern.MatchString(name):
+		return kindYear, name + "年"
+	case parent == kindYear && quarterDirPattern.MatchString(name):
+		return kindQuarter, "第" + quarterDirPattern.FindStringSubmatch(name)[1] + "季度"
+	case (parent == kindYear || parent == kindQuarter) && twoDigitDirPattern.MatchString(name):
+		return kindMonth, trimZero(name) + "月"
+	case parent == kindMonth && weekDirPattern.MatchString(name):
+		return kindWeek, "第" + weekDirPattern.FindStringSubmatch(name)[1] + "周"
+	case (parent == kindMonth || parent == kindWeek) && twoDigitDirPattern.MatchString(name):
+		return kindDay, trimZero(name) + "日"
+	case parent == kindDay && workSegmentDirPattern.MatchString(name):
+		return kindWorkSegment, "工作段" + workSegmentDirPattern.FindStringSubmatch(name)[1]
+	case (parent == kindDay || parent == kindWorkSegment) && twoDigitDirPattern.MatchString(name):
+		return kindHour, trimZero(name) + "时"
+	case parent == kindHour && segmentDirPattern.MatchString(name):
+		return kindSegment, "分段" + segmentDirPattern.FindStringSubmatch(name)[1]
+	}
+	return kindOther, name
+}
+
+func trimZero(s string) string {
+	if n, err := strconv.Atoi(s); err == nil {
+		return strconv.Itoa(n)
+	}
+	return s
+}
+
+// fileRank orders period summaries before screenshot reports
+func fileRank(name string) int {
+	stem := strings.TrimSuffix(name, ".html")
+	for i, prefix := range summaryFileOrder {
+		if stem == prefix || strings.HasPrefix(stem, prefix+"-") || strings.HasSuffix(stem, "-"+prefix) {
+			return i
+		}
+	}
+	return len(summaryFileOrder)
+}
+
+type builder struct {
+	reportsDir  string
+	outputDir   string
+	title       string
+	generatedAt string
+	pages       int
+	indexes     int
+	search      []searchEntry
+}
+
+// pageData is passed to the page and index templates
+type pageData struct {
+	SiteTitle   string
+	Title       string
+	Root        string // Relative URL prefix of the site root, e.g. "../../"
+	Crumbs      []Crumb
+	Body        template.HTML
+	Dirs        []indexLink
+	Pages       []indexLink
+	GeneratedAt string
+}
+
+type indexLink struct {
+	Label string
+	URL   string
+	Count int
+}
+
+// writeNode writes the pages of a directory, its subdirectories and finally its index
+// (page titles are read while writing the pages, so the index lists them)
+func (b *builder) writeNode(n *node, parents []*node) error {
+	depth := 0
+	if n.rel != "" {
+		depth = strings.Count(n.rel, "/") + 1
+	}
+	root := strings.Repeat("../", depth)
+
+	// Breadcrumbs of the index page: every ancestor, relative to this directory
+	var crumbs []Crumb
+	for i, parent := range parents {
+		crumbs = append(crumbs, Crumb{Label: parent.label, URL: strings.Repeat("../", len(parents)-i) + "index.html"})
+	}
+	pageCrumbs := append(append([]Crumb{}, crumbs...), Crumb{Label: n.label, URL: "index.html"})
+
+	for _, child := range n.dirs {
+		if err := b.writeNode(child, append(append([]*node{}, parents...), n)); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range n.pages {
+		if err := b.writePage(p, root, pageCrumbs); err != nil {
+			return err
+		}
+	}
+
+	data := pageData{SiteTitle: b.title, Title: n.label, Root: root, Crumbs: crumbs, GeneratedAt: b.generatedAt}
+	if n.rel == "" {
+		data.Title = b.title
+	}
+	for _, child := range n.dirs {
+		data.Dirs = append(data.Dirs, indexLink{Label: child.label, URL: path.Base(child.rel) + "/index.html", Count: child.total})
+	}
+	for _, p := range n.pages {
+		data.Pages = append(data.Pages, indexLink{Label: p.title, URL: path.Base(p.rel)})
+	}
+	if err := b.render(indexTemplate, path.Join(n.rel, "index.html"), data); err != nil {
+		return err
+	}
+	b.indexes++
+	return nil
+}
+
+func (b *builder) writePage(p *page, root string, crumbs []Crumb) error {
+	var content []byte
+	var err error
+	if p.read != nil {
+		content, err = p.read()
+	} else {
+		content, err = os.ReadFile(p.source)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read report %s: %w", p.source, err)
+	}
+	text := string(content)
+
+	p.title = storage.ParseReportDocument(text).Title
+	if p.title == "" {
+		p.title = strings.TrimSuffix(path.Base(p.rel), ".html")
+	}
+
+	data := pageData{
+		SiteTitle:   b.title,
+		Title:       p.title,
+		Root:        root,
+		Crumbs:      crumbs,
+		Body:        template.HTML(renderMarkdown(text)),
+		GeneratedAt: b.generatedAt,
+	}
+	if err := b.render(pageTemplate, p.rel, data); err != nil {
+		return err
+	}
+
+	labels := make([]string, 0, len(crumbs))
+	for _, crumb := range crumbs[1:] {
+		labels = append(labels, crumb.Label)
+	}
+	b.search = append(b.search, searchEntry{Title: p.title, URL: p.rel, Path: strings.Join(labels, " / "), Text: plainText(text)})
+	b.pages++
+	return nil
+}
+
+func (b *builder) render(tmpl *template.Template, rel string, data pageData) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", rel, err)
+	}
+	target := filepath.Join(b.outputDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	return nil
+}
+
+// writeSearch writes the search page and the search index (newest reports first)
+func (b *builder) writeSearch() error {
+	sort.SliceStable(b.search, func(i, j int) bool { return b.search[i].URL > b.search[j].URL })
+	index, err := json.Marshal(b.search)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	script := append([]byte("var SEARCH_INDEX = "), index...)
+	script = append(script, ";\n"...)
+	if err := os.WriteFile(filepath.Join(b.outputDir, searchIndexFile), script, 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	data := pageData{SiteTitle: b.title, Title: "搜索", Crumbs: []Crumb{{Label: "全部报告", URL: "index.html"}}, GeneratedAt: b.generatedAt}
+	return b.render(searchTemplate, "search.html", data)
+}
+
+const pageHead = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Title}} · {{.SiteTitle}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", sans-serif; margin: 0; background: #fafafa; color: #222; line-height: 1.6; }
+header { background: #fff; border-bottom: 1px solid #e5e5e5; padding: 10px 24px; display: flex; justify-content: space-between; }
+header a { color: #222; text-decoration: none; font-weight: 600; }
+main { max-width: 900px; margin: 0 auto; padding: 16px 24px 48px; }
+nav.crumbs { font-size: 13px; color: #888; margin-bottom: 12px; }
+nav.crumbs a { color: #3867d6; text-decoration: none; }
+a { color: #3867d6; }
+h1 { font-size: 24px; }
+pre { background: #f0f0f0; padding: 10px; overflow-x: auto; }
+code { background: #f0f0f0; padding: 0 3px; }
+pre code { padding: 0; }
+blockquote { border-left: 3px solid #ddd; margin-left: 0; padding-left: 12px; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; }
+ul.index { list-style: none; padding-left: 0; }
+ul.index li { padding: 4px 0; border-bottom: 1px solid #eee; }
+.count, .meta { color: #888; font-size: 13px; }
+#results li { margin-bottom: 10px; }
+</style>
+</head>
+<body>
+<header><a href="{{.Root}}index.html">{{.SiteTitle}}</a><a href="{{.Root}}search.html">搜索</a></header>
+<main>
+{{- if .Crumbs}}
+<nav class="crumbs">{{range $i, $c := .Crumbs}}{{if $i}} / {{end}}<a href="{{$c.URL}}">{{$c.Label}}</a>{{end}}</nav>
+{{- end}}
+`
+
+const pageFoot = `
+<p class="meta">生成时间 {{.GeneratedAt}}</p>
+</main>
+</body>
+</html>
+`
+
+var pageTemplate = template.Must(template.New("page").Parse(pageHead + `<article>
+{{.Body}}
+</article>` + pageFoot))
+
+var indexTemplate = template.Must(template.New("index").Parse(pageHead + `<h1>{{.Title}}</h1>
+{{- if .Pages}}
+<h2>总结与报告</h2>
+<ul class="index">
+{{- range .Pages}}
+<li><a href="{{.URL}}">{{.Label}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Dirs}}
+<h2>目录</h2>
+<ul class="index">
+{{- range .Dirs}}
+<li><a href="{{.URL}}">{{.Label}}</a> <span class="count">{{.Count}} 篇</span></li>
+{{- end}}
+</ul>
+{{- end}}` + pageFoot))
+
+var searchTemplate = template.Must(template.New("search").Parse(pageHead + `<h1>搜索</h1>
+<input id="q" type="search" placeholder="输入关键词（空格分隔）" style="width: 100%; font-size: 16px; padding: 6px;" autofocus>
+<p class="meta" id="summary"></p>
+<ul id="results" class="index"></ul>
+<script src="search-index.js"></script>
+<script>
+(function () {
+  var input = document.getElementById("q");
+  var results = document.getElementById("results");
+  var summary = document.getElementById("summary");
+  function snippet(text, term) {
+    var i = text.toLowerCase().indexOf(term);
+    if (i < 0) return text.slice(0, 120);
+    var start = Math.max(0, i - 40);
+    return (start > 0 ? "…" : "") + text.slice(start, i + 80) + "…";
+  }
+  function search() {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.textContent = "";
+    if (!terms.length) { summary.textContent = ""; return; }
+    var hits = SEARCH_INDEX.filter(function (e) {
+      var hay = (e.t + " " + e.p + " " + e.x).toLowerCase();
+      return terms.every(function (t) { return hay.indexOf(t) >= 0; });
+    });
+    summary.textContent = "找到 " + hits.length + " 篇" + (hits.length > 200 ? "，显示前 200 篇" : "");
+    hits.slice(0, 200).forEach(function (e) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = e.u;
+      a.textContent = e.t;
+      var path = document.createElement("div");
+      path.className = "count";
+      path.textContent = e.p;
+      var text = document.createElement("div");
+      text.textContent = snippet(e.x, terms[0]);
+      li.appendChild(a);
+      li.appendChild(path);
+      li.appendChild(text);
+      results.appendChild(li);
+    });
+  }
+  input.addEventListener("input", search);
+  var q = new URLSearchParams(location.search).get("q");
+  if (q) { input.value = q; search(); }
+})();
+</script>` + pageFoot))
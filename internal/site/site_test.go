@@ -0,0 +1,161 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+	}{
+		{
+			name:     "headings and metadata lines",
+			input:    "# 日总结\n\n**时间段**: 09:00\n**截图数量**: 3",
+			contains: []string{"<h1>日总结</h1>", "<p><strong>时间段</strong>: 09:00<br>\n<strong>截图数量</strong>: 3</p>"},
+		},
+		{
+			name:     "nested lists",
+			input:    "- a\n  - b\n- c",
+			contains: []string{"<ul>\n<li>a<ul>\n<li>b</li></ul>\n</li>\n<li>c</li></ul>"},
+		},
+		{
+			name:     "html is escaped and unsafe links dropped",
+			input:    "<script>x</script> [a](javascript:void) [b](../day.md)",
+			contains: []string{"&lt;script&gt;", " a ", `<a href="../day.html">b</a>`},
+		},
+		{
+			name:     "code fence is not rendered",
+			input:    "```\n# not a heading\n**x**\n```",
+			contains: []string{"<pre><code># not a heading\n**x**</code></pre>"},
+		},
+		{
+			name:     "table",
+			input:    "| 项目 | 时长 |\n|---|---|\n| a | 1h |",
+			contains: []string{"<th>项目</th>", "<td>1h</td>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderMarkdown(tt.input)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderMarkdown() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	reports := t.TempDir()
+	writeReport(t, reports, "2025/Q4/12/month.md", "# 月总结\n\n本月完成迁移")
+	writeReport(t, reports, "2025/Q4/12/W2/09/day.md", "# 2025-12-09 日总结\n\n编写代码")
+	writeReport(t, reports, "2025/Q4/12/W2/09/14/05.md", "# 截图分析\n\n调试测试")
+	writeReport(t, reports, "2025/Q4/empty/.keep", "")
+
+	output := filepath.Join(t.TempDir(), "site")
+	result, err := Build(reports, output, "工作报告")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if result.Pages != 3 || result.Indexes != 7 {
+		t.Errorf("Build() = %+v, want 3 pages and 7 indexes", result)
+	}
+
+	day := readFile(t, filepath.Join(output, "2025/Q4/12/W2/09/day.html"))
+	for _, want := range []string{
+		`<a href="../../../../../index.html">全部报告</a>`,
+		`<a href="../../index.html">12月</a>`,
+		`<a href="index.html">9日</a>`,
+		"<h1>2025-12-09 日总结</h1>",
+	} {
+		if !strings.Contains(day, want) {
+			t.Errorf("day page does not contain %q", want)
+		}
+	}
+	dayIndex := readFile(t, filepath.Join(output, "2025/Q4/12/W2/09/index.html"))
+	if !strings.Contains(dayIndex, `<a href="day.html">2025-12-09 日总结</a>`) || !strings.Contains(dayIndex, `<a href="14/index.html">14时</a>`) {
+		t.Errorf("day index does not list the summary and the hour: %s", dayIndex)
+	}
+	if _, err := os.Stat(filepath.Join(output, "2025/Q4/empty")); !os.IsNotExist(err) {
+		t.Errorf("directory without reports should be skipped")
+	}
+	if index := readFile(t, filepath.Join(output, searchIndexFile)); !strings.Contains(index, "调试测试") {
+		t.Errorf("search index does not contain report text: %s", index)
+	}
+
+	// Rebuilding replaces the previous site
+	os.Remove(filepath.Join(reports, "2025/Q4/12/month.md"))
+	if _, err := Build(reports, output, "工作报告"); err != nil {
+		t.Fatalf("rebuild error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "2025/Q4/12/month.html")); !os.IsNotExist(err) {
+		t.Errorf("page of a deleted report should be removed on rebuild")
+	}
+
+	// Foreign directories and directories overlapping the reports are refused
+	foreign := t.TempDir()
+	writeReport(t, foreign, "keep.txt", "x")
+	if _, err := Build(reports, foreign, "工作报告"); err == nil {
+		t.Errorf("Build() into a non-empty foreign directory should fail")
+	}
+	if _, err := Build(reports, filepath.Join(reports, "site"), "工作报告"); err == nil {
+		t.Errorf("Build() into the reports directory should fail")
+	}
+}
+
+func writeReport(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBuildArchivedReports(t *testing.T) {
+	reports := t.TempDir()
+	writeReport(t, reports, "2025/Q4/12/W2/09/day.md", "# 2025-12-09 日总结\n\n编写代码")
+	writeReport(t, reports, "2025/Q4/12/W2/09/14/hour.md", "# 小时总结\n\n调试测试")
+	writeReport(t, reports, "2025/Q4/12/W2/09/14/05.md", "# 截图分析\n\n调试测试")
+	writeReport(t, reports, "2025/Q4/12/W2/09/15/hour.md", "# 小时总结\n\n代码评审")
+	if _, err := storage.CompactReports(reports, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), false); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "site")
+	result, err := Build(reports, output, "工作报告")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if result.Pages != 4 {
+		t.Errorf("Build() = %+v, want 4 pages", result)
+	}
+	// The hour directory left empty by compaction is listed from the archive
+	if page := readFile(t, filepath.Join(output, "2025/Q4/12/W2/09/15/hour.html")); !strings.Contains(page, "代码评审") {
+		t.Errorf("archived hour page does not contain the report: %s", page)
+	}
+	if page := readFile(t, filepath.Join(output, "2025/Q4/12/W2/09/14/hour.html")); !strings.Contains(page, "调试测试") {
+		t.Errorf("archived hour page next to a screenshot report does not contain the report: %s", page)
+	}
+}
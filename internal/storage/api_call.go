@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// APICall records the token usage, latency and outcome of one request to the LLM provider
+type APICall struct {
+	ID               string
+	Timestamp        time.Time
+	Model            string
+	Purpose          string // e.g. "screenshot_analysis", "summary"
+	Level            string // Summary period type, empty for other purposes
+	PromptTokens     int
+	CompletionTokens int
+	CachedTokens     int
+	LatencyMs        int64
+	Error            string // Empty for a successful request
+	StatusCode       int    // HTTP status of a refused request (429 when rate limited), 0 otherwise
+}
+
+// APICallUsage is the summed usage of the calls to one model
+type APICallUsage struct {
+	Model            string
+	Calls            int
+	Failed           int
+	PromptTokens     int // Successful calls only, like the other token counts
+	CompletionTokens int
+	CachedTokens     int
+}
+
+// DedupSaving records sibling summary lines removed by de-duplication before a higher-level summary
+type DedupSaving struct {
+	Timestamp time.Time
+	PeriodKey string
+	Lines     int
+	Tokens    int // Estimated prompt tokens saved
+}
+
+// APICallStore stores the API call log and de-duplication savings for the cost report
+type APICallStore interface {
+	AddAPICall(call *APICall) error
+	// QueryAPICalls returns calls in [start, end), oldest first
+	QueryAPICalls(start, end time.Time) ([]*APICall, error)
+	// SumAPICallUsage returns the usage of the calls in [start, end) per model
+	SumAPICallUsage(start, end time.Time) ([]*APICallUsage, error)
+	AddDedupSaving(saving *DedupSaving) error
+	// QueryDedupSavings returns savings in [start, end), oldest first
+	QueryDedupSavings(start, end time.Time) ([]*DedupSaving, error)
+}
+
+func (s *SQLiteStorage) initAPICallTable() error {
+	createAPICallTable := `
+	CREATE TABLE IF NOT EXISTS api_calls (
+		id TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		model TEXT,
+		purpose TEXT,
+		level TEXT,
+		prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		completion_tokens INTEGER NOT NULL DEFAULT 0,
+		cached_tokens INTEGER NOT NULL DEFAULT 0,
+		latency_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_calls_timestamp ON api_calls(timestamp);
+
+	CREATE TABLE IF NOT EXISTS dedup_savings (
+		timestamp DATETIME NOT NULL,
+		period_key TEXT NOT NULL,
+		lines INTEGER NOT NULL,
+		tokens INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_dedup_savings_timestamp ON dedup_savings(timestamp);
+	`
+	if _, err := s.db.Exec(createAPICallTable); err != nil {
+		return fmt.Errorf("failed to create api_calls table: %w", err)
+	}
+	// Column may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE api_calls ADD COLUMN status_code INTEGER NOT NULL DEFAULT 0")
+	return nil
+}
+
+func (s *SQLiteStorage) AddAPICall(call *APICall) error {
+	if call.ID == "" {
+		call.ID = generateID()
+	}
+	if call.Timestamp.IsZero() {
+		call.Timestamp = time.Now()
+	}
+	query := `
+	INSERT INTO api_calls (id, timestamp, model, purpose, level, prompt_tokens, completion_tokens, cached_tokens, latency_ms, error, status_code)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, call.ID, call.Timestamp.Format(time.RFC3339Nano), call.Model, call.Purpose, call.Level,
+		call.PromptTokens, call.CompletionTokens, call.CachedTokens, call.LatencyMs, call.Error, call.StatusCode); err != nil {
+		return fmt.Errorf("failed to add API call: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryAPICalls(start, end time.Time) ([]*APICall, error) {
+	query := `
+	SELECT id, timestamp, COALESCE(model, ''), COALESCE(purpose, ''), COALESCE(level, ''),
+		prompt_tokens, completion_tokens, cached_tokens, latency_ms, COALESCE(error, ''), status_code
+	FROM api_calls
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API calls: %w", err)
+	}
+	defer rows.Close()
+
+	var calls []*APICall
+	for rows.Next() {
+		var call APICall
+		var timestampStr string
+		if err := rows.Scan(&call.ID, &timestampStr, &call.Model, &call.Purpose, &call.Level,
+			&call.PromptTokens, &call.CompletionTokens, &call.CachedTokens, &call.LatencyMs, &call.Error, &call.StatusCode); err != nil {
+			return nil, fmt.Errorf("failed to scan API call: %w", err)
+		}
+		if call.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		calls = append(calls, &call)
+	}
+	return calls, rows.Err()
+}
+
+func (s *SQLiteStorage) SumAPICallUsage(start, end time.Time) ([]*APICallUsage, error) {
+	query := `
+	SELECT COALESCE(model, ''), COUNT(*),
+		COALESCE(SUM(CASE WHEN COALESCE(error, '') != '' THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN COALESCE(error, '') = '' THEN prompt_tokens ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN COALESCE(error, '') = '' THEN completion_tokens ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN COALESCE(error, '') = '' THEN cached_tokens ELSE 0 END), 0)
+	FROM api_calls
+	WHERE timestamp >= ? AND timestamp < ?
+	GROUP BY COALESCE(model, '')
+	ORDER BY 1
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum API call usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*APICallUsage
+	for rows.Next() {
+		var u APICallUsage
+		if err := rows.Scan(&u.Model, &u.Calls, &u.Failed, &u.PromptTokens, &u.CompletionTokens, &u.CachedTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan API call usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+	return usage, rows.Err()
+}
+
+func (s *SQLiteStorage) AddDedupSaving(saving *DedupSaving) error {
+	saving.PeriodKey = NormalizePeriodKey(saving.PeriodKey)
+	if saving.Timestamp.IsZero() {
+		saving.Timestamp = time.Now()
+	}
+	query := `INSERT INTO dedup_savings (timestamp, period_key, lines, tokens) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, saving.Timestamp.Format(time.RFC3339Nano), saving.PeriodKey, saving.Lines, saving.Tokens); err != nil {
+		return fmt.Errorf("failed to add dedup saving: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryDedupSavings(start, end time.Time) ([]*DedupSaving, error) {
+	query := `
+	SELECT timestamp, period_key, lines, tokens
+	FROM dedup_savings
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dedup savings: %w", err)
+	}
+	defer rows.Close()
+
+	var savings []*DedupSaving
+	for rows.Next() {
+		var saving DedupSaving
+		var timestampStr string
+		if err := rows.Scan(&timestampStr, &saving.PeriodKey, &saving.Lines, &saving.Tokens); err != nil {
+			return nil, fmt.Errorf("failed to scan dedup saving: %w", err)
+		}
+		if saving.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		savings = append(savings, &saving)
+	}
+	return savings, rows.Err()
+}
+
+func (r *ReportStorage) AddAPICall(call *APICall) error {
+	return r.metadataStorage.AddAPICall(call)
+}
+
+func (r *ReportStorage) QueryAPICalls(start, end time.Time) ([]*APICall, error) {
+	return r.metadataStorage.QueryAPICalls(start, end)
+}
+
+func (r *ReportStorage) SumAPICallUsage(start, end time.Time) ([]*APICallUsage, error) {
+	return r.metadataStorage.SumAPICallUsage(start, end)
+}
+
+func (r *ReportStorage) AddDedupSaving(saving *DedupSaving) error {
+	return r.metadataStorage.AddDedupSaving(saving)
+}
+
+func (r *ReportStorage) QueryDedupSavings(start, end time.Time) ([]*DedupSaving, error) {
+	return r.metadataStorage.QueryDedupSavings(start, end)
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attachment kinds
+const (
+	AttachmentResearchLog = "research_log"
+	AttachmentMeetingNote = "meeting_note"
+	AttachmentJournal     = "journal" // End-of-day reflection answers (Title is the question, Content the answer)
+)
+
+// PeriodAttachment is an extra generated document attached to a period report (research log, meeting note, ...)
+type PeriodAttachment struct {
+	ID        string    `db:"id"`
+	PeriodKey string    `db:"period_key"`
+	Kind      string    `db:"kind"`
+	Title     string    `db:"title"`
+	StartTime time.Time `db:"start_time"` // Time range the attachment covers
+	EndTime   time.Time `db:"end_time"`
+	Content   string    `db:"content"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AttachmentStore stores period attachments
+type AttachmentStore interface {
+	// ReplaceAttachments replaces all attachments of the given kind for a period
+	ReplaceAttachments(periodKey, kind string, attachments []*PeriodAttachment) error
+	// GetAttachments returns the attachments of a period, optionally filtered by kind (empty for all)
+	GetAttachments(periodKey, kind string) ([]*PeriodAttachment, error)
+}
+
+func (s *SQLiteStorage) initAttachmentTable() error {
+	createAttachmentTable := `
+	CREATE TABLE IF NOT EXISTS period_attachments (
+		id TEXT PRIMARY KEY,
+		period_key TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		title TEXT,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME NOT NULL,
+		content TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_period_attachments_period ON period_attachments(period_key, kind);
+	`
+	if _, err := s.db.Exec(createAttachmentTable); err != nil {
+		return fmt.Errorf("failed to create period_attachments table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ReplaceAttachments(periodKey, kind string, attachments []*PeriodAttachment) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM period_attachments WHERE period_key = ? AND kind = ?`, periodKey, kind); err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+
+	query := `
+	INSERT INTO period_attachments (id, period_key, kind, title, start_time, end_time, content, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, a := range attachments {
+		if a.ID == "" {
+			a.ID = generateID()
+		}
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = time.Now()
+		}
+		a.PeriodKey = periodKey
+		a.Kind = kind
+		if _, err := tx.Exec(query, a.ID, a.PeriodKey, a.Kind, a.Title,
+			a.StartTime.Format(time.RFC3339Nano), a.EndTime.Format(time.RFC3339Nano), a.Content, a.CreatedAt.Format(time.RFC3339Nano)); err != nil {
+			return fmt.Errorf("failed to insert attachment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit attachments: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetAttachments(periodKey, kind string) ([]*PeriodAttachment, error) {
+	periodKey = NormalizePeriodKey(periodKey)
+	query := `
+	SELECT id, period_key, kind, COALESCE(title, ''), start_time, end_time, content, created_at
+	FROM period_attachments
+	WHERE period_key = ? AND (? = '' OR kind = ?)
+	ORDER BY start_time ASC, rowid ASC
+	`
+	rows, err := s.db.Query(query, periodKey, kind, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*PeriodAttachment
+	for rows.Next() {
+		var a PeriodAttachment
+		var startStr, endStr, createdStr string
+		if err := rows.Scan(&a.ID, &a.PeriodKey, &a.Kind, &a.Title, &startStr, &endStr, &a.Content, &createdStr); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		if a.StartTime, err = time.Parse(time.RFC3339Nano, startStr); err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		if a.EndTime, err = time.Parse(time.RFC3339Nano, endStr); err != nil {
+			return nil, fmt.Errorf("failed to parse end_time: %w", err)
+		}
+		a.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		attachments = append(attachments, &a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *ReportStorage) ReplaceAttachments(periodKey, kind string, attachments []*PeriodAttachment) error {
+	return r.metadataStorage.ReplaceAttachments(periodKey, kind, attachments)
+}
+
+func (r *ReportStorage) GetAttachments(periodKey, kind string) ([]*PeriodAttachment, error) {
+	return r.metadataStorage.GetAttachments(periodKey, kind)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditEntry records a user-initiated destructive or privacy-relevant operation
+type AuditEntry struct {
+	ID        string    `db:"id"`
+	Timestamp time.Time `db:"timestamp"`
+	Action    string    `db:"action"`  // e.g. "forget"
+	Details   string    `db:"details"` // Human-readable description of what was affected
+}
+
+// NewAuditEntry creates an audit entry with a generated ID and current timestamp
+func NewAuditEntry(action, details string) *AuditEntry {
+	return &AuditEntry{
+		ID:        generateID(),
+		Timestamp: time.Now(),
+		Action:    action,
+		Details:   details,
+	}
+}
+
+// AuditStore stores the audit log
+type AuditStore interface {
+	AddAuditEntry(entry *AuditEntry) error
+	QueryAuditEntries(action string, start, end time.Time) ([]*AuditEntry, error)
+	// DeleteDerivedData deletes the data derived from the given periods and days (YYYY-MM-DD) in one
+	// transaction, along with all intermediate tree aggregation outputs, since they may quote forgotten content
+	DeleteDerivedData(periodKeys, dayKeys []string) error
+}
+
+// periodDerivedTables are the tables keyed by period_key whose rows are derived from the period's summary
+// or screenshots. A store adding such a table must list it here so forget removes it
+var periodDerivedTables = []string{
+	"deliverables",
+	"period_issues",
+	"period_attachments",
+	"golden_periods",
+	"remote_summaries",
+	"summary_lineage",
+}
+
+// dayDerivedDeletes delete the rows derived from a day's screenshots, keyed by the day (YYYY-MM-DD)
+var dayDerivedDeletes = []string{
+	`DELETE FROM focus_scores WHERE date_key = ?`,
+	`DELETE FROM task_thread_members WHERE thread_id IN (SELECT id FROM task_threads WHERE day_key = ?)`,
+	`DELETE FROM task_threads WHERE day_key = ?`,
+}
+
+func (s *SQLiteStorage) initAuditTable() error {
+	createAuditTable := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		action TEXT NOT NULL,
+		details TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp);
+	`
+	if _, err := s.db.Exec(createAuditTable); err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddAuditEntry(entry *AuditEntry) error {
+	query := `INSERT INTO audit_log (id, timestamp, action, details) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, entry.ID, entry.Timestamp.Format(time.RFC3339Nano), entry.Action, entry.Details); err != nil {
+		return fmt.Errorf("failed to add audit entry: %w", err)
+	}
+	return nil
+}
+
+// QueryAuditEntries returns audit entries in [start, end), optionally filtered by action
+func (s *SQLiteStorage) QueryAuditEntries(action string, start, end time.Time) ([]*AuditEntry, error) {
+	query := `
+	SELECT id, timestamp, action, COALESCE(details, '')
+	FROM audit_log
+	WHERE timestamp >= ? AND timestamp < ? AND (? = '' OR action = ?)
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), action, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		var timestampStr string
+		if err := rows.Scan(&entry.ID, &timestampStr, &entry.Action, &entry.Details); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *SQLiteStorage) DeleteDerivedData(periodKeys, dayKeys []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, periodKey := range periodKeys {
+		periodKey = NormalizePeriodKey(periodKey)
+		for _, table := range periodDerivedTables {
+			if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE period_key = ?`, table), periodKey); err != nil {
+				return fmt.Errorf("failed to delete %s of %s: %w", table, periodKey, err)
+			}
+		}
+	}
+	for _, dayKey := range dayKeys {
+		for _, query := range dayDerivedDeletes {
+			if _, err := tx.Exec(query, dayKey); err != nil {
+				return fmt.Errorf("failed to delete derived data of %s: %w", dayKey, err)
+			}
+		}
+	}
+	// Tree sessions are keyed by a hash of their inputs, not the period; they only let an interrupted
+	// aggregation resume, so all of them are dropped
+	if _, err := tx.Exec(`DELETE FROM tree_aggregation_pairs`); err != nil {
+		return fmt.Errorf("failed to delete tree aggregation pairs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit derived data deletion: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) AddAuditEntry(entry *AuditEntry) error {
+	return r.metadataStorage.AddAuditEntry(entry)
+}
+
+func (r *ReportStorage) QueryAuditEntries(action string, start, end time.Time) ([]*AuditEntry, error) {
+	return r.metadataStorage.QueryAuditEntries(action, start, end)
+}
+
+func (r *ReportStorage) DeleteDerivedData(periodKeys, dayKeys []string) error {
+	return r.metadataStorage.DeleteDerivedData(periodKeys, dayKeys)
+}
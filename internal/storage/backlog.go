@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// backlogRetention is how long backlog samples are kept
+const backlogRetention = 7 * 24 * time.Hour
+
+// BacklogSample is the number of screenshots waiting for analysis at a point in time
+type BacklogSample struct {
+	Timestamp time.Time `json:"time"`
+	Pending   int       `json:"pending"`
+}
+
+// BacklogStore tracks the size of the unanalyzed screenshot backlog over time
+type BacklogStore interface {
+	// CountUnanalyzedScreenshots returns the number of pending or failed screenshots
+	CountUnanalyzedScreenshots() (int, error)
+	// AddBacklogSample records a backlog size, dropping samples older than a week
+	AddBacklogSample(sample *BacklogSample) error
+	// QueryBacklogSamples returns samples in [start, end), oldest first
+	QueryBacklogSamples(start, end time.Time) ([]*BacklogSample, error)
+}
+
+func (s *SQLiteStorage) initBacklogTable() error {
+	createBacklogTable := `
+	CREATE TABLE IF NOT EXISTS backlog_samples (
+		timestamp DATETIME NOT NULL,
+		pending INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_backlog_samples_timestamp ON backlog_samples(timestamp);
+	`
+	if _, err := s.db.Exec(createBacklogTable); err != nil {
+		return fmt.Errorf("failed to create backlog_samples table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) CountUnanalyzedScreenshots() (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM screenshots WHERE analysis_status IN ('pending', 'failed')`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unanalyzed screenshots: %w", err)
+	}
+	return count, nil
+}
+
+func (s *SQLiteStorage) AddBacklogSample(sample *BacklogSample) error {
+	if sample.Timestamp.IsZero() {
+		sample.Timestamp = time.Now()
+	}
+	if _, err := s.db.Exec(`INSERT INTO backlog_samples (timestamp, pending) VALUES (?, ?)`,
+		sample.Timestamp.Format(time.RFC3339Nano), sample.Pending); err != nil {
+		return fmt.Errorf("failed to add backlog sample: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM backlog_samples WHERE timestamp < ?`,
+		sample.Timestamp.Add(-backlogRetention).Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to delete old backlog samples: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryBacklogSamples(start, end time.Time) ([]*BacklogSample, error) {
+	rows, err := s.db.Query(`SELECT timestamp, pending FROM backlog_samples WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp ASC`,
+		start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backlog samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []*BacklogSample
+	for rows.Next() {
+		var sample BacklogSample
+		var timestampStr string
+		if err := rows.Scan(&timestampStr, &sample.Pending); err != nil {
+			return nil, fmt.Errorf("failed to scan backlog sample: %w", err)
+		}
+		if sample.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		samples = append(samples, &sample)
+	}
+	return samples, rows.Err()
+}
+
+func (r *ReportStorage) CountUnanalyzedScreenshots() (int, error) {
+	return r.metadataStorage.CountUnanalyzedScreenshots()
+}
+
+func (r *ReportStorage) AddBacklogSample(sample *BacklogSample) error {
+	return r.metadataStorage.AddBacklogSample(sample)
+}
+
+func (r *ReportStorage) QueryBacklogSamples(start, end time.Time) ([]*BacklogSample, error) {
+	return r.metadataStorage.QueryBacklogSamples(start, end)
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBacklogSamples(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	for i, status := range []string{"pending", "failed", "completed"} {
+		record := &ScreenshotRecord{ID: string(rune('a' + i)), Timestamp: base, HourKey: base.Format("2006-01-02-15"), AnalysisStatus: status}
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pending, err := st.CountUnanalyzedScreenshots(); err != nil || pending != 2 {
+		t.Errorf("CountUnanalyzedScreenshots() = %d, %v, want 2", pending, err)
+	}
+
+	for i, pending := range []int{120, 80, 40} {
+		if err := st.AddBacklogSample(&BacklogSample{Timestamp: base.Add(time.Duration(i) * 96 * time.Hour), Pending: pending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples, err := st.QueryBacklogSamples(base.Add(-time.Hour), base.Add(200*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first sample is more than a week older than the last one
+	if len(samples) != 2 || samples[0].Pending != 80 || samples[1].Pending != 40 {
+		t.Errorf("QueryBacklogSamples() = %+v, %+v", samples[0], samples[len(samples)-1])
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"image"
+)
+
+// WindowBounds is the frontmost window when a screenshot was captured, in points relative to the
+// captured display; used to crop the image uploaded for analysis to the active window
+// Bounds are keyed by image path, so they survive rebuilds (which re-import screenshots with new IDs)
+type WindowBounds struct {
+	ImagePath     string `db:"image_path"`
+	X             int    `db:"x"`
+	Y             int    `db:"y"`
+	Width         int    `db:"width"`
+	Height        int    `db:"height"`
+	DisplayWidth  int    `db:"display_width"`
+	DisplayHeight int    `db:"display_height"`
+}
+
+// CropRect returns the window region, grown by padding points on each side, in pixels of an image of
+// the given size (the image may be scaled relative to the display, e.g. Retina captures)
+// Returns an empty rectangle if the window is not visible on the display
+func (b *WindowBounds) CropRect(size image.Point, padding int) image.Rectangle {
+	if b.DisplayWidth <= 0 || b.DisplayHeight <= 0 {
+		return image.Rectangle{}
+	}
+	scaleX := float64(size.X) / float64(b.DisplayWidth)
+	scaleY := float64(size.Y) / float64(b.DisplayHeight)
+	rect := image.Rect(
+		int(float64(b.X-padding)*scaleX),
+		int(float64(b.Y-padding)*scaleY),
+		int(float64(b.X+b.Width+padding)*scaleX),
+		int(float64(b.Y+b.Height+padding)*scaleY),
+	)
+	return rect.Intersect(image.Rectangle{Max: size})
+}
+
+// WindowBoundsStore stores the frontmost window bounds of screenshots
+type WindowBoundsStore interface {
+	SaveWindowBounds(bounds *WindowBounds) error
+	// GetWindowBounds returns nil if no bounds were recorded for the screenshot image
+	GetWindowBounds(imagePath string) (*WindowBounds, error)
+}
+
+func (s *SQLiteStorage) initWindowBoundsTable() error {
+	createWindowBoundsTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_window_bounds (
+		image_path TEXT PRIMARY KEY,
+		x INTEGER NOT NULL,
+		y INTEGER NOT NULL,
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		display_width INTEGER NOT NULL,
+		display_height INTEGER NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createWindowBoundsTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_window_bounds table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveWindowBounds(bounds *WindowBounds) error {
+	query := `
+	INSERT OR REPLACE INTO screenshot_window_bounds (image_path, x, y, width, height, display_width, display_height)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, bounds.ImagePath, bounds.X, bounds.Y, bounds.Width, bounds.Height, bounds.DisplayWidth, bounds.DisplayHeight); err != nil {
+		return fmt.Errorf("failed to save window bounds: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetWindowBounds(imagePath string) (*WindowBounds, error) {
+	query := `SELECT image_path, x, y, width, height, display_width, display_height FROM screenshot_window_bounds WHERE image_path = ?`
+	var b WindowBounds
+	err := s.db.QueryRow(query, imagePath).Scan(&b.ImagePath, &b.X, &b.Y, &b.Width, &b.Height, &b.DisplayWidth, &b.DisplayHeight)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get window bounds: %w", err)
+	}
+	return &b, nil
+}
+
+func (r *ReportStorage) SaveWindowBounds(bounds *WindowBounds) error {
+	return r.metadataStorage.SaveWindowBounds(bounds)
+}
+
+func (r *ReportStorage) GetWindowBounds(imagePath string) (*WindowBounds, error) {
+	return r.metadataStorage.GetWindowBounds(imagePath)
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWindowBoundsCropRect(t *testing.T) {
+	bounds := &WindowBounds{X: 100, Y: 50, Width: 800, Height: 600, DisplayWidth: 1440, DisplayHeight: 900}
+
+	tests := []struct {
+		name    string
+		size    image.Point
+		padding int
+		want    image.Rectangle
+	}{
+		{"same scale", image.Pt(1440, 900), 0, image.Rect(100, 50, 900, 650)},
+		{"retina image", image.Pt(2880, 1800), 0, image.Rect(200, 100, 1800, 1300)},
+		{"padding clamped to the image", image.Pt(1440, 900), 80, image.Rect(20, 0, 980, 730)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bounds.CropRect(tt.size, tt.padding); got != tt.want {
+				t.Errorf("CropRect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&WindowBounds{}).CropRect(image.Pt(1440, 900), 0); !got.Empty() {
+		t.Errorf("CropRect() without display size = %v, want empty", got)
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"math/rand"
+	"time"
+)
+
+// chaosDelay 按概率注入随机延迟，用于模拟慢速磁盘写入
+type chaosDelay struct {
+	rate     float64
+	maxDelay time.Duration
+}
+
+func (c *chaosDelay) sleep() {
+	if c == nil || c.rate <= 0 || c.maxDelay <= 0 {
+		return
+	}
+	if rand.Float64() >= c.rate {
+		return
+	}
+	time.Sleep(time.Duration(rand.Int63n(int64(c.maxDelay)) + 1))
+}
+
+// ChaosStorage wraps a StorageInterface and slows down write operations randomly
+// Used by chaos mode to validate retry/queueing/checkpoint behaviour under slow disks
+type ChaosStorage struct {
+	StorageInterface
+	delay *chaosDelay
+}
+
+// EnableChaos wraps the underlying storage with slow-write fault injection
+func (s *Storage) EnableChaos(rate float64, maxDelay time.Duration) {
+	if _, ok := s.StorageInterface.(*ChaosStorage); ok {
+		return
+	}
+	s.StorageInterface = &ChaosStorage{
+		StorageInterface: s.StorageInterface,
+		delay:            &chaosDelay{rate: rate, maxDelay: maxDelay},
+	}
+}
+
+// EnableChaos 为报告文件写入注入随机延迟
+func (sm *StorageManager) EnableChaos(rate float64, maxDelay time.Duration) {
+	sm.chaos = &chaosDelay{rate: rate, maxDelay: maxDelay}
+}
+
+func (c *ChaosStorage) SaveScreenshot(record *ScreenshotRecord) error {
+	c.delay.sleep()
+	return c.StorageInterface.SaveScreenshot(record)
+}
+
+func (c *ChaosStorage) UpdateScreenshotAnalysis(id, analysis string) error {
+	c.delay.sleep()
+	return c.StorageInterface.UpdateScreenshotAnalysis(id, analysis)
+}
+
+func (c *ChaosStorage) UpdateScreenshotAnalyses(updates []*ScreenshotAnalysisUpdate) error {
+	c.delay.sleep()
+	return c.StorageInterface.UpdateScreenshotAnalyses(updates)
+}
+
+func (c *ChaosStorage) SaveHourSummary(summary *HourSummary) error {
+	c.delay.sleep()
+	return c.StorageInterface.SaveHourSummary(summary)
+}
+
+func (c *ChaosStorage) UpdateHourSummary(hourKey string, screenshotIDs []string, summary string) error {
+	c.delay.sleep()
+	return c.StorageInterface.UpdateHourSummary(hourKey, screenshotIDs, summary)
+}
+
+func (c *ChaosStorage) SavePeriodSummary(summary *PeriodSummary) error {
+	c.delay.sleep()
+	return c.StorageInterface.SavePeriodSummary(summary)
+}
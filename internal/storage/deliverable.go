@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Deliverable is a concrete outcome produced during a period (PR merged, doc written, ticket closed, ...)
+type Deliverable struct {
+	ID        string    `db:"id"`
+	PeriodKey string    `db:"period_key"` // Day key the deliverable was extracted for (day:YYYY-MM-DD)
+	Timestamp time.Time `db:"timestamp"`  // When the deliverable was produced (or the day start if unknown)
+	Kind      string    `db:"kind"`       // e.g. "pr", "commit", "doc", "ticket", "release", "other"
+	Title     string    `db:"title"`
+	Reference string    `db:"reference"` // Optional: URL, commit hash, ticket key
+	Source    string    `db:"source"`    // "analysis" or "git"
+}
+
+// DeliverableStore stores extracted deliverables
+type DeliverableStore interface {
+	// ReplaceDeliverables replaces all deliverables of a period with the given ones
+	ReplaceDeliverables(periodKey string, deliverables []*Deliverable) error
+	// QueryDeliverables returns deliverables produced in [start, end)
+	QueryDeliverables(start, end time.Time) ([]*Deliverable, error)
+}
+
+func (s *SQLiteStorage) initDeliverablesTable() error {
+	createDeliverablesTable := `
+	CREATE TABLE IF NOT EXISTS deliverables (
+		id TEXT PRIMARY KEY,
+		period_key TEXT NOT NULL,
+		timestamp DATETIME NOT NULL,
+		kind TEXT NOT NULL,
+		title TEXT NOT NULL,
+		reference TEXT,
+		source TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_deliverables_period_key ON deliverables(period_key);
+	CREATE INDEX IF NOT EXISTS idx_deliverables_timestamp ON deliverables(timestamp);
+	`
+	if _, err := s.db.Exec(createDeliverablesTable); err != nil {
+		return fmt.Errorf("failed to create deliverables table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ReplaceDeliverables(periodKey string, deliverables []*Deliverable) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM deliverables WHERE period_key = ?`, periodKey); err != nil {
+		return fmt.Errorf("failed to delete deliverables: %w", err)
+	}
+
+	query := `INSERT INTO deliverables (id, period_key, timestamp, kind, title, reference, source) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	for _, d := range deliverables {
+		if d.ID == "" {
+			d.ID = generateID()
+		}
+		d.PeriodKey = periodKey
+		if _, err := tx.Exec(query, d.ID, d.PeriodKey, d.Timestamp.Format(time.RFC3339Nano), d.Kind, d.Title, d.Reference, d.Source); err != nil {
+			return fmt.Errorf("failed to insert deliverable: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deliverables: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryDeliverables(start, end time.Time) ([]*Deliverable, error) {
+	query := `
+	SELECT id, period_key, timestamp, kind, title, COALESCE(reference, ''), COALESCE(source, '')
+	FROM deliverables
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliverables: %w", err)
+	}
+	defer rows.Close()
+
+	var deliverables []*Deliverable
+	for rows.Next() {
+		var d Deliverable
+		var timestampStr string
+		if err := rows.Scan(&d.ID, &d.PeriodKey, &timestampStr, &d.Kind, &d.Title, &d.Reference, &d.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan deliverable: %w", err)
+		}
+		d.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		deliverables = append(deliverables, &d)
+	}
+	return deliverables, rows.Err()
+}
+
+func (r *ReportStorage) ReplaceDeliverables(periodKey string, deliverables []*Deliverable) error {
+	return r.metadataStorage.ReplaceDeliverables(periodKey, deliverables)
+}
+
+func (r *ReportStorage) QueryDeliverables(start, end time.Time) ([]*Deliverable, error) {
+	return r.metadataStorage.QueryDeliverables(start, end)
+}
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -9,9 +11,11 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"stuff-time/internal/datefmt"
 )
 
-// FileSystemStorage implements Storage interface using file system
+// FileSystemStorage implements ReportFileStore using the report files
 type FileSystemStorage struct {
 	reportsPath string
 	parser      *ReportParser
@@ -23,8 +27,12 @@ func NewFileSystemStorage(reportsPath string) (*FileSystemStorage, error) {
 		return nil, fmt.Errorf("reports path not configured")
 	}
 
-	// Ensure reports directory exists
-	if err := os.MkdirAll(reportsPath, 0755); err != nil {
+	// Ensure reports directory exists (read-only mode never creates it)
+	if IsReadOnly() {
+		if _, err := os.Stat(reportsPath); err != nil {
+			return nil, fmt.Errorf("failed to open reports directory: %w", err)
+		}
+	} else if err := os.MkdirAll(reportsPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create reports directory: %w", err)
 	}
 
@@ -69,6 +77,24 @@ func (s *FileSystemStorage) UpdateScreenshotAnalysis(id, analysis string) error
 	return s.writeScreenshotReport(reportPath, parsed)
 }
 
+// UpdateScreenshotAnalysisStatus is a no-op for file system storage (screenshot reports only hold successful analyses)
+func (s *FileSystemStorage) UpdateScreenshotAnalysisStatus(id, status, analysisError string) error {
+	return nil
+}
+
+// UpdateScreenshotAnalyses updates the analysis of each successfully analyzed screenshot report
+func (s *FileSystemStorage) UpdateScreenshotAnalyses(updates []*ScreenshotAnalysisUpdate) error {
+	for _, u := range updates {
+		if u.Status != AnalysisDone {
+			continue
+		}
+		if err := s.UpdateScreenshotAnalysis(u.ID, u.Analysis); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetScreenshotsByHourKey gets all screenshots for a specific hour
 func (s *FileSystemStorage) GetScreenshotsByHourKey(hourKey string) ([]*ScreenshotRecord, error) {
 	// Parse hour key: YYYY-MM-DD-HH
@@ -288,6 +314,21 @@ func (s *FileSystemStorage) QueryHourSummariesByDateRange(start, end time.Time)
 	return []*HourSummary{}, nil
 }
 
+// GetLatestUnanalyzedScreenshots gets the most recent screenshots without analysis, newest first
+func (s *FileSystemStorage) GetLatestUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error) {
+	records, err := s.GetUnanalyzedScreenshots(math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
 // GetUnanalyzedScreenshots gets screenshots without analysis
 func (s *FileSystemStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error) {
 	var records []*ScreenshotRecord
@@ -317,7 +358,7 @@ func (s *FileSystemStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRe
 		}
 
 		// Check if analysis is empty or indicates failure
-		if parsed.Summary == "" || strings.HasPrefix(parsed.Summary, "Analysis failed") {
+		if parsed.Summary == "" || IsFailedAnalysis(parsed.Summary) {
 			record := &ScreenshotRecord{
 				ID:        parsed.ScreenshotID,
 				Timestamp: parsed.StartTime,
@@ -342,6 +383,10 @@ func (s *FileSystemStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRe
 
 // SavePeriodSummary saves a period summary to a report file
 func (s *FileSystemStorage) SavePeriodSummary(summary *PeriodSummary) error {
+	if IsReadOnly() {
+		return ErrReadOnly
+	}
+
 	reportPath, err := s.calculateReportPath(summary)
 	if err != nil {
 		return fmt.Errorf("failed to calculate report path: %w", err)
@@ -382,20 +427,30 @@ func (s *FileSystemStorage) GetPeriodSummary(periodKey string) (*PeriodSummary,
 		return nil, fmt.Errorf("failed to build report path: %w", err)
 	}
 
-	// Check if file exists
+	// Parse the report file, or the report compacted into its month archive
+	var parsed *ParsedReport
 	if _, err := os.Stat(reportPath); os.IsNotExist(err) {
-		return nil, nil // Not found
-	}
-
-	// Parse the report file
-	parsed, err := s.parser.ParsePeriodReport(reportPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse report: %w", err)
+		content, err := ReadArchivedReport(s.reportsPath, reportPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil // Not found
+		}
+		if err != nil {
+			return nil, err
+		}
+		parsed, err = parsePeriodReportContent(reportPath, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report: %w", err)
+		}
+	} else {
+		parsed, err = s.parser.ParsePeriodReport(reportPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report: %w", err)
+		}
 	}
 
 	// Convert to PeriodSummary
 	summary := &PeriodSummary{
-		PeriodKey:   periodKey,
+		PeriodKey:   normalizePeriodKeyOfType(periodKey, parsed.PeriodType),
 		PeriodType:  parsed.PeriodType,
 		StartTime:   parsed.StartTime,
 		EndTime:     parsed.EndTime,
@@ -414,15 +469,19 @@ func (s *FileSystemStorage) GetPeriodSummary(periodKey string) (*PeriodSummary,
 
 // DeletePeriodSummary deletes a period summary report file
 func (s *FileSystemStorage) DeletePeriodSummary(periodKey string) error {
+	if IsReadOnly() {
+		return ErrReadOnly
+	}
+
 	// Build report path directly from period key
 	reportPath, _, err := s.buildReportPathFromPeriodKey(periodKey)
 	if err != nil {
 		return fmt.Errorf("failed to build report path: %w", err)
 	}
 
-	// Check if file exists
+	// Check if file exists; a compacted report is removed from its month archive
 	if _, err := os.Stat(reportPath); os.IsNotExist(err) {
-		return nil // Already deleted
+		return RemoveArchivedReport(s.reportsPath, reportPath)
 	}
 
 	// Clear cache
@@ -557,6 +616,10 @@ func (s *FileSystemStorage) CleanupOldRecords(retentionDays int) error {
 
 // DeleteScreenshotsByIDs deletes screenshot reports by IDs
 func (s *FileSystemStorage) DeleteScreenshotsByIDs(ids []string) error {
+	if IsReadOnly() {
+		return ErrReadOnly
+	}
+
 	idMap := make(map[string]bool)
 	for _, id := range ids {
 		idMap[id] = true
@@ -668,10 +731,8 @@ func (s *FileSystemStorage) calculateReportPath(summary *PeriodSummary) (string,
 		weekDir := fmt.Sprintf("W%d", weekNum)
 		dayDir := summary.StartTime.Format("02")
 		summaryDir = filepath.Join(s.reportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir)
-		parts := strings.Split(summary.PeriodKey, "-")
-		if len(parts) >= 4 && parts[3] == "segment" {
-			segmentNum := parts[4]
-			filename = fmt.Sprintf("work-segment-%s.md", segmentNum)
+		if key, err := ParsePeriodKey(summary.PeriodKey); err == nil && key.Type == "work-segment" {
+			filename = fmt.Sprintf("work-segment-%d.md", key.Segment)
 		} else {
 			filename = fmt.Sprintf("%s.md", summary.PeriodKey)
 		}
@@ -758,8 +819,8 @@ func (s *FileSystemStorage) generatePeriodReportContent(summary *PeriodSummary)
 
 	sb.WriteString(fmt.Sprintf("# %s周期总结报告\n\n", title))
 	sb.WriteString(fmt.Sprintf("**周期类型**: %s\n\n", summary.PeriodType))
-	sb.WriteString(fmt.Sprintf("**开始时间**: %s\n\n", summary.StartTime.Format("2006-01-02 15:04:05")))
-	sb.WriteString(fmt.Sprintf("**结束时间**: %s\n\n", summary.EndTime.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("**开始时间**: %s\n\n", datefmt.DateTime(summary.StartTime)))
+	sb.WriteString(fmt.Sprintf("**结束时间**: %s\n\n", datefmt.DateTime(summary.EndTime)))
 
 	// Count screenshots if available
 	screenshotCount := 0
@@ -789,16 +850,20 @@ func (s *FileSystemStorage) generatePeriodReportContent(summary *PeriodSummary)
 	}
 
 	// Footer
-	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", time.Now().Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", datefmt.DateTime(time.Now())))
 
 	return sb.String()
 }
 
 func (s *FileSystemStorage) writeScreenshotReport(filePath string, parsed *ParsedReport) error {
+	if IsReadOnly() {
+		return ErrReadOnly
+	}
+
 	var sb strings.Builder
 
 	sb.WriteString("# 截图分析报告\n\n")
-	sb.WriteString(fmt.Sprintf("**时间**: %s\n\n", parsed.StartTime.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("**时间**: %s\n\n", datefmt.DateTime(parsed.StartTime)))
 	sb.WriteString(fmt.Sprintf("**截图ID**: %s\n\n", parsed.ScreenshotID))
 	sb.WriteString(fmt.Sprintf("**截图路径**: %s\n\n", parsed.ImagePath))
 	sb.WriteString(fmt.Sprintf("**屏幕ID**: %d\n\n", parsed.ScreenID))
@@ -811,7 +876,7 @@ func (s *FileSystemStorage) writeScreenshotReport(filePath string, parsed *Parse
 	}
 
 	sb.WriteString("---\n\n")
-	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", time.Now().Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", datefmt.DateTime(time.Now())))
 
 	return os.WriteFile(filePath, []byte(sb.String()), 0644)
 }
@@ -852,6 +917,9 @@ func (s *FileSystemStorage) findScreenshotReportByID(id string) (string, error)
 
 // buildReportPathFromPeriodKey builds report file path directly from period key
 func (s *FileSystemStorage) buildReportPathFromPeriodKey(periodKey string) (string, string, error) {
+	// Report paths follow the local time of the period, as version 1 keys do
+	periodKey = LegacyPeriodKey(periodKey)
+
 	// Try common patterns based on period key format
 	// For day: 2025-12-02 -> reports/2025/Q4/12/W1/02/day.md
 	if matched, _ := regexp.MatchString(`^\d{4}-\d{2}-\d{2}$`, periodKey); matched {
@@ -882,6 +950,17 @@ func (s *FileSystemStorage) buildReportPathFromPeriodKey(periodKey string) (stri
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
 		hourPath := filepath.Join(s.reportsPath, year, quarterDir, month, weekDir, dayStr, hour, "hour.md")
+		// With storage.day_start_hour, early-morning hours are filed under the previous day
+		if !ReportExists(s.reportsPath, hourPath) {
+			if t, err := time.ParseInLocation("2006-01-02-15", periodKey, time.Local); err == nil {
+				prev := t.AddDate(0, 0, -1)
+				prevPath := filepath.Join(s.reportsPath, prev.Format("2006"), fmt.Sprintf("Q%d", (int(prev.Month())-1)/3+1),
+					prev.Format("01"), fmt.Sprintf("W%d", (prev.Day()-1)/7+1), prev.Format("02"), hour, "hour.md")
+				if ReportExists(s.reportsPath, prevPath) {
+					return prevPath, "hour", nil
+				}
+			}
+		}
 		return hourPath, "hour", nil
 	}
 
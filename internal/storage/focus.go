@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FocusScore is the daily focus score and the statistics it was computed from
+type FocusScore struct {
+	Date                  time.Time          `db:"date"` // Day start
+	Score                 int                `db:"score"`
+	ActiveMinutes         float64            `db:"active_minutes"`
+	DeepWorkMinutes       float64            `db:"deep_work_minutes"`
+	LongestSessionMinutes float64            `db:"longest_session_minutes"`
+	ContextSwitches       int                `db:"context_switches"`
+	CategoryMinutes       map[string]float64 `db:"category_minutes"` // Stored as JSON
+	UpdatedAt             time.Time          `db:"updated_at"`
+}
+
+// FocusStore stores the daily focus score history
+type FocusStore interface {
+	SaveFocusScore(score *FocusScore) error
+	// QueryFocusScores returns scores of days in [start, end), ordered by date
+	QueryFocusScores(start, end time.Time) ([]*FocusScore, error)
+}
+
+func (s *SQLiteStorage) initFocusTable() error {
+	createFocusTable := `
+	CREATE TABLE IF NOT EXISTS focus_scores (
+		date_key TEXT PRIMARY KEY,
+		date DATETIME NOT NULL,
+		score INTEGER NOT NULL,
+		active_minutes REAL NOT NULL,
+		deep_work_minutes REAL NOT NULL,
+		longest_session_minutes REAL NOT NULL,
+		context_switches INTEGER NOT NULL,
+		category_minutes TEXT,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_focus_scores_date ON focus_scores(date);
+	`
+	if _, err := s.db.Exec(createFocusTable); err != nil {
+		return fmt.Errorf("failed to create focus_scores table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveFocusScore(score *FocusScore) error {
+	categoryJSON, err := json.Marshal(score.CategoryMinutes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal category minutes: %w", err)
+	}
+
+	query := `
+	INSERT OR REPLACE INTO focus_scores
+	(date_key, date, score, active_minutes, deep_work_minutes, longest_session_minutes, context_switches, category_minutes, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = s.db.Exec(query,
+		score.Date.Format("2006-01-02"),
+		score.Date.Format(time.RFC3339Nano),
+		score.Score,
+		score.ActiveMinutes,
+		score.DeepWorkMinutes,
+		score.LongestSessionMinutes,
+		score.ContextSwitches,
+		string(categoryJSON),
+		score.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save focus score: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryFocusScores(start, end time.Time) ([]*FocusScore, error) {
+	query := `
+	SELECT date, score, active_minutes, deep_work_minutes, longest_session_minutes, context_switches,
+		COALESCE(category_minutes, ''), updated_at
+	FROM focus_scores
+	WHERE date >= ? AND date < ?
+	ORDER BY date ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query focus scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*FocusScore
+	for rows.Next() {
+		var fs FocusScore
+		var dateStr, categoryJSON, updatedAtStr string
+		if err := rows.Scan(&dateStr, &fs.Score, &fs.ActiveMinutes, &fs.DeepWorkMinutes, &fs.LongestSessionMinutes,
+			&fs.ContextSwitches, &categoryJSON, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan focus score: %w", err)
+		}
+		fs.Date, err = time.Parse(time.RFC3339Nano, dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date: %w", err)
+		}
+		fs.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAtStr)
+		if categoryJSON != "" {
+			if err := json.Unmarshal([]byte(categoryJSON), &fs.CategoryMinutes); err != nil {
+				return nil, fmt.Errorf("failed to parse category minutes: %w", err)
+			}
+		}
+		scores = append(scores, &fs)
+	}
+	return scores, rows.Err()
+}
+
+func (r *ReportStorage) SaveFocusScore(score *FocusScore) error {
+	return r.metadataStorage.SaveFocusScore(score)
+}
+
+func (r *ReportStorage) QueryFocusScores(start, end time.Time) ([]*FocusScore, error) {
+	return r.metadataStorage.QueryFocusScores(start, end)
+}
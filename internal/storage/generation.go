@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Generation is the lease of a period summary generation, shared by all processes using the database
+// (daemon, generate command, HTTP API), so the same period is never generated twice at once
+type Generation struct {
+	Key        string    `db:"key"`
+	Holder     string    `db:"holder"`
+	Forced     bool      `db:"forced"` // Rebuild from screenshots, its outcome is only shared with other forced callers
+	StartedAt  time.Time `db:"started_at"`
+	ExpiresAt  time.Time `db:"expires_at"`  // A crashed holder's lease is taken over after it expires
+	FinishedAt time.Time `db:"finished_at"` // Zero while running
+	Error      string    `db:"error"`       // Failure of the finished generation, empty on success
+}
+
+// GenerationStore stores the generation leases
+// Times are stored as Unix milliseconds so leases compare correctly across time zones
+type GenerationStore interface {
+	// AcquireGeneration starts a generation of key for holder, false while another holder's lease is running
+	AcquireGeneration(key, holder string, forced bool, lease time.Duration) (bool, error)
+	// RenewGeneration extends the lease of a running generation
+	RenewGeneration(key, holder string, lease time.Duration) error
+	// FinishGeneration ends the generation and records its outcome for the callers waiting on it
+	FinishGeneration(key, holder, errMsg string) error
+	// GetGeneration returns the last generation of key, nil if there was none
+	GetGeneration(key string) (*Generation, error)
+}
+
+func (s *SQLiteStorage) initGenerationTable() error {
+	createGenerationTable := `
+	CREATE TABLE IF NOT EXISTS period_generations (
+		key TEXT PRIMARY KEY,
+		holder TEXT NOT NULL,
+		started_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		finished_at INTEGER,
+		error TEXT NOT NULL DEFAULT '',
+		forced INTEGER NOT NULL DEFAULT 0
+	);
+	`
+	if _, err := s.db.Exec(createGenerationTable); err != nil {
+		return fmt.Errorf("failed to create period_generations table: %w", err)
+	}
+	// Column may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE period_generations ADD COLUMN forced INTEGER NOT NULL DEFAULT 0")
+	return nil
+}
+
+func (s *SQLiteStorage) AcquireGeneration(key, holder string, forced bool, lease time.Duration) (bool, error) {
+	now := time.Now()
+	query := `
+	INSERT INTO period_generations (key, holder, started_at, expires_at, finished_at, error, forced)
+	VALUES (?, ?, ?, ?, NULL, '', ?)
+	ON CONFLICT(key) DO UPDATE SET holder = excluded.holder, started_at = excluded.started_at,
+		expires_at = excluded.expires_at, finished_at = NULL, error = '', forced = excluded.forced
+	WHERE period_generations.finished_at IS NOT NULL OR period_generations.expires_at < ?
+	`
+	result, err := s.db.Exec(query, key, holder, now.UnixMilli(), now.Add(lease).UnixMilli(), forced, now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire generation: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire generation: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (s *SQLiteStorage) RenewGeneration(key, holder string, lease time.Duration) error {
+	query := `UPDATE period_generations SET expires_at = ? WHERE key = ? AND holder = ? AND finished_at IS NULL`
+	if _, err := s.db.Exec(query, time.Now().Add(lease).UnixMilli(), key, holder); err != nil {
+		return fmt.Errorf("failed to renew generation: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) FinishGeneration(key, holder, errMsg string) error {
+	query := `UPDATE period_generations SET finished_at = ?, error = ? WHERE key = ? AND holder = ?`
+	if _, err := s.db.Exec(query, time.Now().UnixMilli(), errMsg, key, holder); err != nil {
+		return fmt.Errorf("failed to finish generation: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetGeneration(key string) (*Generation, error) {
+	query := `SELECT key, holder, forced, started_at, expires_at, finished_at, error FROM period_generations WHERE key = ?`
+	var g Generation
+	var startedAt, expiresAt int64
+	var finishedAt sql.NullInt64
+	err := s.db.QueryRow(query, key).Scan(&g.Key, &g.Holder, &g.Forced, &startedAt, &expiresAt, &finishedAt, &g.Error)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generation: %w", err)
+	}
+	g.StartedAt = time.UnixMilli(startedAt)
+	g.ExpiresAt = time.UnixMilli(expiresAt)
+	if finishedAt.Valid {
+		g.FinishedAt = time.UnixMilli(finishedAt.Int64)
+	}
+	return &g, nil
+}
+
+func (r *ReportStorage) AcquireGeneration(key, holder string, forced bool, lease time.Duration) (bool, error) {
+	return r.metadataStorage.AcquireGeneration(key, holder, forced, lease)
+}
+
+func (r *ReportStorage) RenewGeneration(key, holder string, lease time.Duration) error {
+	return r.metadataStorage.RenewGeneration(key, holder, lease)
+}
+
+func (r *ReportStorage) FinishGeneration(key, holder, errMsg string) error {
+	return r.metadataStorage.FinishGeneration(key, holder, errMsg)
+}
+
+func (r *ReportStorage) GetGeneration(key string) (*Generation, error) {
+	return r.metadataStorage.GetGeneration(key)
+}
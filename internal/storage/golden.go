@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// GoldenPeriod is a period whose summary the user marked as a reference output; regression runs
+// regenerate it in a sandbox after prompt/model changes and compare the quality against this copy
+type GoldenPeriod struct {
+	PeriodKey   string
+	PeriodType  string
+	StartTime   time.Time
+	EndTime     time.Time
+	Screenshots string
+	Summary     string
+	Analysis    string
+	MarkedAt    time.Time
+}
+
+// GoldenStore stores golden periods for summary regression tests
+type GoldenStore interface {
+	// SaveGoldenPeriod marks a period as golden, replacing the stored output if it is already marked
+	SaveGoldenPeriod(golden *GoldenPeriod) error
+	DeleteGoldenPeriod(periodKey string) error
+	// ListGoldenPeriods returns golden periods ordered by start time
+	ListGoldenPeriods() ([]*GoldenPeriod, error)
+	// SnapshotDatabase writes a consistent copy of the database to path, which must not exist
+	SnapshotDatabase(path string) error
+}
+
+func (s *SQLiteStorage) initGoldenTable() error {
+	createGoldenTable := `
+	CREATE TABLE IF NOT EXISTS golden_periods (
+		period_key TEXT PRIMARY KEY,
+		period_type TEXT NOT NULL,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME NOT NULL,
+		screenshots TEXT,
+		summary TEXT NOT NULL,
+		analysis TEXT,
+		marked_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createGoldenTable); err != nil {
+		return fmt.Errorf("failed to create golden_periods table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveGoldenPeriod(golden *GoldenPeriod) error {
+	golden.PeriodKey = normalizePeriodKeyOfType(golden.PeriodKey, golden.PeriodType)
+	query := `
+	INSERT OR REPLACE INTO golden_periods (period_key, period_type, start_time, end_time, screenshots, summary, analysis, marked_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if golden.MarkedAt.IsZero() {
+		golden.MarkedAt = time.Now()
+	}
+	_, err := s.db.Exec(query, golden.PeriodKey, golden.PeriodType,
+		golden.StartTime.Format(time.RFC3339Nano), golden.EndTime.Format(time.RFC3339Nano),
+		golden.Screenshots, golden.Summary, golden.Analysis, golden.MarkedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to save golden period: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) DeleteGoldenPeriod(periodKey string) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	if _, err := s.db.Exec(`DELETE FROM golden_periods WHERE period_key = ?`, periodKey); err != nil {
+		return fmt.Errorf("failed to delete golden period: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ListGoldenPeriods() ([]*GoldenPeriod, error) {
+	query := `
+	SELECT period_key, period_type, start_time, end_time, COALESCE(screenshots, ''), summary, COALESCE(analysis, ''), marked_at
+	FROM golden_periods
+	ORDER BY start_time ASC
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query golden periods: %w", err)
+	}
+	defer rows.Close()
+
+	var goldens []*GoldenPeriod
+	for rows.Next() {
+		var g GoldenPeriod
+		var startStr, endStr, markedStr string
+		if err := rows.Scan(&g.PeriodKey, &g.PeriodType, &startStr, &endStr, &g.Screenshots, &g.Summary, &g.Analysis, &markedStr); err != nil {
+			return nil, fmt.Errorf("failed to scan golden period: %w", err)
+		}
+		if g.StartTime, err = time.Parse(time.RFC3339Nano, startStr); err != nil {
+			return nil, fmt.Errorf("failed to parse start time: %w", err)
+		}
+		if g.EndTime, err = time.Parse(time.RFC3339Nano, endStr); err != nil {
+			return nil, fmt.Errorf("failed to parse end time: %w", err)
+		}
+		g.MarkedAt, _ = time.Parse(time.RFC3339Nano, markedStr)
+		goldens = append(goldens, &g)
+	}
+	return goldens, rows.Err()
+}
+
+func (s *SQLiteStorage) SnapshotDatabase(path string) error {
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) SaveGoldenPeriod(golden *GoldenPeriod) error {
+	return r.metadataStorage.SaveGoldenPeriod(golden)
+}
+
+func (r *ReportStorage) DeleteGoldenPeriod(periodKey string) error {
+	return r.metadataStorage.DeleteGoldenPeriod(periodKey)
+}
+
+func (r *ReportStorage) ListGoldenPeriods() ([]*GoldenPeriod, error) {
+	return r.metadataStorage.ListGoldenPeriods()
+}
+
+func (r *ReportStorage) SnapshotDatabase(path string) error {
+	return r.metadataStorage.SnapshotDatabase(path)
+}
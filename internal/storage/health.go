@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// HealthCheck is the result of a daily self-test of the capture pipeline
+type HealthCheck struct {
+	Timestamp time.Time `db:"timestamp"`
+	Passed    bool      `db:"passed"`
+	Details   string    `db:"details"` // One "<step>: <result>" line per step
+}
+
+// HealthStore stores self-test results
+type HealthStore interface {
+	SaveHealthCheck(check *HealthCheck) error
+	// QueryHealthChecks returns the latest self-test results, newest first
+	QueryHealthChecks(limit int) ([]*HealthCheck, error)
+}
+
+func (s *SQLiteStorage) initHealthTable() error {
+	createHealthTable := `
+	CREATE TABLE IF NOT EXISTS health_checks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp DATETIME NOT NULL,
+		passed INTEGER NOT NULL,
+		details TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_health_checks_timestamp ON health_checks(timestamp);
+	`
+	if _, err := s.db.Exec(createHealthTable); err != nil {
+		return fmt.Errorf("failed to create health_checks table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveHealthCheck(check *HealthCheck) error {
+	query := `INSERT INTO health_checks (timestamp, passed, details) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(query, check.Timestamp.Format(time.RFC3339Nano), check.Passed, check.Details); err != nil {
+		return fmt.Errorf("failed to save health check: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryHealthChecks(limit int) ([]*HealthCheck, error) {
+	query := `
+	SELECT timestamp, passed, COALESCE(details, '')
+	FROM health_checks
+	ORDER BY timestamp DESC
+	LIMIT ?
+	`
+	rows, err := s.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query health checks: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []*HealthCheck
+	for rows.Next() {
+		var c HealthCheck
+		var timestampStr string
+		if err := rows.Scan(&timestampStr, &c.Passed, &c.Details); err != nil {
+			return nil, fmt.Errorf("failed to scan health check: %w", err)
+		}
+		if c.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		checks = append(checks, &c)
+	}
+	return checks, rows.Err()
+}
+
+func (r *ReportStorage) SaveHealthCheck(check *HealthCheck) error {
+	return r.metadataStorage.SaveHealthCheck(check)
+}
+
+func (r *ReportStorage) QueryHealthChecks(limit int) ([]*HealthCheck, error) {
+	return r.metadataStorage.QueryHealthChecks(limit)
+}
@@ -43,6 +43,11 @@ func DetectInvalidReports(reportsPath string) ([]InvalidReportIssue, error) {
 			return nil
 		}
 
+		// Skip manual notes files (notes.md, <report>-notes.md)
+		if IsNotesFile(filename) {
+			return nil
+		}
+
 		// Detect issues for this file
 		fileIssues := detectIssuesInFile(reportsPath, path)
 		issues = append(issues, fileIssues...)
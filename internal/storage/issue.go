@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IssueInfo caches the tracker details (Jira/Linear) of an issue key
+type IssueInfo struct {
+	Key       string    `db:"key"`
+	Title     string    `db:"title"`
+	Status    string    `db:"status"`
+	URL       string    `db:"url"`
+	UpdatedAt time.Time `db:"updated_at"` // When the details were last fetched from the tracker
+}
+
+// PeriodIssue records how much time of a period was attributed to an issue
+type PeriodIssue struct {
+	PeriodKey   string    `db:"period_key"` // Day key (day:YYYY-MM-DD)
+	IssueKey    string    `db:"issue_key"`
+	StartTime   time.Time `db:"start_time"`
+	Screenshots int       `db:"screenshots"` // Number of screenshots mentioning the issue
+	Minutes     float64   `db:"minutes"`     // Estimated time spent (screenshots x capture interval)
+}
+
+// IssueStore stores issue details and time-per-issue of periods
+type IssueStore interface {
+	SaveIssue(issue *IssueInfo) error
+	// GetIssue returns nil if the issue is not cached
+	GetIssue(key string) (*IssueInfo, error)
+	// ReplacePeriodIssues replaces all issue attributions of a period
+	ReplacePeriodIssues(periodKey string, issues []*PeriodIssue) error
+	// QueryPeriodIssues returns issue attributions of periods starting in [start, end)
+	QueryPeriodIssues(start, end time.Time) ([]*PeriodIssue, error)
+}
+
+func (s *SQLiteStorage) initIssueTables() error {
+	createIssueTables := `
+	CREATE TABLE IF NOT EXISTS issues (
+		key TEXT PRIMARY KEY,
+		title TEXT,
+		status TEXT,
+		url TEXT,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS period_issues (
+		period_key TEXT NOT NULL,
+		issue_key TEXT NOT NULL,
+		start_time DATETIME NOT NULL,
+		screenshots INTEGER NOT NULL,
+		minutes REAL NOT NULL,
+		PRIMARY KEY (period_key, issue_key)
+	);
+	CREATE INDEX IF NOT EXISTS idx_period_issues_start_time ON period_issues(start_time);
+	`
+	if _, err := s.db.Exec(createIssueTables); err != nil {
+		return fmt.Errorf("failed to create issue tables: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveIssue(issue *IssueInfo) error {
+	query := `
+	INSERT OR REPLACE INTO issues (key, title, status, url, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, issue.Key, issue.Title, issue.Status, issue.URL, issue.UpdatedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save issue: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetIssue(key string) (*IssueInfo, error) {
+	query := `SELECT key, COALESCE(title, ''), COALESCE(status, ''), COALESCE(url, ''), updated_at FROM issues WHERE key = ?`
+	var issue IssueInfo
+	var updatedAtStr string
+	err := s.db.QueryRow(query, key).Scan(&issue.Key, &issue.Title, &issue.Status, &issue.URL, &updatedAtStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	issue.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return &issue, nil
+}
+
+func (s *SQLiteStorage) ReplacePeriodIssues(periodKey string, issues []*PeriodIssue) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM period_issues WHERE period_key = ?`, periodKey); err != nil {
+		return fmt.Errorf("failed to delete period issues: %w", err)
+	}
+
+	query := `INSERT INTO period_issues (period_key, issue_key, start_time, screenshots, minutes) VALUES (?, ?, ?, ?, ?)`
+	for _, pi := range issues {
+		pi.PeriodKey = periodKey
+		if _, err := tx.Exec(query, pi.PeriodKey, pi.IssueKey, pi.StartTime.Format(time.RFC3339Nano), pi.Screenshots, pi.Minutes); err != nil {
+			return fmt.Errorf("failed to insert period issue: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit period issues: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryPeriodIssues(start, end time.Time) ([]*PeriodIssue, error) {
+	query := `
+	SELECT period_key, issue_key, start_time, screenshots, minutes
+	FROM period_issues
+	WHERE start_time >= ? AND start_time < ?
+	ORDER BY start_time ASC, minutes DESC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query period issues: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*PeriodIssue
+	for rows.Next() {
+		var pi PeriodIssue
+		var startTimeStr string
+		if err := rows.Scan(&pi.PeriodKey, &pi.IssueKey, &startTimeStr, &pi.Screenshots, &pi.Minutes); err != nil {
+			return nil, fmt.Errorf("failed to scan period issue: %w", err)
+		}
+		pi.StartTime, err = time.Parse(time.RFC3339Nano, startTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		result = append(result, &pi)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReportStorage) SaveIssue(issue *IssueInfo) error {
+	return r.metadataStorage.SaveIssue(issue)
+}
+
+func (r *ReportStorage) GetIssue(key string) (*IssueInfo, error) {
+	return r.metadataStorage.GetIssue(key)
+}
+
+func (r *ReportStorage) ReplacePeriodIssues(periodKey string, issues []*PeriodIssue) error {
+	return r.metadataStorage.ReplacePeriodIssues(periodKey, issues)
+}
+
+func (r *ReportStorage) QueryPeriodIssues(start, end time.Time) ([]*PeriodIssue, error) {
+	return r.metadataStorage.QueryPeriodIssues(start, end)
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SummaryLineage is the evidence a period summary was generated from: the child summaries and
+// screenshots actually passed to the model, after placeholders, invalid summaries and desktop/lock
+// screens were filtered out
+type SummaryLineage struct {
+	PeriodKey   string
+	Children    []string // Period keys of the child summaries
+	Screenshots []string // IDs of the screenshots whose analyses were summarized directly
+	Prompts     []string // Database prompt versions the summary was generated with, e.g. summary/day@3
+	CreatedAt   time.Time
+}
+
+// LineageStore stores the lineage of period summaries
+type LineageStore interface {
+	// SaveLineage replaces the lineage of a period
+	SaveLineage(lineage *SummaryLineage) error
+	// GetLineage returns nil if no lineage was recorded (summaries generated before lineage existed)
+	GetLineage(periodKey string) (*SummaryLineage, error)
+}
+
+func (s *SQLiteStorage) initLineageTable() error {
+	createLineageTable := `
+	CREATE TABLE IF NOT EXISTS summary_lineage (
+		period_key TEXT PRIMARY KEY,
+		children TEXT,
+		screenshots TEXT,
+		created_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createLineageTable); err != nil {
+		return fmt.Errorf("failed to create summary_lineage table: %w", err)
+	}
+	// Column may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE summary_lineage ADD COLUMN prompts TEXT")
+	return nil
+}
+
+func (s *SQLiteStorage) SaveLineage(lineage *SummaryLineage) error {
+	lineage.PeriodKey = NormalizePeriodKey(lineage.PeriodKey)
+	for i, child := range lineage.Children {
+		lineage.Children[i] = NormalizePeriodKey(child)
+	}
+	if lineage.CreatedAt.IsZero() {
+		lineage.CreatedAt = time.Now()
+	}
+	query := `INSERT OR REPLACE INTO summary_lineage (period_key, children, screenshots, prompts, created_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, lineage.PeriodKey, strings.Join(lineage.Children, "\n"),
+		strings.Join(lineage.Screenshots, "\n"), strings.Join(lineage.Prompts, "\n"), lineage.CreatedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save lineage: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetLineage(periodKey string) (*SummaryLineage, error) {
+	periodKey = NormalizePeriodKey(periodKey)
+	var children, screenshots, prompts, createdStr string
+	err := s.db.QueryRow(`SELECT COALESCE(children, ''), COALESCE(screenshots, ''), COALESCE(prompts, ''), created_at FROM summary_lineage WHERE period_key = ?`,
+		periodKey).Scan(&children, &screenshots, &prompts, &createdStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lineage: %w", err)
+	}
+	lineage := &SummaryLineage{PeriodKey: periodKey}
+	if children != "" {
+		lineage.Children = strings.Split(children, "\n")
+	}
+	if screenshots != "" {
+		lineage.Screenshots = strings.Split(screenshots, "\n")
+	}
+	if prompts != "" {
+		lineage.Prompts = strings.Split(prompts, "\n")
+	}
+	lineage.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+	return lineage, nil
+}
+
+func (r *ReportStorage) SaveLineage(lineage *SummaryLineage) error {
+	return r.metadataStorage.SaveLineage(lineage)
+}
+
+func (r *ReportStorage) GetLineage(periodKey string) (*SummaryLineage, error) {
+	return r.metadataStorage.GetLineage(periodKey)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LocalOnlyScreenshot marks a screenshot captured while a local-only app was in front:
+// it is analyzed locally and never sent to the cloud provider
+// Marks are keyed by image path, so they survive rebuilds (which re-import screenshots with new IDs)
+type LocalOnlyScreenshot struct {
+	ImagePath string    `db:"image_path"`
+	Timestamp time.Time `db:"timestamp"`
+	App       string    `db:"app"`
+	Title     string    `db:"title"`
+}
+
+// LocalOnlyStore stores local-only screenshot marks
+type LocalOnlyStore interface {
+	MarkLocalOnly(mark *LocalOnlyScreenshot) error
+	// GetLocalOnly returns nil if the screenshot image is not local-only
+	GetLocalOnly(imagePath string) (*LocalOnlyScreenshot, error)
+}
+
+func (s *SQLiteStorage) initLocalOnlyTable() error {
+	createLocalOnlyTable := `
+	CREATE TABLE IF NOT EXISTS local_only_screenshots (
+		image_path TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		app TEXT NOT NULL,
+		title TEXT
+	);
+	`
+	if _, err := s.db.Exec(createLocalOnlyTable); err != nil {
+		return fmt.Errorf("failed to create local_only_screenshots table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) MarkLocalOnly(mark *LocalOnlyScreenshot) error {
+	query := `
+	INSERT OR REPLACE INTO local_only_screenshots (image_path, timestamp, app, title)
+	VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, mark.ImagePath, mark.Timestamp.Format(time.RFC3339Nano), mark.App, mark.Title); err != nil {
+		return fmt.Errorf("failed to mark screenshot local-only: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetLocalOnly(imagePath string) (*LocalOnlyScreenshot, error) {
+	query := `SELECT image_path, timestamp, app, COALESCE(title, '') FROM local_only_screenshots WHERE image_path = ?`
+	var mark LocalOnlyScreenshot
+	var timestampStr string
+	err := s.db.QueryRow(query, imagePath).Scan(&mark.ImagePath, &timestampStr, &mark.App, &mark.Title)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local-only mark: %w", err)
+	}
+	mark.Timestamp, _ = time.Parse(time.RFC3339Nano, timestampStr)
+	return &mark, nil
+}
+
+func (r *ReportStorage) MarkLocalOnly(mark *LocalOnlyScreenshot) error {
+	return r.metadataStorage.MarkLocalOnly(mark)
+}
+
+func (r *ReportStorage) GetLocalOnly(imagePath string) (*LocalOnlyScreenshot, error) {
+	return r.metadataStorage.GetLocalOnly(imagePath)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodLock marks a period whose report was edited by hand; its summary is never regenerated
+type PeriodLock struct {
+	PeriodKey string
+	Reason    string
+	LockedAt  time.Time
+}
+
+// PeriodLockStore stores locked periods
+type PeriodLockStore interface {
+	// LockPeriod locks a period, updating the reason if it is already locked
+	LockPeriod(periodKey, reason string) error
+	UnlockPeriod(periodKey string) error
+	IsPeriodLocked(periodKey string) (bool, error)
+	// ListPeriodLocks returns locked periods ordered by period key
+	ListPeriodLocks() ([]*PeriodLock, error)
+}
+
+func (s *SQLiteStorage) initPeriodLockTable() error {
+	createPeriodLockTable := `
+	CREATE TABLE IF NOT EXISTS period_locks (
+		period_key TEXT PRIMARY KEY,
+		reason TEXT,
+		locked_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createPeriodLockTable); err != nil {
+		return fmt.Errorf("failed to create period_locks table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) LockPeriod(periodKey, reason string) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	query := `
+	INSERT INTO period_locks (period_key, reason, locked_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(period_key) DO UPDATE SET reason = excluded.reason
+	`
+	if _, err := s.db.Exec(query, periodKey, reason, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to lock period: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) UnlockPeriod(periodKey string) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	if _, err := s.db.Exec(`DELETE FROM period_locks WHERE period_key = ?`, periodKey); err != nil {
+		return fmt.Errorf("failed to unlock period: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) IsPeriodLocked(periodKey string) (bool, error) {
+	periodKey = NormalizePeriodKey(periodKey)
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM period_locks WHERE period_key = ?`, periodKey).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check period lock: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *SQLiteStorage) ListPeriodLocks() ([]*PeriodLock, error) {
+	rows, err := s.db.Query(`SELECT period_key, COALESCE(reason, ''), locked_at FROM period_locks ORDER BY period_key ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query period locks: %w", err)
+	}
+	defer rows.Close()
+
+	var locks []*PeriodLock
+	for rows.Next() {
+		var lock PeriodLock
+		var lockedStr string
+		if err := rows.Scan(&lock.PeriodKey, &lock.Reason, &lockedStr); err != nil {
+			return nil, fmt.Errorf("failed to scan period lock: %w", err)
+		}
+		lock.LockedAt, _ = time.Parse(time.RFC3339Nano, lockedStr)
+		locks = append(locks, &lock)
+	}
+	return locks, rows.Err()
+}
+
+func (r *ReportStorage) LockPeriod(periodKey, reason string) error {
+	return r.metadataStorage.LockPeriod(periodKey, reason)
+}
+
+func (r *ReportStorage) UnlockPeriod(periodKey string) error {
+	return r.metadataStorage.UnlockPeriod(periodKey)
+}
+
+func (r *ReportStorage) IsPeriodLocked(periodKey string) (bool, error) {
+	return r.metadataStorage.IsPeriodLocked(periodKey)
+}
+
+func (r *ReportStorage) ListPeriodLocks() ([]*PeriodLock, error) {
+	return r.metadataStorage.ListPeriodLocks()
+}
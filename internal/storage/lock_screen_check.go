@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Sources of a lock screen check
+const (
+	LockCheckHeuristic = "heuristic" // Decided locally from the image (blank, or clearly detailed content)
+	LockCheckLLM       = "llm"
+)
+
+// LockScreenCheck is the persisted result of a lock screen check, keyed by the image content hash
+// so rebuilds of the same archive never ask the model about a screenshot twice
+type LockScreenCheck struct {
+	ImageHash    string
+	IsLockScreen bool
+	Source       string
+	CheckedAt    time.Time
+}
+
+// LockScreenCheckStore caches lock screen detection results across rebuilds
+type LockScreenCheckStore interface {
+	// GetLockScreenCheck returns nil if the image was never checked
+	GetLockScreenCheck(imageHash string) (*LockScreenCheck, error)
+	SaveLockScreenCheck(check *LockScreenCheck) error
+}
+
+func (s *SQLiteStorage) initLockScreenCheckTable() error {
+	createLockScreenCheckTable := `
+	CREATE TABLE IF NOT EXISTS lock_screen_checks (
+		image_hash TEXT PRIMARY KEY,
+		is_lock_screen INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		checked_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createLockScreenCheckTable); err != nil {
+		return fmt.Errorf("failed to create lock_screen_checks table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetLockScreenCheck(imageHash string) (*LockScreenCheck, error) {
+	check := &LockScreenCheck{ImageHash: imageHash}
+	var checkedStr string
+	err := s.db.QueryRow(`SELECT is_lock_screen, source, checked_at FROM lock_screen_checks WHERE image_hash = ?`,
+		imageHash).Scan(&check.IsLockScreen, &check.Source, &checkedStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock screen check: %w", err)
+	}
+	check.CheckedAt, _ = time.Parse(time.RFC3339Nano, checkedStr)
+	return check, nil
+}
+
+func (s *SQLiteStorage) SaveLockScreenCheck(check *LockScreenCheck) error {
+	if check.CheckedAt.IsZero() {
+		check.CheckedAt = time.Now()
+	}
+	query := `INSERT OR REPLACE INTO lock_screen_checks (image_hash, is_lock_screen, source, checked_at) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, check.ImageHash, check.IsLockScreen, check.Source,
+		check.CheckedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save lock screen check: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) GetLockScreenCheck(imageHash string) (*LockScreenCheck, error) {
+	return r.metadataStorage.GetLockScreenCheck(imageHash)
+}
+
+func (r *ReportStorage) SaveLockScreenCheck(check *LockScreenCheck) error {
+	return r.metadataStorage.SaveLockScreenCheck(check)
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"strings"
+
+	"stuff-time/internal/config"
+)
+
+// reportFooterPrefix starts the generation time footer of every report
+const reportFooterPrefix = "*报告生成时间:"
+
+// FormatMarkdown renders a generated report in the configured markdown format:
+// headings are shifted below the configured level, horizontal rules and the metadata block
+// (the **key**: value lines under the title and the generation time footer) are optionally dropped,
+// and home directory paths, internal hostnames and IP addresses are optionally masked
+// Fenced code blocks are left untouched, except that redaction applies to the whole report
+func FormatMarkdown(content string, format config.MarkdownConfig) string {
+	if format.IsDefault() {
+		return content
+	}
+	if format.Redact {
+		content = RedactReport(content, format.RedactPatterns)
+	}
+
+	lines := strings.Split(content, "\n")
+	if format.OmitMetadata {
+		lines = stripReportMetadata(lines)
+	}
+
+	shift := format.HeadingLevel - 1
+	if shift < 0 {
+		shift = 0
+	}
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		} else if !inFence {
+			if format.OmitHorizontalRules && trimmed == "---" {
+				continue
+			}
+			if level, _ := headingText(line); level > 0 && shift > 0 && strings.HasPrefix(line, "#") {
+				newLevel := level + shift
+				if newLevel > 6 {
+					newLevel = 6
+				}
+				line = strings.Repeat("#", newLevel) + line[level:]
+			}
+		}
+		out = append(out, line)
+	}
+	return collapseBlankLines(strings.Join(out, "\n"))
+}
+
+// stripReportMetadata removes the metadata lines between the title and the first rule, and the footer
+func stripReportMetadata(lines []string) []string {
+	for i, line := range lines {
+		if level, _ := headingText(line); level != 1 {
+			continue
+		}
+		sawMetadata := false
+		for j := i + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, "**") && strings.Contains(trimmed, "**: ") {
+				sawMetadata = true
+				continue
+			}
+			if trimmed == "---" && sawMetadata {
+				lines = append(lines[:i+1:i+1], lines[j+1:]...)
+			}
+			break
+		}
+		break
+	}
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(strings.TrimSpace(lines[i]), reportFooterPrefix) {
+			continue
+		}
+		start := i
+		for start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+			start--
+		}
+		if start > 0 && strings.TrimSpace(lines[start-1]) == "---" {
+			start--
+		}
+		lines = append(lines[:start:start], lines[i+1:]...)
+		break
+	}
+	return lines
+}
+
+// headingText returns the level and text of an ATX heading line, level 0 if the line is not a heading
+func headingText(line string) (int, string) {
+	trimmed := strings.TrimSpace(line)
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(trimmed) && trimmed[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(trimmed[level:])
+}
+
+// collapseBlankLines keeps at most one blank line between blocks
+func collapseBlankLines(content string) string {
+	for strings.Contains(content, "\n\n\n") {
+		content = strings.ReplaceAll(content, "\n\n\n", "\n\n")
+	}
+	return strings.TrimLeft(content, "\n")
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stuff-time/internal/config"
+)
+
+const testPeriodReport = "# 日周期总结报告\n\n" +
+	"**周期类型**: day\n\n" +
+	"**开始时间**: 2025-12-09 00:00:00\n\n" +
+	"**结束时间**: 2025-12-10 00:00:00\n\n" +
+	"**截图数量**: 3\n\n" +
+	"---\n\n" +
+	"## 事实总结\n\n" +
+	"编写代码\n\n" +
+	"```\n# not a heading\n---\n```\n\n" +
+	"---\n\n" +
+	"## 改进建议\n\n" +
+	"减少切换\n\n" +
+	"---\n\n" +
+	"*报告生成时间: 2025-12-10 09:00:00*\n"
+
+func TestFormatMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		format config.MarkdownConfig
+		want   string
+	}{
+		{
+			name:   "默认格式不变",
+			format: config.MarkdownConfig{HeadingLevel: 1},
+			want:   testPeriodReport,
+		},
+		{
+			name:   "标题下沉并去掉分隔线和元数据",
+			format: config.MarkdownConfig{HeadingLevel: 2, OmitHorizontalRules: true, OmitMetadata: true},
+			want: "## 日周期总结报告\n\n" +
+				"### 事实总结\n\n" +
+				"编写代码\n\n" +
+				"```\n# not a heading\n---\n```\n\n" +
+				"### 改进建议\n\n" +
+				"减少切换\n\n",
+		},
+		{
+			name:   "标题级别最多到 H6",
+			format: config.MarkdownConfig{HeadingLevel: 6, OmitMetadata: true},
+			want: "###### 日周期总结报告\n\n" +
+				"###### 事实总结\n\n" +
+				"编写代码\n\n" +
+				"```\n# not a heading\n---\n```\n\n" +
+				"---\n\n" +
+				"###### 改进建议\n\n" +
+				"减少切换\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatMarkdown(testPeriodReport, tt.format); got != tt.want {
+				t.Errorf("FormatMarkdown() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeriodReport_FormattedReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "day.md")
+	content := FormatMarkdown(testPeriodReport, config.MarkdownConfig{HeadingLevel: 2, OmitHorizontalRules: true})
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := NewReportParser(filepath.Dir(path)).ParsePeriodReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PeriodType != "day" || report.Analysis != "减少切换" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if want := "编写代码\n```\n# not a heading\n```"; report.Summary != want {
+		t.Errorf("Summary = %q, want %q", report.Summary, want)
+	}
+}
+
+func TestRedactReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"macOS home path", "编辑 /Users/alice/work/api/main.go 中的路由", "编辑 [路径] 中的路由"},
+		{"linux and tilde paths", "对比 /home/bob/notes.md 和 ~/src/app", "对比 [路径] 和 [路径]"},
+		{"windows path", `打开 C:\Users\carol\Desktop\plan.xlsx`, "打开 [路径]"},
+		{"system path kept", "查看 /var/log/system.log", "查看 /var/log/system.log"},
+		{"internal host", "访问 jenkins.corp 和 nas.local 的页面", "访问 [主机] 和 [主机] 的页面"},
+		{"ip address", "ssh 到 10.0.12.7 排查", "ssh 到 [IP] 排查"},
+		{"extra pattern", "部署到 api.acme.io", "部署到 [已脱敏]"},
+		{"image link kept", "![14:03](/Users/alice/data/shots/a.png)\n\n在 /Users/alice/x 下", "![14:03](/Users/alice/data/shots/a.png)\n\n在 [路径] 下"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactReport(tt.content, []string{`[a-z]+\.acme\.io`}); got != tt.want {
+				t.Errorf("RedactReport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
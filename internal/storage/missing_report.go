@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// MissingReport is a period summary whose report file was deleted or moved by hand
+type MissingReport struct {
+	PeriodKey  string
+	ReportPath string
+	DetectedAt time.Time
+}
+
+// MissingReportStore stores period summaries whose report files are missing
+type MissingReportStore interface {
+	// MarkReportMissing marks the report of a period as missing, keeping the first detection time
+	MarkReportMissing(periodKey, reportPath string) error
+	ClearReportMissing(periodKey string) error
+	// ListMissingReports returns missing reports ordered by period key
+	ListMissingReports() ([]*MissingReport, error)
+}
+
+func (s *SQLiteStorage) initMissingReportTable() error {
+	createMissingReportTable := `
+	CREATE TABLE IF NOT EXISTS missing_reports (
+		period_key TEXT PRIMARY KEY,
+		report_path TEXT NOT NULL,
+		detected_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createMissingReportTable); err != nil {
+		return fmt.Errorf("failed to create missing_reports table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) MarkReportMissing(periodKey, reportPath string) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	query := `
+	INSERT INTO missing_reports (period_key, report_path, detected_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(period_key) DO UPDATE SET report_path = excluded.report_path
+	`
+	if _, err := s.db.Exec(query, periodKey, reportPath, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to mark report missing: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ClearReportMissing(periodKey string) error {
+	periodKey = NormalizePeriodKey(periodKey)
+	if _, err := s.db.Exec(`DELETE FROM missing_reports WHERE period_key = ?`, periodKey); err != nil {
+		return fmt.Errorf("failed to clear missing report: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ListMissingReports() ([]*MissingReport, error) {
+	rows, err := s.db.Query(`SELECT period_key, report_path, detected_at FROM missing_reports ORDER BY period_key ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missing reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*MissingReport
+	for rows.Next() {
+		var r MissingReport
+		var detectedStr string
+		if err := rows.Scan(&r.PeriodKey, &r.ReportPath, &detectedStr); err != nil {
+			return nil, fmt.Errorf("failed to scan missing report: %w", err)
+		}
+		r.DetectedAt, _ = time.Parse(time.RFC3339Nano, detectedStr)
+		reports = append(reports, &r)
+	}
+	return reports, rows.Err()
+}
+
+func (r *ReportStorage) MarkReportMissing(periodKey, reportPath string) error {
+	return r.metadataStorage.MarkReportMissing(periodKey, reportPath)
+}
+
+func (r *ReportStorage) ClearReportMissing(periodKey string) error {
+	return r.metadataStorage.ClearReportMissing(periodKey)
+}
+
+func (r *ReportStorage) ListMissingReports() ([]*MissingReport, error) {
+	return r.metadataStorage.ListMissingReports()
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,31 @@ type ScreenshotRecord struct {
 	// This is generated by analyzing the screenshot image
 	Analysis string `db:"analysis"` // Keep field name for DB compatibility, but semantically it's a summary
 	HourKey  string `db:"hour_key"`
+	// AnalysisStatus tracks the analysis outcome, AnalysisError holds the failure reason (never written into Analysis)
+	AnalysisStatus string `db:"analysis_status"`
+	AnalysisError  string `db:"analysis_error"`
+	// App and WindowTitle are the frontmost application and window title at capture (screenshot.window_context),
+	// empty when unknown
+	App         string `db:"app"`
+	WindowTitle string `db:"window_title"`
+}
+
+// Screenshot analysis statuses
+const (
+	AnalysisPending    = "pending"     // Not analyzed yet
+	AnalysisDone       = "done"        // Analysis stored in Analysis
+	AnalysisSkipped    = "skipped"     // Desktop/lock screen or idle (reason in AnalysisError), no analysis needed
+	AnalysisFailed     = "failed"      // Analysis failed (retried by the next batch)
+	AnalysisRejected   = "rejected"    // Image refused by the vision API (not retried)
+	AnalysisSampledOut = "sampled_out" // Left out by rebuild sampling (not analyzed)
+)
+
+// ScreenshotAnalysisUpdate is one analysis result written by UpdateScreenshotAnalyses
+type ScreenshotAnalysisUpdate struct {
+	ID       string
+	Status   string // One of the Analysis* statuses
+	Analysis string // Only stored when Status is AnalysisDone
+	Error    string // Failure reason for AnalysisFailed/AnalysisRejected
 }
 
 type HourSummary struct {
@@ -71,3 +97,25 @@ type RollingCheckpoint struct {
 	IntermediateSummary string    `db:"intermediate_summary"`
 	CreatedAt           time.Time `db:"created_at"`
 }
+
+// AnalysisFailedPrefix prefixes the analysis text older versions stored for failed analyses
+// (still found in screenshot reports and migrated databases)
+const AnalysisFailedPrefix = "Analysis failed"
+
+// IsFailedAnalysis reports whether an analysis text is a legacy failure record
+func IsFailedAnalysis(analysis string) bool {
+	return strings.HasPrefix(analysis, AnalysisFailedPrefix)
+}
+
+// HasAnalysis reports whether the screenshot has a usable analysis (analyzed and not failed)
+func (r *ScreenshotRecord) HasAnalysis() bool {
+	if r.AnalysisStatus != "" && r.AnalysisStatus != AnalysisDone {
+		return false
+	}
+	return r.Analysis != "" && !IsFailedAnalysis(r.Analysis)
+}
+
+// AnalysisUnavailable reports whether the screenshot's analysis failed or was rejected
+func (r *ScreenshotRecord) AnalysisUnavailable() bool {
+	return r.AnalysisStatus == AnalysisFailed || r.AnalysisStatus == AnalysisRejected || IsFailedAnalysis(r.Analysis)
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// NotesFileName is the manual notes file of a directory's own period (year, quarter, month, day, hour)
+const NotesFileName = "notes.md"
+
+// notesSuffix is appended to a report's base name to form its manual notes file (e.g. week-W2-notes.md)
+const notesSuffix = "-notes.md"
+
+// NotesPathForReport returns the manual notes file that belongs to a report file
+func NotesPathForReport(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + notesSuffix
+}
+
+// IsNotesFile checks whether a file name is a manual notes file rather than a report
+func IsNotesFile(name string) bool {
+	return name == NotesFileName || strings.HasSuffix(name, notesSuffix)
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ScreenshotText is the text recognized (OCR) in a screenshot
+type ScreenshotText struct {
+	ScreenshotID string    `db:"screenshot_id"`
+	Timestamp    time.Time `db:"timestamp"`
+	ImagePath    string    `db:"image_path"`
+	Text         string    `db:"text"`
+}
+
+// OCRStore stores the recognized text of screenshots in a full-text index
+type OCRStore interface {
+	// SaveScreenshotText replaces the recognized text of a screenshot
+	SaveScreenshotText(screenshotID, text string) error
+	// SearchScreenshotText returns screenshots in [start, end) whose recognized text contains text, newest first
+	SearchScreenshotText(text string, start, end time.Time, limit int) ([]*ScreenshotText, error)
+	// QueryScreenshotsWithoutText returns screenshots in [start, end) without recognized text, oldest first
+	QueryScreenshotsWithoutText(start, end time.Time, limit int) ([]*ScreenshotRecord, error)
+}
+
+func (s *SQLiteStorage) initOCRTable() error {
+	// The trigram tokenizer matches any substring of three or more characters, also in CJK text
+	// without word boundaries and in codes like 0x80070005
+	createOCRTable := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS screenshot_text USING fts5(
+		screenshot_id UNINDEXED,
+		text,
+		tokenize = 'trigram'
+	);
+	`
+	if _, err := s.db.Exec(createOCRTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_text table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveScreenshotText(screenshotID, text string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM screenshot_text WHERE screenshot_id = ?`, screenshotID); err != nil {
+		return fmt.Errorf("failed to delete screenshot text: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO screenshot_text (screenshot_id, text) VALUES (?, ?)`, screenshotID, text); err != nil {
+		return fmt.Errorf("failed to save screenshot text: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit screenshot text: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SearchScreenshotText(text string, start, end time.Time, limit int) ([]*ScreenshotText, error) {
+	// The index only helps for three or more characters, shorter text is scanned with LIKE
+	condition, arg := `t.text MATCH ?`, `"`+strings.ReplaceAll(text, `"`, `""`)+`"`
+	if utf8.RuneCountInString(text) < 3 {
+		condition, arg = `t.text LIKE ? ESCAPE '\'`, likePattern(text)
+	}
+	query := `
+	SELECT s.id, s.timestamp, s.image_path, t.text
+	FROM screenshot_text t
+	JOIN screenshots s ON s.id = t.screenshot_id
+	WHERE ` + condition + ` AND s.timestamp >= ? AND s.timestamp < ?
+	ORDER BY s.timestamp DESC
+	LIMIT ?
+	`
+	rows, err := s.db.Query(query, arg, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search screenshot text: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*ScreenshotText
+	for rows.Next() {
+		var m ScreenshotText
+		var timestampStr string
+		if err := rows.Scan(&m.ScreenshotID, &timestampStr, &m.ImagePath, &m.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot text: %w", err)
+		}
+		if m.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		matches = append(matches, &m)
+	}
+	return matches, rows.Err()
+}
+
+func (s *SQLiteStorage) QueryScreenshotsWithoutText(start, end time.Time, limit int) ([]*ScreenshotRecord, error) {
+	query := `
+	SELECT id, timestamp, screen_id, image_path, COALESCE(analysis, ''), hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
+	FROM screenshots
+	WHERE timestamp >= ? AND timestamp < ?
+		AND id NOT IN (SELECT screenshot_id FROM screenshot_text)
+	ORDER BY timestamp ASC
+	LIMIT ?
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots without text: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*ScreenshotRecord
+	for rows.Next() {
+		var r ScreenshotRecord
+		var timestampStr string
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
+		}
+		if r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		records = append(records, &r)
+	}
+	return records, rows.Err()
+}
+
+func (r *ReportStorage) SaveScreenshotText(screenshotID, text string) error {
+	return r.metadataStorage.SaveScreenshotText(screenshotID, text)
+}
+
+func (r *ReportStorage) SearchScreenshotText(text string, start, end time.Time, limit int) ([]*ScreenshotText, error) {
+	return r.metadataStorage.SearchScreenshotText(text, start, end, limit)
+}
+
+func (r *ReportStorage) QueryScreenshotsWithoutText(start, end time.Time, limit int) ([]*ScreenshotRecord, error) {
+	return r.metadataStorage.QueryScreenshotsWithoutText(start, end, limit)
+}
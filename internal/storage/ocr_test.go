@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchScreenshotText(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	texts := []string{"Windows Update Error code 0x80070005 Access denied", "构建失败：找不到模块 stuff-time", ""}
+	for i := range texts {
+		record := &ScreenshotRecord{ID: string(rune('a' + i)), Timestamp: base.Add(time.Duration(i) * time.Minute),
+			ImagePath: string(rune('a'+i)) + ".png", HourKey: base.Format("2006-01-02-15")}
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, text := range texts[:2] {
+		if err := st.SaveScreenshotText(string(rune('a'+i)), text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"0x80070005", []string{"a"}},
+		{"access DENIED", []string{"a"}},
+		{"找不到模块", []string{"b"}},
+		{"构建", []string{"b"}},
+		{`"quoted"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			found, err := st.SearchScreenshotText(tt.text, base, base.Add(time.Hour), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range found {
+				got = append(got, f.ScreenshotID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("SearchScreenshotText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			if len(found) > 0 && (found[0].ImagePath == "" || found[0].Timestamp.IsZero()) {
+				t.Errorf("result %+v misses image path or timestamp", found[0])
+			}
+		})
+	}
+
+	missing, err := st.QueryScreenshotsWithoutText(base, base.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0].ID != "c" {
+		t.Errorf("QueryScreenshotsWithoutText() = %d records, want c", len(missing))
+	}
+
+	if err := st.DeleteScreenshotsByIDs([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := st.SearchScreenshotText("0x80070005", base, base.Add(time.Hour), 10); len(found) != 0 {
+		t.Errorf("text of deleted screenshot is still found")
+	}
+}
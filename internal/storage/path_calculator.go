@@ -67,7 +67,7 @@ func (pc *PathCalculator) CalculateHourSegment(minute int) int {
 }
 
 // CalculateWorkSegment 计算日内工作段号（1-based）
-// 公式：段号 = floor(小时数 * day_work_segments / 24) + 1
+// 公式：段号 = floor(小时数 * day_work_segments / 24) + 1，小时数从 day_start_hour 起算
 func (pc *PathCalculator) CalculateWorkSegment(hour int) int {
 	if pc.config.DayWorkSegments <= 0 {
 		return 0 // 0 表示不使用工作段
@@ -83,6 +83,7 @@ func (pc *PathCalculator) CalculateWorkSegment(hour int) int {
 	if hour >= 24 {
 		hour = 23
 	}
+	hour = (hour - pc.config.DayStartHour + 24) % 24
 
 	segmentNum := (hour * pc.config.DayWorkSegments / 24) + 1
 
@@ -208,10 +209,12 @@ func daysInMonth(t time.Time) int {
 // BuildPath 构建完整的层级嵌套路径
 // 路径格式：YYYY/QN/MM/WN/DD/WSN/HH/SN/MIN.ext
 // 如果某个层级的分段数为1，则跳过该层级
+// 日期部分按 day_start_hour 归属：早于一天开始时间的文件放在前一天的目录下
 func (pc *PathCalculator) BuildPath(timestamp time.Time, fileType FileType) string {
-	year := timestamp.Year()
-	month := int(timestamp.Month())
-	day := timestamp.Day()
+	date := pc.config.LogicalDate(timestamp)
+	year := date.Year()
+	month := int(date.Month())
+	day := date.Day()
 	hour := timestamp.Hour()
 	minute := timestamp.Minute()
 
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period keys identify a period summary in the database (summaries, locks, lineage, attachments, ...)
+//
+// Version 2 keys are typed, "<period type>:<value>", so keys of different types never collide:
+//
+//	fifteenmin, halfhour, hour  start instant in UTC       hour:2025-12-09T06:00Z
+//	day, personal-day           local date                 day:2025-12-09
+//	week                        local date of its first day week:2025-12-08
+//	work-segment                local date and index       work-segment:2025-12-09#0
+//	month                       local month                month:2025-12
+//	quarter                     year and quarter           quarter:2025-Q4
+//	year                        year                       year:2025
+//
+// Sub-hour and hour keys are normalized to UTC, so a period keeps its key when the machine's
+// timezone changes; any offset is accepted when parsing (fifteenmin:2025-12-09T14:15+08:00).
+// Version 1 keys are untyped local times (2025-12-09-14-15, 2025-12-08-week, personal-2025-12-09,
+// 2025-12-09-segment-0); they are still accepted everywhere, read in the local timezone, and
+// migrated to version 2 when the database is opened
+
+// ParsedPeriodKey is a parsed period key
+type ParsedPeriodKey struct {
+	Type    string
+	Start   time.Time // Local start of the period; midnight for date based types, see Range
+	Segment int       // Index of a work-segment within its day
+}
+
+const instantKeyLayout = "2006-01-02T15:04Z07:00"
+
+// isInstantPeriodType reports whether keys of the period type identify an instant rather than a date
+func isInstantPeriodType(periodType string) bool {
+	return periodType == "fifteenmin" || periodType == "halfhour" || periodType == "hour"
+}
+
+// FormatPeriodKey returns the version 2 key of the period of the given type starting at start,
+// empty for unknown types (work-segment keys are built by WorkSegmentPeriodKey)
+func FormatPeriodKey(periodType string, start time.Time) string {
+	var value string
+	switch periodType {
+	case "fifteenmin", "halfhour", "hour":
+		value = start.UTC().Format("2006-01-02T15:04Z")
+	case "day", "personal-day", "week":
+		value = start.Format("2006-01-02")
+	case "month":
+		value = start.Format("2006-01")
+	case "quarter":
+		value = fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case "year":
+		value = start.Format("2006")
+	default:
+		return ""
+	}
+	return periodType + ":" + value
+}
+
+// WorkSegmentPeriodKey returns the version 2 key of the index-th work segment of day
+func WorkSegmentPeriodKey(day time.Time, index int) string {
+	return fmt.Sprintf("work-segment:%s#%d", day.Format("2006-01-02"), index)
+}
+
+// String returns the version 2 key
+func (k *ParsedPeriodKey) String() string {
+	if k.Type == "work-segment" {
+		return WorkSegmentPeriodKey(k.Start, k.Segment)
+	}
+	return FormatPeriodKey(k.Type, k.Start)
+}
+
+// Legacy returns the version 1 key, which report file names and paths are still derived from
+func (k *ParsedPeriodKey) Legacy() string {
+	switch k.Type {
+	case "fifteenmin", "halfhour":
+		return k.Start.Format("2006-01-02-15-04")
+	case "hour":
+		return k.Start.Format("2006-01-02-15")
+	case "day":
+		return k.Start.Format("2006-01-02")
+	case "personal-day":
+		return "personal-" + k.Start.Format("2006-01-02")
+	case "week":
+		return k.Start.Format("2006-01-02") + "-week"
+	case "work-segment":
+		return fmt.Sprintf("%s-segment-%d", k.Start.Format("2006-01-02"), k.Segment)
+	case "month":
+		return k.Start.Format("2006-01")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", k.Start.Year(), (int(k.Start.Month())-1)/3+1)
+	default:
+		return k.Start.Format("2006")
+	}
+}
+
+// Range returns the time range of the period; date based periods start at dayStartHour
+// (storage.day_start_hour). Work-segment ranges depend on storage.day_work_segments and can't be derived
+func (k *ParsedPeriodKey) Range(dayStartHour int) (time.Time, time.Time, error) {
+	start := k.Start
+	if !isInstantPeriodType(k.Type) {
+		start = time.Date(start.Year(), start.Month(), start.Day(), dayStartHour, 0, 0, 0, start.Location())
+	}
+	switch k.Type {
+	case "fifteenmin":
+		return start, start.Add(15 * time.Minute), nil
+	case "halfhour":
+		return start, start.Add(30 * time.Minute), nil
+	case "hour":
+		return start, start.Add(time.Hour), nil
+	case "day", "personal-day":
+		return start, start.AddDate(0, 0, 1), nil
+	case "week":
+		return start, start.AddDate(0, 0, 7), nil
+	case "month":
+		return start, start.AddDate(0, 1, 0), nil
+	case "quarter":
+		return start, start.AddDate(0, 3, 0), nil
+	case "year":
+		return start, start.AddDate(1, 0, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("the range of %s periods is not known from the key", k.Type)
+	}
+}
+
+var (
+	quarterValuePattern      = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	workSegmentValuePattern  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})#(\d+)$`)
+	legacyFifteenminPattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}-\d{2}$`)
+	legacyHourPattern        = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}$`)
+	legacyDayPattern         = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	legacyWeekPattern        = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-week$`)
+	legacyWorkSegmentPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-segment-(\d+)$`)
+	legacyPersonalPattern    = regexp.MustCompile(`^personal-(\d{4}-\d{2}-\d{2})$`)
+	legacyMonthPattern       = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	legacyYearPattern        = regexp.MustCompile(`^\d{4}$`)
+)
+
+// ParsePeriodKey parses a version 2 or version 1 period key
+// Version 1 keys of fifteenmin and halfhour periods look the same and are parsed as fifteenmin
+func ParsePeriodKey(key string) (*ParsedPeriodKey, error) {
+	if periodType, value, ok := strings.Cut(key, ":"); ok {
+		return parsePeriodKeyValue(key, periodType, value)
+	}
+	return parseLegacyPeriodKey(key)
+}
+
+func parsePeriodKeyValue(key, periodType, value string) (*ParsedPeriodKey, error) {
+	k := &ParsedPeriodKey{Type: periodType}
+	var err error
+	switch periodType {
+	case "fifteenmin", "halfhour", "hour":
+		var t time.Time
+		if t, err = time.Parse(instantKeyLayout, value); err == nil {
+			k.Start = t.Local()
+		}
+	case "day", "personal-day", "week":
+		k.Start, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	case "work-segment":
+		m := workSegmentValuePattern.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("invalid period key %q", key)
+		}
+		k.Segment, _ = strconv.Atoi(m[2])
+		k.Start, err = time.ParseInLocation("2006-01-02", m[1], time.Local)
+	case "month":
+		k.Start, err = time.ParseInLocation("2006-01", value, time.Local)
+	case "quarter":
+		m := quarterValuePattern.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("invalid period key %q", key)
+		}
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		k.Start = time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.Local)
+	case "year":
+		k.Start, err = time.ParseInLocation("2006", value, time.Local)
+	default:
+		return nil, fmt.Errorf("unknown period type in key %q", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid period key %q: %w", key, err)
+	}
+	return k, nil
+}
+
+func parseLegacyPeriodKey(key string) (*ParsedPeriodKey, error) {
+	var k *ParsedPeriodKey
+	var err error
+	parse := func(periodType, layout, value string) {
+		k = &ParsedPeriodKey{Type: periodType}
+		k.Start, err = time.ParseInLocation(layout, value, time.Local)
+	}
+
+	if legacyFifteenminPattern.MatchString(key) {
+		parse("fifteenmin", "2006-01-02-15-04", key)
+	} else if legacyHourPattern.MatchString(key) {
+		parse("hour", "2006-01-02-15", key)
+	} else if legacyDayPattern.MatchString(key) {
+		parse("day", "2006-01-02", key)
+	} else if m := legacyWeekPattern.FindStringSubmatch(key); m != nil {
+		parse("week", "2006-01-02", m[1])
+	} else if m := legacyPersonalPattern.FindStringSubmatch(key); m != nil {
+		parse("personal-day", "2006-01-02", m[1])
+	} else if m := legacyWorkSegmentPattern.FindStringSubmatch(key); m != nil {
+		parse("work-segment", "2006-01-02", m[1])
+		k.Segment, _ = strconv.Atoi(m[2])
+	} else if legacyMonthPattern.MatchString(key) {
+		parse("month", "2006-01", key)
+	} else if quarterValuePattern.MatchString(key) {
+		return parsePeriodKeyValue(key, "quarter", key)
+	} else if legacyYearPattern.MatchString(key) {
+		parse("year", "2006", key)
+	} else {
+		return nil, fmt.Errorf("unrecognized period key %q", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid period key %q: %w", key, err)
+	}
+	return k, nil
+}
+
+// NormalizePeriodKey returns the version 2 form of a period key; keys that can't be parsed
+// are returned unchanged
+func NormalizePeriodKey(key string) string {
+	k, err := ParsePeriodKey(key)
+	if err != nil {
+		return key
+	}
+	return k.String()
+}
+
+// normalizePeriodKeyOfType is NormalizePeriodKey for keys whose period type is known, which tells
+// version 1 halfhour keys apart from fifteenmin keys
+func normalizePeriodKeyOfType(key, periodType string) string {
+	k, err := ParsePeriodKey(key)
+	if err != nil {
+		return key
+	}
+	if k.Type != periodType && isInstantPeriodType(k.Type) && isInstantPeriodType(periodType) {
+		k.Type = periodType
+	}
+	return k.String()
+}
+
+// LegacyPeriodKey returns the version 1 form of a period key; keys that can't be parsed are
+// returned unchanged
+func LegacyPeriodKey(key string) string {
+	k, err := ParsePeriodKey(key)
+	if err != nil {
+		return key
+	}
+	return k.Legacy()
+}
+
+// periodKeyTables lists the tables keyed by period keys, with the column holding the period type if any
+var periodKeyTables = []struct {
+	table      string
+	typeColumn string
+}{
+	{"period_summaries", "period_type"},
+	{"golden_periods", "period_type"},
+	{"period_locks", ""},
+	{"summary_lineage", ""},
+	{"missing_reports", ""},
+	{"period_attachments", ""},
+	{"period_issues", ""},
+	{"deliverables", ""},
+	{"dedup_savings", ""},
+	{"remote_summaries", ""},
+}
+
+// migratePeriodKeys rewrites version 1 period keys to version 2, including the child keys recorded
+// in summary lineage; rows already stored under the version 2 key are kept
+// Version 1 keys are read in the current local timezone
+func (s *SQLiteStorage) migratePeriodKeys() error {
+	// Version 1 halfhour and fifteenmin keys look the same, so tables without a period type column
+	// take the type of the summary stored under the key
+	summaryTypes := make(map[string]string)
+	rows, err := s.db.Query(`SELECT period_key, period_type FROM period_summaries WHERE instr(period_key, ':') = 0`)
+	if err != nil {
+		return fmt.Errorf("failed to query period types: %w", err)
+	}
+	for rows.Next() {
+		var key, periodType string
+		if err := rows.Scan(&key, &periodType); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan period type: %w", err)
+		}
+		summaryTypes[key] = periodType
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query period types: %w", err)
+	}
+
+	type rename struct{ from, to string }
+	renames := make(map[string][]rename)
+	for _, t := range periodKeyTables {
+		typeColumn := "''"
+		if t.typeColumn != "" {
+			typeColumn = t.typeColumn
+		}
+		rows, err := s.db.Query(fmt.Sprintf(`SELECT DISTINCT period_key, %s FROM %s WHERE instr(period_key, ':') = 0`, typeColumn, t.table))
+		if err != nil {
+			return fmt.Errorf("failed to query period keys of %s: %w", t.table, err)
+		}
+		for rows.Next() {
+			var key, periodType string
+			if err := rows.Scan(&key, &periodType); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan period key of %s: %w", t.table, err)
+			}
+			if periodType == "" {
+				periodType = summaryTypes[key]
+			}
+			if normalized := normalizePeriodKeyOfType(key, periodType); normalized != key {
+				renames[t.table] = append(renames[t.table], rename{key, normalized})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to query period keys of %s: %w", t.table, err)
+		}
+	}
+
+	type lineageChildren struct{ key, children string }
+	var lineages []lineageChildren
+	rows, err = s.db.Query(`SELECT period_key, children FROM summary_lineage WHERE children IS NOT NULL AND children != ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query lineage: %w", err)
+	}
+	for rows.Next() {
+		var l lineageChildren
+		if err := rows.Scan(&l.key, &l.children); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan lineage: %w", err)
+		}
+		children := strings.Split(l.children, "\n")
+		for i, child := range children {
+			children[i] = normalizePeriodKeyOfType(child, summaryTypes[child])
+		}
+		if migrated := strings.Join(children, "\n"); migrated != l.children {
+			lineages = append(lineages, lineageChildren{l.key, migrated})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query lineage: %w", err)
+	}
+
+	if len(renames) == 0 && len(lineages) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, l := range lineages {
+		if _, err := tx.Exec(`UPDATE summary_lineage SET children = ? WHERE period_key = ?`, l.children, l.key); err != nil {
+			return fmt.Errorf("failed to migrate lineage of %s: %w", l.key, err)
+		}
+	}
+	for table, list := range renames {
+		for _, r := range list {
+			if _, err := tx.Exec(fmt.Sprintf(`UPDATE OR IGNORE %s SET period_key = ? WHERE period_key = ?`, table), r.to, r.from); err != nil {
+				return fmt.Errorf("failed to migrate period key %s of %s: %w", r.from, table, err)
+			}
+			// Left over when the version 2 key already existed
+			if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE period_key = ?`, table), r.from); err != nil {
+				return fmt.Errorf("failed to migrate period key %s of %s: %w", r.from, table, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNormalizePeriodKey(t *testing.T) {
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.FixedZone("CST", 8*3600)
+
+	tests := []struct {
+		key    string
+		want   string
+		legacy string
+	}{
+		{"2025-12-09-14-15", "fifteenmin:2025-12-09T06:15Z", "2025-12-09-14-15"},
+		{"2025-12-09-14", "hour:2025-12-09T06:00Z", "2025-12-09-14"},
+		{"2025-12-09-03", "hour:2025-12-08T19:00Z", "2025-12-09-03"},
+		{"hour:2025-12-09T14:00+08:00", "hour:2025-12-09T06:00Z", "2025-12-09-14"},
+		{"2025-12-09", "day:2025-12-09", "2025-12-09"},
+		{"personal-2025-12-09", "personal-day:2025-12-09", "personal-2025-12-09"},
+		{"2025-12-08-week", "week:2025-12-08", "2025-12-08-week"},
+		{"2025-12-09-segment-1", "work-segment:2025-12-09#1", "2025-12-09-segment-1"},
+		{"2025-12", "month:2025-12", "2025-12"},
+		{"2025-Q4", "quarter:2025-Q4", "2025-Q4"},
+		{"2025", "year:2025", "2025"},
+		{"week-W49", "week-W49", "week-W49"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got := NormalizePeriodKey(tt.key)
+			if got != tt.want {
+				t.Errorf("NormalizePeriodKey() = %q, want %q", got, tt.want)
+			}
+			if NormalizePeriodKey(got) != got {
+				t.Errorf("NormalizePeriodKey(%q) is not stable", got)
+			}
+			if legacy := LegacyPeriodKey(got); legacy != tt.legacy {
+				t.Errorf("LegacyPeriodKey() = %q, want %q", legacy, tt.legacy)
+			}
+		})
+	}
+
+	if got := normalizePeriodKeyOfType("2025-12-09-14-30", "halfhour"); got != "halfhour:2025-12-09T06:30Z" {
+		t.Errorf("halfhour key = %q", got)
+	}
+	if _, err := ParsePeriodKey("hour:2025-12-09"); err == nil {
+		t.Errorf("ParsePeriodKey() accepted an hour key without time")
+	}
+}
+
+func TestMigratePeriodKeys(t *testing.T) {
+	s, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	// The day was also saved under its version 2 key, which wins
+	for _, row := range [][3]string{
+		{"2025-12-09-14", "hour", "v1"},
+		{"2025-12-09-14-30", "halfhour", "v1"},
+		{"2025-12-09", "day", "v1"},
+		{"day:2025-12-09", "day", "v2"},
+	} {
+		if _, err := s.db.Exec(`INSERT INTO period_summaries (period_key, period_type, start_time, end_time, screenshots, summary)
+			VALUES (?, ?, ?, ?, '', ?)`, row[0], row[1], start, start, row[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.db.Exec(`INSERT INTO summary_lineage (period_key, children, screenshots, created_at)
+		VALUES ('2025-12-09', ?, '', ?)`, "2025-12-09-14\n2025-12-09-15", start); err != nil {
+		t.Fatal(err)
+	}
+	// A table without a period type takes the type of the summary
+	if _, err := s.db.Exec(`INSERT INTO period_locks (period_key, reason, locked_at) VALUES ('2025-12-09-14-30', '', ?)`, start); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.migratePeriodKeys(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var legacy int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM period_summaries WHERE instr(period_key, ':') = 0`).Scan(&legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy != 0 {
+		t.Errorf("%d version 1 keys left", legacy)
+	}
+	if hour, err := s.GetPeriodSummary("2025-12-09-14"); err != nil || hour == nil || hour.PeriodKey != FormatPeriodKey("hour", start) {
+		t.Errorf("hour summary = %+v, %v", hour, err)
+	}
+	if day, err := s.GetPeriodSummary("day:2025-12-09"); err != nil || day == nil || day.Summary != "v2" {
+		t.Errorf("day summary = %+v, %v, want the version 2 row", day, err)
+	}
+	lineage, err := s.GetLineage("2025-12-09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lineage == nil || len(lineage.Children) != 2 || lineage.Children[0] != FormatPeriodKey("hour", start) {
+		t.Errorf("lineage = %+v, want migrated children", lineage)
+	}
+	halfhour := FormatPeriodKey("halfhour", start.Add(30*time.Minute))
+	if locked, err := s.IsPeriodLocked(halfhour); err != nil || !locked {
+		t.Errorf("lock of %s = %v, %v, want it migrated with the summary's type", halfhour, locked, err)
+	}
+}
+
+func TestPeriodKeyRange(t *testing.T) {
+	tests := []struct {
+		key        string
+		start, end time.Time
+	}{
+		{"day:2025-12-09", time.Date(2025, 12, 9, 4, 0, 0, 0, time.Local), time.Date(2025, 12, 10, 4, 0, 0, 0, time.Local)},
+		{"week:2025-12-08", time.Date(2025, 12, 8, 4, 0, 0, 0, time.Local), time.Date(2025, 12, 15, 4, 0, 0, 0, time.Local)},
+		{"month:2025-12", time.Date(2025, 12, 1, 4, 0, 0, 0, time.Local), time.Date(2026, 1, 1, 4, 0, 0, 0, time.Local)},
+		{FormatPeriodKey("hour", time.Date(2025, 12, 9, 2, 0, 0, 0, time.Local)), time.Date(2025, 12, 9, 2, 0, 0, 0, time.Local), time.Date(2025, 12, 9, 3, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		key, err := ParsePeriodKey(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start, end, err := key.Range(4)
+		if err != nil || !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: Range() = %s, %s, %v; want %s, %s", tt.key, start, end, err, tt.start, tt.end)
+		}
+	}
+	if key, _ := ParsePeriodKey("work-segment:2025-12-09#0"); key != nil {
+		if _, _, err := key.Range(4); err == nil {
+			t.Errorf("Range() of a work-segment key should fail")
+		}
+	}
+}
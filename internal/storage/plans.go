@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlanBlock is a focus block planned ahead (e.g. tomorrow 09:30-11:30 "spec writing"); the day report
+// compares it with the focused time actually captured in it
+type PlanBlock struct {
+	ID        int64
+	StartTime time.Time
+	EndTime   time.Time
+	Title     string
+	CreatedAt time.Time
+}
+
+// PlanStore stores planned focus blocks
+type PlanStore interface {
+	// AddPlanBlock stores a block and sets its ID
+	AddPlanBlock(block *PlanBlock) error
+	// QueryPlanBlocks returns the blocks starting in [start, end), ordered by start time
+	QueryPlanBlocks(start, end time.Time) ([]*PlanBlock, error)
+	// DeletePlanBlock removes a block, returning false when no block has the ID
+	DeletePlanBlock(id int64) (bool, error)
+}
+
+func (s *SQLiteStorage) initPlanTable() error {
+	createPlanTable := `
+	CREATE TABLE IF NOT EXISTS plan_blocks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME NOT NULL,
+		title TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_plan_blocks_start_time ON plan_blocks(start_time);
+	`
+	if _, err := s.db.Exec(createPlanTable); err != nil {
+		return fmt.Errorf("failed to create plan_blocks table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddPlanBlock(block *PlanBlock) error {
+	if block.CreatedAt.IsZero() {
+		block.CreatedAt = time.Now()
+	}
+	result, err := s.db.Exec(`INSERT INTO plan_blocks (start_time, end_time, title, created_at) VALUES (?, ?, ?, ?)`,
+		block.StartTime.Format(time.RFC3339Nano), block.EndTime.Format(time.RFC3339Nano), block.Title, block.CreatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to add plan block: %w", err)
+	}
+	if block.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to get plan block id: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryPlanBlocks(start, end time.Time) ([]*PlanBlock, error) {
+	rows, err := s.db.Query(`SELECT id, start_time, end_time, title, created_at FROM plan_blocks
+		WHERE start_time >= ? AND start_time < ? ORDER BY start_time ASC, id ASC`,
+		start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plan blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*PlanBlock
+	for rows.Next() {
+		var b PlanBlock
+		var startStr, endStr, createdStr string
+		if err := rows.Scan(&b.ID, &startStr, &endStr, &b.Title, &createdStr); err != nil {
+			return nil, fmt.Errorf("failed to scan plan block: %w", err)
+		}
+		if b.StartTime, err = time.Parse(time.RFC3339Nano, startStr); err != nil {
+			return nil, fmt.Errorf("failed to parse start time: %w", err)
+		}
+		if b.EndTime, err = time.Parse(time.RFC3339Nano, endStr); err != nil {
+			return nil, fmt.Errorf("failed to parse end time: %w", err)
+		}
+		if b.CreatedAt, err = time.Parse(time.RFC3339Nano, createdStr); err != nil {
+			return nil, fmt.Errorf("failed to parse created time: %w", err)
+		}
+		blocks = append(blocks, &b)
+	}
+	return blocks, rows.Err()
+}
+
+func (s *SQLiteStorage) DeletePlanBlock(id int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM plan_blocks WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete plan block: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete plan block: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *ReportStorage) AddPlanBlock(block *PlanBlock) error {
+	return r.metadataStorage.AddPlanBlock(block)
+}
+
+func (r *ReportStorage) QueryPlanBlocks(start, end time.Time) ([]*PlanBlock, error) {
+	return r.metadataStorage.QueryPlanBlocks(start, end)
+}
+
+func (r *ReportStorage) DeletePlanBlock(id int64) (bool, error) {
+	return r.metadataStorage.DeletePlanBlock(id)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PromptVersion is a stored version of a prompt; at most one version of a prompt is active
+type PromptVersion struct {
+	Name      string // "<scene>/<file name without .txt>", e.g. summary/day
+	Version   int    // 1-based, in creation order
+	Content   string
+	Active    bool
+	Author    string
+	Note      string
+	CreatedAt time.Time
+}
+
+// PromptStore stores the version history of prompts (prompts.store: db)
+type PromptStore interface {
+	// AddPromptVersion saves the content as the next version of the prompt and activates it
+	AddPromptVersion(version *PromptVersion) error
+	// GetActivePrompt returns nil if the prompt has no active version
+	GetActivePrompt(name string) (*PromptVersion, error)
+	// ListPromptVersions returns the versions of a prompt, newest first
+	ListPromptVersions(name string) ([]*PromptVersion, error)
+	// ActivatePromptVersion activates a version of a prompt, returns false if the version does not exist
+	ActivatePromptVersion(name string, version int) (bool, error)
+	// ListActivePrompts returns the active version of every stored prompt, ordered by name
+	ListActivePrompts() ([]*PromptVersion, error)
+}
+
+func (s *SQLiteStorage) initPromptTable() error {
+	createPromptTable := `
+	CREATE TABLE IF NOT EXISTS prompt_versions (
+		name TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		active INTEGER NOT NULL DEFAULT 0,
+		author TEXT,
+		note TEXT,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (name, version)
+	);
+	`
+	if _, err := s.db.Exec(createPromptTable); err != nil {
+		return fmt.Errorf("failed to create prompt_versions table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddPromptVersion(version *PromptVersion) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) + 1 FROM prompt_versions WHERE name = ?`,
+		version.Name).Scan(&version.Version); err != nil {
+		return fmt.Errorf("failed to get next prompt version: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE prompt_versions SET active = 0 WHERE name = ?`, version.Name); err != nil {
+		return fmt.Errorf("failed to deactivate prompt versions: %w", err)
+	}
+	query := `INSERT INTO prompt_versions (name, version, content, active, author, note, created_at) VALUES (?, ?, ?, 1, ?, ?, ?)`
+	if _, err := tx.Exec(query, version.Name, version.Version, version.Content, version.Author, version.Note,
+		version.CreatedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to insert prompt version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit prompt version: %w", err)
+	}
+	version.Active = true
+	return nil
+}
+
+func (s *SQLiteStorage) GetActivePrompt(name string) (*PromptVersion, error) {
+	versions, err := s.queryPromptVersions(`WHERE name = ? AND active = 1`, name)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+func (s *SQLiteStorage) ListPromptVersions(name string) ([]*PromptVersion, error) {
+	return s.queryPromptVersions(`WHERE name = ? ORDER BY version DESC`, name)
+}
+
+func (s *SQLiteStorage) ActivatePromptVersion(name string, version int) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM prompt_versions WHERE name = ? AND version = ?`, name, version).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get prompt version: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE prompt_versions SET active = (version = ?) WHERE name = ?`, version, name); err != nil {
+		return false, fmt.Errorf("failed to activate prompt version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit prompt activation: %w", err)
+	}
+	return true, nil
+}
+
+func (s *SQLiteStorage) ListActivePrompts() ([]*PromptVersion, error) {
+	return s.queryPromptVersions(`WHERE active = 1 ORDER BY name ASC`)
+}
+
+func (s *SQLiteStorage) queryPromptVersions(where string, args ...interface{}) ([]*PromptVersion, error) {
+	query := `SELECT name, version, content, active, COALESCE(author, ''), COALESCE(note, ''), created_at FROM prompt_versions ` + where
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*PromptVersion
+	for rows.Next() {
+		var v PromptVersion
+		var createdStr string
+		if err := rows.Scan(&v.Name, &v.Version, &v.Content, &v.Active, &v.Author, &v.Note, &createdStr); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt version: %w", err)
+		}
+		v.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *ReportStorage) AddPromptVersion(version *PromptVersion) error {
+	return r.metadataStorage.AddPromptVersion(version)
+}
+
+func (r *ReportStorage) GetActivePrompt(name string) (*PromptVersion, error) {
+	return r.metadataStorage.GetActivePrompt(name)
+}
+
+func (r *ReportStorage) ListPromptVersions(name string) ([]*PromptVersion, error) {
+	return r.metadataStorage.ListPromptVersions(name)
+}
+
+func (r *ReportStorage) ActivatePromptVersion(name string, version int) (bool, error) {
+	return r.metadataStorage.ActivatePromptVersion(name, version)
+}
+
+func (r *ReportStorage) ListActivePrompts() ([]*PromptVersion, error) {
+	return r.metadataStorage.ListActivePrompts()
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPromptVersions(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	for _, content := range []string{"v1 prompt", "v2 prompt"} {
+		if err := st.AddPromptVersion(&PromptVersion{Name: "summary/day", Content: content, Author: "alice"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.AddPromptVersion(&PromptVersion{Name: "summary/hour", Content: "hour prompt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := st.GetActivePrompt("summary/day")
+	if err != nil || active == nil || active.Version != 2 || active.Content != "v2 prompt" {
+		t.Fatalf("GetActivePrompt() = %+v, %v, want v2", active, err)
+	}
+
+	// Reverting keeps the history and activates the earlier version only
+	if ok, err := st.ActivatePromptVersion("summary/day", 1); !ok || err != nil {
+		t.Fatalf("ActivatePromptVersion() = %v, %v", ok, err)
+	}
+	if ok, _ := st.ActivatePromptVersion("summary/day", 9); ok {
+		t.Errorf("ActivatePromptVersion() activated a missing version")
+	}
+	versions, err := st.ListPromptVersions("summary/day")
+	if err != nil || len(versions) != 2 || versions[0].Version != 2 || versions[0].Active || !versions[1].Active {
+		t.Errorf("ListPromptVersions() = %+v, %v", versions, err)
+	}
+
+	all, err := st.ListActivePrompts()
+	if err != nil || len(all) != 2 || all[0].Name != "summary/day" || all[0].Content != "v1 prompt" || all[1].Name != "summary/hour" {
+		t.Errorf("ListActivePrompts() = %+v, %v", all, err)
+	}
+	if missing, err := st.GetActivePrompt("summary/week"); missing != nil || err != nil {
+		t.Errorf("GetActivePrompt() of a prompt without versions = %+v, %v", missing, err)
+	}
+}
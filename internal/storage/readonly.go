@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrReadOnly is returned by write operations when storage is opened read-only
+var ErrReadOnly = errors.New("storage is opened read-only")
+
+// readOnly makes newly created storages open the data directory read-only (viewer mode)
+var readOnly atomic.Bool
+
+// SetReadOnly switches read-only mode for storages created afterwards
+// In read-only mode the database is opened with mode=ro (no schema migrations) and report files are never written
+func SetReadOnly(enabled bool) {
+	readOnly.Store(enabled)
+}
+
+// IsReadOnly reports whether storages are opened read-only
+func IsReadOnly() bool {
+	return readOnly.Load()
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Replacements of the text masked by RedactReport
+const (
+	RedactedPath = "[路径]"
+	RedactedHost = "[主机]"
+	RedactedIP   = "[IP]"
+	RedactedText = "[已脱敏]" // Matches of storage.markdown.redact_patterns
+)
+
+var (
+	// Absolute paths under a home directory: /Users/<name>, /home/<name>, C:\Users\<name>, and ~/...
+	homePathPattern = regexp.MustCompile(`(?:/Users|/home)/[^/\s]+(?:/[^\s)\]>"'` + "`" + `]*)?|[A-Za-z]:\\Users\\[^\\\s]+(?:\\[^\s)\]>"'` + "`" + `]*)?|~/[^\s)\]>"'` + "`" + `]+`)
+	// Host names under domains that only resolve inside a network
+	internalHostPattern = regexp.MustCompile(`(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.(?:local|localdomain|internal|intranet|corp|lan|home\.arpa)\b`)
+	ipv4Pattern         = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+	// Image targets keep pointing at the screenshots, so the local viewer can still show them
+	imageTargetPattern = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+)
+
+// RedactReport masks absolute home directory paths, internal hostnames, IPv4 addresses and the matches of
+// the extra patterns in a report, for reports synced to cloud note apps
+// Screenshot image links are left intact; invalid extra patterns are skipped (they are rejected on config load)
+func RedactReport(content string, extraPatterns []string) string {
+	type rule struct {
+		re          *regexp.Regexp
+		replacement string
+	}
+	rules := []rule{{homePathPattern, RedactedPath}, {internalHostPattern, RedactedHost}, {ipv4Pattern, RedactedIP}}
+	for _, pattern := range extraPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			rules = append(rules, rule{re, RedactedText})
+		}
+	}
+
+	for _, r := range rules {
+		images := imageTargetPattern.FindAllStringIndex(content, -1)
+		matches := r.re.FindAllStringIndex(content, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		out := make([]byte, 0, len(content))
+		last := 0
+		for _, m := range matches {
+			if m[0] == m[1] || insideSpan(images, m[0]) {
+				continue
+			}
+			out = append(out, content[last:m[0]]...)
+			out = append(out, r.replacement...)
+			last = m[1]
+		}
+		content = string(append(out, content[last:]...))
+	}
+	return content
+}
+
+// insideSpan reports whether offset falls inside one of the sorted, non-overlapping spans
+func insideSpan(spans [][]int, offset int) bool {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i][1] > offset })
+	return i < len(spans) && spans[i][0] <= offset
+}
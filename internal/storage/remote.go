@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RemoteSummaryVersion is the last version of a period summary exchanged with a remote worker:
+// pushed by the worker, or merged by the capture daemon
+type RemoteSummaryVersion struct {
+	PeriodKey   string    `db:"period_key"`
+	Hash        string    `db:"hash"`
+	GeneratedAt time.Time `db:"generated_at"`
+	Worker      string    `db:"worker"`
+}
+
+// RemoteStore tracks the analysis queue shared between a capture-only daemon and remote workers
+type RemoteStore interface {
+	// MarkScreenshotsExported records that screenshots were handed to a worker
+	MarkScreenshotsExported(ids []string, worker string, at time.Time) error
+	// GetExportedScreenshots returns the IDs of screenshots handed to a worker since the given time
+	GetExportedScreenshots(since time.Time) (map[string]bool, error)
+
+	// SaveRemoteImport records a screenshot imported by a worker from the capture daemon
+	SaveRemoteImport(id string, importedAt time.Time) error
+	// ListUnpushedImports returns the imported screenshots whose analysis wasn't pushed back yet
+	ListUnpushedImports() ([]string, error)
+	// MarkImportsPushed records that the analyses of imported screenshots were pushed back
+	MarkImportsPushed(ids []string, at time.Time) error
+
+	// GetRemoteSummaryVersions returns the last exchanged version of each period summary, keyed by period key
+	GetRemoteSummaryVersions() (map[string]*RemoteSummaryVersion, error)
+	SaveRemoteSummaryVersion(version *RemoteSummaryVersion) error
+}
+
+func (s *SQLiteStorage) initRemoteTables() error {
+	createRemoteTables := `
+	CREATE TABLE IF NOT EXISTS remote_exports (
+		screenshot_id TEXT PRIMARY KEY,
+		worker TEXT NOT NULL,
+		exported_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS remote_imports (
+		screenshot_id TEXT PRIMARY KEY,
+		imported_at DATETIME NOT NULL,
+		pushed_at DATETIME
+	);
+	CREATE TABLE IF NOT EXISTS remote_summaries (
+		period_key TEXT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		generated_at DATETIME NOT NULL,
+		worker TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_remote_exports_exported_at ON remote_exports(exported_at);
+	`
+	if _, err := s.db.Exec(createRemoteTables); err != nil {
+		return fmt.Errorf("failed to create remote tables: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) MarkScreenshotsExported(ids []string, worker string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT OR REPLACE INTO remote_exports (screenshot_id, worker, exported_at) VALUES (?, ?, ?)`
+	for _, id := range ids {
+		if _, err := tx.Exec(query, id, worker, at.Format(time.RFC3339Nano)); err != nil {
+			return fmt.Errorf("failed to mark screenshot exported: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetExportedScreenshots(since time.Time) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT screenshot_id FROM remote_exports WHERE exported_at >= ?`, since.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exported screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan exported screenshot: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStorage) SaveRemoteImport(id string, importedAt time.Time) error {
+	query := `INSERT OR IGNORE INTO remote_imports (screenshot_id, imported_at) VALUES (?, ?)`
+	if _, err := s.db.Exec(query, id, importedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save remote import: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) ListUnpushedImports() ([]string, error) {
+	rows, err := s.db.Query(`SELECT screenshot_id FROM remote_imports WHERE pushed_at IS NULL ORDER BY imported_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query remote imports: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan remote import: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStorage) MarkImportsPushed(ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{at.Format(time.RFC3339Nano)}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	query := `UPDATE remote_imports SET pushed_at = ? WHERE screenshot_id IN (` + placeholders + `)`
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to mark imports pushed: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetRemoteSummaryVersions() (map[string]*RemoteSummaryVersion, error) {
+	rows, err := s.db.Query(`SELECT period_key, hash, generated_at, COALESCE(worker, '') FROM remote_summaries`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query remote summary versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[string]*RemoteSummaryVersion)
+	for rows.Next() {
+		var v RemoteSummaryVersion
+		var generatedAtStr string
+		if err := rows.Scan(&v.PeriodKey, &v.Hash, &generatedAtStr, &v.Worker); err != nil {
+			return nil, fmt.Errorf("failed to scan remote summary version: %w", err)
+		}
+		if v.GeneratedAt, err = time.Parse(time.RFC3339Nano, generatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse generated_at: %w", err)
+		}
+		versions[v.PeriodKey] = &v
+	}
+	return versions, rows.Err()
+}
+
+func (s *SQLiteStorage) SaveRemoteSummaryVersion(version *RemoteSummaryVersion) error {
+	version.PeriodKey = NormalizePeriodKey(version.PeriodKey)
+	query := `INSERT OR REPLACE INTO remote_summaries (period_key, hash, generated_at, worker) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.Exec(query, version.PeriodKey, version.Hash, version.GeneratedAt.Format(time.RFC3339Nano), version.Worker); err != nil {
+		return fmt.Errorf("failed to save remote summary version: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) MarkScreenshotsExported(ids []string, worker string, at time.Time) error {
+	return r.metadataStorage.MarkScreenshotsExported(ids, worker, at)
+}
+
+func (r *ReportStorage) GetExportedScreenshots(since time.Time) (map[string]bool, error) {
+	return r.metadataStorage.GetExportedScreenshots(since)
+}
+
+func (r *ReportStorage) SaveRemoteImport(id string, importedAt time.Time) error {
+	return r.metadataStorage.SaveRemoteImport(id, importedAt)
+}
+
+func (r *ReportStorage) ListUnpushedImports() ([]string, error) {
+	return r.metadataStorage.ListUnpushedImports()
+}
+
+func (r *ReportStorage) MarkImportsPushed(ids []string, at time.Time) error {
+	return r.metadataStorage.MarkImportsPushed(ids, at)
+}
+
+func (r *ReportStorage) GetRemoteSummaryVersions() (map[string]*RemoteSummaryVersion, error) {
+	return r.metadataStorage.GetRemoteSummaryVersions()
+}
+
+func (r *ReportStorage) SaveRemoteSummaryVersion(version *RemoteSummaryVersion) error {
+	return r.metadataStorage.SaveRemoteSummaryVersion(version)
+}
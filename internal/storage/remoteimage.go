@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RemoteImageStore tracks which screenshot images have been uploaded to the object store,
+// so only uploaded images are evicted from the local cache and failed uploads are retried
+type RemoteImageStore interface {
+	MarkImageUploaded(imagePath, key string) error
+	IsImageUploaded(imagePath string) (bool, error)
+	// GetImagesNotUploaded returns image paths of screenshots not uploaded yet, newest first
+	GetImagesNotUploaded(limit int) ([]string, error)
+}
+
+func (s *SQLiteStorage) initRemoteImageTable() error {
+	createRemoteImageTable := `
+	CREATE TABLE IF NOT EXISTS remote_images (
+		image_path TEXT PRIMARY KEY,
+		object_key TEXT NOT NULL,
+		uploaded_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createRemoteImageTable); err != nil {
+		return fmt.Errorf("failed to create remote_images table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) MarkImageUploaded(imagePath, key string) error {
+	query := `
+	INSERT OR REPLACE INTO remote_images (image_path, object_key, uploaded_at)
+	VALUES (?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, imagePath, key, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to mark image uploaded: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) IsImageUploaded(imagePath string) (bool, error) {
+	var key string
+	err := s.db.QueryRow(`SELECT object_key FROM remote_images WHERE image_path = ?`, imagePath).Scan(&key)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check uploaded image: %w", err)
+	}
+	return true, nil
+}
+
+func (s *SQLiteStorage) GetImagesNotUploaded(limit int) ([]string, error) {
+	query := `
+	SELECT image_path FROM screenshots
+	WHERE image_path NOT IN (SELECT image_path FROM remote_images)
+	ORDER BY timestamp DESC
+	LIMIT ?
+	`
+	rows, err := s.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images not uploaded: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("failed to scan image path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
+func (r *ReportStorage) MarkImageUploaded(imagePath, key string) error {
+	return r.metadataStorage.MarkImageUploaded(imagePath, key)
+}
+
+func (r *ReportStorage) IsImageUploaded(imagePath string) (bool, error) {
+	return r.metadataStorage.IsImageUploaded(imagePath)
+}
+
+func (r *ReportStorage) GetImagesNotUploaded(limit int) ([]string, error) {
+	return r.metadataStorage.GetImagesNotUploaded(limit)
+}
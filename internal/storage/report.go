@@ -169,6 +169,14 @@ func (r *ReportStorage) UpdateScreenshotAnalysis(id, analysis string) error {
 	return r.metadataStorage.UpdateScreenshotAnalysis(id, analysis)
 }
 
+func (r *ReportStorage) UpdateScreenshotAnalysisStatus(id, status, analysisError string) error {
+	return r.metadataStorage.UpdateScreenshotAnalysisStatus(id, status, analysisError)
+}
+
+func (r *ReportStorage) UpdateScreenshotAnalyses(updates []*ScreenshotAnalysisUpdate) error {
+	return r.metadataStorage.UpdateScreenshotAnalyses(updates)
+}
+
 func (r *ReportStorage) GetScreenshotsByHourKey(hourKey string) ([]*ScreenshotRecord, error) {
 	return r.metadataStorage.GetScreenshotsByHourKey(hourKey)
 }
@@ -201,6 +209,10 @@ func (r *ReportStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord
 	return r.metadataStorage.GetUnanalyzedScreenshots(limit)
 }
 
+func (r *ReportStorage) GetLatestUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error) {
+	return r.metadataStorage.GetLatestUnanalyzedScreenshots(limit)
+}
+
 func (r *ReportStorage) CleanupOldRecords(retentionDays int) error {
 	// Cleanup both storage systems
 	if err := r.metadataStorage.CleanupOldRecords(retentionDays); err != nil {
@@ -0,0 +1,453 @@
+package storage
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report archives: fifteenmin and hour reports of old months are compacted into one tar file per
+// month directory (YYYY/QN/MM), with an index recording where each report is in the tar file, so a
+// report is read with a single seek and backups and file browsers deal with two files per month
+// instead of thousands. Loose report files take precedence over archived ones
+
+// ReportArchiveIndexFile is the name of the index in a compacted month directory
+const ReportArchiveIndexFile = "reports-archive.json"
+
+// reportArchiveVersion is the version of the index format
+const reportArchiveVersion = 1
+
+// compactFilePattern matches the report files that are compacted
+var compactFilePattern = regexp.MustCompile(`^(hour|fifteenmin-\d{2})\.md$`)
+
+// monthDirPattern matches month directories relative to the reports directory (YYYY/QN/MM or the
+// legacy YYYY/MM)
+var monthDirPattern = regexp.MustCompile(`^(\d{4})/(?:Q\d/)?(\d{2})$`)
+
+// ReportArchiveEntry is one report in a month archive
+type ReportArchiveEntry struct {
+	Path       string    `json:"path"`        // Slash-separated path relative to the month directory
+	PeriodType string    `json:"period_type"` // hour or fifteenmin
+	Offset     int64     `json:"offset"`      // Offset of the report content in the archive file
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"` // Modification time of the report file when it was archived
+}
+
+// ReportArchiveIndex is the index of a month archive, stored as ReportArchiveIndexFile
+type ReportArchiveIndex struct {
+	Version     int                  `json:"version"`
+	Archive     string               `json:"archive"`    // Name of the tar file in the month directory
+	Generation  int                  `json:"generation"` // Incremented each time the tar file is rewritten
+	CompactedAt time.Time            `json:"compacted_at"`
+	Entries     []ReportArchiveEntry `json:"entries"` // Sorted by path
+}
+
+// ReportArchive is the archive of one month directory
+type ReportArchive struct {
+	Dir   string
+	Index ReportArchiveIndex
+
+	byPath map[string]int
+}
+
+// CompactResult summarizes a compaction
+type CompactResult struct {
+	Months  int   // Month directories compacted
+	Reports int   // Report files moved into archives
+	Bytes   int64 // Size of the report files moved
+}
+
+type cachedArchive struct {
+	modTime time.Time
+	size    int64
+	archive *ReportArchive
+}
+
+// archiveCache keeps parsed indexes, so looking up many archived reports reads each index once
+var archiveCache = struct {
+	sync.Mutex
+	entries map[string]cachedArchive
+}{entries: make(map[string]cachedArchive)}
+
+// OpenReportArchive loads the archive of a month directory, nil if the directory isn't compacted
+func OpenReportArchive(dir string) (*ReportArchive, error) {
+	indexPath := filepath.Join(dir, ReportArchiveIndexFile)
+	info, err := os.Stat(indexPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat report archive index: %w", err)
+	}
+
+	archiveCache.Lock()
+	defer archiveCache.Unlock()
+	if cached, ok := archiveCache.entries[indexPath]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.archive, nil
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report archive index: %w", err)
+	}
+	archive := &ReportArchive{Dir: dir}
+	if err := json.Unmarshal(data, &archive.Index); err != nil {
+		return nil, fmt.Errorf("failed to parse report archive index %s: %w", indexPath, err)
+	}
+	if archive.Index.Version > reportArchiveVersion {
+		return nil, fmt.Errorf("report archive index %s has unsupported version %d", indexPath, archive.Index.Version)
+	}
+	archive.byPath = make(map[string]int, len(archive.Index.Entries))
+	for i, e := range archive.Index.Entries {
+		archive.byPath[e.Path] = i
+	}
+	archiveCache.entries[indexPath] = cachedArchive{modTime: info.ModTime(), size: info.Size(), archive: archive}
+	return archive, nil
+}
+
+// Entry returns the entry of a report, rel being slash-separated and relative to the month directory
+func (a *ReportArchive) Entry(rel string) (ReportArchiveEntry, bool) {
+	i, ok := a.byPath[rel]
+	if !ok {
+		return ReportArchiveEntry{}, false
+	}
+	return a.Index.Entries[i], true
+}
+
+// Read returns the content of an archived report
+func (a *ReportArchive) Read(entry ReportArchiveEntry) ([]byte, error) {
+	f, err := os.Open(filepath.Join(a.Dir, a.Index.Archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report archive: %w", err)
+	}
+	defer f.Close()
+
+	content := make([]byte, entry.Size)
+	if _, err := f.ReadAt(content, entry.Offset); err != nil {
+		return nil, fmt.Errorf("failed to read %s from report archive: %w", entry.Path, err)
+	}
+	return content, nil
+}
+
+// findArchivedReport returns the archive holding the report that was at reportPath
+func findArchivedReport(reportsPath, reportPath string) (*ReportArchive, ReportArchiveEntry, bool) {
+	reportsPath = filepath.Clean(reportsPath)
+	for dir := filepath.Dir(reportPath); within(dir, reportsPath) && dir != reportsPath; dir = filepath.Dir(dir) {
+		archive, err := OpenReportArchive(dir)
+		if err != nil || archive == nil {
+			continue
+		}
+		rel, err := filepath.Rel(dir, reportPath)
+		if err != nil {
+			return nil, ReportArchiveEntry{}, false
+		}
+		entry, ok := archive.Entry(filepath.ToSlash(rel))
+		return archive, entry, ok
+	}
+	return nil, ReportArchiveEntry{}, false
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadArchivedReport returns the content of a compacted report by its original path; the error
+// wraps os.ErrNotExist if the report isn't archived
+func ReadArchivedReport(reportsPath, reportPath string) ([]byte, error) {
+	archive, entry, ok := findArchivedReport(reportsPath, reportPath)
+	if !ok {
+		return nil, fmt.Errorf("report %s is not archived: %w", reportPath, os.ErrNotExist)
+	}
+	return archive.Read(entry)
+}
+
+// ReportExists reports whether a report exists as a file or in a month archive
+func ReportExists(reportsPath, reportPath string) bool {
+	if _, err := os.Stat(reportPath); err == nil {
+		return true
+	}
+	_, _, ok := findArchivedReport(reportsPath, reportPath)
+	return ok
+}
+
+// RemoveArchivedReport removes a compacted report from its month archive (the archive file is
+// rewritten, so the content is gone); nothing happens if the report isn't archived
+func RemoveArchivedReport(reportsPath, reportPath string) error {
+	if IsReadOnly() {
+		return ErrReadOnly
+	}
+	archive, entry, ok := findArchivedReport(reportsPath, reportPath)
+	if !ok {
+		return nil
+	}
+	return writeReportArchive(archive.Dir, archive, nil, map[string]bool{entry.Path: true})
+}
+
+// CompactReports moves the fifteenmin and hour report files of months ending by before into one
+// archive per month directory; months compacted before are merged with their new files
+// With dryRun nothing is changed and the result tells what would be compacted
+func CompactReports(reportsPath string, before time.Time, dryRun bool) (*CompactResult, error) {
+	if IsReadOnly() && !dryRun {
+		return nil, ErrReadOnly
+	}
+	result := &CompactResult{}
+	months, err := listMonthDirs(reportsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir, month := range months {
+		if month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+		files, size, err := listCompactFiles(dir)
+		if err != nil {
+			return result, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if !dryRun {
+			archive, err := OpenReportArchive(dir)
+			if err != nil {
+				return result, err
+			}
+			if err := writeReportArchive(dir, archive, files, nil); err != nil {
+				return result, fmt.Errorf("failed to compact %s: %w", dir, err)
+			}
+			if err := removeCompactedFiles(dir, files); err != nil {
+				return result, err
+			}
+		}
+		result.Months++
+		result.Reports += len(files)
+		result.Bytes += size
+	}
+	return result, nil
+}
+
+// listMonthDirs returns the month directories of the reports tree with the month they stand for
+func listMonthDirs(reportsPath string) (map[string]time.Time, error) {
+	months := make(map[string]time.Time)
+	for _, pattern := range []string{"[0-9][0-9][0-9][0-9]/Q[0-9]/[0-9][0-9]", "[0-9][0-9][0-9][0-9]/[0-9][0-9]"} {
+		matches, err := filepath.Glob(filepath.Join(reportsPath, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list month directories: %w", err)
+		}
+		for _, dir := range matches {
+			rel, err := filepath.Rel(reportsPath, dir)
+			if err != nil {
+				continue
+			}
+			m := monthDirPattern.FindStringSubmatch(filepath.ToSlash(rel))
+			if m == nil {
+				continue
+			}
+			month, err := time.ParseInLocation("2006-01", m[1]+"-"+m[2], time.Local)
+			if err != nil {
+				continue
+			}
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				months[dir] = month
+			}
+		}
+	}
+	return months, nil
+}
+
+// listCompactFiles returns the loose report files to compact under a month directory, keyed by
+// their slash-separated path relative to it, and their total size
+func listCompactFiles(dir string) (map[string]string, int64, error) {
+	files := make(map[string]string)
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !compactFilePattern.MatchString(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = path
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reports under %s: %w", dir, err)
+	}
+	return files, size, nil
+}
+
+// countingWriter counts the bytes written, giving the offset of each report in the tar file
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// writeReportArchive writes a new archive file for a month directory with the reports of the
+// current archive (may be nil), minus drop, plus the files in add (which replace archived reports
+// of the same path), then switches the index over to it and removes the old archive file
+// The index is replaced by a rename, so readers see either the old or the new archive
+func writeReportArchive(dir string, current *ReportArchive, add map[string]string, drop map[string]bool) error {
+	type source struct {
+		entry ReportArchiveEntry
+		path  string // Loose file, empty to read from the current archive
+	}
+	var sources []source
+	generation := 1
+	if current != nil {
+		generation = current.Index.Generation + 1
+		for _, e := range current.Index.Entries {
+			if _, replaced := add[e.Path]; !replaced && !drop[e.Path] {
+				sources = append(sources, source{entry: e})
+			}
+		}
+	}
+	for rel, path := range add {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat report: %w", err)
+		}
+		periodType := "hour"
+		if strings.HasPrefix(filepath.Base(path), "fifteenmin-") {
+			periodType = "fifteenmin"
+		}
+		sources = append(sources, source{entry: ReportArchiveEntry{Path: rel, PeriodType: periodType, ModTime: info.ModTime()}, path: path})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].entry.Path < sources[j].entry.Path })
+
+	indexPath := filepath.Join(dir, ReportArchiveIndexFile)
+	if len(sources) == 0 {
+		if err := os.Remove(indexPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove report archive index: %w", err)
+		}
+		if current != nil {
+			os.Remove(filepath.Join(dir, current.Index.Archive))
+		}
+		return nil
+	}
+
+	name := fmt.Sprintf("reports-archive-%d.tar", generation)
+	tmpPath := filepath.Join(dir, name+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create report archive: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	counter := &countingWriter{w: f}
+	tw := tar.NewWriter(counter)
+	index := ReportArchiveIndex{Version: reportArchiveVersion, Archive: name, Generation: generation, CompactedAt: time.Now()}
+	for _, src := range sources {
+		var content []byte
+		if src.path != "" {
+			content, err = os.ReadFile(src.path)
+		} else {
+			content, err = current.Read(src.entry)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     src.entry.Path,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  src.entry.ModTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write report archive: %w", err)
+		}
+		entry := src.entry
+		entry.Offset = counter.n
+		entry.Size = int64(len(content))
+		if _, err := tw.Write(content); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write report archive: %w", err)
+		}
+		index.Entries = append(index.Entries, entry)
+	}
+	if err := tw.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report archive: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync report archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close report archive: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("failed to save report archive: %w", err)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report archive index: %w", err)
+	}
+	tmpIndexPath := indexPath + ".tmp"
+	if err := os.WriteFile(tmpIndexPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report archive index: %w", err)
+	}
+	if err := os.Rename(tmpIndexPath, indexPath); err != nil {
+		os.Remove(tmpIndexPath)
+		return fmt.Errorf("failed to save report archive index: %w", err)
+	}
+
+	if current != nil && current.Index.Archive != name {
+		os.Remove(filepath.Join(dir, current.Index.Archive))
+	}
+	return nil
+}
+
+// removeCompactedFiles removes archived report files and the directories left empty by them
+func removeCompactedFiles(dir string, files map[string]string) error {
+	parents := make(map[string]bool)
+	for _, path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove compacted report %s: %w", path, err)
+		}
+		parents[filepath.Dir(path)] = true
+	}
+
+	// Deepest first, so emptied parents are removed too
+	var dirs []string
+	for parent := range parents {
+		for d := parent; d != dir && within(d, dir); d = filepath.Dir(d) {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, d := range dirs {
+		os.Remove(d) // Fails for directories that still hold other reports
+	}
+	return nil
+}
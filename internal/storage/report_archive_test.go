@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompactReports(t *testing.T) {
+	reports := t.TempDir()
+	fs, err := NewFileSystemStorage(reports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(periodType, periodKey string, start time.Time, duration time.Duration, summary string) string {
+		t.Helper()
+		s := &PeriodSummary{PeriodKey: periodKey, PeriodType: periodType, StartTime: start, EndTime: start.Add(duration), Summary: summary}
+		if err := fs.SavePeriodSummary(s); err != nil {
+			t.Fatal(err)
+		}
+		path, err := fs.calculateReportPath(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	hour := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	hourPath := save("hour", "2025-12-09-14", hour, time.Hour, "调试测试")
+	fifteenPath := save("fifteenmin", "2025-12-09-14-15", hour.Add(15*time.Minute), 15*time.Minute, "编写代码")
+	dayPath := save("day", "2025-12-09", time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local), 24*time.Hour, "迁移")
+	recentPath := save("hour", "2026-02-03-10", time.Date(2026, 2, 3, 10, 0, 0, 0, time.Local), time.Hour, "近期")
+
+	result, err := CompactReports(reports, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), false)
+	if err != nil {
+		t.Fatalf("CompactReports() error = %v", err)
+	}
+	if result.Months != 1 || result.Reports != 2 {
+		t.Errorf("CompactReports() = %+v, want 2 reports of 1 month", result)
+	}
+	for path, want := range map[string]bool{hourPath: false, fifteenPath: false, dayPath: true, recentPath: true} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("file %s exists = %v, want %v", path, err == nil, want)
+		}
+		if !ReportExists(reports, path) {
+			t.Errorf("ReportExists(%s) = false", path)
+		}
+	}
+
+	// Archived reports are read through the index
+	summary, err := fs.GetPeriodSummary("2025-12-09-14")
+	if err != nil || summary == nil || summary.Summary != "调试测试" {
+		t.Errorf("GetPeriodSummary() = %+v, %v, want the archived hour report", summary, err)
+	}
+
+	// A later run merges new report files into the archive
+	laterPath := save("hour", "2025-12-09-15", hour.Add(time.Hour), time.Hour, "代码评审")
+	if _, err := CompactReports(reports, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), false); err != nil {
+		t.Fatalf("CompactReports() error = %v", err)
+	}
+	for path, want := range map[string]string{hourPath: "调试测试", laterPath: "代码评审"} {
+		content, err := ReadArchivedReport(reports, path)
+		if err != nil {
+			t.Fatalf("ReadArchivedReport(%s) error = %v", path, err)
+		}
+		parsed, err := parsePeriodReportContent(path, content)
+		if err != nil || parsed.Summary != want {
+			t.Errorf("archived report %s = %+v, %v, want summary %q", path, parsed, err, want)
+		}
+	}
+	archives, _ := filepath.Glob(filepath.Join(filepath.Dir(filepath.Dir(filepath.Dir(filepath.Dir(hourPath)))), "reports-archive-*.tar"))
+	if len(archives) != 1 {
+		t.Errorf("archive files = %v, want one per month", archives)
+	}
+
+	// Deleting a summary removes it from the archive
+	if err := fs.DeletePeriodSummary("2025-12-09-14"); err != nil {
+		t.Fatalf("DeletePeriodSummary() error = %v", err)
+	}
+	if ReportExists(reports, hourPath) {
+		t.Errorf("deleted report is still archived")
+	}
+	if !ReportExists(reports, laterPath) {
+		t.Errorf("other archived reports should be kept")
+	}
+}
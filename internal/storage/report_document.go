@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+)
+
+// ReportDocument is the structure of a generated markdown report (period summary or screenshot report),
+// served as JSON by the API and read by the report parser, so consumers do not re-parse markdown
+type ReportDocument struct {
+	Title       string            `json:"title"`
+	Metadata    []*ReportField    `json:"metadata"`            // **key**: value lines between the title and the first section
+	Sections    []*ReportSection  `json:"sections"`            // Sections in report order, nested by heading level
+	Citations   []*ReportCitation `json:"citations,omitempty"` // Entries of the 引用来源 section
+	GeneratedAt time.Time         `json:"generated_at,omitempty"`
+}
+
+// ReportField is a metadata line of a report
+type ReportField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ReportSection is a headed section of a report
+type ReportSection struct {
+	Heading  string           `json:"heading"`
+	Body     string           `json:"body"` // Markdown up to the first subsection, without surrounding blank lines and rules
+	Sections []*ReportSection `json:"sections,omitempty"`
+}
+
+// ReportCitation is a numbered screenshot source of a report
+type ReportCitation struct {
+	Index        int       `json:"index"`
+	Time         time.Time `json:"time"`
+	ScreenshotID string    `json:"screenshot_id"`
+	ImagePath    string    `json:"image_path"`
+}
+
+// CitationsHeading is the heading of the screenshot sources section of cited reports
+const CitationsHeading = "引用来源"
+
+var (
+	metadataPattern = regexp.MustCompile(`^\*\*([^*]+)\*\*:\s*(.*)$`)
+	citationPattern = regexp.MustCompile("^\\[(\\d+)\\] (.+?) · `([^`]+)` · (.*)$")
+)
+
+// ParseReportDocument parses a report into its title, metadata, sections and citations
+// Reports written with storage.markdown options (shifted headings, no rules, no metadata) are
+// supported: the first heading is the title and deeper headings open sections relative to it.
+// Headings and rules inside fenced code blocks are part of the section body
+func ParseReportDocument(content string) *ReportDocument {
+	doc := &ReportDocument{}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	titleLevel := 0
+	var open []*ReportSection // Open sections, outermost first
+	var body []string
+	flush := func() {
+		text := trimSectionBody(body)
+		body = nil
+		if len(open) == 0 {
+			doc.Metadata = append(doc.Metadata, parseMetadata(text)...)
+			return
+		}
+		open[len(open)-1].Body = text
+	}
+
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		} else if !inFence {
+			if level, heading := headingText(line); level > 0 {
+				if titleLevel == 0 {
+					doc.Title, titleLevel = heading, level
+					continue
+				}
+				flush()
+				depth := min(max(level-titleLevel, 1), len(open)+1)
+				open = open[:depth-1]
+				section := &ReportSection{Heading: heading}
+				if depth == 1 {
+					doc.Sections = append(doc.Sections, section)
+				} else {
+					parent := open[depth-2]
+					parent.Sections = append(parent.Sections, section)
+				}
+				open = append(open, section)
+				continue
+			}
+			if strings.HasPrefix(trimmed, reportFooterPrefix) {
+				value := strings.TrimSuffix(strings.TrimPrefix(trimmed, reportFooterPrefix), "*")
+				if t, err := datefmt.Parse(strings.TrimSpace(value)); err == nil {
+					doc.GeneratedAt = t
+				}
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	if section := doc.Section(CitationsHeading); section != nil {
+		doc.Citations = parseCitations(section.Body)
+	}
+	return doc
+}
+
+// trimSectionBody joins body lines without the blank lines and rules around them
+func trimSectionBody(lines []string) string {
+	blank := func(line string) bool {
+		trimmed := strings.TrimSpace(line)
+		return trimmed == "" || trimmed == "---"
+	}
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseMetadata(text string) []*ReportField {
+	var fields []*ReportField
+	for _, line := range strings.Split(text, "\n") {
+		if m := metadataPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			fields = append(fields, &ReportField{Key: strings.TrimSpace(m[1]), Value: strings.TrimSpace(m[2])})
+		}
+	}
+	return fields
+}
+
+func parseCitations(text string) []*ReportCitation {
+	var citations []*ReportCitation
+	for _, line := range strings.Split(text, "\n") {
+		m := citationPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		citation := &ReportCitation{Index: index, ScreenshotID: m[3], ImagePath: m[4]}
+		if t, err := datefmt.Parse(m[2]); err == nil {
+			citation.Time = t
+		}
+		citations = append(citations, citation)
+	}
+	return citations
+}
+
+// Field returns the value of a metadata field, empty when the report does not have it
+func (d *ReportDocument) Field(key string) string {
+	for _, f := range d.Metadata {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Section returns the first top-level section with the heading, nil when there is none
+func (d *ReportDocument) Section(heading string) *ReportSection {
+	for _, s := range d.Sections {
+		if s.Heading == heading {
+			return s
+		}
+	}
+	return nil
+}
+
+// Text returns the section body followed by its subsections, as markdown
+func (s *ReportSection) Text() string {
+	var sb strings.Builder
+	sb.WriteString(s.Body)
+	for _, sub := range s.Sections {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sub.write(&sb, 3)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Markdown renders the document in the default report format; for reports written in that format,
+// Markdown(ParseReportDocument(report)) returns the report unchanged
+func (d *ReportDocument) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", d.Title))
+	for _, f := range d.Metadata {
+		sb.WriteString(fmt.Sprintf("**%s**: %s\n\n", f.Key, f.Value))
+	}
+	for _, s := range d.Sections {
+		sb.WriteString("---\n\n")
+		s.write(&sb, 2)
+	}
+	if !d.GeneratedAt.IsZero() {
+		sb.WriteString("---\n\n")
+		sb.WriteString(fmt.Sprintf("%s %s*\n", reportFooterPrefix, datefmt.DateTime(d.GeneratedAt)))
+	}
+	return sb.String()
+}
+
+func (s *ReportSection) write(sb *strings.Builder, level int) {
+	sb.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", min(level, 6)), s.Heading))
+	if s.Body != "" {
+		sb.WriteString(s.Body)
+		sb.WriteString("\n\n")
+	}
+	for _, sub := range s.Sections {
+		sub.write(sb, level+1)
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"stuff-time/internal/config"
+)
+
+const testCitedReport = "# 日周期总结报告\n\n" +
+	"**周期类型**: day\n\n" +
+	"**开始时间**: 2025-12-09 00:00:00\n\n" +
+	"**结束时间**: 2025-12-10 00:00:00\n\n" +
+	"**截图数量**: 2\n\n" +
+	"---\n\n" +
+	"## 事实总结\n\n" +
+	"评审 PR [1]，修复登录问题 [2]\n\n" +
+	"---\n\n" +
+	"## 会议纪要\n\n" +
+	"### 周会\n\n" +
+	"- 确认发布时间\n\n" +
+	"### 评审会\n\n" +
+	"通过方案\n\n" +
+	"---\n\n" +
+	"## 每日反思\n\n" +
+	"**今天最有价值的事？**\n\n" +
+	"修复登录问题\n\n" +
+	"---\n\n" +
+	"## 引用来源\n\n" +
+	"[1] 2025-12-09 10:02:00 · `a1b2c3` · /data/shots/10-02.png\n" +
+	"[2] 2025-12-09 15:40:00 · `d4e5f6` · /data/shots/15-40.png\n\n" +
+	"---\n\n" +
+	"*报告生成时间: 2025-12-10 09:00:00*\n"
+
+func TestReportDocumentRoundTrip(t *testing.T) {
+	for name, report := range map[string]string{"period": testPeriodReport, "cited": testCitedReport} {
+		t.Run(name, func(t *testing.T) {
+			doc := ParseReportDocument(report)
+			if got := doc.Markdown(); got != report {
+				t.Errorf("Markdown() =\n%s\nwant:\n%s", got, report)
+			}
+
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded ReportDocument
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := decoded.Markdown(); got != report {
+				t.Errorf("Markdown() after JSON =\n%s\nwant:\n%s", got, report)
+			}
+		})
+	}
+}
+
+func TestParseReportDocument(t *testing.T) {
+	doc := ParseReportDocument(testCitedReport)
+	if doc.Title != "日周期总结报告" || doc.Field("截图数量") != "2" || len(doc.Metadata) != 4 {
+		t.Errorf("title = %q, metadata = %+v", doc.Title, doc.Metadata)
+	}
+	meetings := doc.Section("会议纪要")
+	if meetings == nil || len(meetings.Sections) != 2 || meetings.Sections[1].Body != "通过方案" {
+		t.Fatalf("会议纪要 = %+v", meetings)
+	}
+	if doc.Section("每日反思").Body != "**今天最有价值的事？**\n\n修复登录问题" {
+		t.Errorf("每日反思 body = %q", doc.Section("每日反思").Body)
+	}
+	if len(doc.Citations) != 2 || doc.Citations[1].ScreenshotID != "d4e5f6" || doc.Citations[1].Time.Hour() != 15 {
+		t.Errorf("citations = %+v", doc.Citations)
+	}
+	if doc.GeneratedAt.IsZero() {
+		t.Errorf("generation time not parsed")
+	}
+
+	// Shifted headings without rules parse to the same structure
+	formatted := ParseReportDocument(FormatMarkdown(testCitedReport, config.MarkdownConfig{HeadingLevel: 3, OmitHorizontalRules: true}))
+	if formatted.Markdown() != testCitedReport {
+		t.Errorf("formatted report Markdown() =\n%s", formatted.Markdown())
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ReportHashStore stores the content hash of generated report files, so manual edits made after
+// generation can be detected before a report is regenerated
+type ReportHashStore interface {
+	SaveReportHash(reportPath, hash string) error
+	// GetReportHash returns "" if the report was generated before hashes were recorded
+	GetReportHash(reportPath string) (string, error)
+}
+
+func (s *SQLiteStorage) initReportHashTable() error {
+	createReportHashTable := `
+	CREATE TABLE IF NOT EXISTS report_hashes (
+		report_path TEXT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		generated_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createReportHashTable); err != nil {
+		return fmt.Errorf("failed to create report_hashes table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveReportHash(reportPath, hash string) error {
+	query := `INSERT OR REPLACE INTO report_hashes (report_path, hash, generated_at) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(query, reportPath, hash, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save report hash: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetReportHash(reportPath string) (string, error) {
+	var hash string
+	err := s.db.QueryRow(`SELECT hash FROM report_hashes WHERE report_path = ?`, reportPath).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get report hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (r *ReportStorage) SaveReportHash(reportPath, hash string) error {
+	return r.metadataStorage.SaveReportHash(reportPath, hash)
+}
+
+func (r *ReportStorage) GetReportHash(reportPath string) (string, error) {
+	return r.metadataStorage.GetReportHash(reportPath)
+}
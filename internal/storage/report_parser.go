@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"stuff-time/internal/datefmt"
 )
 
 // ReportParser parses markdown report files
@@ -56,93 +58,37 @@ func (p *ReportParser) ParsePeriodReport(filePath string) (*ParsedReport, error)
 		return nil, fmt.Errorf("failed to read report file: %w", err)
 	}
 
-	report := &ParsedReport{}
-	lines := strings.Split(string(content), "\n")
-
-	var inSummary bool
-	var inAnalysis bool
-	var summaryLines []string
-	var analysisLines []string
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Parse period type
-		if strings.HasPrefix(line, "**周期类型**:") {
-			report.PeriodType = strings.TrimSpace(strings.TrimPrefix(line, "**周期类型**:"))
-		}
-
-		// Parse start time
-		if strings.HasPrefix(line, "**开始时间**:") {
-			timeStr := strings.TrimSpace(strings.TrimPrefix(line, "**开始时间**:"))
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-				report.StartTime = t
-			}
-		}
-
-		// Parse end time
-		if strings.HasPrefix(line, "**结束时间**:") {
-			timeStr := strings.TrimSpace(strings.TrimPrefix(line, "**结束时间**:"))
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-				report.EndTime = t
-			}
-		}
-
-		// Parse screenshot count
-		if strings.HasPrefix(line, "**截图数量**:") {
-			countStr := strings.TrimSpace(strings.TrimPrefix(line, "**截图数量**:"))
-			if count, err := strconv.Atoi(countStr); err == nil {
-				report.ScreenshotCount = count
-			}
-		}
-
-		// Parse summary section
-		if line == "## 事实总结" {
-			inSummary = true
-			inAnalysis = false
-			continue
-		}
-
-		// Parse analysis section
-		if line == "## 改进建议" {
-			inSummary = false
-			inAnalysis = true
-			continue
-		}
-
-		// Stop at separator or next section
-		if line == "---" && (inSummary || inAnalysis) {
-			if i+1 < len(lines) {
-				nextLine := strings.TrimSpace(lines[i+1])
-				if strings.HasPrefix(nextLine, "##") {
-					inSummary = false
-					inAnalysis = false
-					continue
-				}
-			}
-		}
+	report, err := parsePeriodReportContent(filePath, content)
+	if err != nil {
+		return nil, err
+	}
 
-		// Collect summary content
-		if inSummary && line != "" && !strings.HasPrefix(line, "---") {
-			summaryLines = append(summaryLines, line)
-		}
+	// Cache the result with write lock
+	p.mu.Lock()
+	p.cache[filePath] = report
+	p.mu.Unlock()
 
-		// Collect analysis content
-		if inAnalysis && line != "" && !strings.HasPrefix(line, "---") {
-			analysisLines = append(analysisLines, line)
-		}
+	return report, nil
+}
 
-		// Parse report generation time
-		if strings.HasPrefix(line, "*报告生成时间:") {
-			timeStr := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "*报告生成时间:"), "*"))
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-				report.Timestamp = t
-			}
-		}
+// parsePeriodReportContent parses the content of a period summary report; filePath names the report in errors
+func parsePeriodReportContent(filePath string, content []byte) (*ParsedReport, error) {
+	doc := ParseReportDocument(string(content))
+	report := &ParsedReport{
+		PeriodType: doc.Field("周期类型"),
+		Summary:    sectionLines(doc.Section("事实总结")),
+		Analysis:   sectionLines(doc.Section("改进建议")),
+		Timestamp:  doc.GeneratedAt,
+	}
+	if t, err := datefmt.Parse(doc.Field("开始时间")); err == nil {
+		report.StartTime = t
+	}
+	if t, err := datefmt.Parse(doc.Field("结束时间")); err == nil {
+		report.EndTime = t
+	}
+	if count, err := strconv.Atoi(doc.Field("截图数量")); err == nil {
+		report.ScreenshotCount = count
 	}
-
-	report.Summary = strings.Join(summaryLines, "\n")
-	report.Analysis = strings.Join(analysisLines, "\n")
 
 	// Validate required fields
 	if report.PeriodType == "" {
@@ -155,14 +101,23 @@ func (p *ReportParser) ParsePeriodReport(filePath string) (*ParsedReport, error)
 		return nil, fmt.Errorf("missing or invalid end time in report: %s", filePath)
 	}
 
-	// Cache the result with write lock
-	p.mu.Lock()
-	p.cache[filePath] = report
-	p.mu.Unlock()
-
 	return report, nil
 }
 
+// sectionLines returns the non-empty lines of a section and its subsections, trimmed and without rules
+func sectionLines(section *ReportSection) string {
+	if section == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(section.Text(), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "---") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ParseScreenshotReport parses a screenshot-level report file (MM.md)
 func (p *ReportParser) ParseScreenshotReport(filePath string) (*ParsedReport, error) {
 	// Check cache first with read lock
@@ -178,77 +133,25 @@ func (p *ReportParser) ParseScreenshotReport(filePath string) (*ParsedReport, er
 		return nil, fmt.Errorf("failed to read report file: %w", err)
 	}
 
-	report := &ParsedReport{}
-	lines := strings.Split(string(content), "\n")
-
-	var inSummary bool
-	var summaryLines []string
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Parse timestamp
-		if strings.HasPrefix(line, "**时间**:") {
-			timeStr := strings.TrimSpace(strings.TrimPrefix(line, "**时间**:"))
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-				report.StartTime = t
-				report.EndTime = t
-				report.Timestamp = t
-				report.HourKey = t.Format("2006-01-02-15")
-			}
-		}
-
-		// Parse screenshot ID
-		if strings.HasPrefix(line, "**截图ID**:") {
-			report.ScreenshotID = strings.TrimSpace(strings.TrimPrefix(line, "**截图ID**:"))
-		}
-
-		// Parse image path
-		if strings.HasPrefix(line, "**截图路径**:") {
-			report.ImagePath = strings.TrimSpace(strings.TrimPrefix(line, "**截图路径**:"))
-		}
-
-		// Parse screen ID
-		if strings.HasPrefix(line, "**屏幕ID**:") {
-			screenIDStr := strings.TrimSpace(strings.TrimPrefix(line, "**屏幕ID**:"))
-			if id, err := strconv.Atoi(screenIDStr); err == nil {
-				report.ScreenID = id
-			}
-		}
-
-		// Parse summary section
-		if line == "## 事实总结" {
-			inSummary = true
-			continue
-		}
-
-		// Stop at separator
-		if line == "---" && inSummary {
-			if i+1 < len(lines) {
-				nextLine := strings.TrimSpace(lines[i+1])
-				if strings.HasPrefix(nextLine, "*") {
-					inSummary = false
-					continue
-				}
-			}
-		}
-
-		// Collect summary content
-		if inSummary && line != "" && !strings.HasPrefix(line, "---") {
-			summaryLines = append(summaryLines, line)
-		}
-
-		// Parse report generation time
-		if strings.HasPrefix(line, "*报告生成时间:") {
-			timeStr := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "*报告生成时间:"), "*"))
-			if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-				report.Timestamp = t
-			}
-		}
+	doc := ParseReportDocument(string(content))
+	report := &ParsedReport{
+		ScreenshotID:    doc.Field("截图ID"),
+		ImagePath:       doc.Field("截图路径"),
+		Summary:         sectionLines(doc.Section("事实总结")),
+		ScreenshotCount: 1,
+	}
+	if t, err := datefmt.Parse(doc.Field("时间")); err == nil {
+		report.StartTime = t
+		report.EndTime = t
+		report.Timestamp = t
+		report.HourKey = t.Format("2006-01-02-15")
+	}
+	if id, err := strconv.Atoi(doc.Field("屏幕ID")); err == nil {
+		report.ScreenID = id
+	}
+	if !doc.GeneratedAt.IsZero() {
+		report.Timestamp = doc.GeneratedAt
 	}
-
-	report.Summary = strings.Join(summaryLines, "\n")
-	report.ScreenshotCount = 1
 
 	// Cache the result with write lock
 	p.mu.Lock()
@@ -258,6 +161,18 @@ func (p *ReportParser) ParseScreenshotReport(filePath string) (*ParsedReport, er
 	return report, nil
 }
 
+// ParseDocument parses a report file, or its copy in a month archive, into its structure
+func (p *ReportParser) ParseDocument(filePath string) (*ReportDocument, error) {
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		content, err = ReadArchivedReport(p.reportsPath, filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+	return ParseReportDocument(string(content)), nil
+}
+
 // ParseReportFile automatically detects report type and parses it
 func (p *ReportParser) ParseReportFile(filePath string) (*ParsedReport, error) {
 	// Check if it's a screenshot-level report (MM.md format)
@@ -275,6 +190,15 @@ func (p *ReportParser) ParseReportFile(filePath string) (*ParsedReport, error) {
 
 // ExtractPeriodKeyFromPath extracts period key from file path
 func ExtractPeriodKeyFromPath(filePath string, periodType string) (string, error) {
+	periodKey, err := extractLegacyPeriodKeyFromPath(filePath, periodType)
+	if err != nil {
+		return "", err
+	}
+	return normalizePeriodKeyOfType(periodKey, periodType), nil
+}
+
+// extractLegacyPeriodKeyFromPath extracts the version 1 period key from file path
+func extractLegacyPeriodKeyFromPath(filePath string, periodType string) (string, error) {
 	relPath, err := filepath.Rel("", filePath)
 	if err != nil {
 		return "", err
@@ -357,42 +281,23 @@ func ExtractPeriodKeyFromPath(filePath string, periodType string) (string, error
 // ValidatePeriodKeyFromStartTime validates that a period_key matches the start_time
 // This ensures consistency between file path and file content
 func ValidatePeriodKeyFromStartTime(periodKey string, periodType string, startTime time.Time) error {
-	var expectedKey string
-
-	switch periodType {
-	case "fifteenmin":
-		expectedKey = startTime.Format("2006-01-02-15-04")
-	case "hour":
-		expectedKey = startTime.Format("2006-01-02-15")
-	case "day":
-		expectedKey = startTime.Format("2006-01-02")
-	case "work-segment":
+	if periodType == "work-segment" {
 		// Work-segment keys include segment number, so we only validate date part
-		if strings.HasPrefix(periodKey, startTime.Format("2006-01-02")) {
+		if key, err := ParsePeriodKey(periodKey); err == nil && key.Type == "work-segment" &&
+			key.Start.Format("2006-01-02") == startTime.Format("2006-01-02") {
 			return nil
 		}
 		return fmt.Errorf("period_key %s does not match start_time %s for work-segment", periodKey, startTime.Format("2006-01-02"))
-	case "week":
-		// Week keys are YYYY-MM-DD-week format, where date is Monday
-		weekday := int(startTime.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		monday := startTime.AddDate(0, 0, -(weekday - 1))
-		expectedKey = monday.Format("2006-01-02") + "-week"
-	case "month":
-		expectedKey = startTime.Format("2006-01")
-	case "quarter":
-		quarter := (int(startTime.Month())-1)/3 + 1
-		expectedKey = fmt.Sprintf("%d-Q%d", startTime.Year(), quarter)
-	case "year":
-		expectedKey = startTime.Format("2006")
-	default:
+	}
+
+	expectedKey := BuildPeriodKeyFromStartTime(startTime, periodType)
+	if expectedKey == "" {
 		// For unknown types, skip validation
 		return nil
 	}
 
-	if periodKey != expectedKey {
+	// Version 1 keys are accepted as long as they identify the same period
+	if normalizePeriodKeyOfType(periodKey, periodType) != expectedKey {
 		return fmt.Errorf("period_key mismatch: expected %s (from start_time %s), got %s", expectedKey, startTime.Format("2006-01-02 15:04:05"), periodKey)
 	}
 
@@ -403,26 +308,16 @@ func ValidatePeriodKeyFromStartTime(periodKey string, periodType string, startTi
 // This is used to validate or correct period_key extracted from file path
 func BuildPeriodKeyFromStartTime(startTime time.Time, periodType string) string {
 	switch periodType {
-	case "fifteenmin":
-		return startTime.Format("2006-01-02-15-04")
-	case "hour":
-		return startTime.Format("2006-01-02-15")
-	case "day":
-		return startTime.Format("2006-01-02")
+	case "fifteenmin", "hour", "day", "month", "quarter", "year":
+		return FormatPeriodKey(periodType, startTime)
 	case "week":
+		// Week keys use the date of the Monday
 		weekday := int(startTime.Weekday())
 		if weekday == 0 {
 			weekday = 7
 		}
 		monday := startTime.AddDate(0, 0, -(weekday - 1))
-		return monday.Format("2006-01-02") + "-week"
-	case "month":
-		return startTime.Format("2006-01")
-	case "quarter":
-		quarter := (int(startTime.Month())-1)/3 + 1
-		return fmt.Sprintf("%d-Q%d", startTime.Year(), quarter)
-	case "year":
-		return startTime.Format("2006")
+		return FormatPeriodKey(periodType, monday)
 	default:
 		return ""
 	}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ScreenshotRoute records the screenshot type (routing rule) chosen from the frontmost window at capture,
+// which decides the model that analyzes the screenshot
+// Routes are keyed by image path, so they survive rebuilds (which re-import screenshots with new IDs)
+type ScreenshotRoute struct {
+	ImagePath string    `db:"image_path"`
+	Timestamp time.Time `db:"timestamp"`
+	Route     string    `db:"route"`
+}
+
+// RouteStore stores screenshot routes
+type RouteStore interface {
+	SaveScreenshotRoute(route *ScreenshotRoute) error
+	// GetScreenshotRoute returns the route name of the screenshot image, empty if it wasn't routed
+	GetScreenshotRoute(imagePath string) (string, error)
+}
+
+func (s *SQLiteStorage) initRouteTable() error {
+	createRouteTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_routes (
+		image_path TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		route TEXT NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createRouteTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_routes table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveScreenshotRoute(route *ScreenshotRoute) error {
+	query := `
+	INSERT OR REPLACE INTO screenshot_routes (image_path, timestamp, route)
+	VALUES (?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, route.ImagePath, route.Timestamp.Format(time.RFC3339Nano), route.Route); err != nil {
+		return fmt.Errorf("failed to save screenshot route: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetScreenshotRoute(imagePath string) (string, error) {
+	var route string
+	err := s.db.QueryRow(`SELECT route FROM screenshot_routes WHERE image_path = ?`, imagePath).Scan(&route)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get screenshot route: %w", err)
+	}
+	return route, nil
+}
+
+func (r *ReportStorage) SaveScreenshotRoute(route *ScreenshotRoute) error {
+	return r.metadataStorage.SaveScreenshotRoute(route)
+}
+
+func (r *ReportStorage) GetScreenshotRoute(imagePath string) (string, error) {
+	return r.metadataStorage.GetScreenshotRoute(imagePath)
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RowTable is a table exposed to the query command; only its documented columns can be selected
+// or filtered, so queries never depend on (or reach) the rest of the schema
+type RowTable struct {
+	Name           string
+	Description    string
+	Columns        []RowColumn
+	DefaultColumns []string // Columns shown without --columns
+
+	table   string
+	orderBy string
+}
+
+// RowColumn is a documented column of a RowTable
+type RowColumn struct {
+	Name        string
+	Description string
+}
+
+// RowFilter is one condition of a row query, e.g. hour_key=2025-12-09-14
+type RowFilter struct {
+	Column string
+	Op     string // One of RowFilterOps
+	Value  string
+}
+
+// RowFilterOps are the supported filter operators; "~" matches values containing the text
+var RowFilterOps = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// RowQuery selects columns of a RowTable's rows matching all filters
+type RowQuery struct {
+	Table   string
+	Columns []string
+	Filters []RowFilter
+	Limit   int
+}
+
+// MaxRowQueryLimit caps the rows returned by one query
+const MaxRowQueryLimit = 10000
+
+// RowQueryStore runs structured queries for the query command
+type RowQueryStore interface {
+	// QueryRows runs a query built from a fixed template: the table and column names are checked
+	// against the documented columns and filter values are bound as parameters
+	// Returns one value per selected column for each row (string, int64, float64, time.Time or nil)
+	QueryRows(q *RowQuery) ([][]any, error)
+}
+
+// RowTables are the tables available to the query command
+// Times are stored as RFC 3339 text in local time, so they compare correctly against prefixes
+// such as 2025-12-09 or 2025-12-09T14
+var RowTables = []*RowTable{
+	{
+		Name:        "screenshots",
+		Description: "Captured screenshots and their analyses",
+		Columns: []RowColumn{
+			{"id", "Screenshot ID"},
+			{"timestamp", "Capture time (RFC 3339)"},
+			{"hour_key", "Hour of the capture (YYYY-MM-DD-HH)"},
+			{"screen_id", "Captured display"},
+			{"image_path", "Image file"},
+			{"analysis", "Analysis of the screenshot"},
+			{"analysis_status", "pending, done, skipped, failed, rejected, sampled_out"},
+			{"analysis_error", "Failure reason of a failed analysis"},
+			{"app", "Frontmost application at capture"},
+			{"window_title", "Frontmost window title at capture"},
+		},
+		DefaultColumns: []string{"id", "timestamp", "analysis_status", "analysis"},
+		table:          "screenshots",
+		orderBy:        "timestamp ASC",
+	},
+	{
+		Name:        "summaries",
+		Description: "Period summaries of every level",
+		Columns: []RowColumn{
+			{"period_key", "Period key (e.g. day:2025-12-09, hour:2025-12-09T06:00Z, week:2025-12-08)"},
+			{"period_type", "fifteenmin, hour, work-segment, day, week, month, quarter, year"},
+			{"start_time", "Period start (RFC 3339)"},
+			{"end_time", "Period end (RFC 3339)"},
+			{"summary", "Summary text"},
+			{"analysis", "Improvement suggestions"},
+			{"screenshots", "Comma-separated IDs of the screenshots summarized"},
+		},
+		DefaultColumns: []string{"period_key", "period_type", "summary"},
+		table:          "period_summaries",
+		orderBy:        "start_time ASC, period_type ASC",
+	},
+	{
+		Name:        "windows",
+		Description: "Frontmost windows recorded at capture time",
+		Columns: []RowColumn{
+			{"screenshot_id", "Screenshot ID"},
+			{"timestamp", "Capture time (RFC 3339)"},
+			{"app", "Frontmost application"},
+			{"title", "Window title"},
+			{"repo", "Attributed repository, empty for app-only records"},
+			{"subproject", "Monorepo sub-project"},
+			{"branch", "Git branch"},
+		},
+		DefaultColumns: []string{"timestamp", "app", "title", "repo", "branch"},
+		table:          "screenshot_windows",
+		orderBy:        "timestamp ASC",
+	},
+	{
+		Name:        "api_calls",
+		Description: "Requests sent to the LLM provider",
+		Columns: []RowColumn{
+			{"timestamp", "Request time (RFC 3339)"},
+			{"model", "Model"},
+			{"purpose", "Request purpose (e.g. screenshot_analysis, summary)"},
+			{"level", "Summary period type"},
+			{"prompt_tokens", "Prompt tokens"},
+			{"completion_tokens", "Completion tokens"},
+			{"cached_tokens", "Prompt tokens served from the provider's cache"},
+			{"latency_ms", "Latency in milliseconds"},
+			{"error", "Error of a failed request"},
+		},
+		DefaultColumns: []string{"timestamp", "model", "purpose", "level", "prompt_tokens", "completion_tokens", "error"},
+		table:          "api_calls",
+		orderBy:        "timestamp ASC",
+	},
+}
+
+// LookupRowTable returns the table with the given name, nil if it isn't exposed
+func LookupRowTable(name string) *RowTable {
+	for _, t := range RowTables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// HasColumn reports whether name is a documented column of the table
+func (t *RowTable) HasColumn(name string) bool {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnNames returns the documented column names
+func (t *RowTable) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+var rowFilterPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(!=|>=|<=|=|>|<|~)(.*)$`)
+
+// ParseRowFilter parses a condition such as hour_key=2025-12-09-14 or analysis~飞书
+func ParseRowFilter(expr string) (RowFilter, error) {
+	m := rowFilterPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return RowFilter{}, fmt.Errorf("invalid condition %q (expected <column><op><value>, op one of %s)",
+			expr, strings.Join(RowFilterOps, " "))
+	}
+	return RowFilter{Column: m[1], Op: m[2], Value: strings.TrimSpace(m[3])}, nil
+}
+
+// buildRowQuery renders the query template and its parameters after validating the query
+func buildRowQuery(q *RowQuery) (string, []any, error) {
+	table := LookupRowTable(q.Table)
+	if table == nil {
+		return "", nil, fmt.Errorf("unknown table: %s", q.Table)
+	}
+	columns := q.Columns
+	if len(columns) == 0 {
+		columns = table.DefaultColumns
+	}
+	for _, c := range columns {
+		if !table.HasColumn(c) {
+			return "", nil, fmt.Errorf("unknown column %q of %s (available: %s)", c, table.Name, strings.Join(table.ColumnNames(), ", "))
+		}
+	}
+
+	var where []string
+	var args []any
+	for _, f := range q.Filters {
+		if !table.HasColumn(f.Column) {
+			return "", nil, fmt.Errorf("unknown column %q of %s (available: %s)", f.Column, table.Name, strings.Join(table.ColumnNames(), ", "))
+		}
+		switch f.Op {
+		case "=", "!=", ">=", "<=", ">", "<":
+			where = append(where, fmt.Sprintf("%s %s ?", f.Column, f.Op))
+			args = append(args, f.Value)
+		case "~":
+			where = append(where, fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, f.Column))
+			escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(f.Value)
+			args = append(args, "%"+escaped+"%")
+		default:
+			return "", nil, fmt.Errorf("unsupported operator %q", f.Op)
+		}
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > MaxRowQueryLimit {
+		limit = MaxRowQueryLimit
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), table.table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT ?", table.orderBy)
+	args = append(args, limit)
+	return query, args, nil
+}
+
+func (s *SQLiteStorage) QueryRows(q *RowQuery) ([][]any, error) {
+	query, args, err := buildRowQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", q.Table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", q.Table, err)
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReportStorage) QueryRows(q *RowQuery) ([][]any, error) {
+	return r.metadataStorage.QueryRows(q)
+}
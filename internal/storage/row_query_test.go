@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueryRows(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	for i, analysis := range []string{"编辑 main.go", "回复飞书消息", "100% done_ok"} {
+		record := &ScreenshotRecord{ID: string(rune('a' + i)), Timestamp: base.Add(time.Duration(i) * 30 * time.Minute),
+			HourKey: base.Add(time.Duration(i) * 30 * time.Minute).Format("2006-01-02-15"), Analysis: analysis}
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    []string
+	}{
+		{"equal", []string{"hour_key=2025-12-09-14"}, []string{"a", "b"}},
+		{"contains", []string{"analysis~飞书"}, []string{"b"}},
+		{"wildcards are literal", []string{"analysis~%"}, []string{"c"}},
+		{"time prefix", []string{"timestamp>=2025-12-09T14:30", "id!=c"}, []string{"b"}},
+		{"injection is a value", []string{"id=a' OR '1'='1"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &RowQuery{Table: "screenshots", Columns: []string{"id", "screen_id"}}
+			for _, expr := range tt.filters {
+				f, err := ParseRowFilter(expr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				q.Filters = append(q.Filters, f)
+			}
+			rows, err := st.QueryRows(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, row := range rows {
+				got = append(got, row[0].(string))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	for _, q := range []*RowQuery{
+		{Table: "sqlite_master"},
+		{Table: "screenshots", Columns: []string{"id; DROP TABLE screenshots"}},
+		{Table: "screenshots", Filters: []RowFilter{{Column: "1=1 OR id", Op: "=", Value: "a"}}},
+	} {
+		if _, err := st.QueryRows(q); err == nil {
+			t.Errorf("QueryRows(%+v) should be rejected", q)
+		}
+	}
+	if _, err := ParseRowFilter("id"); err == nil {
+		t.Error("condition without operator should be rejected")
+	}
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Runtime state keys written by the daemon
+const (
+	RuntimeLastCapture       = "last_capture"     // Value: ID of the last saved screenshot
+	RuntimeLastAnalysis      = "last_analysis"    // Value: stats of the last analysis batch
+	RuntimeLastSummaryPrefix = "last_summary:"    // + period type, value: period key of the last generated summary
+	RuntimeHealthProbe       = "health_probe"     // Value: time written by the database step of the last self-test
+	RuntimePausedUntil       = "paused_until"     // Value: time until which capture is paused, empty when not paused
+	RuntimeAnalysisWorkers   = "analysis_workers" // Value: worker count chosen by screenshot.worker_scaling
+)
+
+// RuntimeState is a piece of pipeline state persisted by the daemon and shown by the status command
+type RuntimeState struct {
+	Key       string    `db:"key"`
+	Value     string    `db:"value"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// RuntimeStateStore stores the daemon runtime state
+type RuntimeStateStore interface {
+	// SaveRuntimeState sets the value of a key, updated now
+	SaveRuntimeState(key, value string) error
+	// GetRuntimeStates returns all keys, keyed by name
+	GetRuntimeStates() (map[string]*RuntimeState, error)
+}
+
+func (s *SQLiteStorage) initRuntimeStateTable() error {
+	createRuntimeStateTable := `
+	CREATE TABLE IF NOT EXISTS runtime_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createRuntimeStateTable); err != nil {
+		return fmt.Errorf("failed to create runtime_state table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveRuntimeState(key, value string) error {
+	query := `INSERT OR REPLACE INTO runtime_state (key, value, updated_at) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(query, key, value, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save runtime state: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetRuntimeStates() (map[string]*RuntimeState, error) {
+	rows, err := s.db.Query(`SELECT key, value, updated_at FROM runtime_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runtime state: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string]*RuntimeState)
+	for rows.Next() {
+		var state RuntimeState
+		var updatedAtStr string
+		if err := rows.Scan(&state.Key, &state.Value, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan runtime state: %w", err)
+		}
+		state.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAtStr)
+		states[state.Key] = &state
+	}
+	return states, rows.Err()
+}
+
+func (r *ReportStorage) SaveRuntimeState(key, value string) error {
+	return r.metadataStorage.SaveRuntimeState(key, value)
+}
+
+func (r *ReportStorage) GetRuntimeStates() (map[string]*RuntimeState, error) {
+	return r.metadataStorage.GetRuntimeStates()
+}
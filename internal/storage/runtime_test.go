@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRuntimeState(t *testing.T) {
+	s, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	states, err := s.GetRuntimeStates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 0 {
+		t.Fatalf("GetRuntimeStates() on a new database = %v, want none", states)
+	}
+
+	before := time.Now().Add(-time.Second)
+	for _, state := range [][2]string{
+		{RuntimeLastCapture, "s1"},
+		{RuntimeLastSummaryPrefix + "hour", "hour:2025-12-09-14"},
+		{RuntimeLastCapture, "s2"},
+	} {
+		if err := s.SaveRuntimeState(state[0], state[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	states, err = s.GetRuntimeStates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{RuntimeLastCapture: "s2", RuntimeLastSummaryPrefix + "hour": "hour:2025-12-09-14"}
+	if len(states) != len(want) {
+		t.Fatalf("GetRuntimeStates() = %v, want %v", states, want)
+	}
+	for key, value := range want {
+		state := states[key]
+		if state == nil || state.Value != value || state.UpdatedAt.Before(before) {
+			t.Errorf("state %s = %+v, want %q updated now", key, state, value)
+		}
+	}
+}
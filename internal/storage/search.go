@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SearchStore provides full-text (substring) search over stored analyses and summaries
+type SearchStore interface {
+	// SearchScreenshots returns screenshots in [start, end) whose analysis contains text, newest first
+	SearchScreenshots(text string, start, end time.Time, limit int) ([]*ScreenshotRecord, error)
+	// SearchPeriodSummaries returns summaries of periods overlapping [start, end) whose text contains text, newest first
+	SearchPeriodSummaries(text string, start, end time.Time, limit int) ([]*PeriodSummary, error)
+}
+
+// likePattern escapes LIKE wildcards in text and wraps it for a substring match
+func likePattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(text) + "%"
+}
+
+func (s *SQLiteStorage) SearchScreenshots(text string, start, end time.Time, limit int) ([]*ScreenshotRecord, error) {
+	query := `
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
+	FROM screenshots
+	WHERE analysis LIKE ? ESCAPE '\' AND timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp DESC
+	LIMIT ?
+	`
+	rows, err := s.db.Query(query, likePattern(text), start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*ScreenshotRecord
+	for rows.Next() {
+		var r ScreenshotRecord
+		var timestampStr string
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
+		}
+		if r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		records = append(records, &r)
+	}
+	return records, rows.Err()
+}
+
+func (s *SQLiteStorage) SearchPeriodSummaries(text string, start, end time.Time, limit int) ([]*PeriodSummary, error) {
+	query := `
+	SELECT period_key, period_type, start_time, end_time, screenshots, summary, COALESCE(analysis, '')
+	FROM period_summaries
+	WHERE (summary LIKE ? ESCAPE '\' OR analysis LIKE ? ESCAPE '\') AND start_time < ? AND end_time > ?
+	ORDER BY start_time DESC
+	LIMIT ?
+	`
+	pattern := likePattern(text)
+	rows, err := s.db.Query(query, pattern, pattern, end.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search period summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*PeriodSummary
+	for rows.Next() {
+		var ps PeriodSummary
+		var startTimeStr, endTimeStr string
+		if err := rows.Scan(&ps.PeriodKey, &ps.PeriodType, &startTimeStr, &endTimeStr, &ps.Screenshots, &ps.Summary, &ps.Analysis); err != nil {
+			return nil, fmt.Errorf("failed to scan period summary: %w", err)
+		}
+		if ps.StartTime, err = time.Parse(time.RFC3339Nano, startTimeStr); err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		if ps.EndTime, err = time.Parse(time.RFC3339Nano, endTimeStr); err != nil {
+			return nil, fmt.Errorf("failed to parse end_time: %w", err)
+		}
+		summaries = append(summaries, &ps)
+	}
+	return summaries, rows.Err()
+}
+
+func (r *ReportStorage) SearchScreenshots(text string, start, end time.Time, limit int) ([]*ScreenshotRecord, error) {
+	return r.metadataStorage.SearchScreenshots(text, start, end, limit)
+}
+
+func (r *ReportStorage) SearchPeriodSummaries(text string, start, end time.Time, limit int) ([]*PeriodSummary, error) {
+	return r.metadataStorage.SearchPeriodSummaries(text, start, end, limit)
+}
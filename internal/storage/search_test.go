@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchPeriodSummaries(t *testing.T) {
+	st, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	week := time.Date(2025, 12, 8, 0, 0, 0, 0, time.Local)
+	for _, s := range []*PeriodSummary{
+		{PeriodKey: "day:2025-12-09", PeriodType: "day", StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: "评审发布计划"},
+		{PeriodKey: "day:2025-12-02", PeriodType: "day", StartTime: day.AddDate(0, 0, -7), EndTime: day.AddDate(0, 0, -6), Summary: "起草发布计划"},
+		{PeriodKey: "week:2025-12-08", PeriodType: "week", StartTime: week, EndTime: week.AddDate(0, 0, 7), Summary: "完成发布计划"},
+		{PeriodKey: "day:2025-12-10", PeriodType: "day", StartTime: day.AddDate(0, 0, 1), EndTime: day.AddDate(0, 0, 2), Summary: "编写代码"},
+	} {
+		if err := st.SavePeriodSummary(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []string
+	}{
+		{"all time", time.Time{}, day.AddDate(1, 0, 0), []string{"day:2025-12-09", "week:2025-12-08", "day:2025-12-02"}},
+		{"one day keeps the overlapping week", day, day.AddDate(0, 0, 1), []string{"day:2025-12-09", "week:2025-12-08"}},
+		{"before the week", day.AddDate(0, 0, -7), day.AddDate(0, 0, -6), []string{"day:2025-12-02"}},
+	}
+	for _, tt := range tests {
+		summaries, err := st.SearchPeriodSummaries("发布计划", tt.start, tt.end, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range summaries {
+			got = append(got, s.PeriodKey)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SearchPeriodSummaries() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
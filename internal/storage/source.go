@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ScreenshotSource records which remote agent sent a screenshot; screenshots captured by the daemon itself have none
+// Sources are keyed by image path, so they survive rebuilds (which re-import screenshots with new IDs)
+type ScreenshotSource struct {
+	ImagePath  string    `db:"image_path"`
+	Agent      string    `db:"agent"`
+	ReceivedAt time.Time `db:"received_at"`
+}
+
+// SourceStore stores the agent sources of screenshots
+type SourceStore interface {
+	SetScreenshotSource(source *ScreenshotSource) error
+	// GetScreenshotSource returns nil if the screenshot was captured locally
+	GetScreenshotSource(imagePath string) (*ScreenshotSource, error)
+}
+
+func (s *SQLiteStorage) initSourceTable() error {
+	createSourceTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_sources (
+		image_path TEXT PRIMARY KEY,
+		agent TEXT NOT NULL,
+		received_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createSourceTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_sources table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SetScreenshotSource(source *ScreenshotSource) error {
+	query := `
+	INSERT OR REPLACE INTO screenshot_sources (image_path, agent, received_at)
+	VALUES (?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, source.ImagePath, source.Agent, source.ReceivedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save screenshot source: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetScreenshotSource(imagePath string) (*ScreenshotSource, error) {
+	query := `SELECT image_path, agent, received_at FROM screenshot_sources WHERE image_path = ?`
+	var source ScreenshotSource
+	var receivedAtStr string
+	err := s.db.QueryRow(query, imagePath).Scan(&source.ImagePath, &source.Agent, &receivedAtStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshot source: %w", err)
+	}
+	source.ReceivedAt, _ = time.Parse(time.RFC3339Nano, receivedAtStr)
+	return &source, nil
+}
+
+func (r *ReportStorage) SetScreenshotSource(source *ScreenshotSource) error {
+	return r.metadataStorage.SetScreenshotSource(source)
+}
+
+func (r *ReportStorage) GetScreenshotSource(imagePath string) (*ScreenshotSource, error) {
+	return r.metadataStorage.GetScreenshotSource(imagePath)
+}
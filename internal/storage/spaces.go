@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScreenshotSpace is the macOS Space (virtual desktop) that was active when a screenshot was captured
+type ScreenshotSpace struct {
+	ScreenshotID string
+	Timestamp    time.Time
+	Space        int // 1-based desktop number as shown in Mission Control
+}
+
+// SpaceStore stores the Spaces of screenshots
+type SpaceStore interface {
+	// SaveScreenshotSpace saves the Space of a screenshot
+	SaveScreenshotSpace(space *ScreenshotSpace) error
+	// QueryScreenshotSpaces returns the Spaces of screenshots captured in [start, end)
+	QueryScreenshotSpaces(start, end time.Time) ([]*ScreenshotSpace, error)
+}
+
+func (s *SQLiteStorage) initSpaceTable() error {
+	createSpaceTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_spaces (
+		screenshot_id TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		space INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_screenshot_spaces_timestamp ON screenshot_spaces(timestamp);
+	`
+	if _, err := s.db.Exec(createSpaceTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_spaces table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveScreenshotSpace(space *ScreenshotSpace) error {
+	query := `INSERT OR REPLACE INTO screenshot_spaces (screenshot_id, timestamp, space) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(query, space.ScreenshotID, space.Timestamp.Format(time.RFC3339Nano), space.Space); err != nil {
+		return fmt.Errorf("failed to save screenshot space: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryScreenshotSpaces(start, end time.Time) ([]*ScreenshotSpace, error) {
+	query := `
+	SELECT screenshot_id, timestamp, space
+	FROM screenshot_spaces
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot spaces: %w", err)
+	}
+	defer rows.Close()
+
+	var spaces []*ScreenshotSpace
+	for rows.Next() {
+		var sp ScreenshotSpace
+		var timestampStr string
+		if err := rows.Scan(&sp.ScreenshotID, &timestampStr, &sp.Space); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot space: %w", err)
+		}
+		if sp.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		spaces = append(spaces, &sp)
+	}
+	return spaces, rows.Err()
+}
+
+func (r *ReportStorage) SaveScreenshotSpace(space *ScreenshotSpace) error {
+	return r.metadataStorage.SaveScreenshotSpace(space)
+}
+
+func (r *ReportStorage) QueryScreenshotSpaces(start, end time.Time) ([]*ScreenshotSpace, error) {
+	return r.metadataStorage.QueryScreenshotSpaces(start, end)
+}
@@ -18,7 +18,12 @@ type SQLiteStorage struct {
 
 // newSQLiteStorage creates a SQLite storage instance (internal function)
 func newSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	if IsReadOnly() {
+		return openReadOnlySQLiteStorage(dbPath)
+	}
+
+	// The daemon, generate and serve may use the database at once; wait for their writes instead of failing
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -32,6 +37,24 @@ func newSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	return s, nil
 }
 
+// openReadOnlySQLiteStorage opens an existing database read-only, without creating or migrating tables
+func openReadOnlySQLiteStorage(dbPath string) (*SQLiteStorage, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open database read-only: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database read-only: %w", err)
+	}
+
+	return &SQLiteStorage{db: db}, nil
+}
+
 func (s *SQLiteStorage) init() error {
 	createScreenshotsTable := `
 	CREATE TABLE IF NOT EXISTS screenshots (
@@ -40,7 +63,11 @@ func (s *SQLiteStorage) init() error {
 		screen_id INTEGER NOT NULL,
 		image_path TEXT NOT NULL,
 		analysis TEXT,
-		hour_key TEXT NOT NULL
+		hour_key TEXT NOT NULL,
+		analysis_status TEXT NOT NULL DEFAULT 'pending',
+		analysis_error TEXT,
+		app TEXT,
+		window_title TEXT
 	);
 	`
 
@@ -69,6 +96,7 @@ func (s *SQLiteStorage) init() error {
 	createIndexes := `
 	CREATE INDEX IF NOT EXISTS idx_screenshots_timestamp ON screenshots(timestamp);
 	CREATE INDEX IF NOT EXISTS idx_screenshots_hour_key ON screenshots(hour_key);
+	CREATE INDEX IF NOT EXISTS idx_screenshots_analysis_status ON screenshots(analysis_status);
 	CREATE INDEX IF NOT EXISTS idx_hour_summaries_date ON hour_summaries(date);
 	CREATE INDEX IF NOT EXISTS idx_period_summaries_type ON period_summaries(period_type);
 	CREATE INDEX IF NOT EXISTS idx_period_summaries_start ON period_summaries(start_time);
@@ -86,38 +114,259 @@ func (s *SQLiteStorage) init() error {
 		return fmt.Errorf("failed to create period_summaries table: %w", err)
 	}
 
+	if err := s.migrateAnalysisStatus(); err != nil {
+		return err
+	}
+	// Columns may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE screenshots ADD COLUMN app TEXT")
+	_, _ = s.db.Exec("ALTER TABLE screenshots ADD COLUMN window_title TEXT")
+
 	if _, err := s.db.Exec(createIndexes); err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
 	}
 
+	if err := s.initAuditTable(); err != nil {
+		return err
+	}
+
+	if err := s.initDeliverablesTable(); err != nil {
+		return err
+	}
+
+	if err := s.initIssueTables(); err != nil {
+		return err
+	}
+
+	if err := s.initFocusTable(); err != nil {
+		return err
+	}
+
+	if err := s.initAttachmentTable(); err != nil {
+		return err
+	}
+
+	if err := s.initStarTable(); err != nil {
+		return err
+	}
+
+	if err := s.initWindowTable(); err != nil {
+		return err
+	}
+
+	if err := s.initTimeSyncTable(); err != nil {
+		return err
+	}
+
+	if err := s.initUploadTable(); err != nil {
+		return err
+	}
+
+	if err := s.initLocalOnlyTable(); err != nil {
+		return err
+	}
+
+	if err := s.initSourceTable(); err != nil {
+		return err
+	}
+
+	if err := s.initRemoteImageTable(); err != nil {
+		return err
+	}
+
+	if err := s.initRuntimeStateTable(); err != nil {
+		return err
+	}
+
+	if err := s.initWindowBoundsTable(); err != nil {
+		return err
+	}
+
+	if err := s.initGoldenTable(); err != nil {
+		return err
+	}
+
+	if err := s.initPeriodLockTable(); err != nil {
+		return err
+	}
+
+	if err := s.initReportHashTable(); err != nil {
+		return err
+	}
+
+	if err := s.initMissingReportTable(); err != nil {
+		return err
+	}
+
+	if err := s.initAPICallTable(); err != nil {
+		return err
+	}
+
+	if err := s.initTreePairTable(); err != nil {
+		return err
+	}
+
+	if err := s.initLineageTable(); err != nil {
+		return err
+	}
+
+	if err := s.initLockScreenCheckTable(); err != nil {
+		return err
+	}
+
+	if err := s.initThreadTables(); err != nil {
+		return err
+	}
+
+	if err := s.initRouteTable(); err != nil {
+		return err
+	}
+
+	if err := s.initHealthTable(); err != nil {
+		return err
+	}
+
+	if err := s.initRemoteTables(); err != nil {
+		return err
+	}
+
+	if err := s.initOCRTable(); err != nil {
+		return err
+	}
+
+	if err := s.initBacklogTable(); err != nil {
+		return err
+	}
+
+	if err := s.initPlanTable(); err != nil {
+		return err
+	}
+
+	if err := s.initSpaceTable(); err != nil {
+		return err
+	}
+
+	if err := s.initPromptTable(); err != nil {
+		return err
+	}
+
+	if err := s.initWorkdayTable(); err != nil {
+		return err
+	}
+
+	if err := s.initGenerationTable(); err != nil {
+		return err
+	}
+
+	if err := s.initDataVersionTable(); err != nil {
+		return err
+	}
+
+	if err := s.migratePeriodKeys(); err != nil {
+		return fmt.Errorf("failed to migrate period keys: %w", err)
+	}
+
 	return nil
 }
 
 func (s *SQLiteStorage) SaveScreenshot(record *ScreenshotRecord) error {
 	query := `
-	INSERT INTO screenshots (id, timestamp, screen_id, image_path, analysis, hour_key)
-	VALUES (?, ?, ?, ?, ?, ?)
+	INSERT INTO screenshots (id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, analysis_error, app, window_title)
+	VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))
 	`
-	_, err := s.db.Exec(query, record.ID, record.Timestamp.Format(time.RFC3339Nano), record.ScreenID, record.ImagePath, record.Analysis, record.HourKey)
+	if record.AnalysisStatus == "" {
+		record.AnalysisStatus = AnalysisPending
+		if record.Analysis != "" {
+			record.AnalysisStatus = AnalysisDone
+		}
+	}
+	_, err := s.db.Exec(query, record.ID, record.Timestamp.Format(time.RFC3339Nano), record.ScreenID, record.ImagePath, record.Analysis, record.HourKey, record.AnalysisStatus,
+		record.AnalysisError, record.App, record.WindowTitle)
 	if err != nil {
 		return fmt.Errorf("failed to save screenshot: %w", err)
 	}
 	return nil
 }
 
-// UpdateScreenshotAnalysis updates the summary field (semantically, analysis stores summary)
+// UpdateScreenshotAnalysis updates the summary field (semantically, analysis stores summary) and marks it done
 func (s *SQLiteStorage) UpdateScreenshotAnalysis(id, analysis string) error {
-	query := `UPDATE screenshots SET analysis = ? WHERE id = ?`
-	_, err := s.db.Exec(query, analysis, id)
+	query := `UPDATE screenshots SET analysis = ?, analysis_status = ?, analysis_error = NULL WHERE id = ?`
+	_, err := s.db.Exec(query, analysis, AnalysisDone, id)
 	if err != nil {
 		return fmt.Errorf("failed to update screenshot summary: %w", err)
 	}
 	return nil
 }
 
+// UpdateScreenshotAnalysisStatus records a screenshot that was not analyzed (skipped, failed, rejected)
+// The analysis text is cleared so the failure reason never leaks into summaries
+func (s *SQLiteStorage) UpdateScreenshotAnalysisStatus(id, status, analysisError string) error {
+	query := `UPDATE screenshots SET analysis = '', analysis_status = ?, analysis_error = NULLIF(?, '') WHERE id = ?`
+	if _, err := s.db.Exec(query, status, analysisError, id); err != nil {
+		return fmt.Errorf("failed to update screenshot analysis status: %w", err)
+	}
+	return nil
+}
+
+// UpdateScreenshotAnalyses writes a batch of analysis results in a single transaction
+func (s *SQLiteStorage) UpdateScreenshotAnalyses(updates []*ScreenshotAnalysisUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE screenshots SET analysis = ?, analysis_status = ?, analysis_error = NULLIF(?, '') WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare analysis update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, u := range updates {
+		analysis, analysisError := u.Analysis, u.Error
+		if u.Status != AnalysisDone {
+			analysis = ""
+		} else {
+			analysisError = ""
+		}
+		if _, err := stmt.Exec(analysis, u.Status, analysisError, u.ID); err != nil {
+			return fmt.Errorf("failed to update analysis for %s: %w", u.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit analysis updates: %w", err)
+	}
+	return nil
+}
+
+// migrateAnalysisStatus adds the analysis_status/analysis_error columns to old databases
+// and moves failure reasons stored as "Analysis failed: ..." analysis text into analysis_error
+func (s *SQLiteStorage) migrateAnalysisStatus() error {
+	// Columns may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE screenshots ADD COLUMN analysis_status TEXT NOT NULL DEFAULT 'pending'")
+	_, _ = s.db.Exec("ALTER TABLE screenshots ADD COLUMN analysis_error TEXT")
+
+	migrations := []string{
+		fmt.Sprintf(`UPDATE screenshots SET analysis_status = '%s', analysis_error = TRIM(SUBSTR(analysis, %d), ': '), analysis = ''
+		WHERE analysis LIKE '%s%%'`, AnalysisFailed, len(AnalysisFailedPrefix)+1, AnalysisFailedPrefix),
+		fmt.Sprintf(`UPDATE screenshots SET analysis_status = '%s'
+		WHERE analysis_status = '%s' AND analysis IS NOT NULL AND analysis != ''`, AnalysisDone, AnalysisPending),
+	}
+	for _, migration := range migrations {
+		if _, err := s.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to migrate screenshot analysis status: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) GetScreenshotsByHourKey(hourKey string) ([]*ScreenshotRecord, error) {
 	query := `
-	SELECT id, timestamp, screen_id, image_path, analysis, hour_key
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
 	FROM screenshots
 	WHERE hour_key = ?
 	ORDER BY timestamp ASC
@@ -132,7 +381,7 @@ func (s *SQLiteStorage) GetScreenshotsByHourKey(hourKey string) ([]*ScreenshotRe
 	for rows.Next() {
 		var r ScreenshotRecord
 		var timestampStr string
-		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey); err != nil {
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
@@ -159,7 +408,7 @@ func (s *SQLiteStorage) GetScreenshotsByIDs(ids []string) (map[string]*Screensho
 	}
 
 	query := fmt.Sprintf(`
-	SELECT id, timestamp, screen_id, image_path, analysis, hour_key
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
 	FROM screenshots
 	WHERE id IN (%s)
 	ORDER BY timestamp ASC
@@ -175,7 +424,7 @@ func (s *SQLiteStorage) GetScreenshotsByIDs(ids []string) (map[string]*Screensho
 	for rows.Next() {
 		var r ScreenshotRecord
 		var timestampStr string
-		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey); err != nil {
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
@@ -264,7 +513,7 @@ func (s *SQLiteStorage) UpdateHourSummary(hourKey string, screenshotIDs []string
 
 func (s *SQLiteStorage) QueryByDateRange(start, end time.Time) ([]*ScreenshotRecord, error) {
 	query := `
-	SELECT id, timestamp, screen_id, image_path, analysis, hour_key
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
 	FROM screenshots
 	WHERE timestamp >= ? AND timestamp <= ?
 	ORDER BY timestamp ASC
@@ -279,7 +528,7 @@ func (s *SQLiteStorage) QueryByDateRange(start, end time.Time) ([]*ScreenshotRec
 	for rows.Next() {
 		var r ScreenshotRecord
 		var timestampStr string
-		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey); err != nil {
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
@@ -320,14 +569,23 @@ func (s *SQLiteStorage) QueryHourSummariesByDateRange(start, end time.Time) ([]*
 	return summaries, rows.Err()
 }
 
-// GetUnanalyzedScreenshots returns screenshots that don't have summary yet
-// (semantically, analysis field stores summary of what user is doing)
+// GetUnanalyzedScreenshots returns screenshots that don't have summary yet or whose analysis failed
+// (semantically, analysis field stores summary of what user is doing); skipped and rejected screenshots are not retried
 func (s *SQLiteStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error) {
+	return s.queryUnanalyzedScreenshots("ASC", limit)
+}
+
+// GetLatestUnanalyzedScreenshots returns the most recent unanalyzed screenshots, newest first
+func (s *SQLiteStorage) GetLatestUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error) {
+	return s.queryUnanalyzedScreenshots("DESC", limit)
+}
+
+func (s *SQLiteStorage) queryUnanalyzedScreenshots(order string, limit int) ([]*ScreenshotRecord, error) {
 	query := `
-	SELECT id, timestamp, screen_id, image_path, analysis, hour_key
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
 	FROM screenshots
-	WHERE analysis IS NULL OR analysis = '' OR analysis LIKE 'Analysis failed%'
-	ORDER BY timestamp ASC
+	WHERE analysis_status IN ('pending', 'failed')
+	ORDER BY timestamp ` + order + `
 	LIMIT ?
 	`
 	rows, err := s.db.Query(query, limit)
@@ -340,7 +598,7 @@ func (s *SQLiteStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord
 	for rows.Next() {
 		var r ScreenshotRecord
 		var timestampStr string
-		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey); err != nil {
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
@@ -353,6 +611,8 @@ func (s *SQLiteStorage) GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord
 }
 
 func (s *SQLiteStorage) SavePeriodSummary(summary *PeriodSummary) error {
+	summary.PeriodKey = normalizePeriodKeyOfType(summary.PeriodKey, summary.PeriodType)
+
 	// Add analysis column if it doesn't exist (for backward compatibility)
 	_, _ = s.db.Exec("ALTER TABLE period_summaries ADD COLUMN analysis TEXT")
 
@@ -368,6 +628,7 @@ func (s *SQLiteStorage) SavePeriodSummary(summary *PeriodSummary) error {
 }
 
 func (s *SQLiteStorage) GetPeriodSummary(periodKey string) (*PeriodSummary, error) {
+	periodKey = NormalizePeriodKey(periodKey)
 	// Try to select with analysis column first, fallback to without if column doesn't exist
 	query := `
 	SELECT period_key, period_type, start_time, end_time, screenshots, summary, COALESCE(analysis, '')
@@ -412,6 +673,7 @@ func (s *SQLiteStorage) GetPeriodSummary(periodKey string) (*PeriodSummary, erro
 }
 
 func (s *SQLiteStorage) DeletePeriodSummary(periodKey string) error {
+	periodKey = NormalizePeriodKey(periodKey)
 	query := `DELETE FROM period_summaries WHERE period_key = ?`
 	_, err := s.db.Exec(query, periodKey)
 	return err
@@ -458,6 +720,21 @@ func (s *SQLiteStorage) CleanupOldRecords(retentionDays int) error {
 		return fmt.Errorf("failed to cleanup old screenshots: %w", err)
 	}
 
+	deleteText := `DELETE FROM screenshot_text WHERE screenshot_id NOT IN (SELECT id FROM screenshots)`
+	if _, err := s.db.Exec(deleteText); err != nil {
+		return fmt.Errorf("failed to cleanup old screenshot text: %w", err)
+	}
+
+	deleteWindows := `DELETE FROM screenshot_windows WHERE timestamp < ?`
+	if _, err := s.db.Exec(deleteWindows, cutoff.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to cleanup old screenshot windows: %w", err)
+	}
+
+	deleteSpaces := `DELETE FROM screenshot_spaces WHERE timestamp < ?`
+	if _, err := s.db.Exec(deleteSpaces, cutoff.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to cleanup old screenshot spaces: %w", err)
+	}
+
 	deleteSummaries := `DELETE FROM hour_summaries WHERE date < ?`
 	if _, err := s.db.Exec(deleteSummaries, cutoff.Format(time.RFC3339Nano)); err != nil {
 		return fmt.Errorf("failed to cleanup old summaries: %w", err)
@@ -472,19 +749,64 @@ func (s *SQLiteStorage) DeleteScreenshotsByIDs(ids []string) error {
 		return nil
 	}
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(ids); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := deleteScreenshotBatch(tx, ids[start:end]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit screenshot deletion: %w", err)
+	}
+	return nil
+}
+
+// deleteBatchSize keeps the IN lists of batched deletes below SQLite's bound parameter limit
+const deleteBatchSize = 500
+
+// screenshotDeletes drop the rows belonging to deleted screenshots, in order
+// Local-only marks, sources, window bounds and upload records are keyed by image path, so they go before the screenshots rows
+var screenshotDeletes = []struct {
+	query string
+	what  string
+}{
+	{`DELETE FROM local_only_screenshots WHERE image_path IN (SELECT image_path FROM screenshots WHERE id IN (%s))`, "local-only marks"},
+	{`DELETE FROM screenshot_sources WHERE image_path IN (SELECT image_path FROM screenshots WHERE id IN (%s))`, "screenshot sources"},
+	{`DELETE FROM screenshot_window_bounds WHERE image_path IN (SELECT image_path FROM screenshots WHERE id IN (%s))`, "window bounds"},
+	{`DELETE FROM remote_images WHERE image_path IN (SELECT image_path FROM screenshots WHERE id IN (%s))`, "remote image records"},
+	{`DELETE FROM screenshots WHERE id IN (%s)`, "screenshots"},
+	{`DELETE FROM screenshot_stars WHERE screenshot_id IN (%s)`, "screenshot stars"},
+	{`DELETE FROM screenshot_windows WHERE screenshot_id IN (%s)`, "screenshot windows"},
+	{`DELETE FROM screenshot_spaces WHERE screenshot_id IN (%s)`, "screenshot spaces"},
+	{`DELETE FROM screenshot_text WHERE screenshot_id IN (%s)`, "screenshot text"},
+	{`DELETE FROM analysis_embeddings WHERE screenshot_id IN (%s)`, "analysis embeddings"},
+	{`DELETE FROM task_thread_members WHERE screenshot_id IN (%s)`, "task thread members"},
+}
+
+// deleteScreenshotBatch deletes one batch of screenshots and their rows
+func deleteScreenshotBatch(tx *sql.Tx, ids []string) error {
 	placeholders := make([]string, len(ids))
 	args := make([]interface{}, len(ids))
 	for i, id := range ids {
 		placeholders[i] = "?"
 		args[i] = id
 	}
-
-	query := fmt.Sprintf(`DELETE FROM screenshots WHERE id IN (%s)`, strings.Join(placeholders, ","))
-	_, err := s.db.Exec(query, args...)
-	if err != nil {
-		return fmt.Errorf("failed to delete screenshots: %w", err)
+	in := strings.Join(placeholders, ",")
+	for _, d := range screenshotDeletes {
+		if _, err := tx.Exec(fmt.Sprintf(d.query, in), args...); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", d.what, err)
+		}
 	}
-
 	return nil
 }
 
@@ -504,7 +826,7 @@ func (s *SQLiteStorage) ClearAllSummaries() error {
 // GetAllScreenshots returns all screenshot records ordered by timestamp
 func (s *SQLiteStorage) GetAllScreenshots() ([]*ScreenshotRecord, error) {
 	query := `
-	SELECT id, timestamp, screen_id, image_path, analysis, hour_key
+	SELECT id, timestamp, screen_id, image_path, analysis, hour_key, analysis_status, COALESCE(analysis_error, ''), COALESCE(app, ''), COALESCE(window_title, '')
 	FROM screenshots
 	ORDER BY timestamp ASC
 	`
@@ -517,7 +839,7 @@ func (s *SQLiteStorage) GetAllScreenshots() ([]*ScreenshotRecord, error) {
 	var records []*ScreenshotRecord
 	for rows.Next() {
 		var r ScreenshotRecord
-		if err := rows.Scan(&r.ID, &r.Timestamp, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey); err != nil {
+		if err := rows.Scan(&r.ID, &r.Timestamp, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle); err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		records = append(records, &r)
@@ -529,18 +851,23 @@ func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
-// LockScreenDetector is a function type that checks if a screenshot is a lock screen
-// Returns true if the screenshot is a lock screen, false otherwise
-type LockScreenDetector func(imagePath string) (bool, error)
+// LockScreenDetector checks a batch of screenshots and returns the paths of those that are lock screens
+// Screenshots that could not be checked are not returned, so they are imported
+type LockScreenDetector func(imagePaths []string) map[string]bool
 
 // RebuildFromDirectory scans the screenshot directory and rebuilds the database
-// If lockScreenDetector is provided, it will be used to filter out lock screen screenshots
+// If lockScreenDetector is provided, it is called once with all screenshots to filter out lock screens
 func (s *SQLiteStorage) RebuildFromDirectory(storagePath string, lockScreenDetector LockScreenDetector) (int, error) {
 	_, err := s.db.Exec("DELETE FROM screenshots")
 	if err != nil {
 		return 0, fmt.Errorf("failed to clear screenshots table: %w", err)
 	}
 
+	_, err = s.db.Exec("DELETE FROM screenshot_text")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear screenshot_text table: %w", err)
+	}
+
 	_, err = s.db.Exec("DELETE FROM hour_summaries")
 	if err != nil {
 		return 0, fmt.Errorf("failed to clear hour_summaries table: %w", err)
@@ -577,19 +904,6 @@ func (s *SQLiteStorage) scanAndImportScreenshots(storagePath string, lockScreenD
 			return nil
 		}
 
-		// Check if this is a lock screen screenshot
-		if lockScreenDetector != nil {
-			isLockScreen, err := lockScreenDetector(path)
-			if err != nil {
-				// Log error but continue processing
-				fmt.Fprintf(os.Stderr, "WARNING: Failed to check if screenshot is lock screen (%s): %v, proceeding anyway\n", path, err)
-			} else if isLockScreen {
-				skippedLockScreens++
-				fmt.Fprintf(os.Stdout, "Skipping lock screen screenshot: %s\n", path)
-				return nil
-			}
-		}
-
 		relPath, err := filepath.Rel(storagePath, path)
 		if err != nil {
 			return nil
@@ -608,6 +922,25 @@ func (s *SQLiteStorage) scanAndImportScreenshots(storagePath string, lockScreenD
 		return count, err
 	}
 
+	// Check all screenshots at once so the detector can run checks in parallel
+	if lockScreenDetector != nil && len(records) > 0 {
+		paths := make([]string, 0, len(records))
+		for _, record := range records {
+			paths = append(paths, record.ImagePath)
+		}
+		lockScreens := lockScreenDetector(paths)
+		kept := records[:0]
+		for _, record := range records {
+			if lockScreens[record.ImagePath] {
+				skippedLockScreens++
+				fmt.Fprintf(os.Stdout, "Skipping lock screen screenshot: %s\n", record.ImagePath)
+				continue
+			}
+			kept = append(kept, record)
+		}
+		records = kept
+	}
+
 	for _, record := range records {
 		if err := s.SaveScreenshot(record); err != nil {
 			continue
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMigrateAnalysisStatus(t *testing.T) {
+	s, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Rows written before the status columns kept failures in the analysis text
+	timestamp := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local).Format(time.RFC3339Nano)
+	for _, row := range [][2]string{
+		{"failed", AnalysisFailedPrefix + ": status 500"},
+		{"done", "Editing bundle.go in VS Code"},
+		{"pending", ""},
+	} {
+		if _, err := s.db.Exec(`INSERT INTO screenshots (id, timestamp, screen_id, image_path, analysis, hour_key)
+			VALUES (?, ?, 0, ?, ?, '2025-12-09-14')`, row[0], timestamp, row[0]+".png", row[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.db.Exec(`INSERT INTO screenshots (id, timestamp, screen_id, image_path, hour_key)
+		VALUES ('null', ?, 0, 'null.png', '2025-12-09-14')`, timestamp); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.migrateAnalysisStatus(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := s.GetScreenshotsByIDs([]string{"failed", "done", "pending"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id       string
+		status   string
+		analysis string
+		errMsg   string
+	}{
+		{"failed", AnalysisFailed, "", "status 500"},
+		{"done", AnalysisDone, "Editing bundle.go in VS Code", ""},
+		{"pending", AnalysisPending, "", ""},
+	}
+	for _, tt := range tests {
+		r := records[tt.id]
+		if r == nil {
+			t.Fatalf("screenshot %s not found", tt.id)
+		}
+		if r.AnalysisStatus != tt.status || r.Analysis != tt.analysis || r.AnalysisError != tt.errMsg {
+			t.Errorf("%s: status %q, analysis %q, error %q; want %q, %q, %q", tt.id,
+				r.AnalysisStatus, r.Analysis, r.AnalysisError, tt.status, tt.analysis, tt.errMsg)
+		}
+	}
+
+	var status string
+	if err := s.db.QueryRow(`SELECT analysis_status FROM screenshots WHERE id = 'null'`).Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != AnalysisPending {
+		t.Errorf("screenshot without analysis has status %q, want %q", status, AnalysisPending)
+	}
+}
+
+func TestGetLatestUnanalyzedScreenshots(t *testing.T) {
+	s, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	statuses := []string{AnalysisPending, AnalysisDone, AnalysisFailed, AnalysisSkipped, AnalysisPending, AnalysisPending}
+	for i, status := range statuses {
+		record := &ScreenshotRecord{ID: fmt.Sprintf("s%d", i), Timestamp: start.Add(time.Duration(i) * time.Minute),
+			ImagePath: fmt.Sprintf("%d.png", i), AnalysisStatus: status}
+		if status == AnalysisDone {
+			record.Analysis = "Editing sqlite.go"
+		}
+		record.GenerateHourKey()
+		if err := s.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		{10, []string{"s5", "s4", "s2", "s0"}},
+		{2, []string{"s5", "s4"}},
+	}
+	for _, tt := range tests {
+		records, err := s.GetLatestUnanalyzedScreenshots(tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLatestUnanalyzedScreenshots(%d) = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
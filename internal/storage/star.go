@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// StarredScreenshot is a screenshot the user starred, with an optional note
+type StarredScreenshot struct {
+	Screenshot *ScreenshotRecord
+	Note       string
+	StarredAt  time.Time
+}
+
+// StarStore stores starred screenshots (favorites)
+type StarStore interface {
+	// StarScreenshot stars a screenshot, updating the note if it is already starred
+	StarScreenshot(screenshotID, note string) error
+	// UnstarScreenshot removes the star from a screenshot
+	UnstarScreenshot(screenshotID string) error
+	// QueryStarred returns starred screenshots captured in [start, end), ordered by capture time
+	QueryStarred(start, end time.Time) ([]*StarredScreenshot, error)
+}
+
+func (s *SQLiteStorage) initStarTable() error {
+	createStarTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_stars (
+		screenshot_id TEXT PRIMARY KEY,
+		note TEXT,
+		starred_at DATETIME NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(createStarTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_stars table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) StarScreenshot(screenshotID, note string) error {
+	query := `
+	INSERT INTO screenshot_stars (screenshot_id, note, starred_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(screenshot_id) DO UPDATE SET note = excluded.note
+	`
+	if _, err := s.db.Exec(query, screenshotID, note, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to star screenshot: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) UnstarScreenshot(screenshotID string) error {
+	if _, err := s.db.Exec(`DELETE FROM screenshot_stars WHERE screenshot_id = ?`, screenshotID); err != nil {
+		return fmt.Errorf("failed to unstar screenshot: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryStarred(start, end time.Time) ([]*StarredScreenshot, error) {
+	query := `
+	SELECT s.id, s.timestamp, s.screen_id, s.image_path, s.analysis, s.hour_key, s.analysis_status, COALESCE(s.analysis_error, ''), COALESCE(s.app, ''), COALESCE(s.window_title, ''), COALESCE(st.note, ''), st.starred_at
+	FROM screenshot_stars st
+	JOIN screenshots s ON s.id = st.screenshot_id
+	WHERE s.timestamp >= ? AND s.timestamp < ?
+	ORDER BY s.timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query starred screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	var starred []*StarredScreenshot
+	for rows.Next() {
+		var r ScreenshotRecord
+		var star StarredScreenshot
+		var timestampStr, starredStr string
+		if err := rows.Scan(&r.ID, &timestampStr, &r.ScreenID, &r.ImagePath, &r.Analysis, &r.HourKey, &r.AnalysisStatus, &r.AnalysisError, &r.App, &r.WindowTitle, &star.Note, &starredStr); err != nil {
+			return nil, fmt.Errorf("failed to scan starred screenshot: %w", err)
+		}
+		if r.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		star.StarredAt, _ = time.Parse(time.RFC3339Nano, starredStr)
+		star.Screenshot = &r
+		starred = append(starred, &star)
+	}
+	return starred, rows.Err()
+}
+
+func (r *ReportStorage) StarScreenshot(screenshotID, note string) error {
+	return r.metadataStorage.StarScreenshot(screenshotID, note)
+}
+
+func (r *ReportStorage) UnstarScreenshot(screenshotID string) error {
+	return r.metadataStorage.UnstarScreenshot(screenshotID)
+}
+
+func (r *ReportStorage) QueryStarred(start, end time.Time) ([]*StarredScreenshot, error) {
+	return r.metadataStorage.QueryStarred(start, end)
+}
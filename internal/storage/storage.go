@@ -1,15 +1,18 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
 
-// StorageInterface defines the storage interface
-// Both SQLiteStorage and FileSystemStorage implement this interface
-type StorageInterface interface {
+// ReportFileStore is the screenshot and summary storage that can be backed by the report files alone
+// SQLiteStorage, ReportStorage and FileSystemStorage implement it
+type ReportFileStore interface {
 	SaveScreenshot(record *ScreenshotRecord) error
 	UpdateScreenshotAnalysis(id, analysis string) error
+	UpdateScreenshotAnalysisStatus(id, status, analysisError string) error
+	UpdateScreenshotAnalyses(updates []*ScreenshotAnalysisUpdate) error
 	GetScreenshotsByHourKey(hourKey string) ([]*ScreenshotRecord, error)
 	GetScreenshotsByIDs(ids []string) (map[string]*ScreenshotRecord, error)
 	GetHourSummary(hourKey string) (*HourSummary, error)
@@ -18,6 +21,7 @@ type StorageInterface interface {
 	QueryByDateRange(start, end time.Time) ([]*ScreenshotRecord, error)
 	QueryHourSummariesByDateRange(start, end time.Time) ([]*HourSummary, error)
 	GetUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error)
+	GetLatestUnanalyzedScreenshots(limit int) ([]*ScreenshotRecord, error)
 	SavePeriodSummary(summary *PeriodSummary) error
 	GetPeriodSummary(periodKey string) (*PeriodSummary, error)
 	DeletePeriodSummary(periodKey string) error
@@ -30,8 +34,55 @@ type StorageInterface interface {
 	RebuildFromDirectory(storagePath string, lockScreenDetector LockScreenDetector) (int, error)
 }
 
+// StorageInterface defines the storage interface
+// The feature stores live in the database only: SQLiteStorage and ReportStorage (which delegates them to
+// its SQLite metadata storage) implement it, FileSystemStorage only implements ReportFileStore
+type StorageInterface interface {
+	ReportFileStore
+
+	AuditStore
+	DeliverableStore
+	IssueStore
+	FocusStore
+	AttachmentStore
+	StarStore
+	WindowStore
+	SearchStore
+	TimeSyncStore
+	UploadStore
+	LocalOnlyStore
+	SourceStore
+	RemoteImageStore
+	RuntimeStateStore
+	WindowBoundsStore
+	GoldenStore
+	PeriodLockStore
+	ReportHashStore
+	MissingReportStore
+	APICallStore
+	TreePairStore
+	LineageStore
+	LockScreenCheckStore
+	ThreadStore
+	RowQueryStore
+	RouteStore
+	HealthStore
+	RemoteStore
+	OCRStore
+	BacklogStore
+	PlanStore
+	SpaceStore
+	PromptStore
+	WorkdayStore
+	GenerationStore
+	DataVersionStore
+}
+
+// ErrNoData is returned when a period or range has no data to work on (nothing to summarize, nothing captured)
+var ErrNoData = errors.New("no data")
+
 // Storage is a type alias for backward compatibility
-// It can be either *SQLiteStorage or *FileSystemStorage or *ReportStorage
+// It can be either *SQLiteStorage or *ReportStorage
 type Storage struct {
 	StorageInterface
 }
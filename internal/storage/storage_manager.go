@@ -27,6 +27,7 @@ type StorageManager struct {
 	config         *config.StorageConfig
 	pathCalculator *PathCalculator
 	basePath       string
+	chaos          *chaosDelay // 故障注入（仅 chaos 模式启用）
 }
 
 // NewStorageManager 创建存储管理器
@@ -55,6 +56,7 @@ func (sm *StorageManager) SaveScreenshot(timestamp time.Time, data []byte) (stri
 	}
 
 	// 写入文件
+	sm.chaos.sleep()
 	if err := os.WriteFile(fullPath, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to write screenshot: %w", err)
 	}
@@ -64,6 +66,7 @@ func (sm *StorageManager) SaveScreenshot(timestamp time.Time, data []byte) (stri
 
 // SaveReport 保存报告
 func (sm *StorageManager) SaveReport(timestamp time.Time, content string) (string, error) {
+	content = FormatMarkdown(content, sm.config.Markdown)
 	if !sm.config.EnableNestedStructure {
 		// 如果未启用嵌套结构，使用旧的平铺格式
 		return sm.saveLegacyReport(timestamp, content)
@@ -79,6 +82,7 @@ func (sm *StorageManager) SaveReport(timestamp time.Time, content string) (strin
 	}
 
 	// 写入文件
+	sm.chaos.sleep()
 	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write report: %w", err)
 	}
@@ -88,6 +92,7 @@ func (sm *StorageManager) SaveReport(timestamp time.Time, content string) (strin
 
 // SaveSummary 保存汇总
 func (sm *StorageManager) SaveSummary(timestamp time.Time, level SummaryLevel, content string) (string, error) {
+	content = FormatMarkdown(content, sm.config.Markdown)
 	if !sm.config.EnableNestedStructure {
 		// 如果未启用嵌套结构，使用旧的平铺格式
 		return sm.saveLegacySummary(timestamp, level, content)
@@ -124,6 +129,7 @@ func (sm *StorageManager) SaveSummary(timestamp time.Time, level SummaryLevel, c
 	}
 
 	// 写入文件
+	sm.chaos.sleep()
 	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write summary: %w", err)
 	}
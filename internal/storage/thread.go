@@ -0,0 +1,273 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// TaskThread is a named thread of related activity within a day (e.g. "PR #412 review"), built by
+// clustering the embeddings of the day's screenshot analyses
+type TaskThread struct {
+	ID            string
+	DayKey        string
+	Name          string
+	Minutes       float64
+	FirstSeen     time.Time
+	LastSeen      time.Time
+	ScreenshotIDs []string // Members, chronological
+
+	Open      bool      // Still in progress at the end of the day, carried into the next day's context
+	StartedOn string    // Day key the effort started on, earlier than DayKey for a thread continued from a previous day
+	Centroid  []float64 // Mean embedding of the members, used to match the thread on the next day
+}
+
+// ThreadStore stores task threads and the analysis embeddings they are clustered from
+type ThreadStore interface {
+	// ReplaceDayThreads replaces the threads of a day
+	ReplaceDayThreads(dayKey string, threads []*TaskThread) error
+	// QueryDayThreads returns the threads of a day, longest first
+	QueryDayThreads(dayKey string) ([]*TaskThread, error)
+	// QueryOpenThreads returns the open threads of the latest day with threads before dayKey,
+	// longest first and without members
+	QueryOpenThreads(beforeDayKey string) ([]*TaskThread, error)
+	// GetEmbeddings returns the stored embeddings of the given screenshots computed with model
+	GetEmbeddings(model string, screenshotIDs []string) (map[string][]float64, error)
+	SaveEmbeddings(model string, embeddings map[string][]float64) error
+}
+
+func (s *SQLiteStorage) initThreadTables() error {
+	createThreadTables := `
+	CREATE TABLE IF NOT EXISTS task_threads (
+		id TEXT PRIMARY KEY,
+		day_key TEXT NOT NULL,
+		name TEXT NOT NULL,
+		minutes REAL NOT NULL,
+		first_seen DATETIME NOT NULL,
+		last_seen DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_threads_day ON task_threads(day_key);
+
+	CREATE TABLE IF NOT EXISTS task_thread_members (
+		thread_id TEXT NOT NULL,
+		screenshot_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (thread_id, screenshot_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS analysis_embeddings (
+		screenshot_id TEXT NOT NULL,
+		model TEXT NOT NULL,
+		vector BLOB NOT NULL,
+		PRIMARY KEY (screenshot_id, model)
+	);
+	`
+	if _, err := s.db.Exec(createThreadTables); err != nil {
+		return fmt.Errorf("failed to create task thread tables: %w", err)
+	}
+	// Columns may already exist (new database or migrated before)
+	_, _ = s.db.Exec("ALTER TABLE task_threads ADD COLUMN open INTEGER NOT NULL DEFAULT 0")
+	_, _ = s.db.Exec("ALTER TABLE task_threads ADD COLUMN started_on TEXT")
+	_, _ = s.db.Exec("ALTER TABLE task_threads ADD COLUMN centroid BLOB")
+	return nil
+}
+
+func (s *SQLiteStorage) ReplaceDayThreads(dayKey string, threads []*TaskThread) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM task_thread_members WHERE thread_id IN (SELECT id FROM task_threads WHERE day_key = ?)`, dayKey); err != nil {
+		return fmt.Errorf("failed to delete task thread members: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM task_threads WHERE day_key = ?`, dayKey); err != nil {
+		return fmt.Errorf("failed to delete task threads: %w", err)
+	}
+
+	for _, t := range threads {
+		if t.ID == "" {
+			t.ID = generateID()
+		}
+		t.DayKey = dayKey
+		if t.StartedOn == "" {
+			t.StartedOn = dayKey
+		}
+		if _, err := tx.Exec(`INSERT INTO task_threads (id, day_key, name, minutes, first_seen, last_seen, open, started_on, centroid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.ID, t.DayKey, t.Name, t.Minutes, t.FirstSeen.Format(time.RFC3339Nano), t.LastSeen.Format(time.RFC3339Nano),
+			t.Open, t.StartedOn, encodeVector(t.Centroid)); err != nil {
+			return fmt.Errorf("failed to insert task thread: %w", err)
+		}
+		for i, id := range t.ScreenshotIDs {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO task_thread_members (thread_id, screenshot_id, position) VALUES (?, ?, ?)`,
+				t.ID, id, i); err != nil {
+				return fmt.Errorf("failed to insert task thread member: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit task threads: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryDayThreads(dayKey string) ([]*TaskThread, error) {
+	threads, err := s.queryThreads(`WHERE day_key = ?`, dayKey)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*TaskThread, len(threads))
+	for _, t := range threads {
+		byID[t.ID] = t
+	}
+
+	members, err := s.db.Query(`
+	SELECT m.thread_id, m.screenshot_id
+	FROM task_thread_members m JOIN task_threads t ON t.id = m.thread_id
+	WHERE t.day_key = ?
+	ORDER BY m.thread_id, m.position
+	`, dayKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task thread members: %w", err)
+	}
+	defer members.Close()
+	for members.Next() {
+		var threadID, screenshotID string
+		if err := members.Scan(&threadID, &screenshotID); err != nil {
+			return nil, fmt.Errorf("failed to scan task thread member: %w", err)
+		}
+		if t := byID[threadID]; t != nil {
+			t.ScreenshotIDs = append(t.ScreenshotIDs, screenshotID)
+		}
+	}
+	return threads, members.Err()
+}
+
+func (s *SQLiteStorage) QueryOpenThreads(beforeDayKey string) ([]*TaskThread, error) {
+	return s.queryThreads(`WHERE open = 1 AND day_key = (SELECT MAX(day_key) FROM task_threads WHERE day_key < ?)`, beforeDayKey)
+}
+
+// queryThreads returns the threads matching where, longest first and without members
+func (s *SQLiteStorage) queryThreads(where string, args ...any) ([]*TaskThread, error) {
+	rows, err := s.db.Query(`
+	SELECT id, day_key, name, minutes, first_seen, last_seen, open, COALESCE(started_on, day_key), centroid
+	FROM task_threads
+	`+where+`
+	ORDER BY minutes DESC, first_seen ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []*TaskThread
+	for rows.Next() {
+		t := &TaskThread{}
+		var firstStr, lastStr string
+		var centroid []byte
+		if err := rows.Scan(&t.ID, &t.DayKey, &t.Name, &t.Minutes, &firstStr, &lastStr, &t.Open, &t.StartedOn, &centroid); err != nil {
+			return nil, fmt.Errorf("failed to scan task thread: %w", err)
+		}
+		t.FirstSeen, _ = time.Parse(time.RFC3339Nano, firstStr)
+		t.LastSeen, _ = time.Parse(time.RFC3339Nano, lastStr)
+		if len(centroid) > 0 {
+			t.Centroid = decodeVector(centroid)
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+func (s *SQLiteStorage) GetEmbeddings(model string, screenshotIDs []string) (map[string][]float64, error) {
+	embeddings := make(map[string][]float64)
+	// Query in chunks to stay below SQLite's variable limit
+	for start := 0; start < len(screenshotIDs); start += 500 {
+		chunk := screenshotIDs[start:min(start+500, len(screenshotIDs))]
+		args := []any{model}
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		query := fmt.Sprintf(`SELECT screenshot_id, vector FROM analysis_embeddings WHERE model = ? AND screenshot_id IN (%s)`,
+			strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ","))
+		rows, err := s.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query embeddings: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			var blob []byte
+			if err := rows.Scan(&id, &blob); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan embedding: %w", err)
+			}
+			embeddings[id] = decodeVector(blob)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return embeddings, nil
+}
+
+func (s *SQLiteStorage) SaveEmbeddings(model string, embeddings map[string][]float64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for id, vector := range embeddings {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO analysis_embeddings (screenshot_id, model, vector) VALUES (?, ?, ?)`,
+			id, model, encodeVector(vector)); err != nil {
+			return fmt.Errorf("failed to save embedding: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit embeddings: %w", err)
+	}
+	return nil
+}
+
+// encodeVector stores a vector as little-endian float32, enough precision for similarity
+func encodeVector(vector []float64) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float64 {
+	vector := make([]float64, len(buf)/4)
+	for i := range vector {
+		vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+	}
+	return vector
+}
+
+func (r *ReportStorage) ReplaceDayThreads(dayKey string, threads []*TaskThread) error {
+	return r.metadataStorage.ReplaceDayThreads(dayKey, threads)
+}
+
+func (r *ReportStorage) QueryDayThreads(dayKey string) ([]*TaskThread, error) {
+	return r.metadataStorage.QueryDayThreads(dayKey)
+}
+
+func (r *ReportStorage) QueryOpenThreads(beforeDayKey string) ([]*TaskThread, error) {
+	return r.metadataStorage.QueryOpenThreads(beforeDayKey)
+}
+
+func (r *ReportStorage) GetEmbeddings(model string, screenshotIDs []string) (map[string][]float64, error) {
+	return r.metadataStorage.GetEmbeddings(model, screenshotIDs)
+}
+
+func (r *ReportStorage) SaveEmbeddings(model string, embeddings map[string][]float64) error {
+	return r.metadataStorage.SaveEmbeddings(model, embeddings)
+}
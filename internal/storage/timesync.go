@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TimeSyncMarker records a session already exported to a time tracker (Toggl/Clockify),
+// so repeated syncs never create duplicate time entries
+type TimeSyncMarker struct {
+	Provider   string    `db:"provider"`
+	EntryKey   string    `db:"entry_key"`   // Stable key of the exported session
+	ExternalID string    `db:"external_id"` // Time entry ID in the tracker
+	SyncedAt   time.Time `db:"synced_at"`
+}
+
+// TimeSyncStore stores time tracker sync markers
+type TimeSyncStore interface {
+	// GetTimeSyncMarker returns nil if the session has not been synced to the provider
+	GetTimeSyncMarker(provider, entryKey string) (*TimeSyncMarker, error)
+	SaveTimeSyncMarker(marker *TimeSyncMarker) error
+}
+
+func (s *SQLiteStorage) initTimeSyncTable() error {
+	createTimeSyncTable := `
+	CREATE TABLE IF NOT EXISTS time_sync_markers (
+		provider TEXT NOT NULL,
+		entry_key TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		synced_at DATETIME NOT NULL,
+		PRIMARY KEY (provider, entry_key)
+	);
+	`
+	if _, err := s.db.Exec(createTimeSyncTable); err != nil {
+		return fmt.Errorf("failed to create time_sync_markers table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetTimeSyncMarker(provider, entryKey string) (*TimeSyncMarker, error) {
+	query := `SELECT provider, entry_key, external_id, synced_at FROM time_sync_markers WHERE provider = ? AND entry_key = ?`
+	var marker TimeSyncMarker
+	var syncedAtStr string
+	err := s.db.QueryRow(query, provider, entryKey).Scan(&marker.Provider, &marker.EntryKey, &marker.ExternalID, &syncedAtStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time sync marker: %w", err)
+	}
+	marker.SyncedAt, _ = time.Parse(time.RFC3339Nano, syncedAtStr)
+	return &marker, nil
+}
+
+func (s *SQLiteStorage) SaveTimeSyncMarker(marker *TimeSyncMarker) error {
+	if marker.SyncedAt.IsZero() {
+		marker.SyncedAt = time.Now()
+	}
+	query := `
+	INSERT OR REPLACE INTO time_sync_markers (provider, entry_key, external_id, synced_at)
+	VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, marker.Provider, marker.EntryKey, marker.ExternalID, marker.SyncedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save time sync marker: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) GetTimeSyncMarker(provider, entryKey string) (*TimeSyncMarker, error) {
+	return r.metadataStorage.GetTimeSyncMarker(provider, entryKey)
+}
+
+func (r *ReportStorage) SaveTimeSyncMarker(marker *TimeSyncMarker) error {
+	return r.metadataStorage.SaveTimeSyncMarker(marker)
+}
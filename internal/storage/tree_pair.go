@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// TreePair is the combined output of one pair of a tree aggregation level, kept so an aggregation
+// interrupted by a crash resumes instead of redoing every API call
+type TreePair struct {
+	SessionID string
+	Level     int
+	Pair      int
+	InputHash string // Hash of the pair's inputs, a changed input invalidates the output
+	Output    string
+	CreatedAt time.Time
+}
+
+// TreePairStore stores intermediate tree aggregation results
+type TreePairStore interface {
+	SaveTreePair(pair *TreePair) error
+	// GetTreePairs returns the stored pairs of a session
+	GetTreePairs(sessionID string) ([]*TreePair, error)
+	DeleteTreeSession(sessionID string) error
+	// PruneTreePairs deletes pairs of sessions abandoned before the given time
+	PruneTreePairs(before time.Time) error
+}
+
+func (s *SQLiteStorage) initTreePairTable() error {
+	createTreePairTable := `
+	CREATE TABLE IF NOT EXISTS tree_aggregation_pairs (
+		session_id TEXT NOT NULL,
+		level INTEGER NOT NULL,
+		pair INTEGER NOT NULL,
+		input_hash TEXT NOT NULL,
+		output TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (session_id, level, pair)
+	);
+	`
+	if _, err := s.db.Exec(createTreePairTable); err != nil {
+		return fmt.Errorf("failed to create tree_aggregation_pairs table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveTreePair(pair *TreePair) error {
+	if pair.CreatedAt.IsZero() {
+		pair.CreatedAt = time.Now()
+	}
+	query := `
+	INSERT OR REPLACE INTO tree_aggregation_pairs (session_id, level, pair, input_hash, output, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, pair.SessionID, pair.Level, pair.Pair, pair.InputHash, pair.Output,
+		pair.CreatedAt.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to save tree aggregation pair: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) GetTreePairs(sessionID string) ([]*TreePair, error) {
+	query := `
+	SELECT session_id, level, pair, input_hash, output, created_at
+	FROM tree_aggregation_pairs
+	WHERE session_id = ?
+	ORDER BY level ASC, pair ASC
+	`
+	rows, err := s.db.Query(query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tree aggregation pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []*TreePair
+	for rows.Next() {
+		var p TreePair
+		var createdStr string
+		if err := rows.Scan(&p.SessionID, &p.Level, &p.Pair, &p.InputHash, &p.Output, &createdStr); err != nil {
+			return nil, fmt.Errorf("failed to scan tree aggregation pair: %w", err)
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		pairs = append(pairs, &p)
+	}
+	return pairs, rows.Err()
+}
+
+func (s *SQLiteStorage) DeleteTreeSession(sessionID string) error {
+	if _, err := s.db.Exec(`DELETE FROM tree_aggregation_pairs WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete tree aggregation session: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) PruneTreePairs(before time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM tree_aggregation_pairs WHERE created_at < ?`, before.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to prune tree aggregation pairs: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportStorage) SaveTreePair(pair *TreePair) error {
+	return r.metadataStorage.SaveTreePair(pair)
+}
+
+func (r *ReportStorage) GetTreePairs(sessionID string) ([]*TreePair, error) {
+	return r.metadataStorage.GetTreePairs(sessionID)
+}
+
+func (r *ReportStorage) DeleteTreeSession(sessionID string) error {
+	return r.metadataStorage.DeleteTreeSession(sessionID)
+}
+
+func (r *ReportStorage) PruneTreePairs(before time.Time) error {
+	return r.metadataStorage.PruneTreePairs(before)
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LLMUpload records one request sent to the LLM provider: which images and text left the machine, where and when
+type LLMUpload struct {
+	ID         string    `db:"id"`
+	Timestamp  time.Time `db:"timestamp"`
+	Endpoint   string    `db:"endpoint"` // Provider API endpoint
+	Model      string    `db:"model"`
+	Purpose    string    `db:"purpose"`     // e.g. "screenshot_analysis", "summary"
+	ImagePaths []string  `db:"image_paths"` // Stored newline-separated
+	Text       string    `db:"text"`
+}
+
+// UploadStore stores the LLM upload log
+type UploadStore interface {
+	AddLLMUpload(upload *LLMUpload) error
+	// QueryLLMUploads returns uploads in [start, end), oldest first
+	QueryLLMUploads(start, end time.Time) ([]*LLMUpload, error)
+}
+
+func (s *SQLiteStorage) initUploadTable() error {
+	createUploadTable := `
+	CREATE TABLE IF NOT EXISTS llm_uploads (
+		id TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		endpoint TEXT NOT NULL,
+		model TEXT,
+		purpose TEXT,
+		image_paths TEXT,
+		text TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_llm_uploads_timestamp ON llm_uploads(timestamp);
+	`
+	if _, err := s.db.Exec(createUploadTable); err != nil {
+		return fmt.Errorf("failed to create llm_uploads table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) AddLLMUpload(upload *LLMUpload) error {
+	if upload.ID == "" {
+		upload.ID = generateID()
+	}
+	if upload.Timestamp.IsZero() {
+		upload.Timestamp = time.Now()
+	}
+	query := `
+	INSERT INTO llm_uploads (id, timestamp, endpoint, model, purpose, image_paths, text)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, upload.ID, upload.Timestamp.Format(time.RFC3339Nano), upload.Endpoint,
+		upload.Model, upload.Purpose, strings.Join(upload.ImagePaths, "\n"), upload.Text); err != nil {
+		return fmt.Errorf("failed to add LLM upload: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryLLMUploads(start, end time.Time) ([]*LLMUpload, error) {
+	query := `
+	SELECT id, timestamp, endpoint, COALESCE(model, ''), COALESCE(purpose, ''), COALESCE(image_paths, ''), COALESCE(text, '')
+	FROM llm_uploads
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var uploads []*LLMUpload
+	for rows.Next() {
+		var upload LLMUpload
+		var timestampStr, imagePaths string
+		if err := rows.Scan(&upload.ID, &timestampStr, &upload.Endpoint, &upload.Model, &upload.Purpose, &imagePaths, &upload.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM upload: %w", err)
+		}
+		upload.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		if imagePaths != "" {
+			upload.ImagePaths = strings.Split(imagePaths, "\n")
+		}
+		uploads = append(uploads, &upload)
+	}
+	return uploads, rows.Err()
+}
+
+func (r *ReportStorage) AddLLMUpload(upload *LLMUpload) error {
+	return r.metadataStorage.AddLLMUpload(upload)
+}
+
+func (r *ReportStorage) QueryLLMUploads(start, end time.Time) ([]*LLMUpload, error) {
+	return r.metadataStorage.QueryLLMUploads(start, end)
+}
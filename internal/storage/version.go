@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// versionedTables are the tables the HTTP API serves cached responses from (timelines, summaries, reports)
+// Triggers bump the data version on every change, so a cache in one process sees writes made by another
+// (daemon, generate command). A table read by a cached endpoint must be listed here
+var versionedTables = []string{
+	"screenshots",
+	"screenshot_windows",
+	"period_summaries",
+	"period_attachments",
+}
+
+// DataVersionStore exposes the data version
+type DataVersionStore interface {
+	// DataVersion returns a counter that changes whenever a versioned table changes
+	DataVersion() (int64, error)
+}
+
+func (s *SQLiteStorage) initDataVersionTable() error {
+	createDataVersionTable := `
+	CREATE TABLE IF NOT EXISTS data_version (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL
+	);
+	INSERT OR IGNORE INTO data_version (id, version) VALUES (1, 0);
+	`
+	if _, err := s.db.Exec(createDataVersionTable); err != nil {
+		return fmt.Errorf("failed to create data_version table: %w", err)
+	}
+	for _, table := range versionedTables {
+		for _, op := range []string{"INSERT", "UPDATE", "DELETE"} {
+			trigger := fmt.Sprintf(`
+			CREATE TRIGGER IF NOT EXISTS %s_%s_version AFTER %s ON %s
+			BEGIN
+				UPDATE data_version SET version = version + 1 WHERE id = 1;
+			END;
+			`, table, op, op, table)
+			if _, err := s.db.Exec(trigger); err != nil {
+				return fmt.Errorf("failed to create data version trigger on %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) DataVersion() (int64, error) {
+	var version int64
+	if err := s.db.QueryRow(`SELECT version FROM data_version WHERE id = 1`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get data version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *ReportStorage) DataVersion() (int64, error) {
+	return r.metadataStorage.DataVersion()
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScreenshotWindow is the IDE/terminal window in front when a screenshot was captured,
+// attributed to a repository, monorepo sub-project and branch
+type ScreenshotWindow struct {
+	ScreenshotID string    `db:"screenshot_id"`
+	Timestamp    time.Time `db:"timestamp"`
+	App          string    `db:"app"`
+	Title        string    `db:"title"`
+	Repo         string    `db:"repo"`
+	Subproject   string    `db:"subproject"`
+	Branch       string    `db:"branch"`
+}
+
+// WindowStore stores window attributions of screenshots
+type WindowStore interface {
+	// SaveScreenshotWindow saves the window attribution of a screenshot
+	SaveScreenshotWindow(window *ScreenshotWindow) error
+	// QueryScreenshotWindows returns window attributions of screenshots captured in [start, end)
+	QueryScreenshotWindows(start, end time.Time) ([]*ScreenshotWindow, error)
+}
+
+func (s *SQLiteStorage) initWindowTable() error {
+	createWindowTable := `
+	CREATE TABLE IF NOT EXISTS screenshot_windows (
+		screenshot_id TEXT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		app TEXT,
+		title TEXT,
+		repo TEXT NOT NULL,
+		subproject TEXT,
+		branch TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_screenshot_windows_timestamp ON screenshot_windows(timestamp);
+	`
+	if _, err := s.db.Exec(createWindowTable); err != nil {
+		return fmt.Errorf("failed to create screenshot_windows table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveScreenshotWindow(window *ScreenshotWindow) error {
+	query := `
+	INSERT OR REPLACE INTO screenshot_windows (screenshot_id, timestamp, app, title, repo, subproject, branch)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.Exec(query, window.ScreenshotID, window.Timestamp.Format(time.RFC3339Nano),
+		window.App, window.Title, window.Repo, window.Subproject, window.Branch); err != nil {
+		return fmt.Errorf("failed to save screenshot window: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryScreenshotWindows(start, end time.Time) ([]*ScreenshotWindow, error) {
+	query := `
+	SELECT screenshot_id, timestamp, COALESCE(app, ''), COALESCE(title, ''), repo, COALESCE(subproject, ''), COALESCE(branch, '')
+	FROM screenshot_windows
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*ScreenshotWindow
+	for rows.Next() {
+		var w ScreenshotWindow
+		var timestampStr string
+		if err := rows.Scan(&w.ScreenshotID, &timestampStr, &w.App, &w.Title, &w.Repo, &w.Subproject, &w.Branch); err != nil {
+			return nil, fmt.Errorf("failed to scan screenshot window: %w", err)
+		}
+		if w.Timestamp, err = time.Parse(time.RFC3339Nano, timestampStr); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		windows = append(windows, &w)
+	}
+	return windows, rows.Err()
+}
+
+func (r *ReportStorage) SaveScreenshotWindow(window *ScreenshotWindow) error {
+	return r.metadataStorage.SaveScreenshotWindow(window)
+}
+
+func (r *ReportStorage) QueryScreenshotWindows(start, end time.Time) ([]*ScreenshotWindow, error) {
+	return r.metadataStorage.QueryScreenshotWindows(start, end)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Workday is the detected start and end of a day's work: its first and last non-idle capture
+type Workday struct {
+	Date      time.Time // Day start
+	Start     time.Time // First non-idle capture
+	End       time.Time // Last non-idle capture
+	Captures  int       // Non-idle captures of the day
+	UpdatedAt time.Time
+}
+
+// Duration is the time from the first to the last non-idle capture
+func (w *Workday) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// WorkdayStore stores the detected workday boundaries of each day
+type WorkdayStore interface {
+	// SaveWorkday replaces the stored boundaries of the day
+	SaveWorkday(workday *Workday) error
+	// QueryWorkdays returns the workdays of days in [start, end), ordered by date
+	QueryWorkdays(start, end time.Time) ([]*Workday, error)
+}
+
+func (s *SQLiteStorage) initWorkdayTable() error {
+	createWorkdayTable := `
+	CREATE TABLE IF NOT EXISTS workdays (
+		date_key TEXT PRIMARY KEY,
+		date DATETIME NOT NULL,
+		start_time DATETIME NOT NULL,
+		end_time DATETIME NOT NULL,
+		captures INTEGER NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_workdays_date ON workdays(date);
+	`
+	if _, err := s.db.Exec(createWorkdayTable); err != nil {
+		return fmt.Errorf("failed to create workdays table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) SaveWorkday(workday *Workday) error {
+	if workday.UpdatedAt.IsZero() {
+		workday.UpdatedAt = time.Now()
+	}
+	query := `
+	INSERT OR REPLACE INTO workdays (date_key, date, start_time, end_time, captures, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := s.db.Exec(query,
+		workday.Date.Format("2006-01-02"),
+		workday.Date.Format(time.RFC3339Nano),
+		workday.Start.Format(time.RFC3339Nano),
+		workday.End.Format(time.RFC3339Nano),
+		workday.Captures,
+		workday.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save workday: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) QueryWorkdays(start, end time.Time) ([]*Workday, error) {
+	query := `
+	SELECT date, start_time, end_time, captures, updated_at
+	FROM workdays
+	WHERE date >= ? AND date < ?
+	ORDER BY date ASC
+	`
+	rows, err := s.db.Query(query, start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workdays: %w", err)
+	}
+	defer rows.Close()
+
+	var workdays []*Workday
+	for rows.Next() {
+		var w Workday
+		var dateStr, startStr, endStr, updatedAtStr string
+		if err := rows.Scan(&dateStr, &startStr, &endStr, &w.Captures, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan workday: %w", err)
+		}
+		if w.Date, err = time.Parse(time.RFC3339Nano, dateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse date: %w", err)
+		}
+		if w.Start, err = time.Parse(time.RFC3339Nano, startStr); err != nil {
+			return nil, fmt.Errorf("failed to parse workday start: %w", err)
+		}
+		if w.End, err = time.Parse(time.RFC3339Nano, endStr); err != nil {
+			return nil, fmt.Errorf("failed to parse workday end: %w", err)
+		}
+		w.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAtStr)
+		workdays = append(workdays, &w)
+	}
+	return workdays, rows.Err()
+}
+
+func (r *ReportStorage) SaveWorkday(workday *Workday) error {
+	return r.metadataStorage.SaveWorkday(workday)
+}
+
+func (r *ReportStorage) QueryWorkdays(start, end time.Time) ([]*Workday, error) {
+	return r.metadataStorage.QueryWorkdays(start, end)
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stuff-time/internal/activitywatch"
+	"stuff-time/internal/breaks"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+// ActivityWatchExport builds ActivityWatch buckets from the captures in [start, end): window
+// events from the app and title recorded at capture time, and AFK status from the breaks
+// Lock screen and desktop captures are left out of the window events and count as away
+func ActivityWatchExport(cfg *config.Config, st *storage.Storage, start, end time.Time, hostname string) (*activitywatch.Export, error) {
+	screenshots, err := st.QueryByDateRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	sort.Slice(screenshots, func(i, j int) bool {
+		return screenshots[i].Timestamp.Before(screenshots[j].Timestamp)
+	})
+
+	windows, err := st.QueryScreenshotWindows(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+	}
+	byID := make(map[string]*storage.ScreenshotWindow, len(windows))
+	for _, w := range windows {
+		byID[w.ScreenshotID] = w
+	}
+
+	var samples []activitywatch.Sample
+	idle := make([]breaks.Sample, 0, len(screenshots))
+	for _, s := range screenshots {
+		away := s.AnalysisStatus == storage.AnalysisSkipped || (s.HasAnalysis() && isDesktopOrLockScreenAnalysis(s.Analysis))
+		idle = append(idle, breaks.Sample{Time: s.Timestamp, Idle: away})
+		if away {
+			continue
+		}
+		sample := activitywatch.Sample{Time: s.Timestamp}
+		if w := byID[s.ID]; w != nil {
+			sample.App, sample.Title = w.App, w.Title
+		}
+		samples = append(samples, sample)
+	}
+
+	interval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+
+	// Every gap between work stretches is away time, work hours don't matter here
+	stats := breaks.Detect(idle, interval, cfg.Breaks.GetMinBreakDuration(), nil)
+	var afk []*activitywatch.Event
+	for i, stretch := range stats.Stretches {
+		afk = append(afk, activitywatch.AFKEvent(stretch.Start, stretch.End, false))
+		if i < len(stats.Breaks) {
+			afk = append(afk, activitywatch.AFKEvent(stats.Breaks[i].Start, stats.Breaks[i].End, true))
+		}
+	}
+
+	return activitywatch.NewExport(hostname, activitywatch.WindowEvents(samples, interval, 2*interval), afk), nil
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"stuff-time/internal/agent"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// IngestAgentScreenshot stores a screenshot pushed by a remote agent, so it is analyzed and
+// summarized together with the screenshots captured on this machine
+func (e *Executor) IngestAgentScreenshot(meta *agent.Metadata, image io.Reader) error {
+	timestamp := meta.Timestamp.Local()
+	if !e.config.Screenshot.WorkHours.IsWorkTime(timestamp) && !e.config.Personal.Enabled {
+		logger.GetLogger().Infof("Dropping screenshot from agent %s outside work hours", meta.Agent)
+		return nil
+	}
+	if e.config.IsEmbargoed(timestamp) {
+		logger.GetLogger().Infof("Dropping screenshot from agent %s taken in an embargo window", meta.Agent)
+		return nil
+	}
+
+	imagePath, err := agentImagePath(e.config.Screenshot.StoragePath, meta.Agent, timestamp, meta.ScreenID, meta.Format)
+	if err != nil {
+		return err
+	}
+	if err := writeAgentImage(imagePath, image); err != nil {
+		return err
+	}
+
+	record := storage.NewScreenshotRecord(meta.ScreenID, imagePath)
+	record.Timestamp = timestamp
+	record.GenerateHourKey()
+	if e.config.Screenshot.WindowContext {
+		record.App, record.WindowTitle = meta.App, meta.Title
+	}
+	if err := e.storage.SaveScreenshot(record); err != nil {
+		os.Remove(imagePath)
+		return fmt.Errorf("failed to save screenshot record: %w", err)
+	}
+	e.recordRuntimeState(storage.RuntimeLastCapture, record.ID)
+	e.uploadImage(record)
+
+	source := &storage.ScreenshotSource{ImagePath: imagePath, Agent: meta.Agent, ReceivedAt: time.Now()}
+	if err := e.storage.SetScreenshotSource(source); err != nil {
+		logger.GetLogger().Warnf("Failed to record source of screenshot %s: %v", record.ID, err)
+	}
+	if meta.App != "" {
+		e.recordWindow(record, meta.App, meta.Title)
+		e.recordLocalOnly(record, meta.App, meta.Title)
+		e.recordRoute(record, meta.App, meta.Title)
+	}
+
+	logger.GetLogger().Infof("Screenshot received from agent %s: %s (screen %d, path: %s)",
+		meta.Agent, record.ID, meta.ScreenID, imagePath)
+	events.Emit(events.LevelInfo, events.ComponentCapture, "Screenshot received from agent %s: %s", meta.Agent, record.ID)
+	return nil
+}
+
+// agentImagePath places agent screenshots under <storage>/agents/<agent>/, using the same
+// YYYY/QN/MM/WN/DD/HH layout as local captures
+func agentImagePath(storagePath, agentName string, t time.Time, screenID int, format string) (string, error) {
+	if storagePath == "" {
+		return "", fmt.Errorf("screenshot.storage_path is not configured")
+	}
+	quarter := fmt.Sprintf("Q%d", (int(t.Month())-1)/3+1)
+	week := fmt.Sprintf("W%d", (t.Day()-1)/7+1)
+	dir := filepath.Join(storagePath, "agents", agentName,
+		t.Format("2006"), quarter, t.Format("01"), week, t.Format("02"), t.Format("15"))
+	return filepath.Join(dir, fmt.Sprintf("%s-s%d.%s", t.Format("04-05"), screenID, format)), nil
+}
+
+func writeAgentImage(path string, image io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(file, image); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package task
+
+import (
+	"sync"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+)
+
+// appIntervalSlack absorbs scheduler jitter, so a capture due on this tick is not pushed to the next one
+const appIntervalSlack = 5 * time.Second
+
+// captureThrottle remembers when the last capture was taken, for per-app capture intervals
+type captureThrottle struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// due reports whether a capture is due at now with the given interval, and records it if so
+func (t *captureThrottle) due(now time.Time, interval time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.last.IsZero() && now.Sub(t.last)+appIntervalSlack < interval {
+		return false
+	}
+	t.last = now
+	return true
+}
+
+// skipForAppInterval reports whether this capture is skipped because the foreground app is captured
+// less often (screenshot.app_intervals); the scheduler ticks at the shortest configured interval
+func (e *Executor) skipForAppInterval(now time.Time) bool {
+	if len(e.config.Screenshot.AppIntervals) == 0 {
+		return false
+	}
+	app, _, err := screenshot.FrontmostWindow()
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get frontmost app for capture interval: %v", err)
+		app = ""
+	}
+	interval := e.config.Screenshot.CaptureInterval(app)
+	if e.captures.due(now, interval) {
+		return false
+	}
+	if rule := e.config.Screenshot.MatchAppInterval(app); rule != nil {
+		logger.GetLogger().Debugf("%s is in front (%s), captured every %s, skipping screenshot capture", app, rule.Name, rule.Interval)
+	} else {
+		logger.GetLogger().Debugf("Captured less than %s ago, skipping screenshot capture", interval)
+	}
+	return true
+}
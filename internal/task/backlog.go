@@ -0,0 +1,151 @@
+package task
+
+import (
+	"strconv"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// analysisBatchSize is the number of screenshots a batch analysis takes from the backlog
+const analysisBatchSize = 100
+
+// Backlog trend and latency windows
+const (
+	backlogTrendWindow   = 6 * time.Hour
+	backlogLatencyWindow = 24 * time.Hour
+)
+
+// maxProjectedBatches bounds the catch-up projection; longer backlogs are reported as not catching up
+const maxProjectedBatches = 10000
+
+// BacklogStatus is the unanalyzed screenshot backlog with its projected catch-up time
+type BacklogStatus struct {
+	Pending   int
+	Workers   int
+	Scaled    bool                     // Workers were last chosen by screenshot.worker_scaling
+	Latency   time.Duration            // Average latency of a screenshot analysis request, 0 when unknown
+	Interval  time.Duration            // Analysis batch interval, 0 under analysis_cron
+	Inflow    float64                  // Screenshots captured per hour at the moment
+	ETA       time.Duration            // Projected time until the backlog is analyzed
+	CatchesUp bool                     // False when the backlog grows faster than it is analyzed, or the latency is unknown
+	Trend     float64                  // Backlog change per hour over the last hours, negative when shrinking
+	Samples   []*storage.BacklogSample // Backlog sizes recorded before each batch analysis
+}
+
+// recordBacklog records the backlog size before a batch analysis and returns it; failures are only logged
+func (e *Executor) recordBacklog() int {
+	pending, err := e.storage.CountUnanalyzedScreenshots()
+	if err == nil {
+		err = e.storage.AddBacklogSample(&storage.BacklogSample{Pending: pending})
+	}
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to record analysis backlog: %v", err)
+	}
+	return pending
+}
+
+// Backlog returns the unanalyzed screenshot backlog, its trend over the last hours and the catch-up time
+// projected from the worker count and the average latency of recent analysis requests
+// The worker count is screenshot.analysis_workers, or the last one chosen by screenshot.worker_scaling
+func Backlog(cfg *config.Config, st *storage.Storage, now time.Time) (*BacklogStatus, error) {
+	pending, err := st.CountUnanalyzedScreenshots()
+	if err != nil {
+		return nil, err
+	}
+	status := &BacklogStatus{Pending: pending, Workers: cfg.Screenshot.AnalysisWorkers}
+	if status.Workers <= 0 {
+		status.Workers = 3 // Same default as the batch analysis
+	}
+	if cfg.Screenshot.WorkerScaling.Enabled {
+		states, err := st.GetRuntimeStates()
+		if err != nil {
+			return nil, err
+		}
+		if state := states[storage.RuntimeAnalysisWorkers]; state != nil {
+			if workers, err := strconv.Atoi(state.Value); err == nil && workers > 0 {
+				status.Workers, status.Scaled = workers, true
+			}
+		}
+	}
+	if cfg.Screenshot.AnalysisCron == "" {
+		status.Interval, _ = cfg.Screenshot.GetAnalysisIntervalDuration()
+	}
+	if interval, err := cfg.Screenshot.GetIntervalDuration(); err == nil && interval > 0 && cfg.Screenshot.Cron == "" &&
+		(cfg.Screenshot.WorkHours.IsWorkTime(now) || cfg.Personal.Enabled) {
+		status.Inflow = float64(time.Hour) / float64(interval)
+	}
+
+	calls, err := st.QueryAPICalls(now.Add(-backlogLatencyWindow), now)
+	if err != nil {
+		return nil, err
+	}
+	var total time.Duration
+	var count int
+	for _, call := range calls {
+		if call.Purpose == analyzer.PurposeScreenshotAnalysis && call.Error == "" && call.LatencyMs > 0 {
+			total += time.Duration(call.LatencyMs) * time.Millisecond
+			count++
+		}
+	}
+	if count > 0 {
+		status.Latency = total / time.Duration(count)
+	}
+
+	if status.Samples, err = st.QueryBacklogSamples(now.Add(-backlogTrendWindow), now.Add(time.Second)); err != nil {
+		return nil, err
+	}
+	if n := len(status.Samples); n >= 2 {
+		first, last := status.Samples[0], status.Samples[n-1]
+		if hours := last.Timestamp.Sub(first.Timestamp).Hours(); hours > 0 {
+			status.Trend = float64(last.Pending-first.Pending) / hours
+		}
+	}
+
+	status.ETA, status.CatchesUp = projectCatchUp(pending, status.Workers, status.Latency, status.Interval, status.Inflow)
+	return status, nil
+}
+
+// projectCatchUp projects how long batch analyses take to empty a backlog of pending screenshots
+// Each batch takes up to analysisBatchSize screenshots and analyzes them with workers in parallel; the
+// next batch starts at the first interval tick after the previous one finished, while inflow screenshots
+// per hour keep arriving. Returns false when the backlog never empties or the latency is unknown
+func projectCatchUp(pending, workers int, latency, interval time.Duration, inflow float64) (time.Duration, bool) {
+	if pending <= 0 {
+		return 0, true
+	}
+	if latency <= 0 || workers <= 0 {
+		return 0, false
+	}
+
+	var eta time.Duration
+	remaining := float64(pending)
+	for i := 0; i < maxProjectedBatches; i++ {
+		n := min(int(remaining+0.5), analysisBatchSize)
+		w := min(workers, n)
+		duration := time.Duration((n+w-1)/w) * latency
+		if float64(n) >= remaining {
+			return eta + duration, true
+		}
+
+		period := duration
+		if interval > 0 {
+			period = interval * ((duration + interval - 1) / interval)
+		}
+		arrived := inflow * period.Hours()
+		if arrived >= float64(n) {
+			return 0, false
+		}
+		remaining += arrived - float64(n)
+		eta += period
+	}
+	return 0, false
+}
+
+// ETAWith projects the catch-up time with a different number of analysis workers
+func (b *BacklogStatus) ETAWith(workers int) (time.Duration, bool) {
+	return projectCatchUp(b.Pending, workers, b.Latency, b.Interval, b.Inflow)
+}
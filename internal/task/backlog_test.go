@@ -0,0 +1,35 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectCatchUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		pending  int
+		workers  int
+		latency  time.Duration
+		interval time.Duration
+		inflow   float64
+		want     time.Duration
+		catches  bool
+	}{
+		{"empty", 0, 3, 5 * time.Second, 30 * time.Minute, 0, 0, true},
+		{"single batch", 30, 3, 6 * time.Second, 30 * time.Minute, 0, time.Minute, true},
+		{"batches wait for the interval", 250, 5, 6 * time.Second, 30 * time.Minute, 0, time.Hour + time.Minute, true},
+		{"inflow refills the backlog", 150, 5, 6 * time.Second, 30 * time.Minute, 60, 30*time.Minute + 96*time.Second, true},
+		{"slow batches skip ticks", 200, 1, 20 * time.Second, 30 * time.Minute, 0, time.Hour + 2000*time.Second, true},
+		{"captures outpace analysis", 500, 3, 6 * time.Second, 30 * time.Minute, 240, 0, false},
+		{"unknown latency", 10, 3, 0, 30 * time.Minute, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, catches := projectCatchUp(tt.pending, tt.workers, tt.latency, tt.interval, tt.inflow)
+			if got != tt.want || catches != tt.catches {
+				t.Errorf("projectCatchUp() = %s, %v, want %s, %v", got, catches, tt.want, tt.catches)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"stuff-time/internal/breaks"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/notify"
+	"stuff-time/internal/storage"
+)
+
+// breakMonitor tracks the continuous work stretch from captures to remind the user to take a break
+// Captures are skipped while the screen is locked, so a gap between captures is a break
+type breakMonitor struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	minBreak    time.Duration
+	notifyAfter time.Duration // 0 disables the reminder
+	start       time.Time     // Start of the current stretch
+	last        time.Time     // Last capture
+	notified    bool          // Reminder already sent for the current stretch
+}
+
+// captured records a work capture at t and returns the length of the current stretch when it
+// newly reached the reminder threshold, 0 otherwise
+func (m *breakMonitor) captured(t time.Time) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.start.IsZero() || t.Sub(m.last)-m.interval >= m.minBreak {
+		m.start = t
+		m.notified = false
+	}
+	m.last = t
+	stretch := t.Sub(m.start) + m.interval
+	if m.notifyAfter <= 0 || m.notified || stretch < m.notifyAfter {
+		return 0
+	}
+	m.notified = true
+	return stretch
+}
+
+func newBreakMonitor(interval, minBreak, notifyAfter time.Duration) *breakMonitor {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &breakMonitor{interval: interval, minBreak: minBreak, notifyAfter: notifyAfter}
+}
+
+// onWorkCaptured sends a break reminder once the continuous work stretch reaches breaks.notify_after
+func (e *Executor) onWorkCaptured(t time.Time) {
+	if e.breaks == nil {
+		return
+	}
+	stretch := e.breaks.captured(t)
+	if stretch == 0 {
+		return
+	}
+
+	logger.GetLogger().Infof("Worked %s without a break, sending reminder", formatMinutes(stretch.Minutes()))
+	events.Emit(events.LevelInfo, events.ComponentCapture, "Break reminder: %s of continuous work", formatMinutes(stretch.Minutes()))
+	if err := notify.Send("Stuff Time", fmt.Sprintf("已连续工作 %s，起身活动一下吧", formatMinutes(stretch.Minutes()))); err != nil {
+		logger.GetLogger().Warnf("Failed to show break reminder: %v", err)
+	}
+}
+
+// dayBreakStats computes the break cadence of the day's work screenshots
+// Lock screen and desktop captures count as idle, failed or pending analyses as work
+func (e *Executor) dayBreakStats(start, end time.Time) (*breaks.Stats, error) {
+	screenshots, err := e.storage.QueryByDateRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	screenshots = e.workScreenshots(screenshots)
+
+	samples := make([]breaks.Sample, 0, len(screenshots))
+	for _, s := range screenshots {
+		samples = append(samples, breaks.Sample{Time: s.Timestamp, Idle: s.HasAnalysis() && isDesktopOrLockScreenAnalysis(s.Analysis)})
+	}
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	return breaks.Detect(samples, interval, e.config.Breaks.GetMinBreakDuration(), e.config.Screenshot.WorkHours.IsWorkTime), nil
+}
+
+// formatBreaksSection renders the break statistics of a day, empty without work
+func formatBreaksSection(stats *breaks.Stats) string {
+	if stats == nil || len(stats.Stretches) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 休息\n\n")
+	sb.WriteString(fmt.Sprintf("- **休息次数**：%d 次，共 %s\n", len(stats.Breaks), formatMinutes(stats.BreakTime.Minutes())))
+	longest := stats.LongestStretch
+	sb.WriteString(fmt.Sprintf("- **最长连续工作**：%s（%s-%s）\n", formatMinutes(longest.Duration().Minutes()),
+		longest.Start.Format("15:04"), longest.End.Format("15:04")))
+	sb.WriteString(fmt.Sprintf("- **平均连续工作**：%s\n", formatMinutes(stats.AverageStretch().Minutes())))
+	if len(stats.Breaks) > 0 {
+		spans := make([]string, 0, len(stats.Breaks))
+		for _, b := range stats.Breaks {
+			spans = append(spans, fmt.Sprintf("%s-%s（%s）", b.Start.Format("15:04"), b.End.Format("15:04"), formatMinutes(b.Duration().Minutes())))
+		}
+		sb.WriteString(fmt.Sprintf("- **休息时段**：%s\n", strings.Join(spans, "、")))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// breaksSection renders the break statistics of a day summary
+func (e *Executor) breaksSection(summary *storage.PeriodSummary) (string, error) {
+	stats, err := e.dayBreakStats(summary.StartTime, summary.EndTime)
+	if err != nil {
+		return "", err
+	}
+	return formatBreaksSection(stats), nil
+}
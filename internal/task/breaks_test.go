@@ -0,0 +1,29 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakMonitorRemindsOncePerStretch(t *testing.T) {
+	m := newBreakMonitor(time.Minute, 5*time.Minute, 30*time.Minute)
+	base := time.Date(2025, 12, 9, 9, 0, 0, 0, time.Local)
+
+	var reminders []time.Duration
+	capture := func(from, to int) {
+		for minute := from; minute < to; minute++ {
+			if stretch := m.captured(base.Add(time.Duration(minute) * time.Minute)); stretch > 0 {
+				reminders = append(reminders, stretch)
+			}
+		}
+	}
+	capture(0, 45)
+	// A 3 minute gap is not a break, the stretch continues without a second reminder
+	capture(48, 60)
+	// A 10 minute break starts a new stretch
+	capture(70, 100)
+
+	if len(reminders) != 2 || reminders[0] != 30*time.Minute || reminders[1] != 30*time.Minute {
+		t.Errorf("reminders = %v, want two after 30m of work", reminders)
+	}
+}
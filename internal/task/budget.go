@@ -0,0 +1,114 @@
+package task
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/costs"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/notify"
+	"stuff-time/internal/storage"
+)
+
+// BudgetStatus is the LLM spending of the current day and month against performance.budget
+type BudgetStatus struct {
+	DayTokens   int
+	MonthTokens int
+	DayCost     float64
+	MonthCost   float64
+	Exceeded    string    // The exceeded limit, e.g. "daily tokens 120000/100000", empty within budget
+	ResumeAt    time.Time // Start of the next day or month, when work resumes; zero within budget
+}
+
+// CheckBudget sums the LLM calls of the current day (from storage.day_start_hour) and calendar month
+// and compares them with performance.budget
+func CheckBudget(cfg *config.Config, st *storage.Storage, now time.Time) (*BudgetStatus, error) {
+	dayStart := cfg.Storage.DayStart(now)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	prices := modelPrices(cfg)
+	day, err := st.SumAPICallUsage(dayStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum API calls: %w", err)
+	}
+	month, err := st.SumAPICallUsage(monthStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum API calls: %w", err)
+	}
+	return budgetStatus(&cfg.Performance.Budget, costs.Usage(day, prices), costs.Usage(month, prices), dayStart, monthStart), nil
+}
+
+// budgetStatus compares the usage since dayStart and monthStart with the budget
+// The monthly limits are checked first, since they defer work the longest
+func budgetStatus(budget *config.BudgetConfig, dayTotal, monthTotal *costs.Row, dayStart, monthStart time.Time) *BudgetStatus {
+	status := &BudgetStatus{
+		DayTokens:   dayTotal.Tokens(),
+		MonthTokens: monthTotal.Tokens(),
+		DayCost:     dayTotal.Cost,
+		MonthCost:   monthTotal.Cost,
+	}
+
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	nextDay := dayStart.AddDate(0, 0, 1)
+	switch {
+	case budget.MonthlyTokens > 0 && status.MonthTokens >= budget.MonthlyTokens:
+		status.Exceeded = fmt.Sprintf("monthly tokens %d/%d", status.MonthTokens, budget.MonthlyTokens)
+		status.ResumeAt = nextMonth
+	case budget.MonthlyUSD > 0 && status.MonthCost >= budget.MonthlyUSD:
+		status.Exceeded = fmt.Sprintf("monthly cost $%.2f/$%.2f", status.MonthCost, budget.MonthlyUSD)
+		status.ResumeAt = nextMonth
+	case budget.DailyTokens > 0 && status.DayTokens >= budget.DailyTokens:
+		status.Exceeded = fmt.Sprintf("daily tokens %d/%d", status.DayTokens, budget.DailyTokens)
+		status.ResumeAt = nextDay
+	case budget.DailyUSD > 0 && status.DayCost >= budget.DailyUSD:
+		status.Exceeded = fmt.Sprintf("daily cost $%.2f/$%.2f", status.DayCost, budget.DailyUSD)
+		status.ResumeAt = nextDay
+	}
+	return status
+}
+
+// budgetMonitor remembers the period whose budget was exceeded, so the alert is raised once per period
+type budgetMonitor struct {
+	mu       sync.Mutex
+	resumeAt time.Time
+}
+
+// overBudget reports whether performance.budget is exceeded, in which case the caller defers work until
+// the next day or month. The budget is not enforced when the spending cannot be read
+func (e *Executor) overBudget(work string) bool {
+	if !e.config.Performance.Budget.Enabled() {
+		return false
+	}
+	status, err := CheckBudget(e.config, e.storage, time.Now())
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to check the LLM budget, not enforcing it: %v", err)
+		return false
+	}
+
+	e.budget.mu.Lock()
+	defer e.budget.mu.Unlock()
+	if status.Exceeded == "" {
+		if !e.budget.resumeAt.IsZero() {
+			e.budget.resumeAt = time.Time{}
+			logger.GetLogger().Info("LLM budget available again, resuming analysis and summaries")
+			events.Emit(events.LevelInfo, events.ComponentDaemon, "LLM budget available again, resuming analysis and summaries")
+		}
+		return false
+	}
+
+	logger.GetLogger().Infof("LLM budget exceeded (%s), deferring %s until %s", status.Exceeded, work, status.ResumeAt.Format(time.RFC3339))
+	if e.budget.resumeAt.Equal(status.ResumeAt) {
+		return true
+	}
+	e.budget.resumeAt = status.ResumeAt
+	events.Emit(events.LevelWarn, events.ComponentDaemon, "LLM budget exceeded (%s), analysis and summaries deferred until %s",
+		status.Exceeded, status.ResumeAt.Format(time.RFC3339))
+	if err := notify.Send("Stuff Time", fmt.Sprintf("LLM 预算已用完（%s），截图分析和总结暂停到 %s", status.Exceeded,
+		datefmt.DateTimeMinute(status.ResumeAt))); err != nil {
+		logger.GetLogger().Warnf("Failed to show budget alert: %v", err)
+	}
+	return true
+}
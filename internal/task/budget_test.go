@@ -0,0 +1,52 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestCheckBudget(t *testing.T) {
+	st := newTestStorage(t)
+
+	monthStart := time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local)
+	dayStart := time.Date(2025, 12, 10, 0, 0, 0, 0, time.Local)
+	for _, call := range []*storage.APICall{
+		{Timestamp: monthStart.Add(time.Hour), Model: "gpt-4o", PromptTokens: 800000, CompletionTokens: 100000},
+		{Timestamp: dayStart.Add(9 * time.Hour), Model: "gpt-4o", PromptTokens: 100000, CompletionTokens: 20000},
+		{Timestamp: dayStart.Add(10 * time.Hour), Model: "local", PromptTokens: 5000},
+		// Failed calls cost nothing
+		{Timestamp: dayStart.Add(11 * time.Hour), Model: "gpt-4o", PromptTokens: 900000, Error: "status 429", StatusCode: 429},
+	} {
+		if err := st.AddAPICall(call); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{}
+	cfg.OpenAI.Pricing = map[string]config.ModelPricing{"GPT-4o": {Input: 2.5, Output: 10}}
+	now := dayStart.Add(12 * time.Hour)
+
+	tests := []struct {
+		name     string
+		budget   config.BudgetConfig
+		exceeded string
+		resumeAt time.Time
+	}{
+		{"within budget", config.BudgetConfig{DailyTokens: 200000, MonthlyUSD: 10}, "", time.Time{}},
+		{"daily tokens", config.BudgetConfig{DailyTokens: 100000}, "daily tokens 125000/100000", dayStart.AddDate(0, 0, 1)},
+		{"daily cost", config.BudgetConfig{DailyUSD: 0.4}, "daily cost $0.45/$0.40", dayStart.AddDate(0, 0, 1)},
+		{"monthly cost first", config.BudgetConfig{DailyTokens: 100000, MonthlyUSD: 3}, "monthly cost $3.45/$3.00", monthStart.AddDate(0, 1, 0)},
+	}
+	for _, tt := range tests {
+		cfg.Performance.Budget = tt.budget
+		status, err := CheckBudget(cfg, st, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Exceeded != tt.exceeded || !status.ResumeAt.Equal(tt.resumeAt) {
+			t.Errorf("%s: CheckBudget() = %q until %s, want %q until %s", tt.name, status.Exceeded, status.ResumeAt, tt.exceeded, tt.resumeAt)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+)
+
+// CatchUp analyzes screenshots left unanalyzed while the daemon was stopped, newest first so the
+// current reports are fresh right away, and regenerates the current hour summary if it is stale
+// At most budget screenshots are analyzed; older ones are picked up by the regular analysis runs
+func (e *Executor) CatchUp(budget int) error {
+	if budget <= 0 {
+		return nil
+	}
+	if !e.analysisMutex.TryLock() {
+		logger.GetLogger().Info("Analysis already in progress, skipping catch-up")
+		return nil
+	}
+	records, err := e.storage.GetLatestUnanalyzedScreenshots(budget + 1)
+	if err != nil {
+		e.analysisMutex.Unlock()
+		return fmt.Errorf("failed to get unanalyzed screenshots: %w", err)
+	}
+
+	if len(records) > 0 {
+		backlog := fmt.Sprintf("%d", len(records))
+		if len(records) > budget {
+			records = records[:budget]
+			backlog = fmt.Sprintf("more than %d", budget)
+		}
+		logger.GetLogger().Infof("Catch-up: %s unanalyzed screenshot(s), analyzing the latest %d", backlog, len(records))
+
+		workerCount := e.config.Screenshot.AnalysisWorkers
+		if workerCount <= 0 {
+			workerCount = 3
+		}
+		if workerCount > len(records) {
+			workerCount = len(records)
+		}
+		e.setAnalyzing(true)
+		err = e.doBatchAnalyzeWithWorkers(records, workerCount)
+		e.setAnalyzing(false)
+	}
+	e.analysisMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("catch-up analysis failed: %w", err)
+	}
+
+	now := time.Now()
+	hourKey := now.Format("2006-01-02-15")
+	e.regenerateReportsForAnalyzedScreenshots(hourKey)
+	stale, err := e.isHourSummaryStale(hourKey)
+	if err != nil {
+		return err
+	}
+	if stale {
+		logger.GetLogger().Infof("Catch-up: regenerating the summary of hour %s", hourKey)
+		if err := e.generateSinglePeriodSummary(now, "hour", false, false); err != nil {
+			return fmt.Errorf("failed to regenerate hour summary: %w", err)
+		}
+	}
+	return nil
+}
+
+// isHourSummaryStale reports whether the hour has analyzed screenshots missing from its period summary
+func (e *Executor) isHourSummaryStale(hourKey string) (bool, error) {
+	screenshots, err := e.storage.GetScreenshotsByHourKey(hourKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to get screenshots of hour %s: %w", hourKey, err)
+	}
+	summary, err := e.storage.GetPeriodSummary(hourKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to get hour summary %s: %w", hourKey, err)
+	}
+
+	summarized := make(map[string]bool)
+	if summary != nil {
+		for _, id := range strings.Split(summary.Screenshots, ",") {
+			summarized[id] = true
+		}
+	}
+	for _, s := range screenshots {
+		if s.HasAnalysis() && !summarized[s.ID] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,118 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// citationGroupPattern matches citation groups produced by the summary model,
+// e.g. "[#14:03:20]" or "[#14:03:20, #14:05:20]"
+var citationGroupPattern = regexp.MustCompile(`\[#\d{2}:\d{2}(?::\d{2})?(?:\s*[,，、]\s*#\d{2}:\d{2}(?::\d{2})?)*\]`)
+
+// citationTagPattern matches a single citation tag inside a group
+var citationTagPattern = regexp.MustCompile(`#(\d{2}:\d{2}(?::\d{2})?)`)
+
+// citationTag returns the citation marker fed to the summary model for a screenshot
+func citationTag(t time.Time) string {
+	return fmt.Sprintf("[#%s]", t.Format("15:04:05"))
+}
+
+// citationIndex maps the second- and minute-precision citation times to the screenshots they refer to
+func citationIndex(screenshots []*storage.ScreenshotRecord) map[string]*storage.ScreenshotRecord {
+	byTime := make(map[string]*storage.ScreenshotRecord)
+	for _, s := range screenshots {
+		byTime[s.Timestamp.Format("15:04:05")] = s
+		// Minute-precision tags refer to the first screenshot of that minute
+		minute := s.Timestamp.Format("15:04")
+		if _, ok := byTime[minute]; !ok {
+			byTime[minute] = s
+		}
+	}
+	return byTime
+}
+
+// normalizeCitations rewrites citation tags to the second-precision tag of the screenshot they refer to
+// and removes tags that match no screenshot, so stored summaries only carry resolvable citations
+func normalizeCitations(text string, screenshots []*storage.ScreenshotRecord) string {
+	byTime := citationIndex(screenshots)
+	return citationGroupPattern.ReplaceAllStringFunc(text, func(group string) string {
+		var tags []string
+		for _, m := range citationTagPattern.FindAllStringSubmatch(group, -1) {
+			if record, ok := byTime[m[1]]; ok {
+				tags = append(tags, "#"+record.Timestamp.Format("15:04:05"))
+			}
+		}
+		if len(tags) == 0 {
+			return ""
+		}
+		return "[" + strings.Join(tags, ", ") + "]"
+	})
+}
+
+// storedCitations prepares the citation tags of a generated summary for saving: they are normalized
+// against the period's screenshots on levels that cite, and removed everywhere else
+func (e *Executor) storedCitations(periodType, summary string, start, end time.Time) string {
+	if !e.config.Summary.Citations || !analyzer.CitedPeriodTypes[periodType] {
+		return stripCitations(summary)
+	}
+	screenshots, err := e.storage.QueryByDateRange(start, end)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query screenshots for citations, removing them: %v", err)
+		return stripCitations(summary)
+	}
+	return normalizeCitations(summary, screenshots)
+}
+
+// renderCitations replaces citation tags in text with numbered markers ([1], [2], ...) in order of first use,
+// and returns the cited screenshots in marker order. Tags that match no screenshot are removed
+func renderCitations(text string, screenshots []*storage.ScreenshotRecord) (string, []*storage.ScreenshotRecord) {
+	byTime := citationIndex(screenshots)
+
+	numbers := make(map[string]int)
+	var cited []*storage.ScreenshotRecord
+	rendered := citationGroupPattern.ReplaceAllStringFunc(text, func(group string) string {
+		var markers strings.Builder
+		for _, m := range citationTagPattern.FindAllStringSubmatch(group, -1) {
+			record, ok := byTime[m[1]]
+			if !ok {
+				continue
+			}
+			n, ok := numbers[record.ID]
+			if !ok {
+				cited = append(cited, record)
+				n = len(cited)
+				numbers[record.ID] = n
+			}
+			markers.WriteString(fmt.Sprintf("[%d]", n))
+		}
+		return markers.String()
+	})
+	return rendered, cited
+}
+
+// stripCitations removes citation tags from text (used for levels whose reports don't list sources)
+func stripCitations(text string) string {
+	return citationGroupPattern.ReplaceAllString(text, "")
+}
+
+// formatCitationsSection renders the cited screenshots as a numbered source list
+func formatCitationsSection(cited []*storage.ScreenshotRecord) string {
+	if len(cited) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 引用来源\n\n")
+	for i, s := range cited {
+		sb.WriteString(fmt.Sprintf("[%d] %s · `%s` · %s\n", i+1, datefmt.DateTime(s.Timestamp), s.ID, s.ImagePath))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
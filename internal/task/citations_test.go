@@ -0,0 +1,32 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestNormalizeCitations(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 3, 20, 0, time.Local)
+	screenshots := []*storage.ScreenshotRecord{
+		{ID: "a", Timestamp: base},
+		{ID: "b", Timestamp: base.Add(2 * time.Minute)},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"编写 bundle.go [#14:03:20]", "编写 bundle.go [#14:03:20]"},
+		{"评审 PR [#14:05]", "评审 PR [#14:05:20]"},
+		{"编写测试 [#14:03:20，#14:05:20]", "编写测试 [#14:03:20, #14:05:20]"},
+		{"编写文档 [#14:03:20, #09:00:00]", "编写文档 [#14:03:20]"},
+		{"开会 [#09:00:00]", "开会 "},
+	}
+	for _, tt := range tests {
+		if got := normalizeCitations(tt.text, screenshots); got != tt.want {
+			t.Errorf("normalizeCitations(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
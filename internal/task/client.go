@@ -0,0 +1,97 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+)
+
+// ConfigureClient applies the provider, API style, prompt guard, network and backend settings of
+// the openai config to an analyzer
+func ConfigureClient(cfg *config.Config, a *analyzer.OpenAI) error {
+	if err := a.ConfigureProvider(cfg.OpenAI.Provider, cfg.OpenAI.APIVersion, cfg.OpenAI.AzureDeployments); err != nil {
+		return fmt.Errorf("failed to configure provider: %w", err)
+	}
+	if err := a.ConfigureAPI(cfg.OpenAI.API, cfg.OpenAI.ImageDetail); err != nil {
+		return fmt.Errorf("failed to configure API: %w", err)
+	}
+	if err := a.ConfigurePromptGuard(cfg.OpenAI.PromptGuard.MaxPromptTokens, cfg.OpenAI.PromptGuard.Strategy); err != nil {
+		return fmt.Errorf("failed to configure prompt guard: %w", err)
+	}
+	// The network transport must be set before the backend, which records through it
+	if err := a.ConfigureNetwork(cfg.OpenAI.Network.Proxy, cfg.OpenAI.Network.CAFile, cfg.OpenAI.Network.ClientCertFile, cfg.OpenAI.Network.ClientKeyFile); err != nil {
+		return fmt.Errorf("failed to configure network: %w", err)
+	}
+	retry, err := retryPolicy(&cfg.OpenAI.Retry)
+	if err != nil {
+		return fmt.Errorf("failed to configure retries: %w", err)
+	}
+	a.Retry = retry
+	if err := a.ConfigureBackend(cfg.OpenAI.Backend.Mode, cfg.OpenAI.Backend.FixturesPath, cfg.OpenAI.Backend.MockResponse); err != nil {
+		return fmt.Errorf("failed to configure LLM backend: %w", err)
+	}
+	return nil
+}
+
+// retryPolicy converts openai.retry into the retry policy of API calls
+func retryPolicy(cfg *config.RetryConfig) (analyzer.RetryPolicy, error) {
+	policy := analyzer.RetryPolicy{MaxRetries: cfg.MaxRetries, Multiplier: cfg.Multiplier}
+	var err error
+	if policy.InitialBackoff, err = parseRetryDuration("initial_backoff", cfg.InitialBackoff); err != nil {
+		return policy, err
+	}
+	if policy.MaxBackoff, err = parseRetryDuration("max_backoff", cfg.MaxBackoff); err != nil {
+		return policy, err
+	}
+
+	policy.Classes = make(map[string]analyzer.RetryOverride)
+	for class, o := range map[string]*config.RetryOverride{
+		analyzer.RetryClassRateLimit:   &cfg.RateLimit,
+		analyzer.RetryClassServerError: &cfg.ServerError,
+		analyzer.RetryClassTimeout:     &cfg.Timeout,
+		analyzer.RetryClassNetwork:     &cfg.Network,
+	} {
+		override, err := retryOverride(class, o)
+		if err != nil {
+			return policy, err
+		}
+		policy.Classes[class] = override
+	}
+	return policy, nil
+}
+
+// summaryRetryPolicy converts openai.retry.summary into the retry policy of whole fifteen-minute summaries
+func summaryRetryPolicy(cfg *config.RetryConfig) (analyzer.RetryPolicy, error) {
+	policy, err := retryPolicy(cfg)
+	if err != nil {
+		return policy, err
+	}
+	override, err := retryOverride("summary", &cfg.Summary)
+	if err != nil {
+		return policy, err
+	}
+	policy = policy.Override(override)
+	policy.Classes = nil
+	return policy, nil
+}
+
+func retryOverride(name string, o *config.RetryOverride) (analyzer.RetryOverride, error) {
+	initialBackoff, err := parseRetryDuration(name+".initial_backoff", o.InitialBackoff)
+	if err != nil {
+		return analyzer.RetryOverride{}, err
+	}
+	return analyzer.RetryOverride{MaxRetries: o.MaxRetries, InitialBackoff: initialBackoff, Multiplier: o.Multiplier}, nil
+}
+
+func parseRetryDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s '%s'", name, value)
+	}
+	return d, nil
+}
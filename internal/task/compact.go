@@ -0,0 +1,44 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// CompactBefore returns the start of the month months before the current one: months ending by
+// then are old enough to compact
+func CompactBefore(now time.Time, months int) time.Time {
+	return time.Date(now.Year(), now.Month()-time.Month(months), 1, 0, 0, 0, 0, now.Location())
+}
+
+// CompactReports compacts the fifteenmin and hour report files of months that ended more than
+// months ago into one archive per month, and writes an audit entry
+// Archived reports are still read by summary, the viewer, site build and the report watchdog
+func CompactReports(cfg *config.Config, st *storage.Storage, months int, dryRun bool) (*storage.CompactResult, error) {
+	if cfg.Storage.ReportsPath == "" {
+		return nil, fmt.Errorf("reports path not configured")
+	}
+	if months < 1 {
+		return nil, fmt.Errorf("months must be at least 1, got %d", months)
+	}
+	before := CompactBefore(time.Now(), months)
+
+	result, err := storage.CompactReports(cfg.Storage.ReportsPath, before, dryRun)
+	if err != nil {
+		return result, err
+	}
+	if dryRun || result.Reports == 0 {
+		return result, nil
+	}
+
+	details := fmt.Sprintf("before=%s months=%d reports=%d bytes=%d", before.Format("2006-01"), result.Months, result.Reports, result.Bytes)
+	if err := st.AddAuditEntry(storage.NewAuditEntry("compact", details)); err != nil {
+		return result, fmt.Errorf("compacted but failed to write audit entry: %w", err)
+	}
+	logger.GetLogger().Infof("Reports compacted: %s", details)
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/costs"
+	"stuff-time/internal/storage"
+)
+
+// MonthCosts builds the cost report of the month containing month from the API call log
+func MonthCosts(cfg *config.Config, st *storage.Storage, month time.Time) (*costs.Report, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	calls, err := st.QueryAPICalls(monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API calls: %w", err)
+	}
+	savings, err := st.QueryDedupSavings(monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dedup savings: %w", err)
+	}
+
+	return costs.Compute(month, calls, savings, modelPrices(cfg)), nil
+}
+
+// modelPrices returns openai.pricing keyed by lower-case model name
+func modelPrices(cfg *config.Config) map[string]costs.Price {
+	prices := make(map[string]costs.Price, len(cfg.OpenAI.Pricing))
+	for model, p := range cfg.OpenAI.Pricing {
+		prices[strings.ToLower(model)] = costs.Price{Input: p.Input, CachedInput: p.CachedInput, Output: p.Output}
+	}
+	return prices
+}
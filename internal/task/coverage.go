@@ -0,0 +1,63 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/coverage"
+	"stuff-time/internal/storage"
+)
+
+// MonthCoverage computes the captured/analyzed/summarized minutes per hour of the month containing month
+// A screenshot counts as summarized when a fifteenmin or hour summary covers it
+func MonthCoverage(cfg *config.Config, st *storage.Storage, month time.Time) (*coverage.Month, error) {
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	screenshots, err := st.QueryByDateRange(monthStart, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+
+	// Fifteen-minute windows covered by a summary, keyed by window start
+	fifteenMinStart := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()/15*15, 0, 0, t.Location())
+	}
+	summarized := make(map[time.Time]bool)
+	for _, periodType := range []string{"fifteenmin", "hour"} {
+		summaries, err := st.QueryPeriodSummaries(periodType, monthStart, monthEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s summaries: %w", periodType, err)
+		}
+		for _, s := range summaries {
+			for t := fifteenMinStart(s.StartTime.In(monthStart.Location())); t.Before(s.EndTime); t = t.Add(15 * time.Minute) {
+				summarized[t] = true
+			}
+		}
+	}
+
+	samples := make([]coverage.Sample, 0, len(screenshots))
+	for _, s := range screenshots {
+		samples = append(samples, coverage.Sample{
+			Time:       s.Timestamp,
+			Analyzed:   s.HasAnalysis() || s.AnalysisStatus == storage.AnalysisSkipped,
+			Summarized: summarized[fifteenMinStart(s.Timestamp.In(monthStart.Location()))],
+		})
+	}
+
+	interval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	result := coverage.Compute(monthStart, samples, interval)
+	for _, o := range cfg.EmbargoOccurrences(monthStart, monthEnd) {
+		start := o[0]
+		for t := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, start.Location()); t.Before(o[1]); t = t.Add(time.Hour) {
+			if t.Month() == monthStart.Month() {
+				result.Days[t.Day()-1].Embargoed[t.Hour()] = true
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package task
+
+import (
+	"image"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// recordWindowBounds stores the frontmost window bounds relative to the captured display, for cropping
+// the image uploaded for analysis
+func (e *Executor) recordWindowBounds(record *storage.ScreenshotRecord, win *screenshot.Window) {
+	display := screenshot.DisplayBounds(record.ScreenID)
+	window := win.Bounds.Intersect(display)
+	if window.Empty() || display.Empty() {
+		// No window (e.g. Finder desktop) or the window is on another display, the full screenshot is analyzed
+		return
+	}
+
+	window = window.Sub(display.Min)
+	bounds := &storage.WindowBounds{
+		ImagePath:     record.ImagePath,
+		X:             window.Min.X,
+		Y:             window.Min.Y,
+		Width:         window.Dx(),
+		Height:        window.Dy(),
+		DisplayWidth:  display.Dx(),
+		DisplayHeight: display.Dy(),
+	}
+	if err := e.storage.SaveWindowBounds(bounds); err != nil {
+		logger.GetLogger().Warnf("Failed to save window bounds of screenshot %s: %v", record.ID, err)
+	}
+}
+
+// analyzeScreenshot analyzes the screenshot with the cloud provider, cropped to the active window
+// when screenshot.crop_to_window is enabled and the window bounds were recorded at capture
+// The model is chosen by the screenshot's route (routing.rules)
+func (e *Executor) analyzeScreenshot(record *storage.ScreenshotRecord) (string, error) {
+	analyzer := e.screenshotAnalyzer(record)
+	window := captureWindow(record)
+	if !e.config.Screenshot.CropToWindow {
+		return analyzer.AnalyzeScreenshot(record.ImagePath, window)
+	}
+
+	bounds, err := e.storage.GetWindowBounds(record.ImagePath)
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get window bounds of %s, analyzing full screenshot: %v", record.ID, err)
+	}
+	if bounds == nil {
+		return analyzer.AnalyzeScreenshot(record.ImagePath, window)
+	}
+
+	padding := e.config.Screenshot.CropPadding
+	return analyzer.AnalyzeScreenshotCropped(record.ImagePath, window, func(size image.Point) image.Rectangle {
+		return bounds.CropRect(size, padding)
+	})
+}
+
+// captureWindow returns the frontmost window recorded with the screenshot (screenshot.window_context),
+// nil when none was recorded
+func captureWindow(record *storage.ScreenshotRecord) *analyzer.CaptureWindow {
+	if record.App == "" {
+		return nil
+	}
+	return &analyzer.CaptureWindow{App: record.App, Title: record.WindowTitle}
+}
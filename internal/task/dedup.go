@@ -0,0 +1,38 @@
+package task
+
+import (
+	"strings"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/dedup"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// dedupMinRunes is the minimum normalized line length considered for de-duplication,
+// shorter lines (headings like 【工作记录】, separators) are always kept
+const dedupMinRunes = 12
+
+// dedupSiblingSummaries collapses near-identical lines repeated across sibling summaries before
+// they are aggregated into periodKey, so the higher-level input doesn't repeat the same sentence
+func (e *Executor) dedupSiblingSummaries(summaryTexts []string, periodKey string) []string {
+	if len(summaryTexts) < 2 {
+		return summaryTexts
+	}
+
+	deduped, removed := dedup.Lines(summaryTexts, dedup.Options{
+		Threshold: e.config.Summary.DedupThreshold,
+		MinRunes:  dedupMinRunes,
+		Normalize: stripCitations,
+	})
+	if removed > 0 {
+		logger.GetLogger().Infof("Removed %d repeated line(s) from %d sibling summaries of %s",
+			removed, len(summaryTexts), periodKey)
+		// Recorded for the cost report
+		saved := analyzer.EstimateTokens(strings.Join(summaryTexts, "\n")) - analyzer.EstimateTokens(strings.Join(deduped, "\n"))
+		if err := e.storage.AddDedupSaving(&storage.DedupSaving{PeriodKey: periodKey, Lines: removed, Tokens: saved}); err != nil {
+			logger.GetLogger().Warnf("Failed to record dedup saving of %s: %v", periodKey, err)
+		}
+	}
+	return deduped
+}
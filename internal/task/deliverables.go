@@ -0,0 +1,212 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// deliverableKindNames maps deliverable kinds to their display names in reports
+var deliverableKindNames = map[string]string{
+	"pr":      "合并请求",
+	"commit":  "代码提交",
+	"doc":     "文档",
+	"ticket":  "工单",
+	"release": "发布",
+	"other":   "其他",
+}
+
+// mergePRPattern matches GitHub/GitLab merge commit subjects, e.g. "Merge pull request #123 from ..."
+var mergePRPattern = regexp.MustCompile(`(?i)^merge (pull|merge) request [#!]?(\d+)`)
+
+// extractedDeliverable is the JSON shape returned by the extraction prompt
+type extractedDeliverable struct {
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Reference string `json:"reference"`
+	Time      string `json:"time"`
+}
+
+// ExtractDeliverables extracts the deliverables of a day from screenshot analyses and configured git repos,
+// and replaces the stored deliverables of that day. The stored ones are kept if the extraction fails
+func (e *Executor) ExtractDeliverables(day time.Time) ([]*storage.Deliverable, error) {
+	dayStart := e.config.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	dayKey := storage.FormatPeriodKey("day", dayStart)
+
+	var deliverables []*storage.Deliverable
+
+	if e.config.Deliverables.PromptContent != "" {
+		screenshots, err := e.storage.QueryByDateRange(dayStart, dayEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query screenshots: %w", err)
+		}
+		screenshots = e.workScreenshots(screenshots)
+
+		var lines []string
+		for _, s := range screenshots {
+			if !s.HasAnalysis() || isDesktopOrLockScreenAnalysis(s.Analysis) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("[%s] %s", s.Timestamp.Format("15:04"), s.Analysis))
+		}
+
+		if len(lines) > 0 {
+			raw, err := e.analyzer.ExtractDeliverables(e.config.Deliverables.PromptContent, strings.Join(lines, "\n"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract deliverables: %w", err)
+			}
+			// Keep the stored deliverables rather than replacing them with a partial list
+			parsed, err := parseDeliverables(raw, dayStart)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse deliverables: %w", err)
+			}
+			deliverables = append(deliverables, parsed...)
+		}
+	}
+
+	for _, repo := range e.config.Deliverables.GitRepos {
+		commits, err := collectGitDeliverables(repo, e.config.Deliverables.GitAuthor, dayStart, dayEnd)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to read git log from %s: %v", repo, err)
+			continue
+		}
+		deliverables = append(deliverables, commits...)
+	}
+
+	if err := e.storage.ReplaceDeliverables(dayKey, deliverables); err != nil {
+		return nil, fmt.Errorf("failed to save deliverables: %w", err)
+	}
+
+	logger.GetLogger().Infof("Extracted %d deliverable(s) for %s", len(deliverables), dayKey)
+	return deliverables, nil
+}
+
+// unmarshalJSONArray decodes the JSON array in model output into v
+// Tolerates markdown code fences and surrounding text around the array
+func unmarshalJSONArray(raw string, v interface{}) error {
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON array in response")
+	}
+	return json.Unmarshal([]byte(raw[start:end+1]), v)
+}
+
+// parseDeliverables parses the model output into deliverables
+func parseDeliverables(raw string, dayStart time.Time) ([]*storage.Deliverable, error) {
+	var items []extractedDeliverable
+	if err := unmarshalJSONArray(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deliverables: %w", err)
+	}
+
+	var deliverables []*storage.Deliverable
+	seen := make(map[string]bool)
+	for _, item := range items {
+		title := strings.TrimSpace(item.Title)
+		if title == "" || seen[title] {
+			continue
+		}
+		seen[title] = true
+
+		kind := strings.ToLower(strings.TrimSpace(item.Kind))
+		if _, ok := deliverableKindNames[kind]; !ok {
+			kind = "other"
+		}
+
+		timestamp := dayStart
+		if t, err := time.ParseInLocation("15:04", strings.TrimSpace(item.Time), dayStart.Location()); err == nil {
+			timestamp = dayStart.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+		}
+
+		deliverables = append(deliverables, &storage.Deliverable{
+			Timestamp: timestamp,
+			Kind:      kind,
+			Title:     title,
+			Reference: strings.TrimSpace(item.Reference),
+			Source:    "analysis",
+		})
+	}
+	return deliverables, nil
+}
+
+// collectGitDeliverables reads commits in [start, end) from a local git repository
+// Merge commits of pull/merge requests are recorded as "pr", other commits as "commit"
+func collectGitDeliverables(repo, author string, start, end time.Time) ([]*storage.Deliverable, error) {
+	args := []string{"-C", repo, "log", "--no-color",
+		"--since=" + start.Format(time.RFC3339), "--until=" + end.Format(time.RFC3339),
+		"--pretty=format:%h%x09%aI%x09%s"}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var deliverables []*storage.Deliverable
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			continue
+		}
+
+		kind := "commit"
+		if mergePRPattern.MatchString(parts[2]) {
+			kind = "pr"
+		}
+		deliverables = append(deliverables, &storage.Deliverable{
+			Timestamp: timestamp.In(start.Location()),
+			Kind:      kind,
+			Title:     parts[2],
+			Reference: parts[0],
+			Source:    "git",
+		})
+	}
+	return deliverables, nil
+}
+
+// formatDeliverablesSection renders the deliverables of a period as a markdown section
+// Returns an empty string if there are no deliverables
+func formatDeliverablesSection(deliverables []*storage.Deliverable) string {
+	if len(deliverables) == 0 {
+		return ""
+	}
+
+	// Group by kind, keeping a stable kind order
+	kindOrder := []string{"release", "pr", "ticket", "doc", "commit", "other"}
+	grouped := make(map[string][]*storage.Deliverable)
+	for _, d := range deliverables {
+		grouped[d.Kind] = append(grouped[d.Kind], d)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 交付成果\n\n")
+	for _, kind := range kindOrder {
+		items := grouped[kind]
+		if len(items) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("### %s（%d）\n\n", deliverableKindNames[kind], len(items)))
+		for _, d := range items {
+			line := fmt.Sprintf("- %s %s", d.Timestamp.Format("01-02"), d.Title)
+			if d.Reference != "" {
+				line += fmt.Sprintf("（%s）", d.Reference)
+			}
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
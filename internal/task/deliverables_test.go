@@ -0,0 +1,45 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestExtractDeliverablesKeepsStoredOnParseFailure(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Deliverables.PromptContent = "list the deliverables"
+	e, st := newTestExecutor(t, cfg)
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	record := &storage.ScreenshotRecord{ID: "s1", Timestamp: day.Add(10 * time.Hour), ImagePath: "/nonexistent/s1.png",
+		Analysis: "Merging the release PR in GitHub"}
+	record.GenerateHourKey()
+	if err := st.SaveScreenshot(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.ReplaceDeliverables("day:2025-12-09", []*storage.Deliverable{{ID: "d1", PeriodKey: "day:2025-12-09",
+		Timestamp: day.Add(11 * time.Hour), Kind: "release", Title: "v2", Source: "analysis"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := analyzer.NewOpenAI("test-key", "", "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	if err := client.ConfigureBackend(analyzer.BackendModeMock, "", "I could not find any deliverables"); err != nil {
+		t.Fatal(err)
+	}
+	e.analyzer = client
+
+	if _, err := e.ExtractDeliverables(day); err == nil {
+		t.Error("ExtractDeliverables() succeeded on a response without a JSON array")
+	}
+	deliverables, err := st.QueryDeliverables(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliverables) != 1 || deliverables[0].Title != "v2" {
+		t.Errorf("deliverables after a failed extraction = %v, want the stored one", deliverables)
+	}
+}
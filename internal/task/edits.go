@@ -0,0 +1,224 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// User sections: content between these comments is written by the user and carried over into
+// regenerated reports
+const (
+	userSectionStart = "<!-- user -->"
+	userSectionEnd   = "<!-- /user -->"
+)
+
+// ErrReportEdited is returned when a report was edited outside user sections since it was generated,
+// so regenerating it would lose the edits
+var ErrReportEdited = errors.New("report was edited by hand since it was generated")
+
+// maxDiffLines limits the diff shown when a regeneration is refused
+const maxDiffLines = 40
+
+// reportFooterPrefix starts the footer line of generated reports, user sections are kept above it
+const reportFooterPrefix = "*报告生成时间"
+
+// mergeManualEdits returns the content to write to reportPath for the regenerated report content
+// User sections of the current file are carried over above the footer of content. If the file was
+// edited outside user sections since it was generated, the regenerated report is written to the
+// conflicts directory and ErrReportEdited is returned with a diff, unless storage.manual_edits is "overwrite"
+func (e *Executor) mergeManualEdits(reportPath, content string) (string, error) {
+	if e.config.Storage.ManualEdits == "overwrite" {
+		return content, nil
+	}
+	existing, err := os.ReadFile(reportPath)
+	if err != nil {
+		return content, nil
+	}
+	current := string(existing)
+
+	if e.reportEdited(reportPath, current) {
+		newPath := e.conflictPath(reportPath)
+		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create conflicts directory: %w", err)
+		}
+		if err := os.WriteFile(newPath, []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write regenerated report: %w", err)
+		}
+		return "", fmt.Errorf("%s: %w; the regenerated report was written to %s.\n"+
+			"Move your additions into %s ... %s sections, lock the period, or regenerate with --overwrite-edits.\n%s",
+			reportPath, ErrReportEdited, newPath, userSectionStart, userSectionEnd, lineDiff(current, content, maxDiffLines))
+	}
+
+	if sections := userSections(current); len(sections) > 0 {
+		content = insertUserSections(content, sections)
+	}
+	return content, nil
+}
+
+// CheckManualEdits returns ErrReportEdited if the report of summary was edited outside user sections,
+// so a regeneration is refused before the summary is saved and the database stays in line with the report
+func (e *Executor) CheckManualEdits(summary *storage.PeriodSummary) error {
+	if e.config.Storage.ManualEdits == "overwrite" || e.config.Storage.ReportsPath == "" {
+		return nil
+	}
+	reportPath, err := e.calculateReportPath(summary)
+	if err != nil {
+		return nil
+	}
+	existing, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil
+	}
+	if e.reportEdited(reportPath, string(existing)) {
+		return fmt.Errorf("%s: %w; move your additions into %s ... %s sections, lock the period, or regenerate with --overwrite-edits",
+			reportPath, ErrReportEdited, userSectionStart, userSectionEnd)
+	}
+	return nil
+}
+
+// conflictPath returns where the regenerated version of an edited report is written: the report's path
+// under the conflicts directory next to the database, so it never shows up in the reports tree
+func (e *Executor) conflictPath(reportPath string) string {
+	rel, err := filepath.Rel(e.config.Storage.ReportsPath, reportPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(reportPath)
+	}
+	return filepath.Join(filepath.Dir(e.config.Storage.DBPath), "conflicts", rel)
+}
+
+// insertUserSections puts the user sections at the end of the report body, above the footer
+// (the generation time and the horizontal rule before it)
+func insertUserSections(content string, sections []string) string {
+	body, footer := content, ""
+	if i := strings.LastIndex(content, reportFooterPrefix); i >= 0 {
+		body, footer = content[:i], content[i:]
+		if trimmed := strings.TrimRight(body, "\n"); strings.HasSuffix(trimmed, "---") {
+			body, footer = strings.TrimSuffix(trimmed, "---"), "---\n\n"+footer
+		}
+	}
+	content = strings.TrimRight(body, "\n") + "\n\n" + strings.Join(sections, "\n\n") + "\n"
+	if footer != "" {
+		content += "\n" + footer
+	}
+	return content
+}
+
+// reportEdited reports whether the current content of a report differs outside user sections from
+// what was generated; reports generated before hashes were recorded count as unedited
+func (e *Executor) reportEdited(reportPath, current string) bool {
+	recorded, err := e.storage.GetReportHash(reportPath)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to get hash of %s: %v", reportPath, err)
+		return false
+	}
+	return recorded != "" && reportHash(current) != recorded
+}
+
+// recordReportHash records the hash of a written report for mergeManualEdits
+func (e *Executor) recordReportHash(reportPath, content string) {
+	if err := e.storage.SaveReportHash(reportPath, reportHash(content)); err != nil {
+		logger.GetLogger().Warnf("Failed to save hash of %s: %v", reportPath, err)
+	}
+}
+
+// reportHash hashes a report without its user sections, so editing only user sections is not an edit
+func reportHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(withoutUserSections(content))))
+	return hex.EncodeToString(sum[:])
+}
+
+// userSections returns the user sections of a report, including their markers
+func userSections(content string) []string {
+	var sections []string
+	for {
+		start := strings.Index(content, userSectionStart)
+		if start < 0 {
+			return sections
+		}
+		end := strings.Index(content[start:], userSectionEnd)
+		if end < 0 {
+			// An unterminated section runs to the end of the report
+			return append(sections, strings.TrimSpace(content[start:])+"\n"+userSectionEnd)
+		}
+		end += start + len(userSectionEnd)
+		sections = append(sections, content[start:end])
+		content = content[end:]
+	}
+}
+
+// withoutUserSections removes the user sections and the blank lines around them, so a report without
+// its user sections reads as it was generated
+func withoutUserSections(content string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(content, userSectionStart)
+		if start < 0 {
+			sb.WriteString(content)
+			return sb.String()
+		}
+		sb.WriteString(strings.TrimRight(content[:start], "\n"))
+		end := strings.Index(content[start:], userSectionEnd)
+		if end < 0 {
+			return sb.String()
+		}
+		content = "\n\n" + strings.TrimLeft(content[start+end+len(userSectionEnd):], "\n")
+	}
+}
+
+// lineDiff returns a line diff from a to b ("-" removed, "+" added), at most maxLines changed lines
+func lineDiff(a, b string, maxLines int) string {
+	aLines := strings.Split(strings.TrimRight(a, "\n"), "\n")
+	bLines := strings.Split(strings.TrimRight(b, "\n"), "\n")
+	// Longest common subsequence table; reports are a few hundred lines at most
+	if len(aLines)*len(bLines) > 4000000 {
+		return fmt.Sprintf("(report too large to diff: %d lines vs %d lines)", len(aLines), len(bLines))
+	}
+	lcs := make([][]int, len(aLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bLines)+1)
+	}
+	for i := len(aLines) - 1; i >= 0; i-- {
+		for j := len(bLines) - 1; j >= 0; j-- {
+			if aLines[i] == bLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	changed := 0
+	add := func(prefix, line string) {
+		if changed < maxLines {
+			sb.WriteString(prefix + line + "\n")
+		}
+		changed++
+	}
+	i, j := 0, 0
+	for i < len(aLines) || j < len(bLines) {
+		switch {
+		case i < len(aLines) && j < len(bLines) && aLines[i] == bLines[j]:
+			i++
+			j++
+		case j < len(bLines) && (i == len(aLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			add("+ ", bLines[j])
+			j++
+		default:
+			add("- ", aLines[i])
+			i++
+		}
+	}
+	if changed > maxLines {
+		sb.WriteString(fmt.Sprintf("... %d more changed lines\n", changed-maxLines))
+	}
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestMergeManualEdits(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DBPath = filepath.Join(dir, "test.db")
+	cfg.Storage.ReportsPath = filepath.Join(dir, "reports")
+	e, _ := newTestExecutor(t, cfg)
+	reportPath := filepath.Join(cfg.Storage.ReportsPath, "2025", "day.md")
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(content string) {
+		t.Helper()
+		merged, err := e.mergeManualEdits(reportPath, content)
+		if err != nil {
+			t.Fatalf("mergeManualEdits() error = %v", err)
+		}
+		if err := os.WriteFile(reportPath, []byte(merged), 0644); err != nil {
+			t.Fatal(err)
+		}
+		e.recordReportHash(reportPath, merged)
+	}
+
+	write("# 日总结\n\n编写代码\n")
+
+	// User sections are carried over into the regenerated report
+	section := "<!-- user -->\n## 补充\n\n线下评审\n<!-- /user -->"
+	os.WriteFile(reportPath, []byte("# 日总结\n\n编写代码\n\n"+section+"\n"), 0644)
+	write("# 日总结\n\n编写代码，修复测试\n")
+	got, _ := os.ReadFile(reportPath)
+	if want := "# 日总结\n\n编写代码，修复测试\n\n" + section + "\n"; string(got) != want {
+		t.Errorf("regenerated report = %q, want %q", got, want)
+	}
+
+	// User sections stay above the footer
+	footer := "\n---\n\n*报告生成时间: 2025-12-09 18:00:00*\n"
+	write("# 日总结\n\n编写代码，修复测试\n" + footer)
+	got, _ = os.ReadFile(reportPath)
+	if want := "# 日总结\n\n编写代码，修复测试\n\n" + section + "\n" + footer; string(got) != want {
+		t.Errorf("regenerated report with footer = %q, want %q", got, want)
+	}
+	// Removing the user sections is not an edit
+	os.WriteFile(reportPath, []byte("# 日总结\n\n编写代码，修复测试\n"+footer), 0644)
+	if e.reportEdited(reportPath, "# 日总结\n\n编写代码，修复测试\n"+footer) {
+		t.Error("removing the user sections counts as an edit")
+	}
+	os.WriteFile(reportPath, got, 0644)
+
+	// Edits outside user sections refuse the regeneration
+	os.WriteFile(reportPath, []byte(strings.Replace(string(got), "修复测试", "修复了三个测试", 1)), 0644)
+	_, err := e.mergeManualEdits(reportPath, "# 日总结\n\n重新生成\n")
+	if !errors.Is(err, ErrReportEdited) {
+		t.Fatalf("mergeManualEdits() error = %v, want ErrReportEdited", err)
+	}
+	if !strings.Contains(err.Error(), "- 编写代码，修复了三个测试") || !strings.Contains(err.Error(), "+ 重新生成") {
+		t.Errorf("error does not contain the diff: %v", err)
+	}
+	conflict := filepath.Join(dir, "conflicts", "2025", "day.md")
+	if newReport, _ := os.ReadFile(conflict); string(newReport) != "# 日总结\n\n重新生成\n" {
+		t.Errorf("regenerated report was not written to the conflicts directory: %q", newReport)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(reportPath)); len(entries) != 1 {
+		t.Errorf("reports directory has %d files, want only the edited report", len(entries))
+	}
+
+	// Regenerations are refused before the summary is saved
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	summary := &storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day", StartTime: day, EndTime: day.AddDate(0, 0, 1)}
+	if err := e.CheckManualEdits(summary); err != nil {
+		t.Errorf("CheckManualEdits() of an unwritten report = %v", err)
+	}
+	if reportPath, err = e.calculateReportPath(summary); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(reportPath), 0755)
+	write("# 日总结\n\n编写代码\n")
+	if err := e.CheckManualEdits(summary); err != nil {
+		t.Errorf("CheckManualEdits() of an unedited report = %v", err)
+	}
+	os.WriteFile(reportPath, []byte("# 日总结\n\n编写了代码\n"), 0644)
+	if err := e.CheckManualEdits(summary); !errors.Is(err, ErrReportEdited) {
+		t.Errorf("CheckManualEdits() of an edited report = %v, want ErrReportEdited", err)
+	}
+
+	e.config.Storage.ManualEdits = "overwrite"
+	if merged, err := e.mergeManualEdits(reportPath, "# 日总结\n\n重新生成\n"); err != nil || merged != "# 日总结\n\n重新生成\n" {
+		t.Errorf("overwrite mode = %q, %v", merged, err)
+	}
+}
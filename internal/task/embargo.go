@@ -0,0 +1,28 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+)
+
+// embargoListMax is the number of embargoed ranges listed in a report, longer periods show a total
+const embargoListMax = 3
+
+// formatEmbargoed describes the embargoed ranges of a period without anything about their content
+func formatEmbargoed(occurrences [][2]time.Time) string {
+	if len(occurrences) > embargoListMax {
+		var total time.Duration
+		for _, o := range occurrences {
+			total += o[1].Sub(o[0])
+		}
+		return fmt.Sprintf("%d 个时段，共约 %.0f 分钟（已屏蔽）", len(occurrences), total.Minutes())
+	}
+	ranges := make([]string, len(occurrences))
+	for i, o := range occurrences {
+		ranges[i] = datefmt.TimeMinute(o[0]) + "-" + datefmt.TimeMinute(o[1])
+	}
+	return strings.Join(ranges, "、") + "（已屏蔽）"
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,10 +11,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 
 	"stuff-time/internal/analyzer"
 	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/events"
+	"stuff-time/internal/idlescreen"
+	"stuff-time/internal/issues"
 	"stuff-time/internal/logger"
+	"stuff-time/internal/objectstore"
 	"stuff-time/internal/screenshot"
 	"stuff-time/internal/storage"
 )
@@ -22,16 +29,39 @@ type Executor struct {
 	config         *config.Config
 	storage        *storage.Storage
 	storageManager *storage.StorageManager
-	analyzer       *analyzer.OpenAI
-	analysisMutex  sync.Mutex
-	isAnalyzing    bool
+	// personalStorageManager writes personal-track reports (24/7 mode), nil when disabled
+	personalStorageManager *storage.StorageManager
+	analyzer               *analyzer.OpenAI
+	localAnalyzer          *analyzer.OpenAI            // Local model for local-only apps, nil describes them from the window
+	routedAnalyzers        map[string]*analyzer.OpenAI // Analyzer per routing rule name
+	summaryRetry           analyzer.RetryPolicy        // Retries of whole fifteen-minute summaries
+	issueTracker           issues.Tracker
+	images                 *objectstore.Store // Object store of screenshot images, nil keeps them on local disk only
+	analysisMutex          sync.Mutex
+	isAnalyzing            bool
+	permission             *permissionMonitor
+	breaks                 *breakMonitor
+	captures               captureThrottle     // Last capture, for per-app capture intervals
+	scaler                 workerScaler        // Analysis worker count under screenshot.worker_scaling
+	regeneration           regenerationTracker // Last check of each hour for outdated screenshot reports
+	promptVersions         map[string]int      // Active database version of each prompt under prompts.store: db
+	budget                 budgetMonitor       // Exceeded performance.budget period, alerted once
 }
 
 func NewExecutor(cfg *config.Config, st *storage.Storage) (*Executor, error) {
-	if cfg.OpenAI.APIKey == "" {
+	if cfg.OpenAI.APIKey == "" && !cfg.OpenAI.Backend.IsOffline() {
 		return nil, fmt.Errorf("OpenAI API key not configured")
 	}
 
+	var promptVersions map[string]int
+	if cfg.Prompts.UseDB() {
+		versions, err := ApplyStoredPrompts(cfg, st)
+		if err != nil {
+			return nil, err
+		}
+		promptVersions = versions
+	}
+
 	// 创建 StorageManager
 	storageManager := storage.NewStorageManager(&cfg.Storage, cfg.Storage.ReportsPath)
 
@@ -76,13 +106,91 @@ func NewExecutor(cfg *config.Config, st *storage.Storage) (*Executor, error) {
 		cfg.OpenAI.AnalysisPromptContent,
 		levelPrompts,
 	)
+	if err := ConfigureClient(cfg, analyzer); err != nil {
+		return nil, err
+	}
+	RecordUploads(cfg, analyzer, st)
+	RecordAPICalls(cfg, analyzer, st)
+	analyzer.UploadFormat = cfg.OpenAI.UploadFormat
+
+	if cfg.Chaos.Enabled {
+		logger.GetLogger().Warnf("Chaos mode enabled: api_failure_rate=%.2f, faults=%v, disk_slow_rate=%.2f",
+			cfg.Chaos.APIFailureRate, cfg.Chaos.APIFaults, cfg.Chaos.DiskSlowRate)
+		analyzer.EnableChaos(cfg.Chaos.APIFailureRate, cfg.Chaos.APIFaults, cfg.Chaos.GetAPITimeoutDuration())
+		st.EnableChaos(cfg.Chaos.DiskSlowRate, cfg.Chaos.GetDiskMaxDelayDuration())
+		storageManager.EnableChaos(cfg.Chaos.DiskSlowRate, cfg.Chaos.GetDiskMaxDelayDuration())
+	}
+
+	if cfg.Summary.Citations {
+		analyzer.SummaryCitationTemplate = cfg.OpenAI.SummaryCitationContent
+	}
+	analyzer.SummaryLengthTemplate = cfg.OpenAI.SummaryLengthContent
+	analyzer.SummaryLengths = cfg.Summary.Lengths
+	analyzer.SummaryTokensPerWord = cfg.Summary.TokensPerWord
+
+	routedAnalyzers, err := newRoutedAnalyzers(cfg, analyzer)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryRetry, err := summaryRetryPolicy(&cfg.OpenAI.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure summary retries: %w", err)
+	}
+
+	localAnalyzer := newLocalAnalyzer(cfg)
+	if localAnalyzer != nil {
+		RecordUploads(cfg, localAnalyzer, st)
+		RecordAPICalls(cfg, localAnalyzer, st)
+		localAnalyzer.UploadFormat = cfg.OpenAI.UploadFormat
+		localAnalyzer.Retry = analyzer.Retry
+	}
+
+	issueTracker, err := newIssueTracker(&cfg.Issues)
+	if err != nil {
+		logger.GetLogger().Warnf("Issue tracker disabled: %v", err)
+	}
+
+	images, err := NewImageStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store: %w", err)
+	}
+
+	captureInterval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil {
+		captureInterval = time.Minute
+	}
+
+	var personalStorageManager *storage.StorageManager
+	if cfg.Personal.Enabled && cfg.Personal.ReportsPath != "" {
+		personalStorageManager = storage.NewStorageManager(&cfg.Storage, cfg.Personal.ReportsPath)
+	}
 
+	return &Executor{
+		config:                 cfg,
+		storage:                st,
+		storageManager:         storageManager,
+		personalStorageManager: personalStorageManager,
+		images:                 images,
+		analyzer:               analyzer,
+		localAnalyzer:          localAnalyzer,
+		routedAnalyzers:        routedAnalyzers,
+		summaryRetry:           summaryRetry,
+		issueTracker:           issueTracker,
+		permission:             &permissionMonitor{threshold: cfg.Screenshot.PermissionAlertAfter},
+		breaks:                 newBreakMonitor(captureInterval, cfg.Breaks.GetMinBreakDuration(), cfg.Breaks.GetNotifyAfterDuration()),
+		promptVersions:         promptVersions,
+	}, nil
+}
+
+// NewReportExecutor creates an executor that only renders reports and notes of stored summaries,
+// for commands that must work without an API key; it cannot call the model
+func NewReportExecutor(cfg *config.Config, st *storage.Storage) *Executor {
 	return &Executor{
 		config:         cfg,
 		storage:        st,
-		storageManager: storageManager,
-		analyzer:       analyzer,
-	}, nil
+		storageManager: storage.NewStorageManager(&cfg.Storage, cfg.Storage.ReportsPath),
+	}
 }
 
 func (e *Executor) CaptureScreenshot() error {
@@ -95,24 +203,59 @@ func (e *Executor) CaptureScreenshot() error {
 		logger.GetLogger().Warnf("Failed to check screen lock status: %v, proceeding anyway", err)
 	} else if locked {
 		logger.GetLogger().Info("Screen is locked, skipping screenshot capture")
+		events.Emit(events.LevelDebug, events.ComponentCapture, "Screen is locked, capture skipped")
 		return nil // Skip screenshot when locked
 	} else {
 		logger.GetLogger().Debug("Screen is not locked, proceeding with screenshot capture")
 	}
 
-	// Check if current time is within work hours
+	// Check if current time is within work hours (24/7 mode captures personal time too)
 	now := time.Now()
-	if !e.config.Screenshot.WorkHours.IsWorkTime(now) {
+	if !e.config.Screenshot.WorkHours.IsWorkTime(now) && !e.config.Personal.Enabled {
 		logger.GetLogger().Info("Outside work hours, skipping screenshot capture")
 		return nil // Skip screenshot when outside work hours
 	}
 
+	if until := e.capturePausedUntil(now); !until.IsZero() {
+		logger.GetLogger().Infof("Capture paused until %s, skipping screenshot capture", datefmt.DateTime(until))
+		events.Emit(events.LevelDebug, events.ComponentCapture, "Capture paused, capture skipped")
+		return nil
+	}
+
+	if e.skipForIdle() {
+		return nil
+	}
+
+	if e.skipForSpace() {
+		return nil
+	}
+
+	if e.skipForAppInterval(now) {
+		// The screen is in use, so a skipped capture does not count as a break
+		if !e.config.IsPersonalTime(now) {
+			e.onWorkCaptured(now)
+		}
+		return nil
+	}
+
+	_, err = e.captureScreen(now)
+	return err
+}
+
+// captureScreen captures the screen under the mouse and saves the screenshot record
+func (e *Executor) captureScreen(now time.Time) (*storage.ScreenshotRecord, error) {
 	screenID, err := screenshot.GetMouseScreenID()
 	if err != nil {
-		return fmt.Errorf("failed to get mouse screen ID: %w", err)
+		return nil, fmt.Errorf("failed to get mouse screen ID: %w", err)
 	}
 	logger.GetLogger().Infof("Mouse screen ID: %d", screenID)
 
+	if !screenshot.HasScreenRecordingPermission() {
+		err := fmt.Errorf("screen recording permission not granted")
+		e.onCaptureFailed(err)
+		return nil, err
+	}
+
 	logger.GetLogger().Infof("Capturing screen %d...", screenID)
 	imagePath, err := screenshot.CaptureScreen(
 		screenID,
@@ -120,21 +263,51 @@ func (e *Executor) CaptureScreenshot() error {
 		e.config.Screenshot.ImageFormat,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to capture screen: %w", err)
+		// Blank captures are not stored, so they never reach the vision model
+		e.onCaptureFailed(err)
+		return nil, fmt.Errorf("failed to capture screen: %w", err)
 	}
+	e.onCaptureSucceeded()
 	logger.GetLogger().Infof("Screen captured, saving to: %s", imagePath)
 
 	record := storage.NewScreenshotRecord(screenID, imagePath)
+	idle := e.tagIdle(record)
+
+	var win *screenshot.Window
+	if e.config.Screenshot.WindowContext || e.config.Projects.Enabled || e.config.Screenshot.RecordApps ||
+		len(e.config.LocalOnly.Apps) > 0 || e.config.Screenshot.CropToWindow {
+		if win, err = screenshot.FrontmostWindowInfo(); err != nil {
+			logger.GetLogger().Debugf("Failed to get frontmost window: %v", err)
+		} else if e.config.Screenshot.WindowContext {
+			record.App, record.WindowTitle = win.App, win.Title
+		}
+	}
 
 	logger.GetLogger().Info("Saving screenshot record to database...")
 	if err := e.storage.SaveScreenshot(record); err != nil {
-		return fmt.Errorf("failed to save screenshot record: %w", err)
+		return nil, fmt.Errorf("failed to save screenshot record: %w", err)
+	}
+	e.recordRuntimeState(storage.RuntimeLastCapture, record.ID)
+	e.uploadImage(record)
+	e.recordSpace(record)
+	if !e.config.IsPersonalTime(now) && !idle {
+		e.onWorkCaptured(now)
+	}
+
+	if win != nil {
+		e.recordWindow(record, win.App, win.Title)
+		e.recordLocalOnly(record, win.App, win.Title)
+		e.recordRoute(record, win.App, win.Title)
+		if e.config.Screenshot.CropToWindow {
+			e.recordWindowBounds(record, win)
+		}
 	}
 
 	logger.GetLogger().Infof("Screenshot captured: %s (screen %d, path: %s)",
 		record.ID, screenID, imagePath)
+	events.Emit(events.LevelInfo, events.ComponentCapture, "Screenshot captured: %s (screen %d)", record.ID, screenID)
 
-	return nil
+	return record, nil
 }
 
 // BatchAnalyze triggers batch analysis asynchronously to avoid blocking the scheduler
@@ -149,8 +322,8 @@ func (e *Executor) BatchAnalyze() error {
 	// Start analysis in a separate goroutine to avoid blocking the scheduler
 	go func() {
 		defer e.analysisMutex.Unlock()
-		e.isAnalyzing = true
-		defer func() { e.isAnalyzing = false }()
+		e.setAnalyzing(true)
+		defer e.setAnalyzing(false)
 
 		if err := e.doBatchAnalyze(); err != nil {
 			logger.GetLogger().Infof("ERROR: Batch analysis failed: %v",
@@ -163,7 +336,14 @@ func (e *Executor) BatchAnalyze() error {
 
 // doBatchAnalyze performs the actual batch analysis work using worker pool for concurrency
 func (e *Executor) doBatchAnalyze() error {
-	records, err := e.storage.GetUnanalyzedScreenshots(100)
+	pending := e.recordBacklog()
+
+	// Pending screenshots stay in the backlog until the budget resets
+	if e.overBudget("screenshot analysis") {
+		return nil
+	}
+
+	records, err := e.storage.GetUnanalyzedScreenshots(analysisBatchSize)
 	if err != nil {
 		return fmt.Errorf("failed to get unanalyzed screenshots: %w", err)
 	}
@@ -184,10 +364,7 @@ func (e *Executor) doBatchAnalyze() error {
 	e.regenerateReportsForAnalyzedScreenshots(records[0].HourKey)
 
 	// Determine worker count
-	workerCount := e.config.Screenshot.AnalysisWorkers
-	if workerCount <= 0 {
-		workerCount = 3 // Default to 3 workers
-	}
+	workerCount := e.analysisWorkers(pending)
 	if workerCount > len(records) {
 		workerCount = len(records) // Don't create more workers than jobs
 	}
@@ -199,6 +376,13 @@ func (e *Executor) doBatchAnalyze() error {
 	return e.doBatchAnalyzeWithWorkers(records, workerCount)
 }
 
+// Analysis results are written to the database in batches of analysisFlushBatchSize,
+// or every analysisFlushInterval when results arrive slowly
+const (
+	analysisFlushBatchSize = 20
+	analysisFlushInterval  = 5 * time.Second
+)
+
 // analysisResult represents the result of analyzing a single screenshot
 type analysisResult struct {
 	record   *storage.ScreenshotRecord
@@ -224,61 +408,131 @@ func (e *Executor) doBatchAnalyzeWithWorkers(records []*storage.ScreenshotRecord
 	close(jobs)
 
 	// Collect results
+	// Database updates are written in batches (one transaction per batch), hour summaries are
+	// refreshed once per hour per batch, and report files are written by a background writer
 	successCount := 0
 	failCount := 0
+	rejectedCount := 0
 
-	for i := 0; i < len(records); i++ {
-		result := <-results
-		record := result.record
+	writer := e.newReportWriter(len(records))
 
-		// Skip desktop or lock screen screenshots (empty analysis means skip)
-		if result.analysis == "" && result.err == nil {
-			logger.GetLogger().Infof("Skipping desktop/lock screen screenshot %s (no analysis needed)",
-				record.ID)
-			// Mark as analyzed but with empty analysis to indicate it was skipped
-			if err := e.storage.UpdateScreenshotAnalysis(record.ID, ""); err != nil {
-				logger.GetLogger().Infof("ERROR: Failed to mark screenshot %s as skipped: %v",
-					record.ID, err)
-			}
-			continue
+	var batch []*storage.ScreenshotRecord
+	flush := func() {
+		if len(batch) == 0 {
+			return
 		}
+		defer func() { batch = batch[:0] }()
 
-		if result.err != nil {
-			logger.GetLogger().Infof("WARNING: Failed to analyze screenshot %s: %v",
-				record.ID, result.err)
-			result.analysis = fmt.Sprintf("Analysis failed: %v", result.err)
-			failCount++
-		} else {
-			successCount++
+		updates := make([]*storage.ScreenshotAnalysisUpdate, 0, len(batch))
+		for _, record := range batch {
+			updates = append(updates, &storage.ScreenshotAnalysisUpdate{
+				ID:       record.ID,
+				Status:   record.AnalysisStatus,
+				Analysis: record.Analysis,
+				Error:    record.AnalysisError,
+			})
+		}
+		dbErr := e.storage.UpdateScreenshotAnalyses(updates)
+		if dbErr != nil {
+			logger.GetLogger().Infof("ERROR: Failed to update analyses of %d screenshot(s): %v",
+				len(updates), dbErr)
+		}
+
+		updatedHours := make(map[string]bool)
+		for _, record := range batch {
+			if record.AnalysisStatus == storage.AnalysisSkipped {
+				continue
+			}
+			if record.AnalysisStatus == storage.AnalysisDone {
+				if dbErr != nil {
+					successCount--
+					failCount++
+				} else {
+					logger.GetLogger().Debugf("Analysis completed for screenshot: %s",
+						record.ID)
+					logger.Count("screenshots_analyzed")
+					events.Emit(events.LevelInfo, events.ComponentAnalyzer, "Analysis completed for %s", record.ID)
+				}
+			}
+
+			if !updatedHours[record.HourKey] {
+				updatedHours[record.HourKey] = true
+				if err := e.updateHourSummary(record); err != nil {
+					logger.GetLogger().Infof("ERROR: Failed to update hour summary for %s: %v",
+						record.HourKey, err)
+				}
+			}
+
+			// Save report to file (always save, even if database update failed)
+			// This ensures report reflects the analysis result
+			writer.Enqueue(record)
 		}
+	}
 
-		// Update record.Analysis BEFORE saving to database, so saveReport can use it
-		record.Analysis = result.analysis
+	flushTicker := time.NewTicker(analysisFlushInterval)
+	defer flushTicker.Stop()
 
-		if err := e.storage.UpdateScreenshotAnalysis(record.ID, result.analysis); err != nil {
-			logger.GetLogger().Infof("ERROR: Failed to update analysis for %s: %v",
-				record.ID, err)
-			failCount++
-		} else {
-			logger.GetLogger().Infof("Analysis completed for screenshot: %s",
-				record.ID)
+	for received := 0; received < len(records); {
+		var result analysisResult
+		select {
+		case result = <-results:
+			received++
+		case <-flushTicker.C:
+			// Persist slow-arriving results without waiting for a full batch
+			flush()
+			continue
 		}
+		record := result.record
+
+		// Skip desktop or lock screen screenshots (empty analysis means skip)
+		if result.analysis == "" && result.err == nil {
+			logger.GetLogger().Infof("Skipping desktop/lock screen screenshot %s (no analysis needed)",
+				record.ID)
+			// Mark as skipped so it is not picked up by the next batch
+			record.AnalysisStatus = storage.AnalysisSkipped
+			record.Analysis = ""
+			record.AnalysisError = ""
+		} else {
+			status := storage.AnalysisDone
+			if errors.Is(result.err, analyzer.ErrVisionRejected) {
+				// The API refused this image, retrying won't help
+				logger.GetLogger().Infof("WARNING: Screenshot %s rejected by vision API: %v", record.ID, result.err)
+				events.Emit(events.LevelWarn, events.ComponentAnalyzer, "Screenshot %s rejected by vision API", record.ID)
+				status = storage.AnalysisRejected
+				rejectedCount++
+			} else if result.err != nil {
+				logger.GetLogger().Infof("WARNING: Failed to analyze screenshot %s: %v",
+					record.ID, result.err)
+				events.Emit(events.LevelWarn, events.ComponentAnalyzer, "Analysis failed for %s: %v", record.ID, result.err)
+				status = storage.AnalysisFailed
+				failCount++
+			} else {
+				successCount++
+			}
 
-		if err := e.updateHourSummary(record); err != nil {
-			logger.GetLogger().Infof("ERROR: Failed to update hour summary for %s: %v",
-				record.HourKey, err)
+			// Update the record BEFORE saving to database, so saveReport can use it
+			// The failure reason goes to analysis_error, never into the analysis text
+			record.AnalysisStatus = status
+			record.Analysis = result.analysis
+			record.AnalysisError = ""
+			if status != storage.AnalysisDone {
+				record.Analysis = ""
+				record.AnalysisError = result.err.Error()
+			}
 		}
 
-		// Save report to file (always save, even if database update failed)
-		// This ensures report reflects the analysis result
-		if err := e.saveReport(record); err != nil {
-			logger.GetLogger().Infof("WARNING: Failed to save report for %s: %v",
-				record.ID, err)
+		batch = append(batch, record)
+		if len(batch) >= analysisFlushBatchSize {
+			flush()
 		}
 	}
+	flush()
+	writer.Close()
 
-	logger.GetLogger().Infof("Batch analysis completed: %d succeeded, %d failed",
-		successCount, failCount)
+	logger.GetLogger().Infof("Batch analysis completed: %d succeeded, %d failed, %d rejected",
+		successCount, failCount, rejectedCount)
+	events.Emit(events.LevelInfo, events.ComponentAnalyzer, "Batch analysis completed: %d succeeded, %d failed, %d rejected", successCount, failCount, rejectedCount)
+	e.recordRuntimeState(storage.RuntimeLastAnalysis, fmt.Sprintf("%d succeeded, %d failed, %d rejected", successCount, failCount, rejectedCount))
 
 	return nil
 }
@@ -286,6 +540,26 @@ func (e *Executor) doBatchAnalyzeWithWorkers(records []*storage.ScreenshotRecord
 // analysisWorker is a worker that processes analysis jobs from the jobs channel
 func (e *Executor) analysisWorker(workerID int, jobs <-chan *storage.ScreenshotRecord, results chan<- analysisResult) {
 	for record := range jobs {
+		// Images evicted from the local cache are downloaded from the object store first
+		if err := e.fetchImage(record); err != nil {
+			results <- analysisResult{record: record, err: fmt.Errorf("failed to fetch screenshot image: %w", err)}
+			continue
+		}
+		e.recognizeText(record)
+
+		// Screenshots of local-only apps never reach the cloud provider
+		mark, err := e.storage.GetLocalOnly(record.ImagePath)
+		if err != nil {
+			// Don't risk sending a screenshot that may be local-only, retry it in the next batch
+			results <- analysisResult{record: record, err: fmt.Errorf("failed to check local-only mark: %w", err)}
+			continue
+		}
+		if mark != nil {
+			analysis, err := e.analyzeLocally(record, mark)
+			results <- analysisResult{record: record, analysis: analysis, err: err}
+			continue
+		}
+
 		// First check if it's desktop or lock screen, skip analysis if so
 		isDesktopOrLockScreen, err := e.analyzer.IsDesktopOrLockScreen(record.ImagePath)
 		if err != nil {
@@ -304,7 +578,7 @@ func (e *Executor) analysisWorker(workerID int, jobs <-chan *storage.ScreenshotR
 		}
 
 		// Proceed with normal analysis
-		analysis, err := e.analyzer.AnalyzeScreenshot(record.ImagePath)
+		analysis, err := e.analyzeScreenshot(record)
 		results <- analysisResult{
 			record:   record,
 			analysis: analysis,
@@ -319,6 +593,11 @@ func (e *Executor) GeneratePeriodSummary(forceFromScreenshots bool, isManual boo
 		summaryPeriods = []string{"hour", "day", "week", "month"}
 	}
 
+	// Scheduled summaries wait for the budget to reset; manual generation is not limited
+	if !isManual && e.overBudget("period summaries") {
+		return nil
+	}
+
 	now := time.Now()
 	var errors []string
 
@@ -351,7 +630,14 @@ func (e *Executor) GenerateSinglePeriodSummary(periodType string, dateStr string
 	}
 
 	// Manual generation always allows generating current period
-	return e.generateSinglePeriodSummary(now, periodType, forceFromScreenshots, true)
+	// Unlike internal generation, a period without data is reported to the caller as storage.ErrNoData
+	return e.generatePeriodSummary(now, periodType, forceFromScreenshots, true)
+}
+
+// RegeneratePeriod regenerates the summary of the period of periodType containing t, e.g. to compare its
+// output after prompt/model changes (work-segment periods are not supported)
+func (e *Executor) RegeneratePeriod(periodType string, t time.Time, forceFromScreenshots bool) error {
+	return e.generatePeriodSummary(t, periodType, forceFromScreenshots, true)
 }
 
 // GenerateHigherLevelSummaries generates all higher-level summaries from a given period type and date
@@ -364,39 +650,65 @@ func (e *Executor) GenerateHigherLevelSummaries(periodType string, dateStr strin
 		if err != nil {
 			return fmt.Errorf("invalid date format: %w", err)
 		}
-		periodTime = parsedDate
+		// The date names a day, which starts at day_start_hour
+		periodTime = e.config.Storage.DateStart(parsedDate)
 	} else {
 		periodTime = time.Now()
 	}
 
 	// Adjust periodTime based on period type to get the correct period start time
 	switch periodType {
-	case "work-segment":
-		// Use current day
-		periodTime = time.Date(periodTime.Year(), periodTime.Month(), periodTime.Day(), 0, 0, 0, 0, periodTime.Location())
-	case "day":
-		periodTime = time.Date(periodTime.Year(), periodTime.Month(), periodTime.Day(), 0, 0, 0, 0, periodTime.Location())
+	case "work-segment", "day", "week", "month", "year":
+		periodTime = e.periodStart(periodType, periodTime)
+	}
+
+	// GenerateHigherLevelSummaries is always called manually, so pass true
+	return e.generateHigherLevelSummaries(periodType, periodTime, forceFromScreenshots, true)
+}
+
+// generateSinglePeriodSummary generates the summary of the period containing now; periods without data are skipped
+func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string, forceFromScreenshots bool, isManual bool) error {
+	err := e.generatePeriodSummary(now, periodType, forceFromScreenshots, isManual)
+	if errors.Is(err, storage.ErrNoData) {
+		err = nil
+	}
+
+	// The personal track is summarized alongside the work day summary (24/7 mode)
+	if periodType == "day" && e.config.Personal.Enabled {
+		if personalErr := e.GeneratePersonalDaySummary(now); personalErr != nil && !errors.Is(personalErr, storage.ErrNoData) {
+			logger.GetLogger().Warnf("Failed to generate personal summary: %v", personalErr)
+		}
+	}
+	return err
+}
+
+// periodStart returns the start of the day, week (Monday), month, quarter or year containing t
+// Days start at storage.day_start_hour, so hours before it belong to the previous day
+func (e *Executor) periodStart(periodType string, t time.Time) time.Time {
+	date := e.config.Storage.LogicalDate(t)
+	switch periodType {
 	case "week":
-		weekday := int(periodTime.Weekday())
+		weekday := int(date.Weekday())
 		if weekday == 0 {
 			weekday = 7
 		}
-		periodTime = time.Date(periodTime.Year(), periodTime.Month(), periodTime.Day(), 0, 0, 0, 0, periodTime.Location())
-		periodTime = periodTime.AddDate(0, 0, -(weekday - 1))
+		return e.config.Storage.DateStart(date).AddDate(0, 0, -(weekday - 1))
 	case "month":
-		periodTime = time.Date(periodTime.Year(), periodTime.Month(), 1, 0, 0, 0, 0, periodTime.Location())
+		return e.config.Storage.DateStart(time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()))
+	case "quarter":
+		quarterStartMonth := (int(date.Month())-1)/3*3 + 1
+		return e.config.Storage.DateStart(time.Date(date.Year(), time.Month(quarterStartMonth), 1, 0, 0, 0, 0, date.Location()))
 	case "year":
-		periodTime = time.Date(periodTime.Year(), 1, 1, 0, 0, 0, 0, periodTime.Location())
+		return e.config.Storage.DateStart(time.Date(date.Year(), 1, 1, 0, 0, 0, 0, date.Location()))
+	default:
+		return e.config.Storage.DateStart(date)
 	}
-
-	// GenerateHigherLevelSummaries is always called manually, so pass true
-	return e.generateHigherLevelSummaries(periodType, periodTime, forceFromScreenshots, true)
 }
 
-func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string, forceFromScreenshots bool, isManual bool) error {
+// generatePeriodSummary generates the summary of the period containing now
+// Returns storage.ErrNoData when the period has no data to summarize
+func (e *Executor) generatePeriodSummary(now time.Time, periodType string, forceFromScreenshots bool, isManual bool) error {
 	var startTime, endTime time.Time
-	var periodKey string
-
 	switch periodType {
 	case "fifteenmin":
 		minute := now.Minute()
@@ -404,46 +716,77 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 		roundedMinute := (minute / 15) * 15
 		startTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), roundedMinute, 0, 0, now.Location())
 		endTime = startTime.Add(15 * time.Minute)
-		periodKey = startTime.Format("2006-01-02-15-04")
 	case "hour":
 		startTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
 		endTime = startTime.Add(time.Hour)
-		periodKey = startTime.Format("2006-01-02-15")
 	case "work-segment":
 		// Work-segment is handled by generateWorkSegmentSummary
 		// This case should not be reached in normal flow
 		return fmt.Errorf("work-segment should be generated via generateWorkSegmentSummary")
 	case "day":
-		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		startTime = e.periodStart(periodType, now)
 		endTime = startTime.AddDate(0, 0, 1)
-		periodKey = startTime.Format("2006-01-02")
 	case "week":
-		weekday := int(now.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		startTime = startTime.AddDate(0, 0, -(weekday - 1))
+		startTime = e.periodStart(periodType, now)
 		endTime = startTime.AddDate(0, 0, 7)
-		periodKey = startTime.Format("2006-01-02") + "-week"
 	case "month":
-		startTime = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		startTime = e.periodStart(periodType, now)
 		endTime = startTime.AddDate(0, 1, 0)
-		periodKey = startTime.Format("2006-01")
 	case "quarter":
 		// Quarter: Q1 (Jan-Mar), Q2 (Apr-Jun), Q3 (Jul-Sep), Q4 (Oct-Dec)
-		quarter := (int(now.Month())-1)/3 + 1
-		quarterStartMonth := (quarter-1)*3 + 1
-		startTime = time.Date(now.Year(), time.Month(quarterStartMonth), 1, 0, 0, 0, 0, now.Location())
+		startTime = e.periodStart(periodType, now)
 		endTime = startTime.AddDate(0, 3, 0)
-		periodKey = fmt.Sprintf("%d-Q%d", now.Year(), quarter)
 	case "year":
-		startTime = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		startTime = e.periodStart(periodType, now)
 		endTime = startTime.AddDate(1, 0, 0)
-		periodKey = startTime.Format("2006")
 	default:
 		return fmt.Errorf("unsupported summary period: %s", periodType)
 	}
+	periodKey := storage.FormatPeriodKey(periodType, startTime)
+
+	// A manual generate (own process or HTTP API) and the scheduler may ask for the same period at once;
+	// the later caller waits for the running generation instead of racing on SavePeriodSummary, and shares its
+	// result when both rebuild from screenshots or neither does
+	shared, err := coalesceGeneration(e.storage, periodKey, forceFromScreenshots, func() error {
+		return e.summarizePeriod(periodType, periodKey, startTime, endTime, forceFromScreenshots, isManual)
+	})
+	if shared {
+		logger.GetLogger().Infof("%s summary %s was already being generated, using its result", periodType, periodKey)
+	}
+	return err
+}
+
+// summarizePeriod generates and saves the summary of the period [startTime, endTime)
+func (e *Executor) summarizePeriod(periodType, periodKey string, startTime, endTime time.Time, forceFromScreenshots bool, isManual bool) error {
+	// Locked periods keep their hand-edited report, also during force rebuilds of a parent
+	if e.isPeriodLocked(periodKey) {
+		logger.GetLogger().Infof("Skipping %s summary generation for %s: period is locked", periodType, periodKey)
+		logger.Count("skipped_locked")
+		return nil
+	}
+
+	// A report edited by hand refuses the regeneration before anything is saved, so the stored summary
+	// stays in line with the report
+	period := &storage.PeriodSummary{PeriodKey: periodKey, PeriodType: periodType, StartTime: startTime, EndTime: endTime}
+	if err := e.CheckManualEdits(period); err != nil {
+		logger.GetLogger().Warnf("Skipping %s summary generation for %s: %v", periodType, periodKey, err)
+		return nil
+	}
+
+	// Manual notes dropped next to the report are fed into the summary input
+	periodNotes := e.readPeriodNotes(period)
+	// Daily reflections from the journal command are woven into the week summary
+	var periodJournal string
+	if periodType == "week" {
+		periodJournal = e.readJournal(startTime, endTime)
+	}
+	// Unfinished task threads of the previous day keep multi-day efforts narrated coherently
+	var threadCarryover string
+	if e.config.Threads.Enabled {
+		threadCarryover = e.threadCarryover(periodType, startTime, endTime)
+	}
+	// Exact counts and durations keep the model from making up numbers
+	periodStats := e.periodStats(periodType, startTime, endTime)
 
 	// For automatic generation, skip periods that haven't ended yet
 	// Manual generation always allows generating current period
@@ -471,9 +814,10 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 	// If no data exists in the theoretical range, return early (no report needed)
 	actualStartTime, actualEndTime, hasData := e.determineActualTimeRange(periodType, startTime, endTime)
 	if !hasData {
-		logger.GetLogger().Infof("No data found for %s (%s to %s), skipping report generation",
+		logger.GetLogger().Debugf("No data found for %s (%s to %s), skipping report generation",
 			periodKey, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
-		return nil
+		logger.Count("skipped_no_data")
+		return fmt.Errorf("%s: %w", periodKey, storage.ErrNoData)
 	}
 
 	// Update time range based on actual data
@@ -484,18 +828,20 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 	var improvementAnalysis string
 	var allScreenshotIDs []string
 	screenshotIDSet := make(map[string]bool) // Use map for deduplication
+	// Child summaries and screenshots actually passed to the model, recorded as the summary's lineage
+	var lineage storage.SummaryLineage
 
 	// Determine if we should aggregate from lower-level summaries or from screenshots
 	lowerLevelType := e.getLowerLevelPeriodType(periodType)
 
 	if lowerLevelType != "" {
 		// Aggregate from lower-level summaries
-		logger.GetLogger().Infof("DEBUG: Querying %s summaries from %s to %s", lowerLevelType, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
+		logger.GetLogger().Debugf("Querying %s summaries from %s to %s", lowerLevelType, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
 		lowerSummaries, err := e.storage.QueryPeriodSummaries(lowerLevelType, startTime, endTime)
 		if err != nil {
 			return fmt.Errorf("failed to query lower-level summaries: %w", err)
 		}
-		logger.GetLogger().Infof("DEBUG: Found %d %s summaries", len(lowerSummaries), lowerLevelType)
+		logger.GetLogger().Debugf("Found %d %s summaries", len(lowerSummaries), lowerLevelType)
 
 		// If forceFromScreenshots=true, force rebuild all lower-level summaries from screenshots
 		// Otherwise, only generate if missing
@@ -528,12 +874,13 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			if err != nil {
 				logger.GetLogger().Infof("WARNING: Failed to query screenshots for %s: %v", periodKey, err)
 			}
+			screenshots = e.workScreenshots(screenshots)
 
 			// Filter out desktop/lock screen screenshots to check if there's any valid work activity
 			var validScreenshots []*storage.ScreenshotRecord
 			if err == nil {
 				for _, s := range screenshots {
-					if s.Analysis != "" && !strings.HasPrefix(s.Analysis, "Analysis failed") {
+					if s.HasAnalysis() {
 						if !isDesktopOrLockScreenAnalysis(s.Analysis) {
 							validScreenshots = append(validScreenshots, s)
 						}
@@ -592,7 +939,7 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 
 			// Check if summary is invalid (contains "no work activity" message) or empty
 			// Only collect screenshot IDs from valid summaries with actual work activity
-			if s.Summary == "" || isInvalidSummary(s.Summary) {
+			if s.Summary == "" || isInvalidSummary(&e.config.Summary, s.Summary) {
 				logger.GetLogger().Infof("Invalid or empty summary detected for %s (%s), will regenerate from lower level",
 					s.PeriodKey, lowerLevelType)
 				invalidSummaryKeys = append(invalidSummaryKeys, s.PeriodKey)
@@ -613,7 +960,7 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			}
 
 			// Add valid summary to aggregation
-			summaryTexts = append(summaryTexts, s.Summary)
+			summaryTexts = append(summaryTexts, aggregationSummary(periodType, e.markLockedSummary(s)))
 			validLowerSummaries = append(validLowerSummaries, s)
 		}
 
@@ -647,9 +994,9 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 						} else {
 							// Query the regenerated summary
 							regenerated, err := e.storage.GetPeriodSummary(invalidKey)
-							if err == nil && regenerated != nil && regenerated.Summary != "" && !isInvalidSummary(regenerated.Summary) {
+							if err == nil && regenerated != nil && regenerated.Summary != "" && !isInvalidSummary(&e.config.Summary, regenerated.Summary) {
 								// Use the regenerated summary
-								summaryTexts = append(summaryTexts, regenerated.Summary)
+								summaryTexts = append(summaryTexts, aggregationSummary(periodType, regenerated.Summary))
 								validLowerSummaries = append(validLowerSummaries, regenerated)
 								// Add screenshot IDs to deduplication set
 								if regenerated.Screenshots != "" {
@@ -686,8 +1033,8 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 						} else {
 							// Query the regenerated summary
 							regenerated, err := e.storage.GetPeriodSummary(invalidKey)
-							if err == nil && regenerated != nil && regenerated.Summary != "" && !isInvalidSummary(regenerated.Summary) {
-								summaryTexts = append(summaryTexts, regenerated.Summary)
+							if err == nil && regenerated != nil && regenerated.Summary != "" && !isInvalidSummary(&e.config.Summary, regenerated.Summary) {
+								summaryTexts = append(summaryTexts, aggregationSummary(periodType, regenerated.Summary))
 								validLowerSummaries = append(validLowerSummaries, regenerated)
 								// Add screenshot IDs to deduplication set
 								if regenerated.Screenshots != "" {
@@ -707,6 +1054,13 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			}
 		}
 
+		for _, s := range validLowerSummaries {
+			lineage.Children = append(lineage.Children, s.PeriodKey)
+		}
+
+		// Collapse content repeated across siblings (e.g. several fifteenmins of the same long task)
+		summaryTexts = e.dedupSiblingSummaries(summaryTexts, periodKey)
+
 		if len(summaryTexts) > 0 {
 			// Determine if we should use direct merge or LLM processing
 			// For natural period summaries from already-aggregated levels (work-segment, day, etc.),
@@ -722,7 +1076,7 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 					"quarter":      true,
 					"year":         true,
 				}
-				if aggregatedLevels[lowerLevelType] && len(summaryTexts) <= 10 && !isManual {
+				if aggregatedLevels[lowerLevelType] && len(summaryTexts) <= e.config.Summary.GetDirectMergeMaxSummaries() && !isManual {
 					// From aggregated level with small number of summaries: direct merge
 					// Manual generation always uses LLM to regenerate the current level
 					shouldDirectMerge = true
@@ -737,28 +1091,28 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 				// This is fast and preserves all information without LLM overhead
 				logger.GetLogger().Infof("Directly merging %d %s summaries for %s (no LLM processing)",
 					len(summaryTexts), lowerLevelType, periodKey)
-				summaryResult = strings.Join(summaryTexts, "\n\n---\n\n")
+				summaryResult = stripLockedLabels(strings.Join(summaryTexts, "\n\n---\n\n"))
 			} else if len(summaryTexts) == 1 {
 				// Single summary, use regular summary
-				summaryResult, err = e.analyzer.GenerateSummary(summaryTexts[0], periodType)
+				summaryResult, err = e.analyzer.GenerateSummary(withPeriodStats(withThreadCarryover(withJournal(withPeriodNotes(withLockedNote(summaryTexts[0]), periodNotes), periodJournal), threadCarryover), periodStats), periodType)
 			} else if len(summaryTexts) == 2 {
 				// Two summaries: equal merge instead of rolling
 				// Rolling treats first as "previous context" and second as "new content"
 				// which causes information loss when first is empty/idle
 				combined := strings.Join(summaryTexts, "\n\n")
-				summaryResult, err = e.analyzer.GenerateSummary(combined, periodType)
+				summaryResult, err = e.analyzer.GenerateSummary(withPeriodStats(withThreadCarryover(withJournal(withPeriodNotes(withLockedNote(combined), periodNotes), periodJournal), threadCarryover), periodStats), periodType)
 			} else {
 				// 3+ summaries: combine all summaries and generate in one LLM call
 				// No rolling summary - all summaries are merged and processed together
 				combined := strings.Join(summaryTexts, "\n\n")
-				summaryResult, err = e.analyzer.GenerateSummary(combined, periodType)
+				summaryResult, err = e.analyzer.GenerateSummary(withPeriodStats(withThreadCarryover(withJournal(withPeriodNotes(withLockedNote(combined), periodNotes), periodJournal), threadCarryover), periodStats), periodType)
 			}
 
 			if err != nil {
 				logger.GetLogger().Infof("WARNING: Failed to generate summary for %s: %v",
 					periodKey, err)
 				// Fallback: combine all summaries
-				periodSummary = strings.Join(summaryTexts, "\n\n")
+				periodSummary = stripLockedLabels(strings.Join(summaryTexts, "\n\n"))
 			} else {
 				// For week and above, apply level-specific prompt to finalize the summary
 				if periodType == "week" || periodType == "month" || periodType == "quarter" || periodType == "year" {
@@ -786,7 +1140,7 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			if len(allScreenshotIDs) == 0 {
 				logger.GetLogger().Infof("No valid summaries and no screenshots for %s (%s), skipping report generation",
 					periodKey, periodType)
-				return nil
+				return fmt.Errorf("%s: %w", periodKey, storage.ErrNoData)
 			}
 
 			// If we have screenshots but no valid summaries, set summary to empty
@@ -803,20 +1157,19 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 		}
 
 		// Clean summary if it indicates no work activity (remove efficiency analysis and improvement suggestions)
-		periodSummary = cleanSummaryIfNoWorkActivity(periodSummary)
+		periodSummary = cleanSummaryIfNoWorkActivity(&e.config.Summary, periodSummary)
 
 		// If summary is empty after cleaning and no screenshots, don't generate report
 		if periodSummary == "" && len(allScreenshotIDs) == 0 {
 			logger.GetLogger().Infof("No valid content and no screenshots for %s (%s), skipping report generation",
 				periodKey, periodType)
-			return nil
+			return fmt.Errorf("%s: %w", periodKey, storage.ErrNoData)
 		}
 
-		// Generate analysis only for week and longer periods
-		// Day and below focus on factual records only
+		// Generate analysis only for the configured levels (week and longer by default)
 		// Only generate analysis if there is valid work activity
-		if periodSummary != "" && len(summaryTexts) > 0 && shouldGenerateAnalysis(periodType) {
-			if hasValidWorkActivity(periodSummary) {
+		if periodSummary != "" && len(summaryTexts) > 0 && e.shouldGenerateAnalysis(periodType) {
+			if hasValidWorkActivity(&e.config.Summary, periodSummary) {
 				analysisResult, err := e.analyzer.AnalyzeBehavior(periodSummary)
 				if err != nil {
 					logger.GetLogger().Infof("WARNING: Failed to perform improvement analysis for %s: %v",
@@ -849,21 +1202,30 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 		if err != nil {
 			return fmt.Errorf("failed to query screenshots: %w", err)
 		}
+		// Personal-time screenshots are summarized in the personal track only
+		screenshots = e.workScreenshots(screenshots)
 
 		if len(screenshots) == 0 {
 			return nil
 		}
 
 		var screenshotSummaries []string
+		lineage.Children = nil
 		for _, s := range screenshots {
 			// Add screenshot IDs to deduplication set
 			if s.ID != "" {
 				screenshotIDSet[s.ID] = true
 			}
-			if s.Analysis != "" && !strings.HasPrefix(s.Analysis, "Analysis failed") {
+			if s.HasAnalysis() {
 				// Filter out desktop/lock screen screenshots
 				if !isDesktopOrLockScreenAnalysis(s.Analysis) {
-					screenshotSummaries = append(screenshotSummaries, s.Analysis)
+					lineage.Screenshots = append(lineage.Screenshots, s.ID)
+					if e.config.Summary.Citations {
+						// Tag each record so the summary can cite its source screenshot
+						screenshotSummaries = append(screenshotSummaries, citationTag(s.Timestamp)+" "+s.Analysis)
+					} else {
+						screenshotSummaries = append(screenshotSummaries, s.Analysis)
+					}
 				}
 			}
 		}
@@ -874,9 +1236,17 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			allScreenshotIDs = append(allScreenshotIDs, id)
 		}
 
+		if minScreenshots := e.config.Summary.MinScreenshotsPerSummary; minScreenshots > 0 && len(screenshotSummaries) > 0 && len(screenshotSummaries) < minScreenshots {
+			// Too little activity to be worth a summary call, mark the period as idle
+			logger.GetLogger().Debugf("Only %d valid screenshot(s) for %s (min_screenshots_per_summary=%d), marking as idle",
+				len(screenshotSummaries), periodKey, minScreenshots)
+			screenshotSummaries = nil
+			lineage.Screenshots = nil
+		}
+
 		if len(screenshotSummaries) > 0 {
 			rawSummaryText := strings.Join(screenshotSummaries, "\n")
-			summaryResult, err := e.analyzer.GenerateSummary(rawSummaryText, periodType)
+			summaryResult, err := e.analyzer.GenerateSummary(withPeriodStats(withThreadCarryover(withJournal(withPeriodNotes(rawSummaryText, periodNotes), periodJournal), threadCarryover), periodStats), periodType)
 			if err != nil {
 				logger.GetLogger().Infof("WARNING: Failed to generate summary for %s: %v",
 					periodKey, err)
@@ -891,13 +1261,17 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 		}
 
 		// Clean summary if it indicates no work activity (remove efficiency analysis and improvement suggestions)
-		periodSummary = cleanSummaryIfNoWorkActivity(periodSummary)
+		periodSummary = cleanSummaryIfNoWorkActivity(&e.config.Summary, periodSummary)
 
-		// Generate analysis only for week and longer periods
-		// Day and below focus on factual records only
+		// Note the sampling rate when part of the screenshots were sampled out during rebuild
+		if note := samplingNote(screenshots); note != "" && periodSummary != "" {
+			periodSummary = periodSummary + "\n\n" + note
+		}
+
+		// Generate analysis only for the configured levels (week and longer by default)
 		// Only generate analysis if there is valid work activity
-		if periodSummary != "" && len(screenshotSummaries) > 0 && shouldGenerateAnalysis(periodType) {
-			if hasValidWorkActivity(periodSummary) {
+		if periodSummary != "" && len(screenshotSummaries) > 0 && e.shouldGenerateAnalysis(periodType) {
+			if hasValidWorkActivity(&e.config.Summary, periodSummary) {
 				analysisResult, err := e.analyzer.AnalyzeBehavior(periodSummary)
 				if err != nil {
 					logger.GetLogger().Infof("WARNING: Failed to perform improvement analysis for %s: %v",
@@ -915,6 +1289,12 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 		}
 	}
 
+	// Oversized day summaries get themed sections, week aggregation reads their executive summary only
+	if periodType == "day" && periodSummary != "" {
+		periodSummary = e.splitOversizedSummary(periodKey, periodSummary)
+	}
+	periodSummary = e.storedCitations(periodType, periodSummary, startTime, endTime)
+
 	summary := &storage.PeriodSummary{
 		PeriodKey:   periodKey,
 		PeriodType:  periodType,
@@ -927,7 +1307,7 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 
 	// Check if summary has valid content before saving
 	// If no valid content, save a placeholder to avoid re-checking in the future
-	if !hasValidContent(summary) {
+	if !hasValidContent(&e.config.Summary, summary) {
 		// Save placeholder to mark that this period has been checked and has no work activity
 		// This avoids re-checking the same period repeatedly when generating higher-level reports
 		placeholderSummary := &storage.PeriodSummary{
@@ -944,8 +1324,9 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			logger.GetLogger().Infof("WARNING: Failed to save placeholder for %s (%s): %v",
 				periodKey, periodType, err)
 		} else {
-			logger.GetLogger().Infof("Saved placeholder for %s (%s): no valid work activity",
+			logger.GetLogger().Debugf("Saved placeholder for %s (%s): no valid work activity",
 				periodKey, periodType)
+			logger.Count("placeholders")
 		}
 
 		// Don't save report file for placeholder
@@ -955,6 +1336,34 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 	if err := e.storage.SavePeriodSummary(summary); err != nil {
 		return fmt.Errorf("failed to save period summary: %w", err)
 	}
+	e.saveLineage(periodKey, lineage.Children, lineage.Screenshots)
+
+	// Extract concrete deliverables once per day, so week/month reports can list outcomes
+	if periodType == "day" && e.config.Deliverables.Enabled {
+		if _, err := e.ExtractDeliverables(startTime); err != nil {
+			logger.GetLogger().Warnf("Failed to extract deliverables for %s: %v", periodKey, err)
+		}
+	}
+	if periodType == "day" && e.config.Issues.Enabled {
+		if _, err := e.CorrelateIssues(startTime); err != nil {
+			logger.GetLogger().Warnf("Failed to correlate issues for %s: %v", periodKey, err)
+		}
+	}
+	if periodType == "day" && e.config.Focus.Enabled {
+		if _, err := ComputeFocusScore(e.config, e.storage, startTime); err != nil {
+			logger.GetLogger().Warnf("Failed to compute focus score for %s: %v", periodKey, err)
+		}
+	}
+	if periodType == "day" && e.config.Reading.Enabled {
+		if _, err := e.GenerateResearchLog(startTime); err != nil {
+			logger.GetLogger().Warnf("Failed to generate research log for %s: %v", periodKey, err)
+		}
+	}
+	if periodType == "day" && e.config.Threads.Enabled {
+		if _, err := e.DetectThreads(startTime); err != nil {
+			logger.GetLogger().Warnf("Failed to detect task threads for %s: %v", periodKey, err)
+		}
+	}
 
 	// Save period summary as report file
 	if err := e.savePeriodSummaryReport(summary); err != nil {
@@ -962,8 +1371,15 @@ func (e *Executor) generateSinglePeriodSummary(now time.Time, periodType string,
 			periodKey, err)
 	}
 
-	logger.GetLogger().Infof("Period summary generated for %s (%s): %d screenshots",
+	// Per-fifteenmin/hour lines would flood long rebuilds, the run summary reports their totals
+	level := logrus.InfoLevel
+	if periodType == "fifteenmin" || periodType == "hour" {
+		level = logrus.DebugLevel
+	}
+	logger.GetLogger().Logf(level, "Period summary generated for %s (%s): %d screenshots",
 		periodKey, periodType, len(allScreenshotIDs))
+	logger.Count("summaries_generated")
+	e.recordRuntimeState(storage.RuntimeLastSummaryPrefix+periodType, periodKey)
 
 	return nil
 }
@@ -1029,8 +1445,8 @@ func (e *Executor) determineActualTimeRange(periodType string, theoreticalStart,
 	// Round to period boundaries based on period type
 	switch periodType {
 	case "day":
-		actualStart := time.Date(earliestTime.Year(), earliestTime.Month(), earliestTime.Day(), 0, 0, 0, 0, earliestTime.Location())
-		actualEnd := time.Date(latestTime.Year(), latestTime.Month(), latestTime.Day(), 23, 59, 59, 0, latestTime.Location())
+		actualStart := e.periodStart(periodType, earliestTime)
+		actualEnd := e.periodStart(periodType, latestTime).AddDate(0, 0, 1).Add(-time.Second)
 		return actualStart, actualEnd, true
 	case "hour":
 		actualStart := time.Date(earliestTime.Year(), earliestTime.Month(), earliestTime.Day(), earliestTime.Hour(), 0, 0, 0, earliestTime.Location())
@@ -1045,36 +1461,20 @@ func (e *Executor) determineActualTimeRange(periodType string, theoreticalStart,
 		actualEnd := time.Date(latestTime.Year(), latestTime.Month(), latestTime.Day(), latestTime.Hour(), roundedMinute+14, 59, 0, latestTime.Location())
 		return actualStart, actualEnd, true
 	case "week":
-		weekday := int(earliestTime.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		actualStart := time.Date(earliestTime.Year(), earliestTime.Month(), earliestTime.Day(), 0, 0, 0, 0, earliestTime.Location())
-		actualStart = actualStart.AddDate(0, 0, -(weekday - 1))
-		weekday = int(latestTime.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		actualEnd := time.Date(latestTime.Year(), latestTime.Month(), latestTime.Day(), 23, 59, 59, 0, latestTime.Location())
-		actualEnd = actualEnd.AddDate(0, 0, 7-weekday)
+		actualStart := e.periodStart(periodType, earliestTime)
+		actualEnd := e.periodStart(periodType, latestTime).AddDate(0, 0, 7).Add(-time.Second)
 		return actualStart, actualEnd, true
 	case "month":
-		actualStart := time.Date(earliestTime.Year(), earliestTime.Month(), 1, 0, 0, 0, 0, earliestTime.Location())
-		actualEnd := time.Date(latestTime.Year(), latestTime.Month(), 1, 0, 0, 0, 0, latestTime.Location())
-		actualEnd = actualEnd.AddDate(0, 1, 0).Add(-time.Second)
+		actualStart := e.periodStart(periodType, earliestTime)
+		actualEnd := e.periodStart(periodType, latestTime).AddDate(0, 1, 0).Add(-time.Second)
 		return actualStart, actualEnd, true
 	case "quarter":
-		quarter := (int(earliestTime.Month())-1)/3 + 1
-		quarterStartMonth := (quarter-1)*3 + 1
-		actualStart := time.Date(earliestTime.Year(), time.Month(quarterStartMonth), 1, 0, 0, 0, 0, earliestTime.Location())
-		quarter = (int(latestTime.Month())-1)/3 + 1
-		quarterEndMonth := quarter * 3
-		actualEnd := time.Date(latestTime.Year(), time.Month(quarterEndMonth), 1, 0, 0, 0, 0, latestTime.Location())
-		actualEnd = actualEnd.AddDate(0, 1, 0).Add(-time.Second)
+		actualStart := e.periodStart(periodType, earliestTime)
+		actualEnd := e.periodStart(periodType, latestTime).AddDate(0, 3, 0).Add(-time.Second)
 		return actualStart, actualEnd, true
 	case "year":
-		actualStart := time.Date(earliestTime.Year(), 1, 1, 0, 0, 0, 0, earliestTime.Location())
-		actualEnd := time.Date(latestTime.Year(), 12, 31, 23, 59, 59, 0, latestTime.Location())
+		actualStart := e.periodStart(periodType, earliestTime)
+		actualEnd := e.periodStart(periodType, latestTime).AddDate(1, 0, 0).Add(-time.Second)
 		return actualStart, actualEnd, true
 	default:
 		// For unknown types, use the theoretical range
@@ -1085,6 +1485,11 @@ func (e *Executor) determineActualTimeRange(periodType string, theoreticalStart,
 // getLowerLevelPeriodType returns the lower-level period type for hierarchical aggregation
 // Returns empty string if this is the lowest level (should aggregate from screenshots)
 func (e *Executor) getLowerLevelPeriodType(periodType string) string {
+	return LowerLevelPeriodType(periodType)
+}
+
+// LowerLevelPeriodType returns the period type a period type aggregates from, empty for the lowest level
+func LowerLevelPeriodType(periodType string) string {
 	hierarchy := map[string]string{
 		"year":         "quarter",
 		"quarter":      "month",
@@ -1099,9 +1504,14 @@ func (e *Executor) getLowerLevelPeriodType(periodType string) string {
 }
 
 // shouldGenerateAnalysis determines if a period type should generate behavior analysis
-// Only week and longer periods have sufficient data for meaningful analysis
-// Day and below focus on factual records only
-func shouldGenerateAnalysis(periodType string) bool {
+// Controlled by analysis.enabled and analysis.levels (week and longer by default,
+// day and below focus on factual records only)
+func (e *Executor) shouldGenerateAnalysis(periodType string) bool {
+	return e.config.Analysis.ShouldAnalyze(periodType)
+}
+
+// isWeekOrLonger checks if a period type is week or longer
+func isWeekOrLonger(periodType string) bool {
 	switch periodType {
 	case "week", "month", "quarter", "year":
 		return true
@@ -1113,7 +1523,7 @@ func shouldGenerateAnalysis(periodType string) bool {
 // isInvalidSummary checks if a summary is invalid (contains "no work activity" message)
 // Invalid summaries should be regenerated from lower level
 // Also checks for placeholder markers (which should not be regenerated, just skipped)
-func isInvalidSummary(summary string) bool {
+func isInvalidSummary(thresholds *config.SummaryConfig, summary string) bool {
 	if summary == "" {
 		return true
 	}
@@ -1162,7 +1572,7 @@ func isInvalidSummary(summary string) bool {
 			remaining = strings.TrimSpace(remaining)
 
 			// If after removing the pattern, there's little content left, it's invalid
-			if len(remaining) < 50 {
+			if len(remaining) < thresholds.GetMinRemainingChars() {
 				return true
 			}
 		}
@@ -1174,13 +1584,13 @@ func isInvalidSummary(summary string) bool {
 // cleanSummaryIfNoWorkActivity removes efficiency analysis and improvement suggestions
 // from summary if it indicates no work activity
 // Returns empty string if no work activity detected
-func cleanSummaryIfNoWorkActivity(summary string) string {
+func cleanSummaryIfNoWorkActivity(thresholds *config.SummaryConfig, summary string) string {
 	if summary == "" {
 		return summary
 	}
 
 	// Check if summary indicates no work activity
-	if !hasValidWorkActivity(summary) {
+	if !hasValidWorkActivity(thresholds, summary) {
 		// Return empty string - no content should be generated when there's no work activity
 		return ""
 	}
@@ -1259,7 +1669,7 @@ func cleanSummaryIfNoWorkActivity(summary string) string {
 
 // hasValidWorkActivity checks if the summary indicates valid work activity
 // Returns false if summary indicates no work activity (desktop/lock screen only)
-func hasValidWorkActivity(summary string) bool {
+func hasValidWorkActivity(thresholds *config.SummaryConfig, summary string) bool {
 	if summary == "" {
 		return false
 	}
@@ -1303,9 +1713,9 @@ func hasValidWorkActivity(summary string) bool {
 			normalized = strings.ReplaceAll(normalized, "#", "")
 			normalized = strings.TrimSpace(normalized)
 
-			// If summary is very short (less than 200 chars after normalization),
+			// If summary is very short (less than short_summary_chars after normalization),
 			// and contains no-work indicator, it's likely invalid
-			if len(normalized) < 200 {
+			if len(normalized) < thresholds.GetShortSummaryChars() {
 				return false
 			}
 
@@ -1340,7 +1750,7 @@ func hasValidWorkActivity(summary string) bool {
 
 			// If after removing no-work indicators, there's very little content left,
 			// it's likely an invalid summary
-			if len(remaining) < 50 {
+			if len(remaining) < thresholds.GetMinRemainingChars() {
 				return false
 			}
 		}
@@ -1412,36 +1822,6 @@ func (e *Executor) getAllHigherLevelTypes(periodType string) []string {
 	return higherLevels
 }
 
-// isNetworkOrRateLimitError 检查是否是网络错误或限流错误
-func isNetworkOrRateLimitError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := err.Error()
-	networkErrors := []string{
-		"timeout",
-		"i/o timeout",
-		"dial tcp",
-		"connection refused",
-		"connection reset",
-		"rate limit",
-		"status 429",
-		"status 502",
-		"status 503",
-		"status 504",
-		"no such host",
-	}
-
-	for _, netErr := range networkErrors {
-		if strings.Contains(strings.ToLower(errStr), netErr) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // deleteExistingSummariesInRange deletes all period summaries of a specific type within a time range
 func (e *Executor) deleteExistingSummariesInRange(periodType string, startTime, endTime time.Time) error {
 	summaries, err := e.storage.QueryPeriodSummaries(periodType, startTime, endTime)
@@ -1451,6 +1831,10 @@ func (e *Executor) deleteExistingSummariesInRange(periodType string, startTime,
 
 	logger.GetLogger().Infof("Found %d existing %s summaries to delete", len(summaries), periodType)
 	for _, summary := range summaries {
+		if e.isPeriodLocked(summary.PeriodKey) {
+			logger.GetLogger().Infof("Keeping locked summary: %s", summary.PeriodKey)
+			continue
+		}
 		if err := e.storage.DeletePeriodSummary(summary.PeriodKey); err != nil {
 			logger.GetLogger().Infof("WARNING: Failed to delete summary %s: %v", summary.PeriodKey, err)
 		} else {
@@ -1478,22 +1862,9 @@ func (e *Executor) generateHigherLevelSummaries(startPeriodType string, startTim
 		// Determine the time range for this higher level based on startTime
 		var periodTime time.Time
 		switch higherLevelType {
-		case "work-segment":
+		case "work-segment", "day", "week", "month", "year":
 			// Work-segment is per day, use the day of startTime
-			periodTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
-		case "day":
-			periodTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
-		case "week":
-			weekday := int(startTime.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
-			periodTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
-			periodTime = periodTime.AddDate(0, 0, -(weekday - 1))
-		case "month":
-			periodTime = time.Date(startTime.Year(), startTime.Month(), 1, 0, 0, 0, 0, startTime.Location())
-		case "year":
-			periodTime = time.Date(startTime.Year(), 1, 1, 0, 0, 0, 0, startTime.Location())
+			periodTime = e.periodStart(higherLevelType, startTime)
 		default:
 			logger.GetLogger().Infof("WARNING: Unsupported higher-level type %s, skipping", higherLevelType)
 			continue
@@ -1515,21 +1886,22 @@ func (e *Executor) generateHigherLevelSummaries(startPeriodType string, startTim
 }
 
 // generateWorkSegmentSummary generates a work-segment summary for a specific day
-// Work-segment divides work hours (9:30-20:00) into multiple 2-hour segments
+// Work-segment divides the day's actual working time into segments of work_hours.segment_minutes (2 hours by default):
+// the work hours (9:30-20:00) are clamped to the hours of the first and last screenshot, so short days get no empty segments;
+// with workday.use_detected the hours from the first to the last non-idle capture are divided instead
 // Each segment aggregates from hour summaries
 func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScreenshots bool) error {
-	workHours := e.config.Screenshot.WorkHours
-	startHour := workHours.StartHour
-	startMinute := workHours.StartMinute
-	endHour := workHours.EndHour
-	endMinute := workHours.EndMinute
-
-	// Calculate work start and end time for this day
-	workStart := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), startHour, startMinute, 0, 0, dayStart.Location())
-	workEnd := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), endHour, endMinute, 0, 0, dayStart.Location())
-
-	// Divide work hours into 2-hour segments
-	segmentDuration := 2 * time.Hour
+	workStart, workEnd, ok, err := e.workSegmentRange(dayStart)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.GetLogger().Infof("No screenshots in work hours of %s, skipping work-segment summaries", dayStart.Format("2006-01-02"))
+		return nil
+	}
+
+	// Divide the working time into segments
+	segmentDuration := e.config.Screenshot.WorkHours.SegmentDuration()
 	segments := []struct {
 		start time.Time
 		end   time.Time
@@ -1544,8 +1916,8 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 			segmentEnd = workEnd
 		}
 
-		// Format: YYYY-MM-DD-segment-N (e.g., 2025-11-21-segment-0)
-		segmentKey := fmt.Sprintf("%s-segment-%d", dayStart.Format("2006-01-02"), segmentIndex)
+		// Format: work-segment:YYYY-MM-DD#N (e.g., work-segment:2025-11-21#0)
+		segmentKey := storage.WorkSegmentPeriodKey(dayStart, segmentIndex)
 
 		segments = append(segments, struct {
 			start time.Time
@@ -1568,7 +1940,15 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 		if err != nil {
 			logger.GetLogger().Infof("WARNING: Failed to check work-segment summary %s: %v",
 				segment.key, err)
+		} else if e.isPeriodLocked(segment.key) {
+			logger.GetLogger().Infof("Skipping work-segment %s: period is locked", segment.key)
 		} else if existing == nil || forceFromScreenshots {
+			if err := e.CheckManualEdits(&storage.PeriodSummary{PeriodKey: segment.key, PeriodType: "work-segment",
+				StartTime: segment.start, EndTime: segment.end}); err != nil {
+				logger.GetLogger().Warnf("Keeping work-segment %s: %v", segment.key, err)
+				continue
+			}
+
 			// Query hour summaries within this segment (only work hours)
 			hourSummaries, err := e.storage.QueryPeriodSummaries("hour", segment.start, segment.end)
 			if err != nil {
@@ -1577,10 +1957,10 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 				continue
 			}
 
-			// Filter hour summaries to only include those within work hours
+			// Filter hour summaries to only include those within work hours (the detected workday already is)
 			var workHourSummaries []*storage.PeriodSummary
 			for _, s := range hourSummaries {
-				if e.config.Screenshot.WorkHours.IsWorkTime(s.StartTime) {
+				if e.config.Workday.UseDetected || e.config.Screenshot.WorkHours.IsWorkTime(s.StartTime) {
 					workHourSummaries = append(workHourSummaries, s)
 				}
 			}
@@ -1594,9 +1974,11 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 			// Generate segment summary from hour summaries
 			var summaryTexts []string
 			var allScreenshotIDs []string
+			var children []string
 			for _, s := range workHourSummaries {
 				if s.Summary != "" {
-					summaryTexts = append(summaryTexts, s.Summary)
+					summaryTexts = append(summaryTexts, e.markLockedSummary(s))
+					children = append(children, s.PeriodKey)
 				}
 				if s.Screenshots != "" {
 					ids := strings.Split(s.Screenshots, ",")
@@ -1604,20 +1986,23 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 				}
 			}
 
+			summaryTexts = e.dedupSiblingSummaries(summaryTexts, segment.key)
+
 			var periodSummary string
 			if len(summaryTexts) > 0 {
 				if len(summaryTexts) == 1 {
-					periodSummary = summaryTexts[0]
+					periodSummary = stripLockedLabels(summaryTexts[0])
 				} else {
 					// Combine all summaries and generate in one LLM call
 					// No rolling summary - all summaries are merged and processed together
 					combined := strings.Join(summaryTexts, "\n\n")
-					generatedSummary, err := e.analyzer.GenerateSummary(combined, "work-segment")
+					segmentNotes := e.readPeriodNotes(&storage.PeriodSummary{PeriodKey: segment.key, PeriodType: "work-segment", StartTime: segment.start, EndTime: segment.end})
+					generatedSummary, err := e.analyzer.GenerateSummary(withPeriodStats(withPeriodNotes(withLockedNote(combined), segmentNotes), e.periodStats("work-segment", segment.start, segment.end)), "work-segment")
 					if err != nil {
 						logger.GetLogger().Infof("WARNING: Failed to generate summary for segment %s: %v",
 							segment.key, err)
 						// Fallback: combine all summaries
-						periodSummary = combined
+						periodSummary = stripLockedLabels(combined)
 					} else {
 						periodSummary = generatedSummary
 					}
@@ -1625,6 +2010,7 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 			} else {
 				periodSummary = fmt.Sprintf("No work activity in segment %s", segment.key)
 			}
+			periodSummary = e.storedCitations("work-segment", periodSummary, segment.start, segment.end)
 
 			// Save segment summary
 			summary := &storage.PeriodSummary{
@@ -1642,6 +2028,14 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 					segment.key, err)
 				continue
 			}
+			e.saveLineage(segment.key, children, nil)
+
+			// Synthesize meeting notes for meeting blocks, attached to the segment report
+			if e.config.Meetings.Enabled {
+				if _, err := e.GenerateMeetingNotes(summary); err != nil {
+					logger.GetLogger().Warnf("Failed to generate meeting notes for %s: %v", segment.key, err)
+				}
+			}
 
 			// Save report file
 			if err := e.savePeriodSummaryReport(summary); err != nil {
@@ -1651,6 +2045,7 @@ func (e *Executor) generateWorkSegmentSummary(dayStart time.Time, forceFromScree
 
 			logger.GetLogger().Infof("Work-segment summary generated for %s: %d hour summaries",
 				segment.key, len(workHourSummaries))
+			e.recordRuntimeState(storage.RuntimeLastSummaryPrefix+"work-segment", segment.key)
 		}
 	}
 
@@ -1690,7 +2085,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 			if fifteenminEnd.After(endTime) {
 				fifteenminEnd = endTime
 			}
-			fifteenminKey := current.Format("2006-01-02-15-04")
+			fifteenminKey := storage.FormatPeriodKey("fifteenmin", current)
 
 			// Check if summary already exists
 			existing, err := e.storage.GetPeriodSummary(fifteenminKey)
@@ -1746,31 +2141,17 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 				semaphore <- struct{}{}        // Acquire semaphore
 				defer func() { <-semaphore }() // Release semaphore
 
-				// Retry mechanism
-				maxRetries := 3
-				var generateErr error
-
-				for retryAttempt := 0; retryAttempt < maxRetries; retryAttempt++ {
-					if retryAttempt > 0 {
-						waitTime := time.Duration(retryAttempt*30) * time.Second
-						logger.GetLogger().Infof("Retrying fifteenmin %s (attempt %d/%d, waiting %v)",
-							j.key, retryAttempt+1, maxRetries, waitTime)
-						time.Sleep(waitTime)
-					}
-
-					generateErr = e.generateSinglePeriodSummary(j.start, "fifteenmin", forceFromScreenshots, isManual)
-					if generateErr == nil {
-						break
-					}
-
-					if retryAttempt < maxRetries-1 && isNetworkOrRateLimitError(generateErr) {
-						logger.GetLogger().Infof("WARNING: Network/rate limit error for %s, will retry: %v",
-							j.key, generateErr)
-						continue
-					}
-				}
+				// API calls are retried by the analyzer, the whole summary again per openai.retry.summary
+				generateErr := e.summaryRetry.Retry(func() error {
+					return e.generateSinglePeriodSummary(j.start, "fifteenmin", forceFromScreenshots, isManual)
+				}, func(retry int, wait time.Duration, err error) {
+					logger.Throttledf(logrus.WarnLevel, "fifteenmin-retry", "Retrying fifteenmin %s (retry %d/%d, waiting %v): %v",
+						j.key, retry, e.summaryRetry.MaxRetries, wait, err)
+					logger.Count("retries")
+				})
 
 				if generateErr != nil {
+					logger.Count("summaries_failed")
 					errChan <- fmt.Errorf("%s: %w", j.key, generateErr)
 				} else {
 					successChan <- j.key
@@ -1780,7 +2161,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 						rate := float64(count) / elapsed.Seconds()
 						remaining := len(jobs) - int(count)
 						eta := time.Duration(float64(remaining)/rate) * time.Second
-						logger.GetLogger().Infof("Fifteenmin progress: %d/%d (%.1f%%), rate: %.1f/s, ETA: %v",
+						logger.Throttledf(logrus.InfoLevel, "fifteenmin-progress", "Fifteenmin progress: %d/%d (%.1f%%), rate: %.1f/s, ETA: %v",
 							count, len(jobs), float64(count)/float64(len(jobs))*100, rate, eta.Round(time.Second))
 					}
 				}
@@ -1835,7 +2216,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 				hourEnd = endTime
 			}
 			// Check if summary already exists
-			hourKey := current.Format("2006-01-02-15")
+			hourKey := storage.FormatPeriodKey("hour", current)
 			existing, err := e.storage.GetPeriodSummary(hourKey)
 			if err != nil {
 				logger.GetLogger().Infof("WARNING: Failed to check hour summary %s: %v",
@@ -1847,13 +2228,14 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 						hourKey, err)
 				}
 				// Then generate the hour summary
-				logger.GetLogger().Infof("Generating hour summary %d/%d: %s",
+				logger.GetLogger().Debugf("Generating hour summary %d/%d: %s",
 					processed+1, totalHours, hourKey)
 				if err := e.generateSinglePeriodSummary(current, "hour", forceFromScreenshots, isManual); err != nil {
 					logger.GetLogger().Infof("WARNING: Failed to generate hour summary for %s: %v",
 						hourKey, err)
+					logger.Count("summaries_failed")
 				} else {
-					logger.GetLogger().Infof("Hour summary %s completed", hourKey)
+					logger.GetLogger().Debugf("Hour summary %s completed", hourKey)
 				}
 			}
 			processed++
@@ -1870,7 +2252,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 		// Generate all work-segment summaries in the range
 		current := startTime
 		for current.Before(endTime) {
-			dayStart := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
+			dayStart := e.periodStart("day", current)
 			dayEnd := dayStart.AddDate(0, 0, 1)
 			if dayEnd.After(endTime) {
 				dayEnd = endTime
@@ -1892,7 +2274,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 		current := startTime
 		now := time.Now()
 		for current.Before(endTime) {
-			dayStart := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
+			dayStart := e.periodStart("day", current)
 			dayEnd := dayStart.AddDate(0, 0, 1)
 
 			// Check if this day period is complete (has naturally ended)
@@ -1903,7 +2285,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 				isComplete = false // Periods truncated by parent range are incomplete
 			}
 
-			dayKey := dayStart.Format("2006-01-02")
+			dayKey := storage.FormatPeriodKey("day", dayStart)
 
 			if isComplete {
 				// Complete period: generate natural period summary
@@ -1946,12 +2328,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 		current := startTime
 		now := time.Now()
 		for current.Before(endTime) {
-			weekday := int(current.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
-			weekStart := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
-			weekStart = weekStart.AddDate(0, 0, -(weekday - 1))
+			weekStart := e.periodStart("week", current)
 			weekEnd := weekStart.AddDate(0, 0, 7)
 
 			// Check if this week period is complete (has naturally ended)
@@ -1962,7 +2339,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 				isComplete = false // Periods truncated by parent range are incomplete
 			}
 
-			weekKey := weekStart.Format("2006-01-02") + "-week"
+			weekKey := storage.FormatPeriodKey("week", weekStart)
 
 			if isComplete {
 				// Complete period: generate natural period summary
@@ -1997,7 +2374,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 		current := startTime
 		now := time.Now()
 		for current.Before(endTime) {
-			monthStart := time.Date(current.Year(), current.Month(), 1, 0, 0, 0, 0, current.Location())
+			monthStart := e.periodStart("month", current)
 			monthEnd := monthStart.AddDate(0, 1, 0)
 
 			// Check if this month period is complete (has naturally ended)
@@ -2008,7 +2385,7 @@ func (e *Executor) generateLowerLevelSummaries(periodType string, startTime, end
 				isComplete = false // Periods truncated by parent range are incomplete
 			}
 
-			monthKey := monthStart.Format("2006-01")
+			monthKey := storage.FormatPeriodKey("month", monthStart)
 
 			if isComplete {
 				// Complete period: generate natural period summary
@@ -2047,13 +2424,18 @@ func (e *Executor) updateHourSummary(record *storage.ScreenshotRecord) error {
 	if err != nil {
 		return fmt.Errorf("failed to get screenshots: %w", err)
 	}
+	// Personal-time screenshots are summarized in the personal track only
+	screenshots = e.workScreenshots(screenshots)
+	if len(screenshots) == 0 {
+		return nil
+	}
 
 	var ids []string
 	var screenshotSummaries []string
 	for _, s := range screenshots {
 		ids = append(ids, s.ID)
 		// s.Analysis contains factual description (semantically it's a summary)
-		if s.Analysis != "" && s.Analysis != "Analysis failed" {
+		if s.HasAnalysis() {
 			// Filter out desktop/lock screen screenshots
 			if !isDesktopOrLockScreenAnalysis(s.Analysis) {
 				screenshotSummaries = append(screenshotSummaries, s.Analysis)
@@ -2084,12 +2466,17 @@ func (e *Executor) saveReport(record *storage.ScreenshotRecord) error {
 	reportContent := e.generateReportContent(record)
 
 	// 使用 StorageManager 保存报告（支持新的层级嵌套结构）
-	relativePath, err := e.storageManager.SaveReport(record.Timestamp, reportContent)
+	// Personal-time screenshots go to the personal report tree (24/7 mode)
+	manager, reportsPath := e.storageManager, e.config.Storage.ReportsPath
+	if e.personalStorageManager != nil && e.config.IsPersonalTime(record.Timestamp) {
+		manager, reportsPath = e.personalStorageManager, e.config.Personal.ReportsPath
+	}
+	relativePath, err := manager.SaveReport(record.Timestamp, reportContent)
 	if err != nil {
 		return fmt.Errorf("failed to save report: %w", err)
 	}
 
-	reportPath := filepath.Join(e.config.Storage.ReportsPath, relativePath)
+	reportPath := filepath.Join(reportsPath, relativePath)
 	logger.GetLogger().Infof("Report saved: %s", reportPath)
 	return nil
 }
@@ -2099,21 +2486,28 @@ func (e *Executor) generateReportContent(record *storage.ScreenshotRecord) strin
 
 	// Header
 	sb.WriteString("# 截图分析报告\n\n")
-	sb.WriteString(fmt.Sprintf("**时间**: %s\n\n", record.Timestamp.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("**时间**: %s\n\n", datefmt.DateTime(record.Timestamp)))
 	sb.WriteString(fmt.Sprintf("**截图ID**: %s\n\n", record.ID))
 	sb.WriteString(fmt.Sprintf("**截图路径**: %s\n\n", record.ImagePath))
 	sb.WriteString(fmt.Sprintf("**屏幕ID**: %d\n\n", record.ScreenID))
+	if source, err := e.storage.GetScreenshotSource(record.ImagePath); err == nil && source != nil {
+		sb.WriteString(fmt.Sprintf("**来源**: %s\n\n", source.Agent))
+	}
 	sb.WriteString("---\n\n")
 
 	// Summary content: factual description of what user is doing
-	if record.Analysis != "" && !strings.HasPrefix(record.Analysis, "Analysis failed") {
+	if record.HasAnalysis() {
 		sb.WriteString("## 事实总结\n\n")
 		sb.WriteString(record.Analysis)
 		sb.WriteString("\n\n")
-	} else if record.Analysis != "" {
+	} else if record.AnalysisUnavailable() {
+		reason := record.AnalysisError
+		if reason == "" {
+			reason = record.Analysis // Legacy failure record
+		}
 		sb.WriteString("## 事实总结\n\n")
 		sb.WriteString("**生成失败**: ")
-		sb.WriteString(record.Analysis)
+		sb.WriteString(reason)
 		sb.WriteString("\n\n")
 	} else {
 		sb.WriteString("## 事实总结\n\n")
@@ -2122,13 +2516,14 @@ func (e *Executor) generateReportContent(record *storage.ScreenshotRecord) strin
 
 	// Footer
 	sb.WriteString("---\n\n")
-	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", time.Now().Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", datefmt.DateTime(time.Now())))
 
 	return sb.String()
 }
 
 // regenerateReportsForAnalyzedScreenshots regenerates reports for screenshots that have summary
 // but might have outdated report files (e.g., generated before summary completed)
+// An hour is only rescanned when it has new analyses since its last check, at most every regenerationDebounce
 func (e *Executor) regenerateReportsForAnalyzedScreenshots(hourKey string) {
 	// Get all screenshots for this hour that have analysis
 	screenshots, err := e.storage.GetScreenshotsByHourKey(hourKey)
@@ -2138,10 +2533,23 @@ func (e *Executor) regenerateReportsForAnalyzedScreenshots(hourKey string) {
 		return
 	}
 
-	regenerated := 0
+	// Skip hours without new analyses since the last check, and debounce frequent triggers
+	analyzed := 0
+	for _, record := range screenshots {
+		if record.HasAnalysis() {
+			analyzed++
+		}
+	}
+	now := time.Now()
+	if !e.regeneration.due(hourKey, analyzed, now) {
+		logger.GetLogger().Debugf("No new analyses for hour %s since the last report check, skipping regeneration", hourKey)
+		return
+	}
+
+	regenerated, failed := 0, 0
 	for _, record := range screenshots {
 		// Only regenerate if screenshot has summary but report might be outdated
-		if record.Analysis != "" && !strings.HasPrefix(record.Analysis, "Analysis failed") {
+		if record.HasAnalysis() {
 			// Check if report exists and might be outdated
 			yearDir := record.Timestamp.Format("2006")
 			monthDir := record.Timestamp.Format("01")
@@ -2158,12 +2566,18 @@ func (e *Executor) regenerateReportsForAnalyzedScreenshots(hourKey string) {
 					// Regenerate report with current summary
 					if err := e.saveReport(record); err == nil {
 						regenerated++
+					} else {
+						failed++
 					}
 				}
 			}
 		}
 	}
 
+	// Failed reports are retried by the next check
+	if failed == 0 {
+		e.regeneration.done(hourKey, analyzed, now)
+	}
 	if regenerated > 0 {
 		logger.GetLogger().Infof("Regenerated %d outdated reports for hour %s",
 			regenerated, hourKey)
@@ -2173,7 +2587,7 @@ func (e *Executor) regenerateReportsForAnalyzedScreenshots(hourKey string) {
 // hasValidContent checks if a period summary has meaningful content
 // Returns true if Summary or Analysis contains valid, non-empty content
 // Placeholder summaries (marked with __NO_WORK_ACTIVITY_PLACEHOLDER__) are considered invalid
-func hasValidContent(summary *storage.PeriodSummary) bool {
+func hasValidContent(thresholds *config.SummaryConfig, summary *storage.PeriodSummary) bool {
 	// Check for placeholder marker - these mark periods that have been checked and have no work activity
 	if summary.Summary == "__NO_WORK_ACTIVITY_PLACEHOLDER__" {
 		return false
@@ -2223,7 +2637,7 @@ func hasValidContent(summary *storage.PeriodSummary) bool {
 
 				// If after removing the pattern and punctuation, there's little left, it's invalid
 				// But if there's substantial content (like detailed analysis), it's valid
-				if len(remaining) < 50 {
+				if len(remaining) < thresholds.GetMinRemainingChars() {
 					isOnlyNoActivity = true
 					break
 				}
@@ -2263,90 +2677,89 @@ func (e *Executor) calculateReportPath(summary *storage.PeriodSummary) (string,
 	var summaryDir string
 	var filename string
 	periodType := summary.PeriodType
+	// Hours before storage.day_start_hour are filed under the previous day
+	date := e.config.Storage.LogicalDate(summary.StartTime)
 
 	switch periodType {
 	case "year":
-		yearDir := summary.StartTime.Format("2006")
+		yearDir := date.Format("2006")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir)
 		filename = "year.md"
 	case "quarter":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir)
 		filename = fmt.Sprintf("quarter-Q%d.md", quarter)
 	case "month":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir)
 		filename = "month.md"
 	case "week":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir)
 		// 使用Calendar Week（月内周号）
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		filename = fmt.Sprintf("week-W%d.md", weekNum)
 	case "work-segment":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		// 计算Calendar Week
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
-		dayDir := summary.StartTime.Format("02")
+		dayDir := date.Format("02")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir)
-		// Extract segment index from period key (format: YYYY-MM-DD-segment-N)
-		// Use period key directly as filename
-		parts := strings.Split(summary.PeriodKey, "-")
-		if len(parts) >= 4 && parts[3] == "segment" {
-			segmentNum := parts[4]
-			filename = fmt.Sprintf("work-segment-%s.md", segmentNum)
+		// Extract segment index from period key (format: work-segment:YYYY-MM-DD#N)
+		if key, err := storage.ParsePeriodKey(summary.PeriodKey); err == nil && key.Type == "work-segment" {
+			filename = fmt.Sprintf("work-segment-%d.md", key.Segment)
 		} else {
 			filename = fmt.Sprintf("%s.md", summary.PeriodKey)
 		}
 	case "day":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		// 计算Calendar Week
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
-		dayDir := summary.StartTime.Format("02")
+		dayDir := date.Format("02")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir)
 		filename = "day.md"
 	case "hour":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		// 计算Calendar Week
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
-		dayDir := summary.StartTime.Format("02")
+		dayDir := date.Format("02")
 		hourDir := summary.StartTime.Format("15")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir, hourDir)
 		filename = "hour.md"
 	case "fifteenmin":
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		// 计算Calendar Week
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
-		dayDir := summary.StartTime.Format("02")
+		dayDir := date.Format("02")
 		hourDir := summary.StartTime.Format("15")
 		// Directory structure stops at hour level, minute info goes to filename
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir, hourDir)
@@ -2355,15 +2768,15 @@ func (e *Executor) calculateReportPath(summary *storage.PeriodSummary) (string,
 	default:
 		// For unknown types, use standard directory structure
 		// This should not happen for standard period types, but handle gracefully
-		yearDir := summary.StartTime.Format("2006")
-		quarter := (int(summary.StartTime.Month())-1)/3 + 1
+		yearDir := date.Format("2006")
+		quarter := (int(date.Month())-1)/3 + 1
 		quarterDir := fmt.Sprintf("Q%d", quarter)
-		monthDir := summary.StartTime.Format("01")
+		monthDir := date.Format("01")
 		// 计算Calendar Week
-		day := summary.StartTime.Day()
+		day := date.Day()
 		weekNum := ((day - 1) / 7) + 1
 		weekDir := fmt.Sprintf("W%d", weekNum)
-		dayDir := summary.StartTime.Format("02")
+		dayDir := date.Format("02")
 		summaryDir = filepath.Join(e.config.Storage.ReportsPath, yearDir, quarterDir, monthDir, weekDir, dayDir)
 		// Use period type as filename, not period key, to avoid generating files like "2025-11-19-day.md"
 		filename = fmt.Sprintf("%s.md", summary.PeriodType)
@@ -2385,7 +2798,7 @@ func (e *Executor) savePeriodSummaryReport(summary *storage.PeriodSummary) error
 	}
 
 	// Check if summary has valid content before saving
-	if !hasValidContent(summary) {
+	if !hasValidContent(&e.config.Summary, summary) {
 		// Calculate report path to check if file exists and delete it
 		reportPath, err := e.calculateReportPath(summary)
 		if err != nil {
@@ -2393,9 +2806,11 @@ func (e *Executor) savePeriodSummaryReport(summary *storage.PeriodSummary) error
 			return nil
 		}
 
-		// Delete existing report file if it exists
-		if _, err := os.Stat(reportPath); err == nil {
-			if err := os.Remove(reportPath); err == nil {
+		// Delete existing report file if it exists, unless the user edited it
+		if existing, err := os.ReadFile(reportPath); err == nil {
+			if e.config.Storage.ManualEdits != "overwrite" && e.reportEdited(reportPath, string(existing)) {
+				logger.GetLogger().Warnf("Keeping %s: %v", reportPath, ErrReportEdited)
+			} else if err := os.Remove(reportPath); err == nil {
 				logger.GetLogger().Infof("Deleted empty report file: %s", reportPath)
 			}
 		}
@@ -2425,38 +2840,263 @@ func (e *Executor) savePeriodSummaryReport(summary *storage.PeriodSummary) error
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("# %s周期总结报告\n\n", getPeriodTypeName(summary.PeriodType)))
 	sb.WriteString(fmt.Sprintf("**周期类型**: %s\n\n", summary.PeriodType))
-	sb.WriteString(fmt.Sprintf("**开始时间**: %s\n\n", summary.StartTime.Format("2006-01-02 15:04:05")))
-	sb.WriteString(fmt.Sprintf("**结束时间**: %s\n\n", summary.EndTime.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("**开始时间**: %s\n\n", datefmt.DateTime(summary.StartTime)))
+	sb.WriteString(fmt.Sprintf("**结束时间**: %s\n\n", datefmt.DateTime(summary.EndTime)))
 	sb.WriteString(fmt.Sprintf("**截图数量**: %d\n\n", len(strings.Split(summary.Screenshots, ","))))
+	if embargoed := e.config.EmbargoOccurrences(summary.StartTime, summary.EndTime); len(embargoed) > 0 {
+		sb.WriteString(fmt.Sprintf("**屏蔽时段**: %s\n\n", formatEmbargoed(embargoed)))
+	}
 	sb.WriteString("---\n\n")
 
 	// Summary section: factual information
+	// Citation tags are rendered as numbered footnotes for cited levels and stripped elsewhere
+	summaryText := summary.Summary
+	var cited []*storage.ScreenshotRecord
+	if analyzer.CitedPeriodTypes[summary.PeriodType] && e.config.Summary.Citations {
+		screenshots, err := e.storage.QueryByDateRange(summary.StartTime, summary.EndTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query screenshots for citations of %s: %v", summary.PeriodKey, err)
+			summaryText = stripCitations(summaryText)
+		} else {
+			summaryText, cited = renderCitations(summaryText, screenshots)
+		}
+	} else {
+		summaryText = stripCitations(summaryText)
+	}
+
 	sb.WriteString("## 事实总结\n\n")
-	if summary.Summary != "" {
-		sb.WriteString(summary.Summary)
+	if summaryText != "" {
+		sb.WriteString(summaryText)
 	} else {
 		sb.WriteString("暂无数据")
 	}
 	sb.WriteString("\n\n")
 
+	// Task threads section: the day's work grouped by thread rather than chronology (day only)
+	if summary.PeriodType == "day" && e.config.Threads.Enabled {
+		if section, err := e.threadsSection(summary); err != nil {
+			logger.GetLogger().Warnf("Failed to render task threads for %s: %v", summary.PeriodKey, err)
+		} else if section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Manual notes section: notes.md / <report>-notes.md dropped by the user
+	if notes := e.readPeriodNotes(summary); notes != "" {
+		sb.WriteString("---\n\n")
+		sb.WriteString("## 手动备注\n\n")
+		sb.WriteString(notes)
+		sb.WriteString("\n\n")
+	}
+
+	// Meeting notes section: notes synthesized from meeting blocks (work-segment only)
+	if summary.PeriodType == "work-segment" {
+		notes, err := e.storage.GetAttachments(summary.PeriodKey, storage.AttachmentMeetingNote)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to load meeting notes for %s: %v", summary.PeriodKey, err)
+		}
+		if len(notes) > 0 {
+			sb.WriteString("---\n\n")
+			sb.WriteString("## 会议纪要\n\n")
+			for _, note := range notes {
+				sb.WriteString(fmt.Sprintf("### %s\n\n", note.Title))
+				sb.WriteString(note.Content)
+				sb.WriteString("\n\n")
+			}
+		}
+	}
+
+	// Journal section: end-of-day reflection answers (day only)
+	if summary.PeriodType == "day" {
+		answers, err := e.storage.GetAttachments(summary.PeriodKey, storage.AttachmentJournal)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to load journal for %s: %v", summary.PeriodKey, err)
+		}
+		if len(answers) > 0 {
+			sb.WriteString("---\n\n")
+			sb.WriteString("## 每日反思\n\n")
+			for _, a := range answers {
+				sb.WriteString(fmt.Sprintf("**%s**\n\n%s\n\n", a.Title, a.Content))
+			}
+		}
+	}
+
+	// Research log section: what was read/researched (day only)
+	if summary.PeriodType == "day" {
+		logs, err := e.storage.GetAttachments(summary.PeriodKey, storage.AttachmentResearchLog)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to load research log for %s: %v", summary.PeriodKey, err)
+		}
+		for _, log := range logs {
+			sb.WriteString("---\n\n")
+			sb.WriteString("## 研究日志\n\n")
+			sb.WriteString(log.Content)
+			sb.WriteString("\n\n")
+		}
+	}
+
+	// Highlights section: starred screenshots (day and week)
+	if highlightPeriodTypes[summary.PeriodType] {
+		starred, err := e.storage.QueryStarred(summary.StartTime, summary.EndTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query starred screenshots for %s: %v", summary.PeriodKey, err)
+		} else if section := formatHighlightsSection(starred, summaryDir); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Unknown time section: captured time without usable analyses (week only)
+	if summary.PeriodType == "week" {
+		screenshots, err := e.storage.QueryByDateRange(summary.StartTime, summary.EndTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query screenshots for unknown time of %s: %v", summary.PeriodKey, err)
+		} else {
+			interval, err := e.config.Screenshot.GetIntervalDuration()
+			if err != nil || interval <= 0 {
+				interval = time.Minute
+			}
+			var work []*storage.ScreenshotRecord
+			for _, s := range screenshots {
+				if !e.config.IsPersonalTime(s.Timestamp) {
+					work = append(work, s)
+				}
+			}
+			if section := formatUnknownTimeSection(unknownTimeBlocks(work, interval), summaryDir); section != "" {
+				sb.WriteString("---\n\n")
+				sb.WriteString(section)
+			}
+		}
+	}
+
+	// Focus section: focus score, streak and trend (day and longer periods)
+	if e.config.Focus.Enabled && (summary.PeriodType == "day" || isWeekOrLonger(summary.PeriodType)) {
+		section, err := formatFocusSection(e.config, e.storage, summary)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to render focus section for %s: %v", summary.PeriodKey, err)
+		} else if section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Plan section: planned focus blocks versus the focused time captured in them (day only)
+	if summary.PeriodType == "day" {
+		results, err := ComparePlan(e.config, e.storage, summary.StartTime, time.Now())
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to compare plan of %s: %v", summary.PeriodKey, err)
+		} else if section := formatPlanSection(results); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Workday section: first and last non-idle capture and overtime (day and week)
+	if e.config.Workday.Enabled && (summary.PeriodType == "day" || summary.PeriodType == "week") {
+		if section, err := e.workdaySection(summary); err != nil {
+			logger.GetLogger().Warnf("Failed to render workday section for %s: %v", summary.PeriodKey, err)
+		} else if section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Breaks section: break count, total break time and longest stretch without a break (day only)
+	if e.config.Breaks.Enabled && summary.PeriodType == "day" {
+		if section, err := e.breaksSection(summary); err != nil {
+			logger.GetLogger().Warnf("Failed to render breaks section for %s: %v", summary.PeriodKey, err)
+		} else if section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Issues section: time per Jira/Linear issue (day and longer periods)
+	if e.config.Issues.Enabled && (summary.PeriodType == "day" || isWeekOrLonger(summary.PeriodType)) {
+		issueTimes, err := AggregateIssueTime(e.storage, summary.StartTime, summary.EndTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to aggregate issue time for %s: %v", summary.PeriodKey, err)
+		} else if section := formatIssuesSection(issueTimes); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Repos section: coding time per repository/branch from IDE and terminal window titles (week and longer periods)
+	if e.config.Projects.Enabled && isWeekOrLonger(summary.PeriodType) {
+		interval, err := e.config.Screenshot.GetIntervalDuration()
+		if err != nil || interval <= 0 {
+			interval = time.Minute
+		}
+		repoTimes, err := AggregateRepoTime(e.storage, summary.StartTime, summary.EndTime, interval)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to aggregate repo time for %s: %v", summary.PeriodKey, err)
+		} else if section := formatReposSection(repoTimes); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Spaces section: captured time per macOS Space (day and longer periods)
+	if e.config.Spaces.Enabled && (summary.PeriodType == "day" || isWeekOrLonger(summary.PeriodType)) {
+		interval, err := e.config.Screenshot.GetIntervalDuration()
+		if err != nil || interval <= 0 {
+			interval = time.Minute
+		}
+		spaceTimes, err := AggregateSpaceTime(e.config, e.storage, summary.StartTime, summary.EndTime, interval)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to aggregate Space time for %s: %v", summary.PeriodKey, err)
+		} else if section := formatSpacesSection(spaceTimes); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
+	// Deliverables section: concrete outcomes (week and longer periods)
+	if isWeekOrLonger(summary.PeriodType) {
+		deliverables, err := e.storage.QueryDeliverables(summary.StartTime, summary.EndTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query deliverables for %s: %v", summary.PeriodKey, err)
+		} else if section := formatDeliverablesSection(deliverables); section != "" {
+			sb.WriteString("---\n\n")
+			sb.WriteString(section)
+		}
+	}
+
 	// Analysis section: improvement suggestions
-	// Only output analysis if there is valid work activity in the summary
-	if summary.Analysis != "" && hasValidWorkActivity(summary.Summary) {
+	// Only output analysis if it is enabled for the level and there is valid work activity in the summary
+	if summary.Analysis != "" && e.shouldGenerateAnalysis(summary.PeriodType) && hasValidWorkActivity(&e.config.Summary, summary.Summary) {
 		sb.WriteString("---\n\n")
 		sb.WriteString("## 改进建议\n\n")
 		sb.WriteString(summary.Analysis)
 		sb.WriteString("\n\n")
 	}
 
+	if section := formatCitationsSection(cited); section != "" {
+		sb.WriteString("---\n\n")
+		sb.WriteString(section)
+	}
+
 	sb.WriteString("---\n\n")
-	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", time.Now().Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("*报告生成时间: %s*\n", datefmt.DateTime(time.Now())))
 
 	// Write report to file
-	if err := os.WriteFile(reportPath, []byte(sb.String()), 0644); err != nil {
+	content := storage.FormatMarkdown(sb.String(), e.config.Storage.Markdown)
+	content, err = e.mergeManualEdits(reportPath, content)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(reportPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write period summary report file: %w", err)
 	}
+	e.recordReportHash(reportPath, content)
+	if err := e.storage.ClearReportMissing(summary.PeriodKey); err != nil {
+		logger.GetLogger().Warnf("Failed to clear missing report mark of %s: %v", summary.PeriodKey, err)
+	}
 
-	logger.GetLogger().Infof("Period summary report saved: %s", reportPath)
+	logger.GetLogger().Debugf("Period summary report saved: %s", reportPath)
+	events.EmitSummaryGenerated(summary.PeriodType, summary.PeriodKey)
 	return nil
 }
 
@@ -2481,99 +3121,9 @@ func getPeriodTypeName(periodType string) string {
 
 // isDesktopOrLockScreenAnalysis checks if the analysis content indicates desktop or lock screen state
 // Returns true if the analysis suggests desktop/lock screen, false otherwise
-// This function uses strict matching to avoid false positives with work-related screenshots
+// The patterns are configured per language (desktop_lock), see package idlescreen
 func isDesktopOrLockScreenAnalysis(analysis string) bool {
-	if analysis == "" {
-		return true // Empty analysis usually means desktop/lock screen was detected
-	}
-
-	// Convert to lowercase for case-insensitive matching
-	lowerAnalysis := strings.ToLower(analysis)
-
-	// Extract summary section for more precise matching
-	// Only check the summary section to avoid false positives from detailed descriptions
-	var summaryPart string
-	if strings.Contains(lowerAnalysis, "【摘要】") {
-		summaryStart := strings.Index(lowerAnalysis, "【摘要】")
-		summaryEnd := strings.Index(lowerAnalysis, "【详细论述】")
-		if summaryEnd > summaryStart {
-			summaryPart = lowerAnalysis[summaryStart:summaryEnd]
-		} else {
-			// If no detailed section, use everything after summary marker
-			summaryPart = lowerAnalysis[summaryStart:]
-		}
-	} else {
-		// If no summary marker, use first 200 chars as summary
-		if len(lowerAnalysis) > 200 {
-			summaryPart = lowerAnalysis[:200]
-		} else {
-			summaryPart = lowerAnalysis
-		}
-	}
-
-	// Strict patterns that indicate desktop or lock screen state
-	// These patterns must appear in the summary section to avoid false positives
-	// We use more specific patterns to avoid matching work-related activities
-	strictPatterns := []string{
-		"锁屏界面",
-		"锁屏状态",
-		"处于锁屏",
-		"电脑桌面",
-		"桌面界面",
-		"桌面状态",
-		"系统登录",
-		"系统锁屏",
-		"等待解锁",
-		"等待输入密码",
-		"没有打开任何应用程序",
-		"没有打开任何应用",
-		"lock screen",
-		"lockscreen",
-		"desktop",
-	}
-
-	// Check for strict patterns in summary
-	for _, pattern := range strictPatterns {
-		if strings.Contains(summaryPart, pattern) {
-			return true
-		}
-	}
-
-	// Special handling for "登录界面" - only match if it's system login, not application login
-	// Pattern: "登录界面" but NOT "游戏.*登录界面" or "应用.*登录界面"
-	if strings.Contains(summaryPart, "登录界面") {
-		// Exclude game or application login screens
-		if !strings.Contains(summaryPart, "游戏") &&
-			!strings.Contains(summaryPart, "应用") &&
-			!strings.Contains(summaryPart, "软件") {
-			return true
-		}
-	}
-
-	// Special handling for "输入密码" - only match if it's system unlock, not application login
-	if strings.Contains(summaryPart, "输入密码") {
-		// Check if it's about unlocking system (lock screen context)
-		if strings.Contains(summaryPart, "解锁") ||
-			strings.Contains(summaryPart, "锁屏") ||
-			strings.Contains(summaryPart, "系统") {
-			return true
-		}
-		// If it's about game/application login, don't filter
-		if strings.Contains(summaryPart, "游戏") ||
-			strings.Contains(summaryPart, "应用") {
-			return false
-		}
-	}
-
-	// Additional check: if summary is very short (< 100 chars) and contains lock/desktop keywords,
-	// it's likely a non-work state
-	if len(summaryPart) < 100 {
-		if strings.Contains(summaryPart, "锁屏") || strings.Contains(summaryPart, "桌面") {
-			return true
-		}
-	}
-
-	return false
+	return idlescreen.IsIdleAnalysis(analysis)
 }
 
 // processRollingSummary processes a list of summaries using rolling summary technique
@@ -2613,7 +3163,7 @@ func (e *Executor) processRollingSummaryWithTimeContext(summaries []string, peri
 	for i := 1; i < len(summaries); i++ {
 		// Log progress every 10 steps or every 30 seconds
 		if i%10 == 0 || time.Since(lastProgressTime) >= 30*time.Second {
-			logger.GetLogger().Infof("Rolling summary progress for %s (%s): %d/%d (%.1f%%)",
+			logger.Throttledf(logrus.InfoLevel, "rolling-progress", "Rolling summary progress for %s (%s): %d/%d (%.1f%%)",
 				periodKey, context, i, totalSteps, float64(i)/float64(totalSteps)*100)
 			lastProgressTime = time.Now()
 		}
@@ -2643,12 +3193,18 @@ func (e *Executor) processTreeAggregation(summaries []string, periodKey string,
 
 // processTreeAggregationWithTimeContext uses tree aggregation with time context for progress logging
 func (e *Executor) processTreeAggregationWithTimeContext(summaries []string, periodKey string, context string, timeContext string) (string, error) {
-	sessionID := uuid.New().String()
+	sessionID := treeSessionID(periodKey, context, timeContext, summaries)
+	checkpoint := e.loadTreeCheckpoint(sessionID)
 	currentLevel := summaries
 	level := 0
 
-	logger.GetLogger().Infof("Starting tree aggregation session %s for %s (%s): %d items",
-		sessionID, periodKey, context, len(summaries))
+	if len(checkpoint.pairs) > 0 {
+		logger.GetLogger().Infof("Resuming tree aggregation session %s for %s (%s): %d items, %d pair results from an interrupted run",
+			sessionID, periodKey, context, len(summaries), len(checkpoint.pairs))
+	} else {
+		logger.GetLogger().Infof("Starting tree aggregation session %s for %s (%s): %d items",
+			sessionID, periodKey, context, len(summaries))
+	}
 
 	// Determine worker count for parallel processing
 	maxWorkers := e.config.Performance.MaxParallelTreeAggregation
@@ -2696,13 +3252,20 @@ func (e *Executor) processTreeAggregationWithTimeContext(summaries []string, per
 					semaphore <- struct{}{}        // Acquire semaphore
 					defer func() { <-semaphore }() // Release semaphore
 
-					// We have a pair, combine them
-					combined, err := e.analyzer.GenerateRollingSummaryWithContext(currentLevel[pairIndex], currentLevel[pairIndex+1], timeContext)
-					if err != nil {
-						logger.GetLogger().Warnf("Tree aggregation failed at level %d, pair [%d,%d]: %v, using concatenation fallback",
-							level, pairIndex, pairIndex+1, err)
-						// Fallback: simple concatenation
-						combined = currentLevel[pairIndex] + "\n\n" + currentLevel[pairIndex+1]
+					// We have a pair, combine them unless an interrupted run already did
+					inputHash := treePairHash(currentLevel[pairIndex], currentLevel[pairIndex+1])
+					combined, ok := checkpoint.get(level, pairIndex/2, inputHash)
+					if !ok {
+						var err error
+						combined, err = e.analyzer.GenerateRollingSummaryWithContext(currentLevel[pairIndex], currentLevel[pairIndex+1], timeContext)
+						if err != nil {
+							logger.GetLogger().Warnf("Tree aggregation failed at level %d, pair [%d,%d]: %v, using concatenation fallback",
+								level, pairIndex, pairIndex+1, err)
+							// Fallback: simple concatenation, not saved so a resumed run tries the pair again
+							combined = currentLevel[pairIndex] + "\n\n" + currentLevel[pairIndex+1]
+						} else {
+							checkpoint.save(level, pairIndex/2, inputHash, combined)
+						}
 					}
 
 					resultChan <- pairResult{
@@ -2713,7 +3276,7 @@ func (e *Executor) processTreeAggregationWithTimeContext(summaries []string, per
 					// Log progress
 					count := completed.Add(1)
 					if count%10 == 0 || count == int32(pairsInLevel) {
-						logger.GetLogger().Infof("Tree aggregation level %d progress: %d/%d pairs (%.1f%%)",
+						logger.Throttledf(logrus.InfoLevel, "tree-progress", "Tree aggregation level %d progress: %d/%d pairs (%.1f%%)",
 							level, count, pairsInLevel, float64(count)/float64(pairsInLevel)*100)
 					}
 				}()
@@ -2744,8 +3307,9 @@ func (e *Executor) processTreeAggregationWithTimeContext(summaries []string, per
 		return "", fmt.Errorf("tree aggregation resulted in empty output")
 	}
 
-	logger.GetLogger().Infof("Completed tree aggregation session %s for %s (%s) in %d levels",
-		sessionID, periodKey, context, level)
+	checkpoint.finish()
+	logger.GetLogger().Infof("Completed tree aggregation session %s for %s (%s) in %d levels (%d pair results resumed)",
+		sessionID, periodKey, context, level, checkpoint.resumed.Load())
 
 	return currentLevel[0], nil
 }
@@ -2769,6 +3333,11 @@ func (e *Executor) CheckAndFillMissingSummaries(daysBack int) error {
 		daysBack = 7 // Default to 7 days
 	}
 
+	// Missing summaries are filled by the next check after the budget resets
+	if e.overBudget("filling missing summaries") {
+		return nil
+	}
+
 	now := time.Now()
 	startTime := now.AddDate(0, 0, -daysBack)
 	endTime := now
@@ -2812,7 +3381,7 @@ func (e *Executor) checkAndFillLevel(periodType string, startTime, endTime time.
 				periodEnd = endTime
 			}
 
-			periodKey := current.Format("2006-01-02-15-04")
+			periodKey := storage.FormatPeriodKey(periodType, current)
 			existing, err := e.storage.GetPeriodSummary(periodKey)
 			if err != nil {
 				logger.GetLogger().Warnf("Failed to check %s summary %s: %v", periodType, periodKey, err)
@@ -2844,7 +3413,7 @@ func (e *Executor) checkAndFillLevel(periodType string, startTime, endTime time.
 				periodEnd = endTime
 			}
 
-			periodKey := current.Format("2006-01-02-15")
+			periodKey := storage.FormatPeriodKey(periodType, current)
 			existing, err := e.storage.GetPeriodSummary(periodKey)
 			if err != nil {
 				logger.GetLogger().Warnf("Failed to check %s summary %s: %v", periodType, periodKey, err)
@@ -2863,8 +3432,7 @@ func (e *Executor) checkAndFillLevel(periodType string, startTime, endTime time.
 
 	case "work-segment":
 		// Check work-segments for each day in the range
-		current := startTime
-		current = time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
+		current := e.periodStart("day", startTime)
 
 		for current.Before(endTime) {
 			dayEnd := current.AddDate(0, 0, 1)
@@ -2885,8 +3453,7 @@ func (e *Executor) checkAndFillLevel(periodType string, startTime, endTime time.
 
 	case "day":
 		// Check every day in the range
-		current := startTime
-		current = time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, current.Location())
+		current := e.periodStart("day", startTime)
 
 		for current.Before(endTime) {
 			periodEnd := current.AddDate(0, 0, 1)
@@ -2894,7 +3461,7 @@ func (e *Executor) checkAndFillLevel(periodType string, startTime, endTime time.
 				periodEnd = endTime
 			}
 
-			periodKey := current.Format("2006-01-02")
+			periodKey := storage.FormatPeriodKey(periodType, current)
 			existing, err := e.storage.GetPeriodSummary(periodKey)
 			if err != nil {
 				logger.GetLogger().Warnf("Failed to check %s summary %s: %v", periodType, periodKey, err)
@@ -2975,6 +3542,11 @@ func (e *Executor) CleanupInvalidReports() error {
 
 			if periodType != "" {
 				periodKey, err := storage.ExtractPeriodKeyFromPath(filePath, periodType)
+				if err == nil && e.isPeriodLocked(periodKey) {
+					// Hand-edited reports may not match the generated format, keep them
+					logger.GetLogger().Infof("Keeping locked report: %s", filePath)
+					continue
+				}
 				if err == nil {
 					// Delete from database
 					if err := e.storage.DeletePeriodSummary(periodKey); err != nil {
@@ -0,0 +1,86 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"stuff-time/internal/bundle"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+// ExportPeriodTypes are the period types whose reports can be exported
+var ExportPeriodTypes = []string{"fifteenmin", "hour", "work-segment", "day", PersonalPeriodType, "week", "month", "quarter", "year"}
+
+// ExportReports returns the reports of the periods of the given types within [from, to), in time
+// order, with their paths relative to the reports directory
+// Reports compacted into month archives are included; periods without a report are skipped
+// It only reads stored data, so no model needs to be configured
+func ExportReports(cfg *config.Config, st *storage.Storage, from, to time.Time, periodTypes []string) ([]*bundle.File, error) {
+	e := &Executor{config: cfg, storage: st}
+
+	var summaries []*storage.PeriodSummary
+	for _, periodType := range periodTypes {
+		found, err := e.storage.QueryPeriodSummaries(periodType, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s summaries: %w", periodType, err)
+		}
+		summaries = append(summaries, found...)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].StartTime.Before(summaries[j].StartTime)
+	})
+
+	var files []*bundle.File
+	for _, summary := range summaries {
+		reportPath, err := e.calculateReportPath(summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate report path of %s: %w", summary.PeriodKey, err)
+		}
+		content, err := os.ReadFile(reportPath)
+		if os.IsNotExist(err) {
+			content, err = storage.ReadArchivedReport(e.config.Storage.ReportsPath, reportPath)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report of %s: %w", summary.PeriodKey, err)
+		}
+		rel, err := filepath.Rel(e.config.Storage.ReportsPath, reportPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve report path of %s: %w", summary.PeriodKey, err)
+		}
+		files = append(files, &bundle.File{Path: filepath.ToSlash(rel), Content: content})
+	}
+	return files, nil
+}
+
+// ErrReportNotFound is returned by ReadReportDocument when a period has no summary or no report file
+var ErrReportNotFound = errors.New("report not found")
+
+// ReadReportDocument returns the structure of the report of a period (any period key version),
+// read from its report file or the month archive it was compacted into
+func ReadReportDocument(cfg *config.Config, st *storage.Storage, periodKey string) (*storage.ReportDocument, error) {
+	summary, err := st.GetPeriodSummary(periodKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period summary: %w", err)
+	}
+	if summary == nil {
+		return nil, ErrReportNotFound
+	}
+	e := &Executor{config: cfg, storage: st}
+	reportPath, err := e.calculateReportPath(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate report path of %s: %w", summary.PeriodKey, err)
+	}
+	doc, err := storage.NewReportParser(cfg.Storage.ReportsPath).ParseDocument(reportPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrReportNotFound
+	}
+	return doc, err
+}
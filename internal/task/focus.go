@@ -0,0 +1,223 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+)
+
+// focusCategoryNames maps focus categories to their display names
+var focusCategoryNames = map[string]string{
+	focus.CategoryCoding:        "编码",
+	focus.CategoryDocs:          "文档/阅读",
+	focus.CategoryCommunication: "沟通",
+	focus.CategoryMeeting:       "会议",
+	focus.CategoryEntertainment: "娱乐",
+	focus.CategoryOther:         "其他",
+}
+
+// ComputeFocusScore computes and stores the focus score of a day from its screenshots
+// Returns nil if there are no screenshots for the day
+func ComputeFocusScore(cfg *config.Config, st *storage.Storage, day time.Time) (*storage.FocusScore, error) {
+	dayStart := cfg.Storage.DateStart(day)
+	screenshots, err := st.QueryByDateRange(dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	// Personal time (24/7 mode) doesn't count towards focus
+	work := screenshots[:0]
+	for _, s := range screenshots {
+		if !cfg.IsPersonalTime(s.Timestamp) {
+			work = append(work, s)
+		}
+	}
+	screenshots = work
+	if len(screenshots) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(screenshots, func(i, j int) bool {
+		return screenshots[i].Timestamp.Before(screenshots[j].Timestamp)
+	})
+
+	samples := make([]focus.Sample, 0, len(screenshots))
+	for _, s := range screenshots {
+		analysis := s.Analysis
+		// Failed, unanalyzed and desktop/lock screen captures count as idle
+		if !s.HasAnalysis() || isDesktopOrLockScreenAnalysis(analysis) {
+			analysis = ""
+		}
+		samples = append(samples, focus.Sample{Time: s.Timestamp, Analysis: analysis})
+	}
+
+	interval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	deepWorkMinutes := cfg.Focus.DeepWorkMinutes
+	if deepWorkMinutes <= 0 {
+		deepWorkMinutes = 25
+	}
+
+	stats := focus.Compute(samples, interval, float64(deepWorkMinutes))
+	score := &storage.FocusScore{
+		Date:                  dayStart,
+		Score:                 stats.Score,
+		ActiveMinutes:         stats.ActiveMinutes,
+		DeepWorkMinutes:       stats.DeepWorkMinutes,
+		LongestSessionMinutes: stats.LongestSessionMinutes,
+		ContextSwitches:       stats.ContextSwitches,
+		CategoryMinutes:       stats.CategoryMinutes,
+		UpdatedAt:             time.Now(),
+	}
+	if err := st.SaveFocusScore(score); err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+// FocusHistory returns one score per day for the given number of days ending with endDay (inclusive)
+// Days without a stored score are returned as nil
+func FocusHistory(st *storage.Storage, endDay time.Time, days int) ([]*storage.FocusScore, error) {
+	end := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), 0, 0, 0, 0, endDay.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -days)
+
+	stored, err := st.QueryFocusScores(start, end)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]*storage.FocusScore)
+	for _, s := range stored {
+		byDay[s.Date.Format("2006-01-02")] = s
+	}
+
+	history := make([]*storage.FocusScore, 0, days)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		history = append(history, byDay[day.Format("2006-01-02")])
+	}
+	return history, nil
+}
+
+// focusValues converts a history to plain scores (missing days as 0)
+func focusValues(history []*storage.FocusScore) []int {
+	values := make([]int, len(history))
+	for i, s := range history {
+		if s != nil {
+			values[i] = s.Score
+		}
+	}
+	return values
+}
+
+// averageFocus returns the average score of days that have a score
+func averageFocus(history []*storage.FocusScore) (float64, int) {
+	var sum, count int
+	for _, s := range history {
+		if s != nil {
+			sum += s.Score
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float64(sum) / float64(count), count
+}
+
+// formatFocusSection renders focus score, streak and trend of a period as a markdown section
+// Returns an empty string if no score is available
+func formatFocusSection(cfg *config.Config, st *storage.Storage, summary *storage.PeriodSummary) (string, error) {
+	threshold := cfg.Focus.StreakThreshold
+	var sb strings.Builder
+
+	if summary.PeriodType == "day" {
+		history, err := FocusHistory(st, summary.StartTime, 14)
+		if err != nil {
+			return "", err
+		}
+		today := history[len(history)-1]
+		if today == nil {
+			return "", nil
+		}
+		avg, _ := averageFocus(history[len(history)-8 : len(history)-1])
+
+		sb.WriteString("## 专注度\n\n")
+		sb.WriteString(fmt.Sprintf("**专注度评分**: %d / 100", today.Score))
+		if avg > 0 {
+			sb.WriteString(fmt.Sprintf("（近7日平均 %.0f，%+.0f）", avg, float64(today.Score)-avg))
+		}
+		sb.WriteString("\n\n")
+		sb.WriteString(fmt.Sprintf("- 活跃时长：%s，深度工作：%s，最长连续专注：%s\n",
+			formatMinutes(today.ActiveMinutes), formatMinutes(today.DeepWorkMinutes), formatMinutes(today.LongestSessionMinutes)))
+		sb.WriteString(fmt.Sprintf("- 上下文切换：%d 次\n", today.ContextSwitches))
+		if mix := formatCategoryMix(today.CategoryMinutes); mix != "" {
+			sb.WriteString(fmt.Sprintf("- 活动构成：%s\n", mix))
+		}
+		sb.WriteString(fmt.Sprintf("- 连续达标（≥%d）：%d 天\n", threshold, focus.Streak(focusValues(history), threshold)))
+		sb.WriteString(fmt.Sprintf("- 近14日趋势：`%s`\n\n", focus.Sparkline(focusValues(history))))
+		return sb.String(), nil
+	}
+
+	days := int(summary.EndTime.Sub(summary.StartTime).Hours()/24 + 0.5)
+	if days <= 0 {
+		return "", nil
+	}
+	lastDay := summary.EndTime.AddDate(0, 0, -1)
+	history, err := FocusHistory(st, lastDay, days)
+	if err != nil {
+		return "", err
+	}
+	avg, count := averageFocus(history)
+	if count == 0 {
+		return "", nil
+	}
+
+	var best *storage.FocusScore
+	for _, s := range history {
+		if s != nil && (best == nil || s.Score > best.Score) {
+			best = s
+		}
+	}
+
+	sb.WriteString("## 专注度\n\n")
+	sb.WriteString(fmt.Sprintf("**平均专注度评分**: %.0f / 100（%d 天有记录）\n\n", avg, count))
+	sb.WriteString(fmt.Sprintf("- 最佳一天：%s（%d）\n", datefmt.Date(best.Date), best.Score))
+	sb.WriteString(fmt.Sprintf("- 周期末连续达标（≥%d）：%d 天\n", threshold, focus.Streak(focusValues(history), threshold)))
+	if days <= 62 {
+		sb.WriteString(fmt.Sprintf("- 每日趋势：`%s`\n", focus.Sparkline(focusValues(history))))
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// formatCategoryMix renders category minutes as "编码 65%、沟通 20%、..."
+func formatCategoryMix(categoryMinutes map[string]float64) string {
+	var total float64
+	categories := make([]string, 0, len(categoryMinutes))
+	for category, minutes := range categoryMinutes {
+		total += minutes
+		categories = append(categories, category)
+	}
+	if total <= 0 {
+		return ""
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categoryMinutes[categories[i]] > categoryMinutes[categories[j]]
+	})
+
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		name := focusCategoryNames[category]
+		if name == "" {
+			name = category
+		}
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", name, categoryMinutes[category]/total*100))
+	}
+	return strings.Join(parts, "、")
+}
@@ -0,0 +1,252 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// ForgetOptions describes which data should be permanently removed
+type ForgetOptions struct {
+	From   time.Time
+	To     time.Time
+	App    string // Optional: only forget screenshots of this app (case-insensitive)
+	DryRun bool
+}
+
+// ForgetResult summarizes what was removed
+type ForgetResult struct {
+	Screenshots      []*storage.ScreenshotRecord
+	DeletedImages    int
+	DeletedReports   int
+	DeletedSummaries []string // Period keys of deleted summaries
+	UnlockedPeriods  []string // Period keys of deleted summaries that were locked, their locks are cleared too
+	AffectedHourKeys []string
+}
+
+// periodTypesForForget lists all period levels, lowest first
+var periodTypesForForget = []string{"fifteenmin", "hour", "work-segment", "day", "week", "month", "quarter", "year"}
+
+// ForgetRange permanently deletes screenshots, their analyses and report files in [From, To),
+// removes all period summaries overlapping the range, and writes an audit entry.
+// It does not need an analyzer; summaries are regenerated separately via Executor.RegenerateRange.
+func ForgetRange(cfg *config.Config, st *storage.Storage, opts ForgetOptions) (*ForgetResult, error) {
+	if !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("invalid range: --from must be before --to")
+	}
+
+	records, err := st.QueryByDateRange(opts.From, opts.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+
+	result := &ForgetResult{}
+	hourKeys := make(map[string]bool)
+	app := strings.ToLower(strings.TrimSpace(opts.App))
+	for _, record := range records {
+		if !record.Timestamp.Before(opts.To) {
+			continue
+		}
+		if app != "" && strings.ToLower(record.App) != app {
+			continue
+		}
+		result.Screenshots = append(result.Screenshots, record)
+		if !hourKeys[record.HourKey] {
+			hourKeys[record.HourKey] = true
+			result.AffectedHourKeys = append(result.AffectedHourKeys, record.HourKey)
+		}
+	}
+
+	if len(result.Screenshots) == 0 {
+		return result, nil
+	}
+
+	// Every summary overlapping the range may quote the forgotten content, so all of them are removed
+	affectedSummaries, err := findOverlappingSummaries(st, opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range affectedSummaries {
+		result.DeletedSummaries = append(result.DeletedSummaries, summary.PeriodKey)
+		// A lock left behind would keep the period from being regenerated, leaving it without a summary
+		locked, err := st.IsPeriodLocked(summary.PeriodKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check lock of %s: %w", summary.PeriodKey, err)
+		}
+		if locked {
+			result.UnlockedPeriods = append(result.UnlockedPeriods, summary.PeriodKey)
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	images, err := NewImageStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store: %w", err)
+	}
+
+	storageManager := storage.NewStorageManager(&cfg.Storage, cfg.Storage.ReportsPath)
+	ids := make([]string, 0, len(result.Screenshots))
+	for _, record := range result.Screenshots {
+		ids = append(ids, record.ID)
+
+		// The image may only exist in the object store after it was evicted from the local cache
+		removedRemote := removeRemoteImage(images, record.ImagePath)
+		if err := os.Remove(record.ImagePath); err == nil || (os.IsNotExist(err) && removedRemote) {
+			result.DeletedImages++
+		} else if !os.IsNotExist(err) {
+			logger.GetLogger().Warnf("Failed to delete screenshot image %s: %v", record.ImagePath, err)
+		}
+
+		if cfg.Storage.ReportsPath != "" {
+			if reportPath, err := storageManager.GetFile(record.Timestamp, storage.FileTypeReport); err == nil {
+				if err := os.Remove(reportPath); err == nil {
+					result.DeletedReports++
+				}
+			}
+		}
+	}
+
+	if err := st.DeleteScreenshotsByIDs(ids); err != nil {
+		return nil, fmt.Errorf("failed to delete screenshot records: %w", err)
+	}
+
+	// Rebuild hour summaries from the remaining screenshots
+	for _, hourKey := range result.AffectedHourKeys {
+		remaining, err := st.GetScreenshotsByHourKey(hourKey)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query remaining screenshots for %s: %v", hourKey, err)
+			continue
+		}
+		var remainingIDs, analyses []string
+		for _, r := range remaining {
+			remainingIDs = append(remainingIDs, r.ID)
+			if r.HasAnalysis() && !isDesktopOrLockScreenAnalysis(r.Analysis) {
+				analyses = append(analyses, r.Analysis)
+			}
+		}
+		hourSummary := strings.Join(analyses, "\n")
+		if hourSummary == "" {
+			hourSummary = fmt.Sprintf("Captured %d screenshot(s) during this hour", len(remainingIDs))
+		}
+		if err := st.UpdateHourSummary(hourKey, remainingIDs, hourSummary); err != nil {
+			logger.GetLogger().Warnf("Failed to update hour summary %s: %v", hourKey, err)
+		}
+	}
+
+	// Deliverables, issues, attachments, focus scores and the like quote the summaries and screenshots
+	var dayKeys []string
+	for day := cfg.Storage.DayStart(opts.From); day.Before(opts.To); day = day.AddDate(0, 0, 1) {
+		dayKeys = append(dayKeys, day.Format("2006-01-02"))
+	}
+	if err := st.DeleteDerivedData(result.DeletedSummaries, dayKeys); err != nil {
+		return nil, fmt.Errorf("failed to delete derived data: %w", err)
+	}
+
+	for _, periodKey := range result.DeletedSummaries {
+		if err := st.DeletePeriodSummary(periodKey); err != nil {
+			logger.GetLogger().Warnf("Failed to delete period summary %s: %v", periodKey, err)
+		}
+	}
+	for _, periodKey := range result.UnlockedPeriods {
+		if err := st.UnlockPeriod(periodKey); err != nil {
+			return nil, fmt.Errorf("failed to unlock %s: %w", periodKey, err)
+		}
+	}
+
+	details := fmt.Sprintf("range=%s..%s app=%q screenshots=%d images=%d reports=%d summaries=%d unlocked=%d",
+		opts.From.Format(time.RFC3339), opts.To.Format(time.RFC3339), opts.App,
+		len(result.Screenshots), result.DeletedImages, result.DeletedReports, len(result.DeletedSummaries), len(result.UnlockedPeriods))
+	if err := st.AddAuditEntry(storage.NewAuditEntry("forget", details)); err != nil {
+		return result, fmt.Errorf("data deleted but failed to write audit entry: %w", err)
+	}
+
+	logger.GetLogger().Infof("Forget completed: %s", details)
+	return result, nil
+}
+
+// findOverlappingSummaries returns all period summaries whose time range overlaps [from, to)
+func findOverlappingSummaries(st *storage.Storage, from, to time.Time) ([]*storage.PeriodSummary, error) {
+	// QueryPeriodSummaries only returns summaries fully contained in the window,
+	// so widen the window to whole years and filter overlaps here
+	windowStart := time.Date(from.Year(), 1, 1, 0, 0, 0, 0, from.Location())
+	windowEnd := time.Date(to.Year()+1, 1, 1, 0, 0, 0, 0, to.Location())
+
+	var result []*storage.PeriodSummary
+	for _, periodType := range periodTypesForForget {
+		summaries, err := st.QueryPeriodSummaries(periodType, windowStart, windowEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s summaries: %w", periodType, err)
+		}
+		for _, summary := range summaries {
+			if summary.StartTime.Before(to) && summary.EndTime.After(from) {
+				result = append(result, summary)
+			}
+		}
+	}
+	return result, nil
+}
+
+// RegenerateRange regenerates summaries covering [from, to) bottom-up after data was removed
+// Every period type in periodTypesForForget is rebuilt, since forget deleted all of them
+func (e *Executor) RegenerateRange(from, to time.Time) error {
+	var failures []string
+	for _, periodType := range periodTypesForForget {
+		switch periodType {
+		case "fifteenmin":
+			fifteenminStart := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), (from.Minute()/15)*15, 0, 0, from.Location())
+			for t := fifteenminStart; t.Before(to); t = t.Add(15 * time.Minute) {
+				if err := e.generateSinglePeriodSummary(t, periodType, false, true); err != nil {
+					failures = append(failures, fmt.Sprintf("fifteenmin %s: %v", t.Format("2006-01-02 15:04"), err))
+				}
+			}
+		case "hour":
+			hourStart := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, from.Location())
+			for t := hourStart; t.Before(to); t = t.Add(time.Hour) {
+				if err := e.generateSinglePeriodSummary(t, periodType, false, true); err != nil {
+					failures = append(failures, fmt.Sprintf("hour %s: %v", t.Format("2006-01-02 15"), err))
+				}
+			}
+		case "work-segment":
+			for day := e.periodStart("day", from); day.Before(to); day = day.AddDate(0, 0, 1) {
+				if err := e.generateWorkSegmentSummary(day, false); err != nil {
+					failures = append(failures, fmt.Sprintf("work-segment %s: %v", day.Format("2006-01-02"), err))
+				}
+			}
+		default:
+			for t := e.periodStart(periodType, from); t.Before(to); t = nextPeriodStart(periodType, t) {
+				if err := e.generateSinglePeriodSummary(t, periodType, false, true); err != nil {
+					failures = append(failures, fmt.Sprintf("%s %s: %v", periodType, t.Format("2006-01-02"), err))
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to regenerate %d period(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// nextPeriodStart returns the start of the day, week, month, quarter or year following the one starting at start
+func nextPeriodStart(periodType string, start time.Time) time.Time {
+	switch periodType {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	case "quarter":
+		return start.AddDate(0, 3, 0)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,172 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestForgetRange(t *testing.T) {
+	st := newTestStorage(t)
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	from, to := day.Add(14*time.Hour), day.Add(15*time.Hour)
+
+	// More screenshots than one delete batch holds
+	for i := 0; i < 1200; i++ {
+		app := "Code"
+		if i%100 == 0 {
+			app = "WeChat"
+		}
+		record := &storage.ScreenshotRecord{
+			ID:        fmt.Sprintf("s%04d", i),
+			Timestamp: from.Add(time.Duration(i) * time.Second),
+			ImagePath: fmt.Sprintf("/nonexistent/%d.png", i),
+			Analysis:  "Chatting in WeChat about the release",
+			App:       app,
+		}
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.SavePeriodSummary(&storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day",
+		StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: "Released v2"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, periodKey := range []string{"day:2025-12-09", "day:2025-12-10"} {
+		if err := st.ReplaceDeliverables(periodKey, []*storage.Deliverable{{ID: periodKey, PeriodKey: periodKey,
+			Timestamp: day.Add(16 * time.Hour), Kind: "release", Title: "v2"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.SaveFocusScore(&storage.FocusScore{Date: day, Score: 80, UpdatedAt: day}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveTreePair(&storage.TreePair{SessionID: "session", Output: "Released v2", CreatedAt: day}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The app filter matches the recorded app, not analyses mentioning it
+	result, err := ForgetRange(&config.Config{}, st, ForgetOptions{From: from, To: to, App: "wechat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Screenshots) != 12 {
+		t.Errorf("forgot %d screenshots, want the 12 WeChat ones", len(result.Screenshots))
+	}
+
+	result, err = ForgetRange(&config.Config{}, st, ForgetOptions{From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Screenshots) != 1188 {
+		t.Errorf("forgot %d screenshots, want 1188", len(result.Screenshots))
+	}
+	remaining, err := st.QueryByDateRange(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("%d screenshots remain after forget", len(remaining))
+	}
+
+	deliverables, err := st.QueryDeliverables(day, day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliverables) != 1 || deliverables[0].PeriodKey != "day:2025-12-10" {
+		t.Errorf("deliverables after forget = %v, want only the next day's", deliverables)
+	}
+	scores, err := st.QueryFocusScores(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 0 {
+		t.Errorf("%d focus scores remain after forget", len(scores))
+	}
+	pairs, err := st.GetTreePairs("session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 0 {
+		t.Errorf("%d tree aggregation pairs remain after forget", len(pairs))
+	}
+}
+
+func TestRegenerateRange(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Storage.ReportsPath = t.TempDir()
+	cfg.Screenshot.WorkHours = config.WorkHoursConfig{StartHour: 9, StartMinute: 30, EndHour: 20}
+	e, st := newTestExecutor(t, cfg)
+	client := analyzer.NewOpenAI("test-key", "", "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	if err := client.ConfigureBackend(analyzer.BackendModeMock, "", "整理发布说明"); err != nil {
+		t.Fatal(err)
+	}
+	e.analyzer = client
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	from, to := day.Add(14*time.Hour), day.Add(15*time.Hour)
+	for i, app := range []string{"Code", "WeChat", "Code"} {
+		record := &storage.ScreenshotRecord{ID: fmt.Sprintf("s%d", i), Timestamp: from.Add(time.Duration(i) * time.Minute),
+			ImagePath: fmt.Sprintf("/nonexistent/%d.png", i), Analysis: "在 VS Code 中整理发布说明", App: app}
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	quarter := time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local)
+	if err := st.SavePeriodSummary(&storage.PeriodSummary{PeriodKey: storage.FormatPeriodKey("quarter", quarter), PeriodType: "quarter",
+		StartTime: quarter, EndTime: quarter.AddDate(0, 3, 0), Summary: "在微信中讨论发布"}); err != nil {
+		t.Fatal(err)
+	}
+	// A locked day is unlocked with its summary, otherwise it would be skipped and left without one
+	if err := st.SavePeriodSummary(&storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day",
+		StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: "手动修订：在微信中讨论发布"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.LockPeriod("day:2025-12-09", "手动修订"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ForgetRange(cfg, st, ForgetOptions{From: from, To: to, App: "wechat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.UnlockedPeriods) != 1 || result.UnlockedPeriods[0] != "day:2025-12-09" {
+		t.Errorf("ForgetRange() unlocked %v, want the locked day", result.UnlockedPeriods)
+	}
+	if locked, err := st.IsPeriodLocked("day:2025-12-09"); err != nil || locked {
+		t.Errorf("day is still locked after forget (err %v)", err)
+	}
+	if err := e.RegenerateRange(from, to); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every level forget deleted is rebuilt, quarter included
+	for _, tt := range []struct {
+		periodType string
+		start      time.Time
+	}{
+		{"hour", from},
+		{"day", day},
+		{"week", day.AddDate(0, 0, -1)},
+		{"month", time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local)},
+		{"quarter", quarter},
+		{"year", time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)},
+	} {
+		periodKey := storage.FormatPeriodKey(tt.periodType, tt.start)
+		summary, err := st.GetPeriodSummary(periodKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary == nil || strings.Contains(summary.Summary, "微信") {
+			t.Errorf("%s after regeneration = %v, want a summary without the forgotten content", periodKey, summary)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// Generation leases are renewed while the generation runs; a lease not renewed for generationLease
+// (crashed process) is taken over by the next caller
+var (
+	generationLease        = 2 * time.Minute
+	generationPollInterval = 500 * time.Millisecond
+)
+
+// generationHolders numbers the generations of this process
+var generationHolders atomic.Int64
+
+// coalesceGeneration runs fn under the generation lease of periodKey. While another goroutine or process
+// (daemon, generate command, HTTP API) holds it, the caller waits for that generation; it receives the
+// outcome instead of running fn when both are forced rebuilds from screenshots or neither is, otherwise
+// fn runs once the other generation finished. shared reports a received outcome. fn still runs when
+// the lease cannot be read for generationLease
+func coalesceGeneration(st *storage.Storage, periodKey string, forced bool, fn func() error) (shared bool, err error) {
+	holder := fmt.Sprintf("%d-%d", os.Getpid(), generationHolders.Add(1))
+	lease := generationLease
+	// Lease times have millisecond precision
+	waitStart := time.UnixMilli(time.Now().UnixMilli())
+	acquired, err := st.AcquireGeneration(periodKey, holder, forced, lease)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to acquire generation lease %s, generating without it: %v", periodKey, err)
+		return false, fn()
+	}
+	lastRead := time.Now()
+	for !acquired {
+		time.Sleep(generationPollInterval)
+		g, err := st.GetGeneration(periodKey)
+		if err != nil {
+			if time.Since(lastRead) < lease {
+				continue
+			}
+			logger.GetLogger().Warnf("Failed to read generation lease %s, generating without it: %v", periodKey, err)
+			return false, fn()
+		}
+		lastRead = time.Now()
+		if g != nil && !g.FinishedAt.IsZero() && !g.FinishedAt.Before(waitStart) && g.Forced == forced {
+			if g.Error != "" {
+				return true, errors.New(g.Error)
+			}
+			return true, nil
+		}
+		// Only a finished, vanished or expired lease (crashed holder) is taken over
+		if g == nil || !g.FinishedAt.IsZero() || g.ExpiresAt.Before(time.Now()) {
+			if acquired, err = st.AcquireGeneration(periodKey, holder, forced, lease); err != nil {
+				logger.GetLogger().Warnf("Failed to acquire generation lease %s, generating without it: %v", periodKey, err)
+				return false, fn()
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := st.RenewGeneration(periodKey, holder, lease); err != nil {
+					logger.GetLogger().Warnf("Failed to renew generation lease %s: %v", periodKey, err)
+				}
+			}
+		}
+	}()
+
+	err = fn()
+	close(done)
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if finishErr := st.FinishGeneration(periodKey, holder, errMsg); finishErr != nil {
+		logger.GetLogger().Warnf("Failed to release generation lease %s: %v", periodKey, finishErr)
+	}
+	return false, err
+}
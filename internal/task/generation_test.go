@@ -0,0 +1,135 @@
+package task
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestCoalesceGeneration(t *testing.T) {
+	defer func(interval time.Duration) { generationPollInterval = interval }(generationPollInterval)
+	generationPollInterval = 10 * time.Millisecond
+
+	// Two storages on one database stand for the daemon and a generate command
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	var stores []*storage.Storage
+	for i := 0; i < 2; i++ {
+		st, err := storage.NewStorage(dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer st.Close()
+		stores = append(stores, st)
+	}
+
+	tests := []struct {
+		name       string
+		forced     bool // Whether the second caller rebuilds from screenshots
+		wantShared bool
+	}{
+		{"normal generation shares the running outcome", false, true},
+		// Serialized on the period, but a different kind of generation runs itself afterwards
+		{"forced rebuild waits and runs itself", true, false},
+	}
+	for _, tt := range tests {
+		var runs atomic.Int32
+		var finished atomic.Bool
+		started, release := make(chan struct{}), make(chan struct{})
+		wantErr := errors.New("generation failed")
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shared, err := coalesceGeneration(stores[0], "day:2025-12-09", false, func() error {
+				runs.Add(1)
+				close(started)
+				<-release
+				finished.Store(true)
+				return wantErr
+			})
+			if shared || err != wantErr {
+				t.Errorf("%s: running generation = %v, %v", tt.name, shared, err)
+			}
+		}()
+		<-started
+
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+		shared, err := coalesceGeneration(stores[1], "day:2025-12-09", tt.forced, func() error {
+			if !finished.Load() {
+				t.Errorf("%s: second generation ran while the first was running", tt.name)
+			}
+			runs.Add(1)
+			return nil
+		})
+		wg.Wait()
+		if tt.wantShared && (!shared || err == nil || err.Error() != wantErr.Error()) {
+			t.Errorf("%s: waiting generation = %v, %v; want the shared error", tt.name, shared, err)
+		}
+		if !tt.wantShared && (shared || err != nil || runs.Load() != 2) {
+			t.Errorf("%s: waiting generation = %v, %v after %d runs; want it to run itself", tt.name, shared, err, runs.Load())
+		}
+	}
+
+	// A finished key runs again
+	if shared, err := coalesceGeneration(stores[1], "day:2025-12-09", false, func() error { return nil }); shared || err != nil {
+		t.Errorf("generation after completion = %v, %v", shared, err)
+	}
+}
+
+func TestCoalesceGenerationUnreadableLease(t *testing.T) {
+	defer func(interval, lease time.Duration) {
+		generationPollInterval, generationLease = interval, lease
+	}(generationPollInterval, generationLease)
+	generationPollInterval, generationLease = 10*time.Millisecond, 200*time.Millisecond
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	holding, err := storage.NewStorage(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holding.Close()
+	waiting, err := storage.NewStorage(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		coalesceGeneration(holding, "day:2025-12-09", false, func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// The waiter's database fails while it waits; it gives up after a lease and generates without it
+	time.AfterFunc(50*time.Millisecond, func() { waiting.Close() })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ran := false
+		shared, err := coalesceGeneration(waiting, "day:2025-12-09", false, func() error {
+			ran = true
+			return nil
+		})
+		if shared || err != nil || !ran {
+			t.Errorf("generation with an unreadable lease = %v, %v, ran %v; want it to run", shared, err, ran)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("waiting on an unreadable lease never gave up")
+	}
+	close(release)
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/notify"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// healthDir is the directory under the screenshot storage path used by the self-test, removed afterwards
+const healthDir = ".health"
+
+// healthHistory is how many previous checks are read to count consecutive failures
+const healthHistory = 100
+
+// healthStep is the outcome of one step of the self-test
+type healthStep struct {
+	name    string
+	err     error
+	skipped string // Reason the step didn't run, empty if it ran
+}
+
+// RunHealthCheck runs the daily self-test of the capture pipeline: it captures a test frame (which
+// runs the blank-frame detector), writes and reads back a file and a database row, and optionally
+// pings the LLM, then records a pass/fail entry
+// Consecutive failed checks reaching health.alert_after send a notification, so an outage doesn't go
+// unnoticed for days; the returned error is only about recording the result
+func (e *Executor) RunHealthCheck() (*storage.HealthCheck, error) {
+	steps := []healthStep{
+		e.healthCapture(),
+		e.healthDisk(),
+		e.healthDatabase(),
+	}
+	if e.config.Health.Ping {
+		steps = append(steps, healthStep{name: "llm", err: e.analyzer.Ping()})
+	} else {
+		steps = append(steps, healthStep{name: "llm", skipped: "disabled"})
+	}
+
+	check := &storage.HealthCheck{Timestamp: time.Now(), Passed: true}
+	var lines []string
+	for _, step := range steps {
+		switch {
+		case step.skipped != "":
+			lines = append(lines, fmt.Sprintf("%s: skipped (%s)", step.name, step.skipped))
+		case step.err != nil:
+			check.Passed = false
+			lines = append(lines, fmt.Sprintf("%s: failed: %v", step.name, step.err))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: ok", step.name))
+		}
+	}
+	check.Details = strings.Join(lines, "\n")
+
+	previous, err := e.storage.QueryHealthChecks(healthHistory)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query previous health checks: %v", err)
+	}
+	if err := e.storage.SaveHealthCheck(check); err != nil {
+		e.onHealthFailed(consecutiveHealthFailures(previous)+1, fmt.Sprintf("%s\nrecord: failed: %v", check.Details, err))
+		return check, err
+	}
+
+	if check.Passed {
+		logger.GetLogger().Infof("Health check passed:\n%s", check.Details)
+		e.onHealthPassed(consecutiveHealthFailures(previous))
+	} else {
+		e.onHealthFailed(consecutiveHealthFailures(previous)+1, check.Details)
+	}
+	return check, nil
+}
+
+// healthCapture captures a test frame into a scratch directory and removes it again
+func (e *Executor) healthCapture() healthStep {
+	step := healthStep{name: "capture"}
+	if locked, err := screenshot.IsScreenLocked(); err == nil && locked {
+		step.skipped = "screen locked"
+		return step
+	}
+	if !screenshot.HasScreenRecordingPermission() {
+		step.err = fmt.Errorf("screen recording permission not granted")
+		return step
+	}
+	screenID, err := screenshot.GetMouseScreenID()
+	if err != nil {
+		step.err = fmt.Errorf("failed to get mouse screen ID: %w", err)
+		return step
+	}
+
+	dir := filepath.Join(e.config.Screenshot.StoragePath, healthDir)
+	defer os.RemoveAll(dir)
+	if _, err := screenshot.CaptureScreen(screenID, dir, e.config.Screenshot.ImageFormat); err != nil {
+		if errors.Is(err, screenshot.ErrBlankCapture) {
+			step.err = fmt.Errorf("test frame is blank: %w", err)
+		} else {
+			step.err = err
+		}
+	}
+	return step
+}
+
+// healthDisk writes a probe file next to the screenshots, reads it back and removes it
+func (e *Executor) healthDisk() healthStep {
+	step := healthStep{name: "disk"}
+	dir := filepath.Join(e.config.Screenshot.StoragePath, healthDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		step.err = fmt.Errorf("failed to create directory: %w", err)
+		return step
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "probe")
+	content := time.Now().Format(time.RFC3339Nano)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		step.err = fmt.Errorf("failed to write probe file: %w", err)
+		return step
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		step.err = fmt.Errorf("failed to read probe file: %w", err)
+	} else if string(data) != content {
+		step.err = fmt.Errorf("probe file content mismatch")
+	}
+	return step
+}
+
+// healthDatabase writes a runtime state row and reads it back
+func (e *Executor) healthDatabase() healthStep {
+	step := healthStep{name: "database"}
+	value := time.Now().Format(time.RFC3339Nano)
+	if err := e.storage.SaveRuntimeState(storage.RuntimeHealthProbe, value); err != nil {
+		step.err = err
+		return step
+	}
+	states, err := e.storage.GetRuntimeStates()
+	if err != nil {
+		step.err = err
+	} else if state := states[storage.RuntimeHealthProbe]; state == nil || state.Value != value {
+		step.err = fmt.Errorf("probe row not read back")
+	}
+	return step
+}
+
+// consecutiveHealthFailures counts the failed checks at the start of a newest-first history
+func consecutiveHealthFailures(checks []*storage.HealthCheck) int {
+	failures := 0
+	for _, c := range checks {
+		if c.Passed {
+			break
+		}
+		failures++
+	}
+	return failures
+}
+
+// onHealthFailed logs a failed check and notifies once failures reached health.alert_after
+// Each further failed check notifies again, so a multi-day outage is reported every day
+func (e *Executor) onHealthFailed(failures int, details string) {
+	logger.GetLogger().Errorf("Health check failed (%d in a row):\n%s", failures, details)
+	events.Emit(events.LevelError, events.ComponentDaemon, "Health check failed (%d in a row)", failures)
+
+	threshold := e.config.Health.AlertAfter
+	if threshold <= 0 || failures < threshold {
+		return
+	}
+	if err := notify.Send("Stuff Time", fmt.Sprintf("自检已连续失败 %d 次，截图或分析可能已中断。运行 stuff-time status 查看详情", failures)); err != nil {
+		logger.GetLogger().Warnf("Failed to show health alert: %v", err)
+	}
+}
+
+// onHealthPassed notifies that the pipeline recovered when the failures before had been alerted
+func (e *Executor) onHealthPassed(failures int) {
+	threshold := e.config.Health.AlertAfter
+	if threshold <= 0 || failures < threshold {
+		return
+	}
+	events.Emit(events.LevelInfo, events.ComponentDaemon, "Health check passed again after %d failures", failures)
+	if err := notify.Send("Stuff Time", "自检已恢复正常"); err != nil {
+		logger.GetLogger().Warnf("Failed to show health notification: %v", err)
+	}
+}
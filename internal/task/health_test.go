@@ -0,0 +1,30 @@
+package task
+
+import (
+	"testing"
+
+	"stuff-time/internal/storage"
+)
+
+func TestConsecutiveHealthFailures(t *testing.T) {
+	pass := &storage.HealthCheck{Passed: true}
+	fail := &storage.HealthCheck{Passed: false}
+
+	tests := []struct {
+		name   string
+		checks []*storage.HealthCheck
+		want   int
+	}{
+		{"no history", nil, 0},
+		{"last passed", []*storage.HealthCheck{pass, fail, fail}, 0},
+		{"failing since last pass", []*storage.HealthCheck{fail, fail, pass, fail}, 2},
+		{"never passed", []*storage.HealthCheck{fail, fail, fail}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := consecutiveHealthFailures(tt.checks); got != tt.want {
+				t.Errorf("consecutiveHealthFailures() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+// newTestStorage opens a storage on a new database in a temporary directory, closed when the test ends
+func newTestStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	st, err := storage.NewStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+// newTestExecutor returns an executor with cfg over a new test storage, without an analyzer
+func newTestExecutor(t *testing.T, cfg *config.Config) (*Executor, *storage.Storage) {
+	t.Helper()
+	st := newTestStorage(t)
+	return &Executor{config: cfg, storage: st}, st
+}
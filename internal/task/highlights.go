@@ -0,0 +1,46 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/gallery"
+	"stuff-time/internal/storage"
+)
+
+// highlightPeriodTypes are the report levels that include the starred screenshots gallery
+var highlightPeriodTypes = map[string]bool{
+	"day":  true,
+	"week": true,
+}
+
+// formatHighlightsSection renders starred screenshots as a gallery of images with their analyses
+// Image links are relative to the report directory so the report stays portable with the data directory
+func formatHighlightsSection(starred []*storage.StarredScreenshot, reportDir string) string {
+	if len(starred) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 高光时刻\n\n")
+	for _, star := range starred {
+		s := star.Screenshot
+		imagePath := s.ImagePath
+		if rel, err := filepath.Rel(reportDir, s.ImagePath); err == nil {
+			imagePath = filepath.ToSlash(rel)
+		}
+
+		sb.WriteString(fmt.Sprintf("### %s\n\n", datefmt.DateTimeMinute(s.Timestamp)))
+		if star.Note != "" {
+			sb.WriteString(fmt.Sprintf("> %s\n\n", star.Note))
+		}
+		sb.WriteString(fmt.Sprintf("![%s](%s)\n\n", datefmt.Time(s.Timestamp), imagePath))
+		if caption := gallery.CaptionLine(s.Analysis); caption != "" {
+			sb.WriteString(caption)
+			sb.WriteString("\n\n")
+		}
+	}
+	return sb.String()
+}
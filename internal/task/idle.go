@@ -0,0 +1,65 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// inputIdle returns the time without keyboard/mouse input when it reached idle.after, 0 when the user is
+// active, an idle.except_apps app is in front, idle detection is off or the idle time is unknown
+func (e *Executor) inputIdle() time.Duration {
+	if !e.config.Idle.Enabled {
+		return 0
+	}
+	idle, err := screenshot.InputIdleTime()
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get input idle time: %v", err)
+		return 0
+	}
+	if idle < e.config.Idle.GetAfterDuration() {
+		return 0
+	}
+	if len(e.config.Idle.ExceptApps) > 0 {
+		if app, _, err := screenshot.FrontmostWindow(); err == nil && e.config.Idle.IsExceptApp(app) {
+			logger.GetLogger().Debugf("No input for %s but %s is in front, not idle", idle.Round(time.Second), app)
+			return 0
+		}
+	}
+	return idle
+}
+
+// skipForIdle reports whether this capture is skipped because there was no input for idle.after
+// With idle.action: tag the capture is taken and tagged by tagIdle instead
+func (e *Executor) skipForIdle() bool {
+	if e.config.Idle.TagOnly() {
+		return false
+	}
+	idle := e.inputIdle()
+	if idle == 0 {
+		return false
+	}
+	logger.GetLogger().Debugf("No input for %s, skipping screenshot capture", idle.Round(time.Second))
+	events.Emit(events.LevelDebug, events.ComponentCapture, "User idle, capture skipped")
+	return true
+}
+
+// tagIdle marks the screenshot skipped, so it is never analyzed and counts as idle time, when there was
+// no input for idle.after under idle.action: tag; reports whether it was tagged
+func (e *Executor) tagIdle(record *storage.ScreenshotRecord) bool {
+	if !e.config.Idle.TagOnly() {
+		return false
+	}
+	idle := e.inputIdle()
+	if idle == 0 {
+		return false
+	}
+	record.AnalysisStatus = storage.AnalysisSkipped
+	record.AnalysisError = fmt.Sprintf("idle: no input for %s", idle.Round(time.Second))
+	logger.GetLogger().Debugf("No input for %s, screenshot %s tagged idle", idle.Round(time.Second), record.ID)
+	return true
+}
@@ -0,0 +1,117 @@
+package task
+
+import (
+	"fmt"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/objectstore"
+	"stuff-time/internal/storage"
+)
+
+// imageSyncBatchSize is the number of pending uploads retried per sync
+const imageSyncBatchSize = 100
+
+// NewImageStore creates the object store mirroring the screenshot directory, nil if object_store is disabled
+func NewImageStore(cfg *config.Config) (*objectstore.Store, error) {
+	if !cfg.ObjectStore.Enabled {
+		return nil, nil
+	}
+	client, err := objectstore.NewClient(cfg.ObjectStore.Endpoint, cfg.ObjectStore.Region, cfg.ObjectStore.Bucket,
+		cfg.ObjectStore.AccessKeyID, cfg.ObjectStore.SecretAccessKey)
+	if err != nil {
+		return nil, err
+	}
+	return objectstore.NewStore(client, cfg.Screenshot.StoragePath, cfg.ObjectStore.Prefix,
+		int64(cfg.ObjectStore.CacheSizeMB)<<20), nil
+}
+
+// uploadImage writes a new screenshot through to the object store
+// Failed uploads are retried by SyncImages; until then the image stays in the local cache
+func (e *Executor) uploadImage(record *storage.ScreenshotRecord) {
+	if e.images == nil {
+		return
+	}
+	key, err := e.images.Upload(record.ImagePath)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to upload screenshot %s, will retry: %v", record.ID, err)
+		return
+	}
+	if err := e.storage.MarkImageUploaded(record.ImagePath, key); err != nil {
+		logger.GetLogger().Warnf("Failed to record upload of screenshot %s: %v", record.ID, err)
+	}
+}
+
+// fetchImage makes sure the screenshot image is in the local cache before it is read
+func (e *Executor) fetchImage(record *storage.ScreenshotRecord) error {
+	if e.images == nil {
+		return nil
+	}
+	return e.images.Fetch(record.ImagePath)
+}
+
+// SyncImages retries failed uploads and evicts uploaded images beyond the cache size
+func (e *Executor) SyncImages() error {
+	if e.images == nil {
+		return nil
+	}
+
+	pending, err := e.storage.GetImagesNotUploaded(imageSyncBatchSize)
+	if err != nil {
+		return err
+	}
+	uploaded := 0
+	for _, imagePath := range pending {
+		key, err := e.images.Upload(imagePath)
+		if err != nil {
+			logger.GetLogger().Debugf("Failed to upload %s: %v", imagePath, err)
+			continue
+		}
+		if err := e.storage.MarkImageUploaded(imagePath, key); err != nil {
+			return err
+		}
+		uploaded++
+	}
+	if uploaded > 0 {
+		logger.GetLogger().Infof("Uploaded %d pending screenshot(s) to the object store", uploaded)
+	}
+
+	evicted, err := e.images.Evict(func(imagePath string) bool {
+		ok, err := e.storage.IsImageUploaded(imagePath)
+		return err == nil && ok
+	})
+	if err != nil {
+		return fmt.Errorf("failed to evict cached screenshots: %w", err)
+	}
+	if evicted > 0 {
+		logger.GetLogger().Infof("Evicted %d uploaded screenshot(s) from the local cache", evicted)
+	}
+	return nil
+}
+
+// FetchImages downloads evicted screenshot images back into the local cache (e.g. before exporting a gallery)
+func FetchImages(cfg *config.Config, records []*storage.ScreenshotRecord) error {
+	images, err := NewImageStore(cfg)
+	if err != nil || images == nil {
+		return err
+	}
+	for _, record := range records {
+		if err := images.Fetch(record.ImagePath); err != nil {
+			logger.GetLogger().Warnf("Failed to fetch screenshot %s: %v", record.ID, err)
+		}
+	}
+	return nil
+}
+
+// removeRemoteImage deletes a screenshot image from the object store (the local copy is deleted by the caller)
+// It reports whether a remote copy was deleted
+func removeRemoteImage(images *objectstore.Store, imagePath string) bool {
+	if images == nil {
+		return false
+	}
+	if err := images.Remove(imagePath); err != nil {
+		logger.GetLogger().Warnf("Failed to delete screenshot %s from the object store: %v", imagePath, err)
+		return false
+	}
+	return true
+}
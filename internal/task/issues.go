@@ -0,0 +1,201 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/issues"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// IssueTime is the aggregated time spent on an issue over a range
+type IssueTime struct {
+	Key         string
+	Title       string
+	Status      string
+	URL         string
+	Screenshots int
+	Minutes     float64
+	Days        int
+}
+
+// CorrelateIssues finds issue keys in the day's screenshot analyses, attributes capture time to them,
+// attaches tracker details (title/status) and replaces the stored attributions of that day
+func (e *Executor) CorrelateIssues(day time.Time) ([]*storage.PeriodIssue, error) {
+	dayStart := e.config.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	dayKey := storage.FormatPeriodKey("day", dayStart)
+
+	screenshots, err := e.storage.QueryByDateRange(dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	screenshots = e.workScreenshots(screenshots)
+
+	// Each screenshot represents one capture interval of activity
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+
+	counts := make(map[string]int)
+	for _, s := range screenshots {
+		if !s.HasAnalysis() {
+			continue
+		}
+		for _, key := range issues.ExtractKeys(s.Analysis, e.config.Issues.Projects) {
+			counts[key]++
+		}
+	}
+
+	var periodIssues []*storage.PeriodIssue
+	for key, count := range counts {
+		info, err := e.resolveIssue(key)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to fetch issue %s: %v", key, err)
+		} else if e.issueTracker != nil && info != nil && info.Title == "" {
+			// The tracker does not know this key, it's not an issue
+			continue
+		}
+		periodIssues = append(periodIssues, &storage.PeriodIssue{
+			IssueKey:    key,
+			StartTime:   dayStart,
+			Screenshots: count,
+			Minutes:     float64(count) * interval.Minutes(),
+		})
+	}
+	sort.Slice(periodIssues, func(i, j int) bool {
+		return periodIssues[i].Minutes > periodIssues[j].Minutes
+	})
+
+	if err := e.storage.ReplacePeriodIssues(dayKey, periodIssues); err != nil {
+		return nil, fmt.Errorf("failed to save period issues: %w", err)
+	}
+
+	logger.GetLogger().Infof("Correlated %d issue(s) for %s", len(periodIssues), dayKey)
+	return periodIssues, nil
+}
+
+// resolveIssue returns cached issue details, refreshing them from the tracker when stale
+func (e *Executor) resolveIssue(key string) (*storage.IssueInfo, error) {
+	cached, err := e.storage.GetIssue(key)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && time.Since(cached.UpdatedAt) < e.config.Issues.GetCacheTTLDuration() {
+		return cached, nil
+	}
+	if e.issueTracker == nil {
+		return cached, nil
+	}
+
+	issue, err := e.issueTracker.Fetch(key)
+	if err != nil {
+		return cached, err
+	}
+	if issue == nil {
+		// Not a tracker issue (e.g. a version string that looks like a key), cache as unknown
+		issue = &issues.Issue{Key: key}
+	}
+
+	info := &storage.IssueInfo{
+		Key:       key,
+		Title:     issue.Title,
+		Status:    issue.Status,
+		URL:       issue.URL,
+		UpdatedAt: time.Now(),
+	}
+	if err := e.storage.SaveIssue(info); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// newIssueTracker creates the configured issue tracker, nil if no provider is configured
+func newIssueTracker(cfg *config.IssuesConfig) (issues.Tracker, error) {
+	if !cfg.Enabled || cfg.Provider == "" {
+		return nil, nil
+	}
+	return issues.NewTracker(cfg.Provider, cfg.BaseURL, cfg.Email, cfg.APIToken)
+}
+
+// AggregateIssueTime sums the time attributed to each issue over [start, end)
+// Issues are sorted by descending time
+func AggregateIssueTime(st *storage.Storage, start, end time.Time) ([]*IssueTime, error) {
+	periodIssues, err := st.QueryPeriodIssues(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*IssueTime)
+	for _, pi := range periodIssues {
+		it, ok := byKey[pi.IssueKey]
+		if !ok {
+			it = &IssueTime{Key: pi.IssueKey}
+			if info, err := st.GetIssue(pi.IssueKey); err == nil && info != nil {
+				it.Title = info.Title
+				it.Status = info.Status
+				it.URL = info.URL
+			}
+			byKey[pi.IssueKey] = it
+		}
+		it.Screenshots += pi.Screenshots
+		it.Minutes += pi.Minutes
+		it.Days++
+	}
+
+	result := make([]*IssueTime, 0, len(byKey))
+	for _, it := range byKey {
+		result = append(result, it)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Minutes != result[j].Minutes {
+			return result[i].Minutes > result[j].Minutes
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// formatIssuesSection renders the time-per-issue of a period as a markdown section
+// Returns an empty string if no issues were correlated
+func formatIssuesSection(issueTimes []*IssueTime) string {
+	if len(issueTimes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 关联工单\n\n")
+	sb.WriteString("| 工单 | 标题 | 状态 | 时长 |\n")
+	sb.WriteString("|------|------|------|------|\n")
+	for _, it := range issueTimes {
+		key := it.Key
+		if it.URL != "" {
+			key = fmt.Sprintf("[%s](%s)", it.Key, it.URL)
+		}
+		title := it.Title
+		if title == "" {
+			title = "-"
+		}
+		status := it.Status
+		if status == "" {
+			status = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", key, strings.ReplaceAll(title, "|", "\\|"), status, formatMinutes(it.Minutes)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatMinutes formats minutes as "1h30m" / "45m"
+func formatMinutes(minutes float64) string {
+	total := int(minutes + 0.5)
+	if total >= 60 {
+		return fmt.Sprintf("%dh%02dm", total/60, total%60)
+	}
+	return fmt.Sprintf("%dm", total)
+}
@@ -0,0 +1,91 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// JournalAnswer is the answer to one end-of-day reflection question
+type JournalAnswer struct {
+	Question string
+	Answer   string
+}
+
+// GetJournal returns the reflection answers saved for a day
+func (e *Executor) GetJournal(day time.Time) ([]*JournalAnswer, error) {
+	attachments, err := e.storage.GetAttachments(storage.FormatPeriodKey("day", day), storage.AttachmentJournal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal: %w", err)
+	}
+	answers := make([]*JournalAnswer, 0, len(attachments))
+	for _, a := range attachments {
+		answers = append(answers, &JournalAnswer{Question: a.Title, Answer: a.Content})
+	}
+	return answers, nil
+}
+
+// SaveJournal replaces the reflection answers of a day and re-renders the day report if it exists
+// Empty answers are dropped
+func (e *Executor) SaveJournal(day time.Time, answers []*JournalAnswer) error {
+	dayStart := e.config.Storage.DateStart(day)
+	dayKey := storage.FormatPeriodKey("day", dayStart)
+
+	var attachments []*storage.PeriodAttachment
+	for _, a := range answers {
+		if strings.TrimSpace(a.Answer) == "" {
+			continue
+		}
+		attachments = append(attachments, &storage.PeriodAttachment{
+			Title:     a.Question,
+			StartTime: dayStart,
+			EndTime:   dayStart.AddDate(0, 0, 1),
+			Content:   strings.TrimSpace(a.Answer),
+		})
+	}
+	if err := e.storage.ReplaceAttachments(dayKey, storage.AttachmentJournal, attachments); err != nil {
+		return fmt.Errorf("failed to save journal: %w", err)
+	}
+
+	summary, err := e.storage.GetPeriodSummary(dayKey)
+	if err != nil {
+		return fmt.Errorf("failed to get day summary: %w", err)
+	}
+	if summary != nil {
+		if err := e.SavePeriodSummaryReport(summary); err != nil {
+			return fmt.Errorf("failed to update day report: %w", err)
+		}
+	}
+	return nil
+}
+
+// readJournal collects the reflection answers of every day in [start, end), empty if there are none
+func (e *Executor) readJournal(start, end time.Time) string {
+	var sb strings.Builder
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		answers, err := e.GetJournal(day)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to load journal for %s: %v", day.Format("2006-01-02"), err)
+			continue
+		}
+		if len(answers) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s:\n", day.Format("2006-01-02 Mon")))
+		for _, a := range answers {
+			sb.WriteString(fmt.Sprintf("- %s %s\n", a.Question, a.Answer))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// withJournal appends the daily reflections to the summary input
+func withJournal(text, journal string) string {
+	if journal == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n用户每日反思（用户的主观感受，请与上面的客观记录结合，体现在总结和分析中）：\n%s", text, journal)
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestSaveJournal(t *testing.T) {
+	dir := t.TempDir()
+	st := newTestStorage(t)
+
+	cfg := &config.Config{}
+	cfg.Storage.ReportsPath = filepath.Join(dir, "reports")
+	e := NewReportExecutor(cfg, st)
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	summary := &storage.PeriodSummary{PeriodKey: "day:2025-12-09", PeriodType: "day", StartTime: day, EndTime: day.AddDate(0, 0, 1),
+		Summary: "完成导出功能的开发", Screenshots: "s1"}
+	if err := st.SavePeriodSummary(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.SaveJournal(day.Add(20*time.Hour), []*JournalAnswer{
+		{Question: "今天最有成就感的事？", Answer: " 导出功能上线 "},
+		{Question: "明天要改进什么？", Answer: "  "},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	answers, err := e.GetJournal(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 1 || answers[0].Answer != "导出功能上线" {
+		t.Fatalf("GetJournal() = %v, want only the non-empty answer", answers)
+	}
+
+	// The existing day report is re-rendered with the reflections
+	reportPath, err := e.calculateReportPath(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "## 每日反思") || !strings.Contains(string(report), "导出功能上线") {
+		t.Errorf("day report does not contain the journal:\n%s", report)
+	}
+
+	// Week summaries read the reflections of every day in the week
+	week := time.Date(2025, 12, 8, 0, 0, 0, 0, time.Local)
+	journal := e.readJournal(week, week.AddDate(0, 0, 7))
+	if want := "2025-12-09 Tue:\n- 今天最有成就感的事？ 导出功能上线"; journal != want {
+		t.Errorf("readJournal() = %q, want %q", journal, want)
+	}
+	if got := withJournal("周报输入", ""); got != "周报输入" {
+		t.Errorf("withJournal() without reflections = %q, want the input unchanged", got)
+	}
+}
@@ -0,0 +1,107 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// saveLineage records the child summaries and screenshots a period summary was generated from
+func (e *Executor) saveLineage(periodKey string, children, screenshots []string) {
+	lineage := &storage.SummaryLineage{PeriodKey: periodKey, Children: children, Screenshots: screenshots,
+		Prompts: e.summaryPromptVersions(periodKey)}
+	if err := e.storage.SaveLineage(lineage); err != nil {
+		logger.GetLogger().Warnf("Failed to save lineage of %s: %v", periodKey, err)
+	}
+}
+
+// LineageNode is a period summary with the evidence it was generated from
+type LineageNode struct {
+	PeriodKey  string    `json:"period_key"`
+	PeriodType string    `json:"period_type,omitempty"` // Empty when the summary was deleted after use
+	StartTime  time.Time `json:"start_time,omitempty"`
+	EndTime    time.Time `json:"end_time,omitempty"`
+	// False for summaries generated before lineage was recorded
+	Recorded    bool                 `json:"recorded"`
+	Collapsed   int                  `json:"collapsed,omitempty"` // Evidence entries below the depth limit
+	Prompts     []string             `json:"prompts,omitempty"`   // Database prompt versions used, e.g. summary/day@3
+	Children    []*LineageNode       `json:"children,omitempty"`
+	Screenshots []*LineageScreenshot `json:"screenshots,omitempty"`
+}
+
+// LineageScreenshot is a screenshot whose analysis was summarized directly
+type LineageScreenshot struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Analysis  string    `json:"analysis,omitempty"`
+	Deleted   bool      `json:"deleted,omitempty"` // Removed after use (cleanup, forget)
+}
+
+// BuildLineage resolves the lineage tree of a period summary; maxDepth limits the levels below
+// the root that are resolved (0 resolves down to the screenshots)
+func BuildLineage(st *storage.Storage, periodKey string, maxDepth int) (*LineageNode, error) {
+	summary, err := st.GetPeriodSummary(periodKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summary %s: %w", periodKey, err)
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("%s: %w", periodKey, storage.ErrNoData)
+	}
+	return buildLineageNode(st, summary, 0, maxDepth)
+}
+
+func buildLineageNode(st *storage.Storage, summary *storage.PeriodSummary, depth, maxDepth int) (*LineageNode, error) {
+	node := &LineageNode{
+		PeriodKey:  summary.PeriodKey,
+		PeriodType: summary.PeriodType,
+		StartTime:  summary.StartTime,
+		EndTime:    summary.EndTime,
+	}
+	lineage, err := st.GetLineage(summary.PeriodKey)
+	if err != nil {
+		return nil, err
+	}
+	if lineage == nil {
+		return node, nil
+	}
+	node.Recorded = true
+	node.Prompts = lineage.Prompts
+	if maxDepth > 0 && depth >= maxDepth {
+		node.Collapsed = len(lineage.Children) + len(lineage.Screenshots)
+		return node, nil
+	}
+
+	for _, key := range lineage.Children {
+		child, err := st.GetPeriodSummary(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary %s: %w", key, err)
+		}
+		if child == nil {
+			// Deleted after the parent was generated, keep the key so the gap is visible
+			node.Children = append(node.Children, &LineageNode{PeriodKey: key})
+			continue
+		}
+		childNode, err := buildLineageNode(st, child, depth+1, maxDepth)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	if len(lineage.Screenshots) > 0 {
+		records, err := st.GetScreenshotsByIDs(lineage.Screenshots)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get screenshots: %w", err)
+		}
+		for _, id := range lineage.Screenshots {
+			shot := &LineageScreenshot{ID: id, Deleted: true}
+			if record := records[id]; record != nil {
+				shot = &LineageScreenshot{ID: id, Timestamp: record.Timestamp, Analysis: record.Analysis}
+			}
+			node.Screenshots = append(node.Screenshots, shot)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestBuildLineage(t *testing.T) {
+	st := newTestStorage(t)
+
+	start := time.Date(2025, 11, 21, 9, 0, 0, 0, time.Local)
+	if err := st.SaveScreenshot(&storage.ScreenshotRecord{ID: "s1", Timestamp: start.Add(time.Minute), Analysis: "编辑 executor.go"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*storage.PeriodSummary{
+		{PeriodKey: "2025-11-21-09", PeriodType: "hour", StartTime: start, EndTime: start.Add(time.Hour), Summary: "开发"},
+		{PeriodKey: "2025-11-21-09-00", PeriodType: "fifteenmin", StartTime: start, EndTime: start.Add(15 * time.Minute), Summary: "编码"},
+		{PeriodKey: "2025-11-21-09-15", PeriodType: "fifteenmin", StartTime: start.Add(15 * time.Minute), EndTime: start.Add(30 * time.Minute), Summary: "评审"},
+	} {
+		if err := st.SavePeriodSummary(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, l := range []*storage.SummaryLineage{
+		// 09-30 was deleted after the hour was generated, s2 was removed by cleanup
+		{PeriodKey: "2025-11-21-09", Children: []string{"2025-11-21-09-00", "2025-11-21-09-30"}},
+		{PeriodKey: "2025-11-21-09-00", Screenshots: []string{"s1", "s2"}},
+	} {
+		if err := st.SaveLineage(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := BuildLineage(st, "2025-11-21-09", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !root.Recorded || len(root.Children) != 2 {
+		t.Fatalf("root = %+v, want 2 recorded children", root)
+	}
+	child := root.Children[0]
+	if child.PeriodType != "fifteenmin" || len(child.Screenshots) != 2 {
+		t.Fatalf("child = %+v, want fifteenmin with 2 screenshots", child)
+	}
+	if shot := child.Screenshots[0]; shot.Deleted || shot.Analysis != "编辑 executor.go" {
+		t.Errorf("screenshot s1 = %+v", shot)
+	}
+	if !child.Screenshots[1].Deleted {
+		t.Errorf("screenshot s2 not marked deleted")
+	}
+	if deleted := root.Children[1]; deleted.PeriodType != "" || deleted.PeriodKey != storage.NormalizePeriodKey("2025-11-21-09-30") {
+		t.Errorf("deleted child = %+v", deleted)
+	}
+
+	collapsed, err := BuildLineage(st, "2025-11-21-09", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := collapsed.Children[0]; len(c.Screenshots) != 0 || c.Collapsed != 2 {
+		t.Errorf("depth 1 child = %+v, want 2 collapsed screenshots", c)
+	}
+
+	// Summaries generated before lineage was recorded
+	unrecorded, err := BuildLineage(st, "2025-11-21-09-15", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unrecorded.Recorded {
+		t.Errorf("summary without lineage reported as recorded")
+	}
+}
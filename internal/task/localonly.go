@@ -0,0 +1,75 @@
+package task
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// newLocalAnalyzer creates the analyzer for local-only screenshots from the local model settings,
+// nil if no local model is configured (local-only screenshots are then described from the window only)
+func newLocalAnalyzer(cfg *config.Config) *analyzer.OpenAI {
+	if cfg.LocalOnly.BaseURL == "" || cfg.LocalOnly.Model == "" {
+		return nil
+	}
+	if u, err := url.Parse(cfg.LocalOnly.BaseURL); err == nil && !isLoopbackHost(u.Hostname()) {
+		logger.GetLogger().Warnf("local_only.base_url %s is not on this machine, local-only screenshots will be sent there", cfg.LocalOnly.BaseURL)
+	}
+	return analyzer.NewOpenAI(
+		cfg.LocalOnly.APIKey,
+		cfg.LocalOnly.BaseURL,
+		cfg.LocalOnly.Model,
+		cfg.OpenAI.MaxCompletionTokens,
+		cfg.OpenAI.PromptContent,
+		"", "", "", "", "", "", "", "", "",
+	)
+}
+
+func isLoopbackHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// recordLocalOnly marks the screenshot local-only if the frontmost app is flagged
+func (e *Executor) recordLocalOnly(record *storage.ScreenshotRecord, app, title string) {
+	if !e.config.LocalOnly.IsLocalOnlyApp(app) {
+		return
+	}
+	mark := &storage.LocalOnlyScreenshot{
+		ImagePath: record.ImagePath,
+		Timestamp: record.Timestamp,
+		App:       app,
+		Title:     title,
+	}
+	if err := e.storage.MarkLocalOnly(mark); err != nil {
+		logger.GetLogger().Warnf("Failed to mark screenshot %s local-only: %v", record.ID, err)
+	}
+}
+
+// analyzeLocally analyzes a local-only screenshot without the cloud provider:
+// with the local model if configured, otherwise a description from the frontmost window
+func (e *Executor) analyzeLocally(record *storage.ScreenshotRecord, mark *storage.LocalOnlyScreenshot) (string, error) {
+	if e.localAnalyzer != nil {
+		analysis, err := e.localAnalyzer.AnalyzeScreenshot(record.ImagePath, captureWindow(record))
+		if err == nil {
+			return analysis, nil
+		}
+		logger.GetLogger().Warnf("Local model failed for %s, describing from window: %v", record.ID, err)
+	}
+	return describeWindow(mark), nil
+}
+
+// describeWindow describes a local-only screenshot from its frontmost app and window title
+func describeWindow(mark *storage.LocalOnlyScreenshot) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("用户正在使用 %s", mark.App))
+	if title := strings.TrimSpace(mark.Title); title != "" {
+		sb.WriteString(fmt.Sprintf("（窗口：%s）", title))
+	}
+	sb.WriteString("。该应用配置为仅本地分析，截图未发送到云端。")
+	return sb.String()
+}
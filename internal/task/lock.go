@@ -0,0 +1,44 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// lockedSummaryLabel marks the content of locked (hand-edited) child summaries in the parent summary input
+const lockedSummaryLabel = "【人工修订，内容为准】"
+
+// isPeriodLocked reports whether the period was locked after manual edits of its report
+// A failed lookup counts as unlocked, so a broken lock table never stops summary generation
+func (e *Executor) isPeriodLocked(periodKey string) bool {
+	locked, err := e.storage.IsPeriodLocked(periodKey)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to check lock of %s: %v", periodKey, err)
+		return false
+	}
+	return locked
+}
+
+// markLockedSummary returns the summary text of a child period, labeled as authoritative if it is locked
+func (e *Executor) markLockedSummary(summary *storage.PeriodSummary) string {
+	if !e.isPeriodLocked(summary.PeriodKey) {
+		return summary.Summary
+	}
+	return lockedSummaryLabel + "\n" + summary.Summary
+}
+
+// withLockedNote tells the model that labeled child content was edited by the user and is authoritative
+func withLockedNote(text string) string {
+	if !strings.Contains(text, lockedSummaryLabel) {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n注：标有%s的内容已由用户人工修订并锁定，是权威内容；请完整保留其中的事实，与其他内容冲突时以其为准。", text, lockedSummaryLabel)
+}
+
+// stripLockedLabels removes the labels from text used without the model (direct merge, fallbacks)
+func stripLockedLabels(text string) string {
+	return strings.ReplaceAll(text, lockedSummaryLabel+"\n", "")
+}
@@ -0,0 +1,39 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"stuff-time/internal/storage"
+)
+
+func TestLockedSummaries(t *testing.T) {
+	e, st := newTestExecutor(t, nil)
+
+	if err := st.LockPeriod("2025-11-21-14", "修正项目名"); err != nil {
+		t.Fatal(err)
+	}
+	locked := e.markLockedSummary(&storage.PeriodSummary{PeriodKey: "2025-11-21-14", Summary: "评审 PR"})
+	unlocked := e.markLockedSummary(&storage.PeriodSummary{PeriodKey: "2025-11-21-15", Summary: "编写代码"})
+	if locked != lockedSummaryLabel+"\n评审 PR" || unlocked != "编写代码" {
+		t.Errorf("markLockedSummary() = %q, %q", locked, unlocked)
+	}
+
+	combined := locked + "\n\n" + unlocked
+	if input := withLockedNote(combined); !strings.HasPrefix(input, combined) || !strings.Contains(input, "以其为准") {
+		t.Errorf("withLockedNote() = %q", input)
+	}
+	if input := withLockedNote(unlocked); input != unlocked {
+		t.Errorf("withLockedNote() without locked content = %q", input)
+	}
+	if merged := stripLockedLabels(combined); merged != "评审 PR\n\n编写代码" {
+		t.Errorf("stripLockedLabels() = %q", merged)
+	}
+
+	if err := st.UnlockPeriod("2025-11-21-14"); err != nil {
+		t.Fatal(err)
+	}
+	if e.isPeriodLocked("2025-11-21-14") {
+		t.Errorf("period should be unlocked")
+	}
+}
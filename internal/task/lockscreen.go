@@ -0,0 +1,206 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image/png"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// detailedEdgeDensity is the edge density from which a screenshot is taken as content (editor,
+// browser, terminal) without asking the model; lock screens are a blurred wallpaper with a clock
+const detailedEdgeDensity = 0.04
+
+// LockScreenDetection checks screenshots for lock screens during a rebuild: results are cached by
+// image hash across rebuilds, blank and clearly detailed screenshots are decided locally, and only
+// the remaining ones are sent to the model, with a cap on concurrent and per-minute calls
+type LockScreenDetection struct {
+	storage   *storage.Storage
+	detect    func(imagePath string) (bool, error)
+	workers   int
+	perMinute int // Model calls started per minute, 0 for no limit
+
+	// Counted per distinct image
+	Cached      int
+	Heuristic   int
+	Model       int
+	Failed      int
+	LockScreens int // Screenshots (not distinct images) found to be lock screens
+}
+
+func NewLockScreenDetection(st *storage.Storage, detect func(imagePath string) (bool, error), workers, perMinute int) *LockScreenDetection {
+	if workers < 1 {
+		workers = 1
+	}
+	return &LockScreenDetection{storage: st, detect: detect, workers: workers, perMinute: perMinute}
+}
+
+// Detect returns the paths of the screenshots that are lock screens, it is a storage.LockScreenDetector
+func (d *LockScreenDetection) Detect(imagePaths []string) map[string]bool {
+	// Identical images (e.g. an idle lock screen captured for hours) are checked once
+	pathsByHash := make(map[string][]string)
+	var hashes []string
+	for _, path := range imagePaths {
+		hash, err := imageHash(path)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to hash screenshot %s, importing without lock screen check: %v", path, err)
+			continue
+		}
+		if _, ok := pathsByHash[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+		pathsByHash[hash] = append(pathsByHash[hash], path)
+	}
+
+	lockScreens := make(map[string]bool)
+	markLockScreen := func(hash string) {
+		for _, path := range pathsByHash[hash] {
+			lockScreens[path] = true
+			d.LockScreens++
+		}
+	}
+
+	var pending []string
+	for _, hash := range hashes {
+		if check, err := d.storage.GetLockScreenCheck(hash); err != nil {
+			logger.GetLogger().Warnf("Failed to get cached lock screen check: %v", err)
+		} else if check != nil {
+			d.Cached++
+			if check.IsLockScreen {
+				markLockScreen(hash)
+			}
+			continue
+		}
+
+		if isLockScreen, decided := lockScreenHeuristic(pathsByHash[hash][0]); decided {
+			d.Heuristic++
+			d.saveCheck(hash, isLockScreen, storage.LockCheckHeuristic)
+			if isLockScreen {
+				markLockScreen(hash)
+			}
+			continue
+		}
+
+		// Screenshots of local-only apps are never sent to the cloud provider
+		if mark, err := d.storage.GetLocalOnly(pathsByHash[hash][0]); err != nil || mark != nil {
+			continue
+		}
+		pending = append(pending, hash)
+	}
+
+	if len(pending) > 0 {
+		logger.GetLogger().Infof("Checking %d screenshot(s) for lock screens with the model (%d cached, %d decided locally)",
+			len(pending), d.Cached, d.Heuristic)
+	}
+	for hash, isLockScreen := range d.detectWithModel(pending, pathsByHash) {
+		if isLockScreen {
+			markLockScreen(hash)
+		}
+	}
+	return lockScreens
+}
+
+// detectWithModel asks the model about each image, returning the results of the successful checks
+func (d *LockScreenDetection) detectWithModel(hashes []string, pathsByHash map[string][]string) map[string]bool {
+	results := make(map[string]bool, len(hashes))
+	if len(hashes) == 0 {
+		return results
+	}
+
+	var tick <-chan time.Time
+	if d.perMinute > 0 {
+		ticker := time.NewTicker(time.Minute / time.Duration(d.perMinute))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hash := range jobs {
+				path := pathsByHash[hash][0]
+				isLockScreen, err := d.detect(path)
+
+				mu.Lock()
+				if err != nil {
+					d.Failed++
+					logger.GetLogger().Warnf("Failed to check if screenshot is lock screen (%s): %v, proceeding anyway", path, err)
+				} else {
+					d.Model++
+					results[hash] = isLockScreen
+					d.saveCheck(hash, isLockScreen, storage.LockCheckLLM)
+				}
+				if done := d.Model + d.Failed; done%100 == 0 {
+					logger.GetLogger().Infof("Lock screen detection: %d/%d checked (%s)", done, len(hashes),
+						time.Since(start).Round(time.Second))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, hash := range hashes {
+		if tick != nil {
+			<-tick
+		}
+		jobs <- hash
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (d *LockScreenDetection) saveCheck(hash string, isLockScreen bool, source string) {
+	check := &storage.LockScreenCheck{ImageHash: hash, IsLockScreen: isLockScreen, Source: source}
+	if err := d.storage.SaveLockScreenCheck(check); err != nil {
+		logger.GetLogger().Warnf("Failed to cache lock screen check: %v", err)
+	}
+}
+
+// lockScreenHeuristic decides obvious cases locally: a blank capture has nothing to analyze and is
+// skipped like a lock screen, a detailed one is content; decided is false when the model must decide
+func lockScreenHeuristic(imagePath string) (isLockScreen, decided bool) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return false, false
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return false, false
+	}
+
+	if screenshot.IsBlankImage(img) {
+		return true, true
+	}
+	if screenshot.EdgeDensity(img) >= detailedEdgeDensity {
+		return false, true
+	}
+	return false, false
+}
+
+// imageHash returns the SHA-256 of the image file content
+func imageHash(imagePath string) (string, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
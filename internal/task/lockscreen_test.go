@@ -0,0 +1,78 @@
+package task
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func writePNG(t *testing.T, path string, fill func(x, y int) color.RGBA) {
+	img := image.NewRGBA(image.Rect(0, 0, 320, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 320; x++ {
+			img.Set(x, y, fill(x, y))
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockScreenDetection(t *testing.T) {
+	dir := t.TempDir()
+	st := newTestStorage(t)
+
+	wallpaper := func(x, y int) color.RGBA { return color.RGBA{uint8(x / 2), uint8(y / 2), 120, 255} }
+	paths := map[string]func(x, y int) color.RGBA{
+		"lock1.png": wallpaper,
+		"lock2.png": wallpaper, // Same image, checked once
+		"blank.png": func(x, y int) color.RGBA { return color.RGBA{0, 0, 0, 255} },
+		"code.png": func(x, y int) color.RGBA {
+			if x%2 == 0 {
+				return color.RGBA{20, 20, 20, 255}
+			}
+			return color.RGBA{250, 250, 250, 255}
+		},
+	}
+	var imagePaths []string
+	for name, fill := range paths {
+		path := filepath.Join(dir, name)
+		writePNG(t, path, fill)
+		imagePaths = append(imagePaths, path)
+	}
+
+	var calls atomic.Int32
+	detect := func(imagePath string) (bool, error) {
+		calls.Add(1)
+		return true, nil
+	}
+
+	first := NewLockScreenDetection(st, detect, 2, 0)
+	lockScreens := first.Detect(imagePaths)
+	for name, want := range map[string]bool{"lock1.png": true, "lock2.png": true, "blank.png": true, "code.png": false} {
+		if got := lockScreens[filepath.Join(dir, name)]; got != want {
+			t.Errorf("%s lock screen = %v, want %v", name, got, want)
+		}
+	}
+	if calls.Load() != 1 || first.Heuristic != 2 {
+		t.Errorf("first run: %d model call(s), %d decided locally, want 1 and 2", calls.Load(), first.Heuristic)
+	}
+
+	// A second rebuild of the same archive is served from the cache
+	second := NewLockScreenDetection(st, detect, 2, 0)
+	if got := len(second.Detect(imagePaths)); got != 3 {
+		t.Errorf("second run found %d lock screen(s), want 3", got)
+	}
+	if calls.Load() != 1 || second.Cached != 3 {
+		t.Errorf("second run: %d model call(s), %d cached, want 1 and 3", calls.Load(), second.Cached)
+	}
+}
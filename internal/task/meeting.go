@@ -0,0 +1,114 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// meetingBlock is a contiguous run of screenshots classified as a meeting
+type meetingBlock struct {
+	start       time.Time
+	end         time.Time
+	screenshots []*storage.ScreenshotRecord
+}
+
+// detectMeetingBlocks groups chronologically ordered screenshots into contiguous meeting blocks
+// Screenshots more than maxGap apart start a new block; blocks shorter than minDuration are dropped
+func detectMeetingBlocks(screenshots []*storage.ScreenshotRecord, maxGap, minDuration, interval time.Duration) []*meetingBlock {
+	var blocks []*meetingBlock
+	var current *meetingBlock
+
+	flush := func() {
+		if current != nil && current.end.Sub(current.start) >= minDuration {
+			blocks = append(blocks, current)
+		}
+		current = nil
+	}
+
+	for _, s := range screenshots {
+		if !s.HasAnalysis() {
+			continue
+		}
+		if focus.Classify(s.Analysis) != focus.CategoryMeeting {
+			continue
+		}
+		if current != nil && s.Timestamp.Sub(current.end) > maxGap {
+			flush()
+		}
+		if current == nil {
+			current = &meetingBlock{start: s.Timestamp}
+		}
+		// Each screenshot covers one capture interval
+		current.end = s.Timestamp.Add(interval)
+		current.screenshots = append(current.screenshots, s)
+	}
+	flush()
+
+	return blocks
+}
+
+// GenerateMeetingNotes synthesizes a meeting note for each meeting block in a work segment
+// and stores them as attachments of the segment report. The stored notes are kept if any note fails
+func (e *Executor) GenerateMeetingNotes(segment *storage.PeriodSummary) ([]*storage.PeriodAttachment, error) {
+	if e.config.Meetings.PromptContent == "" {
+		return nil, nil
+	}
+
+	screenshots, err := e.storage.QueryByDateRange(segment.StartTime, segment.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	screenshots = e.workScreenshots(screenshots)
+	sort.Slice(screenshots, func(i, j int) bool {
+		return screenshots[i].Timestamp.Before(screenshots[j].Timestamp)
+	})
+
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	minMinutes := e.config.Meetings.MinMinutes
+	if minMinutes <= 0 {
+		minMinutes = 10
+	}
+
+	blocks := detectMeetingBlocks(screenshots, e.config.Meetings.GetMaxGapDuration(), time.Duration(minMinutes)*time.Minute, interval)
+
+	var notes []*storage.PeriodAttachment
+	for _, block := range blocks {
+		var lines []string
+		for _, s := range block.screenshots {
+			lines = append(lines, fmt.Sprintf("[%s] %s", s.Timestamp.Format("15:04"), s.Analysis))
+		}
+
+		content, err := e.analyzer.GenerateMeetingNote(e.config.Meetings.PromptContent, strings.Join(lines, "\n"))
+		// Keep the stored notes rather than replacing them with a partial set
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate meeting note for %s-%s: %w",
+				block.start.Format("15:04"), block.end.Format("15:04"), err)
+		}
+
+		notes = append(notes, &storage.PeriodAttachment{
+			Title:     fmt.Sprintf("会议纪要 %s-%s", datefmt.TimeMinute(block.start), datefmt.TimeMinute(block.end)),
+			StartTime: block.start,
+			EndTime:   block.end,
+			Content:   strings.TrimSpace(content),
+		})
+	}
+
+	if err := e.storage.ReplaceAttachments(segment.PeriodKey, storage.AttachmentMeetingNote, notes); err != nil {
+		return nil, err
+	}
+
+	if len(notes) > 0 {
+		logger.GetLogger().Infof("Generated %d meeting note(s) for %s", len(notes), segment.PeriodKey)
+	}
+	return notes, nil
+}
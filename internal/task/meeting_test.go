@@ -0,0 +1,54 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestGenerateMeetingNotesKeepsStoredOnFailure(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Meetings.PromptContent = "write the meeting note"
+	e, st := newTestExecutor(t, cfg)
+
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	segment := &storage.PeriodSummary{PeriodKey: "work-segment:2025-12-09#1", PeriodType: "work-segment",
+		StartTime: start, EndTime: start.Add(time.Hour)}
+	for i := 0; i < 20; i++ {
+		record := &storage.ScreenshotRecord{ID: fmt.Sprintf("s%02d", i), Timestamp: start.Add(time.Duration(i) * time.Minute),
+			ImagePath: fmt.Sprintf("/nonexistent/%d.png", i), Analysis: "在腾讯会议中讨论发布计划"}
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.ReplaceAttachments(segment.PeriodKey, storage.AttachmentMeetingNote, []*storage.PeriodAttachment{{
+		Title: "会议纪要 14:00-14:20", StartTime: start, EndTime: start.Add(20 * time.Minute), Content: "发布计划"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"invalid request"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+	client := analyzer.NewOpenAI("test-key", server.URL, "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	client.Retry = analyzer.RetryPolicy{}
+	e.analyzer = client
+
+	if _, err := e.GenerateMeetingNotes(segment); err == nil {
+		t.Error("GenerateMeetingNotes() succeeded although the note request failed")
+	}
+	notes, err := st.GetAttachments(segment.PeriodKey, storage.AttachmentMeetingNote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Content != "发布计划" {
+		t.Errorf("meeting notes after a failed generation = %v, want the stored one", notes)
+	}
+}
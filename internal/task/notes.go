@@ -0,0 +1,76 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// directoryPeriodTypes are the period types that own their report directory,
+// so a plain notes.md in that directory belongs to them
+var directoryPeriodTypes = map[string]bool{
+	"year":    true,
+	"quarter": true,
+	"month":   true,
+	"day":     true,
+	"hour":    true,
+}
+
+// PeriodNotesPath returns the manual notes file of a period: notes.md in the period's own
+// directory for year/quarter/month/day/hour, otherwise <report>-notes.md next to the report
+func (e *Executor) PeriodNotesPath(summary *storage.PeriodSummary) (string, error) {
+	paths, err := e.periodNotesPaths(summary)
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+// periodNotesPaths returns all accepted notes files of a period, the preferred one first
+func (e *Executor) periodNotesPaths(summary *storage.PeriodSummary) ([]string, error) {
+	reportPath, err := e.calculateReportPath(summary)
+	if err != nil {
+		return nil, err
+	}
+
+	reportNotes := storage.NotesPathForReport(reportPath)
+	if directoryPeriodTypes[summary.PeriodType] {
+		return []string{filepath.Join(filepath.Dir(reportPath), storage.NotesFileName), reportNotes}, nil
+	}
+	return []string{reportNotes}, nil
+}
+
+// readPeriodNotes reads the manual notes of a period, empty if there are none
+func (e *Executor) readPeriodNotes(summary *storage.PeriodSummary) string {
+	paths, err := e.periodNotesPaths(summary)
+	if err != nil {
+		return ""
+	}
+
+	var parts []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.GetLogger().Warnf("Failed to read notes file %s: %v", path, err)
+			}
+			continue
+		}
+		if text := strings.TrimSpace(string(content)); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// withPeriodNotes appends the manual notes of a period to the summary input
+func withPeriodNotes(text, notes string) string {
+	if notes == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n用户手动备注（用户补充的事实，请纳入总结）：\n%s", text, notes)
+}
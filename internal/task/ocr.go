@@ -0,0 +1,69 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// recognizeText indexes the text of a screenshot for search when OCR is enabled
+// Recognition failures are only logged, they never fail the analysis
+func (e *Executor) recognizeText(record *storage.ScreenshotRecord) {
+	if !e.config.OCR.Enabled {
+		return
+	}
+	if err := e.indexText(record); err != nil {
+		logger.GetLogger().Warnf("Failed to index text of screenshot %s: %v", record.ID, err)
+	}
+}
+
+// indexText recognizes the text of a screenshot and saves it, also when no text was found, so the
+// screenshot is not recognized again
+func (e *Executor) indexText(record *storage.ScreenshotRecord) error {
+	text, err := screenshot.RecognizeText(record.ImagePath, e.config.OCR.Languages)
+	if err != nil {
+		return err
+	}
+	return e.storage.SaveScreenshotText(record.ID, text)
+}
+
+// IndexScreenshotText recognizes and indexes the text of up to limit screenshots in [from, to) that
+// have none yet, e.g. those captured before OCR was enabled, and returns how many were indexed
+// Images evicted to the object store are downloaded first; no model is used
+func IndexScreenshotText(cfg *config.Config, st *storage.Storage, from, to time.Time, limit int) (int, int, error) {
+	images, err := NewImageStore(cfg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create object store: %w", err)
+	}
+	e := &Executor{config: cfg, storage: st, images: images}
+
+	records, err := st.QueryScreenshotsWithoutText(from, to, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	indexed, failed := 0, 0
+	for _, record := range records {
+		if err := e.fetchImage(record); err != nil {
+			logger.GetLogger().Warnf("Failed to fetch screenshot %s: %v", record.ID, err)
+			failed++
+			continue
+		}
+		if _, err := os.Stat(record.ImagePath); err != nil {
+			// Deleted by cleanup, nothing to recognize
+			failed++
+			continue
+		}
+		if err := e.indexText(record); err != nil {
+			logger.GetLogger().Warnf("Failed to index text of screenshot %s: %v", record.ID, err)
+			failed++
+			continue
+		}
+		indexed++
+	}
+	return indexed, failed, nil
+}
@@ -0,0 +1,173 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/timeline"
+)
+
+// statsPeriodTypes are the periods whose summary input gets exact statistics; longer periods are
+// summarized from these summaries, and their old screenshots may already be cleaned up
+var statsPeriodTypes = map[string]bool{
+	"fifteenmin": true, "hour": true, "work-segment": true, "day": true,
+}
+
+// PeriodStats are exact figures of a period computed from its screenshots, given to the summary model as
+// ground truth so it does not make up durations and counts
+type PeriodStats struct {
+	Start, End      time.Time
+	Screenshots     int
+	ActiveMinutes   int
+	IdleMinutes     int            // Lock screen, desktop and idle captures
+	FirstActive     time.Time      // Zero without activity
+	LastActive      time.Time      // Zero without activity
+	CategoryMinutes map[string]int // Active minutes by activity category, pending and failed analyses left out
+}
+
+// computePeriodStats computes the statistics of the screenshots of [start, end) captured every interval
+// Each capture covers one interval, like the activity timeline
+func computePeriodStats(screenshots []*storage.ScreenshotRecord, start, end time.Time, interval time.Duration) *PeriodStats {
+	stats := &PeriodStats{Start: start, End: end, CategoryMinutes: make(map[string]int)}
+	sorted := make([]*storage.ScreenshotRecord, len(screenshots))
+	copy(sorted, screenshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	points := make([]timeline.Point, 0, len(sorted))
+	for _, s := range sorted {
+		category := timeline.CategoryUnknown
+		switch {
+		case s.AnalysisStatus == storage.AnalysisSkipped:
+			category = focus.CategoryIdle
+		case s.HasAnalysis() && isDesktopOrLockScreenAnalysis(s.Analysis):
+			category = focus.CategoryIdle
+		case s.HasAnalysis():
+			category = focus.Classify(s.Analysis)
+		}
+		if category != focus.CategoryIdle {
+			if stats.FirstActive.IsZero() {
+				stats.FirstActive = s.Timestamp
+			}
+			stats.LastActive = s.Timestamp
+		}
+		points = append(points, timeline.Point{Time: s.Timestamp, Category: category})
+		stats.Screenshots++
+	}
+
+	for _, segment := range timeline.Build(points, interval, 2*interval) {
+		from, to := segment.Start, segment.End
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		minutes := int(to.Sub(from) / time.Minute)
+		if minutes <= 0 {
+			continue
+		}
+		switch segment.Category {
+		case focus.CategoryIdle:
+			stats.IdleMinutes += minutes
+		case timeline.CategoryUnknown:
+			stats.ActiveMinutes += minutes
+		default:
+			stats.ActiveMinutes += minutes
+			stats.CategoryMinutes[segment.Category] += minutes
+		}
+	}
+	return stats
+}
+
+// formatPeriodStats renders the statistics for the summary prompt in the locale language (locale.language)
+func formatPeriodStats(stats *PeriodStats) string {
+	en := datefmt.Language() == "en"
+	clock := datefmt.TimeMinute
+	if stats.End.Sub(stats.Start) > 24*time.Hour {
+		clock = datefmt.DateTimeMinute
+	}
+
+	var sb strings.Builder
+	if en {
+		sb.WriteString("Statistics (exact figures computed from the screenshots; use only these numbers for durations, counts and times of day, and do not estimate, convert or invent any other numbers):\n")
+		sb.WriteString(fmt.Sprintf("- Period: %s–%s\n", clock(stats.Start), clock(stats.End)))
+		sb.WriteString(fmt.Sprintf("- Screenshots: %d\n", stats.Screenshots))
+		sb.WriteString(fmt.Sprintf("- Active time: %s", datefmt.Minutes(time.Duration(stats.ActiveMinutes)*time.Minute)))
+		if !stats.FirstActive.IsZero() {
+			sb.WriteString(fmt.Sprintf(" (first activity %s, last activity %s)", clock(stats.FirstActive), clock(stats.LastActive)))
+		}
+		sb.WriteString(fmt.Sprintf("\n- Idle time: %s\n", datefmt.Minutes(time.Duration(stats.IdleMinutes)*time.Minute)))
+	} else {
+		sb.WriteString("统计数据（由截图记录精确计算的事实；总结中提到时长、次数和时间点时只能使用这些数字，不要估算、换算或编造其他数字）：\n")
+		sb.WriteString(fmt.Sprintf("- 时段：%s–%s\n", clock(stats.Start), clock(stats.End)))
+		sb.WriteString(fmt.Sprintf("- 截图：共 %d 张\n", stats.Screenshots))
+		sb.WriteString(fmt.Sprintf("- 活跃时长：%s", datefmt.Minutes(time.Duration(stats.ActiveMinutes)*time.Minute)))
+		if !stats.FirstActive.IsZero() {
+			sb.WriteString(fmt.Sprintf("（首次活跃 %s，最后活跃 %s）", clock(stats.FirstActive), clock(stats.LastActive)))
+		}
+		sb.WriteString(fmt.Sprintf("\n- 空闲时长：%s\n", datefmt.Minutes(time.Duration(stats.IdleMinutes)*time.Minute)))
+	}
+
+	categories := make([]string, 0, len(stats.CategoryMinutes))
+	for category := range stats.CategoryMinutes {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if stats.CategoryMinutes[categories[i]] != stats.CategoryMinutes[categories[j]] {
+			return stats.CategoryMinutes[categories[i]] > stats.CategoryMinutes[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	if len(categories) > 0 {
+		parts := make([]string, 0, len(categories))
+		for _, category := range categories {
+			name := category
+			if !en && focusCategoryNames[category] != "" {
+				name = focusCategoryNames[category]
+			}
+			parts = append(parts, fmt.Sprintf("%s %s", name, datefmt.Minutes(time.Duration(stats.CategoryMinutes[category])*time.Minute)))
+		}
+		if en {
+			sb.WriteString("- Activities: " + strings.Join(parts, ", ") + "\n")
+		} else {
+			sb.WriteString("- 活动分布：" + strings.Join(parts, "、") + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// periodStats returns the rendered statistics of the work screenshots of a period for its summary input,
+// empty when disabled (summary.stats), for periods longer than a day or without screenshots
+func (e *Executor) periodStats(periodType string, start, end time.Time) string {
+	if !e.config.Summary.Stats || !statsPeriodTypes[periodType] {
+		return ""
+	}
+	screenshots, err := e.storage.QueryByDateRange(start, end)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query screenshots for the statistics of %s %s: %v", periodType, start.Format(time.RFC3339), err)
+		return ""
+	}
+	screenshots = e.workScreenshots(screenshots)
+	if len(screenshots) == 0 {
+		return ""
+	}
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	return formatPeriodStats(computePeriodStats(screenshots, start, end, interval))
+}
+
+// withPeriodStats appends the statistics of the period to the summary input
+func withPeriodStats(text, stats string) string {
+	if stats == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n%s", text, stats)
+}
@@ -0,0 +1,54 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+)
+
+func TestPeriodStats(t *testing.T) {
+	defer datefmt.Set(datefmt.Options{})
+	at := func(minute int) time.Time {
+		return time.Date(2025, 12, 10, 9, 0, 0, 0, time.Local).Add(time.Duration(minute) * time.Minute)
+	}
+	var screenshots []*storage.ScreenshotRecord
+	for m := 5; m < 35; m++ {
+		screenshots = append(screenshots, &storage.ScreenshotRecord{Timestamp: at(m), AnalysisStatus: storage.AnalysisDone,
+			Analysis: "在 VS Code 中编写 Go 代码，调试单元测试"})
+	}
+	for m := 35; m < 45; m++ {
+		screenshots = append(screenshots, &storage.ScreenshotRecord{Timestamp: at(m), AnalysisStatus: storage.AnalysisSkipped})
+	}
+	screenshots = append(screenshots, &storage.ScreenshotRecord{Timestamp: at(50), AnalysisStatus: storage.AnalysisPending})
+
+	stats := computePeriodStats(screenshots, at(0), at(60), time.Minute)
+	if stats.Screenshots != 41 || stats.ActiveMinutes != 31 || stats.IdleMinutes != 10 {
+		t.Errorf("stats = %d screenshots, %d active, %d idle; want 41, 31, 10", stats.Screenshots, stats.ActiveMinutes, stats.IdleMinutes)
+	}
+	if stats.CategoryMinutes[focus.CategoryCoding] != 30 || !stats.FirstActive.Equal(at(5)) || !stats.LastActive.Equal(at(50)) {
+		t.Errorf("stats = %+v, want 30 coding minutes from 09:05 to 09:50", stats)
+	}
+
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{"zh", []string{"09:00–10:00", "共 41 张", "活跃时长：31 分钟（首次活跃 09:05，最后活跃 09:50）", "编码 30 分钟"}},
+		{"en", []string{"Screenshots: 41", "Active time: 31 min", "coding 30 min"}},
+	}
+	for _, tt := range tests {
+		if err := datefmt.Set(datefmt.Options{Language: tt.language}); err != nil {
+			t.Fatal(err)
+		}
+		text := formatPeriodStats(stats)
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: formatPeriodStats() = %q, missing %q", tt.language, text, want)
+			}
+		}
+	}
+}
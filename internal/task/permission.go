@@ -0,0 +1,68 @@
+package task
+
+import (
+	"sync"
+
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/notify"
+)
+
+// permissionMonitor detects a revoked screen recording permission from consecutive blank or failed captures
+type permissionMonitor struct {
+	mu        sync.Mutex
+	threshold int // Consecutive failures before alerting, 0 disables the alert
+	failures  int
+	lost      bool
+}
+
+// captureFailed records a blank or failed capture and returns true when the permission is newly considered lost
+func (m *permissionMonitor) captureFailed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures++
+	if m.threshold <= 0 || m.lost || m.failures < m.threshold {
+		return false
+	}
+	m.lost = true
+	return true
+}
+
+// captureSucceeded resets the failure count and returns true if the permission was considered lost before
+func (m *permissionMonitor) captureSucceeded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = 0
+	recovered := m.lost
+	m.lost = false
+	return recovered
+}
+
+// onCaptureFailed raises the permission-loss alert once enough consecutive captures failed
+func (e *Executor) onCaptureFailed(err error) {
+	if e.permission == nil || !e.permission.captureFailed() {
+		return
+	}
+
+	logger.GetLogger().Errorf("Screen recording permission appears to be lost (%d consecutive blank/failed captures): %v",
+		e.permission.threshold, err)
+	events.Emit(events.LevelError, events.ComponentCapture, "Screen recording permission lost, captures are blank or failing")
+	events.SetStatus(events.StatusScreenRecording, "lost")
+	if err := notify.Send("Stuff Time", "截图为空白或失败，屏幕录制权限可能已被撤销。请在 系统设置 > 隐私与安全性 > 屏幕录制 中重新授权"); err != nil {
+		logger.GetLogger().Warnf("Failed to show permission alert: %v", err)
+	}
+}
+
+// onCaptureSucceeded clears the permission-loss alert after a successful capture
+func (e *Executor) onCaptureSucceeded() {
+	events.SetStatus(events.StatusScreenRecording, "ok")
+	if e.permission == nil || !e.permission.captureSucceeded() {
+		return
+	}
+
+	logger.GetLogger().Info("Screen recording permission restored, captures are working again")
+	events.Emit(events.LevelInfo, events.ComponentCapture, "Screen recording permission restored")
+	if err := notify.Send("Stuff Time", "屏幕录制权限已恢复，截图已恢复正常"); err != nil {
+		logger.GetLogger().Warnf("Failed to show permission notification: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// PersonalPeriodType is the period type of personal-track day summaries (24/7 mode)
+const PersonalPeriodType = "personal-day"
+
+// PersonalPeriodKey returns the period key of the personal summary of a day
+func PersonalPeriodKey(day time.Time) string {
+	return storage.FormatPeriodKey(PersonalPeriodType, day)
+}
+
+// workScreenshots drops personal-time screenshots, so work summaries and reports only cover work hours
+// Without 24/7 mode every screenshot is work time
+func (e *Executor) workScreenshots(screenshots []*storage.ScreenshotRecord) []*storage.ScreenshotRecord {
+	if !e.config.Personal.Enabled {
+		return screenshots
+	}
+	var work []*storage.ScreenshotRecord
+	for _, s := range screenshots {
+		if !e.config.IsPersonalTime(s.Timestamp) {
+			work = append(work, s)
+		}
+	}
+	return work
+}
+
+// GeneratePersonalDaySummary summarizes the personal-time screenshots of the day containing day
+// into the personal track: its own period key and report tree (personal.reports_path)
+// Returns storage.ErrNoData when nothing was captured outside work hours
+func (e *Executor) GeneratePersonalDaySummary(day time.Time) error {
+	dayStart := e.config.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	periodKey := PersonalPeriodKey(dayStart)
+
+	screenshots, err := e.storage.QueryByDateRange(dayStart, dayEnd)
+	if err != nil {
+		return fmt.Errorf("failed to query screenshots: %w", err)
+	}
+
+	var ids []string
+	var analyses []string
+	for _, s := range screenshots {
+		if !e.config.IsPersonalTime(s.Timestamp) {
+			continue
+		}
+		ids = append(ids, s.ID)
+		if s.HasAnalysis() && !isDesktopOrLockScreenAnalysis(s.Analysis) {
+			analyses = append(analyses, fmt.Sprintf("[%s] %s", s.Timestamp.Format("15:04"), s.Analysis))
+		}
+	}
+	if len(analyses) == 0 {
+		return fmt.Errorf("%s: %w", periodKey, storage.ErrNoData)
+	}
+
+	summaryText, err := e.analyzer.GenerateSummary(strings.Join(analyses, "\n"))
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to generate personal summary for %s: %v", periodKey, err)
+		summaryText = strings.Join(analyses, "\n")
+	}
+
+	summary := &storage.PeriodSummary{
+		PeriodKey:   periodKey,
+		PeriodType:  PersonalPeriodType,
+		StartTime:   dayStart,
+		EndTime:     dayEnd,
+		Screenshots: strings.Join(ids, ","),
+		Summary:     summaryText,
+	}
+	if err := e.storage.SavePeriodSummary(summary); err != nil {
+		return fmt.Errorf("failed to save personal summary: %w", err)
+	}
+
+	if e.personalStorageManager == nil {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 个人时间总结 - %s\n\n", datefmt.Date(dayStart)))
+	sb.WriteString(fmt.Sprintf("**截图数量**: %d（工作时间之外）\n\n", len(ids)))
+	sb.WriteString("---\n\n")
+	sb.WriteString("## 事实总结\n\n")
+	sb.WriteString(summaryText)
+	sb.WriteString("\n")
+	relativePath, err := e.personalStorageManager.SaveSummary(dayStart, storage.SummaryLevelDay, sb.String())
+	if err != nil {
+		return fmt.Errorf("failed to save personal report: %w", err)
+	}
+	logger.GetLogger().Infof("Personal report saved: %s", filepath.Join(e.config.Personal.ReportsPath, relativePath))
+	return nil
+}
+
+// PersonalCleanupResult reports what the personal retention cleanup removed
+type PersonalCleanupResult struct {
+	Screenshots int
+	Summaries   int
+	Reports     int
+}
+
+// CleanupPersonalData removes personal-time screenshots (records and images), personal summaries
+// and personal report files older than personal.retention_days. Work data is left untouched
+func CleanupPersonalData(cfg *config.Config, st *storage.Storage) (*PersonalCleanupResult, error) {
+	result := &PersonalCleanupResult{}
+	if !cfg.Personal.Enabled || cfg.Personal.RetentionDays <= 0 {
+		return result, nil
+	}
+	now := time.Now()
+	cutoff := cfg.Storage.DayStart(now).AddDate(0, 0, -cfg.Personal.RetentionDays)
+
+	screenshots, err := st.QueryByDateRange(time.Time{}, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	images, err := NewImageStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store: %w", err)
+	}
+	var ids []string
+	days := make(map[string]time.Time)
+	for _, s := range screenshots {
+		if !cfg.IsPersonalTime(s.Timestamp) {
+			continue
+		}
+		ids = append(ids, s.ID)
+		if s.ImagePath != "" {
+			removeRemoteImage(images, s.ImagePath)
+			if err := os.Remove(s.ImagePath); err != nil && !os.IsNotExist(err) {
+				logger.GetLogger().Warnf("Failed to delete personal screenshot %s: %v", s.ImagePath, err)
+			}
+		}
+		day := cfg.Storage.DayStart(s.Timestamp)
+		days[PersonalPeriodKey(day)] = day
+	}
+	if err := st.DeleteScreenshotsByIDs(ids); err != nil {
+		return nil, fmt.Errorf("failed to delete personal screenshots: %w", err)
+	}
+	result.Screenshots = len(ids)
+
+	for periodKey := range days {
+		existing, err := st.GetPeriodSummary(periodKey)
+		if err != nil || existing == nil {
+			continue
+		}
+		if err := st.DeletePeriodSummary(periodKey); err != nil {
+			logger.GetLogger().Warnf("Failed to delete personal summary %s: %v", periodKey, err)
+			continue
+		}
+		result.Summaries++
+	}
+
+	// Report files are matched by modification time: the personal tree only holds personal reports
+	if cfg.Personal.ReportsPath != "" {
+		err := filepath.Walk(cfg.Personal.ReportsPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !info.ModTime().Before(cutoff) {
+				return nil
+			}
+			if err := os.Remove(path); err == nil {
+				result.Reports++
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return result, fmt.Errorf("failed to clean personal reports: %w", err)
+		}
+	}
+
+	logger.GetLogger().Infof("Personal cleanup: removed %d screenshot(s), %d summary(ies), %d report(s) older than %d days",
+		result.Screenshots, result.Summaries, result.Reports, cfg.Personal.RetentionDays)
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/timeline"
+)
+
+// planFocusCategories are the activity categories that count as working on a planned focus block
+var planFocusCategories = map[string]bool{
+	focus.CategoryCoding: true,
+	focus.CategoryDocs:   true,
+	focus.CategoryOther:  true,
+}
+
+// BlockAdherence compares a planned focus block with the time captured in it
+type BlockAdherence struct {
+	Block          *storage.PlanBlock
+	FocusedMinutes int            // Coding, docs and other work captured inside the block
+	OtherMinutes   map[string]int // Other captured activity inside the block (meetings, communication, ...), by category
+	StartDelay     time.Duration  // From the block start to the first focused minute, -1 when it never started
+	Adherence      float64        // Share of the block spent focused, 0-1
+	Upcoming       bool           // The block has not ended yet; it is left out of the score
+}
+
+// PlannedMinutes is the length of the block in minutes
+func (a *BlockAdherence) PlannedMinutes() int {
+	return int(a.Block.EndTime.Sub(a.Block.StartTime) / time.Minute)
+}
+
+// ParsePlanRange parses a "09:30-11:30" time range on day; a range ending at or before its start
+// ends on the next day
+func ParsePlanRange(day time.Time, spec string) (time.Time, time.Time, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range %q, expected HH:MM-HH:MM", spec)
+	}
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	parse := func(value string) (time.Time, error) {
+		clock, err := time.Parse("15:04", strings.TrimSpace(value))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q, expected HH:MM", value)
+		}
+		return date.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute), nil
+	}
+	start, err := parse(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parse(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// ComparePlan compares the focus blocks planned for day with the activity timeline of the day
+// Returns nil when nothing was planned
+func ComparePlan(cfg *config.Config, st *storage.Storage, day, now time.Time) ([]*BlockAdherence, error) {
+	dayStart := cfg.Storage.DateStart(day)
+	blocks, err := st.QueryPlanBlocks(dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	segments, err := DayTimeline(cfg, st, day)
+	if err != nil {
+		return nil, err
+	}
+	return compareBlocks(blocks, segments, now), nil
+}
+
+// compareBlocks measures the focused and other captured minutes of each block from the timeline segments
+func compareBlocks(blocks []*storage.PlanBlock, segments []*timeline.Segment, now time.Time) []*BlockAdherence {
+	results := make([]*BlockAdherence, 0, len(blocks))
+	for _, block := range blocks {
+		result := &BlockAdherence{
+			Block:        block,
+			OtherMinutes: make(map[string]int),
+			StartDelay:   -1,
+			Upcoming:     now.Before(block.EndTime),
+		}
+		for _, s := range segments {
+			start, end := s.Start, s.End
+			if start.Before(block.StartTime) {
+				start = block.StartTime
+			}
+			if end.After(block.EndTime) {
+				end = block.EndTime
+			}
+			minutes := int(end.Sub(start) / time.Minute)
+			if minutes <= 0 {
+				continue
+			}
+			switch {
+			case planFocusCategories[s.Category]:
+				result.FocusedMinutes += minutes
+				if delay := start.Sub(block.StartTime); result.StartDelay < 0 || delay < result.StartDelay {
+					result.StartDelay = delay
+				}
+			case s.Category != focus.CategoryIdle && s.Category != timeline.CategoryUnknown:
+				result.OtherMinutes[s.Category] += minutes
+			}
+		}
+		if planned := result.PlannedMinutes(); planned > 0 {
+			result.Adherence = math.Min(1, float64(result.FocusedMinutes)/float64(planned))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PlanScore is the share of the planned time of ended blocks spent focused, 0-100
+// Returns false when no planned block has ended yet
+func PlanScore(results []*BlockAdherence) (int, bool) {
+	planned, focused := planTotals(results)
+	if planned == 0 {
+		return 0, false
+	}
+	return int(math.Round(100 * float64(focused) / float64(planned))), true
+}
+
+// planTotals returns the planned and focused minutes of the ended blocks
+func planTotals(results []*BlockAdherence) (planned, focused int) {
+	for _, r := range results {
+		if !r.Upcoming {
+			planned += r.PlannedMinutes()
+			focused += min(r.FocusedMinutes, r.PlannedMinutes())
+		}
+	}
+	return planned, focused
+}
+
+// formatPlanSection renders planned versus actual focus blocks as a markdown section
+// Returns an empty string if nothing was planned
+func formatPlanSection(results []*BlockAdherence) string {
+	if len(results) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 计划与实际\n\n")
+	if score, ok := PlanScore(results); ok {
+		planned, focused := planTotals(results)
+		sb.WriteString(fmt.Sprintf("**计划执行度**: %d / 100（计划专注 %s，实际专注 %s）\n\n",
+			score, formatMinutes(float64(planned)), formatMinutes(float64(focused))))
+	}
+
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("- %s-%s %s：", datefmt.TimeMinute(r.Block.StartTime), datefmt.TimeMinute(r.Block.EndTime), r.Block.Title))
+		switch {
+		case r.Upcoming && r.FocusedMinutes == 0:
+			sb.WriteString("尚未开始")
+		case r.StartDelay < 0:
+			sb.WriteString("未执行")
+		default:
+			sb.WriteString(fmt.Sprintf("实际专注 %s（%.0f%%）", formatMinutes(float64(r.FocusedMinutes)), r.Adherence*100))
+			if r.StartDelay >= time.Minute {
+				sb.WriteString(fmt.Sprintf("，晚 %s 开始", formatMinutes(r.StartDelay.Minutes())))
+			}
+		}
+		if other := formatOtherMinutes(r.OtherMinutes); other != "" {
+			sb.WriteString("；期间 " + other)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatOtherMinutes renders the other activity of a block as "会议 20m、沟通 10m", longest first
+func formatOtherMinutes(minutes map[string]int) string {
+	categories := make([]string, 0, len(minutes))
+	for category := range minutes {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if minutes[categories[i]] != minutes[categories[j]] {
+			return minutes[categories[i]] > minutes[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		name := focusCategoryNames[category]
+		if name == "" {
+			name = category
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", name, formatMinutes(float64(minutes[category]))))
+	}
+	return strings.Join(parts, "、")
+}
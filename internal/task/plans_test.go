@@ -0,0 +1,71 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/timeline"
+)
+
+func TestParsePlanRange(t *testing.T) {
+	day := time.Date(2025, 12, 10, 0, 0, 0, 0, time.Local)
+	start, end, err := ParsePlanRange(day, "09:30-11:30")
+	if err != nil || start.Hour() != 9 || start.Minute() != 30 || end.Sub(start) != 2*time.Hour {
+		t.Errorf("ParsePlanRange() = %s, %s, %v", start, end, err)
+	}
+	if _, end, err := ParsePlanRange(day, "23:00-01:00"); err != nil || end.Day() != 11 {
+		t.Errorf("overnight block ends %s, %v", end, err)
+	}
+	for _, spec := range []string{"09:30", "9h-11h", "25:00-26:00"} {
+		if _, _, err := ParsePlanRange(day, spec); err == nil {
+			t.Errorf("ParsePlanRange(%q) accepted an invalid range", spec)
+		}
+	}
+}
+
+func TestCompareBlocks(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 12, 10, hour, minute, 0, 0, time.Local)
+	}
+	blocks := []*storage.PlanBlock{
+		{ID: 1, StartTime: at(9, 30), EndTime: at(11, 30), Title: "spec writing"},
+		{ID: 2, StartTime: at(14, 0), EndTime: at(15, 0), Title: "code review"},
+		{ID: 3, StartTime: at(17, 0), EndTime: at(18, 0), Title: "planning"},
+	}
+	segments := []*timeline.Segment{
+		{Start: at(9, 0), End: at(9, 40), Category: focus.CategoryCommunication},
+		{Start: at(9, 40), End: at(11, 0), Category: focus.CategoryDocs},
+		{Start: at(11, 0), End: at(11, 20), Category: focus.CategoryMeeting},
+		{Start: at(11, 20), End: at(12, 0), Category: focus.CategoryCoding},
+		{Start: at(14, 0), End: at(15, 0), Category: focus.CategoryIdle},
+	}
+
+	results := compareBlocks(blocks, segments, at(16, 0))
+	spec := results[0]
+	if spec.FocusedMinutes != 90 || spec.StartDelay != 10*time.Minute || spec.Adherence != 0.75 {
+		t.Errorf("spec writing = %d focused, %s delay, %.2f adherence", spec.FocusedMinutes, spec.StartDelay, spec.Adherence)
+	}
+	if spec.OtherMinutes[focus.CategoryCommunication] != 10 || spec.OtherMinutes[focus.CategoryMeeting] != 20 {
+		t.Errorf("spec writing other minutes = %v", spec.OtherMinutes)
+	}
+	if review := results[1]; review.FocusedMinutes != 0 || review.StartDelay >= 0 || len(review.OtherMinutes) != 0 {
+		t.Errorf("code review = %+v, want not started", review)
+	}
+	if !results[2].Upcoming {
+		t.Errorf("planning block should be upcoming")
+	}
+
+	// 90 of 180 planned minutes of the ended blocks were focused
+	if score, ok := PlanScore(results); !ok || score != 50 {
+		t.Errorf("PlanScore() = %d, %v, want 50", score, ok)
+	}
+	section := formatPlanSection(results)
+	for _, want := range []string{"**计划执行度**: 50 / 100", "晚 10m 开始", "期间 会议 20m、沟通 10m", "code review：未执行", "planning：尚未开始"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section missing %q:\n%s", want, section)
+		}
+	}
+}
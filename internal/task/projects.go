@@ -0,0 +1,112 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/projects"
+	"stuff-time/internal/storage"
+)
+
+// RepoTime is the time spent in a repository branch (and monorepo sub-project) over a period
+type RepoTime struct {
+	Repo        string
+	Subproject  string
+	Branch      string
+	Screenshots int
+	Minutes     float64
+}
+
+// recordWindow attributes the frontmost IDE/terminal window to a repository and branch
+// and stores it for the screenshot. Windows that can't be attributed are only stored, without
+// a repository, when screenshot.record_apps is set
+func (e *Executor) recordWindow(record *storage.ScreenshotRecord, app, title string) {
+	var attribution projects.Attribution
+	var ok bool
+	if e.config.Projects.Enabled {
+		resolver := &projects.Resolver{Roots: e.config.Projects.Roots}
+		attribution, ok = resolver.Resolve(app, title)
+	}
+	if !ok && !e.config.Screenshot.RecordApps {
+		return
+	}
+
+	window := &storage.ScreenshotWindow{
+		ScreenshotID: record.ID,
+		Timestamp:    record.Timestamp,
+		App:          app,
+		Title:        title,
+		Repo:         attribution.Repo,
+		Subproject:   attribution.Subproject,
+		Branch:       attribution.Branch,
+	}
+	if err := e.storage.SaveScreenshotWindow(window); err != nil {
+		logger.GetLogger().Warnf("Failed to save window attribution for %s: %v", record.ID, err)
+	}
+}
+
+// AggregateRepoTime aggregates the time per repository/sub-project/branch in [start, end)
+// Each attributed screenshot counts as one capture interval
+func AggregateRepoTime(st *storage.Storage, start, end time.Time, interval time.Duration) ([]*RepoTime, error) {
+	windows, err := st.QueryScreenshotWindows(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*RepoTime)
+	for _, w := range windows {
+		// App-only records (screenshot.record_apps) aren't attributed to a repository
+		if w.Repo == "" {
+			continue
+		}
+		key := w.Repo + "\x00" + w.Subproject + "\x00" + w.Branch
+		rt, ok := byKey[key]
+		if !ok {
+			rt = &RepoTime{Repo: w.Repo, Subproject: w.Subproject, Branch: w.Branch}
+			byKey[key] = rt
+		}
+		rt.Screenshots++
+		rt.Minutes += interval.Minutes()
+	}
+
+	result := make([]*RepoTime, 0, len(byKey))
+	for _, rt := range byKey {
+		result = append(result, rt)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Minutes != result[j].Minutes {
+			return result[i].Minutes > result[j].Minutes
+		}
+		return result[i].Repo+result[i].Subproject+result[i].Branch < result[j].Repo+result[j].Subproject+result[j].Branch
+	})
+	return result, nil
+}
+
+// formatReposSection renders the time per repository/branch of a period as a markdown section
+// Returns an empty string if no coding windows were attributed
+func formatReposSection(repoTimes []*RepoTime) string {
+	if len(repoTimes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 仓库与分支\n\n")
+	sb.WriteString("| 仓库 | 子项目 | 分支 | 时长 |\n")
+	sb.WriteString("|------|--------|------|------|\n")
+	for _, rt := range repoTimes {
+		subproject := rt.Subproject
+		if subproject == "" {
+			subproject = "-"
+		}
+		branch := rt.Branch
+		if branch == "" {
+			branch = "-"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", rt.Repo, subproject, branch, formatMinutes(rt.Minutes)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// levelPromptNames are the per-level summary prompts; a summary only uses the one of its period type
+var levelPromptNames = map[string]bool{
+	"summary/fifteenmin": true,
+	"summary/hour":       true,
+	"summary/day":        true,
+	"summary/week":       true,
+	"summary/month":      true,
+	"summary/quarter":    true,
+	"summary/year":       true,
+}
+
+// ApplyStoredPrompts replaces the prompt contents of cfg with their active database versions
+// Prompts without a stored version keep their file content. Returns the applied version of each prompt
+func ApplyStoredPrompts(cfg *config.Config, st *storage.Storage) (map[string]int, error) {
+	active, err := st.ListActivePrompts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored prompts: %w", err)
+	}
+	versions := make(map[string]int)
+	for _, stored := range active {
+		prompt := cfg.PromptFile(stored.Name)
+		if prompt == nil {
+			logger.GetLogger().Warnf("Stored prompt %s is not used by the configuration, ignoring it", stored.Name)
+			continue
+		}
+		*prompt.Content = stored.Content
+		versions[stored.Name] = stored.Version
+	}
+	if len(versions) > 0 {
+		logger.GetLogger().Infof("Using %d prompt(s) from the database", len(versions))
+	}
+	return versions, nil
+}
+
+// summaryPromptVersions returns the database prompt versions a summary of the period is generated with,
+// as "<name>@<version>" sorted by name; empty when prompts come from files
+func (e *Executor) summaryPromptVersions(periodKey string) []string {
+	if len(e.promptVersions) == 0 {
+		return nil
+	}
+	periodType := ""
+	if parsed, err := storage.ParsePeriodKey(periodKey); err == nil {
+		periodType = parsed.Type
+	}
+	var prompts []string
+	for name, version := range e.promptVersions {
+		if !strings.HasPrefix(name, "summary/") && name != "analysis/analysis" {
+			continue
+		}
+		if levelPromptNames[name] && name != "summary/"+periodType {
+			continue
+		}
+		prompts = append(prompts, fmt.Sprintf("%s@%d", name, version))
+	}
+	sort.Strings(prompts)
+	return prompts
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// readingIndicators are keywords indicating the user is reading a web page, PDF or document
+var readingIndicators = []string{
+	"浏览器", "网页", "文章", "博客", "阅读", "pdf", "论文", "文档", "官方文档", "手册", "教程",
+	"chrome", "safari", "firefox", "edge", "arc 浏览器", "preview", "预览",
+	"stack overflow", "stackoverflow", "wikipedia", "维基", "知乎", "medium", "arxiv",
+}
+
+// isReadingAnalysis checks if a screenshot analysis describes reading/research activity
+func isReadingAnalysis(analysis string) bool {
+	lower := strings.ToLower(analysis)
+	for _, indicator := range readingIndicators {
+		if strings.Contains(lower, indicator) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateResearchLog generates the research log of a day from its reading-related screenshots
+// and stores it as a day report attachment. Days with too little reading are skipped (returns nil)
+func (e *Executor) GenerateResearchLog(day time.Time) (*storage.PeriodAttachment, error) {
+	if e.config.Reading.PromptContent == "" {
+		return nil, nil
+	}
+
+	dayStart := e.config.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	dayKey := storage.FormatPeriodKey("day", dayStart)
+
+	screenshots, err := e.storage.QueryByDateRange(dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	screenshots = e.workScreenshots(screenshots)
+
+	var lines []string
+	for _, s := range screenshots {
+		if !s.HasAnalysis() || isDesktopOrLockScreenAnalysis(s.Analysis) {
+			continue
+		}
+		if isReadingAnalysis(s.Analysis) {
+			lines = append(lines, fmt.Sprintf("[%s] %s", s.Timestamp.Format("15:04"), s.Analysis))
+		}
+	}
+
+	minScreenshots := e.config.Reading.MinScreenshots
+	if minScreenshots <= 0 {
+		minScreenshots = 5
+	}
+	if len(lines) < minScreenshots {
+		logger.GetLogger().Debugf("Only %d reading screenshot(s) for %s, skipping research log", len(lines), dayKey)
+		// Remove a stale research log from an earlier run
+		if err := e.storage.ReplaceAttachments(dayKey, storage.AttachmentResearchLog, nil); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	content, err := e.analyzer.GenerateResearchLog(e.config.Reading.PromptContent, strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate research log: %w", err)
+	}
+
+	attachment := &storage.PeriodAttachment{
+		Title:     "研究日志",
+		StartTime: dayStart,
+		EndTime:   dayEnd,
+		Content:   strings.TrimSpace(content),
+	}
+	if err := e.storage.ReplaceAttachments(dayKey, storage.AttachmentResearchLog, []*storage.PeriodAttachment{attachment}); err != nil {
+		return nil, err
+	}
+
+	logger.GetLogger().Infof("Research log generated for %s from %d reading screenshot(s)", dayKey, len(lines))
+	return attachment, nil
+}
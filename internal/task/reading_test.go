@@ -0,0 +1,63 @@
+package task
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestGenerateResearchLog(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Reading.PromptContent = "write the research log"
+	e, st := newTestExecutor(t, cfg)
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	analyses := []string{"在 Chrome 中阅读 SQLite 官方文档", "在终端中运行 go test", "在 arXiv 上浏览论文", "在预览中打开 PDF 手册"}
+	for i, analysis := range analyses {
+		record := &storage.ScreenshotRecord{ID: fmt.Sprintf("s%d", i), Timestamp: day.Add(10*time.Hour + time.Duration(i)*time.Minute),
+			ImagePath: fmt.Sprintf("/nonexistent/%d.png", i), Analysis: analysis}
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := analyzer.NewOpenAI("test-key", "", "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	if err := client.ConfigureBackend(analyzer.BackendModeMock, "", "  读了 SQLite 文档和一篇论文  "); err != nil {
+		t.Fatal(err)
+	}
+	e.analyzer = client
+
+	tests := []struct {
+		name           string
+		minScreenshots int
+		want           string // empty when the day has too little reading for a log
+	}{
+		{"enough reading", 3, "读了 SQLite 文档和一篇论文"},
+		{"too little reading removes the stale log", 4, ""},
+	}
+	for _, tt := range tests {
+		cfg.Reading.MinScreenshots = tt.minScreenshots
+		log, err := e.GenerateResearchLog(day.Add(12 * time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored, err := st.GetAttachments("day:2025-12-09", storage.AttachmentResearchLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.want == "" {
+			if log != nil || len(stored) != 0 {
+				t.Errorf("%s: GenerateResearchLog() = %v with %d stored, want none", tt.name, log, len(stored))
+			}
+			continue
+		}
+		if log == nil || log.Content != tt.want || len(stored) != 1 || stored[0].Content != tt.want {
+			t.Errorf("%s: GenerateResearchLog() = %v with %d stored, want %q", tt.name, log, len(stored), tt.want)
+		}
+	}
+}
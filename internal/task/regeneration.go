@@ -0,0 +1,50 @@
+package task
+
+import (
+	"sync"
+	"time"
+)
+
+// regenerationDebounce is the minimum time between two checks of an hour for outdated screenshot reports
+const regenerationDebounce = 5 * time.Minute
+
+// regenerationRetention is how long the check of an hour is remembered; older hours are dropped
+const regenerationRetention = 24 * time.Hour
+
+// regenerationTracker remembers the last check of each hour for outdated screenshot reports, so batch
+// analysis triggers only rescan an hour when new analyses arrived since and the debounce window passed
+type regenerationTracker struct {
+	mu    sync.Mutex
+	hours map[string]regenerationCheck // By hour key
+}
+
+type regenerationCheck struct {
+	analyzed int // Analyzed screenshots of the hour at the check
+	at       time.Time
+}
+
+// due reports whether an hour with the given number of analyzed screenshots needs a check at now
+func (t *regenerationTracker) due(hourKey string, analyzed int, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last, ok := t.hours[hourKey]
+	if !ok {
+		return true
+	}
+	return analyzed != last.analyzed && now.Sub(last.at) >= regenerationDebounce
+}
+
+// done records a completed check of an hour and forgets the checks of old hours
+func (t *regenerationTracker) done(hourKey string, analyzed int, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hours == nil {
+		t.hours = make(map[string]regenerationCheck)
+	}
+	for key, check := range t.hours {
+		if now.Sub(check.at) > regenerationRetention {
+			delete(t.hours, key)
+		}
+	}
+	t.hours[hourKey] = regenerationCheck{analyzed: analyzed, at: now}
+}
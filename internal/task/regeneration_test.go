@@ -0,0 +1,39 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegenerationTracker(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	var tracker regenerationTracker
+
+	if !tracker.due("2025-12-09-14", 3, base) {
+		t.Fatalf("first check of an hour should be due")
+	}
+	tracker.done("2025-12-09-14", 3, base)
+
+	tests := []struct {
+		name     string
+		analyzed int
+		after    time.Duration
+		want     bool
+	}{
+		{"no new analyses", 3, time.Hour, false},
+		{"new analyses within the debounce window", 5, time.Minute, false},
+		{"new analyses after the debounce window", 5, regenerationDebounce, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tracker.due("2025-12-09-14", tt.analyzed, base.Add(tt.after)); got != tt.want {
+				t.Errorf("due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tracker.done("2025-12-10-09", 1, base.Add(regenerationRetention+time.Minute))
+	if !tracker.due("2025-12-09-14", 3, base.Add(regenerationRetention+time.Minute)) {
+		t.Errorf("check of an hour older than the retention should be forgotten")
+	}
+}
@@ -0,0 +1,489 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stuff-time/internal/agent"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// remoteSummaryDays is how far back a worker looks for summaries to push
+const remoteSummaryDays = 8
+
+// RemoteQueue hands the analysis queue of a capture-only daemon to remote workers and merges
+// their results back
+type RemoteQueue struct {
+	e *Executor
+}
+
+// NewRemoteQueue creates the analysis queue served to remote workers
+func NewRemoteQueue(e *Executor) *RemoteQueue {
+	return &RemoteQueue{e: e}
+}
+
+// Export hands up to limit unanalyzed screenshots, oldest first, to worker
+// Screenshots handed to a worker are reserved for remote_analysis.lease; when no result arrives
+// by then they are handed out again
+func (q *RemoteQueue) Export(worker string, limit int) ([]*agent.QueueItem, error) {
+	now := time.Now()
+	leased, err := q.e.storage.GetExportedScreenshots(now.Add(-q.e.config.RemoteAnalysis.GetLeaseDuration()))
+	if err != nil {
+		return nil, err
+	}
+	records, err := q.e.storage.GetUnanalyzedScreenshots(limit + len(leased))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unanalyzed screenshots: %w", err)
+	}
+
+	var pending []*storage.ScreenshotRecord
+	for _, record := range records {
+		if !leased[record.ID] && len(pending) < limit {
+			pending = append(pending, record)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	windows := make(map[string]*storage.ScreenshotWindow)
+	recorded, err := q.e.storage.QueryScreenshotWindows(pending[0].Timestamp, pending[len(pending)-1].Timestamp.Add(time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+	}
+	for _, w := range recorded {
+		windows[w.ScreenshotID] = w
+	}
+
+	items := make([]*agent.QueueItem, 0, len(pending))
+	ids := make([]string, 0, len(pending))
+	for _, record := range pending {
+		// Don't risk handing out a screenshot that may be local-only without its mark
+		mark, err := q.e.storage.GetLocalOnly(record.ImagePath)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to check local-only mark of %s, not exporting it: %v", record.ID, err)
+			continue
+		}
+
+		item := &agent.QueueItem{
+			ID:        record.ID,
+			Timestamp: record.Timestamp,
+			ScreenID:  record.ScreenID,
+			Format:    strings.TrimPrefix(filepath.Ext(record.ImagePath), "."),
+		}
+		if w := windows[record.ID]; w != nil {
+			item.App, item.Title = w.App, w.Title
+		} else if record.App != "" {
+			item.App, item.Title = record.App, record.WindowTitle
+		}
+		if mark != nil {
+			item.LocalOnly = true
+			item.App, item.Title = mark.App, mark.Title
+		}
+		items = append(items, item)
+		ids = append(ids, record.ID)
+	}
+
+	if err := q.e.storage.MarkScreenshotsExported(ids, worker, now); err != nil {
+		return nil, err
+	}
+	logger.GetLogger().Infof("Handed %d screenshot(s) to worker %s", len(items), worker)
+	return items, nil
+}
+
+// OpenImage opens the image of a queued screenshot
+func (q *RemoteQueue) OpenImage(id string) (io.ReadCloser, error) {
+	records, err := q.e.storage.GetScreenshotsByIDs([]string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshot: %w", err)
+	}
+	record, ok := records[id]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if err := q.e.fetchImage(record); err != nil {
+		return nil, fmt.Errorf("failed to fetch screenshot image: %w", err)
+	}
+	return os.Open(record.ImagePath)
+}
+
+// Merge applies the results pushed by a worker
+// Merging is conflict-free: an analysis only fills a screenshot that is still pending or failed here,
+// so the first result wins and pushing the same results again changes nothing; a summary replaces the
+// stored one only if it is newer than the last merged version of the period and the period isn't locked
+func (q *RemoteQueue) Merge(results *agent.Results) (*agent.MergeResult, error) {
+	merged := &agent.MergeResult{}
+
+	ids := make([]string, 0, len(results.Analyses))
+	for _, a := range results.Analyses {
+		ids = append(ids, a.ID)
+	}
+	records, err := q.e.storage.GetScreenshotsByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshots: %w", err)
+	}
+
+	var updated []*storage.ScreenshotRecord
+	var updates []*storage.ScreenshotAnalysisUpdate
+	for _, a := range results.Analyses {
+		record, ok := records[a.ID]
+		if !ok || !isRemoteResultStatus(a.Status) {
+			continue
+		}
+		if record.AnalysisStatus != storage.AnalysisPending && record.AnalysisStatus != storage.AnalysisFailed {
+			continue
+		}
+		record.AnalysisStatus = a.Status
+		record.Analysis, record.AnalysisError = "", a.Error
+		if a.Status == storage.AnalysisDone {
+			record.Analysis, record.AnalysisError = a.Analysis, ""
+		}
+		updated = append(updated, record)
+		updates = append(updates, &storage.ScreenshotAnalysisUpdate{
+			ID:       record.ID,
+			Status:   record.AnalysisStatus,
+			Analysis: record.Analysis,
+			Error:    record.AnalysisError,
+		})
+	}
+	if err := q.e.storage.UpdateScreenshotAnalyses(updates); err != nil {
+		return nil, fmt.Errorf("failed to update analyses: %w", err)
+	}
+	merged.Analyses = len(updates)
+
+	updatedHours := make(map[string]bool)
+	for _, record := range updated {
+		if record.AnalysisStatus == storage.AnalysisSkipped || record.AnalysisStatus == storage.AnalysisSampledOut {
+			continue
+		}
+		if !updatedHours[record.HourKey] {
+			updatedHours[record.HourKey] = true
+			if err := q.e.updateHourSummary(record); err != nil {
+				logger.GetLogger().Warnf("Failed to update hour summary for %s: %v", record.HourKey, err)
+			}
+		}
+		if err := q.e.saveReport(record); err != nil {
+			logger.GetLogger().Warnf("Failed to save report of %s: %v", record.ID, err)
+		}
+	}
+
+	versions, err := q.e.storage.GetRemoteSummaryVersions()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range results.Summaries {
+		// Workers of older versions push version 1 period keys
+		s.PeriodKey = storage.NormalizePeriodKey(s.PeriodKey)
+		hash := agent.SummaryHash(s.PeriodKey, s.Screenshots, s.Summary, s.Analysis)
+		if v := versions[s.PeriodKey]; v != nil && (v.Hash == hash || !s.GeneratedAt.After(v.GeneratedAt)) {
+			continue
+		}
+		if q.e.isPeriodLocked(s.PeriodKey) {
+			logger.GetLogger().Infof("Period %s is locked, ignoring the summary of worker %s", s.PeriodKey, results.Worker)
+			continue
+		}
+
+		summary := &storage.PeriodSummary{
+			PeriodKey:   s.PeriodKey,
+			PeriodType:  s.PeriodType,
+			StartTime:   s.StartTime.Local(),
+			EndTime:     s.EndTime.Local(),
+			Screenshots: s.Screenshots,
+			Summary:     s.Summary,
+			Analysis:    s.Analysis,
+		}
+		if err := q.e.CheckManualEdits(summary); err != nil {
+			logger.GetLogger().Warnf("Ignoring the summary of worker %s: %v", results.Worker, err)
+			continue
+		}
+		if err := q.e.storage.SavePeriodSummary(summary); err != nil {
+			return merged, fmt.Errorf("failed to save summary %s: %w", s.PeriodKey, err)
+		}
+		if hasValidContent(&q.e.config.Summary, summary) {
+			if err := q.e.savePeriodSummaryReport(summary); err != nil {
+				logger.GetLogger().Warnf("Failed to save report of %s: %v", s.PeriodKey, err)
+			}
+		}
+		version := &storage.RemoteSummaryVersion{PeriodKey: s.PeriodKey, Hash: hash, GeneratedAt: s.GeneratedAt, Worker: results.Worker}
+		if err := q.e.storage.SaveRemoteSummaryVersion(version); err != nil {
+			return merged, err
+		}
+		events.EmitSummaryGenerated(s.PeriodType, s.PeriodKey)
+		merged.Summaries++
+	}
+
+	if merged.Analyses > 0 || merged.Summaries > 0 {
+		logger.GetLogger().Infof("Merged results of worker %s: %d analyses, %d summaries",
+			results.Worker, merged.Analyses, merged.Summaries)
+		events.Emit(events.LevelInfo, events.ComponentAnalyzer, "Merged results of worker %s: %d analyses, %d summaries",
+			results.Worker, merged.Analyses, merged.Summaries)
+	}
+	return merged, nil
+}
+
+// isRemoteResultStatus reports whether a worker may push an analysis with the status;
+// failed analyses stay with the worker, which retries them
+func isRemoteResultStatus(status string) bool {
+	switch status {
+	case storage.AnalysisDone, storage.AnalysisSkipped, storage.AnalysisRejected, storage.AnalysisSampledOut:
+		return true
+	}
+	return false
+}
+
+// RemoteWorker analyzes the queue of a capture-only daemon: it pulls screenshots into its own
+// database, analyzes and summarizes them like a local daemon, and pushes the results back
+type RemoteWorker struct {
+	e      *Executor
+	client *agent.Client
+	name   string
+}
+
+// RemoteWorkerResult summarizes one worker run
+type RemoteWorkerResult struct {
+	Pulled    int // Screenshots pulled from the capture daemon
+	Analyses  int // Analyses merged by the capture daemon
+	Summaries int // Summaries merged by the capture daemon
+}
+
+// NewRemoteWorker creates a worker named name pulling from the daemon behind client
+func NewRemoteWorker(e *Executor, client *agent.Client, name string) *RemoteWorker {
+	return &RemoteWorker{e: e, client: client, name: name}
+}
+
+// Run pulls a batch of screenshots, analyzes them, regenerates summaries and pushes the results
+// Results that couldn't be pushed are kept and pushed by the next run
+func (w *RemoteWorker) Run() (*RemoteWorkerResult, error) {
+	result := &RemoteWorkerResult{}
+
+	pulled, err := w.pull()
+	result.Pulled = pulled
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to pull the analysis queue: %v", err)
+	}
+
+	w.e.analysisMutex.Lock()
+	w.e.setAnalyzing(true)
+	err = w.e.doBatchAnalyze()
+	w.e.setAnalyzing(false)
+	w.e.analysisMutex.Unlock()
+	if err != nil {
+		logger.GetLogger().Warnf("Batch analysis failed: %v", err)
+	}
+	if err := w.e.CheckAndFillMissingSummaries(7); err != nil {
+		logger.GetLogger().Warnf("Failed to fill missing summaries: %v", err)
+	}
+	if err := w.e.GeneratePeriodSummary(false, false); err != nil {
+		logger.GetLogger().Warnf("Failed to generate summaries: %v", err)
+	}
+
+	merged, err := w.push()
+	if err != nil {
+		return result, fmt.Errorf("failed to push results: %w", err)
+	}
+	result.Analyses, result.Summaries = merged.Analyses, merged.Summaries
+	return result, nil
+}
+
+// pull imports queued screenshots with their capture daemon IDs, so results map back to them
+func (w *RemoteWorker) pull() (int, error) {
+	batchSize := w.e.config.RemoteAnalysis.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	items, err := w.client.PullQueue(w.name, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	existing, err := w.e.storage.GetScreenshotsByIDs(ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get screenshots: %w", err)
+	}
+
+	pulled := 0
+	for _, item := range items {
+		// Screenshots handed out again after the lease expired are already here
+		if _, ok := existing[item.ID]; ok {
+			continue
+		}
+		if err := w.importItem(item); err != nil {
+			logger.GetLogger().Warnf("Failed to import screenshot %s: %v", item.ID, err)
+			continue
+		}
+		pulled++
+	}
+	if pulled > 0 {
+		logger.GetLogger().Infof("Pulled %d screenshot(s) from %s", pulled, w.client.ServerURL)
+	}
+	return pulled, nil
+}
+
+func (w *RemoteWorker) importItem(item *agent.QueueItem) error {
+	timestamp := item.Timestamp.Local()
+	imagePath, err := remoteImagePath(w.e.config.Screenshot.StoragePath, item.ID, timestamp, item.Format)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(imagePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.Create(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	err = w.client.DownloadImage(item.ID, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(imagePath)
+		return err
+	}
+
+	record := storage.NewScreenshotRecord(item.ScreenID, imagePath)
+	record.ID = item.ID
+	record.Timestamp = timestamp
+	record.GenerateHourKey()
+	if w.e.config.Screenshot.WindowContext {
+		record.App, record.WindowTitle = item.App, item.Title
+	}
+	if err := w.e.storage.SaveScreenshot(record); err != nil {
+		os.Remove(imagePath)
+		return fmt.Errorf("failed to save screenshot record: %w", err)
+	}
+
+	if item.App != "" {
+		w.e.recordWindow(record, item.App, item.Title)
+		w.e.recordRoute(record, item.App, item.Title)
+	}
+	// The capture daemon's local-only marks apply here too, in addition to the worker's own
+	if item.LocalOnly {
+		mark := &storage.LocalOnlyScreenshot{ImagePath: imagePath, Timestamp: timestamp, App: item.App, Title: item.Title}
+		if err := w.e.storage.MarkLocalOnly(mark); err != nil {
+			os.Remove(imagePath)
+			return fmt.Errorf("failed to mark screenshot local-only: %w", err)
+		}
+	} else if item.App != "" {
+		w.e.recordLocalOnly(record, item.App, item.Title)
+	}
+
+	return w.e.storage.SaveRemoteImport(record.ID, time.Now())
+}
+
+// push sends the finished analyses of pulled screenshots and the summaries that changed since the
+// last push
+func (w *RemoteWorker) push() (*agent.MergeResult, error) {
+	results := &agent.Results{Worker: w.name}
+
+	ids, err := w.e.storage.ListUnpushedImports()
+	if err != nil {
+		return nil, err
+	}
+	records, err := w.e.storage.GetScreenshotsByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshots: %w", err)
+	}
+	var pushedIDs []string
+	for _, id := range ids {
+		record, ok := records[id]
+		if !ok {
+			// Removed here (e.g. by forget), nothing to push
+			pushedIDs = append(pushedIDs, id)
+			continue
+		}
+		if !isRemoteResultStatus(record.AnalysisStatus) {
+			continue
+		}
+		results.Analyses = append(results.Analyses, &agent.AnalysisResult{
+			ID:       record.ID,
+			Status:   record.AnalysisStatus,
+			Analysis: record.Analysis,
+			Error:    record.AnalysisError,
+		})
+		pushedIDs = append(pushedIDs, id)
+	}
+
+	versions, err := w.e.storage.GetRemoteSummaryVersions()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	summaries, err := findOverlappingSummaries(w.e.storage, now.AddDate(0, 0, -remoteSummaryDays), now)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		hash := agent.SummaryHash(summary.PeriodKey, summary.Screenshots, summary.Summary, summary.Analysis)
+		if v := versions[summary.PeriodKey]; v != nil && v.Hash == hash {
+			continue
+		}
+		results.Summaries = append(results.Summaries, &agent.SummaryResult{
+			PeriodKey:   summary.PeriodKey,
+			PeriodType:  summary.PeriodType,
+			StartTime:   summary.StartTime,
+			EndTime:     summary.EndTime,
+			Screenshots: summary.Screenshots,
+			Summary:     summary.Summary,
+			Analysis:    summary.Analysis,
+			GeneratedAt: now,
+			Hash:        hash,
+		})
+	}
+
+	if len(results.Analyses) == 0 && len(results.Summaries) == 0 {
+		if err := w.e.storage.MarkImportsPushed(pushedIDs, now); err != nil {
+			return nil, err
+		}
+		return &agent.MergeResult{}, nil
+	}
+	merged, err := w.client.PushResults(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.e.storage.MarkImportsPushed(pushedIDs, now); err != nil {
+		return merged, err
+	}
+	for _, s := range results.Summaries {
+		version := &storage.RemoteSummaryVersion{PeriodKey: s.PeriodKey, Hash: s.Hash, GeneratedAt: now, Worker: w.name}
+		if err := w.e.storage.SaveRemoteSummaryVersion(version); err != nil {
+			return merged, err
+		}
+	}
+	logger.GetLogger().Infof("Pushed %d analyses and %d summaries, %d and %d merged",
+		len(results.Analyses), len(results.Summaries), merged.Analyses, merged.Summaries)
+	return merged, nil
+}
+
+// remoteImagePath places pulled screenshots under <storage>/remote/, using the same
+// YYYY/QN/MM/WN/DD/HH layout as local captures and the capture daemon's ID as file name
+func remoteImagePath(storagePath, id string, t time.Time, format string) (string, error) {
+	if storagePath == "" {
+		return "", fmt.Errorf("screenshot.storage_path is not configured")
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid screenshot ID %q", id)
+	}
+	if format == "" || strings.ContainsAny(format, `/\`) {
+		format = "png"
+	}
+	quarter := fmt.Sprintf("Q%d", (int(t.Month())-1)/3+1)
+	week := fmt.Sprintf("W%d", (t.Day()-1)/7+1)
+	dir := filepath.Join(storagePath, "remote",
+		t.Format("2006"), quarter, t.Format("01"), week, t.Format("02"), t.Format("15"))
+	return filepath.Join(dir, id+"."+format), nil
+}
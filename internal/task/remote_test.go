@@ -0,0 +1,120 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stuff-time/internal/agent"
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestRemoteQueue(t *testing.T) {
+	e, st := newTestExecutor(t, &config.Config{})
+	q := NewRemoteQueue(e)
+
+	base := time.Date(2025, 11, 21, 14, 0, 0, 0, time.Local)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		record := storage.NewScreenshotRecord(1, filepath.Join(t.TempDir(), "shot.png"))
+		record.Timestamp = base.Add(time.Duration(i) * time.Minute)
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, record.ID)
+	}
+
+	// Exported screenshots are leased to the worker
+	items, err := q.Export("server", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != ids[0] || items[0].Format != "png" {
+		t.Fatalf("Export() = %+v, want the 2 oldest screenshots", items)
+	}
+	if items, err = q.Export("server", 10); err != nil || len(items) != 1 || items[0].ID != ids[2] {
+		t.Fatalf("second Export() = %+v, %v, want only the unleased screenshot", items, err)
+	}
+
+	results := &agent.Results{
+		Worker: "server",
+		Analyses: []*agent.AnalysisResult{
+			{ID: ids[0], Status: storage.AnalysisDone, Analysis: "编写代码"},
+			{ID: ids[1], Status: storage.AnalysisSkipped},
+			{ID: ids[2], Status: storage.AnalysisFailed, Error: "timeout"},
+			{ID: "unknown", Status: storage.AnalysisDone, Analysis: "评审 PR"},
+		},
+	}
+	merged, err := q.Merge(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Analyses != 2 {
+		t.Errorf("Merge() analyses = %d, want 2", merged.Analyses)
+	}
+	// Merging the same results again changes nothing
+	results.Analyses[0].Analysis = "其他内容"
+	if merged, err = q.Merge(results); err != nil || merged.Analyses != 0 {
+		t.Errorf("repeated Merge() = %+v, %v, want no analyses", merged, err)
+	}
+	records, err := st.GetScreenshotsByIDs(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := records[ids[0]]; r.AnalysisStatus != storage.AnalysisDone || r.Analysis != "编写代码" {
+		t.Errorf("screenshot 0 = %s %q, want the first merged analysis", r.AnalysisStatus, r.Analysis)
+	}
+	if r := records[ids[2]]; r.AnalysisStatus != storage.AnalysisPending {
+		t.Errorf("screenshot 2 status = %s, failed results must not be merged", r.AnalysisStatus)
+	}
+
+	summary := func(text string, generatedAt time.Time) *agent.SummaryResult {
+		return &agent.SummaryResult{
+			PeriodKey:   "2025-11-21-14",
+			PeriodType:  "hour",
+			StartTime:   base,
+			EndTime:     base.Add(time.Hour),
+			Screenshots: ids[0],
+			Summary:     text,
+			GeneratedAt: generatedAt,
+		}
+	}
+	tests := []struct {
+		name    string
+		summary *agent.SummaryResult
+		lock    bool
+		want    int
+		stored  string
+	}{
+		{"first version", summary("编写代码", base.Add(time.Hour)), false, 1, "编写代码"},
+		{"same content", summary("编写代码", base.Add(2*time.Hour)), false, 0, "编写代码"},
+		{"older version", summary("评审 PR", base.Add(30*time.Minute)), false, 0, "编写代码"},
+		{"newer version", summary("编写代码并评审 PR", base.Add(3*time.Hour)), false, 1, "编写代码并评审 PR"},
+		{"locked period", summary("其他内容", base.Add(4*time.Hour)), true, 0, "编写代码并评审 PR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.lock {
+				if err := st.LockPeriod(tt.summary.PeriodKey, "已确认"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			merged, err := q.Merge(&agent.Results{Worker: "server", Summaries: []*agent.SummaryResult{tt.summary}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if merged.Summaries != tt.want {
+				t.Errorf("Merge() summaries = %d, want %d", merged.Summaries, tt.want)
+			}
+			stored, err := st.GetPeriodSummary(tt.summary.PeriodKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Summary != tt.stored {
+				t.Errorf("stored summary = %q, want %q", stored.Summary, tt.stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestReportAnalysisSection(t *testing.T) {
+	dir := t.TempDir()
+	st := newTestStorage(t)
+
+	cfg := &config.Config{}
+	cfg.Storage.ReportsPath = filepath.Join(dir, "reports")
+	cfg.Analysis.Levels = []string{"week"}
+	e := NewReportExecutor(cfg, st)
+	week := time.Date(2025, 12, 8, 0, 0, 0, 0, time.Local)
+	summary := &storage.PeriodSummary{PeriodKey: "week:2025-W50", PeriodType: "week", StartTime: week, EndTime: week.AddDate(0, 0, 7),
+		Summary: "完成导出功能的开发和测试，评审了三个合并请求", Analysis: "上午的会议打断了编码", Screenshots: "s1,s2"}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    bool
+	}{
+		{"analysis disabled", false, false},
+		{"analysis enabled", true, true},
+	}
+	for _, tt := range tests {
+		cfg.Analysis.Enabled = tt.enabled
+		if err := e.savePeriodSummaryReport(summary); err != nil {
+			t.Fatal(err)
+		}
+		reportPath, err := e.calculateReportPath(summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := os.ReadFile(reportPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(report), "## 改进建议"); got != tt.want {
+			t.Errorf("%s: report has the analysis section = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
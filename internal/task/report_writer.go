@@ -0,0 +1,48 @@
+package task
+
+import (
+	"sync"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// reportWriter writes screenshot report files in the background,
+// so that batch analysis doesn't wait on report generation and disk writes
+type reportWriter struct {
+	executor *Executor
+	records  chan *storage.ScreenshotRecord
+	wg       sync.WaitGroup
+}
+
+// newReportWriter starts a background report writer with the given queue size
+func (e *Executor) newReportWriter(queueSize int) *reportWriter {
+	w := &reportWriter{
+		executor: e,
+		records:  make(chan *storage.ScreenshotRecord, queueSize),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+func (w *reportWriter) run() {
+	defer w.wg.Done()
+	for record := range w.records {
+		if err := w.executor.saveReport(record); err != nil {
+			logger.GetLogger().Infof("WARNING: Failed to save report for %s: %v",
+				record.ID, err)
+		}
+	}
+}
+
+// Enqueue queues a report write (blocks when the queue is full)
+func (w *reportWriter) Enqueue(record *storage.ScreenshotRecord) {
+	w.records <- record
+}
+
+// Close waits for all queued reports to be written
+func (w *reportWriter) Close() {
+	close(w.records)
+	w.wg.Wait()
+}
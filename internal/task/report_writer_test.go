@@ -0,0 +1,56 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestReportWriterCloseFlushesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	st := newTestStorage(t)
+
+	cfg := &config.Config{}
+	cfg.Storage.ReportsPath = filepath.Join(dir, "reports")
+	e := NewReportExecutor(cfg, st)
+	// Slow report writes so Close has queued work left to wait for
+	e.storageManager.EnableChaos(1, 20*time.Millisecond)
+
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	var records []*storage.ScreenshotRecord
+	for i := 0; i < 5; i++ {
+		records = append(records, &storage.ScreenshotRecord{ID: fmt.Sprintf("s%d", i), Timestamp: start.Add(time.Duration(i) * time.Minute),
+			AnalysisStatus: storage.AnalysisDone, Analysis: fmt.Sprintf("第 %d 次分析", i)})
+	}
+
+	writer := e.newReportWriter(len(records) + 1)
+	for _, record := range records {
+		writer.Enqueue(record)
+	}
+	// A record queued twice must end up with the analysis queued last
+	rewritten := *records[0]
+	rewritten.Analysis = "重新分析"
+	writer.Enqueue(&rewritten)
+	writer.Close()
+
+	for i, record := range records {
+		want := record.Analysis
+		if i == 0 {
+			want = rewritten.Analysis
+		}
+		reportPath := filepath.Join(cfg.Storage.ReportsPath, "2025", "12", "09", "14", fmt.Sprintf("%02d-00.md", i))
+		report, err := os.ReadFile(reportPath)
+		if err != nil {
+			t.Fatalf("report of %s missing after Close: %v", record.ID, err)
+		}
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report of %s does not contain %q", record.ID, want)
+		}
+	}
+}
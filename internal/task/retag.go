@@ -0,0 +1,107 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/projects"
+	"stuff-time/internal/storage"
+)
+
+// RetagResult summarizes a retag run
+type RetagResult struct {
+	Days     int // Days whose issue attributions and focus scores were recomputed
+	Windows  int // Window records checked
+	Retagged int // Window records whose repository, sub-project or branch changed
+	Reports  int // Reports rewritten
+}
+
+// Retag re-applies the tagging rules to the screenshots in [from, to) after they changed (e.g. a
+// repository added under projects.roots or a key added to issues.projects), without calling the LLM:
+// window records are attributed again, issue attributions and focus scores (category mix) recomputed,
+// and reports of day and longer periods overlapping the range rewritten from their stored summaries,
+// so only their statistics sections change
+func (e *Executor) Retag(from, to time.Time) (*RetagResult, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid range: --from must be before --to")
+	}
+	result := &RetagResult{}
+
+	if e.config.Projects.Enabled {
+		windows, err := e.storage.QueryScreenshotWindows(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+		}
+		resolver := &projects.Resolver{Roots: e.config.Projects.Roots}
+		for _, w := range windows {
+			result.Windows++
+			retagged, changed := retagWindow(resolver, w)
+			if !changed {
+				continue
+			}
+			if err := e.storage.SaveScreenshotWindow(retagged); err != nil {
+				return nil, fmt.Errorf("failed to update window of screenshot %s: %w", w.ScreenshotID, err)
+			}
+			result.Retagged++
+		}
+	}
+
+	for day := e.config.Storage.DayStart(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		result.Days++
+		if e.config.Issues.Enabled {
+			if _, err := e.CorrelateIssues(day); err != nil {
+				logger.GetLogger().Warnf("Failed to correlate issues for %s: %v", day.Format("2006-01-02"), err)
+			}
+		}
+		if e.config.Focus.Enabled {
+			if _, err := ComputeFocusScore(e.config, e.storage, day); err != nil {
+				logger.GetLogger().Warnf("Failed to compute focus score for %s: %v", day.Format("2006-01-02"), err)
+			}
+		}
+	}
+
+	// Shorter periods have no statistics sections
+	summaries, err := findOverlappingSummaries(e.storage, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if summary.PeriodType != "day" && !isWeekOrLonger(summary.PeriodType) {
+			continue
+		}
+		if err := e.savePeriodSummaryReport(summary); err != nil {
+			logger.GetLogger().Warnf("Failed to rewrite report of %s: %v", summary.PeriodKey, err)
+			continue
+		}
+		result.Reports++
+	}
+
+	details := fmt.Sprintf("range=%s..%s windows=%d retagged=%d days=%d reports=%d",
+		from.Format(time.RFC3339), to.Format(time.RFC3339), result.Windows, result.Retagged, result.Days, result.Reports)
+	if err := e.storage.AddAuditEntry(storage.NewAuditEntry("retag", details)); err != nil {
+		return result, fmt.Errorf("retagged but failed to write audit entry: %w", err)
+	}
+	logger.GetLogger().Infof("Retag completed: %s", details)
+	return result, nil
+}
+
+// retagWindow attributes a recorded window again with the current rules, returning the updated
+// record and whether it changed
+// The branch read from the repository is today's, so the recorded branch is kept when the title
+// doesn't name one and the repository is unchanged
+func retagWindow(resolver *projects.Resolver, w *storage.ScreenshotWindow) (*storage.ScreenshotWindow, bool) {
+	attribution, _ := resolver.Resolve(w.App, w.Title)
+	if hint, _ := projects.ParseTitle(w.App, w.Title); hint.Branch == "" && attribution.Repo == w.Repo {
+		attribution.Branch = w.Branch
+	}
+	if attribution.Repo == w.Repo && attribution.Subproject == w.Subproject && attribution.Branch == w.Branch {
+		return w, false
+	}
+
+	retagged := *w
+	retagged.Repo = attribution.Repo
+	retagged.Subproject = attribution.Subproject
+	retagged.Branch = attribution.Branch
+	return &retagged, true
+}
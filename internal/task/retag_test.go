@@ -0,0 +1,45 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stuff-time/internal/projects"
+	"stuff-time/internal/storage"
+)
+
+func TestRetagWindow(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"shop/.git", "shop/api", "payments/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range map[string]string{
+		"shop/api/go.mod":    "module api\n",
+		"shop/.git/HEAD":     "ref: refs/heads/main\n",
+		"payments/.git/HEAD": "ref: refs/heads/main\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolver := &projects.Resolver{Roots: []string{root}}
+
+	// Recorded app-only before the repository existed on this disk
+	added := &storage.ScreenshotWindow{App: "Code", Title: "main.go — " + filepath.Join(root, "shop/api") + " — Visual Studio Code"}
+	retagged, changed := retagWindow(resolver, added)
+	if !changed || retagged.Repo != "shop" || retagged.Subproject != "api" || retagged.Branch != "main" {
+		t.Errorf("retagWindow() = %+v, %v, want shop/api on main", retagged, changed)
+	}
+	if added.Repo != "" {
+		t.Errorf("retagWindow() should not modify the recorded window")
+	}
+
+	// The branch checked out today doesn't replace the one recorded at capture
+	recorded := &storage.ScreenshotWindow{App: "Code", Title: "main.go — payments — Visual Studio Code", Repo: "payments", Branch: "feature-x"}
+	if retagged, changed := retagWindow(resolver, recorded); changed {
+		t.Errorf("retagWindow() = %+v, want the recorded branch kept", retagged)
+	}
+}
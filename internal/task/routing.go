@@ -0,0 +1,61 @@
+package task
+
+import (
+	"fmt"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// newRoutedAnalyzers creates an analyzer per routing rule from the configured analyzer
+func newRoutedAnalyzers(cfg *config.Config, base *analyzer.OpenAI) (map[string]*analyzer.OpenAI, error) {
+	if len(cfg.Routing.Rules) == 0 {
+		return nil, nil
+	}
+	routed := make(map[string]*analyzer.OpenAI, len(cfg.Routing.Rules))
+	for _, rule := range cfg.Routing.Rules {
+		a, err := base.Route(rule.Model, rule.PromptContent, rule.ImageDetail)
+		if err != nil {
+			return nil, fmt.Errorf("invalid routing rule %s: %w", rule.Name, err)
+		}
+		routed[rule.Name] = a
+	}
+	return routed, nil
+}
+
+// recordRoute classifies the screenshot by its frontmost app and window title, so it is analyzed
+// by the model of the matching routing rule
+func (e *Executor) recordRoute(record *storage.ScreenshotRecord, app, title string) {
+	rule := e.config.Routing.Route(app, title)
+	if rule == nil {
+		return
+	}
+	route := &storage.ScreenshotRoute{
+		ImagePath: record.ImagePath,
+		Timestamp: record.Timestamp,
+		Route:     rule.Name,
+	}
+	if err := e.storage.SaveScreenshotRoute(route); err != nil {
+		logger.GetLogger().Warnf("Failed to save route of screenshot %s: %v", record.ID, err)
+	}
+}
+
+// screenshotAnalyzer returns the analyzer of the screenshot's route, the default analyzer for
+// screenshots without a route or whose rule was removed since the capture
+func (e *Executor) screenshotAnalyzer(record *storage.ScreenshotRecord) *analyzer.OpenAI {
+	if len(e.routedAnalyzers) == 0 {
+		return e.analyzer
+	}
+	route, err := e.storage.GetScreenshotRoute(record.ImagePath)
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get route of %s, using the default model: %v", record.ID, err)
+		return e.analyzer
+	}
+	if a, ok := e.routedAnalyzers[route]; ok {
+		logger.GetLogger().Debugf("Analyzing %s as %s with %s", record.ID, route, a.Model)
+		return a
+	}
+	return e.analyzer
+}
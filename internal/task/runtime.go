@@ -0,0 +1,23 @@
+package task
+
+import (
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+)
+
+// recordRuntimeState persists pipeline state shown by the status command; failures are only logged
+func (e *Executor) recordRuntimeState(key, value string) {
+	if err := e.storage.SaveRuntimeState(key, value); err != nil {
+		logger.GetLogger().Warnf("Failed to record runtime state %s: %v", key, err)
+	}
+}
+
+// setAnalyzing marks whether a batch analysis is running and publishes it as a daemon status flag
+func (e *Executor) setAnalyzing(analyzing bool) {
+	e.isAnalyzing = analyzing
+	status := "idle"
+	if analyzing {
+		status = "running"
+	}
+	events.SetStatus(events.StatusAnalysis, status)
+}
@@ -0,0 +1,106 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+// SampleSpec describes which screenshots are analyzed when rebuilding long ranges
+// Exactly one of Every / MaxPerFifteenMin is set
+type SampleSpec struct {
+	Every            int // Analyze every Nth screenshot
+	MaxPerFifteenMin int // Analyze at most N screenshots per fifteen-minute period
+}
+
+// ParseSampleSpec parses a --sample value: "3" (every 3rd screenshot) or "4/fifteenmin" (at most 4 per fifteen minutes)
+func ParseSampleSpec(value string) (SampleSpec, error) {
+	value = strings.TrimSpace(value)
+	countStr, per, hasPer := strings.Cut(value, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || n <= 0 {
+		return SampleSpec{}, fmt.Errorf("invalid sample %q: expected a positive number, e.g. 3 or 4/fifteenmin", value)
+	}
+	if !hasPer {
+		return SampleSpec{Every: n}, nil
+	}
+	if strings.TrimSpace(per) != "fifteenmin" {
+		return SampleSpec{}, fmt.Errorf("invalid sample %q: only /fifteenmin is supported", value)
+	}
+	return SampleSpec{MaxPerFifteenMin: n}, nil
+}
+
+func (s SampleSpec) String() string {
+	if s.MaxPerFifteenMin > 0 {
+		return fmt.Sprintf("at most %d per fifteen minutes", s.MaxPerFifteenMin)
+	}
+	return fmt.Sprintf("every %d screenshot(s)", s.Every)
+}
+
+// SampleScreenshots returns the screenshots left out by the sampling spec
+// Screenshots are sampled in chronological order; within a fifteen-minute period the kept ones are evenly spaced
+func SampleScreenshots(screenshots []*storage.ScreenshotRecord, spec SampleSpec) []*storage.ScreenshotRecord {
+	sorted := make([]*storage.ScreenshotRecord, len(screenshots))
+	copy(sorted, screenshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		}
+		return sorted[i].ScreenID < sorted[j].ScreenID
+	})
+
+	var sampledOut []*storage.ScreenshotRecord
+	if spec.MaxPerFifteenMin <= 0 {
+		if spec.Every <= 1 {
+			return nil
+		}
+		for i, s := range sorted {
+			if i%spec.Every != 0 {
+				sampledOut = append(sampledOut, s)
+			}
+		}
+		return sampledOut
+	}
+
+	for start := 0; start < len(sorted); {
+		window := sorted[start].Timestamp.Truncate(15 * time.Minute)
+		end := start
+		for end < len(sorted) && sorted[end].Timestamp.Truncate(15*time.Minute).Equal(window) {
+			end++
+		}
+
+		group := sorted[start:end]
+		if len(group) > spec.MaxPerFifteenMin {
+			kept := make(map[int]bool, spec.MaxPerFifteenMin)
+			for i := 0; i < spec.MaxPerFifteenMin; i++ {
+				kept[i*len(group)/spec.MaxPerFifteenMin] = true
+			}
+			for i, s := range group {
+				if !kept[i] {
+					sampledOut = append(sampledOut, s)
+				}
+			}
+		}
+		start = end
+	}
+	return sampledOut
+}
+
+// samplingNote describes the sampling rate of a period whose screenshots were partly sampled out during rebuild
+func samplingNote(screenshots []*storage.ScreenshotRecord) string {
+	sampledOut := 0
+	for _, s := range screenshots {
+		if s.AnalysisStatus == storage.AnalysisSampledOut {
+			sampledOut++
+		}
+	}
+	if sampledOut == 0 {
+		return ""
+	}
+	return fmt.Sprintf("> 采样说明：本时段共 %d 张截图，重建时按采样仅分析了其中 %d 张",
+		len(screenshots), len(screenshots)-sampledOut)
+}
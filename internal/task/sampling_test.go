@@ -0,0 +1,51 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestParseSampleSpec(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SampleSpec
+		wantErr bool
+	}{
+		{"3", SampleSpec{Every: 3}, false},
+		{"4/fifteenmin", SampleSpec{MaxPerFifteenMin: 4}, false},
+		{"0", SampleSpec{}, true},
+		{"abc", SampleSpec{}, true},
+		{"4/hour", SampleSpec{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSampleSpec(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSampleSpec(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSampleSpec(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSampleScreenshots(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	var screenshots []*storage.ScreenshotRecord
+	// 30 screenshots one minute apart: 15 in each fifteen-minute period
+	for i := 0; i < 30; i++ {
+		screenshots = append(screenshots, &storage.ScreenshotRecord{ID: string(rune('a' + i)), Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	if got := len(SampleScreenshots(screenshots, SampleSpec{Every: 3})); got != 20 {
+		t.Errorf("every 3rd: sampled out %d, want 20", got)
+	}
+	if got := len(SampleScreenshots(screenshots, SampleSpec{MaxPerFifteenMin: 4})); got != 22 {
+		t.Errorf("4 per fifteenmin: sampled out %d, want 22", got)
+	}
+	if got := len(SampleScreenshots(screenshots, SampleSpec{MaxPerFifteenMin: 20})); got != 0 {
+		t.Errorf("20 per fifteenmin: sampled out %d, want 0", got)
+	}
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/logger"
+)
+
+// executiveSummaryLabel starts the executive summary of a day summary split into themed sections
+const executiveSummaryLabel = "【执行摘要】"
+
+// splitOversizedSummary restructures a day summary longer than summary.split_day_chars into themed
+// sections below a short executive summary, so week aggregation can use the executive summary only
+// The summary is kept as is when splitting is disabled or fails
+func (e *Executor) splitOversizedSummary(periodKey, summary string) string {
+	limit := e.config.Summary.SplitDayChars
+	if limit <= 0 || utf8.RuneCountInString(summary) <= limit || ExecutiveSummary(summary) != "" {
+		return summary
+	}
+	prompt := e.config.OpenAI.SummarySectionsContent
+	if prompt == "" {
+		logger.GetLogger().Warnf("Not splitting oversized summary of %s: sections.txt prompt not found", periodKey)
+		return summary
+	}
+
+	split, err := e.analyzer.SplitSummary(prompt, summary)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to split oversized summary of %s: %v", periodKey, err)
+		return summary
+	}
+	if ExecutiveSummary(split) == "" {
+		logger.GetLogger().Warnf("Not splitting oversized summary of %s: response has no %s", periodKey, executiveSummaryLabel)
+		return summary
+	}
+	logger.GetLogger().Infof("Split oversized summary of %s (%d chars) into themed sections", periodKey, utf8.RuneCountInString(summary))
+	return strings.TrimSpace(split)
+}
+
+// ExecutiveSummary returns the executive summary of a summary split into themed sections, from its
+// label up to the next 【…】 section, or "" when the summary was not split
+func ExecutiveSummary(summary string) string {
+	_, rest, found := strings.Cut(summary, executiveSummaryLabel)
+	if !found {
+		return ""
+	}
+	lines := strings.Split(rest, "\n")
+	for i, line := range lines {
+		if i > 0 && strings.HasPrefix(strings.TrimSpace(line), "【") {
+			lines = lines[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// aggregationSummary returns the text of a lower-level summary used as aggregation input: week
+// summaries read only the executive summary of day summaries split into themed sections
+// Text before the executive summary (e.g. the locked label) is kept. Citation tags are only passed
+// on to levels that cite
+func aggregationSummary(periodType, summary string) string {
+	if !analyzer.CitedPeriodTypes[periodType] {
+		summary = stripCitations(summary)
+	}
+	i := strings.Index(summary, executiveSummaryLabel)
+	if periodType != "week" || i < 0 {
+		return summary
+	}
+	return summary[:i] + executiveSummaryLabel + "\n" + ExecutiveSummary(summary)
+}
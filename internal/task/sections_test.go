@@ -0,0 +1,28 @@
+package task
+
+import "testing"
+
+func TestAggregationSummary(t *testing.T) {
+	split := "【执行摘要】\n- 完成导出功能\n- 评审 PR\n\n【主题：导出功能】\n编写 bundle.go [1]\n\n【主题：代码评审】\n评审 PR #12 [2]"
+
+	tests := []struct {
+		name       string
+		periodType string
+		summary    string
+		want       string
+	}{
+		{"week reads executive summary", "week", split, "【执行摘要】\n- 完成导出功能\n- 评审 PR"},
+		{"locked label kept", "week", lockedSummaryLabel + "\n" + split, lockedSummaryLabel + "\n【执行摘要】\n- 完成导出功能\n- 评审 PR"},
+		{"not split", "week", "【工作记录】\n编写 bundle.go", "【工作记录】\n编写 bundle.go"},
+		{"other levels read everything", "month", split, split},
+		{"citations dropped for levels that don't cite", "week", "编写 bundle.go[#14:03:20]", "编写 bundle.go"},
+		{"citations kept for levels that cite", "day", "编写 bundle.go[#14:03:20]", "编写 bundle.go[#14:03:20]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregationSummary(tt.periodType, tt.summary); got != tt.want {
+				t.Errorf("aggregationSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
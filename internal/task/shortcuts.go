@@ -0,0 +1,100 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// PauseCapture pauses screenshot capture until the given time
+// The daemon reads the pause from the database before each capture, so it takes effect without a restart
+func PauseCapture(st *storage.Storage, until time.Time) error {
+	return st.SaveRuntimeState(storage.RuntimePausedUntil, until.Format(time.RFC3339))
+}
+
+// ResumeCapture ends a pause started with PauseCapture
+func ResumeCapture(st *storage.Storage) error {
+	return st.SaveRuntimeState(storage.RuntimePausedUntil, "")
+}
+
+// CapturePausedUntil returns the end of the current capture pause, or the zero time when capture is not paused
+func CapturePausedUntil(st *storage.Storage, now time.Time) (time.Time, error) {
+	states, err := st.GetRuntimeStates()
+	if err != nil {
+		return time.Time{}, err
+	}
+	state := states[storage.RuntimePausedUntil]
+	if state == nil || state.Value == "" {
+		return time.Time{}, nil
+	}
+	until, err := time.Parse(time.RFC3339, state.Value)
+	if err != nil || !until.After(now) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// capturePausedUntil is CapturePausedUntil for the daemon; a failed lookup does not stop capturing
+func (e *Executor) capturePausedUntil(now time.Time) time.Time {
+	until, err := CapturePausedUntil(e.storage, now)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to check capture pause: %v", err)
+	}
+	return until
+}
+
+// Snap captures a screenshot right away and stars it with note, so it shows up in the report highlights
+// Work hours, per-app intervals and a capture pause do not apply; the daemon analyzes it with the next batch
+func Snap(cfg *config.Config, st *storage.Storage, note string) (*storage.ScreenshotRecord, error) {
+	if locked, err := screenshot.IsScreenLocked(); err == nil && locked {
+		return nil, fmt.Errorf("screen is locked")
+	}
+	images, err := NewImageStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store: %w", err)
+	}
+	e := &Executor{config: cfg, storage: st, images: images}
+
+	record, err := e.captureScreen(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := st.StarScreenshot(record.ID, note); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// TodaySummary returns today's work summary as plain text: the day summary once it exists, the
+// hour summaries so far before that, or a note when nothing was summarized yet
+func TodaySummary(cfg *config.Config, st *storage.Storage, now time.Time) (string, error) {
+	day := cfg.Storage.DayStart(now)
+	summary, err := st.GetPeriodSummary(storage.FormatPeriodKey("day", day))
+	if err != nil {
+		return "", fmt.Errorf("failed to get day summary: %w", err)
+	}
+	if summary != nil && hasValidContent(&cfg.Summary, summary) {
+		return strings.TrimSpace(summary.Summary), nil
+	}
+
+	hours, err := st.QueryPeriodSummaries("hour", day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return "", fmt.Errorf("failed to query hour summaries: %w", err)
+	}
+	var parts []string
+	for _, hour := range hours {
+		if hasValidContent(&cfg.Summary, hour) && hour.Summary != "" {
+			parts = append(parts, fmt.Sprintf("%s-%s\n%s", datefmt.TimeMinute(hour.StartTime), datefmt.TimeMinute(hour.EndTime), strings.TrimSpace(hour.Summary)))
+		}
+	}
+	if len(parts) == 0 {
+		return "今天还没有生成总结", nil
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestPauseCapture(t *testing.T) {
+	st := newTestStorage(t)
+
+	now := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	if err := PauseCapture(st, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if until, err := CapturePausedUntil(st, now); err != nil || !until.Equal(now.Add(time.Hour)) {
+		t.Errorf("CapturePausedUntil() = %v, %v, want %v", until, err, now.Add(time.Hour))
+	}
+	if until, _ := CapturePausedUntil(st, now.Add(2*time.Hour)); !until.IsZero() {
+		t.Errorf("expired pause still active until %v", until)
+	}
+	if err := ResumeCapture(st); err != nil {
+		t.Fatal(err)
+	}
+	if until, _ := CapturePausedUntil(st, now); !until.IsZero() {
+		t.Errorf("resumed capture still paused until %v", until)
+	}
+}
+
+func TestTodaySummary(t *testing.T) {
+	st := newTestStorage(t)
+	cfg := &config.Config{}
+
+	now := time.Date(2025, 12, 9, 16, 0, 0, 0, time.Local)
+	if text, err := TodaySummary(cfg, st, now); err != nil || text != "今天还没有生成总结" {
+		t.Errorf("TodaySummary() without summaries = %q, %v", text, err)
+	}
+
+	hour := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	for _, s := range []*storage.PeriodSummary{
+		{PeriodKey: storage.FormatPeriodKey("hour", hour), PeriodType: "hour", StartTime: hour, EndTime: hour.Add(time.Hour), Summary: "评审导出功能的 PR"},
+		{PeriodKey: storage.FormatPeriodKey("hour", hour.Add(time.Hour)), PeriodType: "hour", StartTime: hour.Add(time.Hour), EndTime: hour.Add(2 * time.Hour), Summary: "__NO_WORK_ACTIVITY_PLACEHOLDER__"},
+	} {
+		if err := st.SavePeriodSummary(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if text, _ := TodaySummary(cfg, st, now); !strings.Contains(text, "评审导出功能的 PR") || strings.Contains(text, "PLACEHOLDER") {
+		t.Errorf("TodaySummary() from hours = %q", text)
+	}
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	if err := st.SavePeriodSummary(&storage.PeriodSummary{PeriodKey: storage.FormatPeriodKey("day", day), PeriodType: "day",
+		StartTime: day, EndTime: day.AddDate(0, 0, 1), Summary: "完成导出功能并评审 PR"}); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := TodaySummary(cfg, st, now); text != "完成导出功能并评审 PR" {
+		t.Errorf("TodaySummary() = %q, want the day summary", text)
+	}
+}
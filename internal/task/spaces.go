@@ -0,0 +1,126 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/screenshot"
+	"stuff-time/internal/storage"
+)
+
+// SpaceTime is the time captured in a macOS Space (virtual desktop)
+type SpaceTime struct {
+	Space       int
+	Label       string // spaces.names entry, "Space N" without one
+	Screenshots int
+	Minutes     float64
+}
+
+// skipForSpace reports whether this capture is skipped because the active Space is listed in spaces.exclude
+func (e *Executor) skipForSpace() bool {
+	if len(e.config.Spaces.Exclude) == 0 {
+		return false
+	}
+	space, err := screenshot.CurrentSpace()
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get current Space: %v", err)
+		return false
+	}
+	if space == 0 || !e.config.Spaces.IsExcluded(space) {
+		return false
+	}
+	logger.GetLogger().Debugf("%s is excluded, skipping screenshot capture", e.config.Spaces.Label(space))
+	events.Emit(events.LevelDebug, events.ComponentCapture, "Excluded Space in front, capture skipped")
+	return true
+}
+
+// recordSpace saves the active Space of a screenshot when spaces.enabled is set
+// Full-screen apps have no desktop number and are not recorded
+func (e *Executor) recordSpace(record *storage.ScreenshotRecord) {
+	if !e.config.Spaces.Enabled {
+		return
+	}
+	space, err := screenshot.CurrentSpace()
+	if err != nil {
+		logger.GetLogger().Debugf("Failed to get current Space: %v", err)
+		return
+	}
+	if space == 0 {
+		return
+	}
+	if err := e.storage.SaveScreenshotSpace(&storage.ScreenshotSpace{ScreenshotID: record.ID, Timestamp: record.Timestamp, Space: space}); err != nil {
+		logger.GetLogger().Warnf("Failed to save Space of screenshot %s: %v", record.ID, err)
+	}
+}
+
+// screenshotSpaceLabels returns the Space label of each screenshot captured in [start, end), by screenshot ID
+func screenshotSpaceLabels(cfg *config.Config, st *storage.Storage, start, end time.Time) (map[string]string, error) {
+	spaces, err := st.QueryScreenshotSpaces(start, end)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string, len(spaces))
+	for _, s := range spaces {
+		labels[s.ScreenshotID] = cfg.Spaces.Label(s.Space)
+	}
+	return labels, nil
+}
+
+// AggregateSpaceTime aggregates the captured time per Space in [start, end)
+// Each screenshot with a recorded Space counts as one capture interval
+func AggregateSpaceTime(cfg *config.Config, st *storage.Storage, start, end time.Time, interval time.Duration) ([]*SpaceTime, error) {
+	spaces, err := st.QueryScreenshotSpaces(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	bySpace := make(map[int]*SpaceTime)
+	for _, s := range spaces {
+		t, ok := bySpace[s.Space]
+		if !ok {
+			t = &SpaceTime{Space: s.Space, Label: cfg.Spaces.Label(s.Space)}
+			bySpace[s.Space] = t
+		}
+		t.Screenshots++
+		t.Minutes += interval.Minutes()
+	}
+
+	result := make([]*SpaceTime, 0, len(bySpace))
+	for _, t := range bySpace {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Minutes != result[j].Minutes {
+			return result[i].Minutes > result[j].Minutes
+		}
+		return result[i].Space < result[j].Space
+	})
+	return result, nil
+}
+
+// formatSpacesSection renders the time per Space of a period as a markdown section
+// Returns an empty string if no Spaces were recorded
+func formatSpacesSection(spaceTimes []*SpaceTime) string {
+	if len(spaceTimes) == 0 {
+		return ""
+	}
+	var total float64
+	for _, t := range spaceTimes {
+		total += t.Minutes
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 桌面空间\n\n")
+	sb.WriteString("| 桌面 | 截图数 | 时长 | 占比 |\n")
+	sb.WriteString("|------|--------|------|------|\n")
+	for _, t := range spaceTimes {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %.0f%% |\n", t.Label, t.Screenshots, formatMinutes(t.Minutes), 100*t.Minutes/total))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,419 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/threads"
+	"stuff-time/internal/timeline"
+)
+
+// otherThreadName collects threads too short to stand on their own
+const otherThreadName = "其他"
+
+// maxEmbeddingRunes caps the analysis text sent for embedding; the first lines carry the activity
+const maxEmbeddingRunes = 2000
+
+// DetectThreads clusters the day's analyzed work screenshots into task threads by the similarity
+// of their analysis embeddings, names them and replaces the stored threads of the day
+// Embeddings are cached per screenshot, so re-running a day only embeds new analyses
+func (e *Executor) DetectThreads(day time.Time) ([]*storage.TaskThread, error) {
+	cfg := e.config.Threads
+	dayStart := e.config.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	dayKey := dayStart.Format("2006-01-02")
+
+	screenshots, err := e.storage.QueryByDateRange(dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	var analyzed []*storage.ScreenshotRecord
+	for _, s := range e.workScreenshots(screenshots) {
+		if s.HasAnalysis() && !isDesktopOrLockScreenAnalysis(s.Analysis) {
+			analyzed = append(analyzed, s)
+		}
+	}
+	if len(analyzed) == 0 {
+		return nil, e.storage.ReplaceDayThreads(dayKey, nil)
+	}
+
+	vectors, err := e.analysisEmbeddings(cfg.EmbeddingModel, analyzed)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*threads.Item, 0, len(analyzed))
+	for _, s := range analyzed {
+		items = append(items, &threads.Item{ID: s.ID, Time: s.Timestamp, Text: firstAnalysisLine(s.Analysis), Vector: vectors[s.ID]})
+	}
+
+	similarity := cfg.Similarity
+	if similarity <= 0 {
+		similarity = 0.8
+	}
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	clusters := threads.Cluster(items, similarity)
+
+	// Threads shorter than min_minutes are merged into a single "other" thread
+	minMinutes := float64(cfg.MinMinutes)
+	var kept []*threads.Thread
+	var other []*threads.Item
+	for _, c := range clusters {
+		if float64(len(c.Items))*interval.Minutes() < minMinutes {
+			other = append(other, c.Items...)
+			continue
+		}
+		kept = append(kept, c)
+	}
+
+	// Threads matching an unfinished thread of the previous day continue it under its name
+	continued := e.matchOpenThreads(dayStart, kept, similarity)
+	var unnamed []*threads.Thread
+	for i, c := range kept {
+		if continued[i] == nil {
+			unnamed = append(unnamed, c)
+		}
+	}
+	names := e.nameThreads(unnamed)
+
+	var lastWork time.Time
+	for _, s := range analyzed {
+		if s.Timestamp.After(lastWork) {
+			lastWork = s.Timestamp
+		}
+	}
+	openSince := lastWork.Add(-cfg.GetOpenWindowDuration())
+
+	var result []*storage.TaskThread
+	for i, c := range kept {
+		var thread *storage.TaskThread
+		if prev := continued[i]; prev != nil {
+			thread = newTaskThread(dayKey, prev.Name, c.Items, interval)
+			thread.StartedOn = prev.StartedOn
+		} else {
+			thread = newTaskThread(dayKey, names[0], c.Items, interval)
+			names = names[1:]
+		}
+		thread.Centroid = c.Centroid()
+		thread.Open = !thread.LastSeen.Before(openSince)
+		result = append(result, thread)
+	}
+	if len(other) > 0 {
+		result = append(result, newTaskThread(dayKey, otherThreadName, other, interval))
+	}
+
+	if err := e.storage.ReplaceDayThreads(dayKey, result); err != nil {
+		return nil, err
+	}
+	logger.GetLogger().Infof("Detected %d task thread(s) for %s", len(result), dayKey)
+	return result, nil
+}
+
+// maxCarryoverDays limits how far back the previous working day's open threads are carried over
+const maxCarryoverDays = 7
+
+// previousOpenThreads returns the unfinished threads of the latest working day before day
+func (e *Executor) previousOpenThreads(dayStart time.Time) ([]*storage.TaskThread, error) {
+	open, err := e.storage.QueryOpenThreads(dayStart.Format("2006-01-02"))
+	if err != nil || len(open) == 0 {
+		return nil, err
+	}
+	prevDay, err := time.ParseInLocation("2006-01-02", open[0].DayKey, dayStart.Location())
+	if err != nil || dayStart.Sub(prevDay) > maxCarryoverDays*24*time.Hour {
+		return nil, nil
+	}
+	return open, nil
+}
+
+// matchOpenThreads returns, per cluster, the unfinished thread of the previous working day it
+// continues (nil for a new thread); each previous thread is continued at most once
+func (e *Executor) matchOpenThreads(dayStart time.Time, clusters []*threads.Thread, similarity float64) []*storage.TaskThread {
+	continued := make([]*storage.TaskThread, len(clusters))
+	open, err := e.previousOpenThreads(dayStart)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query unfinished task threads: %v", err)
+		return continued
+	}
+	used := make(map[string]bool)
+	for i, c := range clusters {
+		bestSim := similarity
+		for _, prev := range open {
+			if used[prev.ID] {
+				continue
+			}
+			if sim := threads.Cosine(c.Centroid(), prev.Centroid); sim >= bestSim {
+				continued[i], bestSim = prev, sim
+			}
+		}
+		if continued[i] != nil {
+			used[continued[i].ID] = true
+		}
+	}
+	return continued
+}
+
+// threadCarryover returns the unfinished threads of the previous working day as summary context,
+// for the day summary and the screenshot-level summaries of the first hours of the day
+func (e *Executor) threadCarryover(periodType string, startTime, endTime time.Time) string {
+	window := e.config.Threads.GetCarryoverDuration()
+	if window == 0 || (periodType != "day" && e.getLowerLevelPeriodType(periodType) != "") {
+		return ""
+	}
+	dayStart := e.config.Storage.DayStart(startTime)
+	open, err := e.previousOpenThreads(dayStart)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to query unfinished task threads: %v", err)
+		return ""
+	}
+	if len(open) == 0 {
+		return ""
+	}
+
+	if periodType != "day" {
+		screenshots, err := e.storage.QueryByDateRange(dayStart, endTime)
+		if err != nil {
+			logger.GetLogger().Warnf("Failed to query screenshots for task thread carryover: %v", err)
+			return ""
+		}
+		screenshots = e.workScreenshots(screenshots)
+		if len(screenshots) == 0 || startTime.Sub(screenshots[0].Timestamp) >= window {
+			return ""
+		}
+	}
+
+	var sb strings.Builder
+	for _, t := range open {
+		started := t.StartedOn
+		if day, err := time.ParseInLocation("2006-01-02", t.StartedOn, dayStart.Location()); err == nil {
+			started = day.Format("2006-01-02 Mon")
+		}
+		sb.WriteString(fmt.Sprintf("- %s（始于 %s，%s 当天 %s）\n", t.Name, started, t.DayKey, formatMinutes(t.Minutes)))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// withThreadCarryover appends the previous working day's unfinished threads to the summary input
+func withThreadCarryover(text, carryover string) string {
+	if carryover == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n前一个工作日未完成的任务线程（如果上面的记录是在继续这些工作，请衔接叙述，如\"继续周一开始的迁移\"；无关则忽略）：\n%s", text, carryover)
+}
+
+// analysisEmbeddings returns the embeddings of the screenshots' analyses, embedding and storing
+// the ones not computed with model yet
+func (e *Executor) analysisEmbeddings(model string, screenshots []*storage.ScreenshotRecord) (map[string][]float64, error) {
+	ids := make([]string, 0, len(screenshots))
+	for _, s := range screenshots {
+		ids = append(ids, s.ID)
+	}
+	vectors, err := e.storage.GetEmbeddings(model, ids)
+	if err != nil {
+		return nil, err
+	}
+	if vectors == nil {
+		vectors = make(map[string][]float64)
+	}
+
+	var missing []*storage.ScreenshotRecord
+	var texts []string
+	for _, s := range screenshots {
+		if _, ok := vectors[s.ID]; ok {
+			continue
+		}
+		text := []rune(s.Analysis)
+		if len(text) > maxEmbeddingRunes {
+			text = text[:maxEmbeddingRunes]
+		}
+		missing = append(missing, s)
+		texts = append(texts, string(text))
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := e.analyzer.Embed(model, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed analyses: %w", err)
+	}
+	computed := make(map[string][]float64, len(missing))
+	for i, s := range missing {
+		if i < len(embedded) && len(embedded[i]) > 0 {
+			computed[s.ID] = embedded[i]
+			vectors[s.ID] = embedded[i]
+		}
+	}
+	if err := e.storage.SaveEmbeddings(model, computed); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// nameThreads asks the model for a short name per thread, falling back to the most
+// representative analysis line of a thread when naming fails or returns too few names
+func (e *Executor) nameThreads(clusters []*threads.Thread) []string {
+	names := make([]string, len(clusters))
+	for i, c := range clusters {
+		if texts := c.Representative(1); len(texts) > 0 {
+			names[i] = shortenThreadName(texts[0])
+		} else {
+			names[i] = fmt.Sprintf("线程 %d", i+1)
+		}
+	}
+	if len(clusters) == 0 || e.config.Threads.PromptContent == "" {
+		return names
+	}
+
+	var sb strings.Builder
+	for i, c := range clusters {
+		sb.WriteString(fmt.Sprintf("线程 %d（%d 张截图）：\n", i+1, len(c.Items)))
+		for _, text := range c.Representative(5) {
+			sb.WriteString(fmt.Sprintf("- %s\n", text))
+		}
+		sb.WriteString("\n")
+	}
+	raw, err := e.analyzer.NameThreads(e.config.Threads.PromptContent, sb.String())
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to name task threads: %v", err)
+		return names
+	}
+	parsed, err := parseThreadNames(raw)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to parse task thread names: %v", err)
+		return names
+	}
+	for i := range names {
+		if i < len(parsed) && parsed[i] != "" {
+			names[i] = parsed[i]
+		}
+	}
+	return names
+}
+
+// parseThreadNames parses the model output into thread names
+func parseThreadNames(raw string) ([]string, error) {
+	var names []string
+	if err := unmarshalJSONArray(raw, &names); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal thread names: %w", err)
+	}
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names, nil
+}
+
+func newTaskThread(dayKey, name string, items []*threads.Item, interval time.Duration) *storage.TaskThread {
+	thread := &storage.TaskThread{
+		DayKey:  dayKey,
+		Name:    name,
+		Minutes: float64(len(items)) * interval.Minutes(),
+	}
+	for _, item := range items {
+		if thread.FirstSeen.IsZero() || item.Time.Before(thread.FirstSeen) {
+			thread.FirstSeen = item.Time
+		}
+		if item.Time.After(thread.LastSeen) {
+			thread.LastSeen = item.Time
+		}
+		thread.ScreenshotIDs = append(thread.ScreenshotIDs, item.ID)
+	}
+	return thread
+}
+
+// firstAnalysisLine returns the first non-empty line of an analysis without markdown list markers
+func firstAnalysisLine(analysis string) string {
+	for _, line := range strings.Split(analysis, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*#>"))
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// shortenThreadName cuts a fallback thread name to a heading-sized length
+func shortenThreadName(text string) string {
+	runes := []rune(text)
+	if len(runes) > 30 {
+		return string(runes[:30]) + "…"
+	}
+	return text
+}
+
+// formatThreadsSection renders the day's task threads with their duration, time spans and a few
+// representative activities; screenshots provides the member timestamps and analyses
+// A gap of more than two capture intervals (time spent on other threads) splits a span
+func formatThreadsSection(taskThreads []*storage.TaskThread, screenshots []*storage.ScreenshotRecord, interval time.Duration) string {
+	if len(taskThreads) == 0 {
+		return ""
+	}
+	byID := make(map[string]*storage.ScreenshotRecord, len(screenshots))
+	for _, s := range screenshots {
+		byID[s.ID] = s
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 任务线程\n\n")
+	for _, t := range taskThreads {
+		heading := formatMinutes(t.Minutes)
+		if t.StartedOn != "" && t.StartedOn != t.DayKey {
+			heading += "，始于 " + t.StartedOn
+		}
+		if t.Open && t.Name != otherThreadName {
+			heading += "，未完成"
+		}
+		sb.WriteString(fmt.Sprintf("### %s（%s）\n\n", t.Name, heading))
+
+		var points []timeline.Point
+		var lines []string
+		seen := make(map[string]bool)
+		for _, id := range t.ScreenshotIDs {
+			s := byID[id]
+			if s == nil {
+				continue
+			}
+			points = append(points, timeline.Point{Time: s.Timestamp, ScreenshotID: s.ID})
+			if line := firstAnalysisLine(s.Analysis); line != "" && !seen[line] && len(lines) < 3 {
+				seen[line] = true
+				lines = append(lines, line)
+			}
+		}
+
+		var spans []string
+		for _, seg := range timeline.Build(points, interval, 2*interval) {
+			spans = append(spans, fmt.Sprintf("%s-%s", seg.Start.Format("15:04"), seg.End.Format("15:04")))
+		}
+		if len(spans) > 0 {
+			sb.WriteString(fmt.Sprintf("时段：%s\n\n", strings.Join(spans, "、")))
+		}
+		for _, line := range lines {
+			sb.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		if len(lines) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// threadsSection renders the stored task threads of a day summary
+func (e *Executor) threadsSection(summary *storage.PeriodSummary) (string, error) {
+	taskThreads, err := e.storage.QueryDayThreads(summary.StartTime.Format("2006-01-02"))
+	if err != nil || len(taskThreads) == 0 {
+		return "", err
+	}
+	screenshots, err := e.storage.QueryByDateRange(summary.StartTime, summary.EndTime)
+	if err != nil {
+		return "", fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	interval, err := e.config.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	return formatThreadsSection(taskThreads, screenshots, interval), nil
+}
@@ -0,0 +1,143 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestDetectThreadsWithCachedEmbeddings(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Screenshot.Interval = "1m"
+	cfg.Threads = config.ThreadsConfig{Enabled: true, EmbeddingModel: "test", Similarity: 0.8, MinMinutes: 3}
+	e, st := newTestExecutor(t, cfg)
+
+	day := time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)
+	base := day.Add(9 * time.Hour)
+	review := []float64{1, 0, 0}
+	okr := []float64{0, 1, 0}
+	chat := []float64{0, 0, 1}
+	// Review is interrupted by the OKR doc and resumed; the single chat screenshot is too short
+	vectors := [][]float64{review, review, okr, okr, okr, review, review, chat}
+	embeddings := make(map[string][]float64)
+	for i, v := range vectors {
+		id := fmt.Sprintf("s%d", i)
+		text := "审阅 PR #412"
+		switch {
+		case v[1] == 1:
+			text = "编辑 OKR 文档"
+		case v[2] == 1:
+			text = "回复消息"
+		}
+		if err := st.SaveScreenshot(&storage.ScreenshotRecord{ID: id, Timestamp: base.Add(time.Duration(i) * time.Minute), Analysis: text}); err != nil {
+			t.Fatal(err)
+		}
+		embeddings[id] = v
+	}
+	if err := st.SaveEmbeddings("test", embeddings); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.DetectThreads(day); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := st.QueryDayThreads("2025-12-09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("got %d threads, want 3", len(stored))
+	}
+	if stored[0].Name != "审阅 PR #412" || stored[0].Minutes != 4 || len(stored[0].ScreenshotIDs) != 4 {
+		t.Errorf("first thread = %+v, want the 4 minute review", stored[0])
+	}
+	if stored[2].Name != otherThreadName || len(stored[2].ScreenshotIDs) != 1 {
+		t.Errorf("last thread = %+v, want the short chat merged into %s", stored[2], otherThreadName)
+	}
+
+	screenshots, err := st.QueryByDateRange(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	section := formatThreadsSection(stored, screenshots, time.Minute)
+	for _, want := range []string{"## 任务线程", "### 审阅 PR #412（4m，未完成）", "时段：09:00-09:02、09:05-09:07"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section missing %q:\n%s", want, section)
+		}
+	}
+}
+
+func TestParseThreadNames(t *testing.T) {
+	names, err := parseThreadNames("```json\n[\" 审阅 PR #412 \", \"编辑 OKR 文档\"]\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "审阅 PR #412" {
+		t.Errorf("names = %q", names)
+	}
+	if _, err := parseThreadNames("无法命名"); err == nil {
+		t.Error("expected an error without a JSON array")
+	}
+}
+
+func TestThreadCarryover(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Screenshot.Interval = "1m"
+	cfg.Threads = config.ThreadsConfig{Enabled: true, EmbeddingModel: "test", Similarity: 0.8, MinMinutes: 1}
+	e, st := newTestExecutor(t, cfg)
+
+	migration := []float64{1, 0}
+	docs := []float64{0, 1}
+	monday := time.Date(2025, 12, 8, 0, 0, 0, 0, time.Local)
+	tuesday := monday.AddDate(0, 0, 1)
+	embeddings := make(map[string][]float64)
+	save := func(id string, at time.Time, analysis string, vector []float64) {
+		if err := st.SaveScreenshot(&storage.ScreenshotRecord{ID: id, Timestamp: at, Analysis: analysis}); err != nil {
+			t.Fatal(err)
+		}
+		embeddings[id] = vector
+	}
+	// Monday: docs in the morning, the migration runs until the evening
+	save("m1", monday.Add(9*time.Hour), "编写部署文档", docs)
+	save("m2", monday.Add(16*time.Hour), "迁移订单服务到新集群", migration)
+	save("m3", monday.Add(18*time.Hour), "迁移订单服务到新集群", migration)
+	// Tuesday morning continues the migration
+	save("t1", tuesday.Add(9*time.Hour), "修复迁移脚本", migration)
+	if err := st.SaveEmbeddings("test", embeddings); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.DetectThreads(monday); err != nil {
+		t.Fatal(err)
+	}
+	open, err := st.QueryOpenThreads("2025-12-09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open[0].Name != "迁移订单服务到新集群" || len(open[0].Centroid) != 2 {
+		t.Fatalf("open threads = %+v, want only the migration", open)
+	}
+
+	carryover := e.threadCarryover("fifteenmin", tuesday.Add(9*time.Hour), tuesday.Add(9*time.Hour+15*time.Minute))
+	if !strings.Contains(carryover, "迁移订单服务到新集群（始于 2025-12-08 Mon") {
+		t.Errorf("carryover = %q", carryover)
+	}
+	if got := e.threadCarryover("fifteenmin", tuesday.Add(14*time.Hour), tuesday.Add(14*time.Hour+15*time.Minute)); got != "" {
+		t.Errorf("carryover in the afternoon = %q, want none", got)
+	}
+	if got := e.threadCarryover("hour", tuesday.Add(9*time.Hour), tuesday.Add(10*time.Hour)); got != "" {
+		t.Errorf("carryover for hour = %q, want none", got)
+	}
+
+	tuesdayThreads, err := e.DetectThreads(tuesday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tuesdayThreads) != 1 || tuesdayThreads[0].Name != "迁移订单服务到新集群" || tuesdayThreads[0].StartedOn != "2025-12-08" {
+		t.Errorf("tuesday threads = %+v, want the migration continued from monday", tuesdayThreads)
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/focus"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/timeline"
+)
+
+// DayTimeline returns the activity segments of a day
+// The app comes from the recorded frontmost window (IDE/terminal only unless screenshot.record_apps is set),
+// the category from the screenshot analysis and the Space from spaces.enabled captures
+func DayTimeline(cfg *config.Config, st *storage.Storage, day time.Time) ([]*timeline.Segment, error) {
+	dayStart := cfg.Storage.DateStart(day)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	screenshots, err := st.QueryByDateRange(dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	sort.Slice(screenshots, func(i, j int) bool {
+		return screenshots[i].Timestamp.Before(screenshots[j].Timestamp)
+	})
+
+	windows, err := st.QueryScreenshotWindows(dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+	}
+	apps := make(map[string]string, len(windows))
+	for _, w := range windows {
+		apps[w.ScreenshotID] = w.App
+	}
+	spaces, err := screenshotSpaceLabels(cfg, st, dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot spaces: %w", err)
+	}
+
+	points := make([]timeline.Point, 0, len(screenshots))
+	for _, s := range screenshots {
+		category := timeline.CategoryUnknown
+		switch {
+		case s.AnalysisStatus == storage.AnalysisSkipped:
+			category = focus.CategoryIdle
+		case s.HasAnalysis():
+			category = focus.Classify(s.Analysis)
+		}
+		points = append(points, timeline.Point{
+			Time:         s.Timestamp,
+			App:          apps[s.ID],
+			Space:        spaces[s.ID],
+			Category:     category,
+			ScreenshotID: s.ID,
+		})
+	}
+
+	interval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	return timeline.Build(points, interval, 2*interval), nil
+}
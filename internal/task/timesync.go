@@ -0,0 +1,131 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+	"stuff-time/internal/timeline"
+	"stuff-time/internal/timesync"
+)
+
+// TimeSyncResult is the outcome of syncing one work session
+type TimeSyncResult struct {
+	Entry         *timesync.Entry
+	ExternalID    string // Time entry ID in the tracker (new or from an earlier sync)
+	AlreadySynced bool
+	Err           error
+}
+
+// ProjectSessions detects work sessions per project (repository, or repository/sub-project) in [start, end)
+// from the windows recorded at capture time. Short sessions and sessions that may still be going on are left out
+func ProjectSessions(cfg *config.Config, st *storage.Storage, start, end time.Time) ([]*timesync.Entry, error) {
+	windows, err := st.QueryScreenshotWindows(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshot windows: %w", err)
+	}
+
+	points := make([]timeline.Point, 0, len(windows))
+	for _, w := range windows {
+		if w.Repo == "" {
+			continue
+		}
+		project := w.Repo
+		if w.Subproject != "" {
+			project = w.Repo + "/" + w.Subproject
+		}
+		points = append(points, timeline.Point{Time: w.Timestamp, App: project, ScreenshotID: w.ScreenshotID})
+	}
+
+	interval, err := cfg.Screenshot.GetIntervalDuration()
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	maxGap := cfg.TimeSync.GetMaxGapDuration()
+	minMinutes := cfg.TimeSync.MinMinutes
+	if minMinutes <= 0 {
+		minMinutes = 5
+	}
+
+	now := time.Now()
+	var entries []*timesync.Entry
+	for _, segment := range timeline.Build(points, interval, maxGap) {
+		if segment.Minutes < minMinutes {
+			continue
+		}
+		// The session may still grow, sync it once it is over
+		if now.Sub(segment.End) < maxGap {
+			continue
+		}
+		entries = append(entries, &timesync.Entry{
+			Start:       segment.Start,
+			End:         segment.End,
+			Project:     segment.App,
+			ProjectID:   trackerProjectID(cfg.TimeSync.Projects, segment.App),
+			Description: segment.App,
+		})
+	}
+	return entries, nil
+}
+
+// trackerProjectID maps a local project to its tracker project ID, falling back to the repository
+// Config keys are lower-cased by the config loader, so the lookup is case-insensitive
+func trackerProjectID(projects map[string]string, project string) string {
+	project = strings.ToLower(project)
+	if id, ok := projects[project]; ok {
+		return id
+	}
+	repo, _, _ := strings.Cut(project, "/")
+	return projects[repo]
+}
+
+// SyncTimeEntries exports sessions that have not been synced yet to the configured tracker
+// With dryRun nothing is sent; results only tell which sessions would be created
+func SyncTimeEntries(cfg *config.Config, st *storage.Storage, entries []*timesync.Entry, dryRun bool) ([]*TimeSyncResult, error) {
+	provider := cfg.TimeSync.Provider
+	if provider == "" {
+		return nil, fmt.Errorf("timesync.provider is not configured (toggl or clockify)")
+	}
+
+	var exporter timesync.Exporter
+	if !dryRun {
+		var err error
+		exporter, err = timesync.NewExporter(provider, cfg.TimeSync.BaseURL, cfg.TimeSync.WorkspaceID, cfg.TimeSync.APIToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]*TimeSyncResult, 0, len(entries))
+	for _, entry := range entries {
+		result := &TimeSyncResult{Entry: entry}
+		results = append(results, result)
+
+		marker, err := st.GetTimeSyncMarker(provider, entry.Key())
+		if err != nil {
+			return results, err
+		}
+		if marker != nil {
+			result.AlreadySynced = true
+			result.ExternalID = marker.ExternalID
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		id, err := exporter.Create(entry)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		result.ExternalID = id
+		if err := st.SaveTimeSyncMarker(&storage.TimeSyncMarker{Provider: provider, EntryKey: entry.Key(), ExternalID: id}); err != nil {
+			// The entry exists in the tracker now, a missing marker would duplicate it on the next sync
+			return results, fmt.Errorf("created %s entry %s but failed to record it: %w", provider, id, err)
+		}
+	}
+	return results, nil
+}
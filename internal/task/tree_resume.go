@@ -0,0 +1,89 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// treePairMaxAge is how long pair results of an interrupted tree aggregation are kept for a resume
+const treePairMaxAge = 7 * 24 * time.Hour
+
+// treeCheckpoint persists the pair results of a tree aggregation, so a run interrupted by a crash
+// resumes from the pairs already combined instead of redoing every API call
+type treeCheckpoint struct {
+	storage   *storage.Storage
+	sessionID string
+	pairs     map[[2]int]*storage.TreePair // Keyed by level and pair index
+	resumed   atomic.Int32
+}
+
+// treeSessionID derives the session from the aggregation inputs, so a restarted aggregation of the
+// same period and summaries finds the results of the interrupted one
+func treeSessionID(periodKey, context, timeContext string, summaries []string) string {
+	h := sha256.New()
+	for _, part := range append([]string{periodKey, context, timeContext}, summaries...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// treePairHash identifies the inputs of a pair; a stored output is only reused for the same inputs
+func treePairHash(left, right string) string {
+	sum := sha256.Sum256([]byte(left + "\x00" + right))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadTreeCheckpoint loads the pair results stored for a session and prunes abandoned sessions
+// Storage errors only disable resuming
+func (e *Executor) loadTreeCheckpoint(sessionID string) *treeCheckpoint {
+	c := &treeCheckpoint{storage: e.storage, sessionID: sessionID, pairs: make(map[[2]int]*storage.TreePair)}
+	if err := e.storage.PruneTreePairs(time.Now().Add(-treePairMaxAge)); err != nil {
+		logger.GetLogger().Warnf("Failed to prune tree aggregation results: %v", err)
+	}
+	pairs, err := e.storage.GetTreePairs(sessionID)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to load tree aggregation results of session %s: %v", sessionID, err)
+		return c
+	}
+	for _, p := range pairs {
+		c.pairs[[2]int{p.Level, p.Pair}] = p
+	}
+	return c
+}
+
+// get returns the stored output of a pair if its inputs are unchanged
+func (c *treeCheckpoint) get(level, pair int, inputHash string) (string, bool) {
+	p, ok := c.pairs[[2]int{level, pair}]
+	if !ok || p.InputHash != inputHash {
+		return "", false
+	}
+	c.resumed.Add(1)
+	return p.Output, true
+}
+
+// save stores the output of a pair combined by the model
+func (c *treeCheckpoint) save(level, pair int, inputHash, output string) {
+	err := c.storage.SaveTreePair(&storage.TreePair{
+		SessionID: c.sessionID,
+		Level:     level,
+		Pair:      pair,
+		InputHash: inputHash,
+		Output:    output,
+	})
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to save tree aggregation result (level %d, pair %d): %v", level, pair, err)
+	}
+}
+
+// finish deletes the stored results once the aggregation completed
+func (c *treeCheckpoint) finish() {
+	if err := c.storage.DeleteTreeSession(c.sessionID); err != nil {
+		logger.GetLogger().Warnf("Failed to delete tree aggregation results of session %s: %v", c.sessionID, err)
+	}
+}
@@ -0,0 +1,38 @@
+package task
+
+import (
+	"testing"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestTreeAggregationResume(t *testing.T) {
+	// No analyzer: every pair must come from the interrupted run
+	e, st := newTestExecutor(t, &config.Config{})
+
+	summaries := []string{"编写代码", "评审 PR", "开会"}
+	sessionID := treeSessionID("2025-11", "month", "", summaries)
+	for _, p := range []*storage.TreePair{
+		{SessionID: sessionID, Level: 1, Pair: 0, InputHash: treePairHash("编写代码", "评审 PR"), Output: "编写代码并评审 PR"},
+		{SessionID: sessionID, Level: 2, Pair: 0, InputHash: treePairHash("编写代码并评审 PR", "开会"), Output: "开发、评审和会议"},
+	} {
+		if err := st.SaveTreePair(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := e.processTreeAggregation(summaries, "2025-11", "month")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "开发、评审和会议" {
+		t.Errorf("processTreeAggregation() = %q", result)
+	}
+	if pairs, _ := st.GetTreePairs(sessionID); len(pairs) != 0 {
+		t.Errorf("session results not deleted after completion: %d pairs left", len(pairs))
+	}
+	if other := treeSessionID("2025-11", "month", "", summaries[:2]); other == sessionID {
+		t.Errorf("different inputs share session %s", sessionID)
+	}
+}
@@ -0,0 +1,155 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+const (
+	// unknownTimeGap splits unknown time into blocks: screenshots further apart start a new block
+	unknownTimeGap = 15 * time.Minute
+	// unknownTimeMinDuration hides blocks shorter than this (a few failed captures are noise)
+	unknownTimeMinDuration = 10 * time.Minute
+	// unknownTimeMaxBlocks caps the listed blocks, the longest ones are kept
+	unknownTimeMaxBlocks = 10
+)
+
+// Reasons a screenshot has no usable analysis, in display order
+const (
+	unknownFailed        = "分析失败"
+	unknownSkipped       = "已跳过"
+	unknownLowConfidence = "低置信度"
+	unknownNotAnalyzed   = "未分析"
+)
+
+var unknownReasonOrder = []string{unknownFailed, unknownSkipped, unknownLowConfidence, unknownNotAnalyzed}
+
+// lowConfidenceMarkers are phrases of analyses that could not tell what was on the screen
+var lowConfidenceMarkers = []string{"无法识别", "无法判断", "无法确定", "无法辨认", "看不清", "unclear", "cannot determine", "unable to determine"}
+
+// unknownTimeBlock is a stretch of captured time without usable analyses
+type unknownTimeBlock struct {
+	Start       time.Time
+	End         time.Time
+	Screenshots []*storage.ScreenshotRecord
+	Reasons     map[string]int // Screenshot count per reason
+}
+
+// unknownReason returns why a screenshot's time is not accounted for in summaries, empty if it is
+func unknownReason(s *storage.ScreenshotRecord) string {
+	switch {
+	case s.AnalysisUnavailable():
+		return unknownFailed
+	case s.AnalysisStatus == storage.AnalysisSkipped:
+		return unknownSkipped
+	case s.HasAnalysis():
+		lower := strings.ToLower(s.Analysis)
+		for _, marker := range lowConfidenceMarkers {
+			if strings.Contains(lower, marker) {
+				return unknownLowConfidence
+			}
+		}
+		return ""
+	default:
+		return unknownNotAnalyzed
+	}
+}
+
+// unknownTimeBlocks groups screenshots (in chronological order) without usable analyses into blocks
+// Each screenshot stands for one capture interval; blocks shorter than unknownTimeMinDuration are dropped
+func unknownTimeBlocks(screenshots []*storage.ScreenshotRecord, interval time.Duration) []*unknownTimeBlock {
+	var blocks []*unknownTimeBlock
+	var current *unknownTimeBlock
+	for _, s := range screenshots {
+		reason := unknownReason(s)
+		if reason == "" {
+			current = nil
+			continue
+		}
+		if current == nil || s.Timestamp.Sub(current.End) > unknownTimeGap {
+			current = &unknownTimeBlock{Start: s.Timestamp, Reasons: make(map[string]int)}
+			blocks = append(blocks, current)
+		}
+		current.End = s.Timestamp.Add(interval)
+		current.Screenshots = append(current.Screenshots, s)
+		current.Reasons[reason]++
+	}
+
+	kept := blocks[:0]
+	for _, b := range blocks {
+		if b.End.Sub(b.Start) >= unknownTimeMinDuration {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// formatUnknownTimeSection renders the unknown time blocks of a period with an example screenshot each,
+// so time without usable analyses can be backfilled instead of silently missing from the summaries
+// Image links are relative to the report directory; returns an empty string if there is no unknown time
+func formatUnknownTimeSection(blocks []*unknownTimeBlock, reportDir string) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	var total time.Duration
+	for _, b := range blocks {
+		total += b.End.Sub(b.Start)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 未知时间\n\n")
+	sb.WriteString(fmt.Sprintf("以下 %d 个时间段（共约 %.0f 分钟）有截图但没有可用的分析，未计入总结。", len(blocks), total.Minutes()))
+	sb.WriteString("失败的截图会在下次分析时重试；也可以用 `stuff-time note -p <小时>` 补充这段时间做了什么。\n\n")
+
+	listed := blocks
+	if len(listed) > unknownTimeMaxBlocks {
+		// Keep the longest blocks, still in chronological order
+		longest := make(map[*unknownTimeBlock]bool)
+		for len(longest) < unknownTimeMaxBlocks {
+			var best *unknownTimeBlock
+			for _, b := range blocks {
+				if !longest[b] && (best == nil || b.End.Sub(b.Start) > best.End.Sub(best.Start)) {
+					best = b
+				}
+			}
+			longest[best] = true
+		}
+		listed = nil
+		for _, b := range blocks {
+			if longest[b] {
+				listed = append(listed, b)
+			}
+		}
+	}
+
+	for _, b := range listed {
+		var reasons []string
+		for _, reason := range unknownReasonOrder {
+			if n := b.Reasons[reason]; n > 0 {
+				reasons = append(reasons, fmt.Sprintf("%s %d", reason, n))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("### %s - %s\n\n", datefmt.DateTimeMinute(b.Start), datefmt.TimeMinute(b.End)))
+		sb.WriteString(fmt.Sprintf("约 %.0f 分钟，%d 张截图（%s），小时：`%s`\n\n",
+			b.End.Sub(b.Start).Minutes(), len(b.Screenshots), strings.Join(reasons, "、"), b.Start.Format("2006-01-02-15")))
+
+		example := b.Screenshots[len(b.Screenshots)/2]
+		if example.ImagePath != "" {
+			imagePath := example.ImagePath
+			if rel, err := filepath.Rel(reportDir, example.ImagePath); err == nil {
+				imagePath = filepath.ToSlash(rel)
+			}
+			sb.WriteString(fmt.Sprintf("![%s](%s)\n\n", datefmt.Time(example.Timestamp), imagePath))
+		}
+	}
+	if len(listed) < len(blocks) {
+		sb.WriteString(fmt.Sprintf("另有 %d 个较短的时间段未列出。\n\n", len(blocks)-len(listed)))
+	}
+	return sb.String()
+}
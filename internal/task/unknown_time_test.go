@@ -0,0 +1,54 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/storage"
+)
+
+func TestUnknownTimeBlocks(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)
+	var screenshots []*storage.ScreenshotRecord
+	add := func(minute int, status, analysis string) {
+		screenshots = append(screenshots, &storage.ScreenshotRecord{
+			ID:             base.Add(time.Duration(minute) * time.Minute).Format("150405"),
+			Timestamp:      base.Add(time.Duration(minute) * time.Minute),
+			ImagePath:      "/data/screenshots/x.png",
+			AnalysisStatus: status,
+			Analysis:       analysis,
+		})
+	}
+	// 14:00-14:12 failed, then analyzed but unclear
+	for m := 0; m < 6; m++ {
+		add(m, storage.AnalysisFailed, "")
+	}
+	for m := 6; m < 12; m++ {
+		add(m, storage.AnalysisDone, "【摘要】画面模糊，无法识别具体内容")
+	}
+	add(12, storage.AnalysisDone, "【摘要】编写代码")
+	// 14:20-14:23 skipped, too short to list
+	for m := 20; m < 23; m++ {
+		add(m, storage.AnalysisSkipped, "")
+	}
+
+	blocks := unknownTimeBlocks(screenshots, time.Minute)
+	if len(blocks) != 1 {
+		t.Fatalf("unknownTimeBlocks() returned %d blocks, want 1", len(blocks))
+	}
+	b := blocks[0]
+	if !b.Start.Equal(base) || !b.End.Equal(base.Add(12*time.Minute)) {
+		t.Errorf("block = %s - %s, want 14:00 - 14:12", b.Start.Format("15:04"), b.End.Format("15:04"))
+	}
+	if b.Reasons[unknownFailed] != 6 || b.Reasons[unknownLowConfidence] != 6 {
+		t.Errorf("block reasons = %v", b.Reasons)
+	}
+
+	section := formatUnknownTimeSection(blocks, "/data/reports/2025/Q4/12")
+	for _, want := range []string{"## 未知时间", "分析失败 6、低置信度 6", "`2025-12-09-14`", "](../../../../screenshots/x.png)"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section does not contain %q:\n%s", want, section)
+		}
+	}
+}
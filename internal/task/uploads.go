@@ -0,0 +1,54 @@
+package task
+
+import (
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// RecordUploads logs every request the analyzer sends to the provider, for the privacy report
+// Offline backends (mock/replay) never send anything, so nothing is recorded for them
+func RecordUploads(cfg *config.Config, a *analyzer.OpenAI, st *storage.Storage) {
+	if cfg.OpenAI.Backend.IsOffline() {
+		return
+	}
+	a.UploadRecorder = func(upload *analyzer.Upload) {
+		if err := st.AddLLMUpload(&storage.LLMUpload{
+			Timestamp:  upload.Time,
+			Endpoint:   upload.Endpoint,
+			Model:      upload.Model,
+			Purpose:    upload.Purpose,
+			ImagePaths: upload.ImagePaths,
+			Text:       upload.Text,
+		}); err != nil {
+			logger.GetLogger().Warnf("Failed to record LLM upload: %v", err)
+		}
+	}
+}
+
+// RecordAPICalls logs the token usage, latency and outcome of every request, for the cost report
+func RecordAPICalls(cfg *config.Config, a *analyzer.OpenAI, st *storage.Storage) {
+	if cfg.OpenAI.Backend.IsOffline() {
+		return
+	}
+	a.CallRecorder = func(call *analyzer.Call) {
+		record := &storage.APICall{
+			Timestamp:        call.Time,
+			Model:            call.Model,
+			Purpose:          call.Purpose,
+			Level:            call.Level,
+			PromptTokens:     call.Usage.PromptTokens,
+			CompletionTokens: call.Usage.CompletionTokens,
+			CachedTokens:     call.Usage.CachedTokens,
+			LatencyMs:        call.Latency.Milliseconds(),
+			StatusCode:       call.StatusCode,
+		}
+		if call.Err != nil {
+			record.Error = call.Err.Error()
+		}
+		if err := st.AddAPICall(record); err != nil {
+			logger.GetLogger().Warnf("Failed to record API call: %v", err)
+		}
+	}
+}
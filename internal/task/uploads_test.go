@@ -0,0 +1,60 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+)
+
+func TestRecordUploads(t *testing.T) {
+	st := newTestStorage(t)
+
+	// The first attempt fails, so the retry uploads the same data again
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			http.Error(w, `{"error":{"message":"service unavailable"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"专注度良好"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	client := analyzer.NewOpenAI("test-key", server.URL, "test-model", 100, "", "", "", "", "", "", "", "", "analysis-model", "analyze")
+	client.Retry = analyzer.RetryPolicy{MaxRetries: 1}
+	RecordUploads(cfg, client, st)
+
+	before := time.Now().Add(-time.Second)
+	if _, err := client.AnalyzeBehavior("在 VS Code 中编写导出功能"); err != nil {
+		t.Fatal(err)
+	}
+	uploads, err := st.QueryLLMUploads(before, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 2 {
+		t.Fatalf("recorded %d uploads, want one per attempt (2)", len(uploads))
+	}
+	for _, upload := range uploads {
+		if upload.Endpoint != server.URL+"/chat/completions" || upload.Model != "analysis-model" ||
+			upload.Purpose != analyzer.PurposeBehaviorAnalysis || !strings.Contains(upload.Text, "在 VS Code 中编写导出功能") {
+			t.Errorf("recorded upload = %+v, want the behavior analysis request", upload)
+		}
+	}
+
+	// Offline backends never send anything
+	cfg.OpenAI.Backend.Mode = analyzer.BackendModeMock
+	offline := analyzer.NewOpenAI("test-key", server.URL, "test-model", 100, "", "", "", "", "", "", "", "", "", "")
+	RecordUploads(cfg, offline, st)
+	if offline.UploadRecorder != nil {
+		t.Error("RecordUploads() set a recorder for an offline backend")
+	}
+}
@@ -0,0 +1,170 @@
+package task
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// ReportReconcileResult counts what ReconcileReports found and changed
+type ReportReconcileResult struct {
+	Checked     int // Summaries with content, which should have a report file
+	Missing     int // Reports missing after reconciling
+	Regenerated int // Missing reports written again from the database
+	Restored    int // Reports marked missing that are back in place
+}
+
+// reconcilePeriodTypes are the period types whose summaries are written as reports
+var reconcilePeriodTypes = []string{"fifteenmin", "hour", "work-segment", "day", "week", "month", "quarter", "year"}
+
+// ReconcileReports checks that every summary with content has its report file (loose or compacted
+// into its month archive), so the database and the reports tree don't drift apart when reports are
+// deleted or moved by hand
+// A missing report is marked as missing, or written again from the database content when
+// regenerate is set (no model call); marks of reports that are back in place are cleared
+func (e *Executor) ReconcileReports(regenerate bool) (*ReportReconcileResult, error) {
+	result := &ReportReconcileResult{}
+	if e.config.Storage.ReportsPath == "" {
+		return result, nil
+	}
+
+	marks, err := e.storage.ListMissingReports()
+	if err != nil {
+		return nil, err
+	}
+	marked := make(map[string]bool, len(marks))
+	for _, m := range marks {
+		marked[m.PeriodKey] = true
+	}
+
+	end := time.Now().AddDate(1, 0, 0)
+	for _, periodType := range reconcilePeriodTypes {
+		summaries, err := e.storage.QueryPeriodSummaries(periodType, time.Time{}, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range summaries {
+			if !hasValidContent(&e.config.Summary, summary) {
+				continue
+			}
+			reportPath, err := e.calculateReportPath(summary)
+			if err != nil {
+				continue
+			}
+			result.Checked++
+
+			if storage.ReportExists(e.config.Storage.ReportsPath, reportPath) {
+				if marked[summary.PeriodKey] {
+					if err := e.storage.ClearReportMissing(summary.PeriodKey); err != nil {
+						return nil, err
+					}
+					logger.GetLogger().Infof("Report of %s is back at %s", summary.PeriodKey, reportPath)
+					result.Restored++
+				}
+				continue
+			}
+
+			if regenerate {
+				if err := e.savePeriodSummaryReport(summary); err != nil {
+					logger.GetLogger().Warnf("Failed to regenerate missing report %s: %v", reportPath, err)
+				} else {
+					logger.GetLogger().Infof("Regenerated missing report of %s: %s", summary.PeriodKey, reportPath)
+					result.Regenerated++
+					continue
+				}
+			}
+
+			result.Missing++
+			if marked[summary.PeriodKey] {
+				continue
+			}
+			if err := e.storage.MarkReportMissing(summary.PeriodKey, reportPath); err != nil {
+				return nil, err
+			}
+			logger.GetLogger().Warnf("Report of %s was deleted or moved: %s", summary.PeriodKey, reportPath)
+		}
+	}
+	return result, nil
+}
+
+// ReportWatchdog polls the reports tree and reconciles the database when reports disappear
+// Polling keeps it to one directory walk per interval instead of a watch per directory
+type ReportWatchdog struct {
+	executor   *Executor
+	regenerate bool
+	known      map[string]bool
+	missing    int
+}
+
+func NewReportWatchdog(executor *Executor, regenerate bool) *ReportWatchdog {
+	return &ReportWatchdog{executor: executor, regenerate: regenerate}
+}
+
+// Check walks the reports tree and reconciles all reports on the first call, when a report seen
+// before is gone, or when reports appear while some are marked missing
+func (w *ReportWatchdog) Check() error {
+	reportsPath := w.executor.config.Storage.ReportsPath
+	if reportsPath == "" {
+		return nil
+	}
+	current, err := listReportFiles(reportsPath)
+	if err != nil {
+		return err
+	}
+
+	changed := w.known == nil
+	for path := range w.known {
+		if !current[path] {
+			changed = true
+			break
+		}
+	}
+	if !changed && w.missing > 0 {
+		for path := range current {
+			if !w.known[path] {
+				changed = true
+				break
+			}
+		}
+	}
+	w.known = current
+	if !changed {
+		return nil
+	}
+
+	result, err := w.executor.ReconcileReports(w.regenerate)
+	if err != nil {
+		return err
+	}
+	w.missing = result.Missing
+	if result.Regenerated > 0 {
+		// Pick up the rewritten files so they don't count as appeared on the next check
+		if w.known, err = listReportFiles(reportsPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listReportFiles returns the paths of the markdown reports under root
+func listReportFiles(root string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".md") {
+			files[path] = true
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestReconcileReports(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.ReportsPath = filepath.Join(dir, "reports")
+	e, st := newTestExecutor(t, cfg)
+
+	start := time.Date(2025, 11, 21, 14, 0, 0, 0, time.Local)
+	summary := &storage.PeriodSummary{
+		PeriodKey:   "2025-11-21-14",
+		PeriodType:  "hour",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		Screenshots: "1,2",
+		Summary:     "评审 PR，修复登录页面的样式问题",
+	}
+	if err := st.SavePeriodSummary(summary); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.savePeriodSummaryReport(summary); err != nil {
+		t.Fatal(err)
+	}
+	reportPath, err := e.calculateReportPath(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := e.ReconcileReports(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 1 || result.Missing != 0 {
+		t.Errorf("ReconcileReports() with report in place = %+v", result)
+	}
+
+	if err := os.Remove(reportPath); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = e.ReconcileReports(false); err != nil {
+		t.Fatal(err)
+	}
+	missing, err := st.ListMissingReports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Missing != 1 || len(missing) != 1 || missing[0].ReportPath != reportPath {
+		t.Errorf("ReconcileReports() after deletion = %+v, marks %v", result, missing)
+	}
+
+	if result, err = e.ReconcileReports(true); err != nil {
+		t.Fatal(err)
+	}
+	if result.Regenerated != 1 || result.Missing != 0 {
+		t.Errorf("ReconcileReports() with regenerate = %+v", result)
+	}
+	if _, err := os.Stat(reportPath); err != nil {
+		t.Errorf("report not regenerated: %v", err)
+	}
+	if missing, _ := st.ListMissingReports(); len(missing) != 0 {
+		t.Errorf("missing mark not cleared: %v", missing)
+	}
+}
@@ -0,0 +1,215 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/datefmt"
+	"stuff-time/internal/storage"
+)
+
+// DetectWorkday finds the first and last non-idle capture of the logical day containing day and stores them
+// Returns nil when nothing but idle screens was captured
+func DetectWorkday(cfg *config.Config, st *storage.Storage, day time.Time) (*storage.Workday, error) {
+	dayStart := cfg.Storage.DateStart(day)
+	screenshots, err := st.QueryByDateRange(dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	workday := workdayOf(dayStart, screenshots)
+	if workday == nil {
+		return nil, nil
+	}
+	if err := st.SaveWorkday(workday); err != nil {
+		return nil, err
+	}
+	return workday, nil
+}
+
+// DetectWorkdays detects the workday of every day from the day of start to the day before end
+// Days without work are left out
+func DetectWorkdays(cfg *config.Config, st *storage.Storage, start, end time.Time) ([]*storage.Workday, error) {
+	var workdays []*storage.Workday
+	for day := cfg.Storage.DateStart(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		workday, err := DetectWorkday(cfg, st, day)
+		if err != nil {
+			return nil, err
+		}
+		if workday != nil {
+			workdays = append(workdays, workday)
+		}
+	}
+	return workdays, nil
+}
+
+// workdayOf returns the workday of the day's screenshots, nil without a non-idle capture
+// Lock screen, desktop and skipped (idle, embargoed) captures are idle; failed or pending analyses count as work
+func workdayOf(dayStart time.Time, screenshots []*storage.ScreenshotRecord) *storage.Workday {
+	var workday *storage.Workday
+	for _, s := range screenshots {
+		if s.AnalysisStatus == storage.AnalysisSkipped || (s.HasAnalysis() && isDesktopOrLockScreenAnalysis(s.Analysis)) {
+			continue
+		}
+		if workday == nil {
+			workday = &storage.Workday{Date: dayStart, Start: s.Timestamp, End: s.Timestamp}
+		}
+		if s.Timestamp.Before(workday.Start) {
+			workday.Start = s.Timestamp
+		}
+		if s.Timestamp.After(workday.End) {
+			workday.End = s.Timestamp
+		}
+		workday.Captures++
+	}
+	return workday
+}
+
+// WorkdayOvertime returns the time worked beyond the normal workday: with workday.use_detected the time
+// beyond workday.standard_hours, otherwise the time before and after screenshot.work_hours
+// Returns false when there are no work hours to compare with (not configured or spanning midnight)
+func WorkdayOvertime(cfg *config.Config, workday *storage.Workday) (time.Duration, bool) {
+	if cfg.Workday.UseDetected {
+		return max(0, workday.Duration()-cfg.Workday.StandardDuration()), true
+	}
+	hours := cfg.Screenshot.WorkHours
+	date := workday.Date
+	start := time.Date(date.Year(), date.Month(), date.Day(), hours.StartHour, hours.StartMinute, 0, 0, date.Location())
+	end := time.Date(date.Year(), date.Month(), date.Day(), hours.EndHour, hours.EndMinute, 0, 0, date.Location())
+	if !end.After(start) {
+		return 0, false
+	}
+	return max(0, start.Sub(workday.Start)) + max(0, workday.End.Sub(end)), true
+}
+
+// workSegmentRange returns the time of the day divided into work segments: the hours from the first to the
+// last non-idle capture with workday.use_detected, otherwise the work hours clamped to the hours of the
+// first and last screenshot in them. Returns false when nothing was captured
+func (e *Executor) workSegmentRange(dayStart time.Time) (time.Time, time.Time, bool, error) {
+	if e.config.Workday.UseDetected {
+		workday, err := DetectWorkday(e.config, e.storage, dayStart)
+		if err != nil || workday == nil {
+			return time.Time{}, time.Time{}, false, err
+		}
+		first, last := workday.Start, workday.End
+		return time.Date(first.Year(), first.Month(), first.Day(), first.Hour(), 0, 0, 0, first.Location()),
+			time.Date(last.Year(), last.Month(), last.Day(), last.Hour()+1, 0, 0, 0, last.Location()), true, nil
+	}
+
+	workHours := e.config.Screenshot.WorkHours
+	workStart := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), workHours.StartHour, workHours.StartMinute, 0, 0, dayStart.Location())
+	workEnd := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), workHours.EndHour, workHours.EndMinute, 0, 0, dayStart.Location())
+
+	// Clamp to the hours with actual activity
+	screenshots, err := e.storage.QueryByDateRange(workStart, workEnd)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("failed to query screenshots: %w", err)
+	}
+	if len(screenshots) == 0 {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	first, last := screenshots[0].Timestamp, screenshots[0].Timestamp
+	for _, s := range screenshots {
+		if s.Timestamp.Before(first) {
+			first = s.Timestamp
+		}
+		if s.Timestamp.After(last) {
+			last = s.Timestamp
+		}
+	}
+	if firstHour := time.Date(first.Year(), first.Month(), first.Day(), first.Hour(), 0, 0, 0, first.Location()); firstHour.After(workStart) {
+		workStart = firstHour
+	}
+	if lastHourEnd := time.Date(last.Year(), last.Month(), last.Day(), last.Hour()+1, 0, 0, 0, last.Location()); lastHourEnd.Before(workEnd) {
+		workEnd = lastHourEnd
+	}
+	return workStart, workEnd, true, nil
+}
+
+// workdaySection detects and renders the workday boundaries of a day or week summary
+func (e *Executor) workdaySection(summary *storage.PeriodSummary) (string, error) {
+	workdays, err := DetectWorkdays(e.config, e.storage, summary.StartTime, summary.EndTime)
+	if err != nil {
+		return "", err
+	}
+	if summary.PeriodType == "day" {
+		if len(workdays) == 0 {
+			return "", nil
+		}
+		return formatWorkdaySection(e.config, workdays[0]), nil
+	}
+	return formatWeekWorkdaysSection(e.config, workdays), nil
+}
+
+// formatWorkdaySection renders the workday of a day, e.g. "工作时间 09:12–19:47"
+func formatWorkdaySection(cfg *config.Config, workday *storage.Workday) string {
+	var sb strings.Builder
+	sb.WriteString("## 工作时间\n\n")
+	sb.WriteString(fmt.Sprintf("- **工作时间**：%s–%s（%s）\n", datefmt.TimeMinute(workday.Start), datefmt.TimeMinute(workday.End),
+		formatMinutes(workday.Duration().Minutes())))
+	if overtime, ok := WorkdayOvertime(cfg, workday); ok && overtime >= time.Minute {
+		sb.WriteString(fmt.Sprintf("- **加班**：%s（%s）\n", formatMinutes(overtime.Minutes()), overtimeBasis(cfg)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatWeekWorkdaysSection renders the workday of each day of a week and the overtime total, empty without work
+func formatWeekWorkdaysSection(cfg *config.Config, workdays []*storage.Workday) string {
+	if len(workdays) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 工作时间\n\n")
+	sb.WriteString("| 日期 | 上班 | 下班 | 时长 | 加班 |\n")
+	sb.WriteString("|------|------|------|------|------|\n")
+	var total, overtimeTotal time.Duration
+	var startMinutes, endMinutes int
+	hasOvertime := false
+	for _, w := range workdays {
+		overtime := "-"
+		if d, ok := WorkdayOvertime(cfg, w); ok {
+			hasOvertime = true
+			overtimeTotal += d
+			if d >= time.Minute {
+				overtime = formatMinutes(d.Minutes())
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", datefmt.Date(w.Date), datefmt.TimeMinute(w.Start),
+			datefmt.TimeMinute(w.End), formatMinutes(w.Duration().Minutes()), overtime))
+		total += w.Duration()
+		startMinutes += minutesSince(w.Date, w.Start)
+		endMinutes += minutesSince(w.Date, w.End)
+	}
+	n := len(workdays)
+	sb.WriteString(fmt.Sprintf("\n- **平均**：%s 上班，%s 下班，每天 %s\n", clockOf(workdays[0].Date, startMinutes/n),
+		clockOf(workdays[0].Date, endMinutes/n), formatMinutes(total.Minutes()/float64(n))))
+	if hasOvertime {
+		sb.WriteString(fmt.Sprintf("- **加班合计**：%s（%s）\n", formatMinutes(overtimeTotal.Minutes()), overtimeBasis(cfg)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// overtimeBasis describes what the overtime is measured against
+func overtimeBasis(cfg *config.Config) string {
+	if cfg.Workday.UseDetected {
+		return fmt.Sprintf("超出每天 %s", formatMinutes(cfg.Workday.StandardDuration().Minutes()))
+	}
+	hours := cfg.Screenshot.WorkHours
+	return fmt.Sprintf("%02d:%02d-%02d:%02d 之外", hours.StartHour, hours.StartMinute, hours.EndHour, hours.EndMinute)
+}
+
+// minutesSince returns the minutes from the calendar midnight of date to t, so times after midnight
+// of a day starting at storage.day_start_hour average correctly
+func minutesSince(date, t time.Time) int {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return int(t.Sub(midnight) / time.Minute)
+}
+
+// clockOf returns the time minutes after the calendar midnight of date
+func clockOf(date time.Time, minutes int) string {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return datefmt.TimeMinute(midnight.Add(time.Duration(minutes) * time.Minute))
+}
@@ -0,0 +1,103 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+	"stuff-time/internal/storage"
+)
+
+func TestWorkdayOf(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 12, 10, hour, minute, 0, 0, time.Local)
+	}
+	dayStart := at(0, 0)
+	screenshots := []*storage.ScreenshotRecord{
+		{Timestamp: at(8, 0), AnalysisStatus: storage.AnalysisDone, Analysis: "锁屏界面"},
+		{Timestamp: at(9, 12), AnalysisStatus: storage.AnalysisDone, Analysis: "在 VS Code 中编写 Go 代码，实现工作时间识别"},
+		{Timestamp: at(13, 0), AnalysisStatus: storage.AnalysisPending},
+		{Timestamp: at(19, 47), AnalysisStatus: storage.AnalysisFailed},
+		{Timestamp: at(21, 30), AnalysisStatus: storage.AnalysisSkipped, AnalysisError: "idle: no input for 10m0s"},
+	}
+	workday := workdayOf(dayStart, screenshots)
+	if workday == nil || !workday.Start.Equal(at(9, 12)) || !workday.End.Equal(at(19, 47)) || workday.Captures != 3 {
+		t.Fatalf("workdayOf() = %+v, want 09:12-19:47 with 3 captures", workday)
+	}
+	if workdayOf(dayStart, screenshots[:1]) != nil {
+		t.Error("a day with only lock screens has a workday")
+	}
+
+	cfg := &config.Config{}
+	cfg.Screenshot.WorkHours = config.WorkHoursConfig{StartHour: 9, StartMinute: 30, EndHour: 18}
+	tests := []struct {
+		name        string
+		useDetected bool
+		want        time.Duration
+	}{
+		{"outside work hours", false, 18*time.Minute + 107*time.Minute},
+		{"beyond standard hours", true, 10*time.Hour + 35*time.Minute - 8*time.Hour},
+	}
+	for _, tt := range tests {
+		cfg.Workday.UseDetected = tt.useDetected
+		if got, ok := WorkdayOvertime(cfg, workday); !ok || got != tt.want {
+			t.Errorf("%s: WorkdayOvertime() = %s, %v; want %s", tt.name, got, ok, tt.want)
+		}
+	}
+
+	cfg.Workday.UseDetected = false
+	if section := formatWorkdaySection(cfg, workday); !strings.Contains(section, "09:12–19:47") || !strings.Contains(section, "加班") {
+		t.Errorf("formatWorkdaySection() = %q", section)
+	}
+	cfg.Screenshot.WorkHours = config.WorkHoursConfig{}
+	if _, ok := WorkdayOvertime(cfg, workday); ok {
+		t.Error("overtime without configured work hours")
+	}
+}
+
+func TestWorkSegmentRange(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Screenshot.WorkHours = config.WorkHoursConfig{StartHour: 9, StartMinute: 30, EndHour: 20}
+	e, st := newTestExecutor(t, cfg)
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 12, day, hour, minute, 0, 0, time.Local)
+	}
+	for i, timestamp := range []time.Time{at(9, 9, 40), at(9, 15, 5), at(10, 11, 20), at(10, 14, 5), at(10, 21, 0)} {
+		record := &storage.ScreenshotRecord{ID: fmt.Sprintf("s%d", i), Timestamp: timestamp, ImagePath: fmt.Sprintf("/nonexistent/%d.png", i)}
+		record.GenerateHourKey()
+		if err := st.SaveScreenshot(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		day        int
+		start, end time.Time
+		ok         bool
+	}{
+		{"keeps the work start before the first full hour", 9, at(9, 9, 30), at(9, 16, 0), true},
+		{"clamps to the hours with activity", 10, at(10, 11, 0), at(10, 15, 0), true},
+		{"no screenshots in work hours", 11, time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		start, end, ok, err := e.workSegmentRange(at(tt.day, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.ok || !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: workSegmentRange() = %s-%s, %v; want %s-%s, %v", tt.name, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+
+	if d := cfg.Screenshot.WorkHours.SegmentDuration(); d != 2*time.Hour {
+		t.Errorf("default SegmentDuration() = %s, want 2h", d)
+	}
+	cfg.Screenshot.WorkHours.SegmentMinutes = 90
+	if d := cfg.Screenshot.WorkHours.SegmentDuration(); d != 90*time.Minute {
+		t.Errorf("SegmentDuration() = %s, want 1h30m", d)
+	}
+}
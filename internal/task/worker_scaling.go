@@ -0,0 +1,136 @@
+package task
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"stuff-time/internal/analyzer"
+	"stuff-time/internal/config"
+	"stuff-time/internal/costs"
+	"stuff-time/internal/events"
+	"stuff-time/internal/logger"
+	"stuff-time/internal/storage"
+)
+
+// budgetPressure is the share of performance.budget.daily_usd spent after which only min_workers are used
+const budgetPressure = 0.8
+
+// scalingWindow bounds the API calls observed for the first scaling decision of the daemon
+const scalingWindow = time.Hour
+
+// workerScaler keeps the analysis worker count chosen by screenshot.worker_scaling between batches
+// It is only used by the batch analysis, which holds analysisMutex, so it needs no lock of its own
+type workerScaler struct {
+	workers  int       // 0 until the first batch
+	observed time.Time // Start of the previous batch; its API calls drive the next decision
+}
+
+// scalingStats are the observations a scaling decision is based on
+type scalingStats struct {
+	Calls       int           // Screenshot analysis calls since the previous batch, retries included
+	RateLimited int           // Calls rejected with 429
+	Failed      int           // Other failed calls
+	Latency     time.Duration // Average latency of the successful calls, 0 when unknown
+	Pending     int           // Unanalyzed screenshots before this batch
+	Spent       float64       // USD spent today
+	Budget      float64       // performance.budget.daily_usd, 0 when unlimited
+}
+
+// scaleWorkers returns the worker count of the next batch and the reason of a change
+// Rate limits halve the count, errors and a latency over the target remove one worker, and a healthy
+// previous batch adds one while more screenshots are waiting than there are workers. Nearing the
+// daily budget falls back to the minimum
+func scaleWorkers(current int, cfg *config.WorkerScalingConfig, stats scalingStats) (int, string) {
+	lo, hi := cfg.Bounds()
+	current = max(lo, min(hi, current))
+
+	switch {
+	case stats.Budget > 0 && stats.Spent >= stats.Budget*budgetPressure:
+		return lo, "daily budget nearly spent"
+	case stats.Calls == 0:
+		return current, ""
+	case float64(stats.RateLimited)/float64(stats.Calls) > cfg.MaxErrorRate:
+		return max(lo, current/2), "rate limited"
+	case float64(stats.RateLimited+stats.Failed)/float64(stats.Calls) > cfg.MaxErrorRate:
+		return max(lo, current-1), "API errors"
+	case stats.Latency > cfg.GetTargetLatency():
+		return max(lo, current-1), "latency over target"
+	case stats.Pending > current && stats.RateLimited == 0:
+		return min(hi, current+1), "backlog"
+	}
+	return current, ""
+}
+
+// analysisWorkers returns the worker count of the batch analysis of pending screenshots
+// Without screenshot.worker_scaling it is the fixed analysis_workers
+func (e *Executor) analysisWorkers(pending int) int {
+	workers := e.config.Screenshot.AnalysisWorkers
+	if workers <= 0 {
+		workers = 3 // Default to 3 workers
+	}
+	scaling := &e.config.Screenshot.WorkerScaling
+	if !scaling.Enabled {
+		return workers
+	}
+
+	now := time.Now()
+	if e.scaler.workers == 0 {
+		e.scaler.workers = workers
+		e.scaler.observed = now.Add(-scalingWindow)
+	}
+	stats, err := e.scalingStats(e.scaler.observed, now)
+	if err != nil {
+		logger.GetLogger().Warnf("Failed to collect worker scaling stats, keeping %d workers: %v", e.scaler.workers, err)
+		return e.scaler.workers
+	}
+	stats.Pending = pending
+	e.scaler.observed = now
+
+	next, reason := scaleWorkers(e.scaler.workers, scaling, stats)
+	if next != e.scaler.workers {
+		logger.GetLogger().Infof("Analysis workers %d -> %d (%s)", e.scaler.workers, next, reason)
+		events.Emit(events.LevelInfo, events.ComponentAnalyzer, "Analysis workers %d -> %d (%s)", e.scaler.workers, next, reason)
+	}
+	e.scaler.workers = next
+	if err := e.storage.SaveRuntimeState(storage.RuntimeAnalysisWorkers, strconv.Itoa(next)); err != nil {
+		logger.GetLogger().Warnf("Failed to save analysis worker count: %v", err)
+	}
+	return next
+}
+
+// scalingStats collects the screenshot analysis calls within [from, to) and today's spending
+func (e *Executor) scalingStats(from, to time.Time) (scalingStats, error) {
+	stats := scalingStats{Budget: e.config.Performance.Budget.DailyUSD}
+	calls, err := e.storage.QueryAPICalls(from, to)
+	if err != nil {
+		return stats, err
+	}
+
+	var latency time.Duration
+	for _, call := range calls {
+		if call.Purpose != analyzer.PurposeScreenshotAnalysis {
+			continue
+		}
+		stats.Calls++
+		switch {
+		case call.StatusCode == http.StatusTooManyRequests:
+			stats.RateLimited++
+		case call.Error != "":
+			stats.Failed++
+		case call.LatencyMs > 0:
+			latency += time.Duration(call.LatencyMs) * time.Millisecond
+		}
+	}
+	if ok := stats.Calls - stats.RateLimited - stats.Failed; ok > 0 {
+		stats.Latency = latency / time.Duration(ok)
+	}
+	if stats.Budget > 0 {
+		today, err := e.storage.SumAPICallUsage(e.config.Storage.DayStart(to), to)
+		if err != nil {
+			return stats, err
+		}
+		stats.Spent = costs.Usage(today, modelPrices(e.config)).Cost
+	}
+	return stats, nil
+}
@@ -0,0 +1,37 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"stuff-time/internal/config"
+)
+
+func TestScaleWorkers(t *testing.T) {
+	cfg := &config.WorkerScalingConfig{Enabled: true, MinWorkers: 1, MaxWorkers: 6, TargetLatency: "10s", MaxErrorRate: 0.1}
+
+	tests := []struct {
+		name    string
+		current int
+		stats   scalingStats
+		want    int
+	}{
+		{"no calls observed", 3, scalingStats{Pending: 50}, 3},
+		{"healthy with backlog", 3, scalingStats{Calls: 40, Latency: 4 * time.Second, Pending: 50}, 4},
+		{"capped at max", 6, scalingStats{Calls: 40, Latency: 4 * time.Second, Pending: 50}, 6},
+		{"backlog drained", 3, scalingStats{Calls: 40, Latency: 4 * time.Second, Pending: 2}, 3},
+		{"rate limited", 6, scalingStats{Calls: 40, RateLimited: 8, Latency: 4 * time.Second, Pending: 50}, 3},
+		{"server errors", 4, scalingStats{Calls: 40, Failed: 6, Latency: 4 * time.Second, Pending: 50}, 3},
+		{"slow responses", 4, scalingStats{Calls: 40, Latency: 15 * time.Second, Pending: 50}, 3},
+		{"never below min", 1, scalingStats{Calls: 40, RateLimited: 20, Pending: 50}, 1},
+		{"budget pressure", 5, scalingStats{Calls: 40, Latency: 4 * time.Second, Pending: 50, Spent: 4.2, Budget: 5}, 1},
+		{"clamped into range", 10, scalingStats{}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := scaleWorkers(tt.current, cfg, tt.stats); got != tt.want {
+				t.Errorf("scaleWorkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
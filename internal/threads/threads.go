@@ -0,0 +1,106 @@
+// Package threads clusters a day's activities into task threads by the similarity of their
+// analysis embeddings, so work that is interleaved over the day is grouped back together
+package threads
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Item is one analyzed screenshot
+type Item struct {
+	ID     string
+	Time   time.Time
+	Text   string
+	Vector []float64
+}
+
+// Thread is a group of items about the same task
+type Thread struct {
+	Items    []*Item // Chronological
+	centroid []float64
+}
+
+// Cluster assigns items in chronological order to the thread whose centroid is most similar,
+// starting a new thread when no centroid reaches similarity
+// Threads are returned in order of their first item
+func Cluster(items []*Item, similarity float64) []*Thread {
+	sorted := make([]*Item, 0, len(items))
+	for _, item := range items {
+		if len(item.Vector) > 0 {
+			sorted = append(sorted, item)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var threads []*Thread
+	for _, item := range sorted {
+		var best *Thread
+		bestSim := similarity
+		for _, t := range threads {
+			if sim := Cosine(t.centroid, item.Vector); sim >= bestSim {
+				best, bestSim = t, sim
+			}
+		}
+		if best == nil {
+			best = &Thread{centroid: make([]float64, len(item.Vector))}
+			threads = append(threads, best)
+		}
+		best.add(item)
+	}
+	return threads
+}
+
+// add appends an item and moves the centroid to the running mean of the item vectors
+func (t *Thread) add(item *Item) {
+	t.Items = append(t.Items, item)
+	n := float64(len(t.Items))
+	for i := range t.centroid {
+		if i < len(item.Vector) {
+			t.centroid[i] += (item.Vector[i] - t.centroid[i]) / n
+		}
+	}
+}
+
+// Centroid returns the mean vector of the thread's items
+func (t *Thread) Centroid() []float64 {
+	return t.centroid
+}
+
+// Representative returns the texts of up to n distinct items closest to the centroid
+func (t *Thread) Representative(n int) []string {
+	ranked := make([]*Item, len(t.Items))
+	copy(ranked, t.Items)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return Cosine(t.centroid, ranked[i].Vector) > Cosine(t.centroid, ranked[j].Vector)
+	})
+
+	var texts []string
+	seen := make(map[string]bool)
+	for _, item := range ranked {
+		if len(texts) >= n {
+			break
+		}
+		if item.Text == "" || seen[item.Text] {
+			continue
+		}
+		seen[item.Text] = true
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+// Cosine returns the cosine similarity of two vectors, 0 when either is empty or zero
+func Cosine(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
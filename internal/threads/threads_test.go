@@ -0,0 +1,58 @@
+package threads
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClusterGroupsInterleavedWork(t *testing.T) {
+	base := time.Date(2025, 12, 9, 9, 0, 0, 0, time.Local)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	payments := []float64{1, 0.1, 0}
+	okr := []float64{0, 0.1, 1}
+	items := []*Item{
+		{ID: "a", Time: at(0), Text: "payments retry", Vector: payments},
+		{ID: "b", Time: at(1), Text: "payments retry", Vector: []float64{0.9, 0.2, 0}},
+		{ID: "c", Time: at(2), Text: "okr doc", Vector: okr},
+		// Back to the first task after an interruption
+		{ID: "d", Time: at(3), Text: "payments test", Vector: payments},
+		{ID: "e", Time: at(4), Text: "no vector"},
+	}
+
+	threads := Cluster(items, 0.8)
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want 2", len(threads))
+	}
+	var ids []string
+	for _, item := range threads[0].Items {
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[1] != "b" || ids[2] != "d" {
+		t.Errorf("first thread = %v, want [a b d]", ids)
+	}
+	if len(threads[1].Items) != 1 || threads[1].Items[0].ID != "c" {
+		t.Errorf("second thread should hold only c")
+	}
+
+	texts := threads[0].Representative(5)
+	if len(texts) != 2 {
+		t.Errorf("Representative = %v, want the 2 distinct texts", texts)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float64
+		want float64
+	}{
+		{[]float64{1, 0}, []float64{2, 0}, 1},
+		{[]float64{1, 0}, []float64{0, 1}, 0},
+		{[]float64{1, 0}, []float64{-1, 0}, -1},
+		{nil, []float64{1}, 0},
+	}
+	for _, tt := range tests {
+		if got := Cosine(tt.a, tt.b); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("Cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
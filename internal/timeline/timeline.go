@@ -0,0 +1,92 @@
+// Package timeline groups screenshots into minute-resolution activity segments
+// (app, category) for Gantt-style timelines and time tracker exports
+package timeline
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CategoryUnknown is used for screenshots without a usable analysis (pending or failed)
+const CategoryUnknown = "unknown"
+
+// Point is one screenshot on the timeline
+type Point struct {
+	Time         time.Time
+	App          string // Frontmost application, empty if not recorded
+	Space        string // macOS Space label, empty if not recorded
+	Category     string
+	ScreenshotID string
+}
+
+// Segment is a contiguous run of screenshots with the same app, Space and category
+type Segment struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Minutes       int       `json:"minutes"`
+	App           string    `json:"app,omitempty"`
+	Space         string    `json:"space,omitempty"`
+	Category      string    `json:"category"`
+	ScreenshotIDs []string  `json:"screenshot_ids"`
+}
+
+// Build merges chronologically ordered points into segments
+// Each point covers one capture interval; points more than maxGap apart, or with a different
+// app, Space or category, start a new segment. Segment bounds are rounded to whole minutes
+func Build(points []Point, interval, maxGap time.Duration) []*Segment {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if maxGap < interval {
+		maxGap = interval
+	}
+
+	var segments []*Segment
+	var current *Segment
+	var lastTime time.Time
+	for _, p := range points {
+		if current != nil && (p.App != current.App || p.Space != current.Space || p.Category != current.Category || p.Time.Sub(lastTime) > maxGap) {
+			segments = append(segments, current)
+			current = nil
+		}
+		if current == nil {
+			current = &Segment{Start: p.Time.Round(time.Minute), App: p.App, Space: p.Space, Category: p.Category}
+		}
+		current.End = p.Time.Add(interval).Round(time.Minute)
+		current.ScreenshotIDs = append(current.ScreenshotIDs, p.ScreenshotID)
+		lastTime = p.Time
+	}
+	if current != nil {
+		segments = append(segments, current)
+	}
+
+	for _, s := range segments {
+		// Sub-minute intervals could round a short segment down to nothing
+		if !s.End.After(s.Start) {
+			s.End = s.Start.Add(time.Minute)
+		}
+		s.Minutes = int(s.End.Sub(s.Start) / time.Minute)
+	}
+	return segments
+}
+
+// WriteCSV writes segments as CSV (one row per segment), e.g. for importing into time tracking tools
+func WriteCSV(w io.Writer, segments []*Segment) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"start", "end", "minutes", "app", "category", "screenshot_ids"})
+	for _, s := range segments {
+		cw.Write([]string{
+			s.Start.Format(time.RFC3339),
+			s.End.Format(time.RFC3339),
+			fmt.Sprintf("%d", s.Minutes),
+			s.App,
+			s.Category,
+			strings.Join(s.ScreenshotIDs, ";"),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
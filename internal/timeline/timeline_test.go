@@ -0,0 +1,33 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildMergesContiguousPoints(t *testing.T) {
+	base := time.Date(2025, 12, 9, 14, 0, 20, 0, time.Local)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	points := []Point{
+		{Time: at(0), App: "Code", Category: "coding", ScreenshotID: "a"},
+		{Time: at(1), App: "Code", Category: "coding", ScreenshotID: "b"},
+		{Time: at(2), App: "Slack", Category: "communication", ScreenshotID: "c"},
+		// Gap of 10 minutes splits the segment
+		{Time: at(12), App: "Slack", Category: "communication", ScreenshotID: "d"},
+	}
+
+	segments := Build(points, time.Minute, 2*time.Minute)
+	if len(segments) != 3 {
+		t.Fatalf("got %d segments, want 3", len(segments))
+	}
+	first := segments[0]
+	if !first.Start.Equal(time.Date(2025, 12, 9, 14, 0, 0, 0, time.Local)) || first.Minutes != 2 {
+		t.Errorf("first segment = %s +%d min, want 14:00 +2 min", first.Start.Format("15:04:05"), first.Minutes)
+	}
+	if len(first.ScreenshotIDs) != 2 || first.App != "Code" {
+		t.Errorf("first segment = %+v", first)
+	}
+	if segments[2].ScreenshotIDs[0] != "d" {
+		t.Errorf("third segment should start with d, got %v", segments[2].ScreenshotIDs)
+	}
+}
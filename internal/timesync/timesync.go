@@ -0,0 +1,174 @@
+// Package timesync exports detected work sessions as time entries to Toggl Track or Clockify
+package timesync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderToggl    = "toggl"
+	ProviderClockify = "clockify"
+)
+
+// Entry is a work session to export as a time entry
+type Entry struct {
+	Start       time.Time
+	End         time.Time
+	Project     string // Local project tag (repository or repository/sub-project)
+	ProjectID   string // Tracker project ID mapped from Project, empty for none
+	Description string
+}
+
+// Key returns the stable identifier of the session used as idempotent sync marker
+func (e *Entry) Key() string {
+	return fmt.Sprintf("%s|%s|%s", e.Project, e.Start.UTC().Format(time.RFC3339), e.End.UTC().Format(time.RFC3339))
+}
+
+// Exporter creates time entries in a time tracker
+type Exporter interface {
+	// Create creates a time entry and returns its ID in the tracker
+	Create(entry *Entry) (string, error)
+}
+
+// NewExporter creates an exporter for the given provider
+// baseURL overrides the provider's API endpoint (empty for the public API)
+func NewExporter(provider, baseURL, workspaceID, apiToken string) (Exporter, error) {
+	if workspaceID == "" || apiToken == "" {
+		return nil, fmt.Errorf("timesync.workspace_id and timesync.api_token are required")
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch provider {
+	case ProviderToggl:
+		if baseURL == "" {
+			baseURL = "https://api.track.toggl.com/api/v9"
+		}
+		wid, err := strconv.ParseInt(workspaceID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid toggl workspace ID %q: %w", workspaceID, err)
+		}
+		return &TogglExporter{BaseURL: strings.TrimRight(baseURL, "/"), WorkspaceID: wid, APIToken: apiToken, client: client}, nil
+	case ProviderClockify:
+		if baseURL == "" {
+			baseURL = "https://api.clockify.me/api/v1"
+		}
+		return &ClockifyExporter{BaseURL: strings.TrimRight(baseURL, "/"), WorkspaceID: workspaceID, APIKey: apiToken, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported time tracker: %s (supported: toggl, clockify)", provider)
+	}
+}
+
+// TogglExporter creates time entries through the Toggl Track API v9
+type TogglExporter struct {
+	BaseURL     string
+	WorkspaceID int64
+	APIToken    string
+	client      *http.Client
+}
+
+func (t *TogglExporter) Create(entry *Entry) (string, error) {
+	payload := map[string]interface{}{
+		"created_with": "stuff-time",
+		"description":  entry.Description,
+		"start":        entry.Start.UTC().Format(time.RFC3339),
+		"stop":         entry.End.UTC().Format(time.RFC3339),
+		"duration":     int64(entry.End.Sub(entry.Start).Seconds()),
+		"workspace_id": t.WorkspaceID,
+		"tags":         []string{"stuff-time"},
+	}
+	if entry.ProjectID != "" {
+		projectID, err := strconv.ParseInt(entry.ProjectID, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid toggl project ID %q: %w", entry.ProjectID, err)
+		}
+		payload["project_id"] = projectID
+	}
+
+	req, err := newJSONRequest(fmt.Sprintf("%s/workspaces/%d/time_entries", t.BaseURL, t.WorkspaceID), payload)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(t.APIToken, "api_token")
+
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	if err := doRequest(t.client, req, &resp); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+// ClockifyExporter creates time entries through the Clockify API v1
+type ClockifyExporter struct {
+	BaseURL     string
+	WorkspaceID string
+	APIKey      string
+	client      *http.Client
+}
+
+func (c *ClockifyExporter) Create(entry *Entry) (string, error) {
+	payload := map[string]interface{}{
+		"start":       entry.Start.UTC().Format(time.RFC3339),
+		"end":         entry.End.UTC().Format(time.RFC3339),
+		"description": entry.Description,
+	}
+	if entry.ProjectID != "" {
+		payload["projectId"] = entry.ProjectID
+	}
+
+	req, err := newJSONRequest(fmt.Sprintf("%s/workspaces/%s/time-entries", c.BaseURL, c.WorkspaceID), payload)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Api-Key", c.APIKey)
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := doRequest(c.client, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func newJSONRequest(url string, payload interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// doRequest executes a request and decodes the JSON response into out
+func doRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
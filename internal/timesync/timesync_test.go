@@ -0,0 +1,49 @@
+package timesync
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTogglExporterCreatesEntry(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workspaces/42/time_entries" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "token" || pass != "api_token" {
+			t.Errorf("unexpected auth %q/%q", user, pass)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id": 1001}`))
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(ProviderToggl, server.URL, "42", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+	id, err := exporter.Create(&Entry{Start: start, End: start.Add(45 * time.Minute), Project: "stuff-time", ProjectID: "7", Description: "stuff-time"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1001" {
+		t.Errorf("id = %s, want 1001", id)
+	}
+	if got["duration"] != float64(2700) || got["project_id"] != float64(7) || got["stop"] != "2025-12-09T14:45:00Z" {
+		t.Errorf("unexpected payload: %v", got)
+	}
+}
+
+func TestEntryKeyIsStable(t *testing.T) {
+	start := time.Date(2025, 12, 9, 14, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	a := &Entry{Start: start, End: start.Add(time.Hour), Project: "repo", Description: "a"}
+	b := &Entry{Start: start.UTC(), End: start.Add(time.Hour).UTC(), Project: "repo", Description: "b"}
+	if a.Key() != b.Key() {
+		t.Errorf("keys differ: %s vs %s", a.Key(), b.Key())
+	}
+}